@@ -0,0 +1,129 @@
+package main
+
+import (
+	"compress/zlib"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/assetdeps"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+var (
+	configFlags = client.RegisterFlags(flag.CommandLine)
+	maps        = flag.String("map", "", "comma separated map names (e.g. prontera)")
+	jobs        = flag.String("job", "", "comma separated job sprite ids")
+	sprites     = flag.String("sprite", "", "comma separated sprite paths, without extension")
+	bundlePath  = flag.String("bundle", "", "path of a GRF to write the required entries to")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// assetdeps prints every GRF entry the given maps, jobs and sprites
+// transitively require, and optionally bundles them into a new GRF.
+func main() {
+	flag.Parse()
+
+	conf, err := configFlags.Load()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	grfFile, err := vfs.Open(context.Background(), conf.DataDir, conf.GRFPaths...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+
+	r := assetdeps.NewResolver(grfFile)
+
+	for _, name := range splitList(*maps) {
+		if err = r.AddMap(name); err != nil {
+			log.Fatal().Err(err).Send()
+		}
+	}
+
+	for _, id := range splitList(*jobs) {
+		jid, err := strconv.Atoi(id)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("invalid job sprite id '%s'", id)
+		}
+
+		if err = r.AddJob(jobspriteid.Type(jid)); err != nil {
+			log.Fatal().Err(err).Send()
+		}
+	}
+
+	for _, name := range splitList(*sprites) {
+		if err = r.AddSprite(name); err != nil {
+			log.Fatal().Err(err).Send()
+		}
+	}
+
+	entries := r.Entries()
+	for _, name := range entries {
+		fmt.Println(name)
+	}
+
+	for _, name := range r.Missing() {
+		log.Warn().Str("entry", name).Msg("missing")
+	}
+
+	if *bundlePath != "" {
+		if err = bundle(grfFile, entries, *bundlePath); err != nil {
+			log.Fatal().Err(err).Msg("failed to write bundle")
+		}
+
+		log.Info().Int("entries", len(entries)).Str("path", *bundlePath).Msg("bundle written")
+	}
+}
+
+func bundle(grfFile *grf.MultiFile, entries []string, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w, err := grf.NewWriter(out, zlib.DefaultCompression)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range entries {
+		e, err := grfFile.GetEntry(name)
+		if err != nil {
+			return err
+		}
+
+		if err = w.WriteEntry(e); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+func splitList(s string) (res []string) {
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+var (
+	configFlags = client.RegisterFlags(flag.CommandLine)
+	headIndex   = flag.Int("head", 1, "head index used when loading characters")
+	checkOther  = flag.Bool("all", false, "also check every monster and NPC sprite")
+)
+
+var spriteDirs = []string{
+	"data/sprite/¸ó½ºÅÍ", // data/sprite/몬스터
+	"data/sprite/npc",
+}
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// checkdata loads the sprites of every job and gender (and optionally every
+// monster and NPC) and reports the ones that are missing or malformed.
+func main() {
+	flag.Parse()
+
+	conf, err := configFlags.Load()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	grfFile, err := vfs.Open(context.Background(), conf.DataDir, conf.GRFPaths...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+
+	var jobs []jobspriteid.Type
+	for id := range character.JobSpriteNameTable {
+		jobs = append(jobs, id)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i] < jobs[j] })
+
+	var checked, problems int
+
+	for _, jid := range jobs {
+		for _, gender := range []character.GenderType{character.Male, character.Female} {
+			checked++
+			name := fmt.Sprintf("job %d (%s)", jid, gender)
+
+			var cmp *component.CharacterAttachmentComponent
+			err := safely(func() (err error) {
+				cmp, err = component.NewCharacterAttachmentComponent(context.Background(), grfFile, component.CharacterAttachmentComponentConfig{
+					Gender:      gender,
+					JobSpriteID: jid,
+					HeadIndex:   character.HeadIndex(*headIndex),
+				})
+				return err
+			})
+			if err != nil {
+				problems++
+				log.Error().Err(err).Msg(name)
+				continue
+			}
+
+			for elem, files := range cmp.Files {
+				if err = validate(files); err != nil {
+					problems++
+					log.Error().Err(err).Str("attachment", elem.String()).Msg(name)
+				}
+			}
+		}
+	}
+
+	if *checkOther {
+		for _, dir := range spriteDirs {
+			for _, e := range grfFile.GetEntries(strings.ToLower(dir)) {
+				if !strings.HasSuffix(e.Name, ".act") {
+					continue
+				}
+
+				checked++
+				name := strings.TrimSuffix(e.Name, ".act")
+
+				err := safely(func() error {
+					files, err := grfFile.GetSpriteFiles(name)
+					if err != nil {
+						return err
+					}
+					return validate(files)
+				})
+				if err != nil {
+					problems++
+					log.Error().Err(err).Msg(name)
+				}
+			}
+		}
+	}
+
+	log.Info().Int("checked", checked).Int("problems", problems).Msg("done")
+
+	if problems > 0 {
+		os.Exit(1)
+	}
+}
+
+// safely runs fn turning a panic, as raised by the parsers on truncated
+// files, into an error.
+func safely(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed file: %v", r)
+		}
+	}()
+
+	return fn()
+}
+
+// validate checks that every layer of every action frame points to
+// an existing sprite frame.
+func validate(files grf.ActionSpriteFilePair) error {
+	if len(files.ACT.Actions) == 0 {
+		return fmt.Errorf("act file has no actions")
+	}
+
+	palCount := int(files.SPR.Header.PalettedFrameCount)
+	rgbaCount := int(files.SPR.Header.RGBAFrameCount)
+
+	for a, action := range files.ACT.Actions {
+		if action == nil || len(action.Frames) == 0 {
+			return fmt.Errorf("action %d has no frames", a)
+		}
+
+		for f, frame := range action.Frames {
+			for _, layer := range frame.Layers {
+				if layer.SpriteFrameIndex < 0 {
+					continue
+				}
+
+				count := palCount
+				if spr.FileType(layer.SpriteType) == spr.FileTypeRGBA {
+					count = rgbaCount
+				}
+
+				if int(layer.SpriteFrameIndex) >= count {
+					return fmt.Errorf(
+						"action %d frame %d layer %d uses sprite frame %d, sprite has %d",
+						a, f, layer.Index, layer.SpriteFrameIndex, count,
+					)
+				}
+			}
+		}
+	}
+
+	return nil
+}
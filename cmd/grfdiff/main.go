@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/grfdiff"
+)
+
+var (
+	oldPath     = flag.String("old", "", "path of the old GRF file")
+	newPath     = flag.String("new", "", "path of the new GRF file")
+	outputPath  = flag.String("o", "", "path of the report, defaults to stdout")
+	format      = flag.String("format", "markdown", "report format (markdown or json)")
+	compareData = flag.Bool("data", false, "also compare the data of entries with the same size")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// grfdiff reports the entries added, removed and modified between two
+// GRF files, as a Markdown changelog or JSON.
+func main() {
+	flag.Parse()
+
+	oldFile, err := grf.Load(*oldPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load old grf file")
+	}
+	defer oldFile.Close()
+
+	newFile, err := grf.Load(*newPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load new grf file")
+	}
+	defer newFile.Close()
+
+	report, err := grfdiff.Compare(oldFile, newFile, filepath.Base(*oldPath), filepath.Base(*newPath), grfdiff.Options{
+		CompareData: *compareData,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to compare grf files")
+	}
+
+	var out []byte
+	switch *format {
+	case "markdown":
+		out = report.Markdown()
+	case "json":
+		if out, err = report.JSON(); err != nil {
+			log.Fatal().Err(err).Send()
+		}
+	default:
+		log.Fatal().Msgf("unknown format '%s'", *format)
+	}
+
+	if *outputPath == "" {
+		_, _ = os.Stdout.Write(out)
+		return
+	}
+
+	if err = ioutil.WriteFile(*outputPath, out, 0644); err != nil {
+		log.Fatal().Err(err).Send()
+	}
+}
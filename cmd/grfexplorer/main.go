@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
@@ -8,14 +10,16 @@ import (
 	g "github.com/AllenDang/giu"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"golang.org/x/text/encoding/charmap"
 
+	"github.com/project-midgard/midgarts/internal/client"
 	"github.com/project-midgard/midgarts/internal/fileformat/act"
 	"github.com/project-midgard/midgarts/internal/fileformat/grf"
 	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
 )
 
-var grfFile *grf.File
+var grfFile *grf.MultiFile
 var imageWidget = &g.ImageWidget{}
 var fileInfoWidget g.Widget
 var loadedImageName string
@@ -24,14 +28,18 @@ var currentEntry *grf.Entry
 func init() {
 	zerolog.SetGlobalLevel(zerolog.TraceLevel)
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+func main() {
+	configFlags := client.RegisterFlags(flag.CommandLine)
+	flag.Parse()
 
-	var err error
-	grfFile, err = grf.Load("./assets/grf/data.grf")
+	conf, err := configFlags.Load()
 	noErr(err)
 
-}
+	grfFile, err = vfs.Open(context.Background(), conf.DataDir, conf.GRFPaths...)
+	noErr(err)
 
-func main() {
 	wnd := g.NewMasterWindow("Hello world", 640, 480, 0, nil)
 	wnd.Run(Run)
 }
@@ -110,8 +118,7 @@ func loadFileInfo() {
 }
 
 func getDecodedFolder(buf []byte) (string, error) {
-	folderNameBytes, err := charmap.Windows1252.NewDecoder().Bytes(buf)
-	return string(folderNameBytes), err
+	return encoding.DecodeRaw(buf), nil
 }
 
 func buildEntryTreeNodes() g.Layout {
@@ -137,22 +144,10 @@ func buildEntryTreeNodes() g.Layout {
 				}
 			}
 
-			var decodedDirName []byte
-			var err error
-			if decodedDirName, err = charmap.Windows1252.NewDecoder().Bytes([]byte(n.Value)); err != nil {
-				panic(err)
-			}
-
 			if len(nodeEntries) > 0 {
-				node := g.TreeNode(fmt.Sprintf("%s (%d)", decodedDirName, len(nodeEntries)))
+				node := g.TreeNode(fmt.Sprintf("%s (%d)", encoding.Display(n.Value), len(nodeEntries)))
 				selectableNodes = g.RangeBuilder("selectableNodes", nodeEntries, func(i int, v interface{}) g.Widget {
-					var decodedStr string
-					var err error
-					if decodedStr, err = charmap.Windows1252.NewDecoder().String(v.(string)); err != nil {
-						panic(err)
-					}
-
-					return g.Selectable(decodedStr).OnClick(func() {
+					return g.Selectable(encoding.Display(v.(string))).OnClick(func() {
 						onClickEntry(v.(string))
 					})
 				})
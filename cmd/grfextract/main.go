@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+var (
+	inputPath  = flag.String("i", "", "path of the GRF file to extract")
+	outputPath = flag.String("o", "", "folder to extract the entries to")
+	prefix     = flag.String("prefix", "", "only extract the entries starting with this path, e.g. data/sprite/인간족")
+	workers    = flag.Int("workers", 0, "entries decompressed at the same time, the number of CPUs by default")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// grfextract writes the entries of a GRF file to a folder, with their
+// Korean names converted to UTF-8, so it can be used as a data folder.
+func main() {
+	flag.Parse()
+
+	if *inputPath == "" || *outputPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	grfFile, err := grf.LoadContext(ctx, *inputPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+
+	// The entries are named in their raw, lowercased form.
+	rawPrefix, err := encoding.FromUTF8(*prefix)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid -prefix")
+	}
+	rawPrefix = strings.ToLower(strings.ReplaceAll(rawPrefix, `\`, `/`))
+
+	var names []string
+	seen := map[string]bool{}
+	for _, entries := range grfFile.GetEntryDirectories() {
+		for _, e := range entries {
+			if !seen[e.Name] && strings.HasPrefix(e.Name, rawPrefix) {
+				seen[e.Name] = true
+				names = append(names, e.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	start := time.Now()
+	if err = grfFile.ExtractAll(ctx, *outputPath, names, *workers); err != nil {
+		log.Fatal().Err(err).Msg("failed to extract")
+	}
+
+	log.Info().
+		Int("entries", len(names)).
+		Dur("took", time.Since(start)).
+		Msgf("extracted %s to %s", *inputPath, *outputPath)
+}
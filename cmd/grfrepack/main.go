@@ -0,0 +1,117 @@
+package main
+
+import (
+	"compress/zlib"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+var (
+	inputPath  = flag.String("i", "", "path of the GRF file to repack")
+	outputPath = flag.String("o", "", "path of the repacked GRF file")
+	level      = flag.Int("level", zlib.BestCompression, "zlib compression level (0-9)")
+	skipBroken = flag.Bool("skip-broken", false, "drop entries that cannot be decoded instead of failing")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// grfrepack rewrites a GRF file keeping only one entry per file name,
+// recompressing every entry and sorting the file table.
+func main() {
+	flag.Parse()
+
+	if *inputPath == "" || *outputPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	in, _ := filepath.Abs(*inputPath)
+	out, _ := filepath.Abs(*outputPath)
+	if in == out {
+		log.Fatal().Msg("output path must differ from input path")
+	}
+
+	grfFile, err := grf.Load(*inputPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+
+	// GetEntry resolves duplicated names to the first entry in
+	// the file table, so keep that very same entry.
+	var names []string
+	seen := map[string]bool{}
+	for _, entries := range grfFile.GetEntryDirectories() {
+		for _, e := range entries {
+			if !seen[e.Name] {
+				seen[e.Name] = true
+				names = append(names, e.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	f, err := os.Create(*outputPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create output file")
+	}
+	defer f.Close()
+
+	w, err := grf.NewWriter(f, *level)
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	var written int
+	for _, name := range names {
+		e, err := grfFile.GetEntry(name)
+		if err != nil {
+			if *skipBroken {
+				log.Warn().Err(err).Str("entry", name).Msg("skipping broken entry")
+				continue
+			}
+			log.Fatal().Err(err).Str("entry", name).Msg("failed to read entry")
+		}
+
+		if err = w.WriteEntry(e); err != nil {
+			log.Fatal().Err(err).Str("entry", name).Msg("failed to write entry")
+		}
+
+		// Entries cache their data once loaded, release it
+		// so big archives don't end up whole in memory.
+		e.Data = nil
+		written++
+	}
+
+	if err = w.Close(); err != nil {
+		log.Fatal().Err(err).Msg("failed to write file table")
+	}
+
+	inInfo, err := os.Stat(*inputPath)
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+	outInfo, err := f.Stat()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	saved := inInfo.Size() - outInfo.Size()
+	log.Info().
+		Int("entries", written).
+		Int("removed", int(grfFile.Header.EntryCount)-written).
+		Int64("before", inInfo.Size()).
+		Int64("after", outInfo.Size()).
+		Int64("saved", saved).
+		Msgf("repacked %s (%+.2f%%)", *outputPath, float64(-saved)*100/float64(inInfo.Size()))
+}
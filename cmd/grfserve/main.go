@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/assetserver"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/grfhttp"
+)
+
+var (
+	configFlags = client.RegisterFlags(flag.CommandLine)
+	addr        = flag.String("addr", "localhost:8080", "address to listen on")
+	webDir      = flag.String("web", "", "folder served under /, e.g. the one of the WebAssembly demo (see web/)")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// grfserve serves the entries of a GRF file under /grf/, for the clients
+// loading their assets over HTTP, such as the WebAssembly demo, and the
+// assets converted to the formats of the web under /api/, for the web
+// clients (see assetserver).
+func main() {
+	flag.Parse()
+
+	conf, err := configFlags.Load()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	grfFile, err := grf.LoadAllContext(ctx, conf.GRFPaths...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+	grfFile.SetDataDir(conf.DataDir)
+
+	mux := http.NewServeMux()
+	mux.Handle("/grf/", http.StripPrefix("/grf", grfhttp.NewHandler(ctx, grfFile)))
+	mux.Handle("/api/", http.StripPrefix("/api", assetserver.NewServer(ctx, grfFile)))
+	if *webDir != "" {
+		mux.Handle("/", http.FileServer(http.Dir(*webDir)))
+	}
+
+	server := &http.Server{Addr: *addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	log.Info().Strs("grf", conf.GRFPaths).Msgf("serving at http://%s/grf/ and http://%s/api/", *addr, *addr)
+	if err = server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal().Err(err).Msg("server stopped")
+	}
+}
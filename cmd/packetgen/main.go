@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/network/packetgen"
+)
+
+var (
+	specPath  = flag.String("spec", "packets.yaml", "path of the packet spec")
+	outPath   = flag.String("o", "packets_gen.go", "path of the generated Go file")
+	docPath   = flag.String("doc", "", "path of the generated Markdown reference, if any")
+	packetVer = flag.Int("packetver", 0, "packet version to generate, defaults to the spec packetver")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// packetgen generates the packet structs and their encoding code from
+// a declarative spec, see packetgen.Spec.
+func main() {
+	flag.Parse()
+
+	data, err := ioutil.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to read spec")
+	}
+
+	spec, err := packetgen.ParseSpec(data)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to parse spec")
+	}
+
+	if *packetVer == 0 {
+		*packetVer = spec.PacketVer
+	}
+
+	src, err := packetgen.Generate(spec, *packetVer)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to generate code")
+	}
+
+	if err = ioutil.WriteFile(*outPath, src, 0644); err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	if *docPath != "" {
+		doc, err := packetgen.GenerateDoc(spec, *packetVer)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to generate documentation")
+		}
+
+		if err = ioutil.WriteFile(*docPath, doc, 0644); err != nil {
+			log.Fatal().Err(err).Send()
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"strconv"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/portrait"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+const (
+	CellWidth   = 100
+	CellHeight  = 140
+	LabelHeight = 16
+)
+
+var (
+	configFlags = client.RegisterFlags(flag.CommandLine)
+	outputPath  = flag.String("o", "palettes.png", "path of the generated contact sheet")
+	kind        = flag.String("kind", "hair", "palette kind: hair or body")
+	jobID       = flag.Int("job", int(jobspriteid.Novice), "job sprite id")
+	female      = flag.Bool("female", false, "use the female sprites")
+	headIndex   = flag.Int("head", 1, "head (hair style) index")
+	direction   = flag.Int("direction", int(directiontype.South), "direction the character faces (0-7)")
+	maxIndex    = flag.Int("max", 128, "highest palette index to look for")
+	columns     = flag.Int("columns", 10, "number of cells per row")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// palettesheet renders a character sprite once per available hair or clothes
+// palette into a single labeled image, so dye IDs can be picked at a glance.
+func main() {
+	flag.Parse()
+
+	gender := character.Male
+	if *female {
+		gender = character.Female
+	}
+
+	clientConf, err := configFlags.Load()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	grfFile, err := vfs.Open(context.Background(), clientConf.DataDir, clientConf.GRFPaths...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+
+	conf := portrait.Config{
+		Gender:      gender,
+		JobSpriteID: jobspriteid.Type(*jobID),
+		HeadIndex:   character.HeadIndex(*headIndex),
+		Direction:   directiontype.Type(*direction),
+	}
+
+	var paletteFilePath func(i int) string
+	switch *kind {
+	case "hair":
+		paletteFilePath = func(i int) string {
+			return character.HairPaletteFilePath(gender, conf.HeadIndex, i)
+		}
+	case "body":
+		paletteFilePath = func(i int) string {
+			return character.BodyPaletteFilePath(gender, conf.JobSpriteID, i)
+		}
+	default:
+		log.Fatal().Msgf("unsupported palette kind '%s'", *kind)
+	}
+
+	// Index 0 is the sprite's own palette.
+	indices := []int{0}
+	for i := 1; i <= *maxIndex; i++ {
+		if _, err := grfFile.GetEntry(paletteFilePath(i)); err == nil {
+			indices = append(indices, i)
+		}
+	}
+
+	rows := (len(indices) + *columns - 1) / *columns
+	sheet := image.NewRGBA(image.Rect(0, 0, *columns*CellWidth, rows*(CellHeight+LabelHeight)))
+	draw.Draw(sheet, sheet.Bounds(), image.White, image.Point{}, draw.Src)
+
+	var count int
+	for i, index := range indices {
+		if *kind == "hair" {
+			conf.HairPalette = index
+		} else {
+			conf.BodyPalette = index
+		}
+
+		p, err := portrait.Render(context.Background(), grfFile, conf)
+		if err != nil {
+			log.Warn().Err(err).Int("palette", index).Msg("skipping palette")
+			continue
+		}
+		count++
+
+		x, y := (i%*columns)*CellWidth, (i / *columns)*(CellHeight+LabelHeight)
+		min := image.Point{X: x + CellWidth/2, Y: y + CellHeight*3/4}.Sub(p.Origin)
+		draw.Draw(sheet, image.Rectangle{Min: min, Max: min.Add(p.Image.Bounds().Size())}, p.Image, image.Point{}, draw.Over)
+
+		label := strconv.Itoa(index)
+		d := &font.Drawer{
+			Dst:  sheet,
+			Src:  image.NewUniform(color.Black),
+			Face: basicfont.Face7x13,
+			Dot:  fixed.P(x+CellWidth/2-len(label)*7/2, y+CellHeight+LabelHeight-4),
+		}
+		d.DrawString(label)
+	}
+
+	out, err := os.Create(*outputPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create output file")
+	}
+	defer out.Close()
+
+	if err = png.Encode(out, sheet); err != nil {
+		log.Fatal().Err(err).Msg("failed to encode contact sheet")
+	}
+
+	log.Info().Int("palettes", count).Msgf("wrote %s", *outputPath)
+}
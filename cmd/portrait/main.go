@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/actionindex"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/portrait"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+var (
+	configFlags = client.RegisterFlags(flag.CommandLine)
+	outputPath  = flag.String("o", "portrait.png", "path of the generated image")
+	jobID       = flag.Int("job", int(jobspriteid.Novice), "job sprite id")
+	female      = flag.Bool("female", false, "use the female sprites")
+	headIndex   = flag.Int("head", 1, "head (hair style) index")
+	hairPalette = flag.Int("hair-palette", 0, "hair palette index")
+	bodyPalette = flag.Int("body-palette", 0, "clothes palette index")
+	headgears   = flag.String("headgear", "", "comma separated headgear accessory names")
+	direction   = flag.Int("direction", int(directiontype.South), "direction the character faces (0-7)")
+	action      = flag.Int("action", int(actionindex.Idle), "action index")
+	frame       = flag.Int("frame", 0, "action frame index")
+	scale       = flag.Int("scale", 1, "integer upscale factor")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// portrait renders a still PNG image of a character.
+func main() {
+	flag.Parse()
+
+	clientConf, err := configFlags.Load()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	grfFile, err := vfs.Open(context.Background(), clientConf.DataDir, clientConf.GRFPaths...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+
+	conf := portrait.Config{
+		Gender:      character.Male,
+		JobSpriteID: jobspriteid.Type(*jobID),
+		HeadIndex:   character.HeadIndex(*headIndex),
+		Direction:   directiontype.Type(*direction),
+		ActionIndex: actionindex.Type(*action),
+		FrameIndex:  *frame,
+		HairPalette: *hairPalette,
+		BodyPalette: *bodyPalette,
+	}
+	if *female {
+		conf.Gender = character.Female
+	}
+	if *headgears != "" {
+		conf.Headgears = strings.Split(*headgears, ",")
+	}
+
+	p, err := portrait.Render(context.Background(), grfFile, conf)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to render character")
+	}
+
+	img := p.Image
+	if *scale > 1 {
+		img = upscale(img, *scale)
+	}
+
+	out, err := os.Create(*outputPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create output file")
+	}
+	defer out.Close()
+
+	if err = png.Encode(out, img); err != nil {
+		log.Fatal().Err(err).Msg("failed to encode image")
+	}
+
+	log.Info().Msgf("wrote %s", *outputPath)
+}
+
+func upscale(img *image.RGBA, n int) *image.RGBA {
+	b := img.Bounds()
+	res := image.NewRGBA(image.Rect(0, 0, b.Dx()*n, b.Dy()*n))
+
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			r := image.Rect(x*n, y*n, (x+1)*n, (y+1)*n)
+			draw.Draw(res, r, image.NewUniform(img.At(b.Min.X+x, b.Min.Y+y)), image.Point{}, draw.Src)
+		}
+	}
+
+	return res
+}
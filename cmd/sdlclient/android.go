@@ -0,0 +1,26 @@
+//go:build android
+// +build android
+
+package main
+
+import "C"
+
+import (
+	"os"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SDL_main is the entry point of the Android build, a shared library
+// loaded as libmain.so by the SDLActivity of the SDL2 Android project,
+// which calls it once its surface is created. The config file and the
+// archives are read from the external storage of the app.
+//
+//export SDL_main
+func SDL_main() {
+	if err := os.Chdir(sdl.AndroidGetExternalStoragePath()); err != nil {
+		panic(err)
+	}
+
+	main()
+}
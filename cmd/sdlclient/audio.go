@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/effect"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+// mixerChannels is the number of sound effects played at once.
+const mixerChannels = 32
+
+// sdlMixer is the audio.Mixer of the client, playing with SDL_mixer the
+// sound effects of the archives, decoded once, and the music of the
+// folder of the client, streamed.
+type sdlMixer struct {
+	grf       *grf.MultiFile
+	clientDir string
+	effects   map[string]*mix.Chunk
+	music     *mix.Music
+}
+
+// openMixer opens the audio device with the volumes of conf.
+func openMixer(conf client.AudioConfig, grfFile *grf.MultiFile) (*sdlMixer, error) {
+	if err := mix.Init(mix.INIT_MP3); err != nil {
+		return nil, errors.Wrap(err, "could not load the mp3 decoder")
+	}
+	if err := mix.OpenAudio(mix.DEFAULT_FREQUENCY, mix.DEFAULT_FORMAT, 2, mix.DEFAULT_CHUNKSIZE); err != nil {
+		mix.Quit()
+		return nil, errors.Wrap(err, "could not open the audio device")
+	}
+	mix.AllocateChannels(mixerChannels)
+	mix.Volume(-1, int(conf.EffectVolume*mix.MAX_VOLUME))
+	mix.VolumeMusic(int(conf.MusicVolume * mix.MAX_VOLUME))
+
+	return &sdlMixer{grf: grfFile, clientDir: conf.ClientDir, effects: map[string]*mix.Chunk{}}, nil
+}
+
+func (m *sdlMixer) PlayEffect(name string, gains audio.Gains) error {
+	chunk, ok := m.effects[name]
+	if !ok {
+		e, err := m.grf.GetEntry(effect.SoundDir + name)
+		if err != nil {
+			return err
+		}
+		rw, err := sdl.RWFromMem(e.Data)
+		if err != nil {
+			return err
+		}
+		if chunk, err = mix.LoadWAVRW(rw, true); err != nil {
+			return errors.Wrapf(err, "invalid sound '%s'", name)
+		}
+		m.effects[name] = chunk
+	}
+
+	channel, err := chunk.Play(-1, 0)
+	if err != nil {
+		// Every channel is playing: the sound is dropped.
+		return nil
+	}
+
+	// The sounds in front of the listener have gains of √2/2, played at
+	// full volume.
+	pan := func(gain float32) uint8 {
+		return uint8(math.Min(float64(gain)*math.Sqrt2, 1) * 254)
+	}
+	return mix.SetPanning(channel, pan(gains.Left), pan(gains.Right))
+}
+
+func (m *sdlMixer) PlayMusic(path string) error {
+	mix.HaltMusic()
+	if m.music != nil {
+		m.music.Free()
+		m.music = nil
+	}
+	if path == "" {
+		return nil
+	}
+
+	music, err := mix.LoadMUS(filepath.Join(m.clientDir, filepath.FromSlash(path)))
+	if err != nil {
+		return errors.Wrapf(err, "could not load music '%s'", path)
+	}
+	m.music = music
+
+	return music.Play(-1)
+}
+
+// Close stops the sounds and closes the audio device.
+func (m *sdlMixer) Close() {
+	mix.HaltChannel(-1)
+	mix.HaltMusic()
+	for _, chunk := range m.effects {
+		chunk.Free()
+	}
+	if m.music != nil {
+		m.music.Free()
+	}
+
+	mix.CloseAudio()
+	mix.Quit()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/project-midgard/midgarts/internal/ai"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/sim"
+	"github.com/project-midgard/midgarts/internal/system"
+)
+
+// populate makes the characters of -bots act on their own around player:
+// the monsters wander around their spot and attack the player in sight,
+// the first other player follows the player and the next 4 wander.
+func populate(s *system.CharacterAISystem, player *entity.Character, chars []*entity.Character) {
+	target := s.Track(player)
+	hostile := func(a *sim.Actor) bool { return a == target }
+
+	var players int
+	for _, char := range chars {
+		if char == player {
+			continue
+		}
+
+		if char.SpritePath != "" {
+			wander := ai.NewWander(sim.Cell{}, 5)
+			wander.Home = s.Control(char, ai.NewAggro(hostile, wander)).Cell()
+			continue
+		}
+
+		players++
+		switch {
+		case players == 1:
+			s.Control(char, ai.NewFollow(target))
+		case players <= 5:
+			wander := ai.NewWander(sim.Cell{}, 3)
+			wander.Home = s.Control(char, wander).Cell()
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"expvar"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/console"
+	"github.com/project-midgard/midgarts/internal/effect"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/scene"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// typeConsole hands the keyboard to the console: the key of the console
+// action of keys toggles it and, while it is open, the key presses and the
+// typed text edit its line instead of playing. It tells whether ev was
+// taken. The key releases are never taken, so no key stays pressed once
+// the console opens.
+func typeConsole(con *console.Console, keys keybinds, ev sdl.Event) bool {
+	switch ev := ev.(type) {
+	case *sdl.KeyboardEvent:
+		if ev.Type != sdl.KEYDOWN {
+			return false
+		}
+		if keys.action(ev.Keysym.Sym) == client.ActionConsole {
+			if ev.Repeat == 0 {
+				con.Toggle()
+			}
+			return true
+		}
+		if !con.IsOpen() {
+			return false
+		}
+
+		switch ev.Keysym.Sym {
+		case sdl.K_RETURN, sdl.K_KP_ENTER:
+			con.Submit()
+		case sdl.K_BACKSPACE:
+			con.Backspace()
+		case sdl.K_UP:
+			con.Previous()
+		case sdl.K_DOWN:
+			con.Next()
+		case sdl.K_TAB:
+			con.Complete()
+		case sdl.K_ESCAPE:
+			con.Toggle()
+		}
+		return true
+	case *sdl.TextInputEvent:
+		if !con.IsOpen() {
+			return false
+		}
+		con.Type(ev.GetText())
+		return true
+	}
+
+	return false
+}
+
+// registerCommands adds the commands of the client, running the whole
+// session, to con.
+func registerCommands(con *console.Console, scenes *scene.Manager) {
+	con.Register(console.Command{
+		Name:  "map",
+		Usage: "<name>",
+		Help:  "loads a map, e.g. prontera",
+		Run: func(args []string) (string, error) {
+			if len(args) != 1 {
+				return "", errors.New("expected the name of the map")
+			}
+			current, ok := scenes.Current().(*mapScene)
+			if !ok {
+				return "", errors.New("no map is loaded")
+			}
+			if _, err := current.services.GRF.GetEntry("data/" + args[0] + ".gat"); err != nil {
+				return "", errors.Errorf("unknown map '%s'", args[0])
+			}
+
+			scenes.Switch(current.withMap(args[0]))
+			return "loading " + args[0], nil
+		},
+	})
+	con.Register(console.Command{
+		Name: "stats",
+		Help: "shows the metrics, as the debug server does",
+		Run: func([]string) (string, error) {
+			var lines []string
+			expvar.Do(func(kv expvar.KeyValue) {
+				// The ones of the runtime are too long to read here.
+				if kv.Key == "memstats" || kv.Key == "cmdline" {
+					return
+				}
+				lines = append(lines, fmt.Sprintf("%s: %s", kv.Key, kv.Value))
+			})
+			return strings.Join(lines, "\n"), nil
+		},
+	})
+}
+
+// withMap returns a scene of the map of name, for the same client as s.
+func (s *mapScene) withMap(name string) *mapScene {
+	return &mapScene{
+		mapName:     name,
+		frameBudget: s.frameBudget,
+		lodDistance: s.lodDistance,
+		quit:        s.quit,
+		services:    s.services,
+		win:         s.win,
+		ks:          s.ks,
+		profiles:    s.profiles,
+	}
+}
+
+// registerCommands adds the commands of the scene to con, and returns the
+// funcs removing them.
+func (s *mapScene) registerCommands(con *console.Console) []func() {
+	player := s.chars[0]
+
+	return []func(){
+		con.Register(console.Command{
+			Name:  "spawn",
+			Usage: "<job|monster id> [m|f]",
+			Help:  "spawns a character next to the player",
+			Run: func(args []string) (string, error) {
+				if len(args) == 0 {
+					return "", errors.New("expected a job or a monster id")
+				}
+
+				var char *entity.Character
+				if id, err := strconv.Atoi(args[0]); err == nil {
+					if char, err = s.services.Factory.CreateMonster(id); err != nil {
+						return "", err
+					}
+				} else {
+					job, err := jobspriteid.Parse(args[0])
+					if err != nil {
+						return "", err
+					}
+					gender := character.Male
+					if len(args) > 1 && args[1] == "f" {
+						gender = character.Female
+					}
+					char = s.services.Factory.CreatePlayer(gender, job, player.HeadIndex)
+				}
+
+				char.SetPosition(player.Position().Add(mgl32.Vec3{-2, 0, 0}))
+				s.addCharacter(char)
+				return fmt.Sprintf("spawned entity %d", char.ID()), nil
+			},
+		}),
+		con.Register(console.Command{
+			Name:  "job",
+			Usage: "<job>",
+			Help:  "changes the job of the player, e.g. assassin",
+			Run: func(args []string) (string, error) {
+				if len(args) != 1 {
+					return "", errors.New("expected a job")
+				}
+				job, err := jobspriteid.Parse(args[0])
+				if err != nil {
+					return "", err
+				}
+
+				// Loads the sprites of the job.
+				s.renderSys.Remove(*player.BasicEntity)
+				player.JobSpriteID = job
+				s.renderSys.Add(player)
+				s.services.Bus.Publish(event.LevelUp{Character: player, Kind: event.JobChanged})
+				return "", nil
+			},
+		}),
+		con.Register(console.Command{
+			Name:  "dye",
+			Usage: "<body|hair> <palette>",
+			Help:  "dyes the clothes or the hair of the player, 0 for none",
+			Run: func(args []string) (string, error) {
+				if len(args) != 2 {
+					return "", errors.New("expected body or hair and a palette")
+				}
+				var elem character.AttachmentType
+				switch args[0] {
+				case "body":
+					elem = character.AttachmentBody
+				case "hair":
+					elem = character.AttachmentHead
+				default:
+					return "", errors.Errorf("unknown attachment '%s', expected body or hair", args[0])
+				}
+				index, err := strconv.Atoi(args[1])
+				if err != nil || index < 0 {
+					return "", errors.Errorf("invalid palette '%s'", args[1])
+				}
+
+				palettes := map[character.AttachmentType]int{}
+				for e, i := range player.Palettes {
+					palettes[e] = i
+				}
+				palettes[elem] = index
+
+				// Loads the dyed sprites.
+				s.renderSys.Remove(*player.BasicEntity)
+				player.Palettes = palettes
+				s.renderSys.Add(player)
+				return "", nil
+			},
+		}),
+		con.Register(console.Command{
+			Name:  "headgear",
+			Usage: "<top|mid|low> <view id>",
+			Help:  "puts a headgear on the player, 0 for none",
+			Run: func(args []string) (string, error) {
+				if len(args) != 2 {
+					return "", errors.New("expected top, mid or low and a view id")
+				}
+				id, err := strconv.Atoi(args[1])
+				if err != nil || id < 0 {
+					return "", errors.Errorf("invalid view id '%s'", args[1])
+				}
+				if _, err := character.HeadgearSpritePath(player.Gender, id); id != 0 && err != nil {
+					return "", err
+				}
+
+				var headgear *int
+				switch args[0] {
+				case "top":
+					headgear = &player.TopHeadgear
+				case "mid":
+					headgear = &player.MidHeadgear
+				case "low":
+					headgear = &player.LowHeadgear
+				default:
+					return "", errors.Errorf("unknown headgear '%s', expected top, mid or low", args[0])
+				}
+
+				// Loads the sprites of the headgear.
+				s.renderSys.Remove(*player.BasicEntity)
+				*headgear = id
+				s.renderSys.Add(player)
+				return "", nil
+			},
+		}),
+		con.Register(console.Command{
+			Name:  "weapon",
+			Usage: "<type>",
+			Help:  "puts a weapon of a type in the hands of the player, e.g. sword, none for none",
+			Run: func(args []string) (string, error) {
+				if len(args) != 1 {
+					return "", errors.New("expected a weapon type")
+				}
+				weapon, err := weapontype.Parse(args[0])
+				if err != nil {
+					return "", err
+				}
+				if _, err := character.WeaponFilePath(player.Gender, player.JobSpriteID, weapon); weapon != weapontype.None && err != nil {
+					return "", err
+				}
+
+				// Loads the sprites of the weapon.
+				s.renderSys.Remove(*player.BasicEntity)
+				player.Weapon = weapon
+				s.renderSys.Add(player)
+				return "", nil
+			},
+		}),
+		con.Register(console.Command{
+			Name:  "levelup",
+			Usage: "[base|job]",
+			Help:  "shows the level up of the player, base by default",
+			Run: func(args []string) (string, error) {
+				kind := event.BaseLevelUp
+				if len(args) > 0 {
+					switch args[0] {
+					case "base":
+					case "job":
+						kind = event.JobLevelUp
+					default:
+						return "", errors.Errorf("unknown level '%s', expected base or job", args[0])
+					}
+				}
+
+				s.services.Bus.Publish(event.LevelUp{Character: player, Kind: kind})
+				return "", nil
+			},
+		}),
+		con.Register(console.Command{
+			Name:  "effect",
+			Usage: "<name>",
+			Help:  "plays an effect on the player, e.g. firewall",
+			Run: func(args []string) (string, error) {
+				if len(args) != 1 {
+					return "", errors.New("expected an effect")
+				}
+
+				return "", s.effects.PlayNamed(effectPlayer{s}, args[0], effect.Target{Entity: player.ID()})
+			},
+		}),
+		con.Register(console.Command{
+			Name:  "time",
+			Usage: "<hour>|off",
+			Help:  "lights the map as at an hour of the day, from 0 to 24",
+			Run: func(args []string) (string, error) {
+				if len(args) != 1 {
+					return "", errors.New("expected an hour")
+				}
+				if args[0] == "off" {
+					s.glRender.RemovePostPass("daylight")
+					return "", nil
+				}
+				hour, err := strconv.ParseFloat(args[0], 32)
+				if err != nil || hour < 0 || hour > 24 {
+					return "", errors.Errorf("invalid hour '%s'", args[0])
+				}
+
+				pass := opengl.NewColorGradingPass(daylight(float32(hour)))
+				pass.Name = "daylight"
+				s.glRender.AddPostPass(pass)
+				return "", nil
+			},
+		}),
+		con.Register(console.Command{
+			Name: "overlay",
+			Help: "toggles the walkability and height overlay, as F2",
+			Run: func([]string) (string, error) {
+				s.services.Bus.Publish(event.GATOverlayToggled{})
+				return "", nil
+			},
+		}),
+	}
+}
+
+// daylight returns the grading of the frame at hour, from 0 to 24: the
+// nights dark and blue, the dawns and the dusks orange.
+func daylight(hour float32) opengl.ColorGrading {
+	// 1 at noon, -1 at midnight.
+	sun := float32(math.Cos(float64(hour-12) / 24 * 2 * math.Pi))
+	light := 0.65 + 0.35*sun
+	// The sun is on the horizon.
+	dusk := 1 - float32(math.Abs(float64(sun)))
+
+	return opengl.ColorGrading{
+		Saturation: 0.7 + 0.3*light,
+		Contrast:   1,
+		Balance:    mgl32.Vec3{light * (1 + 0.2*dusk), light, light*(1-0.2*dusk) + 0.2*(1-light)},
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"io/ioutil"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/effect"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+)
+
+// effectPlayer is the effect.Player of the scene. The STR animations are
+// played by the effect render system and the sounds by the audio system;
+// the client renders no effect sprite nor particle preset yet: those
+// effects are only logged.
+type effectPlayer struct {
+	scene *mapScene
+}
+
+// PlaySTR plays file where the entity of target stands when the effect
+// starts, or at its position.
+func (p effectPlayer) PlaySTR(file string, target effect.Target) error {
+	position := target.Position
+	if char := p.scene.character(target.Entity); char != nil {
+		position = char.Position()
+	} else if target.Entity != 0 {
+		// Out of sight.
+		return nil
+	}
+
+	return p.scene.effectSys.Play(file, position)
+}
+
+func (effectPlayer) PlaySprite(name string, target effect.Target) error {
+	log.Debug().Str("sprite", effect.SpriteDir+name).Uint64("entity", target.Entity).Msg("sprite effects are not rendered yet, ignoring")
+	return nil
+}
+
+func (effectPlayer) PlayParticles(preset string, target effect.Target) error {
+	log.Debug().Str("particles", preset).Uint64("entity", target.Entity).Msg("particle effects are not rendered yet, ignoring")
+	return nil
+}
+
+func (p effectPlayer) PlaySound(file string, target effect.Target) error {
+	position := target.Position
+	if char := p.scene.character(target.Entity); char != nil {
+		position = char.Position()
+	} else if target.Entity != 0 {
+		// Out of sight, so out of hearing.
+		return nil
+	}
+
+	x, y := p.scene.renderSys.Ground.Cell(position)
+	p.scene.services.Audio.PlayEffect(file, mgl32.Vec2{x, y})
+	return nil
+}
+
+// loadEffects returns the default effect table, extended with the one of
+// -effects, if any.
+func loadEffects() (*effect.Registry, error) {
+	effects := effect.NewDefaultRegistry()
+	if *effectTable == "" {
+		return effects, nil
+	}
+
+	data, err := ioutil.ReadFile(*effectTable)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read effect table")
+	}
+	if err = effects.LoadTable(data); err != nil {
+		return nil, errors.Wrapf(err, "failed to load effect table '%s'", *effectTable)
+	}
+
+	return effects, nil
+}
+
+// levelUpEffects are the effects of the kinds of event.LevelUp.
+var levelUpEffects = map[event.LevelUpKind]effect.Effect{
+	event.BaseLevelUp: effect.LevelUp,
+	event.JobLevelUp:  effect.JobLevelUp,
+	event.JobChanged:  effect.JobChange,
+}
+
+// levelUps are the kinds of level gained of the types of
+// packet.ZC_NOTIFY_EFFECT.
+var levelUps = map[uint32]event.LevelUpKind{
+	0: event.BaseLevelUp,
+	1: event.JobLevelUp,
+	7: event.BaseLevelUp,
+	8: event.JobLevelUp,
+	9: event.BaseLevelUp,
+}
+
+// subscribeEffects plays the effects the server shows with the effects of
+// the scene, and the ones of the levels gained.
+func (s *mapScene) subscribeEffects(bus *event.Bus) func() {
+	unsubscribePackets := bus.Subscribe(func(e event.PacketReceived) {
+		switch p := e.Packet.(type) {
+		case *packet.ZC_NOTIFY_EFFECT2:
+			if err := s.effects.Play(effectPlayer{s}, effect.ID(p.EffectID), effect.Target{Entity: uint64(p.AID)}); err != nil {
+				log.Warn().Err(err).Uint32("aid", p.AID).Msg("could not show effect")
+			}
+		case *packet.ZC_NOTIFY_EFFECT:
+			kind, ok := levelUps[p.Type]
+			if !ok {
+				log.Debug().Uint32("aid", p.AID).Uint32("type", p.Type).Msg("effect not shown yet, ignoring")
+				return
+			}
+			if char := s.character(uint64(p.AID)); char != nil {
+				bus.Publish(event.LevelUp{Character: char, Kind: kind})
+			}
+		}
+	})
+
+	unsubscribeLevelUps := bus.Subscribe(func(e event.LevelUp) {
+		if err := levelUpEffects[e.Kind].Play(effectPlayer{s}, effect.Target{Entity: e.Character.ID()}); err != nil {
+			log.Warn().Err(err).Uint64("entity", e.Character.ID()).Msg("could not show level up")
+		}
+	})
+
+	return func() {
+		unsubscribePackets()
+		unsubscribeLevelUps()
+	}
+}
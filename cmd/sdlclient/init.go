@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/client"
+)
+
+// runInit implements `midgarts init`, writing a default config file
+// and checking the archives it references.
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	output := fs.String("o", client.DefaultConfigFileName, "path of the config file to write")
+	grfPath := fs.String("grf", "", "GRF archive to reference, defaults to GRF_FILE_PATH")
+	dataDir := fs.String("data", "", "loose data folder to reference")
+	force := fs.Bool("force", false, "overwrite an existing config file")
+	user := fs.Bool("user", false, "write the config file to the user config folder, instead of -o")
+	_ = fs.Parse(args)
+
+	if *user {
+		path, err := client.UserConfigPath()
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to write config file")
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			log.Fatal().Err(err).Msg("failed to write config file")
+		}
+		*output = path
+	}
+
+	conf := client.DefaultConfig()
+	if *grfPath != "" {
+		conf.GRFPaths = []string{*grfPath}
+	}
+	conf.DataDir = *dataDir
+
+	if err := client.WriteConfigFile(*output, conf, *force); err != nil {
+		if os.IsExist(err) {
+			log.Fatal().Msgf("%s already exists, use -force to overwrite it", *output)
+		}
+		log.Fatal().Err(err).Msg("failed to write config file")
+	}
+
+	log.Info().Str("path", *output).Msg("config file written")
+
+	problems := conf.Check()
+	for _, err := range problems {
+		log.Warn().Err(err).Msg("config check")
+	}
+
+	if len(problems) > 0 {
+		log.Warn().Msgf("edit %s to fix the problems above", *output)
+		os.Exit(1)
+	}
+}
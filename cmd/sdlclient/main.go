@@ -1,52 +1,179 @@
 package main
 
 import (
-	"fmt"
+	"bytes"
+	"context"
+	"flag"
+	"io"
 	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"runtime/trace"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/EngoEngine/ecs"
-	"github.com/davecgh/go-spew/spew"
-	"github.com/go-gl/gl/v3.2-core/gl"
-	"github.com/go-gl/mathgl/mgl32"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/veandco/go-sdl2/sdl"
 
+	"github.com/project-midgard/midgarts/internal/asset"
+	"github.com/project-midgard/midgarts/internal/audio"
 	"github.com/project-midgard/midgarts/internal/camera"
 	"github.com/project-midgard/midgarts/internal/character"
 	"github.com/project-midgard/midgarts/internal/character/directiontype"
-	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
-	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/console"
+	"github.com/project-midgard/midgarts/internal/crash"
+	"github.com/project-midgard/midgarts/internal/debugserver"
 	"github.com/project-midgard/midgarts/internal/entity"
-	"github.com/project-midgard/midgarts/internal/fileformat/gat"
-	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/gl"
 	"github.com/project-midgard/midgarts/internal/graphic/caching"
+	"github.com/project-midgard/midgarts/internal/i18n"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/internal/metrics"
+	"github.com/project-midgard/midgarts/internal/network/capture"
+	"github.com/project-midgard/midgarts/internal/replay"
+	"github.com/project-midgard/midgarts/internal/scene"
+	"github.com/project-midgard/midgarts/internal/service"
+	"github.com/project-midgard/midgarts/internal/snapshot"
 	"github.com/project-midgard/midgarts/internal/system"
-	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/timestep"
+	"github.com/project-midgard/midgarts/internal/touch"
 	"github.com/project-midgard/midgarts/internal/window"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+	"github.com/project-midgard/midgarts/pkg/plugin"
 	"github.com/project-midgard/midgarts/pkg/version"
 )
 
-const (
-	WindowWidth  = 960
-	WindowHeight = 720
-	AspectRatio  = float32(WindowWidth) / float32(WindowHeight)
-	FPS          = 60
+var (
+	configFlags = client.RegisterFlags(flag.CommandLine).RegisterClientFlags()
+
+	debugAddr     = flag.String("debug-addr", "", "serve the pprof endpoints on this address (e.g. localhost:6060)")
+	tracePath     = flag.String("trace", "", "capture a runtime trace of the whole session to this file")
+	hotReload     = flag.Bool("hot-reload", false, "reload the sprites of the data folder when they change")
+	logLevel      = flag.String("log-level", "trace", "minimum level of the logs")
+	logLevels     = flag.String("log-levels", "", "comma separated subsystem levels, e.g. render=debug,assetreload=warn")
+	scriptPath    = flag.String("script", "", "run this Lua script, see internal/script")
+	cinematicPath = flag.String("cinematic", "", "play the timeline of this YAML file, see internal/cinematic")
+	pluginPaths   = flag.String("plugins", "", "comma separated Go plugin files (.so) to load")
+	recordPath    = flag.String("record", "", "record the input and network events of the session to this file")
+	replayPath    = flag.String("replay", "", "play back the events of this recording instead of the input, then quit")
+	crashDir      = flag.String("crash-dir", "", "folder of the crash reports, the working directory by default")
+	restorePath   = flag.String("snapshot", "", "restore the characters and the camera of this snapshot file (saved with F5)")
+	weatherKind   = flag.String("weather", "", "force the weather of the map: rain, snow, clouds or none")
+	weatherMaps   = flag.String("weather-maps", "", "YAML file of the weather of the maps, replacing the default one, see internal/weather")
+	effectTable   = flag.String("effects", "", "YAML table of effects, extending or replacing the default EF_* table, see internal/effect")
+	headgearTable = flag.String("headgears", "", "table of the accessory names of the headgears by view id, made of id#name# lines")
+	postPasses    = flag.String("post", "", "comma separated post-processing passes: crt, bloom, grading, grayscale or .frag files, see internal/system/opengl")
+	partyDemo     = flag.Bool("party", false, "demo: a party of different jobs walking in formation around the start of the map")
+	bots          = flag.Bool("bots", false, "let the characters of the map act on their own: wander, follow the player and attack it")
+	crowd         = flag.Int("crowd", 0, "stress test: spawn this many animated characters, report the frame times after -crowd-duration, then quit")
+	crowdTime     = flag.Duration("crowd-duration", 30*time.Second, "time the -crowd frames are recorded, after a warmup of "+crowdWarmup.String())
+	startMap      = flag.String("map", "izlude", "name of the map to start on")
+	capturePath   = flag.String("capture", "", "replay the zone session of this pcap capture on the map: its entities spawn, walk, chat and vanish as captured")
+	capturePort   = flag.Int("capture-port", capture.DefaultPort, "port of the map server of the -capture session")
+	accountName   = flag.String("account", "", "play with the profile of this account, see the profiles of the configuration")
+	characterName = flag.String("character", "", "play with the profile of this character, over the one of its account")
 )
 
-var (
-	GrfFilePath = os.Getenv("GRF_FILE_PATH")
+const (
+	// itemTablePath is the table of the item sprites, see
+	// entity.Factory.LoadItemTable.
+	itemTablePath = "data/idnum2itemresnametable.txt"
+	// msgStringTablePath holds the strings of the data, see
+	// i18n.Catalog.LoadMsgStringTable.
+	msgStringTablePath = "data/msgstringtable.txt"
+	// crowdWarmup is the time the -crowd frames are not recorded, while
+	// the sprites of the crowd load.
+	crowdWarmup = 5 * time.Second
 )
 
 func init() {
-	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	// SDL and OpenGL are called from the main thread only, see the
+	// threading model of internal/service.
+	runtime.LockOSThread()
+
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 }
 
 func main() {
-	var err error
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		runInit(os.Args[2:])
+		return
+	}
+
+	flag.Parse()
+
+	level, err := zerolog.ParseLevel(*logLevel)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid -log-level")
+	}
+
+	subsystemLevels, err := logging.ParseLevels(*logLevels)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid -log-levels")
+	}
+
+	// The subsystems may log below the default level, see logging.Levels.
+	log.Logger = log.Logger.Level(level)
+	zerolog.SetGlobalLevel(subsystemLevels.Lowest(level))
+
+	conf, err := configFlags.Load()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+	windowWidth, windowHeight := conf.Graphics.Width, conf.Graphics.Height
+
+	userProfiles, err := newProfiles(conf)
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+	uiTheme, err := conf.Accessibility.Theme()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid accessibility settings")
+	}
+
+	if *hotReload && conf.DataDir == "" {
+		log.Fatal().Msg("hot reload requires a data folder (data_dir, DATA_DIR or -data-dir)")
+	}
+
+	// Cancels the asset loading when interrupted or when the window closes.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	ctx = logging.WithLevels(ctx, subsystemLevels)
+
+	if *debugAddr != "" {
+		debugServer, err := debugserver.Start(ctx, *debugAddr)
+		if err != nil {
+			log.Fatal().Err(err).Send()
+		}
+		defer debugServer.Close()
+	}
+
+	if *tracePath != "" {
+		stopTrace, err := debugserver.StartTrace(*tracePath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to start trace")
+		}
+		defer func() {
+			if err := stopTrace(); err != nil {
+				log.Error().Err(err).Msg("failed to write trace")
+			}
+		}()
+	}
+
+	catalog, text, err := loadText(conf)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load the language packs")
+	}
+
 	if err = sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 		log.Fatal().Err(err).Msg("failed to load sdl")
 	}
@@ -57,14 +184,19 @@ func main() {
 		log.Fatal().Err(err).Msg("getting desktop display mode")
 	}
 
+	var windowFlags uint32 = sdl.WINDOW_OPENGL
+	if conf.Graphics.Fullscreen {
+		windowFlags |= sdl.WINDOW_FULLSCREEN
+	}
+
 	var win *sdl.Window
 	if win, err = sdl.CreateWindow(
-		fmt.Sprintf("Midgarts Client - %s", version.Get()),
-		desktop.W-WindowWidth,
+		text.T(i18n.WindowTitle, version.Get()),
+		desktop.W-int32(windowWidth),
 		0,
-		WindowWidth,
-		WindowHeight,
-		sdl.WINDOW_OPENGL,
+		int32(windowWidth),
+		int32(windowHeight),
+		windowFlags,
 	); err != nil {
 		panic(err)
 	}
@@ -72,222 +204,412 @@ func main() {
 		_ = win.Destroy()
 	}()
 
-	err = sdl.GLSetAttribute(sdl.GL_CONTEXT_MAJOR_VERSION, 3)
+	err = sdl.GLSetAttribute(sdl.GL_CONTEXT_MAJOR_VERSION, gl.MajorVersion)
 	if err != nil {
 		panic(err)
 	}
 
-	err = sdl.GLSetAttribute(sdl.GL_CONTEXT_MINOR_VERSION, 2)
+	err = sdl.GLSetAttribute(sdl.GL_CONTEXT_MINOR_VERSION, gl.MinorVersion)
 	if err != nil {
 		panic(err)
 	}
 
-	err = sdl.GLSetAttribute(sdl.GL_CONTEXT_PROFILE_MASK, sdl.GL_CONTEXT_PROFILE_CORE)
+	// The ES context of the gles builds, as on Android (see internal/gl).
+	profile := sdl.GL_CONTEXT_PROFILE_CORE
+	if gl.ES {
+		profile = sdl.GL_CONTEXT_PROFILE_ES
+	}
+	err = sdl.GLSetAttribute(sdl.GL_CONTEXT_PROFILE_MASK, profile)
 	if err != nil {
 		panic(err)
 	}
 
-	context, err := win.GLCreateContext()
+	glContext, err := win.GLCreateContext()
 	if err != nil {
 		panic(err)
 	}
-	defer sdl.GLDeleteContext(context)
+	defer sdl.GLDeleteContext(glContext)
 
 	if err := gl.Init(); err != nil {
 		panic(err)
 	}
+
+	swapInterval := 0
+	if conf.Graphics.VSync {
+		swapInterval = 1
+	}
+	if err = sdl.GLSetSwapInterval(swapInterval); err != nil {
+		log.Warn().Err(err).Bool("vsync", conf.Graphics.VSync).Msg("could not set the swap interval")
+	}
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	log.Info().Msgf("OpenGL version: %s", version)
 
-	var grfFile *grf.File
-	if grfFile, err = grf.Load(GrfFilePath); err != nil {
+	grfFile, err := vfs.Open(ctx, conf.DataDir, conf.GRFPaths...)
+	if err != nil {
 		log.Fatal().Err(err).Msg("failed to load grf file")
 	}
 
-	e, err := grfFile.GetEntry("data/izlude.gat")
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed to get gat entry")
+	if e, err := grfFile.GetEntry(msgStringTablePath); err != nil {
+		log.Debug().Err(err).Msg("no msgstringtable")
+	} else if err = catalog.LoadMsgStringTable(conf.Language, bytes.NewReader(e.Data), encoding.DecodeCP949); err != nil {
+		log.Error().Err(err).Msg("failed to load the msgstringtable")
 	}
-	groundAltitude, err := gat.Load(e.Data)
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed to load gat")
+
+	var mixer audio.Mixer
+	if !conf.Audio.Mute {
+		if m, err := openMixer(conf.Audio, grfFile); err != nil {
+			log.Warn().Err(err).Msg("could not open the audio, no sound is played")
+		} else {
+			defer m.Close()
+			mixer = m
+		}
+	}
+	musics := audio.MusicTable{}
+	if e, err := grfFile.GetEntry(audio.MusicTablePath); err != nil {
+		log.Debug().Err(err).Msg("no music table, the maps have no music")
+	} else if musics, err = audio.LoadMusicTable(bytes.NewReader(e.Data)); err != nil {
+		log.Error().Err(err).Msg("failed to load the music table")
 	}
-	_ = groundAltitude
 
-	gl.Viewport(0, 0, WindowWidth, WindowHeight)
+	gl.Viewport(0, 0, int32(windowWidth), int32(windowHeight))
 
-	cam := camera.NewPerspectiveCamera(0.638, AspectRatio, 0.1, 1000.0)
-	cam.ResetAngleAndY(WindowWidth, WindowHeight)
+	cam := camera.NewPerspectiveCamera(0.638, float32(windowWidth)/float32(windowHeight), 0.1, 1000.0)
+	cam.ResetAngleAndY(int32(windowWidth), int32(windowHeight))
 
 	ks := window.NewKeyState(win)
 
-	w := ecs.World{}
-	renderSys := system.NewCharacterRenderSystem(grfFile, caching.NewCachedTextureProvider())
-	actionSystem := system.NewCharacterActionSystem(grfFile)
-
-	c1 := entity.NewCharacter(character.Male, jobspriteid.Knight, 23)
-	c1.HasShield = true
-	c2 := entity.NewCharacter(character.Male, jobspriteid.Knight, 22)
-	c2.HasShield = true
-	c3 := entity.NewCharacter(character.Male, jobspriteid.Swordsman, 14)
-	c3.HasShield = true
-	c4 := entity.NewCharacter(character.Female, jobspriteid.Alchemist, 16)
-	c5 := entity.NewCharacter(character.Male, jobspriteid.Bard, 19)
-	c6 := entity.NewCharacter(character.Female, jobspriteid.MonkH, 27)
-	c7 := entity.NewCharacter(character.Male, jobspriteid.Crusader2, 30)
-	c8 := entity.NewCharacter(character.Male, jobspriteid.Assassin, 17)
-	c9 := entity.NewCharacter(character.Male, jobspriteid.Monk, 15)
-	c10 := entity.NewCharacter(character.Female, jobspriteid.Wizard, 19)
-	c11 := entity.NewCharacter(character.Female, jobspriteid.Sage, 4)
-	c12 := entity.NewCharacter(character.Female, jobspriteid.Dancer, 16)
-
-	c1.SetPosition(mgl32.Vec3{0, 44, -1})
-	c2.SetPosition(mgl32.Vec3{4, 44, 0})
-	c3.SetPosition(mgl32.Vec3{8, 44, 0})
-	c4.SetPosition(mgl32.Vec3{0, 40, 0})
-	c5.SetPosition(mgl32.Vec3{4, 40, 0})
-	c6.SetPosition(mgl32.Vec3{8, 40, 0})
-	c7.SetPosition(mgl32.Vec3{0, 36, 0})
-	c8.SetPosition(mgl32.Vec3{4, 36, 0})
-	c9.SetPosition(mgl32.Vec3{8, 36, 0})
-	c10.SetPosition(mgl32.Vec3{0, 32, 0})
-	c11.SetPosition(mgl32.Vec3{4, 32, 0})
-	c12.SetPosition(mgl32.Vec3{8, 32, 0})
-
-	var actionable *system.CharacterActionable
-	var renderable *system.CharacterRenderable
-	w.AddSystemInterface(actionSystem, actionable, nil)
-	w.AddSystemInterface(renderSys, renderable, nil)
-	w.AddSystem(opengl.NewOpenGLRenderSystem(cam, renderSys.RenderCommands))
-
-	w.AddEntity(c1)
-	w.AddEntity(c2)
-	w.AddEntity(c3)
-	w.AddEntity(c4)
-	w.AddEntity(c5)
-	w.AddEntity(c6)
-	w.AddEntity(c7)
-	w.AddEntity(c8)
-	w.AddEntity(c9)
-	w.AddEntity(c10)
-	w.AddEntity(c11)
-	w.AddEntity(c12)
-
-	c1.SetState(statetype.StandBy)
+	textureProvider := caching.NewCachedTextureProvider(conf.Graphics.TextureBudget << 20)
+	if textureProvider.Filters, err = conf.Graphics.Filters(); err != nil {
+		log.Fatal().Err(err).Msg("invalid graphics settings")
+	}
+	assets := asset.NewManager(ctx, grfFile, textureProvider, asset.DefaultWorkers)
+	defer assets.Close()
 
-	shouldStop := false
+	bus := event.NewBus()
+
+	reporter := crash.NewReporter(*crashDir)
+	reporter.Watch(bus)
+	defer reporter.Recover()
+
+	pluginHost, err := loadPlugins(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load plugins")
+	}
+
+	mapName := *startMap
+	var restored *snapshot.Snapshot
+	if *restorePath != "" {
+		if restored, err = snapshot.Load(*restorePath); err != nil {
+			log.Fatal().Err(err).Send()
+		}
+		if restored.Map != "" {
+			mapName = restored.Map
+		}
+	}
+
+	factory := entity.NewFactory()
+	if e, err := grfFile.GetEntry(itemTablePath); err != nil {
+		log.Debug().Err(err).Msg("no item table, items are not available")
+	} else if err = factory.LoadItemTable(bytes.NewReader(e.Data)); err != nil {
+		log.Error().Err(err).Msg("failed to load the item table")
+	}
+	if *headgearTable != "" {
+		f, err := os.Open(*headgearTable)
+		if err == nil {
+			err = character.LoadHeadgearTable(f)
+			_ = f.Close()
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load the headgear table")
+		}
+	}
+
+	scenes := scene.NewManager(ctx, assets, &loadingScene{win: win, assets: assets, text: text})
+	con := console.New()
+	registerCommands(con, scenes)
+	scenes.Switch(&mapScene{
+		mapName:     mapName,
+		snapshot:    restored,
+		frameBudget: time.Duration(conf.Graphics.FrameBudget) * time.Millisecond,
+		lodDistance: conf.Graphics.LODDistance,
+		crowd:       *crowd,
+		quit:        cancel,
+		services: &service.Services{
+			Ctx:          ctx,
+			GRF:          grfFile,
+			Assets:       assets,
+			Textures:     textureProvider,
+			Bus:          bus,
+			Camera:       cam,
+			Factory:      factory,
+			Plugins:      pluginHost,
+			Text:         text,
+			Console:      con,
+			Theme:        uiTheme,
+			Audio:        system.NewAudioSystem(ctx, mixer, musics, nil),
+			WindowWidth:  windowWidth,
+			WindowHeight: windowHeight,
+		},
+		win:      win,
+		ks:       ks,
+		profiles: userProfiles,
+	})
+	defer func() {
+		if current := scenes.Current(); current != nil {
+			current.Exit()
+		}
+	}()
 
-	var refreshPeriod = time.Second / FPS
+	bus.Subscribe(func(e event.KeyChanged) {
+		ks.Set(sdl.Keycode(e.Key), e.Pressed)
+	})
+	bus.Subscribe(func(e event.PacketReceived) {
+		metrics.PacketsReceived.Add(1)
+		metrics.PacketRate.Add(1)
+	})
+
+	var recorder *replay.Recorder
+	if *recordPath != "" {
+		f, err := os.Create(*recordPath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to create recording")
+		}
+		defer f.Close()
 
-	for !shouldStop {
-		frameStart := time.Now()
-		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-			switch eventType := event.(type) {
-			case *sdl.QuitEvent:
+		recorder = replay.NewRecorder(f, bus)
+		defer recorder.Close()
+	}
+
+	var player *replay.Player
+	if *replayPath != "" {
+		f, err := os.Open(*replayPath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to open recording")
+		}
+		defer f.Close()
+
+		player = replay.NewPlayer(f)
+	}
+
+	shouldStop := false
+	// background is set while the app is not shown, on the mobile systems:
+	// nothing is updated nor drawn until it is back.
+	background := false
+	gestures := touch.NewRecognizer()
+
+	limiter := timestep.NewLimiter(conf.Graphics.FPS)
+
+	lastFrame := time.Now()
+	for !shouldStop && ctx.Err() == nil {
+		frameRegion := trace.StartRegion(ctx, "frame")
+		for ev := sdl.PollEvent(); ev != nil; ev = sdl.PollEvent() {
+			if _, ok := ev.(*sdl.QuitEvent); ok {
 				println("Quit")
 				shouldStop = true
-				break
-			case *sdl.MouseButtonEvent:
-				halfWidth := WindowWidth / 2
-				halfHeight := WindowHeight / 2
-
-				if eventType.Type == sdl.MOUSEBUTTONDOWN {
-					if int(eventType.X) < halfWidth {
-						if int(eventType.Y) < halfHeight {
-							c1.Direction = directiontype.NorthWest
-						} else if int(eventType.Y) > halfHeight {
-							c1.Direction = directiontype.SouthWest
-						} else {
-							c1.Direction = directiontype.West
-						}
-					}
-
-					if int(eventType.X) > halfWidth {
-						if int(eventType.Y) < halfHeight {
-							c1.Direction = directiontype.NorthEast
-						} else if int(eventType.Y) > halfHeight {
-							c1.Direction = directiontype.SouthEast
-						} else {
-							c1.Direction = directiontype.East
-						}
-					}
-
-					spew.Dump(eventType)
-					//}
-					break
+				cancel()
+			} else if ev, ok := ev.(*sdl.CommonEvent); ok {
+				switch ev.Type {
+				case sdl.APP_TERMINATING:
+					shouldStop = true
+					cancel()
+				case sdl.APP_LOWMEMORY:
+					log.Warn().Msg("low memory")
+					debug.FreeOSMemory()
+				case sdl.APP_WILLENTERBACKGROUND:
+					background = true
+				case sdl.APP_DIDENTERFOREGROUND:
+					background = false
+					// The time in the background is not played.
+					lastFrame = time.Now()
 				}
+			} else if player == nil && !typeConsole(con, userProfiles.keys, ev) {
+				publishInput(bus, gestures, userProfiles.keys, ev, windowWidth, windowHeight)
 			}
+		}
 
-			ks.Update(event)
+		if background {
+			frameRegion.End()
+			sdl.Delay(100)
+			continue
 		}
 
-		const MovementRate = float32(0.065)
-
-		p1 := c1.Position()
-
-		// char controls
-		if ks.Pressed(sdl.K_w) && ks.Pressed(sdl.K_d) {
-			c1.Direction = directiontype.NorthEast
-			c1.SetState(statetype.Walking)
-			c1.SetPosition(mgl32.Vec3{p1.X() - MovementRate, p1.Y() + MovementRate, p1.Z()})
-		} else if ks.Pressed(sdl.K_w) && ks.Pressed(sdl.K_a) {
-			c1.Direction = directiontype.NorthWest
-			c1.SetState(statetype.Walking)
-			c1.SetPosition(mgl32.Vec3{p1.X() + MovementRate, p1.Y() + MovementRate, p1.Z()})
-		} else if ks.Pressed(sdl.K_s) && ks.Pressed(sdl.K_d) {
-			c1.Direction = directiontype.SouthEast
-			c1.SetState(statetype.Walking)
-			c1.SetPosition(mgl32.Vec3{p1.X() - MovementRate, p1.Y() - MovementRate, p1.Z()})
-		} else if ks.Pressed(sdl.K_s) && ks.Pressed(sdl.K_a) {
-			c1.Direction = directiontype.SouthWest
-			c1.SetPosition(mgl32.Vec3{p1.X() + MovementRate, p1.Y() - MovementRate, p1.Z()})
-			c1.SetState(statetype.Walking)
-		} else if ks.Pressed(sdl.K_w) {
-			c1.Direction = directiontype.North
-			c1.SetState(statetype.Walking)
-			c1.SetPosition(mgl32.Vec3{p1.X(), p1.Y() + MovementRate, p1.Z()})
-		} else if ks.Pressed(sdl.K_s) {
-			c1.Direction = directiontype.South
-			c1.SetState(statetype.Walking)
-			c1.SetPosition(mgl32.Vec3{p1.X(), p1.Y() - MovementRate, p1.Z()})
-		} else if ks.Pressed(sdl.K_d) {
-			c1.Direction = directiontype.East
-			c1.SetState(statetype.Walking)
-			c1.SetPosition(mgl32.Vec3{p1.X() - MovementRate, p1.Y(), p1.Z()})
-		} else if ks.Pressed(sdl.K_a) {
-			c1.Direction = directiontype.West
-			c1.SetState(statetype.Walking)
-			c1.SetPosition(mgl32.Vec3{p1.X() + MovementRate, p1.Y(), p1.Z()})
-			//
-			//p2 := c2.Position()
-			//c2.Direction = directiontype.West
-			//c2.SetState(statetype.Walking)
-			//c2.SetPosition(mgl32.Vec3{p2.X() + MovementRate, p2.Y(), p2.Z()})
-		} else {
-			//c1.SetState(statetype.StandBy)
-			c1.SetState(statetype.StandBy)
+		now := time.Now()
+		frameDelta := float32(now.Sub(lastFrame).Seconds())
+		metrics.FrameTime.ObserveDuration(now.Sub(lastFrame))
+		lastFrame = now
+
+		if player != nil {
+			if frameDelta, err = player.NextFrame(bus); err == io.EOF {
+				log.Info().Msg("replay finished")
+				break
+			} else if err != nil {
+				log.Fatal().Err(err).Msg("failed to replay")
+			}
 		}
 
-		// camera controls
-		if ks.Pressed(sdl.K_z) {
-			cam.SetPosition(mgl32.Vec3{cam.Position().X(), cam.Position().Y(), cam.Position().Z() + 0.2})
-		} else if ks.Pressed(sdl.K_x) {
-			cam.SetPosition(mgl32.Vec3{cam.Position().X(), cam.Position().Y(), cam.Position().Z() - 0.2})
-		} else if ks.Pressed(sdl.K_c) {
-			cam.SetPosition(mgl32.Vec3{cam.Position().X() - 0.2, cam.Position().Y(), cam.Position().Z()})
-		} else if ks.Pressed(sdl.K_v) {
-			cam.SetPosition(mgl32.Vec3{cam.Position().X() + 0.2, cam.Position().Y(), cam.Position().Z()})
+		bus.Update(frameDelta)
+		if recorder != nil {
+			if err = recorder.EndFrame(frameDelta); err != nil {
+				log.Error().Err(err).Msg("recording stopped")
+				recorder.Close()
+				recorder = nil
+			}
+		}
+		assets.Update(frameDelta)
+		if report := reporter.Capture(func() { scenes.Update(frameDelta) }); report != nil {
+			showCrash(win, text, report)
+			break
 		}
 
-		//c2.SetState(statetype.StandBy)
+		win.GLSwap()
+		textureProvider.NextFrame()
+		frameRegion.End()
 
-		frameStart = time.Now()
-		frameDelta := frameStart.Sub(frameStart)
+		limiter.Wait()
+	}
+}
 
-		w.Update(float32(frameDelta.Seconds()))
+// loadText returns the catalog of the language packs, with the ones of
+// the language folder, and the translator of the configured language.
+func loadText(conf client.Config) (*i18n.Catalog, *i18n.Translator, error) {
+	catalog, err := i18n.NewCatalog()
+	if err != nil {
+		return nil, nil, err
+	}
 
-		win.GLSwap()
+	if conf.LanguageDir != "" {
+		if err = catalog.LoadDir(conf.LanguageDir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return catalog, catalog.Translator(conf.Language), nil
+}
 
-		time.Sleep(refreshPeriod)
+// showCrash tells the player where the report of a crash was written.
+func showCrash(win *sdl.Window, text *i18n.Translator, report *crash.Report) {
+	if report.Path == "" {
+		return
 	}
+
+	if err := sdl.ShowSimpleMessageBox(sdl.MESSAGEBOX_ERROR, text.T(i18n.CrashTitle), text.T(i18n.CrashMessage, report.Path), win); err != nil {
+		log.Error().Err(err).Msg("could not show the crash dialog")
+	}
+}
+
+// publishInput publishes the input events of an SDL event on bus, the
+// actions of the keys of keys and the touches recognized by gestures in a
+// window of width by height pixels.
+func publishInput(bus *event.Bus, gestures *touch.Recognizer, keys keybinds, ev sdl.Event, width, height int) {
+	switch ev := ev.(type) {
+	case *sdl.KeyboardEvent:
+		if ev.Repeat != 0 {
+			return
+		}
+
+		bus.Publish(event.KeyChanged{Key: int32(ev.Keysym.Sym), Pressed: ev.State == sdl.PRESSED})
+		if ev.Type == sdl.KEYDOWN {
+			switch keys.action(ev.Keysym.Sym) {
+			case client.ActionGATOverlay:
+				bus.Publish(event.GATOverlayToggled{})
+			case client.ActionSnapshot:
+				bus.Publish(event.SnapshotRequested{})
+			case client.ActionPause:
+				bus.Publish(event.TimeScaleToggled{Scale: 0})
+			case client.ActionSlowMotion:
+				bus.Publish(event.TimeScaleToggled{Scale: 0.25})
+			case client.ActionCycleWeather:
+				bus.Publish(event.WeatherCycled{})
+			case client.ActionCycleStatus:
+				bus.Publish(event.StatusCycled{})
+			}
+		}
+	case *sdl.MouseButtonEvent:
+		// The touches are also reported as clicks, handled as taps.
+		if ev.Type == sdl.MOUSEBUTTONDOWN && ev.Which != sdl.TOUCH_MOUSEID {
+			bus.Publish(event.MouseClicked{X: int(ev.X), Y: int(ev.Y)})
+		}
+	case *sdl.TouchFingerEvent:
+		// The fingers are in fractions of the window.
+		x, y := ev.X*float32(width), ev.Y*float32(height)
+		t := time.Duration(ev.Timestamp) * time.Millisecond
+		switch ev.Type {
+		case sdl.FINGERDOWN:
+			gestures.Down(int64(ev.FingerID), x, y, t)
+		case sdl.FINGERMOTION:
+			if pinch, ok := gestures.Move(int64(ev.FingerID), x, y); ok {
+				bus.Publish(event.Pinched{Scale: pinch.Scale})
+			}
+		case sdl.FINGERUP:
+			if tap, ok := gestures.Up(int64(ev.FingerID), x, y, t); ok {
+				bus.Publish(event.Tapped{X: tap.X, Y: tap.Y})
+			}
+		}
+	}
+}
+
+// preloadCharacters requests the attachments of the characters, so they
+// are loaded in the background before the characters are added.
+func preloadCharacters(assets *asset.Scope, chars ...*entity.Character) {
+	for _, char := range chars {
+		paths, err := component.CharacterAttachmentPaths(char.AttachmentConfig())
+		if err != nil {
+			log.Warn().Err(err).Uint64("entity", char.ID()).Msg("could not preload character")
+			continue
+		}
+
+		for _, path := range paths {
+			assets.Preload(path)
+		}
+	}
+}
+
+// loadPlugins returns the host of the plugins registered at build time
+// (see plugins.go) and of the ones given with -plugins.
+func loadPlugins(ctx context.Context) (*plugin.Host, error) {
+	plugins := plugin.Registered()
+
+	if *pluginPaths != "" {
+		for _, path := range strings.Split(*pluginPaths, ",") {
+			p, err := plugin.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			plugins = append(plugins, p)
+		}
+	}
+
+	for _, p := range plugins {
+		log.Info().Str("plugin", p.Name()).Msg("plugin loaded")
+	}
+
+	return plugin.NewHost(ctx, plugins...)
+}
+
+// clickDirection returns the direction of a click at (x, y) from the
+// center of the window, seen from the camera of directions, or direction
+// when the click is on the center column.
+func clickDirection(x, y, windowWidth, windowHeight int, directions pkgcharacter.DirectionResolver, direction directiontype.Type) directiontype.Type {
+	halfWidth := windowWidth / 2
+	halfHeight := windowHeight / 2
+
+	if x < halfWidth {
+		if y < halfHeight {
+			return directions.Facing(directiontype.NorthWest)
+		} else if y > halfHeight {
+			return directions.Facing(directiontype.SouthWest)
+		}
+		return directions.Facing(directiontype.West)
+	}
+
+	if x > halfWidth {
+		if y < halfHeight {
+			return directions.Facing(directiontype.NorthEast)
+		} else if y > halfHeight {
+			return directions.Facing(directiontype.SouthEast)
+		}
+		return directions.Facing(directiontype.East)
+	}
+
+	return direction
 }
@@ -0,0 +1,784 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/project-midgard/midgarts/internal/asset"
+	"github.com/project-midgard/midgarts/internal/audio"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/cinematic"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/effect"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+	"github.com/project-midgard/midgarts/internal/footstep"
+	"github.com/project-midgard/midgarts/internal/gl"
+	"github.com/project-midgard/midgarts/internal/i18n"
+	"github.com/project-midgard/midgarts/internal/lod"
+	"github.com/project-midgard/midgarts/internal/mapground"
+	"github.com/project-midgard/midgarts/internal/mapmodel"
+	"github.com/project-midgard/midgarts/internal/mapstream"
+	"github.com/project-midgard/midgarts/internal/metrics"
+	"github.com/project-midgard/midgarts/internal/script"
+	"github.com/project-midgard/midgarts/internal/service"
+	"github.com/project-midgard/midgarts/internal/snapshot"
+	"github.com/project-midgard/midgarts/internal/stress"
+	"github.com/project-midgard/midgarts/internal/system"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/timestep"
+	"github.com/project-midgard/midgarts/internal/weather"
+	"github.com/project-midgard/midgarts/internal/window"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+)
+
+// mapScene is the scene walking around a map. Its worlds, and the systems
+// of the worlds, live from Enter to Exit: the simulation world is updated
+// at a fixed rate, the render world every frame. Besides its own systems,
+// it installs the registered ones (see service.Register).
+type mapScene struct {
+	mapName string
+	// snapshot, when set, gives the characters and the camera position.
+	snapshot *snapshot.Snapshot
+	// frameBudget is the frame time past which the distant characters are
+	// skipped, see timestep.Budget.
+	frameBudget time.Duration
+	// lodDistance is the camera distance from which the detail of the
+	// characters is lowered, see lod.LOD.
+	lodDistance float32
+	// crowd, when not 0, is the number of characters of the stress test,
+	// see stress.Crowd.
+	crowd int
+	// quit quits the client, once the stress test is recorded.
+	quit func()
+
+	services *service.Services
+	win      *sdl.Window
+	ks       *window.KeyState
+	// profiles holds the keys and the settings of the character playing.
+	profiles *profiles
+
+	// assets holds the sprites of the scene, released on exit.
+	assets     *asset.Scope
+	chars      []*entity.Character
+	worlds     service.Worlds
+	step       *timestep.FixedStep
+	renderSys  *system.CharacterRenderSystem
+	gatOverlay *system.GATOverlaySystem
+	captions   *system.CaptionSystem
+	statuses   *system.StatusSystem
+	console    *system.ConsoleSystem
+	windows    *system.WindowSystem
+	weather    *system.WeatherSystem
+	effectSys  *system.EffectRenderSystem
+	ground     *opengl.ModelBatches
+	models     *system.MapModelSystem
+	glRender   *opengl.RenderSystem
+	effects    *effect.Registry
+	engine     *script.Engine
+	cinematic  *cinematic.Player
+	// scriptFade is the fade set by the scripts and the cinematics.
+	scriptFade float32
+	stress     *stress.Crowd
+	// party is the -party demo, its leader first.
+	party []*entity.Character
+	// session replays the -capture session, when set.
+	session  *system.SessionSystem
+	recorder *stress.Recorder
+	teardown func()
+	unsubs   []func()
+}
+
+// newCharacters returns the characters standing on the map, c1 being the
+// one controlled with the keyboard.
+func newCharacters(factory *entity.Factory) []*entity.Character {
+	c1 := factory.CreatePlayer(character.Male, jobspriteid.Knight, 23)
+	c1.HasShield = true
+	c2 := factory.CreatePlayer(character.Male, jobspriteid.Knight, 22)
+	c2.HasShield = true
+	c3 := factory.CreatePlayer(character.Male, jobspriteid.Swordsman, 14)
+	c3.HasShield = true
+	c4 := factory.CreatePlayer(character.Female, jobspriteid.Alchemist, 16)
+	c5 := factory.CreatePlayer(character.Male, jobspriteid.Bard, 19)
+	c6 := factory.CreatePlayer(character.Female, jobspriteid.MonkH, 27)
+	c7 := factory.CreatePlayer(character.Male, jobspriteid.Crusader2, 30)
+	c8 := factory.CreatePlayer(character.Male, jobspriteid.Assassin, 17)
+	c9 := factory.CreatePlayer(character.Male, jobspriteid.Monk, 15)
+	c10 := factory.CreatePlayer(character.Female, jobspriteid.Wizard, 19)
+	c11 := factory.CreatePlayer(character.Female, jobspriteid.Sage, 4)
+	c12 := factory.CreatePlayer(character.Female, jobspriteid.Dancer, 16)
+
+	c1.SetPosition(mgl32.Vec3{0, 44, -1})
+	c2.SetPosition(mgl32.Vec3{4, 44, 0})
+	c3.SetPosition(mgl32.Vec3{8, 44, 0})
+	c4.SetPosition(mgl32.Vec3{0, 40, 0})
+	c5.SetPosition(mgl32.Vec3{4, 40, 0})
+	c6.SetPosition(mgl32.Vec3{8, 40, 0})
+	c7.SetPosition(mgl32.Vec3{0, 36, 0})
+	c8.SetPosition(mgl32.Vec3{4, 36, 0})
+	c9.SetPosition(mgl32.Vec3{8, 36, 0})
+	c10.SetPosition(mgl32.Vec3{0, 32, 0})
+	c11.SetPosition(mgl32.Vec3{4, 32, 0})
+	c12.SetPosition(mgl32.Vec3{8, 32, 0})
+
+	chars := []*entity.Character{c1, c2, c3, c4, c5, c6, c7, c8, c9, c10, c11, c12}
+
+	poring, err := factory.CreateMonster(1002)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return chars
+	}
+	poring.SetPosition(mgl32.Vec3{4, 28, 0})
+
+	return append(chars, poring)
+}
+
+func (s *mapScene) Name() string {
+	return "map " + s.mapName
+}
+
+func (s *mapScene) Preload(assets *asset.Manager) {
+	s.assets = assets.NewScope()
+	s.chars = s.characters()
+	preloadCharacters(s.assets, s.chars...)
+}
+
+// Release releases the sprites of the scene, preloaded or loaded.
+func (s *mapScene) Release() {
+	if s.assets != nil {
+		s.assets.Release()
+		s.assets = nil
+	}
+}
+
+// characters returns the characters of the scene, and the -party demo, if
+// any.
+func (s *mapScene) characters() []*entity.Character {
+	chars := s.sceneCharacters()
+	if *partyDemo {
+		s.party = newParty(s.services.Factory)
+		chars = append(chars, s.party...)
+	}
+
+	return chars
+}
+
+// sceneCharacters returns the characters of the snapshot, or the default
+// ones and the stress test crowd, if any.
+func (s *mapScene) sceneCharacters() []*entity.Character {
+	if s.crowd > 0 {
+		s.stress = stress.NewCrowd(s.services.Factory, s.crowd, mgl32.Vec3{4, 38, 0})
+		s.recorder = stress.NewRecorder(crowdWarmup)
+
+		return append(newCharacters(s.services.Factory), s.stress.Characters...)
+	}
+
+	if s.snapshot == nil {
+		return newCharacters(s.services.Factory)
+	}
+
+	chars, err := s.snapshot.Restore(nil)
+	if err != nil || len(chars) == 0 {
+		log.Error().Err(err).Msg("could not restore the snapshot characters")
+		return newCharacters(s.services.Factory)
+	}
+
+	return chars
+}
+
+// loadGND returns the ground of the map, nil when it cannot be loaded, the
+// characters then standing on the GAT altitudes.
+func (s *mapScene) loadGND() *gnd.GroundFile {
+	e, err := s.services.GRF.GetEntry("data/" + s.mapName + ".gnd")
+	if err != nil {
+		log.Warn().Err(err).Msg("no gnd, placing the characters on the gat altitudes")
+		return nil
+	}
+
+	ground, err := gnd.Load(e.Data)
+	if err != nil {
+		log.Error().Err(err).Msg("could not load gnd, placing the characters on the gat altitudes")
+		return nil
+	}
+
+	return ground
+}
+
+// loadWorld returns the rsw of the map, nil when it has none or it cannot
+// be loaded: the map has no model nor sound.
+func (s *mapScene) loadWorld() *rsw.ResourceWorldFile {
+	e, err := s.services.GRF.GetEntry("data/" + s.mapName + ".rsw")
+	if err != nil {
+		log.Warn().Err(err).Msg("no rsw, the map has no model nor sound")
+		return nil
+	}
+
+	world, err := rsw.Load(e.Data)
+	if err != nil {
+		log.Error().Err(err).Msg("could not load rsw, the map has no model nor sound")
+		return nil
+	}
+
+	return world
+}
+
+// loadGround returns the mesh of ground, placed around center, nil without
+// ground.
+func (s *mapScene) loadGround(ctx context.Context, ground *gnd.GroundFile, center mgl32.Vec3) *opengl.ModelBatches {
+	if ground == nil {
+		return nil
+	}
+
+	scene := mapground.Build(ground, center)
+	log.Info().Int("chunks", len(scene.Objects)).Int("batches", len(scene.Batches)).Msg("map ground built")
+
+	batches := opengl.NewModelBatches(scene, mapmodel.LoadTextures(ctx, s.services.GRF, scene), s.services.Textures)
+	batches.Visible, batches.DrawCalls = metrics.GroundChunksVisible, metrics.GroundDrawCalls
+
+	return batches
+}
+
+// footsteps returns the system of the steps of the characters, played by
+// the audio system.
+func (s *mapScene) footsteps() *system.FootstepSystem {
+	steps := system.NewFootstepSystem(s.renderSys.Ground, footstep.MapGround(footstep.DefaultMapGrounds, s.mapName))
+	steps.Play = func(_ *entity.Character, step audio.Emitter) {
+		s.services.Audio.Play(step)
+	}
+
+	return steps
+}
+
+// actionSounds returns the system of the sounds of the ACT frames of the
+// characters, played by the audio system.
+func (s *mapScene) actionSounds() *system.ActionSoundSystem {
+	sounds := system.NewActionSoundSystem(s.renderSys.Ground)
+	sounds.Play = func(_ *entity.Character, sound audio.Emitter) {
+		s.services.Audio.Play(sound)
+	}
+
+	return sounds
+}
+
+// ambience returns the system of the ambient sounds of world, a map of
+// width by height cells, started by the audio system.
+func (s *mapScene) ambience(world *rsw.ResourceWorldFile, width, height int) *system.AmbientSoundSystem {
+	var emitters []audio.Emitter
+	if world != nil {
+		emitters = audio.WorldEmitters(world, width, height)
+	}
+	log.Info().Int("sounds", len(emitters)).Msg("map sounds placed")
+
+	ambience := system.NewAmbientSoundSystem(emitters, s.services.Audio.Listener)
+	ambience.Play = s.services.Audio.PlayAmbient
+
+	return ambience
+}
+
+// mapWeather returns the weather of -weather, else the one of the map in
+// the table of -weather-maps, or in the default one.
+func (s *mapScene) mapWeather() (weather.Weather, error) {
+	if *weatherKind != "" {
+		kind, err := weather.Parse(*weatherKind)
+		if err != nil {
+			return weather.Weather{}, errors.Wrap(err, "invalid -weather")
+		}
+		return weather.Weather{Kind: kind}, nil
+	}
+
+	maps := weather.DefaultMaps
+	if *weatherMaps != "" {
+		data, err := ioutil.ReadFile(*weatherMaps)
+		if err != nil {
+			return weather.Weather{}, errors.Wrap(err, "failed to read weather maps")
+		}
+		if maps, err = weather.LoadMaps(data); err != nil {
+			return weather.Weather{}, errors.Wrapf(err, "failed to load weather maps '%s'", *weatherMaps)
+		}
+	}
+
+	return weather.ForMap(maps, s.mapName), nil
+}
+
+// addCharacter adds a character to the running scene.
+func (s *mapScene) addCharacter(char *entity.Character) {
+	s.chars = append(s.chars, char)
+	s.worlds.AddEntity(char)
+}
+
+// saveSnapshot writes the state of the scene to a new file of the working
+// directory.
+func (s *mapScene) saveSnapshot() {
+	path := fmt.Sprintf("snapshot-%d.json", time.Now().Unix())
+	if err := snapshot.Take(s.mapName, s.services.Camera, s.chars...).Save(path); err != nil {
+		log.Error().Err(err).Msg("could not save snapshot")
+		return
+	}
+
+	log.Info().Str("path", path).Msg("snapshot saved")
+}
+
+func (s *mapScene) Enter(ctx context.Context) error {
+	services := s.services
+	e, err := services.GRF.GetEntry("data/" + s.mapName + ".gat")
+	if err != nil {
+		return errors.Wrap(err, "failed to get gat entry")
+	}
+	groundAltitude, err := gat.Load(e.Data)
+	if err != nil {
+		return errors.Wrap(err, "failed to load gat")
+	}
+
+	mapWeather, err := s.mapWeather()
+	if err != nil {
+		return err
+	}
+	if s.effects, err = loadEffects(); err != nil {
+		return err
+	}
+
+	if s.assets == nil {
+		s.assets = services.Assets.NewScope()
+	}
+	if s.chars == nil {
+		s.chars = s.characters()
+	}
+	if s.snapshot != nil {
+		services.Camera.SetPosition(s.snapshot.Camera.Position)
+	}
+	c1 := s.chars[0]
+	c1.SetState(statetype.StandBy)
+
+	s.worlds = service.NewWorlds()
+	s.step = timestep.New(timestep.DefaultRate)
+	s.renderSys = system.NewCharacterRenderSystem(ctx, s.assets, services.Textures)
+	s.renderSys.Budget = timestep.NewBudget(s.frameBudget)
+	s.renderSys.LOD = lod.New(s.lodDistance)
+	s.renderSys.SetFocus(c1)
+	actionSystem := system.NewCharacterActionSystem(ctx, s.assets)
+	s.renderSys.Ground = &system.Ground{GAT: groundAltitude, GND: s.loadGND(), Center: mgl32.Vec3{4, 38, -1}}
+	s.ground = s.loadGround(ctx, s.renderSys.Ground.GND, s.renderSys.Ground.Center)
+	world := s.loadWorld()
+	s.gatOverlay = system.NewGATOverlaySystem(ctx, groundAltitude, services.Textures, s.renderSys.RenderCommands)
+	// Behind the characters.
+	s.gatOverlay.Position = s.renderSys.Ground.Center.Add(mgl32.Vec3{0, 0, 2})
+	s.gatOverlay.SetPalette(services.Theme.Palette)
+	s.captions = system.NewCaptionSystem(services.Textures, s.renderSys.RenderCommands)
+	s.captions.Theme = services.Theme
+	s.statuses = system.NewStatusSystem(services.Textures, s.renderSys.RenderCommands)
+	s.statuses.Theme = services.Theme
+	s.weather = system.NewWeatherSystem(ctx, mapWeather, services.Textures, s.renderSys.RenderCommands)
+	s.weather.Focus = c1
+	s.effectSys = system.NewEffectRenderSystem(ctx, services.GRF, services.Textures, s.renderSys.RenderCommands)
+	// The sounds are heard from the player.
+	ground := s.renderSys.Ground
+	services.Audio.Listener = func() audio.Listener {
+		x, y := ground.Cell(c1.Position())
+		return audio.NewListener(x, y, services.Camera.OrbitYaw())
+	}
+	services.Audio.PlayBGM(s.mapName)
+
+	bus, width, height := services.Bus, services.WindowWidth, services.WindowHeight
+	s.unsubs = append(s.unsubs,
+		s.renderSys.SubscribePicking(bus, services.Camera, width, height),
+		s.gatOverlay.Subscribe(bus),
+		s.weather.Subscribe(bus),
+		s.subscribeEffects(bus),
+		s.statuses.Subscribe(bus),
+		s.subscribeStatuses(bus, c1),
+		bus.Subscribe(func(e event.CharacterPicked) {
+			log.Info().Uint64("id", e.Character.ID()).Str("job", e.Character.JobSpriteID.String()).Msg("character picked")
+		}),
+		bus.Subscribe(func(e event.GroundClicked) {
+			c1.Direction = clickDirection(e.X, e.Y, width, height, pkgcharacter.DirectionResolver{Yaw: float64(services.Camera.OrbitYaw())}, c1.Direction)
+		}),
+		// A tap picks a character as a click does, else walks to the ground
+		// point tapped.
+		bus.Subscribe(func(e event.Tapped) {
+			if picked := s.renderSys.PickCharacter(services.Camera, width, height, e.X, e.Y); picked != nil {
+				bus.Publish(event.CharacterPicked{Character: picked})
+				return
+			}
+
+			if p, ok := services.Camera.GroundPoint(e.X, e.Y, width, height, c1.Position().Z()); ok {
+				c1.WalkTo(mgl32.Vec2{p.X(), p.Y()})
+			}
+		}),
+		bus.Subscribe(func(e event.Pinched) {
+			services.Camera.Zoom(e.Scale)
+		}),
+		bus.Subscribe(func(e event.SnapshotRequested) {
+			s.saveSnapshot()
+		}),
+		bus.Subscribe(func(e event.TimeScaleToggled) {
+			if s.step.Scale == e.Scale {
+				s.step.Scale = 1
+			} else {
+				s.step.Scale = e.Scale
+			}
+		}),
+	)
+
+	if *bots || s.party != nil {
+		aiSystem := system.NewCharacterAISystem(s.renderSys.Ground)
+		if *bots {
+			populate(aiSystem, c1, s.chars)
+		}
+		if s.party != nil {
+			march(aiSystem, s.party)
+		}
+		s.worlds.Simulation.AddSystem(aiSystem)
+	}
+
+	if *capturePath != "" {
+		if s.session, err = s.replaySession(c1); err != nil {
+			s.Exit()
+			return err
+		}
+		s.worlds.Simulation.AddSystem(s.session)
+	}
+
+	var actionable *system.CharacterActionable
+	var renderable *system.CharacterRenderable
+	// Before the walk animations start.
+	s.worlds.Simulation.AddSystemInterface(s.footsteps(), renderable, nil)
+	s.worlds.Simulation.AddSystemInterface(s.actionSounds(), renderable, nil)
+	s.worlds.Simulation.AddSystemInterface(actionSystem, actionable, nil)
+	// After the moves of the tick.
+	s.worlds.Simulation.AddSystemInterface(system.NewGroundPlacementSystem(s.renderSys.Ground), renderable, nil)
+	s.worlds.Render.AddSystemInterface(s.renderSys, renderable, nil)
+	s.worlds.Render.AddSystem(s.gatOverlay)
+	s.worlds.Render.AddSystem(s.captions)
+	s.worlds.Render.AddSystemInterface(s.statuses, renderable, nil)
+	s.worlds.Render.AddSystem(s.weather)
+	s.worlds.Render.AddSystem(s.effectSys)
+	s.worlds.Render.AddSystem(s.ambience(world, int(groundAltitude.Width), int(groundAltitude.Height)))
+	// The sounds are decoded while the simulation updates.
+	s.worlds.Concurrent.Add(services.Audio)
+	s.glRender = opengl.NewOpenGLRenderSystem(ctx, services.Camera, s.renderSys.RenderCommands)
+	s.glRender.Ground = s.ground
+	if err = s.postProcess(services.Plugins); err != nil {
+		s.Exit()
+		return err
+	}
+	if world != nil {
+		streamer := mapstream.NewStreamer(ctx, services.GRF, world, int(groundAltitude.Width), int(groundAltitude.Height))
+		s.models = system.NewMapModelSystem(ctx, streamer, services.GRF, s.renderSys.Ground, services.Camera, s.glRender, services.Textures)
+		s.worlds.Render.AddSystem(s.models)
+	}
+	if services.Plugins != nil {
+		// Under the console.
+		s.windows = system.NewWindowSystem(services.Plugins.Windows(), services.Textures, s.renderSys.RenderCommands, width, height)
+		s.worlds.Render.AddSystem(s.windows)
+	}
+	s.console = system.NewConsoleSystem(services.Console, services.Textures, s.renderSys.RenderCommands, width, height)
+	s.console.Theme = services.Theme
+	s.unsubs = append(s.unsubs, s.registerCommands(services.Console)...)
+	s.worlds.Render.AddSystem(s.console)
+	s.worlds.Render.AddSystem(s.glRender)
+
+	// There is no login yet: the player enters as the -account and
+	// -character profiles.
+	s.unsubs = append(s.unsubs, s.subscribeProfile(bus))
+	bus.Publish(event.CharacterEntered{Account: *accountName, Name: *characterName})
+
+	if s.teardown, err = service.Install(services, s.worlds); err != nil {
+		s.Exit()
+		return err
+	}
+
+	for _, char := range s.chars {
+		s.worlds.AddEntity(char)
+	}
+
+	api := &scriptAPI{scene: s, factory: services.Factory, cam: services.Camera, win: s.win}
+	if *scriptPath != "" {
+		source, err := ioutil.ReadFile(*scriptPath)
+		if err != nil {
+			s.Exit()
+			return errors.Wrap(err, "failed to read script")
+		}
+
+		s.engine = script.NewEngine(ctx, api)
+
+		s.worlds.Simulation.AddSystem(s.engine)
+		if err = s.engine.RunString(*scriptPath, string(source)); err != nil {
+			log.Error().Err(err).Send()
+		}
+	}
+
+	if *cinematicPath != "" {
+		source, err := ioutil.ReadFile(*cinematicPath)
+		if err != nil {
+			s.Exit()
+			return errors.Wrap(err, "failed to read cinematic")
+		}
+		timeline, err := cinematic.Load(source)
+		if err != nil {
+			s.Exit()
+			return errors.Wrapf(err, "failed to load cinematic '%s'", *cinematicPath)
+		}
+
+		s.cinematic = cinematic.NewPlayer(ctx, api, timeline)
+		s.worlds.Simulation.AddSystem(s.cinematic)
+	}
+
+	return nil
+}
+
+// SetFade darkens the frame with the fade of the scene transitions, or the
+// one of the scripts when darker.
+func (s *mapScene) SetFade(fade float32) {
+	if s.scriptFade > fade {
+		fade = s.scriptFade
+	}
+	s.renderSys.RenderCommands.Fade = fade
+}
+
+func (s *mapScene) Update(dt float32) {
+	for ticks := s.step.Advance(dt); ticks > 0; ticks-- {
+		start := time.Now()
+		s.renderSys.Tick()
+		s.control(s.step.Tick)
+		if s.stress != nil {
+			s.stress.Update()
+		}
+		s.worlds.Simulation.Update(s.step.Tick)
+		metrics.SimulationTime.Since(start)
+	}
+
+	if s.cinematic != nil && s.cinematic.Done() {
+		log.Info().Dur("elapsed", s.cinematic.Elapsed()).Msg("cinematic played")
+		s.cinematic = nil
+	}
+
+	start := time.Now()
+	s.moveCamera(dt)
+	s.renderSys.SetAlpha(s.step.Alpha())
+	s.renderSys.SetCameraYaw(s.services.Camera.OrbitYaw())
+	s.renderSys.SetCameraDistance(s.services.Camera.Distance())
+	s.worlds.Render.Update(dt)
+	metrics.RenderTime.Since(start)
+
+	if s.recorder != nil {
+		s.record(dt)
+	}
+}
+
+// record records a frame of dt seconds of the stress test, quitting with
+// the report once -crowd-duration is recorded.
+func (s *mapScene) record(dt float32) {
+	s.recorder.Frame(time.Duration(float64(dt) * float64(time.Second)))
+	if s.recorder.Duration() < *crowdTime {
+		return
+	}
+
+	report := s.recorder.Report()
+	log.Info().
+		Int("characters", len(s.chars)).
+		Int("frames", report.Frames).
+		Float64("fps", report.FPS).
+		Dur("p50", report.P50).
+		Dur("p95", report.P95).
+		Dur("p99", report.P99).
+		Dur("max", report.Max).
+		Float64("allocs_per_frame", report.AllocsPerFrame).
+		Float64("alloc_bytes_per_second", report.AllocBytesPerSecond).
+		Uint32("gcs", report.GCs).
+		Msg("stress test")
+
+	s.recorder = nil
+	s.quit()
+}
+
+// control moves the first character with the keyboard, for a tick of dt
+// seconds.
+func (s *mapScene) control(dt float32) {
+	ks, keys := s.ks, s.profiles.keys
+	north, south := keys.pressed(ks, client.ActionMoveNorth), keys.pressed(ks, client.ActionMoveSouth)
+	east, west := keys.pressed(ks, client.ActionMoveEast), keys.pressed(ks, client.ActionMoveWest)
+	c1 := s.chars[0]
+	p1 := c1.Position()
+	// A cell is a world unit, walked in the same time as a step of the
+	// walk animation takes.
+	movement := dt / float32(c1.WalkSpeed().Seconds()) * float32(c1.TimeScale)
+	diagonal := movement / character.DiagonalWalkFactor
+
+	// The keyboard takes over the walk paths.
+	if north || south || east || west {
+		c1.Walk = nil
+	} else if c1.Walk != nil {
+		return
+	}
+
+	// char controls
+	if north && east {
+		c1.Direction = directiontype.NorthEast
+		c1.SetState(statetype.Walking)
+		c1.SetPosition(mgl32.Vec3{p1.X() - diagonal, p1.Y() + diagonal, p1.Z()})
+	} else if north && west {
+		c1.Direction = directiontype.NorthWest
+		c1.SetState(statetype.Walking)
+		c1.SetPosition(mgl32.Vec3{p1.X() + diagonal, p1.Y() + diagonal, p1.Z()})
+	} else if south && east {
+		c1.Direction = directiontype.SouthEast
+		c1.SetState(statetype.Walking)
+		c1.SetPosition(mgl32.Vec3{p1.X() - diagonal, p1.Y() - diagonal, p1.Z()})
+	} else if south && west {
+		c1.Direction = directiontype.SouthWest
+		c1.SetPosition(mgl32.Vec3{p1.X() + diagonal, p1.Y() - diagonal, p1.Z()})
+		c1.SetState(statetype.Walking)
+	} else if north {
+		c1.Direction = directiontype.North
+		c1.SetState(statetype.Walking)
+		c1.SetPosition(mgl32.Vec3{p1.X(), p1.Y() + movement, p1.Z()})
+	} else if south {
+		c1.Direction = directiontype.South
+		c1.SetState(statetype.Walking)
+		c1.SetPosition(mgl32.Vec3{p1.X(), p1.Y() - movement, p1.Z()})
+	} else if east {
+		c1.Direction = directiontype.East
+		c1.SetState(statetype.Walking)
+		c1.SetPosition(mgl32.Vec3{p1.X() - movement, p1.Y(), p1.Z()})
+	} else if west {
+		c1.Direction = directiontype.West
+		c1.SetState(statetype.Walking)
+		c1.SetPosition(mgl32.Vec3{p1.X() + movement, p1.Y(), p1.Z()})
+	} else {
+		c1.SetState(statetype.StandBy)
+	}
+}
+
+// moveCamera moves the camera with the keyboard, for a frame of dt
+// seconds. The camera is not part of the simulation, it moves every frame.
+func (s *mapScene) moveCamera(dt float32) {
+	// CameraRate is in world units per second, OrbitRate in degrees per
+	// second.
+	const (
+		CameraRate = float32(12)
+		OrbitRate  = float32(90)
+	)
+
+	ks, keys, cam := s.ks, s.profiles.keys, s.services.Camera
+	camMovement := CameraRate * dt
+
+	if keys.pressed(ks, client.ActionCameraIn) {
+		cam.SetPosition(mgl32.Vec3{cam.Position().X(), cam.Position().Y(), cam.Position().Z() + camMovement})
+	} else if keys.pressed(ks, client.ActionCameraOut) {
+		cam.SetPosition(mgl32.Vec3{cam.Position().X(), cam.Position().Y(), cam.Position().Z() - camMovement})
+	} else if keys.pressed(ks, client.ActionCameraEast) {
+		cam.SetPosition(mgl32.Vec3{cam.Position().X() - camMovement, cam.Position().Y(), cam.Position().Z()})
+	} else if keys.pressed(ks, client.ActionCameraWest) {
+		cam.SetPosition(mgl32.Vec3{cam.Position().X() + camMovement, cam.Position().Y(), cam.Position().Z()})
+	}
+
+	if keys.pressed(ks, client.ActionOrbitLeft) {
+		cam.Orbit(-OrbitRate * dt)
+	} else if keys.pressed(ks, client.ActionOrbitRight) {
+		cam.Orbit(OrbitRate * dt)
+	}
+}
+
+func (s *mapScene) Exit() {
+	for _, unsubscribe := range s.unsubs {
+		unsubscribe()
+	}
+	s.unsubs = nil
+
+	if s.teardown != nil {
+		s.teardown()
+		s.teardown = nil
+	}
+	if s.engine != nil {
+		s.engine.Close()
+		s.engine = nil
+	}
+
+	// Frees the textures of the map, unless the next scene uses them.
+	if s.gatOverlay != nil {
+		s.gatOverlay.Close()
+		s.gatOverlay = nil
+	}
+	if s.captions != nil {
+		s.captions.Close()
+		s.captions = nil
+	}
+	if s.statuses != nil {
+		s.statuses.Close()
+		s.statuses = nil
+	}
+	if s.weather != nil {
+		s.weather.Close()
+		s.weather = nil
+	}
+	if s.effectSys != nil {
+		s.effectSys.Close()
+		s.effectSys = nil
+	}
+	s.services.Audio.Listener = nil
+	if s.ground != nil {
+		s.ground.Close()
+		s.ground = nil
+	}
+	if s.models != nil {
+		s.models.Close()
+		s.models = nil
+	}
+	if s.windows != nil {
+		s.windows.Close()
+		s.windows = nil
+	}
+	if s.console != nil {
+		s.console.Close()
+		s.console = nil
+	}
+	if s.glRender != nil {
+		s.glRender.Close()
+		s.glRender = nil
+	}
+	s.Release()
+
+	s.worlds, s.renderSys, s.chars = service.Worlds{}, nil, nil
+}
+
+// loadingScene clears the screen and shows the loading progress in the
+// window title.
+type loadingScene struct {
+	win    *sdl.Window
+	assets *asset.Manager
+	text   *i18n.Translator
+	title  string
+}
+
+func (s *loadingScene) Name() string {
+	return "loading"
+}
+
+func (s *loadingScene) Enter(ctx context.Context) error {
+	s.title = s.win.GetTitle()
+	return nil
+}
+
+func (s *loadingScene) Update(dt float32) {
+	progress := s.assets.Progress()
+	s.win.SetTitle(s.text.T(i18n.LoadingProgress, s.title, progress.Done, progress.Total))
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+}
+
+func (s *loadingScene) Exit() {
+	log.Info().Int("sprites", s.assets.Progress().Total).Msg("assets loaded")
+	s.win.SetTitle(s.title)
+}
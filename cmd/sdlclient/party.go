@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/ai"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/sim"
+	"github.com/project-midgard/midgarts/internal/system"
+)
+
+const (
+	// partyRoute is the side, in cells, of the square the -party leader
+	// walks around its spot.
+	partyRoute = 14
+	// partyRankWidth is the number of members per rank of the formation.
+	partyRankWidth = 3
+)
+
+// partyCenter is the world position of the -party leader.
+var partyCenter = mgl32.Vec3{-6, 40, 0}
+
+// partyMembers are the jobs, genders and hair of the -party members, the
+// leader first.
+var partyMembers = []struct {
+	job    jobspriteid.Type
+	gender character.GenderType
+	head   character.HeadIndex
+}{
+	{jobspriteid.Knight, character.Male, 5},
+	{jobspriteid.Priest, character.Female, 12},
+	{jobspriteid.Wizard, character.Male, 8},
+	{jobspriteid.Hunter, character.Female, 3},
+	{jobspriteid.Assassin, character.Male, 17},
+	{jobspriteid.Blacksmith, character.Male, 9},
+	{jobspriteid.Dancer, character.Female, 21},
+}
+
+// newParty returns the characters of the -party demo, the leader first,
+// the members standing in a line behind it.
+func newParty(factory *entity.Factory) []*entity.Character {
+	var party []*entity.Character
+	for i, m := range partyMembers {
+		char := factory.CreatePlayer(m.gender, m.job, m.head)
+		char.HasShield = m.job == jobspriteid.Knight
+		char.SetPosition(partyCenter.Sub(mgl32.Vec3{0, float32(2 * i), 0}))
+		party = append(party, char)
+	}
+
+	return party
+}
+
+// march makes the leader of party walk a square around its spot, the
+// members following in formation, in ranks behind it.
+func march(s *system.CharacterAISystem, party []*entity.Character) {
+	leader, members := party[0], party[1:]
+
+	patrol := ai.NewPatrol()
+	actor := s.Control(leader, patrol)
+	start := actor.Cell()
+	for _, corner := range []sim.Cell{{X: 0, Y: partyRoute}, {X: partyRoute, Y: partyRoute}, {X: partyRoute}, {}} {
+		if waypoint, ok := s.Driver.World.NearestWalkable(sim.Cell{X: start.X + corner.X, Y: start.Y + corner.Y}, 3); ok {
+			patrol.Waypoints = append(patrol.Waypoints, waypoint)
+		}
+	}
+
+	for i, slot := range ai.Ranks(len(members), partyRankWidth) {
+		s.Control(members[i], ai.NewFormation(actor, slot[0], slot[1]))
+	}
+}
@@ -0,0 +1,6 @@
+package main
+
+// Plugins registered at build time are blank imported in this file, their
+// init function calling plugin.Register (see pkg/plugin), e.g.:
+//
+//	import _ "github.com/project-midgard/midgarts/plugins/damagemeter"
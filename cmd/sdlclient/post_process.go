@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/pkg/plugin"
+)
+
+// postProcess sets the shaders and the post-processing passes of the
+// plugins on the render system of the scene, then appends the passes of
+// -post: built-in passes by name, or the fragment shaders of .frag files,
+// named after them.
+func (s *mapScene) postProcess(plugins *plugin.Host) error {
+	if plugins != nil {
+		if err := plugins.AddShaders(s.glRender); err != nil {
+			return err
+		}
+	}
+
+	if *postPasses == "" {
+		return nil
+	}
+
+	for _, name := range strings.Split(*postPasses, ",") {
+		if filepath.Ext(name) != ".frag" {
+			pass, err := opengl.BuiltinPostPass(name)
+			if err != nil {
+				return err
+			}
+			s.glRender.AddPostPass(pass)
+			continue
+		}
+
+		source, err := ioutil.ReadFile(name)
+		if err != nil {
+			return errors.Wrap(err, "failed to read post-processing pass")
+		}
+		s.glRender.AddPostPass(&opengl.PostPass{
+			Name:     strings.TrimSuffix(filepath.Base(name), ".frag"),
+			Fragment: string(source),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/window"
+)
+
+// keybinds are the keys of the actions, by action (see client.Profile).
+type keybinds map[string]sdl.Keycode
+
+// newKeybinds returns the keys of keys, named as by SDL.
+func newKeybinds(keys map[string]string) (keybinds, error) {
+	k := keybinds{}
+	for action, name := range keys {
+		key := sdl.GetKeyFromName(name)
+		if key == sdl.K_UNKNOWN {
+			return nil, errors.Errorf("unknown key '%s' of the action '%s'", name, action)
+		}
+		k[action] = key
+	}
+
+	return k, nil
+}
+
+// pressed tells whether the key of action is pressed.
+func (k keybinds) pressed(ks *window.KeyState, action string) bool {
+	key, ok := k[action]
+	return ok && ks.Pressed(key)
+}
+
+// action returns the action of key, "" when it is not bound.
+func (k keybinds) action(key sdl.Keycode) string {
+	for action, bound := range k {
+		if bound == key {
+			return action
+		}
+	}
+
+	return ""
+}
+
+// profiles holds the profile of the character playing, switched when it
+// enters the game.
+type profiles struct {
+	conf    client.Config
+	current client.Profile
+	keys    keybinds
+}
+
+// newProfiles returns the profiles of conf, the default one current.
+func newProfiles(conf client.Config) (*profiles, error) {
+	p := &profiles{conf: conf}
+	if err := p.switchTo(); err != nil {
+		return nil, errors.Wrapf(err, "invalid profile '%s'", client.DefaultProfile)
+	}
+
+	return p, nil
+}
+
+// switchTo makes the profile of names current (see client.Config.Profile),
+// unless its keys are invalid.
+func (p *profiles) switchTo(names ...string) error {
+	profile := p.conf.Profile(names...)
+	keys, err := newKeybinds(profile.Keybinds)
+	if err != nil {
+		return err
+	}
+
+	p.current, p.keys = profile, keys
+	return nil
+}
+
+// subscribeProfile switches to the profile of the characters entering the
+// game, and applies its options and its layout.
+func (s *mapScene) subscribeProfile(bus *event.Bus) func() {
+	return bus.Subscribe(func(e event.CharacterEntered) {
+		if err := s.profiles.switchTo(e.Account, e.Name); err != nil {
+			log.Error().Err(err).Str("account", e.Account).Str("character", e.Name).Msg("could not switch profile")
+			return
+		}
+		log.Debug().Str("account", e.Account).Str("character", e.Name).Msg("profile switched")
+
+		s.applyProfile(s.profiles.current)
+	})
+}
+
+// applyProfile applies the options and the window layout of p to the scene.
+func (s *mapScene) applyProfile(p client.Profile) {
+	// The camera of a snapshot is restored as it was.
+	if distance := p.Options.CameraDistance; distance != 0 && s.snapshot == nil {
+		cam := s.services.Camera
+		cam.Zoom(cam.Distance() / distance)
+	}
+	if p.Options.GATOverlay != nil {
+		s.gatOverlay.Enabled = *p.Options.GATOverlay
+	}
+
+	if l, ok := p.Windows[client.WindowConsole]; ok {
+		s.console.Position = mgl32.Vec2{l.X, l.Y}
+		s.console.Size = mgl32.Vec2{l.Width, l.Height}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/project-midgard/midgarts/internal/camera"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/effect"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/script"
+	"github.com/project-midgard/midgarts/internal/weather"
+)
+
+// scriptAPI is the client driven by the -script scripts.
+type scriptAPI struct {
+	scene   *mapScene
+	factory *entity.Factory
+	cam     *camera.Camera
+	win     *sdl.Window
+}
+
+func (a *scriptAPI) Spawn(c script.Character) (uint64, error) {
+	char := a.factory.CreatePlayer(c.Gender, c.JobSpriteID, c.HeadIndex)
+	char.HasShield = c.HasShield
+	char.Scale = c.Scale
+	char.SetPosition(c.Position)
+	a.scene.addCharacter(char)
+
+	return char.ID(), nil
+}
+
+func (a *scriptAPI) Walk(id uint64, points []mgl32.Vec2) error {
+	char, err := a.character(id)
+	if err != nil {
+		return err
+	}
+
+	char.WalkTo(points...)
+	return nil
+}
+
+func (a *scriptAPI) SetState(id uint64, state statetype.Type) error {
+	char, err := a.character(id)
+	if err != nil {
+		return err
+	}
+
+	char.SetState(state)
+	return nil
+}
+
+func (a *scriptAPI) Say(id uint64, text string, d time.Duration) error {
+	char, err := a.character(id)
+	if err != nil {
+		return err
+	}
+
+	a.scene.captions.Say(char, text, d)
+	return nil
+}
+
+func (a *scriptAPI) character(id uint64) (*entity.Character, error) {
+	if char := a.scene.character(id); char != nil {
+		return char, nil
+	}
+
+	return nil, errors.Errorf("unknown character %d", id)
+}
+
+func (a *scriptAPI) PlayEffect(name string, position mgl32.Vec3) error {
+	return a.scene.effects.PlayNamed(effectPlayer{a.scene}, name, effect.Target{Position: position})
+}
+
+func (a *scriptAPI) MoveCamera(position mgl32.Vec3) {
+	a.cam.SetPosition(position)
+}
+
+func (a *scriptAPI) CameraPosition() mgl32.Vec3 {
+	return a.cam.Position()
+}
+
+func (a *scriptAPI) SetFade(fade float32) {
+	a.scene.scriptFade = fade
+	a.scene.SetFade(fade)
+}
+
+func (a *scriptAPI) SetWeather(w weather.Weather) {
+	a.scene.weather.Set(w)
+}
+
+func (a *scriptAPI) ShowDialog(title, text string) {
+	if err := sdl.ShowSimpleMessageBox(sdl.MESSAGEBOX_INFORMATION, title, text, a.win); err != nil {
+		log.Error().Err(err).Msg("could not show dialog")
+	}
+}
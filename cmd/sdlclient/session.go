@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/network/capture"
+	"github.com/project-midgard/midgarts/internal/session"
+	"github.com/project-midgard/midgarts/internal/sim"
+	"github.com/project-midgard/midgarts/internal/system"
+)
+
+// chatDuration is the time a chat captured in a session is shown.
+const chatDuration = 5 * time.Second
+
+// replaySession returns the system replaying the session of the -capture
+// file on the map: the entities the server showed spawn, walk, chat and
+// vanish as captured, and player enters where the captured one did.
+func (s *mapScene) replaySession(player *entity.Character) (*system.SessionSystem, error) {
+	f, err := os.Open(*capturePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open capture")
+	}
+	defer f.Close()
+
+	records, err := capture.Read(f, uint16(*capturePort))
+	if err != nil {
+		if len(records) == 0 {
+			return nil, errors.Wrapf(err, "failed to read capture '%s'", *capturePath)
+		}
+		log.Warn().Err(err).Int("packets", len(records)).Msg("replaying the packets of the capture before the error")
+	}
+	log.Info().Int("packets", len(records)).Str("capture", *capturePath).Msg("replaying session")
+
+	ground := s.renderSys.Ground
+	sessionSys := system.NewSessionSystem(s.services.Bus, ground, records)
+	sessionSys.World.Entered = func(c sim.Cell) {
+		p := ground.World(c.Center().X(), c.Center().Y())
+		player.SetPosition(mgl32.Vec3{p.X(), p.Y(), player.Position().Z()})
+	}
+	sessionSys.World.Said = func(e *session.Entity, message string) {
+		if char := sessionSys.Character(e.GID); char != nil {
+			s.captions.Say(char, message, chatDuration)
+		}
+	}
+	sessionSys.Show = func(e *session.Entity) *entity.Character {
+		char, err := s.sessionCharacter(e)
+		if err != nil {
+			log.Warn().Err(err).Uint32("gid", e.GID).Msg("could not show entity")
+			return nil
+		}
+		s.addCharacter(char)
+		return char
+	}
+	sessionSys.Hide = s.removeCharacter
+
+	return sessionSys, nil
+}
+
+// sessionCharacter returns the character of an entity of a session.
+func (s *mapScene) sessionCharacter(e *session.Entity) (*entity.Character, error) {
+	factory := s.services.Factory
+	switch e.ObjectType {
+	case session.ObjectPlayer:
+		gender := character.Female
+		if e.Male {
+			gender = character.Male
+		}
+		char := factory.CreatePlayer(gender, jobspriteid.Type(e.Job), character.HeadIndex(e.Head))
+		char.TopHeadgear, char.MidHeadgear, char.LowHeadgear = int(e.TopHeadgear), int(e.MidHeadgear), int(e.LowHeadgear)
+		char.Weapon = weapontype.Type(e.Weapon)
+		return char, nil
+	case session.ObjectMonster:
+		return factory.CreateMonster(int(e.Job))
+	case session.ObjectNPC:
+		return factory.CreateNPC(int(e.Job))
+	}
+
+	return nil, errors.Errorf("entities of type %d are not shown", e.ObjectType)
+}
+
+// removeCharacter removes char from the scene.
+func (s *mapScene) removeCharacter(char *entity.Character) {
+	for i, other := range s.chars {
+		if other == char {
+			s.chars = append(s.chars[:i], s.chars[i+1:]...)
+			break
+		}
+	}
+	s.worlds.RemoveEntity(*char.BasicEntity)
+}
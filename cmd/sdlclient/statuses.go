@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/project-midgard/midgarts/internal/character/statustype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+)
+
+// subscribeStatuses changes the status effects of the characters from the
+// states the server sends, and cycles the ones of player on every
+// event.StatusCycled. The client has no account ids yet: the AID of the
+// packets is the entity id of the character.
+func (s *mapScene) subscribeStatuses(bus *event.Bus, player *entity.Character) func() {
+	unsubscribePackets := bus.Subscribe(func(e event.PacketReceived) {
+		p, ok := e.Packet.(*packet.ZC_STATE_CHANGE3)
+		if !ok {
+			return
+		}
+
+		char := s.character(uint64(p.AID))
+		if char == nil {
+			return
+		}
+
+		active := map[statustype.Type]bool{}
+		for _, status := range statustype.FromStates(p.BodyState, p.HealthState) {
+			active[status] = true
+		}
+		for _, status := range statustype.All() {
+			if active[status] != char.HasStatus(status) {
+				bus.Publish(event.StatusChanged{Character: char, Status: status, Active: active[status]})
+			}
+		}
+	})
+
+	unsubscribeCycle := bus.Subscribe(func(event.StatusCycled) {
+		statuses := statustype.All()
+		next := 0
+		for i, status := range statuses {
+			if player.HasStatus(status) {
+				bus.Publish(event.StatusChanged{Character: player, Status: status})
+				if next == 0 {
+					next = i + 1
+				}
+			}
+		}
+
+		// None after the last one.
+		if next < len(statuses) {
+			bus.Publish(event.StatusChanged{Character: player, Status: statuses[next], Active: true})
+		}
+	})
+
+	return func() {
+		unsubscribePackets()
+		unsubscribeCycle()
+	}
+}
+
+// character returns the character of the scene of id, nil if none. The ids
+// of the packets of a replayed session are the ones the server gave.
+func (s *mapScene) character(id uint64) *entity.Character {
+	if s.session != nil {
+		if char := s.session.Character(uint32(id)); char != nil {
+			return char
+		}
+	}
+	for _, char := range s.chars {
+		if char.ID() == id {
+			return char
+		}
+	}
+
+	return nil
+}
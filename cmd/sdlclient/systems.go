@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/project-midgard/midgarts/internal/hotreload"
+	"github.com/project-midgard/midgarts/internal/service"
+	"github.com/project-midgard/midgarts/internal/system"
+)
+
+// The optional systems of the map scenes. A new system registers its
+// installer here, and gets its dependencies from the services.
+func init() {
+	service.Register("assetreload", installAssetReload)
+	service.Register("plugins", installPlugins)
+}
+
+// installAssetReload reloads the sprites of the data folder when
+// -hot-reload is set.
+func installAssetReload(s *service.Services, w service.Worlds) (func(), error) {
+	if !*hotReload {
+		return nil, nil
+	}
+
+	watcher := hotreload.NewWatcher(hotreload.DefaultInterval)
+
+	var reloadable *system.CharacterRenderable
+	w.Render.AddSystemInterface(system.NewAssetReloadSystem(s.Ctx, s.GRF, watcher), reloadable, nil)
+
+	return watcher.Close, nil
+}
+
+// installPlugins adds the systems of the plugins, and hands them the
+// spawns, the frames and the events.
+func installPlugins(s *service.Services, w service.Worlds) (func(), error) {
+	s.Plugins.AddSystems(w.Simulation)
+
+	var spawnable *system.CharacterRenderable
+	w.Simulation.AddSystemInterface(s.Plugins, spawnable, nil)
+
+	return s.Plugins.Subscribe(s.Bus), nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/aseprite"
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+	"github.com/project-midgard/midgarts/internal/spritebundle"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+var (
+	configFlags = client.RegisterFlags(flag.CommandLine)
+	spritePath  = flag.String("sprite", "", "GRF path of the sprite, without the .act/.spr extension (Korean names in UTF-8)")
+	palettePath = flag.String("palette", "", "GRF path of a palette to apply to the sprite")
+	outputPath  = flag.String("o", "", "path of the generated files, without extension (defaults to the sprite name)")
+	format      = flag.String("format", "aseprite", "format of the animation data: aseprite (.json), godot (SpriteFrames .tres) or unity (.json)")
+	godotPath   = flag.String("godot-texture", "", "Godot resource path of the sheet image (defaults to res://<image name>)")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// spriteexport exports an ACT+SPR sprite as a sprite sheet: a PNG image
+// and its animation data, the Aseprite JSON data with a tag per action, a
+// Godot SpriteFrames resource or the JSON of a Unity importer, with an
+// animation per action and direction (see spritebundle).
+func main() {
+	flag.Parse()
+
+	if *spritePath == "" {
+		log.Fatal().Msg("missing -sprite")
+	}
+
+	if raw, err := encoding.FromUTF8(*spritePath); err == nil {
+		*spritePath = raw
+	}
+
+	name := path.Base(encoding.Transliterate(filepath.ToSlash(*spritePath)))
+	if *outputPath == "" {
+		*outputPath = name
+	}
+
+	conf, err := configFlags.Load()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	grfFile, err := vfs.Open(context.Background(), conf.DataDir, conf.GRFPaths...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+
+	files, err := grfFile.GetSpriteFiles(*spritePath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load sprite")
+	}
+
+	if *palettePath != "" {
+		if raw, err := encoding.FromUTF8(*palettePath); err == nil {
+			*palettePath = raw
+		}
+
+		e, err := grfFile.GetEntry(*palettePath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load palette")
+		}
+
+		p, err := pal.Load(e.Data)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load palette")
+		}
+
+		files.SPR.ApplyPalette(p)
+	}
+
+	imagePath := *outputPath + ".png"
+	sheet, err := aseprite.Export(files.ACT, files.SPR, name, filepath.Base(imagePath))
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to export sprite")
+	}
+
+	out, err := os.Create(imagePath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create output file")
+	}
+	defer out.Close()
+
+	if err = png.Encode(out, sheet.Image); err != nil {
+		log.Fatal().Err(err).Msg("failed to encode image")
+	}
+
+	animations := spritebundle.Animations(sheet.Data, len(files.ACT.Actions))
+
+	var (
+		dataPath = *outputPath + ".json"
+		data     []byte
+	)
+	switch *format {
+	case "aseprite":
+		data, err = json.MarshalIndent(sheet.Data, "", "  ")
+	case "godot":
+		if *godotPath == "" {
+			*godotPath = "res://" + filepath.Base(imagePath)
+		}
+
+		var buf bytes.Buffer
+		err = spritebundle.WriteGodot(&buf, sheet.Data, animations, *godotPath)
+		dataPath, data = *outputPath+".tres", buf.Bytes()
+	case "unity":
+		data, err = json.MarshalIndent(spritebundle.NewUnitySheet(sheet.Data, animations, filepath.Base(imagePath)), "", "  ")
+	default:
+		log.Fatal().Msgf("unknown format '%s'", *format)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to encode data")
+	}
+
+	if err = ioutil.WriteFile(dataPath, data, 0644); err != nil {
+		log.Fatal().Err(err).Msg("failed to write data")
+	}
+
+	log.Info().Msgf("wrote %s and %s (%d frames, %d animations)", imagePath, dataPath, len(sheet.Data.Frames), len(animations))
+}
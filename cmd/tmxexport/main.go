@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/bmp"
+
+	"github.com/project-midgard/midgarts/internal/client"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/tiled"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+var (
+	configFlags = client.RegisterFlags(flag.CommandLine)
+	mapName     = flag.String("map", "", "name of the map, e.g. prontera")
+	outputDir   = flag.String("o", "", "folder of the generated files (defaults to the map name)")
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+// tmxexport exports a map to the Tiled map editor: a TMX map of a
+// collision layer of the GAT cell types and of a ground layer of the GND
+// tiles, the TSX tilesets of the cell types and of the ground textures,
+// and the images of the tilesets.
+func main() {
+	flag.Parse()
+
+	if *mapName == "" {
+		log.Fatal().Msg("missing -map")
+	}
+	if *outputDir == "" {
+		*outputDir = *mapName
+	}
+
+	conf, err := configFlags.Load()
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	grfFile, err := vfs.Open(context.Background(), conf.DataDir, conf.GRFPaths...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load grf file")
+	}
+	defer grfFile.Close()
+
+	e, err := grfFile.GetEntry("data/" + *mapName + ".gat")
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load map")
+	}
+
+	gatFile, err := gat.Load(e.Data)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load map")
+	}
+
+	var (
+		gndFile  *gnd.GroundFile
+		textures []tiled.Texture
+	)
+	if e, err = grfFile.GetEntry("data/" + *mapName + ".gnd"); err != nil {
+		log.Warn().Err(err).Msg("no ground, exporting the collision layer only")
+	} else if gndFile, err = gnd.Load(e.Data); err != nil {
+		log.Fatal().Err(err).Msg("failed to load ground")
+	} else if len(gndFile.Surfaces) == 0 {
+		log.Warn().Msgf("ground version %.1f not supported, exporting the collision layer only", gndFile.Version)
+	}
+
+	if err = os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatal().Err(err).Msg("failed to create output folder")
+	}
+
+	if gndFile != nil {
+		for _, name := range gndFile.Textures {
+			textures = append(textures, writeTexture(grfFile, name))
+		}
+	}
+
+	export := tiled.NewExport(*mapName, gatFile, gndFile, textures)
+
+	var buf bytes.Buffer
+	if err = export.Map.Write(&buf); err != nil {
+		log.Fatal().Err(err).Msg("failed to encode map")
+	}
+	writeFile(*mapName+".tmx", buf.Bytes())
+
+	for source, ts := range export.Tilesets {
+		buf.Reset()
+		if err = ts.Write(&buf); err != nil {
+			log.Fatal().Err(err).Msgf("failed to encode tileset %s", source)
+		}
+		writeFile(source, buf.Bytes())
+	}
+
+	buf.Reset()
+	if err = png.Encode(&buf, tiled.CellTypesTilesetImage()); err != nil {
+		log.Fatal().Err(err).Msg("failed to encode image")
+	}
+	writeFile(tiled.CellTypesImage, buf.Bytes())
+
+	log.Info().Msgf("wrote %s (%dx%d cells, %d tilesets)", filepath.Join(*outputDir, *mapName+".tmx"), gatFile.Width, gatFile.Height, len(export.Tilesets))
+}
+
+// writeTexture copies the ground texture name to the texture folder of
+// the output, returning its tileset image.
+func writeTexture(grfFile *grf.MultiFile, name string) tiled.Texture {
+	source := "texture/" + encoding.Transliterate(strings.ReplaceAll(name, `\`, "/"))
+	tex := tiled.Texture{Source: source}
+
+	e, err := grfFile.GetEntry("data/texture/" + name)
+	if err != nil {
+		log.Warn().Err(err).Msgf("missing texture %s", encoding.Display(name))
+		return tex
+	}
+
+	if conf, err := bmp.DecodeConfig(bytes.NewReader(e.Data)); err == nil {
+		tex.Width, tex.Height = conf.Width, conf.Height
+	}
+
+	if err = os.MkdirAll(filepath.Join(*outputDir, filepath.Dir(filepath.FromSlash(source))), 0755); err != nil {
+		log.Fatal().Err(err).Msg("failed to create texture folder")
+	}
+	writeFile(source, e.Data)
+
+	return tex
+}
+
+// writeFile writes the output file name, a slash separated path relative
+// to the output folder.
+func writeFile(name string, data []byte) {
+	if err := ioutil.WriteFile(filepath.Join(*outputDir, filepath.FromSlash(name)), data, 0644); err != nil {
+		log.Fatal().Err(err).Msgf("failed to write %s", name)
+	}
+}
@@ -0,0 +1,202 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/animation"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/actionindex"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/grfhttp"
+	"github.com/project-midgard/midgarts/internal/webgl"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+)
+
+// zoom is the size of a sprite pixel on the canvas.
+const zoom = 2
+
+// clearColor is the background of the canvas, the one of the desktop
+// client (see opengl.ClearColor).
+var clearColor = [4]float32{0.3, 0.3, 0.5, 1.0}
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, NoColor: true})
+}
+
+// wasmdemo animates a character in the canvas of id "midgarts" of a web
+// page, its sprites loaded over HTTP from a GRF served by grfserve and
+// drawn with WebGL. The parameters of the page select the character, e.g.
+// ?job=Knight&gender=female&head=3&state=Walking, and grf the URL the
+// entries are served under, /grf/ by default. Clicking the canvas turns
+// the character.
+func main() {
+	query := js.Global().Get("URLSearchParams").New(js.Global().Get("location").Get("search"))
+	param := func(name, fallback string) string {
+		if v := query.Call("get", name); !v.IsNull() && v.String() != "" {
+			return v.String()
+		}
+		return fallback
+	}
+
+	canvas := js.Global().Get("document").Call("getElementById", "midgarts")
+	gl, err := webgl.NewContext(canvas)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create the WebGL context")
+	}
+
+	job, err := jobspriteid.Parse(param("job", jobspriteid.Novice.String()))
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+	gender := character.Male
+	if strings.EqualFold(param("gender", "male"), "female") {
+		gender = character.Female
+	}
+	head, err := strconv.Atoi(param("head", "1"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid head")
+	}
+
+	char := entity.NewFactory().CreatePlayer(gender, job, character.HeadIndex(head))
+	char.SetState(statetype.Type(param("state", string(statetype.StandBy))))
+	if char.ActionIndex, err = actionindex.GetActionIndex(char.State); err != nil {
+		log.Fatal().Err(err).Send()
+	}
+	if char.State == statetype.Walking {
+		char.FPSMultiplier = character.WalkAnimationMultiplier(char.WalkSpeed())
+	}
+
+	loader := grfhttp.NewLoader(param("grf", "/grf/"))
+	cmp, err := component.NewCharacterAttachmentComponent(context.Background(), loader, char.AttachmentConfig())
+	if err != nil {
+		// The attachments that did load are still drawn.
+		log.Error().Err(err).Msg("could not load character attachments")
+	}
+	char.SetCharacterAttachmentComponent(cmp)
+
+	d := &demo{gl: gl, char: char, textures: map[*spr.SpriteFile]*webgl.Texture{}}
+	canvas.Call("addEventListener", "click", js.FuncOf(func(js.Value, []js.Value) interface{} {
+		char.Direction = (char.Direction + 1) % pkgcharacter.DirectionCount
+		return nil
+	}))
+	d.onFrame = js.FuncOf(d.frame)
+	js.Global().Call("requestAnimationFrame", d.onFrame)
+
+	select {}
+}
+
+// demo draws the character every frame of the browser.
+type demo struct {
+	gl       *webgl.Context
+	char     *entity.Character
+	textures map[*spr.SpriteFile]*webgl.Texture
+
+	onFrame    js.Func
+	last       time.Duration
+	placements []animation.Placement
+	quads      []webgl.Quad
+}
+
+// frame animates and draws the character, args[0] being the time of the
+// frame, in milliseconds.
+func (d *demo) frame(_ js.Value, args []js.Value) interface{} {
+	now := time.Duration(args[0].Float() * float64(time.Millisecond))
+	if d.last != 0 {
+		d.char.AnimationElapsed += now - d.last
+	}
+	d.last = now
+
+	d.render()
+	js.Global().Call("requestAnimationFrame", d.onFrame)
+
+	return nil
+}
+
+func (d *demo) render() {
+	width, height := d.gl.Size()
+	origin := mgl32.Vec2{float32(width) / 2, float32(height) * 2 / 3}
+
+	d.gl.Clear(clearColor)
+	d.placements = animation.AppendPose(d.placements[:0], d.char, pkgcharacter.DirectionResolver{}, d.char.AnimationElapsed)
+	for _, p := range d.placements {
+		tex, err := d.texture(p.SPR)
+		if err != nil {
+			log.Error().Err(err).Msgf("could not draw %s", p.Attachment)
+			continue
+		}
+
+		atlas := p.SPR.Atlas()
+		d.quads = d.quads[:0]
+		for _, layer := range p.Layers {
+			index := character.SpriteIndex(layer.SpriteFrameIndex)
+			if index < 0 || int(index) >= len(p.SPR.Frames) || p.SPR.ImageAt(index) == nil {
+				continue
+			}
+
+			frame := p.SPR.Frames[index]
+			size := mgl32.Vec2{float32(frame.Width) * layer.Scale[0], float32(frame.Height) * layer.Scale[1]}
+			// As the desktop client, see system.CharacterRenderSystem.
+			center := mgl32.Vec2{
+				float32(layer.Position[0]) + p.Position[0] + pixelCenter(size.X()),
+				float32(layer.Position[1]) + p.Position[1] + pixelCenter(size.Y()),
+			}
+
+			color := mgl32.Vec4{1, 1, 1, 1}
+			if c := layer.Color; c != nil {
+				color = mgl32.Vec4{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255, float32(c.A) / 255}
+			}
+
+			d.quads = append(d.quads, webgl.Quad{
+				Center:           origin.Add(center.Mul(zoom)),
+				Size:             size.Mul(zoom),
+				RotationRadians:  float32(layer.Angle) * math.Pi / 180,
+				UV:               atlas.UV(int(index)),
+				FlipHorizontally: layer.Mirrored,
+				Color:            color,
+			})
+		}
+
+		d.gl.Draw(tex, d.quads...)
+	}
+}
+
+// texture returns the texture of the atlas of f, uploaded once.
+func (d *demo) texture(f *spr.SpriteFile) (*webgl.Texture, error) {
+	if tex, ok := d.textures[f]; ok {
+		return tex, nil
+	}
+
+	tex, err := d.gl.NewTexture(f.Atlas().Image, graphic.DefaultFilters[graphic.TextureSprite])
+	if err != nil {
+		return nil, err
+	}
+	d.textures[f] = tex
+
+	return tex, nil
+}
+
+// pixelCenter returns the offset from the position of a layer of size
+// pixels to its center, the layer starting at the position minus half
+// its size rounded down.
+func pixelCenter(size float32) float32 {
+	return size/2 - float32(int(size)/2)
+}
@@ -0,0 +1,24 @@
+-- A short scene around the characters of the client, run with:
+--   go run ./cmd/sdlclient -script examples/scripts/izlude.lua
+
+local guide = midgarts.spawn{job = "Priest", gender = "f", head = 12, x = 12, y = 40, z = 0}
+midgarts.log("guide spawned", guide)
+
+-- The guide walks around the fountain, rounding the corners.
+midgarts.walk(guide, 12, 44, 8, 44, 8, 40)
+
+-- A giant knight, twice the size of the others.
+midgarts.spawn{job = "Knight", head = 3, x = 16, y = 42, z = 0, scale = 2}
+
+midgarts.dialog("Welcome to Izlude!", "Guide")
+
+-- Pan the camera along the characters for a few seconds.
+local elapsed = 0
+function on_frame(dt)
+	if elapsed > 4 then
+		return
+	end
+
+	elapsed = elapsed + dt
+	midgarts.camera(8 - elapsed * 2, 40, 0)
+end
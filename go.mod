@@ -12,11 +12,15 @@ require (
 	github.com/golang-ui/nuklear v0.0.0-20220830122737-d1c7e248721c
 	github.com/google/uuid v1.2.0
 	github.com/joho/godotenv v1.3.0
+	github.com/klauspost/compress v1.15.9
 	github.com/pkg/errors v0.9.1
 	github.com/rs/zerolog v1.26.1
 	github.com/stretchr/testify v1.7.0
 	github.com/veandco/go-sdl2 v0.4.25
 	github.com/xlab/android-go v0.0.0-20221014001251-3dab312ceaf9 // indirect
 	github.com/xlab/closer v1.1.0
+	github.com/yuin/gopher-lua v0.0.0-20210529063254-f4c35e4016d9
+	golang.org/x/image v0.0.0-20190321063152-3fc05d484e9f
 	golang.org/x/text v0.3.6
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,116 @@
+// Package ai makes the actors of a sim.World act on their own: a
+// Controller decides the next action of an actor every tick, and a Driver
+// applies the actions of the actors it controls to the world. Wander,
+// Follow and Aggro are sample controllers, populating the demo scenes and
+// the local tests without a server.
+package ai
+
+import (
+	"github.com/project-midgard/midgarts/internal/sim"
+)
+
+// Kind is the kind of an Action.
+type Kind int
+
+const (
+	// Idle stands by where the actor is.
+	Idle Kind = iota
+	// Move walks to Action.Cell.
+	Move
+	// Attack attacks Action.Target, walking to it first when it is out of
+	// range.
+	Attack
+)
+
+// Action is what an actor does next.
+type Action struct {
+	Kind   Kind
+	Cell   sim.Cell
+	Target *sim.Actor
+}
+
+// Controller decides what an actor does. A controller controls a single
+// actor, and may keep its state between the ticks.
+type Controller interface {
+	// Next returns the next action of self, dt seconds after the last
+	// call. Returning the action self is doing keeps doing it.
+	Next(w *sim.World, self *sim.Actor, dt float32) Action
+}
+
+// Driver applies the actions of the controllers of its actors to a world.
+type Driver struct {
+	World *sim.World
+
+	controlled []controlled
+}
+
+type controlled struct {
+	actor      *sim.Actor
+	controller Controller
+}
+
+// NewDriver returns a driver of the actors of w, without controllers.
+func NewDriver(w *sim.World) *Driver {
+	return &Driver{World: w}
+}
+
+// Attach makes c control a, replacing its controller if any.
+func (d *Driver) Attach(a *sim.Actor, c Controller) {
+	for i := range d.controlled {
+		if d.controlled[i].actor == a {
+			d.controlled[i].controller = c
+			return
+		}
+	}
+
+	d.controlled = append(d.controlled, controlled{actor: a, controller: c})
+}
+
+// Detach leaves a to itself, doing what it does.
+func (d *Driver) Detach(a *sim.Actor) {
+	for i, c := range d.controlled {
+		if c.actor == a {
+			d.controlled = append(d.controlled[:i], d.controlled[i+1:]...)
+			return
+		}
+	}
+}
+
+// Update applies the next actions of the controlled actors, then advances
+// the world by dt seconds. The actors removed from the world are
+// detached.
+func (d *Driver) Update(dt float32) {
+	kept := d.controlled[:0]
+	for _, c := range d.controlled {
+		if _, ok := d.World.Actor(c.actor.ID); !ok {
+			continue
+		}
+		kept = append(kept, c)
+
+		d.apply(c.actor, c.controller.Next(d.World, c.actor, dt))
+	}
+	d.controlled = kept
+
+	d.World.Update(dt)
+}
+
+// apply makes a do action, unless it already does.
+func (d *Driver) apply(a *sim.Actor, action Action) {
+	switch action.Kind {
+	case Move:
+		if destination, walking := a.Destination(); (walking && destination == action.Cell && a.Target() == nil) ||
+			(!walking && a.Cell() == action.Cell) {
+			return
+		}
+		d.World.WalkTo(a, action.Cell)
+	case Attack:
+		if action.Target == nil || a.Target() == action.Target {
+			return
+		}
+		d.World.Attack(a, action.Target)
+	default:
+		if _, walking := a.Destination(); walking || a.Target() != nil {
+			d.World.Stop(a)
+		}
+	}
+}
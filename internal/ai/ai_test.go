@@ -0,0 +1,189 @@
+package ai
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/sim"
+)
+
+// newWorld returns the world of a walkable map of width by height cells.
+func newWorld(width, height int) *sim.World {
+	f := &gat.GroundAltitudeFile{Width: uint32(width), Height: uint32(height), Cells: make([]gat.Cell, width*height)}
+	for i := range f.Cells {
+		f.Cells[i].CellType = gat.Walkable
+	}
+
+	return sim.NewWorld(f)
+}
+
+// run updates the driver for seconds, a tick every 20ms.
+func run(d *Driver, seconds float32) {
+	for t := float32(0); t < seconds; t += 0.02 {
+		d.Update(0.02)
+	}
+}
+
+// scripted returns the actions of a script, one per tick, then idles.
+type scripted []Action
+
+func (s *scripted) Next(w *sim.World, self *sim.Actor, dt float32) Action {
+	if len(*s) == 0 {
+		return Action{Kind: Idle}
+	}
+	action := (*s)[0]
+	*s = (*s)[1:]
+
+	return action
+}
+
+func TestDriver(t *testing.T) {
+	w := newWorld(10, 10)
+	a, b := w.Spawn(sim.Cell{}), w.Spawn(sim.Cell{X: 5, Y: 5})
+	d := NewDriver(w)
+
+	script := scripted{{Kind: Move, Cell: sim.Cell{X: 3}}, {Kind: Move, Cell: sim.Cell{X: 3}}}
+	d.Attach(a, &script)
+	d.Update(0.02)
+	destination, walking := a.Destination()
+	assert.True(t, walking)
+	assert.Equal(t, sim.Cell{X: 3}, destination)
+
+	// The same action keeps the walk going.
+	d.Update(0.02)
+	assert.InDelta(t, 0.5+2*0.02/0.15, a.Position.X(), 1e-4)
+
+	script = scripted{{Kind: Attack, Target: b}}
+	d.Update(0.02)
+	assert.Equal(t, b, a.Target())
+
+	// Idle stops the attack.
+	d.Update(0.02)
+	assert.Nil(t, a.Target())
+	assert.Equal(t, statetype.StandBy, a.State)
+
+	// The removed actors are detached.
+	w.Remove(a)
+	d.Update(0.02)
+	assert.Empty(t, d.controlled)
+}
+
+func TestWander(t *testing.T) {
+	w := newWorld(20, 20)
+	home := sim.Cell{X: 10, Y: 10}
+	a := w.Spawn(home)
+	d := NewDriver(w)
+
+	wander := NewWander(home, 3)
+	wander.Rand = rand.New(rand.NewSource(1))
+	d.Attach(a, wander)
+
+	// Stands through the pause.
+	run(d, 2.9)
+	assert.Equal(t, home, a.Cell())
+	assert.NotEqual(t, statetype.Walking, a.State)
+
+	moved := false
+	for i := 0; i < 20; i++ {
+		run(d, 1)
+		moved = moved || a.Cell() != home
+		assert.LessOrEqual(t, sim.Distance(home, a.Cell()), 3)
+	}
+	assert.True(t, moved)
+}
+
+func TestFollow(t *testing.T) {
+	w := newWorld(20, 5)
+	leader, follower := w.Spawn(sim.Cell{X: 2}), w.Spawn(sim.Cell{})
+	d := NewDriver(w)
+	d.Attach(follower, NewFollow(leader))
+
+	require.True(t, w.WalkTo(leader, sim.Cell{X: 15}))
+	run(d, 4)
+	assert.Equal(t, sim.Cell{X: 15}, leader.Cell())
+	assert.Equal(t, 2, sim.Distance(leader.Cell(), follower.Cell()))
+	assert.Equal(t, statetype.StandBy, follower.State)
+}
+
+func TestAggro(t *testing.T) {
+	w := newWorld(30, 5)
+	monster, player := w.Spawn(sim.Cell{}), w.Spawn(sim.Cell{X: 20})
+	d := NewDriver(w)
+
+	otherwise := &scripted{}
+	d.Attach(monster, NewAggro(func(a *sim.Actor) bool { return a == player }, otherwise))
+
+	// Out of sight.
+	run(d, 1)
+	assert.Nil(t, monster.Target())
+
+	w.Place(player, sim.Cell{X: 5}.Center())
+	run(d, 1)
+	assert.Equal(t, player, monster.Target())
+	assert.Equal(t, statetype.Attacking, monster.State)
+	assert.Equal(t, 1, sim.Distance(monster.Cell(), player.Cell()))
+
+	// The player runs out of sight: the monster stops.
+	w.Place(player, sim.Cell{X: 25}.Center())
+	d.Update(0.02)
+	d.Update(0.02)
+	assert.Nil(t, monster.Target())
+}
+
+func TestPatrol(t *testing.T) {
+	w := newWorld(10, 10)
+	a := w.Spawn(sim.Cell{X: 1, Y: 1})
+	d := NewDriver(w)
+	// The waypoint out of the map is skipped.
+	d.Attach(a, NewPatrol(sim.Cell{X: 5, Y: 1}, sim.Cell{X: 20, Y: 20}, sim.Cell{X: 1, Y: 1}))
+
+	var visited []sim.Cell
+	for i := 0; i < 200; i++ {
+		d.Update(0.02)
+		if _, walking := a.Destination(); !walking && (len(visited) == 0 || visited[len(visited)-1] != a.Cell()) {
+			visited = append(visited, a.Cell())
+		}
+	}
+	assert.Equal(t, []sim.Cell{{X: 5, Y: 1}, {X: 1, Y: 1}, {X: 5, Y: 1}}, visited[:3])
+}
+
+func TestRanks(t *testing.T) {
+	assert.Equal(t, [][2]int{{-2, 2}, {0, 2}, {2, 2}, {-1, 4}, {1, 4}}, Ranks(5, 3))
+}
+
+// TestFormation walks a party in formation: the members keep their slots
+// as the leader turns, and face as the leader once it stops.
+func TestFormation(t *testing.T) {
+	w := newWorld(40, 40)
+	leader := w.Spawn(sim.Cell{X: 10, Y: 10})
+	d := NewDriver(w)
+
+	var members []*Formation
+	for i, slot := range Ranks(5, 3) {
+		f := NewFormation(leader, slot[0], slot[1])
+		m := w.Spawn(sim.Cell{X: 10 + i, Y: 5})
+		d.Attach(m, f)
+		members = append(members, f)
+	}
+
+	// North, then east.
+	for _, to := range []sim.Cell{{X: 10, Y: 25}, {X: 30, Y: 25}} {
+		require.True(t, w.WalkTo(leader, to))
+		run(d, 6)
+	}
+
+	assert.Equal(t, directiontype.East, leader.Direction)
+	for i, m := range w.Actors()[1:] {
+		assert.Equal(t, members[i].Slot(), m.Cell(), "member %d", i)
+		assert.Equal(t, leader.Direction, m.Direction, "member %d", i)
+		assert.NotEqual(t, statetype.Walking, m.State, "member %d", i)
+	}
+	// Behind the leader, the west, and to its left, the north.
+	assert.Equal(t, sim.Cell{X: 28, Y: 27}, members[0].Slot())
+}
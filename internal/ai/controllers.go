@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/project-midgard/midgarts/internal/sim"
+)
+
+// wanderTries is how many random cells Wander tries per tick to find one
+// it can walk to.
+const wanderTries = 8
+
+// Wander walks to random cells around a home cell, pausing between the
+// walks, as the monsters do.
+type Wander struct {
+	Home sim.Cell
+	// Radius is the distance from Home of the cells walked to, in cells.
+	Radius int
+	// Pause is the time standing between two walks.
+	Pause time.Duration
+	// Rand picks the cells, the default source of math/rand when nil.
+	Rand *rand.Rand
+
+	waited time.Duration
+}
+
+// NewWander returns a controller wandering within radius cells of home,
+// pausing 3 seconds between the walks.
+func NewWander(home sim.Cell, radius int) *Wander {
+	return &Wander{Home: home, Radius: radius, Pause: 3 * time.Second}
+}
+
+func (c *Wander) Next(w *sim.World, self *sim.Actor, dt float32) Action {
+	if destination, walking := self.Destination(); walking {
+		return Action{Kind: Move, Cell: destination}
+	}
+
+	c.waited += time.Duration(float64(dt) * float64(time.Second))
+	if c.waited < c.Pause {
+		return Action{Kind: Idle}
+	}
+
+	for i := 0; i < wanderTries; i++ {
+		cell := sim.Cell{X: c.Home.X + c.intn(2*c.Radius+1) - c.Radius, Y: c.Home.Y + c.intn(2*c.Radius+1) - c.Radius}
+		if cell == self.Cell() || !w.Walkable(cell) {
+			continue
+		}
+		if _, ok := w.FindPath(self, cell); ok {
+			c.waited = 0
+			return Action{Kind: Move, Cell: cell}
+		}
+	}
+
+	return Action{Kind: Idle}
+}
+
+func (c *Wander) intn(n int) int {
+	if c.Rand == nil {
+		return rand.Intn(n)
+	}
+
+	return c.Rand.Intn(n)
+}
+
+// Follow walks after a leader, stopping within Distance cells of it.
+type Follow struct {
+	Leader *sim.Actor
+	// Distance is the distance to the leader the follower keeps, in cells.
+	Distance int
+}
+
+// NewFollow returns a controller following leader, 2 cells behind.
+func NewFollow(leader *sim.Actor) *Follow {
+	return &Follow{Leader: leader, Distance: 2}
+}
+
+func (c *Follow) Next(w *sim.World, self *sim.Actor, dt float32) Action {
+	if _, ok := w.Actor(c.Leader.ID); !ok || sim.Distance(self.Cell(), c.Leader.Cell()) <= c.Distance {
+		return Action{Kind: Idle}
+	}
+
+	return Action{Kind: Move, Cell: c.Leader.Cell()}
+}
+
+// Aggro attacks the nearest hostile actor in sight, chasing it while it
+// stays in sight, and leaves the actor to Otherwise when none is.
+type Aggro struct {
+	// Sight is the distance at which the hostile actors are seen, in
+	// cells.
+	Sight int
+	// Hostile tells whether an actor is attacked.
+	Hostile func(*sim.Actor) bool
+	// Otherwise controls the actor when no hostile actor is in sight,
+	// standing by when nil.
+	Otherwise Controller
+}
+
+// NewAggro returns a controller attacking the hostile actors within 9
+// cells, as the aggressive monsters do, else leaving the actor to
+// otherwise.
+func NewAggro(hostile func(*sim.Actor) bool, otherwise Controller) *Aggro {
+	return &Aggro{Sight: 9, Hostile: hostile, Otherwise: otherwise}
+}
+
+func (c *Aggro) Next(w *sim.World, self *sim.Actor, dt float32) Action {
+	if target := self.Target(); target != nil && c.inSight(w, self, target) {
+		return Action{Kind: Attack, Target: target}
+	}
+
+	var nearest *sim.Actor
+	for _, other := range w.Actors() {
+		if other == self || !c.inSight(w, self, other) || !c.Hostile(other) {
+			continue
+		}
+		if nearest == nil || sim.Distance(self.Cell(), other.Cell()) < sim.Distance(self.Cell(), nearest.Cell()) {
+			nearest = other
+		}
+	}
+	if nearest != nil {
+		return Action{Kind: Attack, Target: nearest}
+	}
+
+	if c.Otherwise == nil {
+		return Action{Kind: Idle}
+	}
+
+	return c.Otherwise.Next(w, self, dt)
+}
+
+func (c *Aggro) inSight(w *sim.World, self, other *sim.Actor) bool {
+	if _, ok := w.Actor(other.ID); !ok {
+		return false
+	}
+
+	return sim.Distance(self.Cell(), other.Cell()) <= c.Sight
+}
@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"github.com/project-midgard/midgarts/internal/sim"
+)
+
+// slotSearch is the distance, in cells, from its slot at which a member
+// of a formation looks for a walkable cell when the slot is not.
+const slotSearch = 2
+
+// Patrol walks through waypoints, starting over from the first one once
+// the last one is reached.
+type Patrol struct {
+	Waypoints []sim.Cell
+
+	next int
+}
+
+// NewPatrol returns a controller walking through waypoints in a loop.
+func NewPatrol(waypoints ...sim.Cell) *Patrol {
+	return &Patrol{Waypoints: waypoints}
+}
+
+func (c *Patrol) Next(w *sim.World, self *sim.Actor, dt float32) Action {
+	if len(c.Waypoints) == 0 {
+		return Action{Kind: Idle}
+	}
+
+	// The waypoints that cannot be reached are skipped.
+	for tries := 0; tries < len(c.Waypoints); tries++ {
+		waypoint := c.Waypoints[c.next]
+		if destination, walking := self.Destination(); walking && destination == waypoint {
+			return Action{Kind: Move, Cell: waypoint}
+		}
+
+		if self.Cell() != waypoint {
+			if _, ok := w.FindPath(self, waypoint); ok {
+				return Action{Kind: Move, Cell: waypoint}
+			}
+		}
+		c.next = (c.next + 1) % len(c.Waypoints)
+	}
+
+	return Action{Kind: Idle}
+}
+
+// Formation keeps an actor at its slot of a formation around a leader:
+// Right cells to the right of the leader and Back cells behind it, as the
+// leader faces. The members stand facing as the leader does.
+type Formation struct {
+	Leader      *sim.Actor
+	Right, Back int
+}
+
+// NewFormation returns a controller keeping an actor right cells to the
+// right and back cells behind leader.
+func NewFormation(leader *sim.Actor, right, back int) *Formation {
+	return &Formation{Leader: leader, Right: right, Back: back}
+}
+
+// Slot returns the cell of the slot in the formation around the leader.
+func (c *Formation) Slot() sim.Cell {
+	forward := sim.Step(c.Leader.Direction)
+	// The right of the leader is a quarter turn clockwise, seen from above.
+	right := sim.Cell{X: forward.Y, Y: -forward.X}
+	leader := c.Leader.Cell()
+
+	return sim.Cell{
+		X: leader.X + c.Right*right.X - c.Back*forward.X,
+		Y: leader.Y + c.Right*right.Y - c.Back*forward.Y,
+	}
+}
+
+func (c *Formation) Next(w *sim.World, self *sim.Actor, dt float32) Action {
+	if _, ok := w.Actor(c.Leader.ID); !ok {
+		return Action{Kind: Idle}
+	}
+
+	slot, ok := w.NearestWalkable(c.Slot(), slotSearch)
+	if !ok {
+		// Around the leader, as a follower.
+		slot, ok = w.NearestWalkable(c.Leader.Cell(), slotSearch)
+		if !ok {
+			return Action{Kind: Idle}
+		}
+	}
+
+	if destination, walking := self.Destination(); (walking && destination == slot) || self.Cell() != slot {
+		return Action{Kind: Move, Cell: slot}
+	}
+
+	if _, leading := c.Leader.Destination(); !leading {
+		self.Direction = c.Leader.Direction
+	}
+
+	return Action{Kind: Idle}
+}
+
+// Ranks returns the slots, right and back of the leader, of n members of
+// a formation in ranks of width behind the leader, the ranks centered on
+// the leader and 2 cells apart. The slots of an even rank straddle the
+// leader.
+func Ranks(n, width int) [][2]int {
+	slots := make([][2]int, n)
+	for i := range slots {
+		rank, file := i/width, i%width
+		inRank := width
+		if left := n - rank*width; left < width {
+			inRank = left
+		}
+		slots[i] = [2]int{2*file - (inRank - 1), 2 * (rank + 1)}
+	}
+
+	return slots
+}
@@ -0,0 +1,260 @@
+// Package animation resolves the frames a character shows: which of its
+// attachments are drawn, in which order, at which frame of their action
+// and where. It knows nothing of the renderer, so every frontend animates
+// characters the same way and only turns the frames into draw calls.
+package animation
+
+import (
+	"time"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/actionindex"
+	"github.com/project-midgard/midgarts/internal/character/actionplaymode"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+)
+
+// MinFrameDuration is the shortest time a frame is shown, unless the
+// character forces the duration of its animation.
+const MinFrameDuration = 100 * time.Millisecond
+
+// Placement is the frame of an attachment to draw.
+type Placement struct {
+	Attachment character.AttachmentType
+	SPR        *spr.SpriteFile
+	Action     *act.Action
+	Frame      *act.ActionFrame
+	// Layers holds the layers of Frame to draw.
+	Layers []*act.ActionFrameLayer
+	// Position is the offset of the frame from the character origin, in
+	// pixels, anchoring the head to the body.
+	Position [2]float32
+}
+
+// Attachments returns the attachments of a character seen from the camera
+// of directions, back to front.
+func Attachments(char *entity.Character, directions pkgcharacter.DirectionResolver) []character.AttachmentType {
+	return AppendAttachments(nil, char, directions)
+}
+
+// AppendAttachments is like Attachments, appending the attachments to
+// attachments.
+func AppendAttachments(attachments []character.AttachmentType, char *entity.Character, directions pkgcharacter.DirectionResolver) []character.AttachmentType {
+	behind := directions.Behind(char.Direction)
+	withShield := char.HasShield && char.ActionIndex == actionindex.StandBy
+
+	if char.ActionIndex != actionindex.Dead && char.ActionIndex != actionindex.Sitting {
+		attachments = append(attachments, character.AttachmentShadow)
+	}
+
+	if behind && withShield {
+		attachments = append(attachments, character.AttachmentShield)
+	}
+
+	attachments = append(attachments, character.AttachmentBody, character.AttachmentHead)
+
+	for _, h := range [...]struct {
+		elem character.AttachmentType
+		id   int
+	}{
+		{character.AttachmentHeadgearLow, char.LowHeadgear},
+		{character.AttachmentHeadgearMid, char.MidHeadgear},
+		{character.AttachmentHeadgearTop, char.TopHeadgear},
+	} {
+		if h.id != 0 {
+			attachments = append(attachments, h.elem)
+		}
+	}
+
+	if char.Weapon != weapontype.None {
+		attachments = append(attachments, character.AttachmentWeapon, character.AttachmentWeaponSlash)
+	}
+
+	if !behind && withShield {
+		attachments = append(attachments, character.AttachmentShield)
+	}
+
+	return attachments
+}
+
+// ActionIndex returns the index, among actionCount actions, of the current
+// action of a character facing its direction, seen from the camera of
+// directions.
+func ActionIndex(char *entity.Character, directions pkgcharacter.DirectionResolver, actionCount int) int {
+	return directions.ActionIndex(int(char.ActionIndex), char.Direction, actionCount)
+}
+
+// FrameIndex returns the frame of action shown elapsed after the start of
+// the animation of a character.
+func FrameIndex(char *entity.Character, action *act.Action, elapsed time.Duration) int {
+	return TimelineFrameIndex(char, act.NewTimeline(action), elapsed)
+}
+
+// TimelineFrameIndex is like FrameIndex, with the precomputed timeline of
+// the action.
+func TimelineFrameIndex(char *entity.Character, timeline *act.Timeline, elapsed time.Duration) int {
+	frameCount := len(timeline.Ends)
+
+	// Ignore "doridori" animation
+	if frameCount == 3 || frameCount == 0 {
+		return 0
+	}
+
+	switch char.PlayMode {
+	case actionplaymode.Repeat:
+		return Progress(char, timeline, elapsed) % frameCount
+	case actionplaymode.PlayThenHold:
+		if frame := Progress(char, timeline, elapsed); frame < frameCount {
+			return frame
+		}
+		return frameCount - 1
+	}
+
+	return 0
+}
+
+// Progress returns the frame of timeline started elapsed after the start
+// of the animation of a character, counting the repeats: frames from
+// len(timeline.Ends) on are past the first play through. Unlike
+// TimelineFrameIndex, it goes on for every play mode.
+func Progress(char *entity.Character, timeline *act.Timeline, elapsed time.Duration) int {
+	frameCount := len(timeline.Ends)
+	if frameCount == 0 || elapsed < 0 {
+		return 0
+	}
+
+	frameDuration := time.Duration(float64(timeline.Delay) / char.FPSMultiplier)
+	if frameDuration < MinFrameDuration {
+		frameDuration = MinFrameDuration
+	}
+
+	// Forced durations are kept whole, e.g. the attack motion, so the
+	// animation ends when the damage is applied.
+	if char.ForcedDuration != 0 {
+		frameDuration = char.ForcedDuration / time.Duration(frameCount)
+		if frameDuration <= 0 {
+			frameDuration = 1
+		}
+	}
+
+	// Back to the normal speed of the timeline.
+	period := frameDuration * time.Duration(frameCount)
+	frame := timeline.FrameAt(time.Duration(float64(elapsed%period) * float64(timeline.Delay) / float64(frameDuration)))
+
+	return int(elapsed/period)*frameCount + frame
+}
+
+// CurrentTimeline returns the timeline of the current action of an
+// attachment of a character seen from the camera of directions, if loaded.
+func CurrentTimeline(char *entity.Character, elem character.AttachmentType, directions pkgcharacter.DirectionResolver) (*act.Timeline, bool) {
+	if char.CharacterAttachmentComponent == nil {
+		return nil, false
+	}
+
+	files, ok := char.Files[elem]
+	if !ok || files.ACT == nil || len(files.ACT.Actions) == 0 {
+		return nil, false
+	}
+
+	index := ActionIndex(char, directions, len(files.ACT.Actions))
+	return actionTimeline(char, elem, index, files.ACT.Actions[index]), true
+}
+
+// Pose returns the frames of the loaded attachments of a character seen
+// from the camera of directions, back to front, elapsed after the start of
+// its animation. Attachments whose frame is empty are left out.
+func Pose(char *entity.Character, directions pkgcharacter.DirectionResolver, elapsed time.Duration) []Placement {
+	return AppendPose(nil, char, directions, elapsed)
+}
+
+// AppendPose is like Pose, appending the frames to placements, so the
+// renderer can reuse them from a frame to the next. The head of the
+// players is placed behind their body at the frames their IMF file tells
+// so (see CharacterAttachmentComponent.Priorities).
+func AppendPose(placements []Placement, char *entity.Character, directions pkgcharacter.DirectionResolver, elapsed time.Duration) []Placement {
+	var (
+		// The attachments of a character fit, not allocated.
+		buf [character.NumAttachments]character.AttachmentType
+		// The placement of the body, its action, frame and anchor, if any.
+		body, bodyAction, bodyFrame = -1, 0, 0
+		anchor                      [2]float32
+		// Whether the head, and so its headgears, is behind the body.
+		headBehind bool
+	)
+
+	if char.CharacterAttachmentComponent == nil {
+		return placements
+	}
+
+	for _, elem := range AppendAttachments(buf[:0], char, directions) {
+		files, ok := char.Files[elem]
+		if !ok || len(files.ACT.Actions) == 0 {
+			continue
+		}
+
+		actionIndex := ActionIndex(char, directions, len(files.ACT.Actions))
+		action := files.ACT.Actions[actionIndex]
+		if len(action.Frames) == 0 {
+			continue
+		}
+
+		timeline := actionTimeline(char, elem, actionIndex, action)
+		frameIndex := TimelineFrameIndex(char, timeline, elapsed)
+		frame := action.Frames[frameIndex]
+		if len(frame.Layers) == 0 {
+			continue
+		}
+
+		// The head, the headgears and the weapon are aligned on the anchor
+		// point of the body at its frame, e.g. its neck for the head.
+		var position [2]float32
+		if a, ok := frame.Anchor(); ok && elem != character.AttachmentBody && elem != character.AttachmentShield {
+			position = [2]float32{anchor[0] - float32(a.X), anchor[1] - float32(a.Y)}
+		}
+
+		p := Placement{
+			Attachment: elem,
+			SPR:        files.SPR,
+			Action:     action,
+			Frame:      frame,
+			Layers:     timeline.Layers[frameIndex],
+			Position:   position,
+		}
+		switch {
+		case elem == character.AttachmentBody:
+			body, bodyAction, bodyFrame = len(placements), actionIndex, frameIndex
+			if a, ok := frame.Anchor(); ok {
+				anchor = [2]float32{float32(a.X), float32(a.Y)}
+			}
+		case elem == character.AttachmentHead && body >= 0 && body == len(placements)-1 &&
+			char.Priorities != nil && char.Priorities.HeadBehind(bodyAction, bodyFrame):
+			headBehind = true
+			fallthrough
+		case elem.IsHeadgear() && headBehind:
+			// Anchored to the body, the head and its headgears are placed
+			// after it but drawn before it.
+			placements = append(placements, Placement{})
+			copy(placements[body+1:], placements[body:])
+			placements[body] = p
+			body++
+			continue
+		}
+
+		placements = append(placements, p)
+	}
+
+	return placements
+}
+
+// actionTimeline returns the timeline of the action of an attachment,
+// computed when the character has none.
+func actionTimeline(char *entity.Character, elem character.AttachmentType, index int, action *act.Action) *act.Timeline {
+	if timelines := char.Timelines[elem]; index < len(timelines) {
+		return timelines[index]
+	}
+
+	return act.NewTimeline(action)
+}
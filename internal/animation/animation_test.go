@@ -0,0 +1,253 @@
+package animation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/actionindex"
+	"github.com/project-midgard/midgarts/internal/character/actionplaymode"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/imf"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+)
+
+// camera is the camera of the client, from which the character
+// directions are seen as is.
+var camera = pkgcharacter.ResolverForCameraDirection(pkgcharacter.DefaultCameraDirection)
+
+func TestAttachments(t *testing.T) {
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	char.HasShield = true
+	char.ActionIndex = actionindex.StandBy
+
+	char.Direction = directiontype.South
+	assert.Equal(t, []character.AttachmentType{
+		character.AttachmentShadow,
+		character.AttachmentBody,
+		character.AttachmentHead,
+		character.AttachmentShield,
+	}, Attachments(char, camera))
+
+	char.Direction = directiontype.North
+	assert.Equal(t, []character.AttachmentType{
+		character.AttachmentShadow,
+		character.AttachmentShield,
+		character.AttachmentBody,
+		character.AttachmentHead,
+	}, Attachments(char, camera))
+
+	char.ActionIndex = actionindex.Sitting
+	assert.Equal(t, []character.AttachmentType{
+		character.AttachmentBody,
+		character.AttachmentHead,
+	}, Attachments(char, camera))
+
+	// Over the head, from the lowest.
+	char.TopHeadgear, char.LowHeadgear = 5, 12
+	assert.Equal(t, []character.AttachmentType{
+		character.AttachmentBody,
+		character.AttachmentHead,
+		character.AttachmentHeadgearLow,
+		character.AttachmentHeadgearTop,
+	}, Attachments(char, camera))
+
+	// The weapon and its slash over the headgears.
+	char.Weapon = weapontype.Sword
+	assert.Equal(t, []character.AttachmentType{
+		character.AttachmentBody,
+		character.AttachmentHead,
+		character.AttachmentHeadgearLow,
+		character.AttachmentHeadgearTop,
+		character.AttachmentWeapon,
+		character.AttachmentWeaponSlash,
+	}, Attachments(char, camera))
+}
+
+func TestFrameIndex(t *testing.T) {
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	action := &act.Action{Delay: 200, Frames: make([]*act.ActionFrame, 4)}
+
+	assert.Equal(t, 0, FrameIndex(char, action, 100*time.Millisecond))
+	assert.Equal(t, 1, FrameIndex(char, action, 200*time.Millisecond))
+	assert.Equal(t, 0, FrameIndex(char, action, 800*time.Millisecond))
+
+	// Frames are shown at least MinFrameDuration.
+	action.Delay = 10
+	assert.Equal(t, 1, FrameIndex(char, action, MinFrameDuration))
+
+	char.ForcedDuration = 2 * time.Second
+	assert.Equal(t, 2, FrameIndex(char, action, time.Second))
+
+	// Three frames actions ("doridori") are not animated.
+	action.Frames = make([]*act.ActionFrame, 3)
+	assert.Equal(t, 0, FrameIndex(char, action, time.Second))
+}
+
+// actAction returns an action of frameCount frames of an ACT file whose
+// interval is interval, in the 25 ms ticks of the files.
+func actAction(interval float32, frameCount int) *act.Action {
+	return &act.Action{Delay: uint32(interval * 25), Frames: make([]*act.ActionFrame, frameCount)}
+}
+
+func TestFrameSelection(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Action         *act.Action
+		PlayMode       actionplaymode.Type
+		FPSMultiplier  float64
+		ForcedDuration time.Duration
+		Elapsed        time.Duration
+		Frame          int
+	}{
+		{Name: "stand by start", Action: actAction(4, 8), Elapsed: 0, Frame: 0},
+		{Name: "stand by frame end", Action: actAction(4, 8), Elapsed: 99 * time.Millisecond, Frame: 0},
+		{Name: "stand by frame start", Action: actAction(4, 8), Elapsed: 100 * time.Millisecond, Frame: 1},
+		{Name: "stand by last frame", Action: actAction(4, 8), Elapsed: 750 * time.Millisecond, Frame: 7},
+		{Name: "stand by repeat", Action: actAction(4, 8), Elapsed: 1250 * time.Millisecond, Frame: 4},
+		// 75 ms frames, shown MinFrameDuration.
+		{Name: "fast walk", Action: actAction(3, 8), Elapsed: 150 * time.Millisecond, Frame: 1},
+		{Name: "slow walk", Action: actAction(4, 8), FPSMultiplier: 0.5, Elapsed: 350 * time.Millisecond, Frame: 1},
+		{Name: "quick walk", Action: actAction(6, 8), FPSMultiplier: 1.5, Elapsed: 350 * time.Millisecond, Frame: 3},
+		{Name: "no interval", Action: actAction(0, 4), Elapsed: 250 * time.Millisecond, Frame: 2},
+		// The attack motion at 150 ASPD, 500 ms.
+		{Name: "attack", Action: actAction(6, 5), PlayMode: actionplaymode.PlayThenHold, ForcedDuration: 500 * time.Millisecond, Elapsed: 250 * time.Millisecond, Frame: 2},
+		{Name: "attack end", Action: actAction(6, 5), PlayMode: actionplaymode.PlayThenHold, ForcedDuration: 500 * time.Millisecond, Elapsed: 499 * time.Millisecond, Frame: 4},
+		{Name: "attack hold", Action: actAction(6, 5), PlayMode: actionplaymode.PlayThenHold, ForcedDuration: 500 * time.Millisecond, Elapsed: 900 * time.Millisecond, Frame: 4},
+		// The attack motion at 190 ASPD, 100 ms.
+		{Name: "fast attack", Action: actAction(6, 5), PlayMode: actionplaymode.PlayThenHold, ForcedDuration: 100 * time.Millisecond, Elapsed: 45 * time.Millisecond, Frame: 2},
+		{Name: "doridori", Action: actAction(4, 3), Elapsed: 150 * time.Millisecond, Frame: 0},
+		{Name: "once", Action: actAction(4, 8), PlayMode: actionplaymode.Once, Elapsed: 150 * time.Millisecond, Frame: 0},
+	}
+
+	for _, tt := range tests {
+		char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+		char.PlayMode = tt.PlayMode
+		char.ForcedDuration = tt.ForcedDuration
+		if tt.FPSMultiplier != 0 {
+			char.FPSMultiplier = tt.FPSMultiplier
+		}
+
+		assert.Equal(t, tt.Frame, FrameIndex(char, tt.Action, tt.Elapsed), tt.Name)
+	}
+}
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &ManualClock{T: start}
+
+	assert.Equal(t, start, clock.Now())
+	clock.Advance(20 * time.Millisecond)
+	assert.Equal(t, start.Add(20*time.Millisecond), clock.Now())
+}
+
+func TestTimelineFrameIndex(t *testing.T) {
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	timeline := act.NewTimeline(&act.Action{Delay: 200, Frames: make([]*act.ActionFrame, 4)})
+
+	assert.Equal(t, 800*time.Millisecond, timeline.Duration())
+	for elapsed := time.Duration(0); elapsed < 2*time.Second; elapsed += 50 * time.Millisecond {
+		assert.Equal(t, int(elapsed/(200*time.Millisecond))%4, TimelineFrameIndex(char, timeline, elapsed), elapsed)
+	}
+
+	char.FPSMultiplier = 2
+	assert.Equal(t, 1, TimelineFrameIndex(char, timeline, 100*time.Millisecond))
+	assert.Equal(t, 3, TimelineFrameIndex(char, timeline, 350*time.Millisecond))
+}
+
+func TestProgress(t *testing.T) {
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	timeline := act.NewTimeline(&act.Action{Delay: 200, Frames: make([]*act.ActionFrame, 4)})
+
+	assert.Equal(t, 0, Progress(char, timeline, -time.Millisecond))
+	assert.Equal(t, 3, Progress(char, timeline, 700*time.Millisecond))
+	assert.Equal(t, 5, Progress(char, timeline, 1100*time.Millisecond))
+
+	// The frames go on past the first play through whatever the mode.
+	char.PlayMode = actionplaymode.Once
+	assert.Equal(t, 5, Progress(char, timeline, 1100*time.Millisecond))
+	assert.Equal(t, 0, TimelineFrameIndex(char, timeline, 1100*time.Millisecond))
+}
+
+func TestPlayThenHold(t *testing.T) {
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	char.PlayMode = actionplaymode.PlayThenHold
+	char.ForcedDuration = 400 * time.Millisecond
+	timeline := act.NewTimeline(&act.Action{Delay: 200, Frames: make([]*act.ActionFrame, 4)})
+
+	// Played in ForcedDuration, then holding the last frame.
+	assert.Equal(t, 2, TimelineFrameIndex(char, timeline, 250*time.Millisecond))
+	assert.Equal(t, 3, TimelineFrameIndex(char, timeline, 350*time.Millisecond))
+	assert.Equal(t, 3, TimelineFrameIndex(char, timeline, time.Second))
+
+	// Forced durations are not bounded by MinFrameDuration.
+	char.ForcedDuration = 200 * time.Millisecond
+	assert.Equal(t, 2, TimelineFrameIndex(char, timeline, 100*time.Millisecond))
+}
+
+func TestPose(t *testing.T) {
+	frame := func(x, y int32) *act.ActionFrame {
+		return &act.ActionFrame{
+			Layers:  []*act.ActionFrameLayer{{}},
+			Anchors: []act.AnchorPoint{{X: x, Y: y}},
+		}
+	}
+	files := func(f *act.ActionFrame) grf.ActionSpriteFilePair {
+		return grf.ActionSpriteFilePair{ACT: &act.ActionFile{Actions: []*act.Action{{Frames: []*act.ActionFrame{f}}}}}
+	}
+
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	char.SetCharacterAttachmentComponent(&component.CharacterAttachmentComponent{
+		Files: map[character.AttachmentType]grf.ActionSpriteFilePair{
+			character.AttachmentShadow: files(&act.ActionFrame{}),
+			character.AttachmentBody:   files(frame(0, -70)),
+			character.AttachmentHead:   files(frame(2, 5)),
+		},
+	})
+
+	placements := Pose(char, camera, 0)
+	require.Len(t, placements, 2)
+	assert.Equal(t, character.AttachmentBody, placements[0].Attachment)
+	assert.Equal(t, [2]float32{0, 0}, placements[0].Position)
+
+	// The head is anchored to the body.
+	assert.Equal(t, character.AttachmentHead, placements[1].Attachment)
+	assert.Equal(t, [2]float32{-2, -75}, placements[1].Position)
+	assert.Len(t, placements[1].Layers, 1)
+	// The headgears are anchored to the body too.
+	char.Files[character.AttachmentHeadgearTop] = files(frame(1, 1))
+	char.TopHeadgear = 5
+	placements = Pose(char, camera, 0)
+	require.Len(t, placements, 3)
+	assert.Equal(t, character.AttachmentHeadgearTop, placements[2].Attachment)
+	assert.Equal(t, [2]float32{-1, -71}, placements[2].Position)
+
+	// So is the weapon.
+	char.Files[character.AttachmentWeapon] = files(frame(3, 0))
+	char.Weapon = weapontype.Sword
+	placements = Pose(char, camera, 0)
+	require.Len(t, placements, 4)
+	assert.Equal(t, character.AttachmentWeapon, placements[3].Attachment)
+	assert.Equal(t, [2]float32{-3, -70}, placements[3].Position)
+	char.Weapon = weapontype.None
+
+	// Behind the body, as its IMF file tells, the head and its headgears
+	// keep their anchors.
+	char.Priorities = &imf.PriorityFile{Layers: [][][]imf.Frame{{{{Priority: 1}}}, {{{Priority: 0}}}}}
+	placements = Pose(char, camera, 0)
+	require.Len(t, placements, 3)
+	assert.Equal(t, character.AttachmentHead, placements[0].Attachment)
+	assert.Equal(t, [2]float32{-2, -75}, placements[0].Position)
+	assert.Equal(t, character.AttachmentHeadgearTop, placements[1].Attachment)
+	assert.Equal(t, [2]float32{-1, -71}, placements[1].Position)
+	assert.Equal(t, character.AttachmentBody, placements[2].Attachment)
+}
@@ -0,0 +1,33 @@
+package animation
+
+import "time"
+
+// Clock tells the time the animations start and end at. The frames
+// themselves only depend on the time played since the start of an
+// animation (see Progress), so animating with a ManualClock and fixed
+// steps always shows the same frames.
+type Clock interface {
+	Now() time.Time
+}
+
+// WallClock is the Clock of the system time.
+type WallClock struct{}
+
+func (WallClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock is a Clock only moving when told to, e.g. in tests or when
+// replaying a recording.
+type ManualClock struct {
+	T time.Time
+}
+
+func (c *ManualClock) Now() time.Time {
+	return c.T
+}
+
+// Advance moves the clock d forward.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.T = c.T.Add(d)
+}
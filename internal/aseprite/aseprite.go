@@ -0,0 +1,340 @@
+// Package aseprite exports ACT+SPR sprites as an Aseprite sprite sheet: a
+// packed PNG sheet and the JSON data Aseprite writes with --data, so the
+// sprites can be opened and edited in modern pixel-art tools.
+package aseprite
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/portrait"
+	"github.com/project-midgard/midgarts/pkg/version"
+)
+
+// OriginSlice is the name of the slice whose pivot is the sprite origin
+// (the character feet) inside each frame.
+const OriginSlice = "origin"
+
+const actionTagPrefix = "action "
+
+// ActionTag returns the name of the frame tag of the action a.
+func ActionTag(a int) string {
+	return actionTagPrefix + strconv.Itoa(a)
+}
+
+// TagAction returns the action of a frame tag named by ActionTag.
+func TagAction(name string) (int, bool) {
+	if !strings.HasPrefix(name, actionTagPrefix) {
+		return 0, false
+	}
+
+	a, err := strconv.Atoi(strings.TrimPrefix(name, actionTagPrefix))
+	return a, err == nil
+}
+
+type Rect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type Size struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type Frame struct {
+	Filename         string `json:"filename"`
+	Frame            Rect   `json:"frame"`
+	Rotated          bool   `json:"rotated"`
+	Trimmed          bool   `json:"trimmed"`
+	SpriteSourceSize Rect   `json:"spriteSourceSize"`
+	SourceSize       Size   `json:"sourceSize"`
+	Duration         int    `json:"duration"`
+}
+
+type FrameTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+}
+
+type SliceKey struct {
+	Frame  int   `json:"frame"`
+	Bounds Rect  `json:"bounds"`
+	Pivot  Point `json:"pivot"`
+}
+
+type Slice struct {
+	Name  string     `json:"name"`
+	Color string     `json:"color"`
+	Keys  []SliceKey `json:"keys"`
+}
+
+type Layer struct {
+	Name      string `json:"name"`
+	Opacity   int    `json:"opacity"`
+	BlendMode string `json:"blendMode"`
+}
+
+type Meta struct {
+	App       string     `json:"app"`
+	Version   string     `json:"version"`
+	Image     string     `json:"image"`
+	Format    string     `json:"format"`
+	Size      Size       `json:"size"`
+	Scale     string     `json:"scale"`
+	FrameTags []FrameTag `json:"frameTags"`
+	Layers    []Layer    `json:"layers"`
+	Slices    []Slice    `json:"slices"`
+}
+
+// Data is the JSON data of a sheet, in Aseprite "array" format.
+type Data struct {
+	Frames []Frame `json:"frames"`
+	Meta   Meta    `json:"meta"`
+}
+
+type Sheet struct {
+	Image *image.RGBA
+	Data  Data
+}
+
+type cell struct {
+	img    *image.RGBA
+	bounds image.Rectangle // opaque bounds, in canvas coordinates
+}
+
+// Export composes every ACT frame and packs them into a sheet. All the
+// frames share the same canvas, so the origin stays at the same place;
+// empty space is trimmed from the sheet. imageName is the file name the
+// sheet image is saved as, referenced by the data.
+func Export(actFile *act.ActionFile, sprFile *spr.SpriteFile, name, imageName string) (*Sheet, error) {
+	var (
+		frames []*image.RGBA
+		mins   []image.Point
+		canvas image.Rectangle
+	)
+
+	for _, action := range actFile.Actions {
+		for _, frame := range action.Frames {
+			img, min := composeFrame(sprFile, frame)
+			frames = append(frames, img)
+			mins = append(mins, min)
+
+			if img != nil {
+				canvas = canvas.Union(img.Bounds().Add(min))
+			}
+		}
+	}
+
+	if canvas.Empty() {
+		return nil, errors.New("sprite has no visible frames")
+	}
+
+	cells := make([]cell, len(frames))
+	for i, img := range frames {
+		cells[i] = trim(img, mins[i].Sub(canvas.Min))
+	}
+
+	sheet := &Sheet{
+		Data: Data{
+			Meta: Meta{
+				App:     "https://github.com/project-midgard/midgarts",
+				Version: version.Get(),
+				Image:   imageName,
+				Format:  "RGBA8888",
+				Scale:   "1",
+				Layers:  []Layer{{Name: name, Opacity: 255, BlendMode: "normal"}},
+				Slices: []Slice{{
+					Name:  OriginSlice,
+					Color: "#0000ffff",
+					Keys: []SliceKey{{
+						Bounds: Rect{W: canvas.Dx(), H: canvas.Dy()},
+						Pivot:  Point{X: -canvas.Min.X, Y: -canvas.Min.Y},
+					}},
+				}},
+			},
+		},
+	}
+
+	positions, size := pack(cells)
+	sheet.Image = image.NewRGBA(image.Rectangle{Max: size})
+	sheet.Data.Meta.Size = Size{W: size.X, H: size.Y}
+
+	i := 0
+	for a, action := range actFile.Actions {
+		if len(action.Frames) == 0 {
+			continue
+		}
+
+		duration := int(action.Delay)
+		if duration == 0 {
+			duration = act.ActionDefaultDelay
+		}
+
+		sheet.Data.Meta.FrameTags = append(sheet.Data.Meta.FrameTags, FrameTag{
+			Name:      ActionTag(a),
+			From:      i,
+			To:        i + len(action.Frames) - 1,
+			Direction: "forward",
+		})
+
+		for range action.Frames {
+			c := cells[i]
+			dst := image.Rectangle{Min: positions[i], Max: positions[i].Add(c.bounds.Size())}
+			if c.img != nil {
+				draw.Draw(sheet.Image, dst, c.img, c.bounds.Min, draw.Src)
+			}
+
+			sheet.Data.Frames = append(sheet.Data.Frames, Frame{
+				Filename:         name + " " + strconv.Itoa(i) + ".aseprite",
+				Frame:            rect(dst),
+				Trimmed:          true,
+				SpriteSourceSize: rect(c.bounds),
+				SourceSize:       Size{W: canvas.Dx(), H: canvas.Dy()},
+				Duration:         duration,
+			})
+			i++
+		}
+	}
+
+	return sheet, nil
+}
+
+// composeFrame draws the layers of a frame, returning the image and the
+// position of its top-left corner relative to the frame origin.
+func composeFrame(sprFile *spr.SpriteFile, frame *act.ActionFrame) (*image.RGBA, image.Point) {
+	type placed struct {
+		img *image.RGBA
+		min image.Point
+	}
+
+	var (
+		layers []placed
+		bounds image.Rectangle
+	)
+
+	for _, layer := range frame.Layers {
+		img := portrait.LayerImage(sprFile, layer)
+		if img == nil {
+			continue
+		}
+
+		size := img.Bounds().Size()
+		min := image.Point{
+			X: int(layer.Position[0]) - size.X/2,
+			Y: int(layer.Position[1]) - size.Y/2,
+		}
+
+		layers = append(layers, placed{img: img, min: min})
+		bounds = bounds.Union(image.Rectangle{Min: min, Max: min.Add(size)})
+	}
+
+	if bounds.Empty() {
+		return nil, image.Point{}
+	}
+
+	res := image.NewRGBA(image.Rectangle{Max: bounds.Size()})
+	for _, l := range layers {
+		min := l.min.Sub(bounds.Min)
+		draw.Draw(res, image.Rectangle{Min: min, Max: min.Add(l.img.Bounds().Size())}, l.img, image.Point{}, draw.Over)
+	}
+
+	return res, bounds.Min
+}
+
+// trim returns the opaque part of a frame image placed at offset in the
+// canvas. Empty frames keep a single transparent pixel, as Aseprite does.
+func trim(img *image.RGBA, offset image.Point) cell {
+	empty := cell{bounds: image.Rect(0, 0, 1, 1)}
+	if img == nil {
+		return empty
+	}
+
+	b := img.Bounds()
+	opaque := image.Rectangle{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y).A != 0 {
+				opaque = opaque.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	if opaque.Empty() {
+		return empty
+	}
+
+	// Shift the image so its bounds are in canvas coordinates.
+	shifted := &image.RGBA{Pix: img.Pix, Stride: img.Stride, Rect: b.Add(offset)}
+
+	return cell{img: shifted, bounds: opaque.Add(offset)}
+}
+
+// pack places the cells in rows of roughly the same width, returning the
+// position of each cell and the sheet size.
+func pack(cells []cell) ([]image.Point, image.Point) {
+	area := 0
+	widest := 0
+	for _, c := range cells {
+		s := c.bounds.Size()
+		area += s.X * s.Y
+		if s.X > widest {
+			widest = s.X
+		}
+	}
+
+	maxWidth := int(math.Ceil(math.Sqrt(float64(area))))
+	if maxWidth < widest {
+		maxWidth = widest
+	}
+
+	var (
+		positions = make([]image.Point, len(cells))
+		cursor    image.Point
+		rowHeight int
+		size      image.Point
+	)
+
+	for i, c := range cells {
+		s := c.bounds.Size()
+		if cursor.X > 0 && cursor.X+s.X > maxWidth {
+			cursor = image.Point{Y: cursor.Y + rowHeight}
+			rowHeight = 0
+		}
+
+		positions[i] = cursor
+		cursor.X += s.X
+		if s.Y > rowHeight {
+			rowHeight = s.Y
+		}
+
+		if cursor.X > size.X {
+			size.X = cursor.X
+		}
+		if cursor.Y+rowHeight > size.Y {
+			size.Y = cursor.Y + rowHeight
+		}
+	}
+
+	return positions, size
+}
+
+func rect(r image.Rectangle) Rect {
+	return Rect{X: r.Min.X, Y: r.Min.Y, W: r.Dx(), H: r.Dy()}
+}
@@ -0,0 +1,98 @@
+package aseprite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/graphic"
+)
+
+// newSprite returns a sprite of two 2x2 fully opaque paletted frames.
+func newSprite() *spr.SpriteFile {
+	f := &spr.SpriteFile{
+		Frames: []*spr.SpriteFrame{
+			{SpriteType: spr.FileTypePAL, Width: 2, Height: 2, Data: []byte{1, 1, 1, 1}},
+			{SpriteType: spr.FileTypePAL, Width: 2, Height: 2, Data: []byte{2, 2, 2, 2}},
+		},
+		Images: make([]*graphic.UniqueRGBA, 2),
+	}
+	f.Header.PalettedFrameCount = 2
+	f.Palette[4], f.Palette[8] = 255, 128
+
+	return f
+}
+
+func layer(index int32, x, y int32) *act.ActionFrameLayer {
+	return &act.ActionFrameLayer{SpriteFrameIndex: index, Position: [2]int32{x, y}, Scale: [2]float32{1, 1}}
+}
+
+func TestExport(t *testing.T) {
+	actFile := &act.ActionFile{Actions: []*act.Action{
+		{
+			Delay: 150,
+			Frames: []*act.ActionFrame{
+				{Layers: []*act.ActionFrameLayer{layer(0, 0, 0)}},
+				{Layers: []*act.ActionFrameLayer{layer(1, 4, -2)}},
+			},
+		},
+		{Frames: []*act.ActionFrame{}},
+		{
+			Frames: []*act.ActionFrame{
+				{Layers: []*act.ActionFrameLayer{layer(-1, 0, 0)}},
+			},
+		},
+	}}
+
+	sheet, err := Export(actFile, newSprite(), "poring", "poring.png")
+	require.NoError(t, err)
+
+	data := sheet.Data
+	require.Len(t, data.Frames, 3)
+
+	// The canvas covers both frames: (-1, -3) to (5, 1).
+	for _, f := range data.Frames {
+		assert.Equal(t, Size{W: 6, H: 4}, f.SourceSize)
+		assert.True(t, f.Trimmed)
+	}
+	assert.Equal(t, Rect{X: 0, Y: 2, W: 2, H: 2}, data.Frames[0].SpriteSourceSize)
+	assert.Equal(t, Rect{X: 4, Y: 0, W: 2, H: 2}, data.Frames[1].SpriteSourceSize)
+	assert.Equal(t, Rect{W: 1, H: 1}, data.Frames[2].SpriteSourceSize)
+
+	assert.Equal(t, 150, data.Frames[0].Duration)
+	assert.Equal(t, act.ActionDefaultDelay, data.Frames[2].Duration)
+
+	assert.Equal(t, []FrameTag{
+		{Name: "action 0", From: 0, To: 1, Direction: "forward"},
+		{Name: "action 2", From: 2, To: 2, Direction: "forward"},
+	}, data.Meta.FrameTags)
+
+	require.Len(t, data.Meta.Slices, 1)
+	assert.Equal(t, Point{X: 1, Y: 3}, data.Meta.Slices[0].Keys[0].Pivot)
+	assert.Equal(t, "poring.png", data.Meta.Image)
+
+	// Frames are packed without overlapping and keep their pixels.
+	assert.Equal(t, Size{W: sheet.Image.Bounds().Dx(), H: sheet.Image.Bounds().Dy()}, data.Meta.Size)
+	first, second := data.Frames[0].Frame, data.Frames[1].Frame
+	assert.Equal(t, uint8(255), sheet.Image.RGBAAt(first.X, first.Y).R)
+	assert.Equal(t, uint8(128), sheet.Image.RGBAAt(second.X, second.Y).R)
+}
+
+func TestExportEmpty(t *testing.T) {
+	actFile := &act.ActionFile{Actions: []*act.Action{{Frames: []*act.ActionFrame{{}}}}}
+
+	_, err := Export(actFile, newSprite(), "empty", "empty.png")
+	assert.Error(t, err)
+}
+
+func TestTagAction(t *testing.T) {
+	a, ok := TagAction(ActionTag(42))
+	assert.True(t, ok)
+	assert.Equal(t, 42, a)
+
+	_, ok = TagAction("walk")
+	assert.False(t, ok)
+}
@@ -0,0 +1,396 @@
+// Package asset loads sprites in the background. The ACT and SPR files are
+// read and their images decoded and packed in an atlas on worker
+// goroutines, then the atlas is uploaded on the main thread, where the
+// OpenGL context lives.
+//
+// The sprites loaded with a Scope are reference counted: once every scope
+// using a sprite is released, its textures are deleted. The sprites loaded
+// directly from the Manager are kept until it is closed.
+package asset
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/imf"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+// DefaultWorkers is the number of sprites loaded at the same time.
+const DefaultWorkers = 4
+
+// DefaultUploadBudget is the size of the textures uploaded per frame, a
+// few body sprites.
+const DefaultUploadBudget = 4 << 20
+
+// Sprite is the handle of a sprite requested from a Manager. Its files can
+// be used once Done is closed; its textures are uploaded once Ready.
+type Sprite struct {
+	Path string
+
+	done     chan struct{}
+	files    grf.ActionSpriteFilePair
+	err      error
+	ready    int32
+	uploaded chan struct{}
+
+	// Guarded by the mutex of the manager.
+	refs     int
+	pinned   bool
+	released bool
+}
+
+// Done is closed once the files are loaded, or failed to.
+func (s *Sprite) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait returns the files once loaded, or ctx.Err() when ctx is done first.
+func (s *Sprite) Wait(ctx context.Context) (grf.ActionSpriteFilePair, error) {
+	select {
+	case <-s.done:
+		return s.files, s.err
+	case <-ctx.Done():
+		return grf.ActionSpriteFilePair{}, ctx.Err()
+	}
+}
+
+// Ready reports whether the sprite is loaded and its textures uploaded.
+func (s *Sprite) Ready() bool {
+	return atomic.LoadInt32(&s.ready) == 1
+}
+
+// WaitReady returns once the sprite is Ready, or ctx.Err() when ctx is
+// done first. The textures are uploaded by Manager.Update, on the main
+// thread: it must not be called from there.
+func (s *Sprite) WaitReady(ctx context.Context) error {
+	select {
+	case <-s.uploaded:
+		return s.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Progress is the count of requested sprites that are ready, or failed.
+type Progress struct {
+	Done, Total int
+}
+
+func (p Progress) Complete() bool {
+	return p.Done == p.Total
+}
+
+// Manager loads sprites on worker goroutines, each path once. It is also a
+// system: Update uploads the textures of the loaded sprites, so it must be
+// called on the main thread. The uploads are queued and spread over the
+// frames, UploadBudget per frame, so many sprites appearing at once do
+// not make a frame spike.
+type Manager struct {
+	// UploadBudget is the size, in bytes, of the textures uploaded per
+	// Update, 0 for no limit. A sprite is uploaded whole: at least one is
+	// uploaded per Update, whatever its size.
+	UploadBudget int
+
+	ctx             context.Context
+	cancel          context.CancelFunc
+	log             zerolog.Logger
+	fs              vfs.FS
+	textureProvider graphic.TextureProvider
+	workers         chan struct{}
+	wg              sync.WaitGroup
+
+	mu       sync.Mutex
+	sprites  map[string]*Sprite
+	loaded   []*Sprite
+	released []*Sprite
+	progress Progress
+}
+
+// The manager and its scopes are filesystems (see vfs.FS) caching the
+// sprites, so the systems loading characters can read from them.
+var (
+	_ vfs.FS = (*Manager)(nil)
+	_ vfs.FS = (*Scope)(nil)
+)
+
+// NewManager returns a manager loading at most workers sprites of fs at
+// the same time. ctx cancels the loading and carries its logger (see
+// logging.For).
+func NewManager(
+	ctx context.Context,
+	fs vfs.FS,
+	textureProvider graphic.TextureProvider,
+	workers int,
+) *Manager {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &Manager{
+		UploadBudget:    DefaultUploadBudget,
+		ctx:             ctx,
+		cancel:          cancel,
+		log:             logging.For(ctx, logging.Asset),
+		fs:              fs,
+		textureProvider: textureProvider,
+		workers:         make(chan struct{}, workers),
+		sprites:         map[string]*Sprite{},
+	}
+}
+
+// Load requests a sprite by its path without extension, returning its
+// handle. Sprites already requested are not loaded again. The sprite is
+// never released, see Scope to release it.
+func (m *Manager) Load(path string) *Sprite {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.sprite(path)
+	s.pinned = true
+
+	return s
+}
+
+// acquire requests a sprite and adds a reference to it.
+func (m *Manager) acquire(path string) *Sprite {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.sprite(path)
+	s.refs++
+
+	return s
+}
+
+// release removes a reference to a sprite. The textures of the sprites
+// left unreferenced are deleted by the next Update.
+func (m *Manager) release(s *Sprite) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.refs--
+	if s.refs > 0 || s.pinned {
+		return
+	}
+
+	s.released = true
+	delete(m.sprites, s.Path)
+	m.released = append(m.released, s)
+}
+
+// sprite returns the sprite of path, requesting it if needed. m.mu must
+// be held.
+func (m *Manager) sprite(path string) *Sprite {
+	if s, ok := m.sprites[path]; ok {
+		return s
+	}
+
+	s := &Sprite{Path: path, done: make(chan struct{}), uploaded: make(chan struct{})}
+	m.sprites[path] = s
+	m.progress.Total++
+
+	m.wg.Add(1)
+	go m.load(s)
+
+	return s
+}
+
+// Preload requests sprites ahead of their use, e.g. behind a loading
+// screen.
+func (m *Manager) Preload(paths ...string) {
+	for _, path := range paths {
+		m.Load(path)
+	}
+}
+
+// GetSpriteFilesContext loads a sprite and waits for its files, so the
+// manager can be given to component.NewCharacterAttachmentComponent.
+func (m *Manager) GetSpriteFilesContext(ctx context.Context, name string) (grf.ActionSpriteFilePair, error) {
+	return m.Load(name).Wait(ctx)
+}
+
+// GetEntryContext reads a file from the filesystem of m. The files are
+// not kept, only the sprites are.
+func (m *Manager) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	return m.fs.GetEntryContext(ctx, name)
+}
+
+// OverlayPaths returns the paths on disk of the file name, see
+// vfs.FS.
+func (m *Manager) OverlayPaths(name string) []string {
+	return m.fs.OverlayPaths(name)
+}
+
+// GetPaletteContext loads a palette from the filesystem of m, when it loads
+// palettes (see component.PaletteLoader). The palettes are small: they are
+// not kept.
+func (m *Manager) GetPaletteContext(ctx context.Context, path string) (*pal.PaletteFile, error) {
+	loader, ok := m.fs.(component.PaletteLoader)
+	if !ok {
+		return nil, errors.Errorf("could not load palette '%s': the sprites are not loaded from an archive", path)
+	}
+
+	return loader.GetPaletteContext(ctx, path)
+}
+
+// GetPrioritiesContext loads an IMF file from the filesystem of m, when it
+// loads them (see component.PriorityLoader). As the palettes, they are
+// not kept.
+func (m *Manager) GetPrioritiesContext(ctx context.Context, path string) (*imf.PriorityFile, error) {
+	loader, ok := m.fs.(component.PriorityLoader)
+	if !ok {
+		return nil, errors.Errorf("could not load IMF file '%s': the sprites are not loaded from an archive", path)
+	}
+
+	return loader.GetPrioritiesContext(ctx, path)
+}
+
+// WaitSpriteReady loads a sprite and waits for its textures, see
+// Sprite.WaitReady.
+func (m *Manager) WaitSpriteReady(ctx context.Context, name string) error {
+	return m.Load(name).WaitReady(ctx)
+}
+
+// Progress returns how many of the requested sprites are ready.
+func (m *Manager) Progress() Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.progress
+}
+
+func (m *Manager) load(s *Sprite) {
+	defer m.wg.Done()
+
+	select {
+	case m.workers <- struct{}{}:
+		defer func() { <-m.workers }()
+	case <-m.ctx.Done():
+		m.finish(s, grf.ActionSpriteFilePair{}, m.ctx.Err())
+		return
+	}
+
+	files, err := m.fs.GetSpriteFilesContext(m.ctx, s.Path)
+	if err == nil && files.SPR != nil {
+		// Decode and pack the images here rather than on the first
+		// render.
+		files.SPR.Atlas()
+	}
+
+	m.finish(s, files, err)
+}
+
+func (m *Manager) finish(s *Sprite, files grf.ActionSpriteFilePair, err error) {
+	s.files, s.err = files, errors.Wrapf(err, "could not load sprite '%s'", s.Path)
+	close(s.done)
+
+	m.mu.Lock()
+	m.loaded = append(m.loaded, s)
+	m.mu.Unlock()
+}
+
+// Update uploads the textures of the sprites loaded, in the order they
+// were, within UploadBudget, and deletes the ones of the sprites released.
+func (m *Manager) Update(dt float32) {
+	m.mu.Lock()
+	loaded, released := m.loaded, m.released
+	m.loaded, m.released = nil, nil
+	m.mu.Unlock()
+
+	uploaded := 0
+	for i, s := range loaded {
+		if m.UploadBudget > 0 && i > 0 && uploaded+s.size() > m.UploadBudget {
+			// Left for the next frames, before the sprites loaded since.
+			m.mu.Lock()
+			m.loaded = append(loaded[i:len(loaded):len(loaded)], m.loaded...)
+			m.mu.Unlock()
+			break
+		}
+
+		m.mu.Lock()
+		skip := s.released
+		m.mu.Unlock()
+
+		if s.err != nil {
+			m.log.Error().Err(s.err).Send()
+		} else if !skip {
+			m.upload(s)
+			uploaded += s.size()
+		}
+
+		atomic.StoreInt32(&s.ready, 1)
+		close(s.uploaded)
+
+		m.mu.Lock()
+		m.progress.Done++
+		m.mu.Unlock()
+	}
+
+	for _, s := range released {
+		select {
+		case <-s.done:
+			m.free(s)
+		default:
+			// Still loading, its files cannot be read yet.
+			m.mu.Lock()
+			m.released = append(m.released, s)
+			m.mu.Unlock()
+		}
+	}
+}
+
+func (m *Manager) upload(s *Sprite) {
+	sprFile := s.files.SPR
+	if sprFile == nil {
+		return
+	}
+
+	// The frames are drawn from the atlas, a single texture.
+	if _, err := m.textureProvider.NewTextureFromRGBA(sprFile.Atlas().Image, graphic.TextureSprite); err != nil {
+		m.log.Error().Err(err).Str("path", s.Path).Msg("could not upload sprite texture")
+	}
+}
+
+// size returns the size of the textures of a loaded sprite, in bytes.
+func (s *Sprite) size() int {
+	if s.err != nil || s.files.SPR == nil {
+		return 0
+	}
+
+	return len(s.files.SPR.Atlas().Image.Pix)
+}
+
+// free deletes the textures of a released sprite.
+func (m *Manager) free(s *Sprite) {
+	sprFile := s.files.SPR
+	if sprFile == nil {
+		return
+	}
+
+	for _, img := range sprFile.DecodedImages() {
+		m.textureProvider.DeleteTexture(img)
+	}
+
+	m.log.Debug().Str("path", s.Path).Msg("sprite released")
+}
+
+func (m *Manager) Remove(e ecs.BasicEntity) {}
+
+// Close cancels the pending loads and waits for the workers to stop.
+func (m *Manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
@@ -0,0 +1,188 @@
+package asset
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/graphic"
+)
+
+type loaderFunc func(ctx context.Context, name string) (grf.ActionSpriteFilePair, error)
+
+func (f loaderFunc) GetSpriteFilesContext(ctx context.Context, name string) (grf.ActionSpriteFilePair, error) {
+	return f(ctx, name)
+}
+
+// GetEntryContext finds no file: the fake only loads sprites.
+func (f loaderFunc) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	return nil, os.ErrNotExist
+}
+
+func (f loaderFunc) OverlayPaths(name string) []string {
+	return nil
+}
+
+// textureCounter counts the uploads and deletions instead of creating
+// OpenGL textures.
+type textureCounter struct {
+	uploads, deletes int
+}
+
+func (t *textureCounter) NewTextureFromRGBA(rgba *graphic.UniqueRGBA, class graphic.TextureClass) (*graphic.Texture, error) {
+	t.uploads++
+	return &graphic.Texture{}, nil
+}
+
+func (t *textureCounter) DeleteTexture(rgba *graphic.UniqueRGBA) {
+	t.deletes++
+}
+
+// spriteLoader returns sprites of two 1x1 frames, failing for "missing"
+// and once ctx is done, and counts the loads of each path.
+func spriteLoader(loads map[string]int, mu *sync.Mutex) loaderFunc {
+	return func(ctx context.Context, name string) (grf.ActionSpriteFilePair, error) {
+		mu.Lock()
+		loads[name]++
+		mu.Unlock()
+
+		if err := ctx.Err(); err != nil {
+			return grf.ActionSpriteFilePair{}, err
+		}
+
+		if name == "missing" {
+			return grf.ActionSpriteFilePair{}, errors.New("not found")
+		}
+
+		sprFile := &spr.SpriteFile{
+			Frames: []*spr.SpriteFrame{
+				{SpriteType: spr.FileTypePAL, Width: 1, Height: 1, Data: []byte{1}},
+				{SpriteType: spr.FileTypePAL, Width: 1, Height: 1, Data: []byte{2}},
+			},
+			Images: make([]*graphic.UniqueRGBA, 2),
+		}
+		sprFile.Header.PalettedFrameCount = 2
+
+		return grf.ActionSpriteFilePair{ACT: &act.ActionFile{}, SPR: sprFile}, nil
+	}
+}
+
+func TestManager(t *testing.T) {
+	var mu sync.Mutex
+	loads := map[string]int{}
+	textures := &textureCounter{}
+
+	m := NewManager(context.Background(), spriteLoader(loads, &mu), textures, 2)
+	defer m.Close()
+
+	m.Preload("a", "b", "missing")
+	a := m.Load("a")
+	assert.Equal(t, Progress{Done: 0, Total: 3}, m.Progress())
+
+	files, err := a.Wait(context.Background())
+	require.NoError(t, err)
+	assert.NotNil(t, files.SPR.Images[0])
+
+	_, err = m.Load("missing").Wait(context.Background())
+	assert.Error(t, err)
+
+	_, err = m.GetSpriteFilesContext(context.Background(), "b")
+	require.NoError(t, err)
+	assert.False(t, a.Ready())
+
+	m.Update(0)
+	assert.True(t, a.Ready())
+	assert.Equal(t, Progress{Done: 3, Total: 3}, m.Progress())
+	assert.True(t, m.Progress().Complete())
+	assert.Equal(t, 2, textures.uploads, "an atlas per sprite")
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "missing": 1}, loads)
+}
+
+func TestScope(t *testing.T) {
+	var mu sync.Mutex
+	loads := map[string]int{}
+	textures := &textureCounter{}
+
+	m := NewManager(context.Background(), spriteLoader(loads, &mu), textures, 2)
+	defer m.Close()
+
+	izlude, prontera := m.NewScope(), m.NewScope()
+	izlude.Preload("a", "b", "b")
+	prontera.Preload("b")
+	m.Load("pinned")
+
+	for _, path := range []string{"a", "b", "pinned"} {
+		_, err := izlude.GetSpriteFilesContext(context.Background(), path)
+		require.NoError(t, err)
+	}
+	m.Update(0)
+	assert.Equal(t, 3, textures.uploads)
+
+	izlude.Release()
+	m.Update(0)
+	assert.Equal(t, 3, textures.deletes, "only a is unreferenced, its frames and atlas")
+
+	prontera.Release()
+	m.Update(0)
+	assert.Equal(t, 6, textures.deletes)
+
+	_, err := prontera.GetSpriteFilesContext(context.Background(), "b")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "pinned": 1}, loads)
+
+	prontera.Release()
+	m.Update(0)
+	assert.Equal(t, 3, textures.uploads, "released before its upload")
+	assert.Equal(t, 9, textures.deletes)
+}
+
+func TestManagerUploadBudget(t *testing.T) {
+	var mu sync.Mutex
+	textures := &textureCounter{}
+
+	m := NewManager(context.Background(), spriteLoader(map[string]int{}, &mu), textures, 2)
+	defer m.Close()
+
+	sprites := []*Sprite{m.Load("a"), m.Load("b"), m.Load("c")}
+	for _, s := range sprites {
+		_, err := s.Wait(context.Background())
+		require.NoError(t, err)
+	}
+
+	// Less than two sprites, one is uploaded per update.
+	m.UploadBudget = 2*sprites[0].size() - 1
+	m.Update(0)
+	assert.Equal(t, 1, textures.uploads)
+	assert.Equal(t, Progress{Done: 1, Total: 3}, m.Progress())
+
+	ready := make(chan error)
+	go func() {
+		ready <- m.WaitSpriteReady(context.Background(), "c")
+	}()
+
+	m.Update(0)
+	m.Update(0)
+	assert.Equal(t, 3, textures.uploads)
+	assert.True(t, m.Progress().Complete())
+	assert.NoError(t, <-ready)
+}
+
+func TestManagerCanceled(t *testing.T) {
+	var mu sync.Mutex
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m := NewManager(ctx, spriteLoader(map[string]int{}, &mu), &textureCounter{}, 1)
+	defer m.Close()
+
+	_, err := m.Load("a").Wait(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package asset
+
+import (
+	"context"
+	"sync"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/imf"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+)
+
+// Scope holds references to the sprites it loads, e.g. the ones of a map,
+// until released.
+type Scope struct {
+	m *Manager
+
+	mu      sync.Mutex
+	sprites map[string]*Sprite
+}
+
+// NewScope returns an empty scope loading from m.
+func (m *Manager) NewScope() *Scope {
+	return &Scope{m: m, sprites: map[string]*Sprite{}}
+}
+
+// Load requests a sprite, as Manager.Load, and holds a reference to it.
+func (sc *Scope) Load(path string) *Sprite {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if s, ok := sc.sprites[path]; ok {
+		return s
+	}
+
+	s := sc.m.acquire(path)
+	sc.sprites[path] = s
+
+	return s
+}
+
+// Preload requests sprites ahead of their use.
+func (sc *Scope) Preload(paths ...string) {
+	for _, path := range paths {
+		sc.Load(path)
+	}
+}
+
+// GetSpriteFilesContext loads a sprite and waits for its files, so the
+// scope can be given to component.NewCharacterAttachmentComponent.
+func (sc *Scope) GetSpriteFilesContext(ctx context.Context, name string) (grf.ActionSpriteFilePair, error) {
+	return sc.Load(name).Wait(ctx)
+}
+
+// GetEntryContext reads a file, see Manager.GetEntryContext.
+func (sc *Scope) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	return sc.m.GetEntryContext(ctx, name)
+}
+
+// OverlayPaths returns the paths on disk of the file name, see
+// Manager.OverlayPaths.
+func (sc *Scope) OverlayPaths(name string) []string {
+	return sc.m.OverlayPaths(name)
+}
+
+// GetPaletteContext loads a palette, see Manager.GetPaletteContext.
+func (sc *Scope) GetPaletteContext(ctx context.Context, path string) (*pal.PaletteFile, error) {
+	return sc.m.GetPaletteContext(ctx, path)
+}
+
+// GetPrioritiesContext loads an IMF file, see
+// Manager.GetPrioritiesContext.
+func (sc *Scope) GetPrioritiesContext(ctx context.Context, path string) (*imf.PriorityFile, error) {
+	return sc.m.GetPrioritiesContext(ctx, path)
+}
+
+// WaitSpriteReady loads a sprite and waits for its textures, see
+// Sprite.WaitReady.
+func (sc *Scope) WaitSpriteReady(ctx context.Context, name string) error {
+	return sc.Load(name).WaitReady(ctx)
+}
+
+// Release removes the references of the scope. The scope can be used
+// again afterwards.
+func (sc *Scope) Release() {
+	sc.mu.Lock()
+	sprites := sc.sprites
+	sc.sprites = map[string]*Sprite{}
+	sc.mu.Unlock()
+
+	for _, s := range sprites {
+		sc.m.release(s)
+	}
+}
@@ -0,0 +1,194 @@
+// Package assetdeps lists the GRF entries a map or a job transitively
+// requires, to build minimal asset bundles.
+package assetdeps
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsm"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+)
+
+const (
+	textureDir = "data/texture/"
+	modelDir   = "data/model/"
+	soundDir   = "data/wav/"
+)
+
+// EntryLoader loads the entries of the archives, as grf.File and
+// grf.MultiFile do.
+type EntryLoader interface {
+	GetEntry(name string) (*grf.Entry, error)
+}
+
+// Resolver accumulates the entries required by the added assets. Missing
+// referenced entries are recorded instead of failing the resolution,
+// official data does reference files it does not ship.
+type Resolver struct {
+	grf     EntryLoader
+	entries map[string]bool
+	missing map[string]bool
+}
+
+func NewResolver(f EntryLoader) *Resolver {
+	return &Resolver{
+		grf:     f,
+		entries: map[string]bool{},
+		missing: map[string]bool{},
+	}
+}
+
+// Entries returns the sorted names of the required entries.
+func (r *Resolver) Entries() []string {
+	return sortedKeys(r.entries)
+}
+
+// Missing returns the sorted names of the referenced entries that are
+// not in the GRF.
+func (r *Resolver) Missing() []string {
+	return sortedKeys(r.missing)
+}
+
+// AddMap adds a map world file (e.g. "prontera"), its ground and altitude
+// files, the ground textures, the models and their textures and the
+// ambient sounds.
+func (r *Resolver) AddMap(name string) error {
+	e, ok := r.add("data/" + name + ".rsw")
+	if !ok {
+		return errors.Errorf("could not find map '%s'", name)
+	}
+
+	rswFile, err := rsw.Load(e.Data)
+	if err != nil {
+		return errors.Wrapf(err, "could not load map '%s'", name)
+	}
+
+	if rswFile.AltitudeFile != "" {
+		r.add("data/" + rswFile.AltitudeFile)
+	}
+
+	if e, ok = r.add("data/" + rswFile.GroundFile); ok {
+		gndFile, err := gnd.Load(e.Data)
+		if err != nil {
+			return errors.Wrapf(err, "could not load ground '%s'", rswFile.GroundFile)
+		}
+
+		for _, texture := range gndFile.Textures {
+			r.add(textureDir + texture)
+		}
+	}
+
+	for _, m := range rswFile.Models {
+		if err = r.addModel(m.FileName); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range rswFile.Sounds {
+		r.add(soundDir + s.FileName)
+	}
+
+	return nil
+}
+
+// AddJob adds the body sprites of both genders of a job, with the shadow
+// sprite every character uses.
+func (r *Resolver) AddJob(jobSpriteID jobspriteid.Type) error {
+	if character.JobSpriteNameTable[jobSpriteID] == "" {
+		return errors.Errorf("unsupported jobSpriteID: %v", jobSpriteID)
+	}
+
+	if err := r.AddSprite("data/sprite/shadow"); err != nil {
+		return err
+	}
+
+	for _, gender := range []character.GenderType{character.Male, character.Female} {
+		if err := r.AddSprite(character.BodyFilePath(gender, jobSpriteID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddSprite adds a sprite, given its path without extension, and the sound
+// effects its actions play.
+func (r *Resolver) AddSprite(name string) error {
+	r.add(name + ".spr")
+
+	e, ok := r.add(name + ".act")
+	if !ok {
+		return nil
+	}
+
+	actFile, err := act.Load(e.Data)
+	if err != nil {
+		return errors.Wrapf(err, "could not load '%s.act'", name)
+	}
+
+	for _, sound := range actFile.Sounds {
+		sound = strings.TrimRight(sound, "\x00")
+
+		// "atk" marks the attack frame, it is not a file.
+		if sound == "" || sound == "atk" {
+			continue
+		}
+
+		r.add(soundDir + sound)
+	}
+
+	return nil
+}
+
+func (r *Resolver) addModel(fileName string) error {
+	e, ok := r.add(modelDir + fileName)
+	if !ok {
+		return nil
+	}
+
+	rsmFile, err := rsm.Load(e.Data)
+	if err != nil {
+		return errors.Wrapf(err, "could not load model '%s'", fileName)
+	}
+
+	for _, texture := range rsmFile.Textures {
+		r.add(textureDir + texture)
+	}
+
+	return nil
+}
+
+// add records an entry and returns it, loaded, unless it is missing.
+func (r *Resolver) add(name string) (*grf.Entry, bool) {
+	name = strings.ToLower(strings.ReplaceAll(name, `\`, `/`))
+	if r.missing[name] {
+		return nil, false
+	}
+
+	e, err := r.grf.GetEntry(name)
+	if err != nil {
+		r.missing[name] = true
+		return nil, false
+	}
+
+	r.entries[name] = true
+	return e, true
+}
+
+func sortedKeys(m map[string]bool) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res
+}
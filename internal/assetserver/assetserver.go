@@ -0,0 +1,246 @@
+// Package assetserver serves the assets of a GRF archive over REST,
+// converted to the formats of the web: the sprites as Aseprite sprite
+// sheets, the maps as JSON and their ground as glTF, and the textures as
+// PNG. The web clients, such as the roBrowser-like ones, use it as their
+// data backend instead of parsing the archive formats.
+//
+// The routes, under the root of the handler:
+//
+//	GET /sprites/<path>.png    sheet of data/sprite/<path>.act+.spr
+//	GET /sprites/<path>.json   Aseprite data of the sheet
+//	GET /maps/<name>.json      objects, water and light of data/<name>.rsw
+//	GET /maps/<name>.gltf      ground of data/<name>.gat, as a glTF mesh
+//	GET /textures/<path>.png   data/texture/<path>.bmp
+//
+// The sprites take a palette=<path> query, a palette of data/palette/
+// applied before the conversion. The paths are slash separated, in
+// UTF-8 Korean or in the raw form of the archive names (see
+// pkg/encoding).
+package assetserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/aseprite"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/bmp"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/grfhttp"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+// errNotFound is the error of the routes and entries that do not exist.
+var errNotFound = errors.New("not found")
+
+// Server serves the converted assets of an archive.
+type Server struct {
+	loader grfhttp.EntryLoader
+	log    zerolog.Logger
+}
+
+// NewServer returns the server of the assets of loader, logging to the
+// logger of ctx (see logging.For). Mount it with http.StripPrefix to
+// serve the assets under a path.
+func NewServer(ctx context.Context, loader grfhttp.EntryLoader) *Server {
+	return &Server{loader: loader, log: logging.For(ctx, logging.AssetServer)}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The pages of the web clients may be served from another origin.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	route, name := split(r.URL.Path)
+	ext := path.Ext(name)
+	name = strings.TrimSuffix(name, ext)
+
+	var (
+		contentType string
+		data        []byte
+		err         error
+	)
+	switch {
+	case route == "sprites" && ext == ".png":
+		contentType = "image/png"
+		data, err = s.spriteImage(r.Context(), name, r.URL.Query().Get("palette"))
+	case route == "sprites" && ext == ".json":
+		contentType = "application/json"
+		data, err = s.spriteData(r.Context(), name, r.URL.Query().Get("palette"))
+	case route == "maps" && ext == ".json":
+		contentType = "application/json"
+		data, err = s.mapData(r.Context(), name)
+	case route == "maps" && ext == ".gltf":
+		contentType = "model/gltf+json"
+		data, err = s.mapGround(r.Context(), name)
+	case route == "textures" && ext == ".png":
+		contentType = "image/png"
+		data, err = s.texture(r.Context(), name)
+	default:
+		err = errNotFound
+	}
+
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			s.log.Debug().Err(err).Str("path", r.URL.Path).Msg("asset not found")
+			http.NotFound(w, r)
+			return
+		}
+
+		s.log.Error().Err(err).Str("path", r.URL.Path).Msg("could not convert asset")
+		http.Error(w, "could not convert asset", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
+
+// split returns the first segment of p, the route, and the rest of it.
+func split(p string) (route, name string) {
+	p = strings.TrimPrefix(p, "/")
+	if i := strings.Index(p, "/"); i >= 0 {
+		return p[:i], p[i+1:]
+	}
+
+	return p, ""
+}
+
+// getEntry returns the entry name, looked up first in its raw form when
+// it is readable Korean.
+func (s *Server) getEntry(ctx context.Context, name string) (*grf.Entry, error) {
+	if raw, err := encoding.FromUTF8(name); err == nil && raw != name {
+		if e, err := s.loader.GetEntryContext(ctx, raw); err == nil {
+			return e, nil
+		}
+	}
+
+	e, err := s.loader.GetEntryContext(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(errNotFound, "entry '%s': %v", name, err)
+	}
+
+	return e, nil
+}
+
+// sprite returns the sprite sheet of data/sprite/name, colored with the
+// palette of data/palette/palette, if any.
+func (s *Server) sprite(ctx context.Context, name, palette string) (*aseprite.Sheet, error) {
+	e, err := s.getEntry(ctx, "data/sprite/"+name+".act")
+	if err != nil {
+		return nil, err
+	}
+
+	actFile, err := act.Load(e.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if e, err = s.getEntry(ctx, "data/sprite/"+name+".spr"); err != nil {
+		return nil, err
+	}
+
+	sprFile, err := spr.Load(e.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if palette != "" {
+		if e, err = s.getEntry(ctx, "data/palette/"+palette); err != nil {
+			return nil, err
+		}
+
+		p, err := pal.Load(e.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		sprFile.ApplyPalette(p)
+	}
+
+	// The data references the image next to it.
+	return aseprite.Export(actFile, sprFile, path.Base(name), path.Base(name)+".png")
+}
+
+func (s *Server) spriteImage(ctx context.Context, name, palette string) ([]byte, error) {
+	sheet, err := s.sprite(ctx, name, palette)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodePNG(sheet.Image)
+}
+
+func (s *Server) spriteData(ctx context.Context, name, palette string) ([]byte, error) {
+	sheet, err := s.sprite(ctx, name, palette)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(sheet.Data)
+}
+
+// mapAltitude returns the GAT file of the map name.
+func (s *Server) mapAltitude(ctx context.Context, name string) (*gat.GroundAltitudeFile, error) {
+	e, err := s.getEntry(ctx, "data/"+name+".gat")
+	if err != nil {
+		return nil, err
+	}
+
+	return gat.Load(e.Data)
+}
+
+func (s *Server) mapGround(ctx context.Context, name string) ([]byte, error) {
+	f, err := s.mapAltitude(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Width == 0 || f.Height == 0 {
+		return nil, errors.Errorf("map '%s' has no cell", name)
+	}
+
+	return json.Marshal(newGroundGLTF(f))
+}
+
+// texture returns the texture data/texture/name, its magenta pixels
+// transparent, as the original client draws them.
+func (s *Server) texture(ctx context.Context, name string) ([]byte, error) {
+	e, err := s.getEntry(ctx, "data/texture/"+name+".bmp")
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := bmp.Load(e.Data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode texture '%s'", name)
+	}
+
+	return encodePNG(img)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, errors.Wrap(err, "could not encode image")
+	}
+
+	return buf.Bytes(), nil
+}
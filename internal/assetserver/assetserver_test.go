@@ -0,0 +1,192 @@
+package assetserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+// fakeLoader serves the entries of a map.
+type fakeLoader map[string][]byte
+
+func (l fakeLoader) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	data, ok := l[name]
+	if !ok {
+		return nil, errors.Errorf("could not find entry '%s'", name)
+	}
+
+	return &grf.Entry{Name: name, Data: data}, nil
+}
+
+// gatData returns a GAT file of width by height flat cells, at altitude.
+func gatData(width, height uint32, altitude float32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(gat.HeaderSignature)
+	buf.Write([]byte{1, 2})
+	_ = binary.Write(&buf, binary.LittleEndian, [2]uint32{width, height})
+	for i := 0; i < int(width*height); i++ {
+		_ = binary.Write(&buf, binary.LittleEndian, [4]float32{altitude, altitude, altitude, altitude})
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	}
+
+	return buf.Bytes()
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	texture := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	texture.Set(0, 0, color.RGBA{R: 255, B: 255, A: 255})
+	texture.Set(1, 0, color.RGBA{R: 10, G: 20, B: 30, A: 255})
+	var bmpData bytes.Buffer
+	require.NoError(t, bmp.Encode(&bmpData, texture))
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", NewServer(context.Background(), fakeLoader{
+		"data/prontera.gat": gatData(2, 3, -10),
+		"data/empty.gat":    gatData(0, 0, 0),
+		"data/texture/" + encoding.MustFromUTF8("유저인터페이스") + "/test.bmp": bmpData.Bytes(),
+		"data/sprite/invalid.act": []byte("XX"),
+		"data/sprite/invalid.spr": []byte("XX"),
+	})))
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func get(t *testing.T, url string) (*http.Response, []byte) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	_, err = body.ReadFrom(resp.Body)
+	require.NoError(t, err)
+
+	return resp, body.Bytes()
+}
+
+func TestTexture(t *testing.T) {
+	s := newServer(t)
+
+	resp, body := get(t, s.URL+"/api/textures/유저인터페이스/test.png")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	img, err := png.Decode(bytes.NewReader(body))
+	require.NoError(t, err)
+	_, _, _, a := img.At(0, 0).RGBA()
+	assert.Zero(t, a, "magenta is transparent")
+	assert.Equal(t, color.NRGBA{R: 10, G: 20, B: 30, A: 255}, color.NRGBAModel.Convert(img.At(1, 0)))
+}
+
+func TestMapGround(t *testing.T) {
+	s := newServer(t)
+
+	resp, body := get(t, s.URL+"/api/maps/prontera.gltf")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "model/gltf+json", resp.Header.Get("Content-Type"))
+
+	var doc gltf
+	require.NoError(t, json.Unmarshal(body, &doc))
+	assert.Equal(t, "2.0", doc.Asset.Version)
+	require.Len(t, doc.Accessors, 3)
+	assert.Equal(t, 2*3*4, doc.Accessors[0].Count)
+	assert.Equal(t, 2*3*6, doc.Accessors[2].Count)
+	// The altitudes grow downwards in the files.
+	assert.Equal(t, []float32{0, 2, -3}, doc.Accessors[0].Min)
+	assert.Equal(t, []float32{2, 2, 0}, doc.Accessors[0].Max)
+
+	resp, _ = get(t, s.URL+"/api/maps/empty.gltf")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestGroundGLTFNormals(t *testing.T) {
+	f := &gat.GroundAltitudeFile{Width: 1, Height: 1, Cells: []gat.Cell{{Cells: [4]float32{0, 0, 0, 0}}}}
+	doc := newGroundGLTF(f)
+
+	data, err := base64Data(doc.Buffers[0].URI)
+	require.NoError(t, err)
+
+	view := doc.BufferViews[1]
+	normals := make([]mgl32.Vec3, doc.Accessors[1].Count)
+	require.NoError(t, binary.Read(bytes.NewReader(data[view.ByteOffset:view.ByteOffset+view.ByteLength]), binary.LittleEndian, normals))
+	for _, n := range normals {
+		assert.Equal(t, mgl32.Vec3{0, 1, 0}, n)
+	}
+}
+
+// base64Data returns the data of a base64 data URI.
+func base64Data(uri string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(uri[strings.Index(uri, ",")+1:])
+}
+
+func TestNewMap(t *testing.T) {
+	m := NewMap("prontera", &rsw.ResourceWorldFile{
+		Water: rsw.Water{Level: 1},
+		Models: []rsw.Model{{
+			FileName: encoding.MustFromUTF8(`내부소품\의자.rsm`),
+			Position: mgl32.Vec3{1, 2, 3},
+		}},
+	})
+
+	assert.Equal(t, "prontera", m.Name)
+	assert.Equal(t, float32(1), m.Water.Level)
+	require.Len(t, m.Models, 1)
+	assert.Equal(t, "내부소품/의자.rsm", m.Models[0].FileName)
+	assert.Equal(t, mgl32.Vec3{1, 2, 3}, m.Models[0].Position)
+	assert.Empty(t, m.Sounds)
+}
+
+func TestNotFound(t *testing.T) {
+	s := newServer(t)
+
+	for _, path := range []string{
+		"/api/",
+		"/api/unknown/prontera.json",
+		"/api/maps/prontera.obj",
+		"/api/maps/missing.gltf",
+		"/api/maps/prontera.json",
+		"/api/sprites/missing.png",
+		"/api/textures/missing.png",
+	} {
+		resp, _ := get(t, s.URL+path)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+
+	resp, _ := get(t, s.URL+"/api/sprites/invalid.json")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestMethod(t *testing.T) {
+	s := newServer(t)
+
+	resp, err := http.Post(s.URL+"/api/maps/prontera.gltf", "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -0,0 +1,156 @@
+package assetserver
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+)
+
+// The glTF 2.0 constants used by the ground mesh.
+const (
+	gltfFloat        = 5126
+	gltfUnsignedInt  = 5125
+	gltfArrayBuffer  = 34962
+	gltfElementArray = 34963
+	gltfTriangles    = 4
+)
+
+type gltf struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name string `json:"name"`
+	Mesh int    `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+type gltfBuffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri"`
+}
+
+// newGroundGLTF returns the ground of f, of a cell at least, as a glTF
+// mesh, its buffer embedded. A cell is a unit, the map spanning from the
+// origin east along +X and north along -Z, the altitudes growing up along
+// +Y.
+func newGroundGLTF(f *gat.GroundAltitudeFile) *gltf {
+	cellCount := int(f.Width) * int(f.Height)
+	positions := make([]mgl32.Vec3, 0, cellCount*4)
+	normals := make([]mgl32.Vec3, 0, cellCount*4)
+	indices := make([]uint32, 0, cellCount*6)
+
+	min := mgl32.Vec3{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	max := mgl32.Vec3{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for y := 0; y < int(f.Height); y++ {
+		for x := 0; x < int(f.Width); x++ {
+			cell := f.CellAt(x, y)
+			first := uint32(len(positions))
+
+			// South west, south east, north west and north east, as the
+			// altitudes of the cell.
+			var corners [4]mgl32.Vec3
+			for i, altitude := range cell.Cells {
+				corners[i] = mgl32.Vec3{
+					float32(x + i%2),
+					-altitude / gat.UnitsPerCell,
+					-float32(y + i/2),
+				}
+				for axis := 0; axis < 3; axis++ {
+					min[axis] = float32(math.Min(float64(min[axis]), float64(corners[i][axis])))
+					max[axis] = float32(math.Max(float64(max[axis]), float64(corners[i][axis])))
+				}
+			}
+
+			// The normal of the diagonals, the cells being slightly bent
+			// at most.
+			normal := corners[1].Sub(corners[2]).Cross(corners[3].Sub(corners[0])).Normalize()
+			positions = append(positions, corners[:]...)
+			normals = append(normals, normal, normal, normal, normal)
+			indices = append(indices, first, first+1, first+3, first, first+3, first+2)
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, positions)
+	positionsLength := buf.Len()
+	_ = binary.Write(&buf, binary.LittleEndian, normals)
+	normalsLength := buf.Len() - positionsLength
+	_ = binary.Write(&buf, binary.LittleEndian, indices)
+	indicesLength := buf.Len() - positionsLength - normalsLength
+
+	return &gltf{
+		Asset:  gltfAsset{Version: "2.0", Generator: "midgarts"},
+		Scenes: []gltfScene{{Nodes: []int{0}}},
+		Nodes:  []gltfNode{{Name: "ground", Mesh: 0}},
+		Meshes: []gltfMesh{{
+			Name: "ground",
+			Primitives: []gltfPrimitive{{
+				Attributes: map[string]int{"POSITION": 0, "NORMAL": 1},
+				Indices:    2,
+				Mode:       gltfTriangles,
+			}},
+		}},
+		Accessors: []gltfAccessor{
+			{BufferView: 0, ComponentType: gltfFloat, Count: len(positions), Type: "VEC3", Min: min[:], Max: max[:]},
+			{BufferView: 1, ComponentType: gltfFloat, Count: len(normals), Type: "VEC3"},
+			{BufferView: 2, ComponentType: gltfUnsignedInt, Count: len(indices), Type: "SCALAR"},
+		},
+		BufferViews: []gltfBufferView{
+			{ByteOffset: 0, ByteLength: positionsLength, Target: gltfArrayBuffer},
+			{ByteOffset: positionsLength, ByteLength: normalsLength, Target: gltfArrayBuffer},
+			{ByteOffset: positionsLength + normalsLength, ByteLength: indicesLength, Target: gltfElementArray},
+		},
+		Buffers: []gltfBuffer{{
+			ByteLength: buf.Len(),
+			URI:        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+		}},
+	}
+}
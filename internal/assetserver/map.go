@@ -0,0 +1,179 @@
+package assetserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+// Map is the JSON description of a map, from its RSW, GND and GAT files.
+// The file names are slash separated, in UTF-8.
+type Map struct {
+	Name string `json:"name"`
+	// Width and Height are the size of the map, in cells.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// GroundTextures are the textures of the ground, under data/texture/,
+	// served under /textures/ as PNG.
+	GroundTextures []string `json:"groundTextures"`
+
+	Water        MapWater         `json:"water"`
+	Light        MapLight         `json:"light"`
+	Models       []MapModel       `json:"models"`
+	LightSources []MapLightSource `json:"lightSources"`
+	Sounds       []MapSound       `json:"sounds"`
+	Effects      []MapEffect      `json:"effects"`
+}
+
+type MapWater struct {
+	Level      float32 `json:"level"`
+	Type       int32   `json:"type"`
+	WaveHeight float32 `json:"waveHeight"`
+	WaveSpeed  float32 `json:"waveSpeed"`
+	WavePitch  float32 `json:"wavePitch"`
+	AnimSpeed  int32   `json:"animSpeed"`
+}
+
+type MapLight struct {
+	Longitude int32      `json:"longitude"`
+	Latitude  int32      `json:"latitude"`
+	Diffuse   mgl32.Vec3 `json:"diffuse"`
+	Ambient   mgl32.Vec3 `json:"ambient"`
+	Opacity   float32    `json:"opacity"`
+}
+
+type MapModel struct {
+	Name     string     `json:"name"`
+	FileName string     `json:"fileName"`
+	Position mgl32.Vec3 `json:"position"`
+	Rotation mgl32.Vec3 `json:"rotation"`
+	Scale    mgl32.Vec3 `json:"scale"`
+}
+
+type MapLightSource struct {
+	Name     string     `json:"name"`
+	Position mgl32.Vec3 `json:"position"`
+	Color    [3]int32   `json:"color"`
+	Range    float32    `json:"range"`
+}
+
+type MapSound struct {
+	Name     string     `json:"name"`
+	FileName string     `json:"fileName"`
+	Position mgl32.Vec3 `json:"position"`
+	Volume   float32    `json:"volume"`
+	Range    float32    `json:"range"`
+	Cycle    float32    `json:"cycle"`
+}
+
+type MapEffect struct {
+	Name     string     `json:"name"`
+	Position mgl32.Vec3 `json:"position"`
+	ID       int32      `json:"id"`
+}
+
+func (s *Server) mapData(ctx context.Context, name string) ([]byte, error) {
+	e, err := s.getEntry(ctx, "data/"+name+".rsw")
+	if err != nil {
+		return nil, err
+	}
+
+	world, err := rsw.Load(e.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewMap(name, world)
+
+	// As the ground mesh, the altitudes are the ones of the map name.
+	altitude, err := s.mapAltitude(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	m.Width, m.Height = int(altitude.Width), int(altitude.Height)
+
+	if world.GroundFile != "" {
+		if e, err = s.getEntry(ctx, "data/"+displayName(world.GroundFile)); err != nil {
+			return nil, err
+		}
+
+		ground, err := gnd.Load(e.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range ground.Textures {
+			m.GroundTextures = append(m.GroundTextures, displayName(t))
+		}
+	}
+
+	return json.Marshal(m)
+}
+
+// NewMap returns the description of the map name, from its world file.
+// The size and the ground textures are left to the caller.
+func NewMap(name string, f *rsw.ResourceWorldFile) *Map {
+	m := &Map{
+		Name:           name,
+		GroundTextures: []string{},
+		Water:          MapWater(f.Water),
+		Light:          MapLight(f.Light),
+		Models:         make([]MapModel, 0, len(f.Models)),
+		LightSources:   make([]MapLightSource, 0, len(f.LightSources)),
+		Sounds:         make([]MapSound, 0, len(f.Sounds)),
+		Effects:        make([]MapEffect, 0, len(f.Effects)),
+	}
+
+	for _, o := range f.Models {
+		m.Models = append(m.Models, MapModel{
+			Name:     displayName(o.Name),
+			FileName: displayName(o.FileName),
+			Position: o.Position,
+			Rotation: o.Rotation,
+			Scale:    o.Scale,
+		})
+	}
+	for _, o := range f.LightSources {
+		m.LightSources = append(m.LightSources, MapLightSource{
+			Name:     displayName(o.Name),
+			Position: o.Position,
+			Color:    o.Color,
+			Range:    o.Range,
+		})
+	}
+	for _, o := range f.Sounds {
+		m.Sounds = append(m.Sounds, MapSound{
+			Name:     displayName(o.Name),
+			FileName: displayName(o.FileName),
+			Position: o.Position,
+			Volume:   o.Volume,
+			Range:    o.Range,
+			Cycle:    o.Cycle,
+		})
+	}
+	for _, o := range f.Effects {
+		m.Effects = append(m.Effects, MapEffect{
+			Name:     displayName(o.Name),
+			Position: o.Position,
+			ID:       o.ID,
+		})
+	}
+
+	return m
+}
+
+// displayName returns a raw file name of the data slash separated and in
+// UTF-8, as the routes take them.
+func displayName(raw string) string {
+	name, err := encoding.ToUTF8(raw)
+	if err != nil {
+		name = raw
+	}
+
+	return strings.ReplaceAll(name, `\`, "/")
+}
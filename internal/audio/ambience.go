@@ -0,0 +1,65 @@
+package audio
+
+// Sound is an emitter heard by the listener.
+type Sound struct {
+	Emitter
+	Gains Gains
+	// Start is set when the sound starts, again every cycle of the
+	// emitter.
+	Start bool
+}
+
+// Ambience loops the ambient sounds of a map, such as the waterfalls and
+// the birds of its RSW (see WorldEmitters): a sound starts as soon as the
+// listener is in its range, then again every cycle of its emitter while it
+// is heard.
+type Ambience struct {
+	Emitters []Emitter
+
+	// left is the time before the next start of each emitter, in seconds.
+	left []float32
+	// heard tells whether each emitter was heard during the last update.
+	heard  []bool
+	sounds []Sound
+}
+
+// NewAmbience returns the ambience of emitters, none heard yet.
+func NewAmbience(emitters []Emitter) *Ambience {
+	return &Ambience{
+		Emitters: emitters,
+		left:     make([]float32, len(emitters)),
+		heard:    make([]bool, len(emitters)),
+	}
+}
+
+// Update advances the loops by dt seconds and returns the sounds l hears,
+// with the gains a mixer plays them with. The sounds are valid until the
+// next update.
+func (a *Ambience) Update(l Listener, dt float32) []Sound {
+	a.sounds = a.sounds[:0]
+	for i, e := range a.Emitters {
+		gains := l.Gains(e)
+		if gains == (Gains{}) {
+			// Starts again once back in range.
+			a.heard[i], a.left[i] = false, 0
+			continue
+		}
+
+		start := !a.heard[i]
+		a.heard[i] = true
+		if a.left[i] -= dt; a.left[i] <= 0 {
+			start = true
+		}
+		if start {
+			cycle := e.Cycle
+			if cycle <= 0 {
+				cycle = DefaultCycle
+			}
+			a.left[i] = cycle
+		}
+
+		a.sounds = append(a.sounds, Sound{Emitter: e, Gains: gains, Start: start})
+	}
+
+	return a.sounds
+}
@@ -0,0 +1,132 @@
+// Package audio places the sounds of the world around the listener, the
+// point the camera looks at: the sounds fade with their distance to it
+// and are panned to the side of the screen they come from. It only
+// computes the gains of the sounds, the mixer playing them applies them.
+//
+// The positions are in map cells, east along +X and north along +Y (see
+// system.Ground.Cell).
+package audio
+
+import (
+	"math"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+)
+
+const (
+	// DefaultRange is the distance, in cells, from which the sounds
+	// without range, such as the sounds of the ACT frames, are not heard.
+	DefaultRange = 20
+	// DefaultCycle is the time, in seconds, between the starts of the
+	// looping sounds without cycle, as the one of the old RSW files.
+	DefaultCycle = 4
+)
+
+// Emitter is a sound played at a position.
+type Emitter struct {
+	Name     string
+	Position mgl32.Vec2
+	// Volume is the gain of the sound next to the listener, from 0 to 1.
+	Volume float32
+	// Range is the distance, in cells, from which the sound is not
+	// heard, DefaultRange when 0.
+	Range float32
+	// Cycle is the time, in seconds, between the starts of a looping
+	// sound, DefaultCycle when 0 (see Ambience).
+	Cycle float32
+}
+
+// Gains are the gains of the left and right channels of a sound, from 0
+// to 1.
+type Gains struct {
+	Left, Right float32
+}
+
+// Listener hears the sounds from a position of the map.
+type Listener struct {
+	Position mgl32.Vec2
+	// Right is the direction of the right of the screen, normalized.
+	Right mgl32.Vec2
+}
+
+// NewListener returns the listener at the cell x, y, seen from a camera
+// orbiting yaw degrees around it (see camera.Camera.OrbitYaw): at 0 the
+// north is up and the east on the right.
+func NewListener(x, y, yaw float32) Listener {
+	rad := float64(mgl32.DegToRad(yaw))
+	return Listener{
+		Position: mgl32.Vec2{x, y},
+		Right:    mgl32.Vec2{float32(math.Cos(rad)), float32(-math.Sin(rad))},
+	}
+}
+
+// Gains returns the gains e is heard with. The volume decreases linearly
+// with the distance, down to 0 at the range of e. The sound is panned
+// with a constant power, each channel having a gain of √2/2 of the
+// volume for the sounds in front of the listener.
+func (l Listener) Gains(e Emitter) Gains {
+	r := e.Range
+	if r <= 0 {
+		r = DefaultRange
+	}
+
+	offset := e.Position.Sub(l.Position)
+	distance := offset.Len()
+	if distance >= r {
+		return Gains{}
+	}
+	volume := e.Volume * (1 - distance/r)
+
+	var pan float32
+	if distance > 1e-3 {
+		pan = offset.Mul(1 / distance).Dot(l.Right)
+	}
+
+	// From 0, on the left, to π/2, on the right.
+	angle := float64(pan+1) * math.Pi / 4
+	return Gains{
+		Left:  volume * float32(math.Cos(angle)),
+		Right: volume * float32(math.Sin(angle)),
+	}
+}
+
+// FrameSound returns the sound played when frame of f starts, if any.
+func FrameSound(f *act.ActionFile, frame *act.ActionFrame) (string, bool) {
+	if frame.Sound < 0 || int(frame.Sound) >= len(f.Sounds) {
+		return "", false
+	}
+
+	// The names are padded with zeros.
+	name := f.Sounds[frame.Sound]
+	if i := strings.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	return name, name != ""
+}
+
+// WorldEmitters returns the sound emitters of world, a map of width by
+// height cells.
+func WorldEmitters(world *rsw.ResourceWorldFile, width, height int) []Emitter {
+	emitters := make([]Emitter, len(world.Sounds))
+	for i, s := range world.Sounds {
+		// Centered on the map, gat.UnitsPerCell per cell.
+		emitters[i] = Emitter{
+			Name: s.FileName,
+			Position: mgl32.Vec2{
+				s.Position.X()/gat.UnitsPerCell + float32(width)/2,
+				s.Position.Z()/gat.UnitsPerCell + float32(height)/2,
+			},
+			Volume: s.Volume,
+			Range:  s.Range / gat.UnitsPerCell,
+			Cycle:  s.Cycle,
+		}
+	}
+
+	return emitters
+}
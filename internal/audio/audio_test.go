@@ -0,0 +1,121 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+)
+
+const half = float32(math.Sqrt2 / 2)
+
+func TestListenerGains(t *testing.T) {
+	l := NewListener(100, 100, 0)
+
+	tests := []struct {
+		Name    string
+		Emitter Emitter
+		Gains   Gains
+	}{
+		{Name: "on the listener", Emitter: Emitter{Position: mgl32.Vec2{100, 100}, Volume: 1}, Gains: Gains{half, half}},
+		{Name: "north", Emitter: Emitter{Position: mgl32.Vec2{100, 110}, Volume: 1}, Gains: Gains{half / 2, half / 2}},
+		{Name: "east", Emitter: Emitter{Position: mgl32.Vec2{110, 100}, Volume: 1}, Gains: Gains{0, 0.5}},
+		{Name: "west", Emitter: Emitter{Position: mgl32.Vec2{95, 100}, Volume: 1}, Gains: Gains{0.75, 0}},
+		{Name: "volume", Emitter: Emitter{Position: mgl32.Vec2{100, 90}, Volume: 0.5}, Gains: Gains{half / 4, half / 4}},
+		{Name: "range", Emitter: Emitter{Position: mgl32.Vec2{105, 100}, Volume: 1, Range: 10}, Gains: Gains{0, 0.5}},
+		{Name: "out of range", Emitter: Emitter{Position: mgl32.Vec2{100, 130}, Volume: 1}, Gains: Gains{}},
+	}
+
+	for _, tt := range tests {
+		gains := l.Gains(tt.Emitter)
+		assert.InDelta(t, tt.Gains.Left, gains.Left, 1e-5, tt.Name)
+		assert.InDelta(t, tt.Gains.Right, gains.Right, 1e-5, tt.Name)
+	}
+}
+
+func TestListenerOrbit(t *testing.T) {
+	east := Emitter{Position: mgl32.Vec2{110, 100}, Volume: 1}
+
+	// The camera orbited to the west of the listener, looking east: the
+	// east is up, the sound in front.
+	gains := NewListener(100, 100, 90).Gains(east)
+	assert.InDelta(t, gains.Left, gains.Right, 1e-5)
+
+	// Looking south, the east is on the left.
+	gains = NewListener(100, 100, 180).Gains(east)
+	assert.InDelta(t, 0.5, gains.Left, 1e-5)
+	assert.InDelta(t, 0, gains.Right, 1e-5)
+}
+
+func TestFrameSound(t *testing.T) {
+	f := &act.ActionFile{Sounds: []string{"atk\x00\x00\x00", "knight_attack.wav\x00"}}
+
+	name, ok := FrameSound(f, &act.ActionFrame{Sound: 1})
+	assert.True(t, ok)
+	assert.Equal(t, "knight_attack.wav", name)
+
+	name, ok = FrameSound(f, &act.ActionFrame{Sound: 0})
+	assert.True(t, ok)
+	assert.Equal(t, "atk", name)
+
+	_, ok = FrameSound(f, &act.ActionFrame{Sound: -1})
+	assert.False(t, ok)
+	_, ok = FrameSound(f, &act.ActionFrame{Sound: 2})
+	assert.False(t, ok)
+}
+
+func TestWorldEmitters(t *testing.T) {
+	world := &rsw.ResourceWorldFile{Sounds: []rsw.Sound{{
+		FileName: "fountain.wav",
+		Position: mgl32.Vec3{50, -10, -100},
+		Volume:   0.8,
+		Range:    75,
+		Cycle:    6,
+	}}}
+
+	assert.Equal(t, []Emitter{{
+		Name:     "fountain.wav",
+		Position: mgl32.Vec2{110, 80},
+		Volume:   0.8,
+		Range:    15,
+		Cycle:    6,
+	}}, WorldEmitters(world, 200, 200))
+}
+
+func TestAmbience(t *testing.T) {
+	waterfall := Emitter{Name: "waterfall.wav", Position: mgl32.Vec2{100, 100}, Volume: 1, Range: 10, Cycle: 2}
+	birds := Emitter{Name: "birds.wav", Position: mgl32.Vec2{200, 100}, Volume: 1}
+	a := NewAmbience([]Emitter{waterfall, birds})
+	near := NewListener(100, 100, 0)
+
+	starts := func(sounds []Sound) (names []string) {
+		for _, s := range sounds {
+			if s.Start {
+				names = append(names, s.Name)
+			}
+		}
+		return names
+	}
+
+	sounds := a.Update(near, 0.5)
+	assert.Len(t, sounds, 1)
+	assert.Equal(t, []string{"waterfall.wav"}, starts(sounds))
+
+	assert.Empty(t, starts(a.Update(near, 1)))
+	assert.Len(t, a.Update(near, 0.5), 1)
+	// A cycle later.
+	assert.Equal(t, []string{"waterfall.wav"}, starts(a.Update(near, 0.5)))
+
+	// Out of range, then back: starts at once, the birds after their
+	// default cycle.
+	assert.Empty(t, a.Update(NewListener(150, 100, 0), 0.5))
+	far := NewListener(195, 100, 0)
+	assert.Equal(t, []string{"birds.wav"}, starts(a.Update(far, 0.5)))
+	assert.Empty(t, starts(a.Update(far, DefaultCycle-1)))
+	assert.Equal(t, []string{"birds.wav"}, starts(a.Update(far, 1)))
+	assert.Equal(t, []string{"waterfall.wav"}, starts(a.Update(near, 0.1)))
+}
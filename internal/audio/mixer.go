@@ -0,0 +1,12 @@
+package audio
+
+// Mixer plays the sounds, e.g. with SDL_mixer in the client.
+type Mixer interface {
+	// PlayEffect plays once the sound of name, a .wav file of data/wav/,
+	// with gains.
+	PlayEffect(name string, gains Gains) error
+	// PlayMusic loops the music of path, relative to the folder of the
+	// client (see MusicTable), in place of the music playing; "" stops
+	// the music.
+	PlayMusic(path string) error
+}
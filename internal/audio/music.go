@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// MusicTablePath is the table of the music of the maps in the data.
+const MusicTablePath = "data/mp3nametable.txt"
+
+// MusicTable holds the music of the maps, by map name, e.g. "bgm/08.mp3"
+// for prontera: the paths are relative to the folder of the client, the
+// music not being in the archives.
+type MusicTable map[string]string
+
+// LoadMusicTable reads the table of r made of "map.rsw#music#" lines, as
+// mp3nametable.txt, e.g. "prontera.rsw#bgm\\08.mp3#".
+func LoadMusicTable(r io.Reader) (MusicTable, error) {
+	t := MusicTable{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "//") {
+			continue
+		}
+
+		fields := strings.Split(text, "#")
+		if len(fields) < 2 || fields[0] == "" || strings.TrimSpace(fields[1]) == "" {
+			return nil, fmt.Errorf("invalid music table line %d", line)
+		}
+
+		// The backslashes are escaped, or not.
+		music := strings.ReplaceAll(strings.TrimSpace(fields[1]), `\`, "/")
+		t[mapKey(fields[0])] = path.Clean(music)
+	}
+
+	return t, scanner.Err()
+}
+
+// Music returns the music of the map mapName, with or without its .rsw
+// extension, and whether it has any.
+func (t MusicTable) Music(mapName string) (string, bool) {
+	music, ok := t[mapKey(mapName)]
+	return music, ok
+}
+
+func mapKey(mapName string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(mapName)), ".rsw")
+}
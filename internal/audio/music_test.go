@@ -0,0 +1,34 @@
+package audio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMusicTable(t *testing.T) {
+	table := "// map#music#\nprontera.rsw#bgm\\\\08.mp3#\n\nIzlude.rsw#bgm\\26.mp3# \nmoc_fild01.rsw#BGM\\\\14.mp3#\n"
+	musics, err := LoadMusicTable(strings.NewReader(table))
+	require.NoError(t, err)
+	assert.Len(t, musics, 3)
+
+	music, ok := musics.Music("prontera")
+	assert.True(t, ok)
+	assert.Equal(t, "bgm/08.mp3", music)
+
+	music, ok = musics.Music("izlude.rsw")
+	assert.True(t, ok)
+	assert.Equal(t, "bgm/26.mp3", music)
+
+	// The case of the files is kept.
+	music, _ = musics.Music("moc_fild01")
+	assert.Equal(t, "BGM/14.mp3", music)
+
+	_, ok = musics.Music("geffen")
+	assert.False(t, ok)
+
+	_, err = LoadMusicTable(strings.NewReader("prontera.rsw##\n"))
+	assert.Error(t, err)
+}
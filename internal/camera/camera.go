@@ -19,6 +19,13 @@ const (
 	Pitch = float32(-60.0)
 )
 
+// MinZoomDistance and MaxZoomDistance are the closest and farthest the
+// camera zooms to the ground (see Zoom), in world units.
+const (
+	MinZoomDistance = float32(8)
+	MaxZoomDistance = float32(100)
+)
+
 type Camera struct {
 	*graphic.Transform
 	target                       *graphic.Transform
@@ -30,6 +37,9 @@ type Camera struct {
 	distance                     float32
 	targetRotation               mgl32.Vec3
 	altitude                     float32
+	// orbitYaw is the rotation of the view around the looked at point, in
+	// degrees (see SetOrbitYaw).
+	orbitYaw float32
 }
 
 func NewPerspectiveCamera(fov, aspect, near, far float32) *Camera {
@@ -51,6 +61,10 @@ func NewPerspectiveCamera(fov, aspect, near, far float32) *Camera {
 }
 
 func (c *Camera) createViewMatrix() mgl32.Mat4 {
+	// The camera looks down at the ground, its orbit turns the up of the
+	// screen from the north to the east as the yaw goes to 90 degrees.
+	up := mgl32.QuatRotate(mgl32.DegToRad(c.orbitYaw), graphic.Forward).Rotate(graphic.Up)
+
 	return mgl32.LookAt(
 		c.Position().X(),
 		c.Position().Y(),
@@ -58,12 +72,89 @@ func (c *Camera) createViewMatrix() mgl32.Mat4 {
 		c.Position().X()+graphic.Forward.X(),
 		c.Position().Y()+graphic.Forward.Y(),
 		c.Position().Z()+graphic.Forward.Z(),
-		graphic.Up.X(),
-		graphic.Up.Y(),
-		graphic.Up.Z(),
+		up.X(),
+		up.Y(),
+		up.Z(),
 	)
 }
 
+// OrbitYaw returns the rotation of the camera around the looked at point,
+// in degrees from 0 to 360.
+func (c *Camera) OrbitYaw() float32 {
+	return c.orbitYaw
+}
+
+// SetOrbitYaw sets the rotation of the camera around the looked at point,
+// in degrees. At 0 the camera is south of it, as in the official client;
+// it orbits clockwise, seen from above, as the yaw increases.
+func (c *Camera) SetOrbitYaw(yaw float32) {
+	c.orbitYaw = float32(math.Mod(float64(yaw), 360))
+	if c.orbitYaw < 0 {
+		c.orbitYaw += 360
+	}
+}
+
+// Orbit rotates the camera around the looked at point by delta degrees.
+func (c *Camera) Orbit(delta float32) {
+	c.SetOrbitYaw(c.orbitYaw + delta)
+}
+
+// Distance returns the distance of the camera to the ground at the
+// altitude 0, in world units.
+func (c *Camera) Distance() float32 {
+	// The camera looks at the ground along +Z, from below it.
+	return -c.Position().Z()
+}
+
+// Zoom moves the camera scale times closer to the ground at the altitude
+// 0, between MinZoomDistance and MaxZoomDistance of it.
+func (c *Camera) Zoom(scale float32) {
+	if scale <= 0 {
+		return
+	}
+
+	distance := c.Distance() / scale
+	if distance < MinZoomDistance {
+		distance = MinZoomDistance
+	} else if distance > MaxZoomDistance {
+		distance = MaxZoomDistance
+	}
+
+	c.SetPosition(mgl32.Vec3{c.Position().X(), c.Position().Y(), -distance})
+}
+
+// GroundPoint returns the world point at the altitude z seen at the window
+// point (x, y), in pixels from the top-left corner of a window of
+// windowWidth by windowHeight pixels. It is false when the point is not
+// in front of the camera.
+func (c *Camera) GroundPoint(x, y, windowWidth, windowHeight int, z float32) (mgl32.Vec3, bool) {
+	view, projection := c.ViewMatrix(), c.ProjectionMatrix()
+	// The window origin of OpenGL is the bottom-left corner.
+	win := mgl32.Vec3{float32(x), float32(windowHeight - y), 0}
+
+	near, err := mgl32.UnProject(win, view, projection, 0, 0, windowWidth, windowHeight)
+	if err != nil {
+		return mgl32.Vec3{}, false
+	}
+	win[2] = 1
+	far, err := mgl32.UnProject(win, view, projection, 0, 0, windowWidth, windowHeight)
+	if err != nil {
+		return mgl32.Vec3{}, false
+	}
+
+	ray := far.Sub(near)
+	if math.Abs(float64(ray.Z())) < 1e-6 {
+		return mgl32.Vec3{}, false
+	}
+
+	t := (z - near.Z()) / ray.Z()
+	if t < 0 {
+		return mgl32.Vec3{}, false
+	}
+
+	return near.Add(ray.Mul(t)), true
+}
+
 func (c *Camera) ViewMatrix() mgl32.Mat4 {
 	c.viewMatrix = c.createViewMatrix()
 	return c.viewMatrix
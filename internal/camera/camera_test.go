@@ -0,0 +1,55 @@
+package camera
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCamera() *Camera {
+	cam := NewPerspectiveCamera(0.638, 4.0/3, 0.1, 1000.0)
+	cam.ResetAngleAndY(800, 600)
+
+	return cam
+}
+
+func TestGroundPoint(t *testing.T) {
+	cam := newCamera()
+
+	// The camera looks straight at the ground.
+	p, ok := cam.GroundPoint(400, 300, 800, 600, 0)
+	require.True(t, ok)
+	assert.InDelta(t, cam.Position().X(), p.X(), 1e-3)
+	assert.InDelta(t, cam.Position().Y(), p.Y(), 1e-3)
+	assert.InDelta(t, 0, p.Z(), 1e-3)
+
+	// North is up and east, along -X, on the right.
+	p, _ = cam.GroundPoint(400, 100, 800, 600, 0)
+	assert.Greater(t, p.Y(), cam.Position().Y())
+	p, _ = cam.GroundPoint(700, 300, 800, 600, 0)
+	assert.Less(t, p.X(), cam.Position().X())
+
+	// Orbited to the west, looking east: the south is on the right.
+	cam.SetOrbitYaw(90)
+	p, _ = cam.GroundPoint(700, 300, 800, 600, 0)
+	assert.Less(t, p.Y(), cam.Position().Y())
+	assert.InDelta(t, cam.Position().X(), p.X(), 1e-3)
+}
+
+func TestZoom(t *testing.T) {
+	cam := newCamera()
+	z := cam.Position().Z()
+
+	cam.Zoom(2)
+	assert.InDelta(t, z/2, cam.Position().Z(), 1e-5)
+
+	cam.Zoom(100)
+	assert.Equal(t, -MinZoomDistance, cam.Position().Z())
+	cam.Zoom(0.001)
+	assert.Equal(t, -MaxZoomDistance, cam.Position().Z())
+
+	cam.Zoom(0)
+	assert.Equal(t, mgl32.Vec3{cam.Position().X(), cam.Position().Y(), -MaxZoomDistance}, cam.Position())
+}
@@ -2,8 +2,8 @@ package actionindex
 
 import (
 	"fmt"
+
 	"github.com/project-midgard/midgarts/internal/character/statetype"
-	"log"
 )
 
 type Type int
@@ -24,34 +24,30 @@ const (
 	CastingSpell    Type = 96
 )
 
-func GetActionIndex(s statetype.Type) (t Type) {
+func GetActionIndex(s statetype.Type) (Type, error) {
 	switch s {
 	case statetype.Attacking:
-		return Attacking3
+		return Attacking3, nil
 	case statetype.Walking:
-		return Walking
+		return Walking, nil
 	case statetype.Idle:
-		return Idle
+		return Idle, nil
 	case statetype.StandBy:
-		return StandBy
+		return StandBy, nil
 	default:
-		log.Fatal(fmt.Sprintf("state type '%v' not supported\n", s))
+		return 0, fmt.Errorf("state type '%v' not supported", s)
 	}
-
-	return
 }
 
-func GetStateType(s Type) (t statetype.Type) {
+func GetStateType(s Type) (statetype.Type, error) {
 	switch s {
 	case Idle:
-		return statetype.Idle
+		return statetype.Idle, nil
 	case Walking:
-		return statetype.Walking
+		return statetype.Walking, nil
 	case StandBy:
-		return statetype.StandBy
+		return statetype.StandBy, nil
 	default:
-		panic(fmt.Sprintf("state type '%v' not supported\n", s))
+		return "", fmt.Errorf("action index '%v' not supported", s)
 	}
-
-	return
 }
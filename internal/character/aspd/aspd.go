@@ -0,0 +1,53 @@
+// Package aspd derives the timing of the attacks of a character from its
+// attack speed (ASPD), as the servers do (the amotion and adelay of
+// rAthena), so the attack animations end when the damage is applied.
+package aspd
+
+import "time"
+
+const (
+	// Max is the highest ASPD, the max_aspd of the servers.
+	Max = 190
+	// Default is the ASPD of the characters whose speed is not known.
+	Default = 150
+
+	// MaxMotion is the longest attack motion.
+	MaxMotion = 2000 * time.Millisecond
+)
+
+// Timing is the timing of the attacks at an ASPD.
+type Timing struct {
+	// Motion is the duration of the attack animation, the damage being
+	// applied at its end. It is the motion cancel point: from then on, the
+	// character may move, dropping what is left of the attack.
+	Motion time.Duration
+	// Delay is the time from an attack to the next one.
+	Delay time.Duration
+}
+
+// New returns the timing of the attacks at aspd, bounded to Max.
+func New(aspd float64) Timing {
+	if aspd > Max {
+		aspd = Max
+	}
+
+	// amotion = (200 - ASPD) * 10 milliseconds.
+	motion := time.Duration((200 - aspd) * 10 * float64(time.Millisecond))
+	if motion > MaxMotion {
+		motion = MaxMotion
+	}
+
+	return Timing{Motion: motion, Delay: 2 * motion}
+}
+
+// CanCancel tells whether an attack started elapsed ago reached its motion
+// cancel point.
+func (t Timing) CanCancel(elapsed time.Duration) bool {
+	return elapsed >= t.Motion
+}
+
+// CanAttack tells whether the next attack may start, elapsed after the
+// start of the last one.
+func (t Timing) CanAttack(elapsed time.Duration) bool {
+	return elapsed >= t.Delay
+}
@@ -0,0 +1,37 @@
+package aspd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		ASPD   float64
+		Motion time.Duration
+	}{
+		{ASPD: 150, Motion: 500 * time.Millisecond},
+		{ASPD: 185.5, Motion: 145 * time.Millisecond},
+		{ASPD: 190, Motion: 100 * time.Millisecond},
+		// Bounded to Max and MaxMotion.
+		{ASPD: 199, Motion: 100 * time.Millisecond},
+		{ASPD: -50, Motion: MaxMotion},
+	}
+
+	for _, tt := range tests {
+		timing := New(tt.ASPD)
+		assert.Equal(t, tt.Motion, timing.Motion, "aspd %v", tt.ASPD)
+		assert.Equal(t, 2*tt.Motion, timing.Delay, "aspd %v", tt.ASPD)
+	}
+}
+
+func TestTiming(t *testing.T) {
+	timing := New(150)
+
+	assert.False(t, timing.CanCancel(499*time.Millisecond))
+	assert.True(t, timing.CanCancel(500*time.Millisecond))
+	assert.False(t, timing.CanAttack(999*time.Millisecond))
+	assert.True(t, timing.CanAttack(time.Second))
+}
@@ -1,5 +1,7 @@
 package character
 
+import "fmt"
+
 type AttachmentType int
 
 const (
@@ -7,6 +9,13 @@ const (
 	AttachmentBody
 	AttachmentHead
 	AttachmentShield
+	// The headgears, worn low, in the middle and on top of the head.
+	AttachmentHeadgearLow
+	AttachmentHeadgearMid
+	AttachmentHeadgearTop
+	// The weapon held, and its slash, the trail drawn over its attacks.
+	AttachmentWeapon
+	AttachmentWeaponSlash
 	NumAttachments
 )
 
@@ -20,8 +29,18 @@ func (e AttachmentType) String() (att string) {
 		att = "AttachmentHead"
 	case AttachmentShield:
 		att = "AttachmentShield"
+	case AttachmentHeadgearLow:
+		att = "AttachmentHeadgearLow"
+	case AttachmentHeadgearMid:
+		att = "AttachmentHeadgearMid"
+	case AttachmentHeadgearTop:
+		att = "AttachmentHeadgearTop"
+	case AttachmentWeapon:
+		att = "AttachmentWeapon"
+	case AttachmentWeaponSlash:
+		att = "AttachmentWeaponSlash"
 	default:
-		panic("unsupported attachment type")
+		att = fmt.Sprintf("AttachmentType(%d)", int(e))
 	}
 
 	return att
@@ -33,5 +52,20 @@ func Attachments() []AttachmentType {
 		AttachmentBody,
 		AttachmentHead,
 		AttachmentShield,
+		AttachmentHeadgearLow,
+		AttachmentHeadgearMid,
+		AttachmentHeadgearTop,
+		AttachmentWeapon,
+		AttachmentWeaponSlash,
 	}
 }
+
+// IsHeadgear tells whether e is a headgear.
+func (e AttachmentType) IsHeadgear() bool {
+	return e == AttachmentHeadgearLow || e == AttachmentHeadgearMid || e == AttachmentHeadgearTop
+}
+
+// IsWeapon tells whether e is the weapon or its slash.
+func (e AttachmentType) IsWeapon() bool {
+	return e == AttachmentWeapon || e == AttachmentWeaponSlash
+}
@@ -1,9 +1,43 @@
 package character
 
+import (
+	"fmt"
+
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+)
+
 var (
 	MaleFilePathf   = "data/sprite/%s/%s/³²/%s_³²"
 	FemaleFilePathf = "data/sprite/%s/%s/¿©/%s_¿©"
+
+	// data/sprite/인간족/몸통/%s/%s_%s
+	BodyFilePathf = "data/sprite/ÀÎ°£Á·/¸öÅë/%s/%s_%s"
+
+	// data/sprite/악세사리/%s/%s%s
+	HeadgearFilePathf = "data/sprite/¾Ç¼¼»ç¸®/%s/%s%s"
+
+	// data/imf/%s_%s.imf
+	PriorityFilePathf = "data/imf/%s_%s.imf"
 )
 
 type HeadIndex int
 type SpriteIndex int
+
+// HeadgearFilePath returns the path, without extension, of a headgear
+// sprite given its accessory name (e.g. "_¸®º»").
+func HeadgearFilePath(gender GenderType, accessoryName string) string {
+	folder := genderFolder(gender)
+	return fmt.Sprintf(HeadgearFilePathf, folder, folder, accessoryName)
+}
+
+// PriorityFilePath returns the path of the IMF file of a job, ordering
+// the layers of its body and head.
+func PriorityFilePath(gender GenderType, jobSpriteID jobspriteid.Type) string {
+	return fmt.Sprintf(PriorityFilePathf, JobSpriteNameTable[jobSpriteID], genderFolder(gender))
+}
+
+// BodyFilePath returns the path, without extension, of a job body sprite.
+func BodyFilePath(gender GenderType, jobSpriteID jobspriteid.Type) string {
+	folder := genderFolder(gender)
+	return fmt.Sprintf(BodyFilePathf, folder, JobSpriteNameTable[jobSpriteID], folder)
+}
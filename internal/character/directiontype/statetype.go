@@ -1,16 +1,20 @@
 package directiontype
 
+import "github.com/project-midgard/midgarts/pkg/character"
+
+// DirectionTable is the offset of the sprite directions for each camera
+// direction of the official client (see character.DirectionResolver).
 var DirectionTable = [8]int{6, 5, 4, 3, 2, 1, 0, 7}
 
-type Type uint8
+type Type = character.Direction
 
 const (
-	South Type = iota
-	SouthWest
-	West
-	NorthWest
-	North
-	NorthEast
-	East
-	SouthEast
+	South     = character.South
+	SouthWest = character.SouthWest
+	West      = character.West
+	NorthWest = character.NorthWest
+	North     = character.North
+	NorthEast = character.NorthEast
+	East      = character.East
+	SouthEast = character.SouthEast
 )
@@ -0,0 +1,63 @@
+package character
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+// HeadgearNameTable holds the accessory names of the headgear sprites,
+// e.g. "_¸®º»", by the view ids the servers send, as the accname table of
+// the client does. It is empty until LoadHeadgearTable fills it.
+var HeadgearNameTable = map[int]string{}
+
+// LoadHeadgearTable registers the headgears of a table made of "id#name#"
+// lines, as idnum2itemresnametable.txt, in HeadgearNameTable. The names
+// are in UTF-8 or, as the tables of the data, in CP949.
+func LoadHeadgearTable(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "//") {
+			continue
+		}
+
+		fields := strings.Split(text, "#")
+		if len(fields) < 2 || fields[1] == "" {
+			return fmt.Errorf("invalid headgear table line %d", line)
+		}
+
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return errors.Wrapf(err, "invalid headgear table line %d", line)
+		}
+
+		name := encoding.DecodeRaw([]byte(fields[1]))
+		if utf8.ValidString(fields[1]) {
+			if name, err = encoding.FromUTF8(fields[1]); err != nil {
+				return errors.Wrapf(err, "invalid headgear table line %d", line)
+			}
+		}
+		HeadgearNameTable[id] = name
+	}
+
+	return scanner.Err()
+}
+
+// HeadgearSpritePath returns the path, without extension, of the sprite of
+// the headgear of view id, see HeadgearNameTable.
+func HeadgearSpritePath(gender GenderType, id int) (string, error) {
+	name, ok := HeadgearNameTable[id]
+	if !ok {
+		return "", fmt.Errorf("unknown headgear %d", id)
+	}
+
+	return HeadgearFilePath(gender, name), nil
+}
@@ -0,0 +1,34 @@
+package character
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+func TestLoadHeadgearTable(t *testing.T) {
+	defer func(table map[int]string) { HeadgearNameTable = table }(HeadgearNameTable)
+	HeadgearNameTable = map[int]string{}
+
+	// 리본, in UTF-8 and in CP949.
+	cp949, err := encoding.EncodeCP949("_리본")
+	require.NoError(t, err)
+	table := "// view id#accessory#\n1#_리본#\n\n2#" + string(cp949) + "#\n3#_goggle#\n"
+	require.NoError(t, LoadHeadgearTable(strings.NewReader(table)))
+
+	ribbon := encoding.MustFromUTF8("_리본")
+	assert.Equal(t, map[int]string{1: ribbon, 2: ribbon, 3: "_goggle"}, HeadgearNameTable)
+
+	path, err := HeadgearSpritePath(Male, 3)
+	require.NoError(t, err)
+	assert.Equal(t, HeadgearFilePath(Male, "_goggle"), path)
+	_, err = HeadgearSpritePath(Male, 4)
+	assert.Error(t, err)
+
+	assert.Error(t, LoadHeadgearTable(strings.NewReader("x#_goggle#")))
+	assert.Error(t, LoadHeadgearTable(strings.NewReader("5##")))
+}
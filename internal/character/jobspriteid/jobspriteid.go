@@ -1,8 +1,10 @@
 package jobspriteid
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/project-midgard/midgarts/internal/character/jobid"
-	"log"
 )
 
 type Type int
@@ -34,40 +36,38 @@ const (
 	MonkH      = Type(4016)
 )
 
-func GetJobSpriteID(jid jobid.Type, isMounted bool) (t Type) {
+func GetJobSpriteID(jid jobid.Type, isMounted bool) (Type, error) {
 	switch jid {
 	case jobid.Archer:
-		return Archer
+		return Archer, nil
 	case jobid.Monk:
-		return Monk
+		return Monk, nil
 	case jobid.Assassin:
-		return Assassin
+		return Assassin, nil
 	case jobid.Swordsman:
-		return Swordsman
+		return Swordsman, nil
 	case jobid.Alchemist:
-		return Alchemist
+		return Alchemist, nil
 	case jobid.Knight:
 		if isMounted {
-			return Knight2
+			return Knight2, nil
 		} else {
-			return Knight
+			return Knight, nil
 		}
 	case jobid.Crusader:
 		if isMounted {
-			return Crusader2
+			return Crusader2, nil
 		} else {
-			return Crusader
+			return Crusader, nil
 		}
 	//case jobid.Thief:
-	//	return Thief
+	//	return Thief, nil
 	//case jobid.MonkH:
-	//	return MonkH
+	//	return MonkH, nil
 
 	default:
-		log.Fatalf("jobid '%v' not supported", jid)
+		return 0, fmt.Errorf("jobid '%v' not supported", jid)
 	}
-
-	return
 }
 
 func (j Type) String() string {
@@ -121,10 +121,8 @@ func (j Type) String() string {
 	case MonkH:
 		return "MonkH"
 	default:
-		log.Fatalf("unsupported jobspriteid %d\n", j)
+		return fmt.Sprintf("Type(%d)", int(j))
 	}
-
-	return ""
 }
 
 func All() []Type {
@@ -148,8 +146,22 @@ func All() []Type {
 		Sage,
 		Rogue,
 		Alchemist,
+		Bard,
+		Dancer,
 		Crusader2,
 		KnightH,
 		MonkH,
 	}
 }
+
+// Parse returns the job sprite of a name, as returned by String, ignoring
+// case.
+func Parse(name string) (Type, error) {
+	for _, j := range All() {
+		if strings.EqualFold(j.String(), name) {
+			return j, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown job sprite '%s'", name)
+}
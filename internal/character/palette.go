@@ -0,0 +1,32 @@
+package character
+
+import (
+	"fmt"
+
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+)
+
+var (
+	// data/palette/머리/머리%d_%s_%d.pal
+	HairPaletteFilePathf = "data/palette/¸Ó¸®/¸Ó¸®%d_%s_%d.pal"
+	// data/palette/몸/%s_%s_%d.pal
+	BodyPaletteFilePathf = "data/palette/¸ö/%s_%s_%d.pal"
+)
+
+// HairPaletteFilePath returns the path of the hair dye palette for the given head.
+func HairPaletteFilePath(gender GenderType, headIndex HeadIndex, paletteIndex int) string {
+	return fmt.Sprintf(HairPaletteFilePathf, headIndex, genderFolder(gender), paletteIndex)
+}
+
+// BodyPaletteFilePath returns the path of the clothes dye palette for the given job.
+func BodyPaletteFilePath(gender GenderType, jobSpriteID jobspriteid.Type, paletteIndex int) string {
+	return fmt.Sprintf(BodyPaletteFilePathf, JobSpriteNameTable[jobSpriteID], genderFolder(gender), paletteIndex)
+}
+
+func genderFolder(gender GenderType) string {
+	if gender == Female {
+		return "¿©"
+	}
+
+	return "³²"
+}
@@ -1,5 +1,10 @@
 package statetype
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Type string
 
 const (
@@ -8,3 +13,19 @@ const (
 	Attacking Type = "Attacking"
 	StandBy   Type = "StandBy"
 )
+
+// All returns the states.
+func All() []Type {
+	return []Type{Idle, Walking, Attacking, StandBy}
+}
+
+// Parse returns the state of a name, ignoring case.
+func Parse(name string) (Type, error) {
+	for _, s := range All() {
+		if strings.EqualFold(string(s), name) {
+			return s, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown state '%s'", name)
+}
@@ -0,0 +1,82 @@
+// Package statustype names the status effects shown on the characters.
+package statustype
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Type string
+
+const (
+	Poison  Type = "Poison"
+	Curse   Type = "Curse"
+	Silence Type = "Silence"
+	Blind   Type = "Blind"
+	Stun    Type = "Stun"
+	Sleep   Type = "Sleep"
+	Frozen  Type = "Frozen"
+	Stone   Type = "Stone"
+)
+
+// All returns the status effects.
+func All() []Type {
+	return []Type{Poison, Curse, Silence, Blind, Stun, Sleep, Frozen, Stone}
+}
+
+// Parse returns the status effect of a name, ignoring case.
+func Parse(name string) (Type, error) {
+	for _, s := range All() {
+		if strings.EqualFold(string(s), name) {
+			return s, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown status effect '%s'", name)
+}
+
+// bodyStates are the status effects of the body states of the zone
+// packets (see packet.ZC_STATE_CHANGE3), a single one at a time.
+var bodyStates = map[uint16]Type{
+	1: Stone,
+	2: Frozen,
+	3: Stun,
+	4: Sleep,
+	// Turning to stone.
+	6: Stone,
+}
+
+// healthStates are the status effects of the bits of the health states of
+// the zone packets.
+var healthStates = []struct {
+	bit    uint16
+	status Type
+}{
+	{0x01, Poison},
+	{0x02, Curse},
+	{0x04, Silence},
+	{0x10, Blind},
+}
+
+// FromStates returns the status effects of the body and health states of
+// the zone packets, in the order of All.
+func FromStates(body, health uint16) []Type {
+	set := map[Type]bool{}
+	if s, ok := bodyStates[body]; ok {
+		set[s] = true
+	}
+	for _, h := range healthStates {
+		if health&h.bit != 0 {
+			set[h.status] = true
+		}
+	}
+
+	var statuses []Type
+	for _, s := range All() {
+		if set[s] {
+			statuses = append(statuses, s)
+		}
+	}
+
+	return statuses
+}
@@ -0,0 +1,24 @@
+package statustype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromStates(t *testing.T) {
+	assert.Empty(t, FromStates(0, 0))
+	assert.Equal(t, []Type{Frozen}, FromStates(2, 0))
+	assert.Equal(t, []Type{Poison, Blind, Sleep}, FromStates(4, 0x11))
+	// Unknown states are ignored.
+	assert.Empty(t, FromStates(5, 0x08))
+}
+
+func TestParse(t *testing.T) {
+	s, err := Parse("frozen")
+	assert.NoError(t, err)
+	assert.Equal(t, Frozen, s)
+
+	_, err = Parse("Happy")
+	assert.Error(t, err)
+}
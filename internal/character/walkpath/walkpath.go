@@ -0,0 +1,165 @@
+// Package walkpath moves the characters along their walk paths between the
+// cells of a map. The characters walk the straight parts of a path at the
+// walk speed and round its corners: they slow down into a turn and speed
+// up out of it, so walking does not look like stepping between the cells.
+package walkpath
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+)
+
+// Corner is the distance, in cells, from a turn at which the characters
+// start rounding it. It is shortened on the short parts of a path, not to
+// round two corners at once.
+const Corner = 0.5
+
+// Path is a walk path through world positions, a cell per world unit.
+type Path struct {
+	points []mgl32.Vec2
+	// at is the distance from the start of the path to each point, and
+	// radius the distance from each point at which its corner is rounded.
+	at     []float32
+	radius []float32
+}
+
+// New returns the path through points, the first being where the walk
+// starts. The repeated points are dropped.
+func New(points ...mgl32.Vec2) *Path {
+	p := &Path{}
+	for _, point := range points {
+		if n := len(p.points); n > 0 && p.points[n-1].ApproxEqual(point) {
+			continue
+		}
+
+		distance := float32(0)
+		if n := len(p.points); n > 0 {
+			distance = p.at[n-1] + point.Sub(p.points[n-1]).Len()
+		}
+		p.points = append(p.points, point)
+		p.at = append(p.at, distance)
+	}
+
+	p.radius = make([]float32, len(p.points))
+	for i := 1; i < len(p.points)-1; i++ {
+		before, after := p.at[i]-p.at[i-1], p.at[i+1]-p.at[i]
+		p.radius[i] = min32(Corner, min32(before/2, after/2))
+	}
+
+	return p
+}
+
+// Len returns the distance walked from the start to the end of the path,
+// the length of the straight lines through its points: rounding the
+// corners takes the time walking them would.
+func (p *Path) Len() float32 {
+	if len(p.at) == 0 {
+		return 0
+	}
+
+	return p.at[len(p.at)-1]
+}
+
+// At returns the position, and the heading, of a character having walked
+// distance along the path. The heading is not normalized; it is the
+// heading of the last part of the path past its end.
+func (p *Path) At(distance float32) (position, heading mgl32.Vec2) {
+	switch len(p.points) {
+	case 0:
+		return mgl32.Vec2{}, mgl32.Vec2{}
+	case 1:
+		return p.points[0], mgl32.Vec2{}
+	}
+
+	last := len(p.points) - 1
+	if distance <= 0 {
+		return p.points[0], p.direction(0)
+	}
+	if distance >= p.at[last] {
+		return p.points[last], p.direction(last - 1)
+	}
+
+	// i is the point the character walked past last.
+	i := 0
+	for i < last-1 && p.at[i+1] <= distance {
+		i++
+	}
+
+	// Rounding the corner of i, or of the next point.
+	if r := p.radius[i]; r > 0 && distance < p.at[i]+r {
+		return p.corner(i, (distance-(p.at[i]-r))/(2*r))
+	}
+	if r := p.radius[i+1]; r > 0 && distance > p.at[i+1]-r {
+		return p.corner(i+1, (distance-(p.at[i+1]-r))/(2*r))
+	}
+
+	in := p.direction(i)
+	return p.points[i].Add(in.Mul(distance - p.at[i])), in
+}
+
+// direction returns the unit vector from the point i to the next one.
+func (p *Path) direction(i int) mgl32.Vec2 {
+	return p.points[i+1].Sub(p.points[i]).Normalize()
+}
+
+// corner returns the position and heading at t, from 0 to 1, along the
+// rounded corner of the point i: a quadratic Bézier curve from the
+// radius before the point to the radius after it, the point being its
+// control point. Its speed is that of the straight parts at both ends and
+// lower in between, easing the turn.
+func (p *Path) corner(i int, t float32) (position, heading mgl32.Vec2) {
+	r := p.radius[i]
+	control := p.points[i]
+	start := control.Sub(p.direction(i - 1).Mul(r))
+	end := control.Add(p.direction(i).Mul(r))
+
+	u := 1 - t
+	position = start.Mul(u * u).Add(control.Mul(2 * u * t)).Add(end.Mul(t * t))
+	heading = control.Sub(start).Mul(u).Add(end.Sub(control).Mul(t))
+
+	return position, heading
+}
+
+// Walk is the progress of a character along a path.
+type Walk struct {
+	Path *Path
+	// Walked is the distance walked from the start of the path.
+	Walked float32
+}
+
+// Advance walks distance further along the path, returning the new
+// position and heading, and whether the end of the path is reached.
+func (w *Walk) Advance(distance float32) (position, heading mgl32.Vec2, done bool) {
+	w.Walked += distance
+	position, heading = w.Path.At(w.Walked)
+
+	return position, heading, w.Walked >= w.Path.Len()
+}
+
+// Direction returns the direction of the world a character heading along
+// heading faces, north being +Y and east -X. A zero heading faces South.
+func Direction(heading mgl32.Vec2) directiontype.Type {
+	if heading.X() == 0 && heading.Y() == 0 {
+		return directiontype.South
+	}
+
+	// Clockwise from the south, seen from above with the north up.
+	angle := math.Atan2(float64(heading.X()), float64(-heading.Y()))
+	step := int(math.Round(angle/(math.Pi/4))) % 8
+	if step < 0 {
+		step += 8
+	}
+
+	return directiontype.Type(step)
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,104 @@
+package walkpath
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+)
+
+func TestPathStraight(t *testing.T) {
+	p := New(mgl32.Vec2{0, 0}, mgl32.Vec2{0, 1}, mgl32.Vec2{0, 3})
+	assert.Equal(t, float32(3), p.Len())
+
+	// No corner to round along a straight line.
+	for _, distance := range []float32{0, 0.75, 1, 1.25, 2.5, 3} {
+		position, heading := p.At(distance)
+		assert.InDelta(t, 0, position.X(), 1e-6, "distance %v", distance)
+		assert.InDelta(t, distance, position.Y(), 1e-6, "distance %v", distance)
+		assert.Equal(t, directiontype.North, Direction(heading), "distance %v", distance)
+	}
+
+	position, _ := p.At(5)
+	assert.Equal(t, mgl32.Vec2{0, 3}, position)
+}
+
+func TestPathCorner(t *testing.T) {
+	// North, then west (+X).
+	p := New(mgl32.Vec2{0, 0}, mgl32.Vec2{0, 2}, mgl32.Vec2{2, 2})
+	assert.Equal(t, float32(4), p.Len())
+
+	// Straight up to the corner radius.
+	position, heading := p.At(1.5)
+	assert.True(t, position.ApproxEqual(mgl32.Vec2{0, 1.5}), "%v", position)
+	assert.Equal(t, directiontype.North, Direction(heading))
+
+	// Cutting the corner, heading north-west at its middle.
+	position, heading = p.At(2)
+	assert.True(t, position.ApproxEqual(mgl32.Vec2{0.125, 1.875}), "%v", position)
+	assert.Equal(t, directiontype.NorthWest, Direction(heading))
+
+	position, heading = p.At(2.5)
+	assert.True(t, position.ApproxEqual(mgl32.Vec2{0.5, 2}), "%v", position)
+	assert.Equal(t, directiontype.West, Direction(heading))
+}
+
+func TestPathCornerContinuous(t *testing.T) {
+	p := New(mgl32.Vec2{0, 0}, mgl32.Vec2{1, 1}, mgl32.Vec2{1, 2}, mgl32.Vec2{-2, 2})
+
+	// No jump: the steps are no longer than the distance walked, and
+	// shorter in the corners.
+	const step = 0.01
+	previous, _ := p.At(0)
+	for distance := float32(step); distance <= p.Len(); distance += step {
+		position, _ := p.At(distance)
+		assert.LessOrEqual(t, position.Sub(previous).Len(), float32(step*1.001), "distance %v", distance)
+		previous = position
+	}
+}
+
+func TestPathShortSegments(t *testing.T) {
+	// The corners of a one cell step are rounded by half a cell at most.
+	p := New(mgl32.Vec2{0, 0}, mgl32.Vec2{0, 0.5}, mgl32.Vec2{0.5, 0.5})
+	assert.Equal(t, []float32{0, 0.25, 0}, p.radius)
+
+	position, _ := p.At(0.5)
+	assert.True(t, position.ApproxEqual(mgl32.Vec2{0.0625, 0.4375}), "%v", position)
+}
+
+func TestWalkAdvance(t *testing.T) {
+	w := Walk{Path: New(mgl32.Vec2{4, 4}, mgl32.Vec2{4, 4}, mgl32.Vec2{2, 4})}
+
+	position, heading, done := w.Advance(1)
+	assert.Equal(t, mgl32.Vec2{3, 4}, position)
+	assert.Equal(t, directiontype.East, Direction(heading))
+	assert.False(t, done)
+
+	position, _, done = w.Advance(1.5)
+	assert.Equal(t, mgl32.Vec2{2, 4}, position)
+	assert.True(t, done)
+}
+
+func TestDirection(t *testing.T) {
+	tests := []struct {
+		Heading   mgl32.Vec2
+		Direction directiontype.Type
+	}{
+		{Heading: mgl32.Vec2{0, 0}, Direction: directiontype.South},
+		{Heading: mgl32.Vec2{0, -1}, Direction: directiontype.South},
+		{Heading: mgl32.Vec2{1, -1}, Direction: directiontype.SouthWest},
+		{Heading: mgl32.Vec2{1, 0}, Direction: directiontype.West},
+		{Heading: mgl32.Vec2{1, 1}, Direction: directiontype.NorthWest},
+		{Heading: mgl32.Vec2{0, 1}, Direction: directiontype.North},
+		{Heading: mgl32.Vec2{-1, 1}, Direction: directiontype.NorthEast},
+		{Heading: mgl32.Vec2{-1, 0}, Direction: directiontype.East},
+		{Heading: mgl32.Vec2{-1, -1}, Direction: directiontype.SouthEast},
+		{Heading: mgl32.Vec2{0.2, 1}, Direction: directiontype.North},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.Direction, Direction(tt.Heading), "heading %v", tt.Heading)
+	}
+}
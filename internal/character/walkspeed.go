@@ -0,0 +1,38 @@
+package character
+
+import "time"
+
+// DefaultWalkSpeed is the time to walk a cell at the normal speed, as on
+// the servers.
+const DefaultWalkSpeed = 150 * time.Millisecond
+
+// DiagonalWalkFactor is how much longer a diagonal step takes than a
+// straight one, as on the servers.
+const DiagonalWalkFactor = 1.4
+
+// WalkSpeed returns the time to walk a cell at speed times the normal
+// speed, e.g. 1.25 with Agi Up.
+func WalkSpeed(speed float64) time.Duration {
+	if speed <= 0 {
+		return DefaultWalkSpeed
+	}
+
+	return time.Duration(float64(DefaultWalkSpeed) / speed)
+}
+
+// WalkAnimationMultiplier returns the frame rate multiplier of the walk
+// animation at walkSpeed per cell, so a step of the animation always
+// covers the same distance and the feet do not slide.
+func WalkAnimationMultiplier(walkSpeed time.Duration) float64 {
+	if walkSpeed <= 0 {
+		return 1
+	}
+
+	return float64(DefaultWalkSpeed) / float64(walkSpeed)
+}
+
+// WalkDistance returns the distance, in cells, walked in dt seconds at
+// walkSpeed per cell.
+func WalkDistance(dt float32, walkSpeed time.Duration) float32 {
+	return dt / float32(walkSpeed.Seconds())
+}
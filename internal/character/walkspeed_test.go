@@ -0,0 +1,24 @@
+package character
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkSpeed(t *testing.T) {
+	assert.Equal(t, DefaultWalkSpeed, WalkSpeed(1))
+	assert.Equal(t, 120*time.Millisecond, WalkSpeed(1.25))
+	assert.Equal(t, DefaultWalkSpeed, WalkSpeed(0))
+
+	// The walk animation follows the walk speed.
+	assert.Equal(t, 1.0, WalkAnimationMultiplier(DefaultWalkSpeed))
+	assert.Equal(t, 1.25, WalkAnimationMultiplier(WalkSpeed(1.25)))
+	assert.Equal(t, 0.5, WalkAnimationMultiplier(300*time.Millisecond))
+}
+
+func TestWalkDistance(t *testing.T) {
+	assert.InDelta(t, 2, WalkDistance(0.3, DefaultWalkSpeed), 1e-6)
+	assert.InDelta(t, 2.5, WalkDistance(0.3, WalkSpeed(1.25)), 1e-6)
+}
@@ -0,0 +1,56 @@
+package character
+
+import (
+	"fmt"
+
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+var (
+	// data/sprite/인간족/%s/%s_%s%s
+	WeaponFilePathf = "data/sprite/ÀÎ°£Á·/%s/%s_%s%s"
+
+	// WeaponSlashSuffix ends the path of the slash of a weapon, the trail
+	// drawn over its attacks: _검광.
+	WeaponSlashSuffix = encoding.MustFromUTF8("_검광")
+)
+
+// WeaponNameTable holds the names of the weapon sprites of each class of
+// weapons, the one-handed and two-handed ones sharing some.
+var WeaponNameTable = map[weapontype.Type]string{
+	weapontype.Dagger:       encoding.MustFromUTF8("_단검"),
+	weapontype.Sword:        encoding.MustFromUTF8("_검"),
+	weapontype.TwoHandSword: encoding.MustFromUTF8("_투핸드소드"),
+	weapontype.Spear:        encoding.MustFromUTF8("_창"),
+	weapontype.TwoHandSpear: encoding.MustFromUTF8("_창"),
+	weapontype.Axe:          encoding.MustFromUTF8("_도끼"),
+	weapontype.TwoHandAxe:   encoding.MustFromUTF8("_도끼"),
+	weapontype.Mace:         encoding.MustFromUTF8("_클럽"),
+	weapontype.TwoHandMace:  encoding.MustFromUTF8("_클럽"),
+	weapontype.Rod:          encoding.MustFromUTF8("_롯드"),
+	weapontype.Bow:          encoding.MustFromUTF8("_활"),
+	weapontype.Knuckle:      encoding.MustFromUTF8("_너클"),
+	weapontype.Instrument:   encoding.MustFromUTF8("_악기"),
+	weapontype.Whip:         encoding.MustFromUTF8("_채찍"),
+	weapontype.Book:         encoding.MustFromUTF8("_책"),
+	weapontype.Katar:        encoding.MustFromUTF8("_카타르"),
+}
+
+// WeaponFilePath returns the path, without extension, of the sprite of a
+// class of weapons held by a job: each job has its own sprites of the
+// weapons, in its folder. The path of the slash of the weapon adds
+// WeaponSlashSuffix.
+func WeaponFilePath(gender GenderType, jobSpriteID jobspriteid.Type, weapon weapontype.Type) (string, error) {
+	job := JobSpriteNameTable[jobSpriteID]
+	if job == "" {
+		return "", fmt.Errorf("unsupported jobSpriteID: %v", jobSpriteID)
+	}
+	name, ok := WeaponNameTable[weapon]
+	if !ok {
+		return "", fmt.Errorf("no sprite for weapon type %v", weapon)
+	}
+
+	return fmt.Sprintf(WeaponFilePathf, job, job, genderFolder(gender), name), nil
+}
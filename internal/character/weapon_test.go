@@ -0,0 +1,31 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+func TestWeaponFilePath(t *testing.T) {
+	path, err := WeaponFilePath(Male, jobspriteid.Swordsman, weapontype.Sword)
+	require.NoError(t, err)
+	job := JobSpriteNameTable[jobspriteid.Swordsman]
+	assert.Equal(t, encoding.MustFromUTF8("data/sprite/인간족/")+job+"/"+job+encoding.MustFromUTF8("_남_검"), path)
+
+	// The two-handed spears are the spears.
+	spear, err := WeaponFilePath(Female, jobspriteid.Knight, weapontype.Spear)
+	require.NoError(t, err)
+	twoHandSpear, err := WeaponFilePath(Female, jobspriteid.Knight, weapontype.TwoHandSpear)
+	require.NoError(t, err)
+	assert.Equal(t, spear, twoHandSpear)
+
+	_, err = WeaponFilePath(Male, jobspriteid.Swordsman, weapontype.None)
+	assert.Error(t, err)
+	_, err = WeaponFilePath(Male, jobspriteid.Type(-1), weapontype.Sword)
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+// Package weapontype names the classes of weapons the characters hold, as
+// numbered by the servers.
+package weapontype
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Type int
+
+const (
+	None         = Type(0)
+	Dagger       = Type(1)
+	Sword        = Type(2)
+	TwoHandSword = Type(3)
+	Spear        = Type(4)
+	TwoHandSpear = Type(5)
+	Axe          = Type(6)
+	TwoHandAxe   = Type(7)
+	Mace         = Type(8)
+	TwoHandMace  = Type(9)
+	Rod          = Type(10)
+	Bow          = Type(11)
+	Knuckle      = Type(12)
+	Instrument   = Type(13)
+	Whip         = Type(14)
+	Book         = Type(15)
+	Katar        = Type(16)
+)
+
+// All returns the classes of weapons, None excluded.
+func All() []Type {
+	return []Type{
+		Dagger, Sword, TwoHandSword, Spear, TwoHandSpear, Axe, TwoHandAxe, Mace,
+		TwoHandMace, Rod, Bow, Knuckle, Instrument, Whip, Book, Katar,
+	}
+}
+
+func (t Type) String() string {
+	switch t {
+	case None:
+		return "None"
+	case Dagger:
+		return "Dagger"
+	case Sword:
+		return "Sword"
+	case TwoHandSword:
+		return "TwoHandSword"
+	case Spear:
+		return "Spear"
+	case TwoHandSpear:
+		return "TwoHandSpear"
+	case Axe:
+		return "Axe"
+	case TwoHandAxe:
+		return "TwoHandAxe"
+	case Mace:
+		return "Mace"
+	case TwoHandMace:
+		return "TwoHandMace"
+	case Rod:
+		return "Rod"
+	case Bow:
+		return "Bow"
+	case Knuckle:
+		return "Knuckle"
+	case Instrument:
+		return "Instrument"
+	case Whip:
+		return "Whip"
+	case Book:
+		return "Book"
+	case Katar:
+		return "Katar"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Parse returns the class of weapons of a name, ignoring case, e.g.
+// "sword".
+func Parse(name string) (Type, error) {
+	for _, t := range append(All(), None) {
+		if strings.EqualFold(t.String(), name) {
+			return t, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown weapon type '%s'", name)
+}
@@ -0,0 +1,145 @@
+package cinematic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/script"
+	"github.com/project-midgard/midgarts/internal/weather"
+)
+
+// fakeAPI records the calls of the player.
+type fakeAPI struct {
+	spawned []script.Character
+	calls   []string
+	camera  mgl32.Vec3
+	fade    float32
+}
+
+func (a *fakeAPI) Spawn(c script.Character) (uint64, error) {
+	a.spawned = append(a.spawned, c)
+	return uint64(len(a.spawned)), nil
+}
+
+func (a *fakeAPI) Walk(id uint64, points []mgl32.Vec2) error {
+	a.calls = append(a.calls, "walk")
+	return nil
+}
+
+func (a *fakeAPI) PlayEffect(name string, position mgl32.Vec3) error {
+	a.calls = append(a.calls, "effect "+name)
+	return nil
+}
+
+func (a *fakeAPI) SetState(id uint64, state statetype.Type) error {
+	a.calls = append(a.calls, "state "+string(state))
+	return nil
+}
+
+func (a *fakeAPI) Say(id uint64, text string, d time.Duration) error {
+	a.calls = append(a.calls, "say "+text+" "+d.String())
+	return nil
+}
+
+func (a *fakeAPI) MoveCamera(position mgl32.Vec3) { a.camera = position }
+func (a *fakeAPI) CameraPosition() mgl32.Vec3     { return a.camera }
+func (a *fakeAPI) SetFade(fade float32)           { a.fade = fade }
+func (a *fakeAPI) SetWeather(w weather.Weather)   { a.calls = append(a.calls, "weather "+string(w.Kind)) }
+func (a *fakeAPI) ShowDialog(title, text string)  { a.calls = append(a.calls, "dialog "+text) }
+
+const timeline = `
+name: Izlude
+cues:
+  - at: 4s
+    say: {name: knight, text: For Izlude!}
+  - at: 0s
+    spawn: {name: knight, job: knight, gender: f, head: 23, shield: true, position: [0, 44, -1]}
+  - at: 0s
+    camera: {position: [10, 0, 0], over: 2s}
+  - at: 1s
+    walk: {name: knight, to: [[4, 44], [4, 40]]}
+  - at: 3s
+    state: {name: knight, state: attacking}
+  - at: 4s
+    effect: {name: firewall, position: [4, 40, 0]}
+  - at: 6s
+    fade: {to: 1}
+  - at: 6s
+    fade: {to: 0, over: 2s}
+`
+
+func TestLoad(t *testing.T) {
+	tl, err := Load([]byte(timeline))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Izlude", tl.Name)
+	require.Len(t, tl.Cues, 8)
+	assert.NotNil(t, tl.Cues[0].Spawn)
+	assert.Equal(t, 2*time.Second, tl.Cues[1].Camera.Over)
+	// The cues of the same time keep their order.
+	assert.NotNil(t, tl.Cues[4].Say)
+	assert.NotNil(t, tl.Cues[5].Effect)
+	assert.Equal(t, 8*time.Second, tl.Duration())
+}
+
+func TestLoadErrors(t *testing.T) {
+	for name, source := range map[string]string{
+		"syntax":      "cues: [",
+		"no action":   "cues: [{at: 1s}]",
+		"two actions": "cues: [{fade: {to: 1}, dialog: {text: hi}}]",
+		"job":         "cues: [{spawn: {name: a, job: Chef}}]",
+		"gender":      "cues: [{spawn: {name: a, job: Knight, gender: x}}]",
+		"twice":       "cues: [{spawn: {name: a, job: Knight}}, {spawn: {name: a, job: Knight}}]",
+		"unknown":     "cues: [{walk: {name: a, to: [[1, 2]]}}]",
+		"before":      "cues: [{at: 1s, spawn: {name: a, job: Knight}}, {state: {name: a, state: Idle}}]",
+		"state":       "cues: [{spawn: {name: a, job: Knight}}, {state: {name: a, state: Dancing}}]",
+	} {
+		_, err := Load([]byte(source))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	tl, err := Load([]byte(timeline))
+	require.NoError(t, err)
+
+	api := &fakeAPI{}
+	p := NewPlayer(context.Background(), api, tl)
+
+	p.Update(0)
+	assert.Equal(t, []script.Character{{
+		Gender:      character.Female,
+		JobSpriteID: jobspriteid.Knight,
+		HeadIndex:   23,
+		HasShield:   true,
+		Position:    mgl32.Vec3{0, 44, -1},
+		Scale:       1,
+	}}, api.spawned)
+	assert.Equal(t, mgl32.Vec3{}, api.camera)
+
+	// Half way, the camera eases half way.
+	p.Update(1)
+	assert.InDelta(t, 5, api.camera.X(), 1e-4)
+	assert.Equal(t, []string{"walk"}, api.calls)
+
+	p.Update(3)
+	assert.Equal(t, mgl32.Vec3{10, 0, 0}, api.camera)
+	assert.Equal(t, []string{"walk", "state Attacking", "say For Izlude! 3s", "effect firewall"}, api.calls)
+
+	// Fades in from black.
+	p.Update(3)
+	assert.InDelta(t, 0.5, api.fade, 1e-4)
+	assert.False(t, p.Done())
+
+	p.Update(1)
+	assert.Equal(t, float32(0), api.fade)
+	assert.True(t, p.Done())
+}
@@ -0,0 +1,177 @@
+package cinematic
+
+import (
+	"context"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/internal/script"
+)
+
+// Player plays a timeline on a script.API. It is a system, playing the
+// cues due every update; it must be used from a single goroutine.
+type Player struct {
+	log      zerolog.Logger
+	api      script.API
+	timeline *Timeline
+
+	elapsed time.Duration
+	// next is the first cue not played.
+	next int
+	// ids are the entity ids of the spawned characters, by name.
+	ids map[string]uint64
+
+	camera *tween
+	fade   *tween
+	// fadeValue is the fade of the frame.
+	fadeValue float32
+}
+
+// tween moves a value from from to to over a duration, from start.
+type tween struct {
+	from, to mgl32.Vec3
+	start    time.Duration
+	duration time.Duration
+}
+
+// at returns the value at elapsed, easing in and out of the move, and
+// whether the move is over.
+func (t *tween) at(elapsed time.Duration) (mgl32.Vec3, bool) {
+	if t.duration <= 0 || elapsed >= t.start+t.duration {
+		return t.to, true
+	}
+
+	x := float32(elapsed-t.start) / float32(t.duration)
+	x = x * x * (3 - 2*x)
+
+	return t.from.Add(t.to.Sub(t.from).Mul(x)), false
+}
+
+// NewPlayer returns a player of timeline on api, logging to the logger of
+// ctx (see logging.For).
+func NewPlayer(ctx context.Context, api script.API, timeline *Timeline) *Player {
+	return &Player{
+		log:      logging.For(ctx, logging.Cinematic),
+		api:      api,
+		timeline: timeline,
+		ids:      map[string]uint64{},
+	}
+}
+
+// Elapsed returns the time played.
+func (p *Player) Elapsed() time.Duration {
+	return p.elapsed
+}
+
+// Done tells whether the whole timeline is played.
+func (p *Player) Done() bool {
+	return p.next == len(p.timeline.Cues) && p.elapsed >= p.timeline.Duration()
+}
+
+// Update plays the cues due dt seconds later, and the camera moves and
+// fades under way. A failing cue is logged and skipped.
+func (p *Player) Update(dt float32) {
+	p.elapsed += time.Duration(float64(dt) * float64(time.Second))
+
+	for ; p.next < len(p.timeline.Cues) && p.timeline.Cues[p.next].At <= p.elapsed; p.next++ {
+		c := &p.timeline.Cues[p.next]
+		if err := p.play(c); err != nil {
+			p.log.Error().Err(err).Msgf("cue %d at %s failed", p.next, c.At)
+		}
+	}
+
+	if p.camera != nil {
+		position, done := p.camera.at(p.elapsed)
+		p.api.MoveCamera(position)
+		if done {
+			p.camera = nil
+		}
+	}
+	if p.fade != nil {
+		fade, done := p.fade.at(p.elapsed)
+		p.fadeValue = fade.X()
+		p.api.SetFade(p.fadeValue)
+		if done {
+			p.fade = nil
+		}
+	}
+}
+
+func (p *Player) Remove(ecs.BasicEntity) {}
+
+func (p *Player) play(c *Cue) error {
+	switch {
+	case c.Spawn != nil:
+		s := c.Spawn
+		job, _ := jobspriteid.Parse(s.Job)
+		gender := character.Male
+		if s.Gender == "f" || s.Gender == "female" {
+			gender = character.Female
+		}
+		scale := s.Scale
+		if scale == 0 {
+			scale = 1
+		}
+
+		id, err := p.api.Spawn(script.Character{
+			Gender:      gender,
+			JobSpriteID: job,
+			HeadIndex:   character.HeadIndex(s.Head),
+			HasShield:   s.Shield,
+			Position:    s.Position,
+			Scale:       scale,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "could not spawn '%s'", s.Name)
+		}
+		p.ids[s.Name] = id
+	case c.Walk != nil:
+		points := make([]mgl32.Vec2, len(c.Walk.To))
+		for i, to := range c.Walk.To {
+			points[i] = to
+		}
+		return p.api.Walk(p.ids[c.Walk.Name], points)
+	case c.State != nil:
+		state, _ := statetype.Parse(c.State.State)
+		return p.api.SetState(p.ids[c.State.Name], state)
+	case c.Say != nil:
+		return p.api.Say(p.ids[c.Say.Name], c.Say.Text, c.Say.duration())
+	case c.Effect != nil:
+		return p.api.PlayEffect(c.Effect.Name, c.Effect.Position)
+	case c.Camera != nil:
+		p.camera = nil
+		if c.Camera.Over == 0 {
+			p.api.MoveCamera(c.Camera.Position)
+			break
+		}
+		p.camera = &tween{from: p.api.CameraPosition(), to: c.Camera.Position, start: c.At, duration: c.Camera.Over}
+	case c.Fade != nil:
+		p.fade = nil
+		if c.Fade.Over == 0 {
+			p.fadeValue = c.Fade.To
+			p.api.SetFade(p.fadeValue)
+			break
+		}
+		p.fade = &tween{from: mgl32.Vec3{p.fadeValue}, to: mgl32.Vec3{c.Fade.To}, start: c.At, duration: c.Fade.Over}
+	case c.Dialog != nil:
+		p.api.ShowDialog(c.Dialog.Title, c.Dialog.Text)
+	}
+
+	return nil
+}
+
+func (s *Say) duration() time.Duration {
+	if s.For == 0 {
+		return script.DefaultSayDuration
+	}
+
+	return s.For
+}
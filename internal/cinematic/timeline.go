@@ -0,0 +1,213 @@
+// Package cinematic plays the timelines of the showcase demos: the cues of
+// a YAML file spawn the characters, walk them, play their animations and
+// the effects, make them say texts, move the camera and fade the frame at
+// their time, so a demo plays the same every time. The client is driven
+// through the script.API, as the Lua scripts drive it.
+//
+//	name: Izlude
+//	cues:
+//	  - at: 0s
+//	    spawn: {name: knight, job: Knight, gender: m, head: 23, shield: true, position: [0, 44, 0]}
+//	  - at: 0s
+//	    camera: {position: [0, 44, 0], over: 3s}
+//	  - at: 1s
+//	    walk: {name: knight, to: [[4, 44], [4, 40]]}
+//	  - at: 3s
+//	    state: {name: knight, state: Attacking}
+//	  - at: 4s
+//	    say: {name: knight, text: For Izlude!, for: 2s}
+//	  - at: 4s
+//	    effect: {name: firewall, position: [4, 40, 0]}
+//	  - at: 8s
+//	    fade: {to: 1, over: 2s}
+package cinematic
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+)
+
+// Timeline is the cues of a cinematic.
+type Timeline struct {
+	Name string `yaml:"name"`
+	// Cues are the cues of the timeline, by time (see Load).
+	Cues []Cue `yaml:"cues"`
+}
+
+// Cue is an action of a timeline, at its time from the start. A cue has a
+// single action.
+type Cue struct {
+	At time.Duration `yaml:"at"`
+
+	Spawn  *Spawn  `yaml:"spawn"`
+	Walk   *Walk   `yaml:"walk"`
+	State  *State  `yaml:"state"`
+	Say    *Say    `yaml:"say"`
+	Effect *Effect `yaml:"effect"`
+	Camera *Camera `yaml:"camera"`
+	Fade   *Fade   `yaml:"fade"`
+	Dialog *Dialog `yaml:"dialog"`
+}
+
+// Spawn adds a character, named for the next cues.
+type Spawn struct {
+	Name string `yaml:"name"`
+	// Job is the job sprite of the character (see jobspriteid.Parse).
+	Job string `yaml:"job"`
+	// Gender is "m" (by default) or "f".
+	Gender   string     `yaml:"gender"`
+	Head     int        `yaml:"head"`
+	Shield   bool       `yaml:"shield"`
+	Scale    float32    `yaml:"scale"`
+	Position [3]float32 `yaml:"position"`
+}
+
+// Walk makes a character walk through world positions.
+type Walk struct {
+	Name string       `yaml:"name"`
+	To   [][2]float32 `yaml:"to"`
+}
+
+// State plays the animation of a state of a character (see
+// statetype.Parse).
+type State struct {
+	Name  string `yaml:"name"`
+	State string `yaml:"state"`
+}
+
+// Say shows a text above a character, for script.DefaultSayDuration when
+// For is 0.
+type Say struct {
+	Name string        `yaml:"name"`
+	Text string        `yaml:"text"`
+	For  time.Duration `yaml:"for"`
+}
+
+// Effect plays an effect at a world position.
+type Effect struct {
+	Name     string     `yaml:"name"`
+	Position [3]float32 `yaml:"position"`
+}
+
+// Camera moves the camera to a world position, Over a time, at once when
+// it is 0.
+type Camera struct {
+	Position [3]float32    `yaml:"position"`
+	Over     time.Duration `yaml:"over"`
+}
+
+// Fade darkens the frame to To, from 0 (unchanged) to 1 (black), Over a
+// time, at once when it is 0.
+type Fade struct {
+	To   float32       `yaml:"to"`
+	Over time.Duration `yaml:"over"`
+}
+
+// Dialog shows a message box.
+type Dialog struct {
+	Title string `yaml:"title"`
+	Text  string `yaml:"text"`
+}
+
+// Load returns the timeline of a YAML file, its cues sorted by time, the
+// cues of the same time in the order of the file.
+func Load(data []byte) (*Timeline, error) {
+	var t Timeline
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, errors.Wrap(err, "could not decode timeline")
+	}
+
+	sort.SliceStable(t.Cues, func(i, j int) bool { return t.Cues[i].At < t.Cues[j].At })
+
+	names := map[string]bool{}
+	for i, c := range t.Cues {
+		if err := c.validate(names); err != nil {
+			return nil, errors.Wrapf(err, "invalid cue %d at %s", i, c.At)
+		}
+	}
+
+	return &t, nil
+}
+
+// Duration returns the time the timeline takes, up to the end of its last
+// camera move, fade or text.
+func (t *Timeline) Duration() time.Duration {
+	var d time.Duration
+	for _, c := range t.Cues {
+		end := c.At
+		switch {
+		case c.Camera != nil:
+			end += c.Camera.Over
+		case c.Fade != nil:
+			end += c.Fade.Over
+		case c.Say != nil:
+			end += c.Say.duration()
+		}
+		if end > d {
+			d = end
+		}
+	}
+
+	return d
+}
+
+// validate checks that c has a single valid action, on the characters of
+// names spawned by the cues before it. The character c spawns is added to
+// names.
+func (c *Cue) validate(names map[string]bool) error {
+	var actions int
+	for _, set := range []bool{c.Spawn != nil, c.Walk != nil, c.State != nil, c.Say != nil, c.Effect != nil, c.Camera != nil, c.Fade != nil, c.Dialog != nil} {
+		if set {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return errors.Errorf("%d actions, expected 1", actions)
+	}
+
+	known := func(name string) error {
+		if !names[name] {
+			return errors.Errorf("unknown character '%s'", name)
+		}
+		return nil
+	}
+
+	switch {
+	case c.Spawn != nil:
+		if c.Spawn.Name == "" {
+			return errors.New("spawned character without a name")
+		}
+		if names[c.Spawn.Name] {
+			return errors.Errorf("character '%s' spawned twice", c.Spawn.Name)
+		}
+		if _, err := jobspriteid.Parse(c.Spawn.Job); err != nil {
+			return err
+		}
+		switch c.Spawn.Gender {
+		case "", "m", "male", "f", "female":
+		default:
+			return errors.Errorf("unknown gender '%s'", c.Spawn.Gender)
+		}
+		names[c.Spawn.Name] = true
+	case c.Walk != nil:
+		if len(c.Walk.To) == 0 {
+			return errors.New("no position to walk to")
+		}
+		return known(c.Walk.Name)
+	case c.State != nil:
+		if _, err := statetype.Parse(c.State.State); err != nil {
+			return err
+		}
+		return known(c.State.Name)
+	case c.Say != nil:
+		return known(c.Say.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,342 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/i18n"
+	"github.com/project-midgard/midgarts/internal/lod"
+	"github.com/project-midgard/midgarts/internal/theme"
+)
+
+const DefaultConfigFileName = "midgarts.yaml"
+
+// Config holds the client settings.
+type Config struct {
+	// GRFPaths are the archives to load assets from, the first ones
+	// taking precedence, or DATA.INI files listing them (see
+	// grf.ArchivePaths).
+	GRFPaths []string `yaml:"grf_paths"`
+	// DataDir is a loose data/ folder overriding the archives, if any.
+	DataDir       string `yaml:"data_dir"`
+	ServerAddress string `yaml:"server_address"`
+	// Language is the language of the text (see i18n), LanguageDir a
+	// folder of additional <language>.yaml packs, if any.
+	Language    string         `yaml:"language"`
+	LanguageDir string         `yaml:"language_dir"`
+	Graphics    GraphicsConfig `yaml:"graphics"`
+	Audio       AudioConfig    `yaml:"audio"`
+	// Accessibility selects the theme of the captions, the windows and
+	// the overlays.
+	Accessibility AccessibilityConfig `yaml:"accessibility"`
+	// Profiles are the settings switched with the character playing, by
+	// profile name (see Profile).
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+type GraphicsConfig struct {
+	Width      int  `yaml:"width"`
+	Height     int  `yaml:"height"`
+	FPS        int  `yaml:"fps"`
+	Fullscreen bool `yaml:"fullscreen"`
+	VSync      bool `yaml:"vsync"`
+	// TextureBudget is the memory of the textures, in MiB, 0 for no limit.
+	TextureBudget int `yaml:"texture_budget"`
+	// SpriteFilter and GroundFilter are the filtering of the sprite and
+	// map textures, "nearest" or "linear" (see graphic.ParseFilter).
+	SpriteFilter string `yaml:"sprite_filter"`
+	GroundFilter string `yaml:"ground_filter"`
+	// FrameBudget is the frame time, in milliseconds, past which the
+	// characters far from the player are updated less often, 0 to always
+	// update them (see timestep.Budget).
+	FrameBudget int `yaml:"frame_budget"`
+	// LODDistance is the camera distance, in world units, from which the
+	// detail of the characters is lowered, 0 to never lower it (see
+	// lod.LOD).
+	LODDistance float32 `yaml:"lod_distance"`
+}
+
+type AudioConfig struct {
+	// Mute plays no sound, the mixer not being opened.
+	Mute bool `yaml:"mute"`
+	// EffectVolume and MusicVolume are the volumes of the sound effects
+	// and of the music, from 0 to 1.
+	EffectVolume float32 `yaml:"effect_volume"`
+	MusicVolume  float32 `yaml:"music_volume"`
+	// ClientDir is the folder of the client holding the music of the maps
+	// (see audio.MusicTable), not in the archives.
+	ClientDir string `yaml:"client_dir"`
+}
+
+// Check returns an error when a volume is out of range.
+func (c AudioConfig) Check() error {
+	for name, v := range map[string]float32{"effect_volume": c.EffectVolume, "music_volume": c.MusicVolume} {
+		if v < 0 || v > 1 {
+			return fmt.Errorf("invalid %s %g, expected 0 to 1", name, v)
+		}
+	}
+
+	return nil
+}
+
+type AccessibilityConfig struct {
+	// Palette is the palette of the colors telling things apart, e.g.
+	// "deuteranopia" (see theme.Palettes).
+	Palette string `yaml:"palette"`
+	// TextScale multiplies the size of the text, from 1 to 3.
+	TextScale float32 `yaml:"text_scale"`
+	// HighContrast draws the text on opaque black and the overlays opaque.
+	HighContrast bool `yaml:"high_contrast"`
+}
+
+// Theme returns the theme of the accessibility options.
+func (c AccessibilityConfig) Theme() (theme.Theme, error) {
+	return theme.New(c.Palette, c.TextScale, c.HighContrast)
+}
+
+// Filters returns the filter of each texture class, the default one when
+// unset.
+func (c GraphicsConfig) Filters() (map[graphic.TextureClass]graphic.Filter, error) {
+	filters := map[graphic.TextureClass]graphic.Filter{}
+
+	for class, name := range map[graphic.TextureClass]string{
+		graphic.TextureSprite: c.SpriteFilter,
+		graphic.TextureGround: c.GroundFilter,
+	} {
+		if name == "" {
+			filters[class] = graphic.DefaultFilters[class]
+			continue
+		}
+
+		f, err := graphic.ParseFilter(name)
+		if err != nil {
+			return nil, err
+		}
+		filters[class] = f
+	}
+
+	return filters, nil
+}
+
+// DefaultConfig returns the default settings, using the GRF_FILE_PATH
+// environment variable as archive when set.
+func DefaultConfig() Config {
+	conf := Config{
+		ServerAddress: "127.0.0.1:6900",
+		Language:      i18n.DefaultLanguage,
+		Graphics: GraphicsConfig{
+			Width:  960,
+			Height: 720,
+			FPS:    60,
+			VSync:  true,
+			// Enough for a map and a crowd of characters.
+			TextureBudget: 256,
+			SpriteFilter:  graphic.DefaultFilters[graphic.TextureSprite].String(),
+			GroundFilter:  graphic.DefaultFilters[graphic.TextureGround].String(),
+			// 30fps.
+			FrameBudget: 33,
+			LODDistance: lod.DefaultDistance,
+		},
+		Audio: AudioConfig{
+			EffectVolume: 1,
+			MusicVolume:  0.6,
+			ClientDir:    ".",
+		},
+		Accessibility: AccessibilityConfig{
+			Palette:   theme.DefaultPalette,
+			TextScale: 1,
+		},
+		Profiles: map[string]Profile{
+			DefaultProfile: {Keybinds: map[string]string{}, Windows: map[string]WindowLayout{}},
+		},
+	}
+	for action, key := range DefaultKeybinds {
+		conf.Profiles[DefaultProfile].Keybinds[action] = key
+	}
+	for window, layout := range DefaultWindows {
+		conf.Profiles[DefaultProfile].Windows[window] = layout
+	}
+
+	if path := os.Getenv(EnvGRFPath); path != "" {
+		conf.GRFPaths = filepath.SplitList(path)
+	}
+
+	return conf
+}
+
+var configTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`# Midgarts client configuration.
+
+# GRF archives to load the assets from, or DATA.INI files listing them.
+# When an entry exists in several archives, the first one listed wins.
+grf_paths:
+{{- range .GRFPaths}}
+  - {{quote .}}
+{{- else}} []
+{{- end}}
+
+# Loose data folder (containing "data/...") overriding the archives,
+# leave empty to only use the archives.
+data_dir: {{quote .DataDir}}
+
+# Login server address (host:port).
+server_address: {{quote .ServerAddress}}
+
+# Language of the text, e.g. "en", "fr" or "pt-BR". The strings missing in
+# a language are shown in English.
+language: {{quote .Language}}
+
+# Folder of additional language packs, named <language>.yaml, leave empty
+# to only use the packs built in the client.
+language_dir: {{quote .LanguageDir}}
+
+graphics:
+  # Window size, in pixels.
+  width: {{.Graphics.Width}}
+  height: {{.Graphics.Height}}
+  # Frame rate cap, 0 for no cap.
+  fps: {{.Graphics.FPS}}
+  fullscreen: {{.Graphics.Fullscreen}}
+  # Synchronize the frames with the display refresh.
+  vsync: {{.Graphics.VSync}}
+  # Video memory of the textures, in MiB, 0 for no limit. The least
+  # recently used textures are freed past it.
+  texture_budget: {{.Graphics.TextureBudget}}
+  # Filtering of the textures, nearest (sharp pixels) or linear (smooth),
+  # for the sprites and the map.
+  sprite_filter: {{.Graphics.SpriteFilter}}
+  ground_filter: {{.Graphics.GroundFilter}}
+  # Frame time, in milliseconds, past which the characters far from the
+  # player are animated less often to keep up, 0 to always animate them.
+  frame_budget: {{.Graphics.FrameBudget}}
+  # Camera distance from which the shadows and shields are not drawn and
+  # the distant characters are animated less often, the farther the
+  # less, 0 to always draw them in full. The camera zooms from 8 to 100.
+  lod_distance: {{.Graphics.LODDistance}}
+
+audio:
+  # Play no sound.
+  mute: {{.Audio.Mute}}
+  # Volumes of the sound effects and of the music, from 0 to 1.
+  effect_volume: {{.Audio.EffectVolume}}
+  music_volume: {{.Audio.MusicVolume}}
+  # Folder of the client, holding the bgm folder of the music of the maps.
+  client_dir: {{quote .Audio.ClientDir}}
+
+accessibility:
+  # Colors of the HP bars, the damage and the walkability overlay: default,
+  # or safe for a color blindness, deuteranopia, protanopia or tritanopia.
+  palette: {{quote .Accessibility.Palette}}
+  # Size of the text of the captions and the console, from 1 to 3.
+  text_scale: {{.Accessibility.TextScale}}
+  # Draw the text on opaque black and the overlays opaque.
+  high_contrast: {{.Accessibility.HighContrast}}
+
+# Settings switched with the character playing. The default profile is
+# the one of every character, the profile named after its account then
+# the one named after the character itself (-account and -character)
+# override its settings.
+profiles:
+{{- range $name, $p := .Profiles}}
+  {{quote $name}}:
+    # Keys of the actions, named as by SDL, e.g. "F2", "W" or "` + "`" + `".
+    keybinds:
+{{- range $action, $key := $p.Keybinds}}
+      {{$action}}: {{quote $key}}
+{{- else}} {}
+{{- end}}
+    # Windows, placed in fractions of the screen from its top left corner.
+    windows:
+{{- range $window, $l := $p.Windows}}
+      {{$window}}: {x: {{$l.X}}, y: {{$l.Y}}, width: {{$l.Width}}, height: {{$l.Height}}}
+{{- else}} {}
+{{- end}}
+    options:
+      # Distance of the camera to the ground when the character enters a
+      # map, from 8 to 100, 0 to keep it.
+      camera_distance: {{$p.Options.CameraDistance}}
+{{- with $p.Options.GATOverlay}}
+      # Show the walkability overlay.
+      gat_overlay: {{.}}
+{{- end}}
+{{- end}}
+`))
+
+// WriteConfig writes conf as a commented YAML file.
+func WriteConfig(w io.Writer, conf Config) error {
+	return configTemplate.Execute(w, conf)
+}
+
+// WriteConfigFile writes conf to path, refusing to replace an existing
+// file unless overwrite is set.
+func WriteConfigFile(path string, conf Config, overwrite bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err = WriteConfig(f, conf); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "could not write config")
+	}
+
+	return f.Close()
+}
+
+// Check verifies that the referenced archives exist and are valid GRF
+// files, and that the data folder exists. It returns every problem found.
+func (c Config) Check() (problems []error) {
+	if len(c.GRFPaths) == 0 {
+		problems = append(problems, errors.New("no grf_paths configured"))
+	}
+
+	paths, err := grf.ArchivePaths(c.GRFPaths)
+	if err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid grf_paths"))
+	}
+	for _, path := range paths {
+		f, err := grf.Load(path)
+		if err != nil {
+			problems = append(problems, errors.Wrapf(err, "invalid archive '%s'", path))
+			continue
+		}
+		_ = f.Close()
+	}
+
+	if c.Graphics.FPS < 0 {
+		problems = append(problems, fmt.Errorf("invalid fps %d, 0 for no cap", c.Graphics.FPS))
+	}
+	if _, err := c.Graphics.Filters(); err != nil {
+		problems = append(problems, err)
+	}
+	if err := c.Audio.Check(); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid audio settings"))
+	}
+	if _, err := c.Accessibility.Theme(); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid accessibility settings"))
+	}
+
+	if c.DataDir != "" {
+		if fi, err := os.Stat(c.DataDir); err != nil {
+			problems = append(problems, errors.Wrap(err, "invalid data_dir"))
+		} else if !fi.IsDir() {
+			problems = append(problems, fmt.Errorf("data_dir '%s' is not a directory", c.DataDir))
+		}
+	}
+
+	return problems
+}
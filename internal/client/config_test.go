@@ -0,0 +1,154 @@
+package client
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/project-midgard/midgarts/internal/graphic"
+)
+
+func TestWriteConfig(t *testing.T) {
+	conf := DefaultConfig()
+	conf.GRFPaths = []string{"/data/data.grf", `C:\RO\rdata.grf`}
+	conf.DataDir = "data"
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, WriteConfig(buf, conf))
+
+	var got Config
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, conf, got)
+
+	conf.GRFPaths = nil
+	buf.Reset()
+	assert.NoError(t, WriteConfig(buf, conf))
+
+	got = Config{}
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &got))
+	assert.Empty(t, got.GRFPaths)
+}
+
+func TestCheck(t *testing.T) {
+	conf := Config{GRFPaths: []string{"../../assets/grf/with-files.grf", "../../assets/grf/not-grf.grf"}}
+	assert.Len(t, conf.Check(), 1)
+
+	conf.DataDir = "does-not-exist"
+	assert.Len(t, conf.Check(), 2)
+
+	conf.Graphics.SpriteFilter = "trilinear"
+	assert.Len(t, conf.Check(), 3)
+
+	conf.Accessibility.Palette = "sepia"
+	assert.Len(t, conf.Check(), 4)
+
+	conf.Audio.MusicVolume = 2
+	assert.Len(t, conf.Check(), 5)
+
+	conf.Graphics.FPS = -1
+	assert.Len(t, conf.Check(), 6)
+}
+
+// setenv sets an environment variable for the duration of the test.
+func setenv(t *testing.T, key, value string) {
+	previous, ok := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, previous)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+func TestFilters(t *testing.T) {
+	filters, err := DefaultConfig().Graphics.Filters()
+	require.NoError(t, err)
+	assert.Equal(t, graphic.DefaultFilters, filters)
+
+	filters, err = GraphicsConfig{SpriteFilter: "bilinear"}.Filters()
+	require.NoError(t, err)
+	assert.Equal(t, graphic.FilterLinear, filters[graphic.TextureSprite])
+	assert.Equal(t, graphic.FilterLinear, filters[graphic.TextureGround])
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "midgarts.yaml")
+	conf := DefaultConfig()
+	conf.GRFPaths = []string{"file.grf"}
+	conf.DataDir = "file-data"
+	conf.Graphics.Width = 1280
+	require.NoError(t, WriteConfigFile(path, conf, false))
+
+	setenv(t, EnvGRFPath, "")
+	setenv(t, EnvConfigPath, "")
+	setenv(t, EnvDataDir, "env-data")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := RegisterFlags(fs).RegisterClientFlags()
+	require.NoError(t, fs.Parse([]string{"-config", path, "-height", "600", "-fps", "0", "-vsync=false", "-lang", "fr", "-mute"}))
+
+	got, err := f.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file.grf"}, got.GRFPaths)
+	assert.Equal(t, "env-data", got.DataDir)
+	assert.Equal(t, 1280, got.Graphics.Width)
+	assert.Equal(t, 600, got.Graphics.Height)
+	assert.Equal(t, 0, got.Graphics.FPS)
+	assert.False(t, got.Graphics.VSync)
+	assert.Equal(t, "fr", got.Language)
+	assert.True(t, got.Audio.Mute)
+	assert.Equal(t, DefaultConfig().ServerAddress, got.ServerAddress)
+
+	setenv(t, EnvGRFPath, "a.grf"+string(filepath.ListSeparator)+"b.grf")
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	f = RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"-config", path}))
+
+	got, err = f.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.grf", "b.grf"}, got.GRFPaths)
+
+	require.NoError(t, fs.Parse([]string{"-config", path, "-grf", "c.grf,d.grf"}))
+	got, err = f.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c.grf", "d.grf"}, got.GRFPaths)
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	f = RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}))
+	_, err = f.Load()
+	assert.Error(t, err)
+}
+
+func TestLoadUserConfig(t *testing.T) {
+	dir := t.TempDir()
+	setenv(t, "XDG_CONFIG_HOME", dir)
+	setenv(t, "HOME", dir)
+	setenv(t, "AppData", dir)
+	setenv(t, EnvGRFPath, "")
+	setenv(t, EnvConfigPath, "")
+
+	path, err := UserConfigPath()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(path, dir))
+	assert.Equal(t, []string{DefaultConfigFileName, path}, DefaultConfigPaths())
+
+	conf := DefaultConfig()
+	conf.GRFPaths = []string{filepath.Join(dir, "user.grf")}
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, WriteConfigFile(path, conf, false))
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	got, err := RegisterFlags(fs).Load()
+	require.NoError(t, err)
+	assert.Equal(t, conf.GRFPaths, got.GRFPaths)
+}
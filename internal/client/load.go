@@ -0,0 +1,172 @@
+package client
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables overriding the config file. GRF_FILE_PATH may
+// list several archives, separated as in PATH.
+const (
+	EnvGRFPath       = "GRF_FILE_PATH"
+	EnvDataDir       = "DATA_DIR"
+	EnvServerAddress = "SERVER_ADDRESS"
+	EnvConfigPath    = "MIDGARTS_CONFIG"
+)
+
+// UserConfigPath returns the path of the config file in the midgarts
+// folder of the user config folder (see os.UserConfigDir): under
+// %AppData% on Windows, ~/Library/Application Support on macOS and
+// $XDG_CONFIG_HOME or ~/.config elsewhere.
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.Wrap(err, "could not find the user config folder")
+	}
+
+	return filepath.Join(dir, "midgarts", DefaultConfigFileName), nil
+}
+
+// DefaultConfigPaths returns the config files read when none is given, the
+// first existing one being used: DefaultConfigFileName in the working
+// directory, then the one of UserConfigPath.
+func DefaultConfigPaths() []string {
+	paths := []string{DefaultConfigFileName}
+	if path, err := UserConfigPath(); err == nil {
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+// LoadConfigFile reads a YAML config file over conf, keeping the settings
+// the file does not mention.
+func LoadConfigFile(path string, conf *Config) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err = yaml.Unmarshal(data, conf); err != nil {
+		return errors.Wrapf(err, "invalid config file '%s'", path)
+	}
+
+	return nil
+}
+
+// ApplyEnv overrides conf with the environment variables that are set.
+func ApplyEnv(conf *Config) {
+	if v := os.Getenv(EnvGRFPath); v != "" {
+		conf.GRFPaths = filepath.SplitList(v)
+	}
+
+	if v := os.Getenv(EnvDataDir); v != "" {
+		conf.DataDir = v
+	}
+
+	if v := os.Getenv(EnvServerAddress); v != "" {
+		conf.ServerAddress = v
+	}
+}
+
+// Flags are the command line flags overriding the configuration.
+type Flags struct {
+	fs *flag.FlagSet
+
+	configPath    string
+	grfPaths      string
+	dataDir       string
+	serverAddress string
+	language      string
+	width, height int
+	fps           int
+	vsync         bool
+	mute          bool
+}
+
+// RegisterFlags registers the flags every entry point shares: -config,
+// -grf and -data-dir.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs}
+
+	fs.StringVar(&f.configPath, "config", "", "path of the config file, defaults to the first existing one of "+strings.Join(DefaultConfigPaths(), ", "))
+	fs.StringVar(&f.grfPaths, "grf", "", "comma separated GRF archives, overriding the config file and "+EnvGRFPath)
+	fs.StringVar(&f.dataDir, "data-dir", "", "loose data folder, overriding the config file and "+EnvDataDir)
+
+	return f
+}
+
+// RegisterClientFlags also registers the flags of the client itself:
+// -server, -lang, -width, -height, -fps, -vsync and -mute.
+func (f *Flags) RegisterClientFlags() *Flags {
+	f.fs.StringVar(&f.serverAddress, "server", "", "login server address (host:port)")
+	f.fs.StringVar(&f.language, "lang", "", "language of the text, e.g. en, fr or pt-BR")
+	f.fs.IntVar(&f.width, "width", 0, "window width, in pixels")
+	f.fs.IntVar(&f.height, "height", 0, "window height, in pixels")
+	f.fs.IntVar(&f.fps, "fps", 0, "frame rate cap, 0 for no cap")
+	f.fs.BoolVar(&f.vsync, "vsync", false, "synchronize the frames with the display refresh")
+	f.fs.BoolVar(&f.mute, "mute", false, "play no sound")
+
+	return f
+}
+
+// Load returns the configuration once the flags are parsed: the defaults,
+// overridden by the config file, then the environment, then the flags.
+func (f *Flags) Load() (Config, error) {
+	conf := DefaultConfig()
+
+	paths, required := []string{f.configPath}, true
+	if f.configPath == "" {
+		paths[0] = os.Getenv(EnvConfigPath)
+	}
+	if paths[0] == "" {
+		paths, required = DefaultConfigPaths(), false
+	}
+
+	for _, path := range paths {
+		err := LoadConfigFile(path, &conf)
+		if err == nil {
+			break
+		}
+		if required || !os.IsNotExist(err) {
+			return conf, errors.Wrap(err, "could not load config")
+		}
+	}
+
+	ApplyEnv(&conf)
+
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "grf":
+			conf.GRFPaths = strings.Split(f.grfPaths, ",")
+		case "data-dir":
+			conf.DataDir = f.dataDir
+		case "server":
+			conf.ServerAddress = f.serverAddress
+		case "lang":
+			conf.Language = f.language
+		case "width":
+			conf.Graphics.Width = f.width
+		case "height":
+			conf.Graphics.Height = f.height
+		case "fps":
+			conf.Graphics.FPS = f.fps
+		case "vsync":
+			conf.Graphics.VSync = f.vsync
+		case "mute":
+			conf.Audio.Mute = f.mute
+		}
+	})
+
+	if len(conf.GRFPaths) == 0 {
+		return conf, errors.Errorf("no GRF archive configured, set grf_paths in %s, %s or -grf", strings.Join(DefaultConfigPaths(), " or "), EnvGRFPath)
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"sort"
+)
+
+// DefaultProfile is the name of the profile every character plays with,
+// the ones of its account and of itself overriding it (see
+// Config.Profile).
+const DefaultProfile = "default"
+
+// The actions bound to keys.
+const (
+	ActionMoveNorth    = "move_north"
+	ActionMoveSouth    = "move_south"
+	ActionMoveEast     = "move_east"
+	ActionMoveWest     = "move_west"
+	ActionCameraIn     = "camera_in"
+	ActionCameraOut    = "camera_out"
+	ActionCameraEast   = "camera_east"
+	ActionCameraWest   = "camera_west"
+	ActionOrbitLeft    = "orbit_left"
+	ActionOrbitRight   = "orbit_right"
+	ActionGATOverlay   = "gat_overlay"
+	ActionSnapshot     = "snapshot"
+	ActionPause        = "pause"
+	ActionSlowMotion   = "slow_motion"
+	ActionCycleWeather = "cycle_weather"
+	ActionCycleStatus  = "cycle_status"
+	ActionConsole      = "console"
+)
+
+// DefaultKeybinds are the keys of the actions, named as by SDL.
+var DefaultKeybinds = map[string]string{
+	ActionMoveNorth:    "W",
+	ActionMoveSouth:    "S",
+	ActionMoveEast:     "D",
+	ActionMoveWest:     "A",
+	ActionCameraIn:     "Z",
+	ActionCameraOut:    "X",
+	ActionCameraEast:   "C",
+	ActionCameraWest:   "V",
+	ActionOrbitLeft:    "Q",
+	ActionOrbitRight:   "E",
+	ActionGATOverlay:   "F2",
+	ActionSnapshot:     "F5",
+	ActionPause:        "F6",
+	ActionSlowMotion:   "F7",
+	ActionCycleWeather: "F8",
+	ActionCycleStatus:  "F9",
+	ActionConsole:      "`",
+}
+
+// WindowConsole is the name of the developer console window.
+const WindowConsole = "console"
+
+// DefaultWindows are the layouts of the windows, by name.
+var DefaultWindows = map[string]WindowLayout{
+	WindowConsole: {Width: 1, Height: 0.5},
+}
+
+// Profile is a set of settings switched with the character playing: the
+// keys of the actions, the layout of the windows and options.
+type Profile struct {
+	// Keybinds are the keys of the actions, named as by SDL (e.g. "F2",
+	// "W" or "`"), by action.
+	Keybinds map[string]string `yaml:"keybinds,omitempty"`
+	// Windows are the layouts of the windows, by name.
+	Windows map[string]WindowLayout `yaml:"windows,omitempty"`
+	Options ProfileOptions          `yaml:"options,omitempty"`
+}
+
+// WindowLayout places a window, in fractions of the screen from its top
+// left corner.
+type WindowLayout struct {
+	X      float32 `yaml:"x"`
+	Y      float32 `yaml:"y"`
+	Width  float32 `yaml:"width"`
+	Height float32 `yaml:"height"`
+}
+
+// ProfileOptions are the options of a profile, applied when a character
+// enters a map. The zero options keep the ones of the client.
+type ProfileOptions struct {
+	// CameraDistance is the distance of the camera to the ground, in world
+	// units (see camera.Camera.Distance).
+	CameraDistance float32 `yaml:"camera_distance,omitempty"`
+	// GATOverlay shows the walkability overlay, as the F2 key.
+	GATOverlay *bool `yaml:"gat_overlay,omitempty"`
+}
+
+// Profile returns the settings of the profiles of names, e.g. an account
+// then one of its characters, each overriding the settings the ones
+// before it set, over the DefaultProfile, itself over DefaultKeybinds and
+// DefaultWindows. The names without profile are skipped.
+func (c Config) Profile(names ...string) Profile {
+	p := Profile{Keybinds: map[string]string{}, Windows: map[string]WindowLayout{}}
+	for action, key := range DefaultKeybinds {
+		p.Keybinds[action] = key
+	}
+	for window, layout := range DefaultWindows {
+		p.Windows[window] = layout
+	}
+
+	for _, name := range append([]string{DefaultProfile}, names...) {
+		named, ok := c.Profiles[name]
+		if !ok || name == "" {
+			continue
+		}
+
+		for action, key := range named.Keybinds {
+			p.Keybinds[action] = key
+		}
+		for window, layout := range named.Windows {
+			p.Windows[window] = layout
+		}
+		if named.Options.CameraDistance != 0 {
+			p.Options.CameraDistance = named.Options.CameraDistance
+		}
+		if named.Options.GATOverlay != nil {
+			p.Options.GATOverlay = named.Options.GATOverlay
+		}
+	}
+
+	return p
+}
+
+// ProfileNames returns the names of the profiles, sorted.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestProfile(t *testing.T) {
+	overlay, hidden := true, false
+	conf := DefaultConfig()
+	conf.Profiles["account"] = Profile{
+		Keybinds: map[string]string{ActionGATOverlay: "F3"},
+		Options:  ProfileOptions{CameraDistance: 40, GATOverlay: &overlay},
+	}
+	conf.Profiles["knight"] = Profile{
+		Keybinds: map[string]string{ActionMoveNorth: "Up"},
+		Windows:  map[string]WindowLayout{"console": {Y: 0.5, Width: 1, Height: 0.5}},
+		Options:  ProfileOptions{GATOverlay: &hidden},
+	}
+
+	p := conf.Profile("account", "knight")
+	assert.Equal(t, "F3", p.Keybinds[ActionGATOverlay])
+	assert.Equal(t, "Up", p.Keybinds[ActionMoveNorth])
+	assert.Equal(t, "S", p.Keybinds[ActionMoveSouth])
+	assert.Equal(t, WindowLayout{Y: 0.5, Width: 1, Height: 0.5}, p.Windows["console"])
+	assert.Equal(t, float32(40), p.Options.CameraDistance)
+	require.NotNil(t, p.Options.GATOverlay)
+	assert.False(t, *p.Options.GATOverlay)
+
+	// The characters without profile play with the default one.
+	p = conf.Profile("", "swordman")
+	assert.Equal(t, DefaultKeybinds, p.Keybinds)
+	assert.Equal(t, DefaultWindows, p.Windows)
+	assert.Nil(t, p.Options.GATOverlay)
+
+	assert.Equal(t, []string{"account", DefaultProfile, "knight"}, conf.ProfileNames())
+
+	// The configurations written before the profiles.
+	p = Config{}.Profile("knight")
+	assert.Equal(t, DefaultKeybinds, p.Keybinds)
+}
+
+func TestWriteConfigProfiles(t *testing.T) {
+	overlay := true
+	conf := DefaultConfig()
+	conf.Profiles["knight"] = Profile{
+		Keybinds: map[string]string{ActionConsole: "F12"},
+		Windows:  map[string]WindowLayout{"console": {X: 0.25, Width: 0.5, Height: 0.75}},
+		Options:  ProfileOptions{CameraDistance: 50, GATOverlay: &overlay},
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteConfig(buf, conf))
+
+	var got Config
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, conf.Profiles[DefaultProfile], got.Profiles[DefaultProfile])
+	assert.Equal(t, conf.Profiles["knight"], got.Profiles["knight"])
+}
@@ -0,0 +1,136 @@
+package component
+
+import (
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+)
+
+type animationEventKind int
+
+const (
+	animationFrame animationEventKind = iota
+	animationLoop
+	animationComplete
+)
+
+type animationCallback struct {
+	// state is the state of the character when the callback was added, the
+	// callback belongs to the animation of that state.
+	state statetype.Type
+	kind  animationEventKind
+	frame int
+	fn    func()
+}
+
+type frameHook struct {
+	id int
+	fn func(state statetype.Type, frame int)
+}
+
+// AnimationEvents holds the callbacks of the animation of a character, so
+// gameplay can apply a hit at the attack frame or chain states once an
+// animation is done. The callbacks belong to the animation of the state
+// the character is in when they are added: they are dropped when an
+// animation of another state starts.
+type AnimationEvents struct {
+	callbacks []animationCallback
+	// frameHooks are called at every frame of every animation.
+	frameHooks []frameHook
+	nextHookID int
+	// next is the frame, counting the repeats, from which Advance calls
+	// the callbacks.
+	next      int
+	completed bool
+}
+
+// OnFrame calls fn each time the frame n of the animation of state starts.
+func (e *AnimationEvents) OnFrame(state statetype.Type, n int, fn func()) {
+	e.callbacks = append(e.callbacks, animationCallback{state: state, kind: animationFrame, frame: n, fn: fn})
+}
+
+// OnLoop calls fn each time the animation of state starts over.
+func (e *AnimationEvents) OnLoop(state statetype.Type, fn func()) {
+	e.callbacks = append(e.callbacks, animationCallback{state: state, kind: animationLoop, fn: fn})
+}
+
+// OnComplete calls fn once the animation of state played through.
+func (e *AnimationEvents) OnComplete(state statetype.Type, fn func()) {
+	e.callbacks = append(e.callbacks, animationCallback{state: state, kind: animationComplete, fn: fn})
+}
+
+// OnEachFrame calls fn each time a frame of an animation starts, whatever
+// its state, e.g. to play the sounds of the frames, and returns the
+// function removing it. Unlike the other callbacks, fn is kept from an
+// animation to the next.
+func (e *AnimationEvents) OnEachFrame(fn func(state statetype.Type, frame int)) (unsubscribe func()) {
+	e.nextHookID++
+	id := e.nextHookID
+	e.frameHooks = append(e.frameHooks, frameHook{id: id, fn: fn})
+
+	return func() {
+		for i, h := range e.frameHooks {
+			if h.id == id {
+				e.frameHooks = append(e.frameHooks[:i:i], e.frameHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Restart starts the events of a new animation of state, dropping the
+// callbacks of the other states.
+func (e *AnimationEvents) Restart(state statetype.Type) {
+	kept := e.callbacks[:0]
+	for _, c := range e.callbacks {
+		if c.state == state {
+			kept = append(kept, c)
+		}
+	}
+	for i := len(kept); i < len(e.callbacks); i++ {
+		e.callbacks[i] = animationCallback{}
+	}
+
+	e.callbacks = kept
+	e.next = 0
+	e.completed = false
+}
+
+// Advance calls the callbacks of the frames started up to frame, counting
+// the repeats, of an animation of frameCount frames. A frame of
+// frameCount or more completes the animation; it then only goes on when
+// repeat is set.
+func (e *AnimationEvents) Advance(state statetype.Type, frame, frameCount int, repeat bool) {
+	if frameCount <= 0 {
+		return
+	}
+
+	for ; e.next <= frame; e.next++ {
+		if e.next >= frameCount && !e.completed {
+			e.completed = true
+			e.call(state, animationComplete, 0)
+		}
+		if e.completed && !repeat {
+			e.next = frame + 1
+			return
+		}
+
+		n := e.next % frameCount
+		if n == 0 && e.next > 0 {
+			e.call(state, animationLoop, 0)
+		}
+		e.call(state, animationFrame, n)
+		for _, h := range e.frameHooks {
+			h.fn(state, n)
+		}
+	}
+}
+
+func (e *AnimationEvents) call(state statetype.Type, kind animationEventKind, frame int) {
+	// Callbacks may add callbacks.
+	callbacks := append([]animationCallback(nil), e.callbacks...)
+
+	for _, c := range callbacks {
+		if c.state == state && c.kind == kind && (kind != animationFrame || c.frame == frame) {
+			c.fn()
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package component
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+)
+
+func TestAnimationEvents(t *testing.T) {
+	var (
+		e      AnimationEvents
+		called []string
+	)
+
+	e.OnFrame(statetype.Attacking, 2, func() { called = append(called, "hit") })
+	e.OnLoop(statetype.Attacking, func() { called = append(called, "loop") })
+	e.OnComplete(statetype.Attacking, func() { called = append(called, "complete") })
+	e.Restart(statetype.Attacking)
+
+	e.Advance(statetype.Attacking, 1, 4, true)
+	assert.Empty(t, called)
+
+	// Frames skipped between two updates still call their callbacks.
+	e.Advance(statetype.Attacking, 4, 4, true)
+	assert.Equal(t, []string{"hit", "complete", "loop"}, called)
+
+	e.Advance(statetype.Attacking, 6, 4, true)
+	assert.Equal(t, []string{"hit", "complete", "loop", "hit"}, called)
+}
+
+func TestAnimationEventsOnce(t *testing.T) {
+	var (
+		e        AnimationEvents
+		hits     int
+		complete int
+	)
+
+	e.OnFrame(statetype.Attacking, 0, func() { hits++ })
+	e.OnComplete(statetype.Attacking, func() { complete++ })
+
+	e.Advance(statetype.Attacking, 3, 2, false)
+	e.Advance(statetype.Attacking, 8, 2, false)
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 1, complete)
+}
+
+func TestAnimationEventsRestart(t *testing.T) {
+	var (
+		e     AnimationEvents
+		calls int
+	)
+
+	e.OnFrame(statetype.Attacking, 0, func() { calls++ })
+
+	// Added for the next animation, not the current one.
+	e.Advance(statetype.StandBy, 0, 4, true)
+	assert.Equal(t, 0, calls)
+
+	e.Restart(statetype.Attacking)
+	e.Advance(statetype.Attacking, 0, 4, true)
+	assert.Equal(t, 1, calls)
+
+	// Dropped once another animation starts.
+	e.Restart(statetype.StandBy)
+	e.Restart(statetype.Attacking)
+	e.Advance(statetype.Attacking, 0, 4, true)
+	assert.Equal(t, 1, calls)
+}
+
+func TestAnimationEventsOnEachFrame(t *testing.T) {
+	var (
+		e      AnimationEvents
+		frames []int
+	)
+
+	unsubscribe := e.OnEachFrame(func(state statetype.Type, frame int) {
+		if state == statetype.Attacking {
+			frames = append(frames, frame)
+		}
+	})
+
+	e.Restart(statetype.Attacking)
+	e.Advance(statetype.Attacking, 2, 2, true)
+	assert.Equal(t, []int{0, 1, 0}, frames)
+
+	// Kept from an animation to the next.
+	e.Restart(statetype.StandBy)
+	e.Restart(statetype.Attacking)
+	e.Advance(statetype.Attacking, 0, 2, true)
+	assert.Equal(t, []int{0, 1, 0, 0}, frames)
+
+	unsubscribe()
+	e.Advance(statetype.Attacking, 1, 2, true)
+	assert.Equal(t, []int{0, 1, 0, 0}, frames)
+	assert.Empty(t, e.frameHooks)
+}
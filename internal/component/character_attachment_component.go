@@ -1,15 +1,21 @@
 package component
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
 	"github.com/pkg/errors"
-	"golang.org/x/text/encoding/charmap"
 
 	"github.com/project-midgard/midgarts/internal/character"
 	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
 	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/imf"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/pkg/encoding"
 )
 
 type CharacterAttachmentComponentFace interface {
@@ -20,6 +26,15 @@ type CharacterAttachmentComponentFace interface {
 // character attachments (shadow, body, head...).
 type CharacterAttachmentComponent struct {
 	Files map[character.AttachmentType]grf.ActionSpriteFilePair
+	// Paths holds the sprite path, without extension, of each attachment.
+	Paths map[character.AttachmentType]string
+	// Timelines holds the timeline of each action of each attachment,
+	// computed once the files are loaded.
+	Timelines map[character.AttachmentType][]*act.Timeline
+	// Priorities orders the body and the head of the players at each
+	// frame, see imf.PriorityFile.HeadBehind; nil when the job has no IMF
+	// file, the head drawn in front of the body.
+	Priorities *imf.PriorityFile
 }
 
 type CharacterAttachmentComponentConfig struct {
@@ -28,29 +43,115 @@ type CharacterAttachmentComponentConfig struct {
 	HeadIndex        character.HeadIndex
 	EnableShield     bool
 	ShieldSpriteName string // Loaded from GRF
+	// TopHeadgear, MidHeadgear and LowHeadgear are the view ids of the
+	// headgears worn, see character.HeadgearNameTable, 0 for none.
+	TopHeadgear, MidHeadgear, LowHeadgear int
+	// Weapon is the class of the weapon held, weapontype.None for none.
+	Weapon weapontype.Type
+	// SpritePath is the sprite, without extension, of the characters that
+	// are a single sprite (monsters, NPCs, items), replacing the body and
+	// head of the job.
+	SpritePath string
+	// Palettes are the indices of the palettes of data/palette dyeing the
+	// body and the head (see CharacterPalettePath), by attachment. 0 keeps
+	// the palette of the sprite.
+	Palettes map[character.AttachmentType]int
 }
 
+// SpriteLoader loads the ACT and SPR files of a sprite, given its path
+// without extension, as grf.File and asset.Manager do.
+type SpriteLoader interface {
+	GetSpriteFilesContext(ctx context.Context, name string) (grf.ActionSpriteFilePair, error)
+}
+
+// PaletteLoader loads the palettes of data/palette, as grf.File and
+// asset.Manager do. The SpriteLoader of a character with Palettes must
+// load palettes too.
+type PaletteLoader interface {
+	GetPaletteContext(ctx context.Context, path string) (*pal.PaletteFile, error)
+}
+
+// PriorityLoader loads the IMF files of data/imf, as grf.File and
+// asset.Manager do.
+type PriorityLoader interface {
+	GetPrioritiesContext(ctx context.Context, path string) (*imf.PriorityFile, error)
+}
+
+// NewCharacterAttachmentComponent loads the attachments of a character,
+// dyed with their palettes, and the IMF file of its job when f is a
+// PriorityLoader. On error, the attachments loaded so far are
+// kept in the returned component.
 func NewCharacterAttachmentComponent(
-	f *grf.File,
+	ctx context.Context,
+	f SpriteLoader,
 	conf CharacterAttachmentComponentConfig,
 ) (*CharacterAttachmentComponent, error) {
 	cmp := &CharacterAttachmentComponent{
-		Files: make(map[character.AttachmentType]grf.ActionSpriteFilePair),
+		Files:     make(map[character.AttachmentType]grf.ActionSpriteFilePair),
+		Paths:     make(map[character.AttachmentType]string),
+		Timelines: make(map[character.AttachmentType][]*act.Timeline),
+	}
+
+	paths, err := CharacterAttachmentPaths(conf)
+	if err != nil {
+		return cmp, err
+	}
+
+	for _, elem := range character.Attachments() {
+		path, ok := paths[elem]
+		if !ok {
+			continue
+		}
+
+		files, err := f.GetSpriteFilesContext(ctx, path)
+		// Not every weapon has a slash.
+		if err != nil && elem == character.AttachmentWeaponSlash {
+			continue
+		}
+		if err != nil {
+			return cmp, errors.Wrapf(err, "could not load %v act and spr files (%s)", elem, path)
+		}
+		if conf.Palettes[elem] != 0 {
+			if files.SPR, err = dye(ctx, f, conf, elem, files.SPR); err != nil {
+				return cmp, err
+			}
+		}
+		cmp.Files[elem] = files
+		cmp.Paths[elem] = path
+		cmp.Timelines[elem] = act.NewTimelines(files.ACT)
+	}
+
+	// Many jobs have no IMF file: their heads are always in front.
+	if loader, ok := f.(PriorityLoader); ok && conf.SpritePath == "" {
+		cmp.Priorities, _ = loader.GetPrioritiesContext(ctx, character.PriorityFilePath(conf.Gender, conf.JobSpriteID))
+	}
+
+	return cmp, nil
+}
+
+// CharacterAttachmentPaths returns the sprite path, without extension, of
+// each attachment of a character, so they can be loaded ahead of time.
+func CharacterAttachmentPaths(conf CharacterAttachmentComponentConfig) (map[character.AttachmentType]string, error) {
+	if conf.SpritePath != "" {
+		return map[character.AttachmentType]string{
+			character.AttachmentShadow: "data/sprite/shadow",
+			character.AttachmentBody:   conf.SpritePath,
+		}, nil
 	}
 
 	jobFileName := character.JobSpriteNameTable[conf.JobSpriteID]
 	if "" == jobFileName {
-		return cmp, fmt.Errorf("unsupported jobSpriteID: %v", conf.JobSpriteID)
+		return nil, fmt.Errorf("unsupported jobSpriteID: %v", conf.JobSpriteID)
 	}
 
 	decodedFolderA, err := getDecodedFolder([]byte{0xC0, 0xCE, 0xB0, 0xA3, 0xC1, 0xB7})
 	if err != nil {
-		return cmp, errors.Wrap(err, "unable to decode folder name")
+		return nil, errors.Wrap(err, "unable to decode folder name")
 	}
 
 	decodedFolderB, err := getDecodedFolder([]byte{0xB8, 0xF6, 0xC5, 0xEB})
 	if err != nil {
-		return cmp, errors.Wrap(err, "unable to decode folder name")
+		return nil, errors.Wrap(err, "unable to decode folder name")
 	}
 
 	genderPath := "³²"
@@ -58,38 +159,90 @@ func NewCharacterAttachmentComponent(
 		genderPath = "¿©"
 	}
 
-	cmp.Files[character.AttachmentShadow], err = f.GetSpriteFiles("data/sprite/shadow")
-	if err != nil {
-		return cmp, errors.Wrapf(err, "could not load shadow act and spr files (%v, %s)", conf.Gender, conf.JobSpriteID)
+	paths := map[character.AttachmentType]string{
+		character.AttachmentShadow: "data/sprite/shadow",
+		character.AttachmentBody:   "data/sprite/" + decodedFolderA + "/" + decodedFolderB + "/" + genderPath + "/" + jobFileName + "_" + genderPath,
+		character.AttachmentHead:   "data/sprite/ÀÎ°£Á·/¸Ó¸®Åë/" + genderPath + "/" + strconv.Itoa(int(conf.HeadIndex)) + "_" + genderPath,
+	}
+
+	if conf.EnableShield {
+		if conf.ShieldSpriteName == "" {
+			conf.ShieldSpriteName = "°¡µå"
+		}
+		paths[character.AttachmentShield] = "data/sprite/¹æÆÐ/" + jobFileName + "/" + jobFileName + "_" + genderPath + "_" + conf.ShieldSpriteName
+	}
+
+	for _, h := range [...]struct {
+		elem character.AttachmentType
+		id   int
+	}{
+		{character.AttachmentHeadgearLow, conf.LowHeadgear},
+		{character.AttachmentHeadgearMid, conf.MidHeadgear},
+		{character.AttachmentHeadgearTop, conf.TopHeadgear},
+	} {
+		if h.id == 0 {
+			continue
+		}
+		if paths[h.elem], err = character.HeadgearSpritePath(conf.Gender, h.id); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.Weapon != weapontype.None {
+		weapon, err := character.WeaponFilePath(conf.Gender, conf.JobSpriteID, conf.Weapon)
+		if err != nil {
+			return nil, err
+		}
+		paths[character.AttachmentWeapon] = weapon
+		paths[character.AttachmentWeaponSlash] = weapon + character.WeaponSlashSuffix
 	}
 
-	bodyFilePath := "data/sprite/" + decodedFolderA + "/" + decodedFolderB + "/" + genderPath + "/" + jobFileName + "_" + genderPath
-	cmp.Files[character.AttachmentBody], err = f.GetSpriteFiles(bodyFilePath)
+	return paths, nil
+}
+
+// dye returns sprFile, the sprite of the attachment elem, with the palette
+// of conf.
+func dye(
+	ctx context.Context,
+	f SpriteLoader,
+	conf CharacterAttachmentComponentConfig,
+	elem character.AttachmentType,
+	sprFile *spr.SpriteFile,
+) (*spr.SpriteFile, error) {
+	path, err := CharacterPalettePath(conf, elem, conf.Palettes[elem])
 	if err != nil {
-		return cmp, errors.Wrapf(err, "could not load body act and spr files (%v, %s)", conf.Gender, conf.JobSpriteID)
+		return nil, err
 	}
 
-	headFilePath := "data/sprite/ÀÎ°£Á·/¸Ó¸®Åë/" + genderPath + "/" + strconv.Itoa(int(conf.HeadIndex)) + "_" + genderPath
-	cmp.Files[character.AttachmentHead], err = f.GetSpriteFiles(headFilePath)
+	loader, ok := f.(PaletteLoader)
+	if !ok {
+		return nil, errors.Errorf("could not load %v palette (%s): the loader has no palettes", elem, path)
+	}
+
+	p, err := loader.GetPaletteContext(ctx, path)
 	if err != nil {
-		return cmp, errors.Wrapf(err, "could not load head act and spr files (%v, %s)", conf.Gender, conf.JobSpriteID)
+		return nil, errors.Wrapf(err, "could not load %v palette (%s)", elem, path)
 	}
 
-	if conf.EnableShield {
-		if conf.ShieldSpriteName == "" {
-			conf.ShieldSpriteName = "°¡µå"
-		}
-		shieldFilePath := "data/sprite/¹æÆÐ/" + jobFileName + "/" + jobFileName + "_" + genderPath + "_" + conf.ShieldSpriteName
-		cmp.Files[character.AttachmentShield], err = f.GetSpriteFiles(shieldFilePath)
-		if err != nil {
-			return cmp, errors.Wrapf(err, "could not load shield act and spr files (%v, %s, %s)", conf.Gender, conf.JobSpriteID, conf.ShieldSpriteName)
+	return sprFile.WithPalette(p), nil
+}
+
+// CharacterPalettePath returns the path of the palette of index dyeing
+// the attachment elem of a character: the clothes dyes of the body and the
+// hair colors of the head.
+func CharacterPalettePath(conf CharacterAttachmentComponentConfig, elem character.AttachmentType, index int) (string, error) {
+	if conf.SpritePath == "" {
+		switch elem {
+		case character.AttachmentBody:
+			return character.BodyPaletteFilePath(conf.Gender, conf.JobSpriteID, index), nil
+		case character.AttachmentHead:
+			return character.HairPaletteFilePath(conf.Gender, conf.HeadIndex, index), nil
 		}
 	}
 
-	return cmp, nil
+	return "", fmt.Errorf("no palette for the %v of the character", elem)
 }
 
 func getDecodedFolder(buf []byte) (string, error) {
-	folderNameBytes, err := charmap.Windows1252.NewDecoder().Bytes(buf)
-	return string(folderNameBytes), err
+	return encoding.DecodeRaw(buf), nil
 }
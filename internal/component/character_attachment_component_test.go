@@ -0,0 +1,128 @@
+package component
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+)
+
+type paletteLoader map[string]*pal.PaletteFile
+
+func (paletteLoader) GetSpriteFilesContext(context.Context, string) (grf.ActionSpriteFilePair, error) {
+	return grf.ActionSpriteFilePair{}, nil
+}
+
+func (l paletteLoader) GetPaletteContext(_ context.Context, path string) (*pal.PaletteFile, error) {
+	p, ok := l[path]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return p, nil
+}
+
+type spriteOnlyLoader struct{}
+
+func (spriteOnlyLoader) GetSpriteFilesContext(context.Context, string) (grf.ActionSpriteFilePair, error) {
+	return grf.ActionSpriteFilePair{}, nil
+}
+
+func TestCharacterPalettePath(t *testing.T) {
+	conf := CharacterAttachmentComponentConfig{Gender: character.Male, JobSpriteID: jobspriteid.Novice, HeadIndex: 2}
+
+	path, err := CharacterPalettePath(conf, character.AttachmentBody, 3)
+	require.NoError(t, err)
+	assert.Equal(t, character.BodyPaletteFilePath(character.Male, jobspriteid.Novice, 3), path)
+
+	path, err = CharacterPalettePath(conf, character.AttachmentHead, 1)
+	require.NoError(t, err)
+	assert.Equal(t, character.HairPaletteFilePath(character.Male, 2, 1), path)
+
+	_, err = CharacterPalettePath(conf, character.AttachmentShield, 1)
+	assert.Error(t, err)
+
+	conf.SpritePath = "npc/4_m_mocass1"
+	_, err = CharacterPalettePath(conf, character.AttachmentBody, 1)
+	assert.Error(t, err)
+}
+
+func TestDye(t *testing.T) {
+	conf := CharacterAttachmentComponentConfig{
+		Gender:      character.Female,
+		JobSpriteID: jobspriteid.Novice,
+		Palettes:    map[character.AttachmentType]int{character.AttachmentBody: 1},
+	}
+	path := character.BodyPaletteFilePath(character.Female, jobspriteid.Novice, 1)
+	p := &pal.PaletteFile{}
+	p.Palette[4] = 200
+
+	sprFile := &spr.SpriteFile{}
+	dyed, err := dye(context.Background(), paletteLoader{path: p}, conf, character.AttachmentBody, sprFile)
+	require.NoError(t, err)
+	assert.Equal(t, p.Palette, dyed.Palette)
+	assert.Zero(t, sprFile.Palette)
+
+	_, err = dye(context.Background(), paletteLoader{}, conf, character.AttachmentBody, sprFile)
+	assert.Error(t, err)
+	_, err = dye(context.Background(), spriteOnlyLoader{}, conf, character.AttachmentBody, sprFile)
+	assert.Error(t, err)
+}
+
+func TestCharacterAttachmentPathsHeadgears(t *testing.T) {
+	defer func(table map[int]string) { character.HeadgearNameTable = table }(character.HeadgearNameTable)
+	character.HeadgearNameTable = map[int]string{5: "_goggle"}
+
+	conf := CharacterAttachmentComponentConfig{Gender: character.Female, JobSpriteID: jobspriteid.Novice, MidHeadgear: 5}
+	paths, err := CharacterAttachmentPaths(conf)
+	require.NoError(t, err)
+	assert.Equal(t, character.HeadgearFilePath(character.Female, "_goggle"), paths[character.AttachmentHeadgearMid])
+	assert.NotContains(t, paths, character.AttachmentHeadgearTop)
+
+	conf.TopHeadgear = 6
+	_, err = CharacterAttachmentPaths(conf)
+	assert.Error(t, err)
+}
+
+// sprites loads the sprites of its paths.
+type sprites map[string]bool
+
+func (s sprites) GetSpriteFilesContext(_ context.Context, name string) (grf.ActionSpriteFilePair, error) {
+	if !s[name] {
+		return grf.ActionSpriteFilePair{}, assert.AnError
+	}
+	return grf.ActionSpriteFilePair{ACT: &act.ActionFile{}, SPR: &spr.SpriteFile{}}, nil
+}
+
+func TestCharacterAttachmentWeapon(t *testing.T) {
+	conf := CharacterAttachmentComponentConfig{Gender: character.Male, JobSpriteID: jobspriteid.Swordsman, Weapon: weapontype.Sword}
+	paths, err := CharacterAttachmentPaths(conf)
+	require.NoError(t, err)
+	weapon, err := character.WeaponFilePath(character.Male, jobspriteid.Swordsman, weapontype.Sword)
+	require.NoError(t, err)
+	assert.Equal(t, weapon, paths[character.AttachmentWeapon])
+	assert.Equal(t, weapon+character.WeaponSlashSuffix, paths[character.AttachmentWeaponSlash])
+
+	// A weapon without slash is loaded without it.
+	loader := sprites{}
+	for elem, path := range paths {
+		loader[path] = elem != character.AttachmentWeaponSlash
+	}
+	cmp, err := NewCharacterAttachmentComponent(context.Background(), loader, conf)
+	require.NoError(t, err)
+	assert.Contains(t, cmp.Files, character.AttachmentWeapon)
+	assert.NotContains(t, cmp.Files, character.AttachmentWeaponSlash)
+
+	// Without its weapon, it fails.
+	loader[weapon] = false
+	_, err = NewCharacterAttachmentComponent(context.Background(), loader, conf)
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package component
 
 import (
+	"image"
+
 	"github.com/project-midgard/midgarts/internal/character/actionindex"
 	"github.com/project-midgard/midgarts/internal/character/directiontype"
 	"time"
@@ -19,6 +21,20 @@ type CharacterSpriteRenderInfoComponent struct {
 	ForcedDuration     time.Duration
 	FPSMultiplier      float64
 	IsStandingBy       bool
+
+	// AnimationElapsed is the time played of the animation, scaled by
+	// TimeScale.
+	AnimationElapsed time.Duration
+	// TimeScale is the speed of the animation and movement of the
+	// character, e.g. 0.5 when slowed by a status effect.
+	TimeScale float64
+
+	// Events holds the callbacks of the animation, see entity.Character.OnFrame.
+	Events AnimationEvents
+
+	// Hitbox holds the bounds of the visible pixels of the last rendered
+	// frame, in sprite pixels relative to the character position.
+	Hitbox image.Rectangle
 }
 
 func NewCharacterSpriteRenderInfoComponent() *CharacterSpriteRenderInfoComponent {
@@ -31,5 +47,6 @@ func NewCharacterSpriteRenderInfoComponent() *CharacterSpriteRenderInfoComponent
 		Direction:          directiontype.South,
 		ForcedDuration:     0,
 		FPSMultiplier:      1.0,
+		TimeScale:          1.0,
 	}
 }
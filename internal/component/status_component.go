@@ -0,0 +1,75 @@
+package component
+
+import (
+	"time"
+
+	"github.com/project-midgard/midgarts/internal/character/statustype"
+)
+
+type StatusComponentFace interface {
+	GetStatusComponent() *StatusComponent
+}
+
+// StatusComponent holds the status effects of a character, in the order
+// they were added.
+type StatusComponent struct {
+	Statuses []Status
+}
+
+// Status is a status effect, with the time it has left, 0 for the ones
+// lasting until removed.
+type Status struct {
+	Type statustype.Type
+	Left time.Duration
+}
+
+// AddStatus adds the status effect t for d, 0 until removed, replacing
+// the time left of t when it is already active.
+func (c *StatusComponent) AddStatus(t statustype.Type, d time.Duration) {
+	for i := range c.Statuses {
+		if c.Statuses[i].Type == t {
+			c.Statuses[i].Left = d
+			return
+		}
+	}
+
+	c.Statuses = append(c.Statuses, Status{Type: t, Left: d})
+}
+
+// RemoveStatus removes the status effect t, telling whether it was active.
+func (c *StatusComponent) RemoveStatus(t statustype.Type) bool {
+	for i, s := range c.Statuses {
+		if s.Type == t {
+			c.Statuses = append(c.Statuses[:i], c.Statuses[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasStatus tells whether the status effect t is active.
+func (c *StatusComponent) HasStatus(t statustype.Type) bool {
+	for _, s := range c.Statuses {
+		if s.Type == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExpireStatuses counts d down from the time left of the status effects,
+// removing the ones whose time is up.
+func (c *StatusComponent) ExpireStatuses(d time.Duration) {
+	kept := c.Statuses[:0]
+	for _, s := range c.Statuses {
+		if s.Left > 0 {
+			if s.Left -= d; s.Left <= 0 {
+				continue
+			}
+		}
+		kept = append(kept, s)
+	}
+	c.Statuses = kept
+}
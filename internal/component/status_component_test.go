@@ -0,0 +1,28 @@
+package component
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character/statustype"
+)
+
+func TestStatusComponent(t *testing.T) {
+	var c StatusComponent
+
+	c.AddStatus(statustype.Poison, 2*time.Second)
+	c.AddStatus(statustype.Sleep, 0)
+	c.AddStatus(statustype.Poison, 3*time.Second)
+	assert.Equal(t, []Status{{statustype.Poison, 3 * time.Second}, {statustype.Sleep, 0}}, c.Statuses)
+
+	c.ExpireStatuses(3 * time.Second)
+	assert.False(t, c.HasStatus(statustype.Poison))
+	// Until removed.
+	assert.True(t, c.HasStatus(statustype.Sleep))
+
+	assert.True(t, c.RemoveStatus(statustype.Sleep))
+	assert.False(t, c.RemoveStatus(statustype.Sleep))
+	assert.Empty(t, c.Statuses)
+}
@@ -0,0 +1,283 @@
+// Package console is the developer console of the client: a drop-down
+// line editor, with the history of its lines, running the commands of a
+// registry, e.g.
+//
+//	> spawn assassin f
+//	> time 21
+//	> map prontera
+//
+// The commands are registered by the client and its scenes; help lists
+// them and clear empties the output.
+package console
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxLines is the number of output lines kept.
+	MaxLines = 200
+	// MaxHistory is the number of submitted lines kept.
+	MaxHistory = 50
+)
+
+// Command is a command of the console.
+type Command struct {
+	Name string
+	// Usage shows the arguments of the command, e.g. "<job> [m|f]".
+	Usage string
+	Help  string
+	// Run runs the command with its arguments, returning its output.
+	Run func(args []string) (string, error)
+}
+
+// Console edits a line and runs it as a command. It must be used from a
+// single goroutine.
+type Console struct {
+	open     bool
+	input    []rune
+	commands map[string]Command
+	// lines are the output, the last one the most recent.
+	lines []string
+
+	history []string
+	// browsing is the index in history of the line shown, len(history)
+	// when editing a new one.
+	browsing int
+	// editing is the new line while the history is browsed.
+	editing []rune
+
+	version uint64
+}
+
+// New returns a closed console with the help and clear commands.
+func New() *Console {
+	c := &Console{commands: map[string]Command{}}
+
+	c.Register(Command{
+		Name:  "help",
+		Usage: "[command]",
+		Help:  "lists the commands, or shows the help of one",
+		Run:   c.help,
+	})
+	c.Register(Command{
+		Name: "clear",
+		Help: "empties the output",
+		Run: func([]string) (string, error) {
+			c.lines = nil
+			return "", nil
+		},
+	})
+
+	return c
+}
+
+// Register adds cmd, replacing the command of the same name, if any, and
+// returns the func removing it.
+func (c *Console) Register(cmd Command) (unregister func()) {
+	c.commands[cmd.Name] = cmd
+
+	return func() {
+		delete(c.commands, cmd.Name)
+	}
+}
+
+// Commands returns the names of the commands, sorted.
+func (c *Console) Commands() []string {
+	names := make([]string, 0, len(c.commands))
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// IsOpen tells whether the console is shown, taking the keyboard.
+func (c *Console) IsOpen() bool {
+	return c.open
+}
+
+// Toggle shows or hides the console.
+func (c *Console) Toggle() {
+	c.open = !c.open
+	c.changed()
+}
+
+// Input returns the line being edited.
+func (c *Console) Input() string {
+	return string(c.input)
+}
+
+// Lines returns the output, the last line the most recent.
+func (c *Console) Lines() []string {
+	return c.lines
+}
+
+// Version changes every time the console does, so its image is only drawn
+// again when it changed.
+func (c *Console) Version() uint64 {
+	return c.version
+}
+
+// Type appends text to the line. The backquote toggling the console and
+// the control characters are ignored.
+func (c *Console) Type(text string) {
+	for _, r := range text {
+		if r == '`' || r < ' ' {
+			continue
+		}
+		c.input = append(c.input, r)
+	}
+	c.changed()
+}
+
+// Backspace erases the last character of the line.
+func (c *Console) Backspace() {
+	if n := len(c.input); n > 0 {
+		c.input = c.input[:n-1]
+		c.changed()
+	}
+}
+
+// Previous shows the line submitted before the one shown.
+func (c *Console) Previous() {
+	if c.browsing == 0 {
+		return
+	}
+	if c.browsing == len(c.history) {
+		c.editing = c.input
+	}
+
+	c.browsing--
+	c.input = []rune(c.history[c.browsing])
+	c.changed()
+}
+
+// Next shows the line submitted after the one shown, or the new line.
+func (c *Console) Next() {
+	if c.browsing == len(c.history) {
+		return
+	}
+
+	c.browsing++
+	if c.browsing == len(c.history) {
+		c.input = c.editing
+	} else {
+		c.input = []rune(c.history[c.browsing])
+	}
+	c.changed()
+}
+
+// Complete completes the name of the command being typed, when a single
+// command starts with it, and lists the commands starting with it
+// otherwise.
+func (c *Console) Complete() {
+	prefix := string(c.input)
+	if strings.ContainsRune(prefix, ' ') {
+		return
+	}
+
+	var matches []string
+	for _, name := range c.Commands() {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+	case 1:
+		c.input = []rune(matches[0] + " ")
+		c.changed()
+	default:
+		c.Printf("%s", strings.Join(matches, "  "))
+	}
+}
+
+// Submit runs the line, and adds it to the history.
+func (c *Console) Submit() {
+	line := strings.TrimSpace(string(c.input))
+	c.input, c.editing = nil, nil
+	if line != "" && (len(c.history) == 0 || c.history[len(c.history)-1] != line) {
+		c.history = append(c.history, line)
+		if len(c.history) > MaxHistory {
+			c.history = c.history[len(c.history)-MaxHistory:]
+		}
+	}
+	c.browsing = len(c.history)
+
+	c.Run(line)
+}
+
+// Run runs a line, its first word the name of the command and the next
+// ones its arguments, printing the line and the output of the command.
+func (c *Console) Run(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		c.changed()
+		return
+	}
+
+	c.Printf("> %s", line)
+	output, err := c.run(fields[0], fields[1:])
+	if err != nil {
+		c.Printf("error: %v", err)
+		return
+	}
+	if output != "" {
+		c.Printf("%s", output)
+	}
+}
+
+func (c *Console) run(name string, args []string) (string, error) {
+	cmd, ok := c.commands[name]
+	if !ok {
+		return "", errors.Errorf("unknown command '%s', see help", name)
+	}
+
+	return cmd.Run(args)
+}
+
+// Printf appends a line to the output, split at its line breaks.
+func (c *Console) Printf(format string, args ...interface{}) {
+	c.lines = append(c.lines, strings.Split(fmt.Sprintf(format, args...), "\n")...)
+	if len(c.lines) > MaxLines {
+		c.lines = c.lines[len(c.lines)-MaxLines:]
+	}
+	c.changed()
+}
+
+func (c *Console) help(args []string) (string, error) {
+	if len(args) > 0 {
+		cmd, ok := c.commands[args[0]]
+		if !ok {
+			return "", errors.Errorf("unknown command '%s'", args[0])
+		}
+		return fmt.Sprintf("%s: %s", usage(cmd), cmd.Help), nil
+	}
+
+	lines := make([]string, 0, len(c.commands))
+	for _, name := range c.Commands() {
+		cmd := c.commands[name]
+		lines = append(lines, fmt.Sprintf("%-24s %s", usage(cmd), cmd.Help))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func usage(cmd Command) string {
+	if cmd.Usage == "" {
+		return cmd.Name
+	}
+
+	return cmd.Name + " " + cmd.Usage
+}
+
+func (c *Console) changed() {
+	c.version++
+}
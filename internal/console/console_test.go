@@ -0,0 +1,122 @@
+package console
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConsole() (*Console, *[]string) {
+	c := New()
+	var spawned []string
+	c.Register(Command{
+		Name:  "spawn",
+		Usage: "<job>",
+		Help:  "spawns a character",
+		Run: func(args []string) (string, error) {
+			if len(args) != 1 {
+				return "", errors.New("expected a job")
+			}
+			spawned = append(spawned, args[0])
+			return "spawned " + args[0], nil
+		},
+	})
+	c.Register(Command{Name: "stats", Help: "shows the metrics", Run: func([]string) (string, error) { return "", nil }})
+
+	return c, &spawned
+}
+
+func TestConsoleSubmit(t *testing.T) {
+	c, spawned := newTestConsole()
+
+	c.Toggle()
+	assert.True(t, c.IsOpen())
+
+	// The backquote opening the console is not typed.
+	c.Type("`spawn  knigth")
+	c.Backspace()
+	c.Backspace()
+	c.Type("ht")
+	assert.Equal(t, "spawn  knight", c.Input())
+
+	c.Submit()
+	assert.Equal(t, []string{"knight"}, *spawned)
+	assert.Equal(t, "", c.Input())
+
+	c.Run("spawn")
+	c.Run("dance")
+	assert.Equal(t, []string{
+		"> spawn  knight",
+		"spawned knight",
+		"> spawn",
+		"error: expected a job",
+		"> dance",
+		"error: unknown command 'dance', see help",
+	}, c.Lines())
+
+	c.Run("clear")
+	assert.Empty(t, c.Lines())
+}
+
+func TestConsoleHistory(t *testing.T) {
+	c, _ := newTestConsole()
+
+	for _, line := range []string{"spawn a", "spawn b", "spawn b"} {
+		c.Type(line)
+		c.Submit()
+	}
+	c.Type("spa")
+
+	c.Previous()
+	assert.Equal(t, "spawn b", c.Input())
+	c.Previous()
+	assert.Equal(t, "spawn a", c.Input())
+	c.Previous()
+	assert.Equal(t, "spawn a", c.Input())
+
+	c.Next()
+	c.Next()
+	assert.Equal(t, "spa", c.Input())
+}
+
+func TestConsoleComplete(t *testing.T) {
+	c, _ := newTestConsole()
+
+	c.Type("sp")
+	c.Complete()
+	assert.Equal(t, "spawn ", c.Input())
+
+	c.Submit()
+	c.Type("s")
+	c.Complete()
+	assert.Equal(t, "s", c.Input())
+	assert.Equal(t, "spawn  stats", c.Lines()[len(c.Lines())-1])
+}
+
+func TestConsoleHelp(t *testing.T) {
+	c, _ := newTestConsole()
+	unregister := c.Register(Command{Name: "quit", Run: func([]string) (string, error) { return "", nil }})
+	unregister()
+
+	assert.Equal(t, []string{"clear", "help", "spawn", "stats"}, c.Commands())
+
+	c.Run("help spawn")
+	assert.Equal(t, "spawn <job>: spawns a character", c.Lines()[1])
+
+	c.Run("help")
+	assert.True(t, strings.HasPrefix(c.Lines()[3], "clear "), c.Lines()[3])
+	assert.Len(t, c.Lines(), 7)
+}
+
+func TestConsoleVersion(t *testing.T) {
+	c := New()
+	v := c.Version()
+
+	c.Backspace()
+	assert.Equal(t, v, c.Version())
+
+	c.Type("a")
+	assert.NotEqual(t, v, c.Version())
+}
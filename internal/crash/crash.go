@@ -0,0 +1,198 @@
+// Package crash turns the panics of the client into crash reports: the
+// panic, its stack trace, the context added on the way up (e.g. the entity
+// being rendered, see Annotate) and the last events of the bus, written to
+// a file and optionally uploaded.
+package crash
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/pkg/version"
+)
+
+// DefaultRecentEvents is the number of events kept for the reports.
+const DefaultRecentEvents = 50
+
+// Field is a piece of context of a panic.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// annotated is a panic value carrying context, see Annotate.
+type annotated struct {
+	value   interface{}
+	context []Field
+}
+
+// Annotate, deferred, adds key and value to the context of a panic going
+// through the calling function:
+//
+//	defer crash.Annotate("map", mapName)
+func Annotate(key string, value interface{}) {
+	if p := recover(); p != nil {
+		annotate(p, key, value)
+	}
+}
+
+// AnnotateUint is like Annotate, for an integer such as an entity ID.
+// Unlike Annotate, it boxes the value only on panic, it does not allocate
+// on the paths run every frame.
+func AnnotateUint(key string, value uint64) {
+	if p := recover(); p != nil {
+		annotate(p, key, value)
+	}
+}
+
+// annotate panics again with p, annotated with key and value.
+func annotate(p interface{}, key string, value interface{}) {
+	a, ok := p.(*annotated)
+	if !ok {
+		a = &annotated{value: p}
+	}
+	a.context = append(a.context, Field{Key: key, Value: value})
+
+	panic(a)
+}
+
+type Report struct {
+	Time    time.Time
+	Version string
+	Panic   interface{}
+	// Context is ordered from the innermost Annotate.
+	Context []Field
+	Events  []interface{}
+	Stack   []byte
+	// Path is the file the report was written to, empty if it could not
+	// be written.
+	Path string
+}
+
+// Bytes returns the report as text.
+func (r *Report) Bytes() []byte {
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "Midgarts crash report\n\n")
+	fmt.Fprintf(buf, "Time: %s\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(buf, "Version: %s\n", r.Version)
+	fmt.Fprintf(buf, "Panic: %v\n", r.Panic)
+
+	if len(r.Context) > 0 {
+		fmt.Fprintf(buf, "\nContext:\n")
+		for _, f := range r.Context {
+			fmt.Fprintf(buf, "  %s: %v\n", f.Key, f.Value)
+		}
+	}
+
+	if len(r.Events) > 0 {
+		fmt.Fprintf(buf, "\nRecent events, oldest first:\n")
+		for _, e := range r.Events {
+			fmt.Fprintf(buf, "  %T%+v\n", e, e)
+		}
+	}
+
+	fmt.Fprintf(buf, "\nStack:\n%s", r.Stack)
+
+	return buf.Bytes()
+}
+
+// Reporter writes the reports of the panics it recovers.
+type Reporter struct {
+	// Dir is the folder of the reports, the working directory when empty.
+	Dir string
+	// Upload, when set, is called with the path of every report written.
+	Upload func(path string, report *Report) error
+
+	mu     sync.Mutex
+	events []interface{}
+	next   int
+}
+
+func NewReporter(dir string) *Reporter {
+	return &Reporter{Dir: dir, events: make([]interface{}, 0, DefaultRecentEvents)}
+}
+
+// Watch keeps the last events of bus for the reports.
+func (r *Reporter) Watch(bus *event.Bus) (unsubscribe func()) {
+	return bus.SubscribeAll(func(e interface{}) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if len(r.events) < cap(r.events) {
+			r.events = append(r.events, e)
+			return
+		}
+
+		r.events[r.next] = e
+		r.next = (r.next + 1) % len(r.events)
+	})
+}
+
+func (r *Reporter) recentEvents() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]interface{}, 0, len(r.events))
+	events = append(events, r.events[r.next:]...)
+	return append(events, r.events[:r.next]...)
+}
+
+// Capture calls fn and, if it panics, writes the report of the panic and
+// returns it.
+func (r *Reporter) Capture(fn func()) (report *Report) {
+	defer func() {
+		if p := recover(); p != nil {
+			report = r.report(p)
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+// Recover, deferred, writes the report of a panic, then panics again.
+func (r *Reporter) Recover() {
+	if p := recover(); p != nil {
+		report := r.report(p)
+		panic(report.Panic)
+	}
+}
+
+func (r *Reporter) report(p interface{}) *Report {
+	report := &Report{
+		Time:    time.Now(),
+		Version: version.Get(),
+		Panic:   p,
+		Events:  r.recentEvents(),
+		Stack:   debug.Stack(),
+	}
+
+	if a, ok := p.(*annotated); ok {
+		report.Panic, report.Context = a.value, a.context
+	}
+
+	path := filepath.Join(r.Dir, fmt.Sprintf("crash-%d.txt", report.Time.Unix()))
+	if err := ioutil.WriteFile(path, report.Bytes(), 0644); err != nil {
+		log.Error().Err(err).Msg("could not write crash report")
+		return report
+	}
+	report.Path = path
+	log.Error().Str("path", path).Interface("panic", report.Panic).Msg("crash report written")
+
+	if r.Upload != nil {
+		if err := r.Upload(path, report); err != nil {
+			log.Error().Err(err).Str("path", path).Msg("could not upload crash report")
+		}
+	}
+
+	return report
+}
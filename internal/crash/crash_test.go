@@ -0,0 +1,93 @@
+package crash
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/event"
+)
+
+func renderEntity(id uint64) {
+	defer Annotate("entity", id)
+
+	var chars map[uint64]string
+	chars[id] = "poring"
+}
+
+func TestCapture(t *testing.T) {
+	r := NewReporter(t.TempDir())
+
+	var uploaded string
+	r.Upload = func(path string, report *Report) error {
+		uploaded = path
+		return nil
+	}
+
+	bus := event.NewBus()
+	r.Watch(bus)
+	for i := 0; i < DefaultRecentEvents+2; i++ {
+		bus.Publish(event.MouseClicked{X: i})
+	}
+
+	assert.Nil(t, r.Capture(func() {}))
+
+	report := r.Capture(func() {
+		renderEntity(12)
+	})
+	require.NotNil(t, report)
+
+	assert.EqualError(t, report.Panic.(error), "assignment to entry in nil map")
+	assert.Equal(t, []Field{{Key: "entity", Value: uint64(12)}}, report.Context)
+	require.Len(t, report.Events, DefaultRecentEvents)
+	assert.Equal(t, event.MouseClicked{X: 2}, report.Events[0])
+	assert.Equal(t, event.MouseClicked{X: DefaultRecentEvents + 1}, report.Events[DefaultRecentEvents-1])
+	assert.Contains(t, string(report.Stack), "renderEntity")
+
+	require.NotEmpty(t, uploaded)
+	assert.Equal(t, r.Dir, filepath.Dir(uploaded))
+	data, err := ioutil.ReadFile(uploaded)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Panic: assignment to entry in nil map")
+	assert.Contains(t, string(data), "  entity: 12\n")
+	assert.Contains(t, string(data), "event.MouseClicked{X:2 Y:0}")
+}
+
+func TestRecover(t *testing.T) {
+	r := NewReporter(t.TempDir())
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer r.Recover()
+		panic("boom")
+	})
+
+	files, err := ioutil.ReadDir(r.Dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func renderEntityUint(id uint64, fail bool) {
+	defer AnnotateUint("entity", id)
+
+	if fail {
+		panic("boom")
+	}
+}
+
+func TestAnnotateUint(t *testing.T) {
+	r := NewReporter(t.TempDir())
+
+	report := r.Capture(func() {
+		renderEntityUint(1000, true)
+	})
+	require.NotNil(t, report)
+	assert.Equal(t, []Field{{Key: "entity", Value: uint64(1000)}}, report.Context)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		renderEntityUint(1000, false)
+	})
+	assert.Zero(t, allocs)
+}
@@ -0,0 +1,91 @@
+// Package debugserver exposes the runtime profiling endpoints of a running
+// program, to investigate render loop performance without instrumenting it.
+package debugserver
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime/trace"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/logging"
+)
+
+// Server serves the net/http/pprof endpoints under /debug/pprof/, including
+// the runtime trace capture (/debug/pprof/trace?seconds=N), and the expvar
+// variables, e.g. the metrics of the client, under /debug/vars.
+type Server struct {
+	Addr string
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewMux returns a mux with the pprof and expvar handlers registered,
+// without touching http.DefaultServeMux.
+func NewMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return mux
+}
+
+// Start listens on addr (e.g. "localhost:6060") and serves the endpoints
+// in the background. It logs to the logger of ctx (see logging.For).
+func Start(ctx context.Context, addr string) (*Server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not listen for the debug server")
+	}
+
+	s := &Server{
+		Addr:     l.Addr().String(),
+		listener: l,
+		server:   &http.Server{Handler: NewMux()},
+	}
+
+	log := logging.For(ctx, logging.DebugServer)
+
+	go func() {
+		if err := s.server.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Error().Err(err).Msg("debug server stopped")
+		}
+	}()
+
+	log.Info().Msgf("pprof endpoints available at http://%s/debug/pprof/, metrics at http://%[1]s/debug/vars", s.Addr)
+
+	return s, nil
+}
+
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// StartTrace captures a runtime trace to path until the returned stop
+// function is called. Inspect it with `go tool trace`.
+func StartTrace(path string) (stop func() error, err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = trace.Start(f); err != nil {
+		_ = f.Close()
+		return nil, errors.Wrap(err, "could not start trace")
+	}
+
+	return func() error {
+		trace.Stop()
+		return f.Close()
+	}, nil
+}
@@ -0,0 +1,226 @@
+// Package effect maps the effect IDs the servers send, the EF_* table of
+// the official client, to their implementations: an STR animation, a
+// sprite, a particle preset or an effect written in Go, with the sound
+// played along. The zone packets showing an
+// effect (see packet.ZC_NOTIFY_EFFECT2) and the scripts play the effects
+// through a Registry, on the Player of the frontend.
+//
+// The default table (see DefaultEffects) holds the first effects of the
+// official table, their STR files named after them; communities replace
+// or extend it with a YAML table of the effects by ID (see LoadTable):
+//
+//	25: {name: firewall, str: firewall.str}
+//	45: {name: firefly, particles: firefly}
+package effect
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ID is the number of an effect in the EF_* table.
+type ID uint32
+
+// Target is where an effect is played: on an entity, or at a world
+// position when Entity is 0.
+type Target struct {
+	Entity   uint64
+	Position mgl32.Vec3
+}
+
+// Player plays the effects on a frontend.
+type Player interface {
+	// PlaySTR plays the STR animation of a file of STRDir.
+	PlaySTR(file string, target Target) error
+	// PlaySprite plays the animation of the sprite of SpriteDir named
+	// name, once.
+	PlaySprite(name string, target Target) error
+	// PlayParticles plays a particle preset.
+	PlayParticles(preset string, target Target) error
+	// PlaySound plays the sound of a file of SoundDir.
+	PlaySound(file string, target Target) error
+}
+
+// Func is an effect written in Go, played with the STR animations and
+// particle presets of p.
+type Func func(p Player, target Target) error
+
+const (
+	// STRDir is the folder of the STR files.
+	STRDir = "data/texture/effect/"
+	// SpriteDir is the folder of the sprites of the effects.
+	//
+	// data/sprite/이팩트/
+	SpriteDir = "data/sprite/ÀÌÆÑÆ®/"
+	// SoundDir is the folder of the sounds.
+	SoundDir = "data/wav/"
+)
+
+// Effect is an effect of the table, with a single implementation.
+type Effect struct {
+	ID ID `yaml:"-"`
+	// Name is the name of the effect, its EF_* name in lower case
+	// without the prefix, as the scripts name it.
+	Name      string `yaml:"name"`
+	STR       string `yaml:"str"`
+	Sprite    string `yaml:"sprite"`
+	Particles string `yaml:"particles"`
+	Func      Func   `yaml:"-"`
+	// Sound is played with the effect, if set.
+	Sound string `yaml:"sound"`
+}
+
+// Play plays e on p at target.
+func (e Effect) Play(p Player, target Target) error {
+	if e.Sound != "" {
+		if err := p.PlaySound(e.Sound, target); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case e.Func != nil:
+		return e.Func(p, target)
+	case e.STR != "":
+		return p.PlaySTR(e.STR, target)
+	case e.Sprite != "":
+		return p.PlaySprite(e.Sprite, target)
+	case e.Particles != "":
+		return p.PlayParticles(e.Particles, target)
+	}
+
+	return errors.Errorf("effect '%s' has no implementation", e.Name)
+}
+
+func (e Effect) validate() error {
+	if e.Name == "" {
+		return errors.Errorf("effect %d has no name", e.ID)
+	}
+
+	var implementations int
+	for _, set := range []bool{e.STR != "", e.Sprite != "", e.Particles != "", e.Func != nil} {
+		if set {
+			implementations++
+		}
+	}
+	if implementations != 1 {
+		return errors.Errorf("effect '%s' has %d implementations, expected 1", e.Name, implementations)
+	}
+
+	return nil
+}
+
+// Registry holds the effects by ID and by name.
+type Registry struct {
+	byID   map[ID]Effect
+	byName map[string]ID
+}
+
+// NewRegistry returns a registry of effects (see Register).
+func NewRegistry(effects ...Effect) (*Registry, error) {
+	r := &Registry{byID: map[ID]Effect{}, byName: map[string]ID{}}
+	for _, e := range effects {
+		if err := r.Register(e); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// NewDefaultRegistry returns the registry of DefaultEffects.
+func NewDefaultRegistry() *Registry {
+	r, err := NewRegistry(DefaultEffects...)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// Register adds e, replacing the effect of its ID, if any, so a table
+// can override the default one. Its name, in lower case, must not be the
+// one of another ID.
+func (r *Registry) Register(e Effect) error {
+	e.Name = strings.ToLower(e.Name)
+	if err := e.validate(); err != nil {
+		return err
+	}
+	if id, ok := r.byName[e.Name]; ok && id != e.ID {
+		return errors.Errorf("effect '%s' registered as %d and %d", e.Name, id, e.ID)
+	}
+
+	if previous, ok := r.byID[e.ID]; ok {
+		delete(r.byName, previous.Name)
+	}
+	r.byID[e.ID] = e
+	r.byName[e.Name] = e.ID
+
+	return nil
+}
+
+// Effect returns the effect of id.
+func (r *Registry) Effect(id ID) (Effect, bool) {
+	e, ok := r.byID[id]
+	return e, ok
+}
+
+// Named returns the effect of name, ignoring case.
+func (r *Registry) Named(name string) (Effect, bool) {
+	id, ok := r.byName[strings.ToLower(name)]
+	if !ok {
+		return Effect{}, false
+	}
+
+	return r.byID[id], true
+}
+
+// Play plays the effect of id on p at target.
+func (r *Registry) Play(p Player, id ID, target Target) error {
+	e, ok := r.Effect(id)
+	if !ok {
+		return errors.Errorf("unknown effect %d", id)
+	}
+
+	return e.Play(p, target)
+}
+
+// PlayNamed plays the effect of name, ignoring case, on p at target.
+func (r *Registry) PlayNamed(p Player, name string, target Target) error {
+	e, ok := r.Named(name)
+	if !ok {
+		return errors.Errorf("unknown effect '%s'", name)
+	}
+
+	return e.Play(p, target)
+}
+
+// LoadTable registers the effects of a YAML table of the effects by ID in
+// r.
+func (r *Registry) LoadTable(data []byte) error {
+	var effects map[ID]Effect
+	if err := yaml.Unmarshal(data, &effects); err != nil {
+		return errors.Wrap(err, "could not decode effect table")
+	}
+
+	ids := make([]ID, 0, len(effects))
+	for id := range effects {
+		ids = append(ids, id)
+	}
+	// In order, so the errors are the same every time.
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		e := effects[id]
+		e.ID = id
+		if err := r.Register(e); err != nil {
+			return errors.Wrap(err, "invalid effect table")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package effect
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlayer records the effects played.
+type fakePlayer struct {
+	played []string
+}
+
+func (p *fakePlayer) PlaySTR(file string, target Target) error {
+	p.played = append(p.played, "str "+file)
+	return nil
+}
+
+func (p *fakePlayer) PlaySprite(name string, target Target) error {
+	p.played = append(p.played, "sprite "+name)
+	return nil
+}
+
+func (p *fakePlayer) PlayParticles(preset string, target Target) error {
+	p.played = append(p.played, "particles "+preset)
+	return nil
+}
+
+func (p *fakePlayer) PlaySound(file string, target Target) error {
+	p.played = append(p.played, "sound "+file)
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewDefaultRegistry()
+	p := &fakePlayer{}
+
+	require.NoError(t, r.Play(p, 25, Target{Position: mgl32.Vec3{1, 2, 3}}))
+	require.NoError(t, r.Play(p, 45, Target{Entity: 1}))
+	assert.Equal(t, []string{"str firewall.str", "particles firefly"}, p.played)
+	assert.Error(t, r.Play(p, 100000, Target{}))
+	require.NoError(t, r.PlayNamed(p, "Warp", Target{}))
+	assert.Equal(t, "str warp.str", p.played[2])
+	assert.Error(t, r.PlayNamed(p, "unknown", Target{}))
+
+	e, ok := r.Named("FireWall")
+	assert.True(t, ok)
+	assert.Equal(t, ID(25), e.ID)
+
+	// A Go effect, replacing the default one.
+	require.NoError(t, r.Register(Effect{ID: 25, Name: "firewall", Func: func(p Player, target Target) error {
+		_ = p.PlayParticles("fire", target)
+		return p.PlaySTR("firewall.str", target)
+	}}))
+	p.played = nil
+	require.NoError(t, r.Play(p, 25, Target{}))
+	assert.Equal(t, []string{"particles fire", "str firewall.str"}, p.played)
+
+	assert.Error(t, r.Register(Effect{ID: 1, Name: "firewall", STR: "a.str"}))
+	assert.Error(t, r.Register(Effect{ID: 1, Name: "both", STR: "a.str", Particles: "b"}))
+	assert.Error(t, r.Register(Effect{ID: 1, Name: "sound", Sound: "a.wav"}))
+	assert.Error(t, r.Register(Effect{ID: 1, Name: "none"}))
+	assert.Error(t, r.Register(Effect{ID: 1, STR: "a.str"}))
+}
+
+func TestLoadTable(t *testing.T) {
+	r := NewDefaultRegistry()
+	require.NoError(t, r.LoadTable([]byte(`
+25: {name: flames, particles: fire}
+1000: {name: custom, str: custom.str}
+`)))
+
+	e, ok := r.Effect(25)
+	assert.True(t, ok)
+	assert.Equal(t, "flames", e.Name)
+	// The name of the replaced effect is free.
+	_, ok = r.Named("firewall")
+	assert.False(t, ok)
+	_, ok = r.Named("custom")
+	assert.True(t, ok)
+
+	assert.Error(t, r.LoadTable([]byte("1: {name: a}")))
+	assert.Error(t, r.LoadTable([]byte("[")))
+}
+
+func TestLevelUpEffects(t *testing.T) {
+	p := &fakePlayer{}
+
+	require.NoError(t, LevelUp.Play(p, Target{Entity: 1}))
+	require.NoError(t, JobLevelUp.Play(p, Target{Entity: 1}))
+	require.NoError(t, JobChange.Play(p, Target{Entity: 1}))
+	assert.Equal(t, []string{
+		"sound levelup.wav", "sprite levelup",
+		"sound levelup.wav", "sprite joblvup",
+		"sound levelup.wav", "sprite levelup", "sprite joblvup",
+	}, p.played)
+
+	for _, e := range []Effect{LevelUp, JobLevelUp, JobChange} {
+		assert.NoError(t, e.validate(), e.Name)
+	}
+}
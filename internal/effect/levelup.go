@@ -0,0 +1,21 @@
+package effect
+
+// The effects of the levels gained, shown by the zone server with
+// packet.ZC_NOTIFY_EFFECT rather than by EF_* number: the aura rising
+// around the character, and the fanfare.
+var (
+	LevelUp    = Effect{Name: "levelup", Sprite: "levelup", Sound: "levelup.wav"}
+	JobLevelUp = Effect{Name: "joblevelup", Sprite: "joblvup", Sound: "levelup.wav"}
+	// JobChange plays both auras, as the official client has no effect of
+	// its own for the jobs changed.
+	JobChange = Effect{
+		Name:  "jobchange",
+		Sound: "levelup.wav",
+		Func: func(p Player, target Target) error {
+			if err := p.PlaySprite(LevelUp.Sprite, target); err != nil {
+				return err
+			}
+			return p.PlaySprite(JobLevelUp.Sprite, target)
+		},
+	}
+)
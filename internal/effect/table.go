@@ -0,0 +1,49 @@
+package effect
+
+// DefaultEffects are the first effects of the EF_* table of the official
+// client, their STR animations named after them.
+var DefaultEffects = []Effect{
+	{ID: 6, Name: "entry", STR: "entry.str"},
+	{ID: 7, Name: "exit", STR: "exit.str"},
+	{ID: 8, Name: "warp", STR: "warp.str"},
+	{ID: 9, Name: "enhance", STR: "enhance.str"},
+	{ID: 10, Name: "coin", STR: "coin.str"},
+	{ID: 11, Name: "endure", STR: "endure.str"},
+	{ID: 12, Name: "beginspell", STR: "beginspell.str"},
+	{ID: 13, Name: "glasswall", STR: "glasswall.str"},
+	{ID: 14, Name: "healsp", STR: "healsp.str"},
+	{ID: 15, Name: "soulstrike", STR: "soulstrike.str"},
+	{ID: 16, Name: "bash", STR: "bash.str"},
+	{ID: 17, Name: "magnumbreak", STR: "magnumbreak.str"},
+	{ID: 18, Name: "steal", STR: "steal.str"},
+	{ID: 19, Name: "hiding", STR: "hiding.str"},
+	{ID: 20, Name: "pattack", STR: "pattack.str"},
+	{ID: 21, Name: "detoxication", STR: "detoxication.str"},
+	{ID: 22, Name: "sight", STR: "sight.str"},
+	{ID: 23, Name: "stonecurse", STR: "stonecurse.str"},
+	{ID: 24, Name: "fireball", STR: "fireball.str"},
+	{ID: 25, Name: "firewall", STR: "firewall.str"},
+	{ID: 26, Name: "icearrow", STR: "icearrow.str"},
+	{ID: 27, Name: "frostdiver", STR: "frostdiver.str"},
+	{ID: 28, Name: "frostdiver2", STR: "frostdiver2.str"},
+	{ID: 29, Name: "lightbolt", STR: "lightbolt.str"},
+	{ID: 30, Name: "thunderstorm", STR: "thunderstorm.str"},
+	{ID: 31, Name: "firearrow", STR: "firearrow.str"},
+	{ID: 32, Name: "napalmbeat", STR: "napalmbeat.str"},
+	{ID: 33, Name: "ruwach", STR: "ruwach.str"},
+	{ID: 34, Name: "teleportation", STR: "teleportation.str"},
+	{ID: 35, Name: "readyportal", STR: "readyportal.str"},
+	{ID: 36, Name: "portal", STR: "portal.str"},
+	{ID: 37, Name: "incagility", STR: "incagility.str"},
+	{ID: 38, Name: "decagility", STR: "decagility.str"},
+	{ID: 39, Name: "aqua", STR: "aqua.str"},
+	{ID: 40, Name: "signum", STR: "signum.str"},
+	{ID: 41, Name: "angelus", STR: "angelus.str"},
+	{ID: 42, Name: "blessing", STR: "blessing.str"},
+	{ID: 43, Name: "incagidex", STR: "incagidex.str"},
+	{ID: 44, Name: "smoke", Particles: "smoke"},
+	{ID: 45, Name: "firefly", Particles: "firefly"},
+	{ID: 46, Name: "sandwind", Particles: "sandwind"},
+	{ID: 47, Name: "torch", Particles: "torch"},
+	{ID: 48, Name: "spraypond", Particles: "spraypond"},
+}
@@ -1,11 +1,18 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+
 	"github.com/project-midgard/midgarts/internal/character"
 	"github.com/project-midgard/midgarts/internal/character/actionplaymode"
+	"github.com/project-midgard/midgarts/internal/character/aspd"
 	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
 	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/character/walkpath"
+	"github.com/project-midgard/midgarts/internal/character/weapontype"
 	"github.com/project-midgard/midgarts/internal/component"
 	"github.com/project-midgard/midgarts/internal/graphic"
 )
@@ -16,14 +23,37 @@ type Character struct {
 	*component.CharacterAttachmentComponent
 	*component.CharacterStateComponent
 	*component.CharacterSpriteRenderInfoComponent
+	*component.StatusComponent
 
-	HeadIndex        character.HeadIndex
-	Gender           character.GenderType
-	JobSpriteID      jobspriteid.Type
-	IsMounted        bool
-	MovementSpeed    float64
+	HeadIndex   character.HeadIndex
+	Gender      character.GenderType
+	JobSpriteID jobspriteid.Type
+	IsMounted   bool
+	// MovementSpeed is the walk speed, relative to the normal one (see
+	// WalkSpeed).
+	MovementSpeed float64
+	// Walk is the path c walks, nil when it is not walking one (see
+	// WalkTo).
+	Walk *walkpath.Walk
+	// Scale is the size of the sprites relative to their pixels, e.g. 2
+	// for a boss or 0.5 for a minimized monster (see SpriteScale).
+	Scale float32
+	// ASPD is the attack speed, timing the attack animations (see
+	// internal/character/aspd).
+	ASPD             float64
 	HasShield        bool
 	ShieldSpriteName string
+	// TopHeadgear, MidHeadgear and LowHeadgear are the view ids of the
+	// headgears worn, 0 for none (see character.HeadgearNameTable).
+	TopHeadgear, MidHeadgear, LowHeadgear int
+	// Weapon is the class of the weapon held, weapontype.None for none.
+	Weapon weapontype.Type
+	// SpritePath is the sprite of the characters that are not players, see
+	// Factory.
+	SpritePath string
+	// Palettes are the dyes of the body and the hair, see
+	// component.CharacterAttachmentComponentConfig.
+	Palettes map[character.AttachmentType]int
 }
 
 func NewCharacter(gender character.GenderType, jobSpriteID jobspriteid.Type, headIndex character.HeadIndex) *Character {
@@ -36,17 +66,37 @@ func NewCharacter(gender character.GenderType, jobSpriteID jobspriteid.Type, hea
 			PreviousState: statetype.StandBy,
 		},
 		CharacterSpriteRenderInfoComponent: component.NewCharacterSpriteRenderInfoComponent(),
+		StatusComponent:                    &component.StatusComponent{},
 		Transform:                          graphic.NewTransform(graphic.Origin),
 		Gender:                             gender,
 		JobSpriteID:                        jobSpriteID,
 		HeadIndex:                          headIndex,
 		IsMounted:                          true,
-		MovementSpeed:                      1.25,
+		MovementSpeed:                      1,
+		Scale:                              1,
+		ASPD:                               aspd.Default,
 	}
 
 	return c
 }
 
+// AttachmentConfig returns the configuration of the attachments of c.
+func (c *Character) AttachmentConfig() component.CharacterAttachmentComponentConfig {
+	return component.CharacterAttachmentComponentConfig{
+		Gender:           c.Gender,
+		JobSpriteID:      c.JobSpriteID,
+		HeadIndex:        c.HeadIndex,
+		EnableShield:     c.HasShield,
+		ShieldSpriteName: c.ShieldSpriteName,
+		TopHeadgear:      c.TopHeadgear,
+		MidHeadgear:      c.MidHeadgear,
+		LowHeadgear:      c.LowHeadgear,
+		Weapon:           c.Weapon,
+		SpritePath:       c.SpritePath,
+		Palettes:         c.Palettes,
+	}
+}
+
 func (c *Character) SetCharacterStateComponent(component *component.CharacterStateComponent) {
 	c.CharacterStateComponent = component
 }
@@ -67,7 +117,53 @@ func (c *Character) GetCharacterSpriteRenderInfoComponent() *component.Character
 	return c.CharacterSpriteRenderInfoComponent
 }
 
+func (c *Character) GetStatusComponent() *component.StatusComponent {
+	return c.StatusComponent
+}
+
+// SpriteScale returns Scale, 1 when unset.
+func (c *Character) SpriteScale() float32 {
+	if c.Scale <= 0 {
+		return 1
+	}
+
+	return c.Scale
+}
+
+// WalkSpeed returns the time c takes to walk a cell. It times both the
+// movement and the walk animation, see character.WalkAnimationMultiplier.
+func (c *Character) WalkSpeed() time.Duration {
+	return character.WalkSpeed(c.MovementSpeed)
+}
+
+// WalkTo starts a walk of c from its position through the world
+// positions of points, a cell per world unit.
+func (c *Character) WalkTo(points ...mgl32.Vec2) {
+	p := c.Position()
+	c.Walk = &walkpath.Walk{Path: walkpath.New(append([]mgl32.Vec2{{p.X(), p.Y()}}, points...)...)}
+}
+
 func (c *Character) SetState(state statetype.Type) {
 	c.PreviousState = c.State
 	c.State = state
 }
+
+// OnFrame calls fn each time the frame n of the animation of the current
+// state of c starts, e.g. to apply a hit at the attack frame. The
+// callbacks of an animation are dropped when the animation of another
+// state starts (see component.AnimationEvents).
+func (c *Character) OnFrame(n int, fn func()) {
+	c.Events.OnFrame(c.State, n, fn)
+}
+
+// OnLoop calls fn each time the animation of the current state of c
+// starts over.
+func (c *Character) OnLoop(fn func()) {
+	c.Events.OnLoop(c.State, fn)
+}
+
+// OnComplete calls fn once the animation of the current state of c played
+// through, e.g. to chain another state.
+func (c *Character) OnComplete(fn func()) {
+	c.Events.OnComplete(c.State, fn)
+}
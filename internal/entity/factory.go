@@ -0,0 +1,160 @@
+package entity
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+)
+
+// Kind is a kind of character created from an id by Factory.
+type Kind int
+
+const (
+	Monster Kind = iota
+	NPC
+	Item
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Monster:
+		return "monster"
+	case NPC:
+		return "npc"
+	case Item:
+		return "item"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// SpriteFolder returns the sprite folder of a kind, in its raw GRF form.
+func (k Kind) SpriteFolder() string {
+	switch k {
+	case Monster:
+		// data/sprite/몬스터
+		return "data/sprite/¸ó½ºÅÍ"
+	case NPC:
+		return "data/sprite/npc"
+	case Item:
+		// data/sprite/아이템
+		return "data/sprite/¾ÆÀÌÅÛ"
+	default:
+		return ""
+	}
+}
+
+// DefaultMonsters are the sprite names of the monsters known without
+// registering them.
+var DefaultMonsters = map[int]string{
+	1002: "poring",
+	1007: "fabre",
+	1031: "poporing",
+	1063: "lunatic",
+	1113: "drops",
+}
+
+// Factory creates the characters of the world, resolving the sprites of
+// the monsters, NPCs and items from their ids.
+type Factory struct {
+	names map[Kind]map[int]string
+}
+
+// NewFactory returns a factory knowing the DefaultMonsters.
+func NewFactory() *Factory {
+	f := &Factory{names: map[Kind]map[int]string{
+		Monster: {},
+		NPC:     {},
+		Item:    {},
+	}}
+
+	for id, name := range DefaultMonsters {
+		f.Register(Monster, id, name)
+	}
+
+	return f
+}
+
+// Register sets the sprite name, without folder nor extension, of the id
+// of a kind.
+func (f *Factory) Register(kind Kind, id int, spriteName string) {
+	f.names[kind][id] = spriteName
+}
+
+// LoadItemTable registers the item sprites of an idnum2itemresnametable.txt
+// table, made of "id#name#" lines.
+func (f *Factory) LoadItemTable(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "//") {
+			continue
+		}
+
+		fields := strings.Split(text, "#")
+		if len(fields) < 2 || fields[1] == "" {
+			return fmt.Errorf("invalid item table line %d", line)
+		}
+
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return errors.Wrapf(err, "invalid item table line %d", line)
+		}
+
+		f.Register(Item, id, fields[1])
+	}
+
+	return scanner.Err()
+}
+
+// SpritePath returns the sprite, without extension, of the id of a kind.
+func (f *Factory) SpritePath(kind Kind, id int) (string, error) {
+	name, ok := f.names[kind][id]
+	if !ok {
+		return "", fmt.Errorf("unknown %s %d", kind, id)
+	}
+
+	return kind.SpriteFolder() + "/" + name, nil
+}
+
+// CreatePlayer returns a player character.
+func (f *Factory) CreatePlayer(gender character.GenderType, jobSpriteID jobspriteid.Type, headIndex character.HeadIndex) *Character {
+	return NewCharacter(gender, jobSpriteID, headIndex)
+}
+
+func (f *Factory) CreateMonster(id int) (*Character, error) {
+	return f.create(Monster, id)
+}
+
+func (f *Factory) CreateNPC(id int) (*Character, error) {
+	return f.create(NPC, id)
+}
+
+// CreateItem returns an item lying on the ground.
+func (f *Factory) CreateItem(id int) (*Character, error) {
+	return f.create(Item, id)
+}
+
+// create returns a character of a single sprite. Such sprites start their
+// actions with the idle one, not the stand by one of the players.
+func (f *Factory) create(kind Kind, id int) (*Character, error) {
+	path, err := f.SpritePath(kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewCharacter(character.Male, jobspriteid.Novice, 0)
+	c.SpritePath = path
+	c.IsMounted = false
+	c.State, c.PreviousState = statetype.Idle, statetype.Idle
+
+	return c, nil
+}
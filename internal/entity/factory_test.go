@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/component"
+)
+
+func TestFactory(t *testing.T) {
+	f := NewFactory()
+
+	poring, err := f.CreateMonster(1002)
+	require.NoError(t, err)
+	assert.Equal(t, "data/sprite/¸ó½ºÅÍ/poring", poring.SpritePath)
+	assert.Equal(t, statetype.Idle, poring.State)
+
+	paths, err := component.CharacterAttachmentPaths(poring.AttachmentConfig())
+	require.NoError(t, err)
+	assert.Equal(t, map[character.AttachmentType]string{
+		character.AttachmentShadow: "data/sprite/shadow",
+		character.AttachmentBody:   "data/sprite/¸ó½ºÅÍ/poring",
+	}, paths)
+
+	_, err = f.CreateNPC(112)
+	assert.EqualError(t, err, "unknown npc 112")
+
+	f.Register(NPC, 112, "4_f_kafra1")
+	kafra, err := f.CreateNPC(112)
+	require.NoError(t, err)
+	assert.Equal(t, "data/sprite/npc/4_f_kafra1", kafra.SpritePath)
+}
+
+func TestFactoryLoadItemTable(t *testing.T) {
+	f := NewFactory()
+
+	require.NoError(t, f.LoadItemTable(strings.NewReader("// items\n501#»¡°£Æ÷¼Ç#\n\n502#ÁÖÈ²Æ÷¼Ç#\n")))
+	potion, err := f.CreateItem(501)
+	require.NoError(t, err)
+	assert.Equal(t, "data/sprite/¾ÆÀÌÅÛ/»¡°£Æ÷¼Ç", potion.SpritePath)
+
+	assert.EqualError(t, f.LoadItemTable(strings.NewReader("503\n")), "invalid item table line 1")
+}
@@ -0,0 +1,61 @@
+package entity
+
+// CharacterStore holds characters by entity id. They are kept in a slice,
+// so the systems iterate them without going through a map, and looked up
+// by their integer id.
+type CharacterStore struct {
+	index map[uint64]int
+	chars []*Character
+}
+
+func NewCharacterStore() *CharacterStore {
+	return &CharacterStore{index: map[uint64]int{}}
+}
+
+// Add adds c, replacing the character of the same id.
+func (s *CharacterStore) Add(c *Character) {
+	if i, ok := s.index[c.ID()]; ok {
+		s.chars[i] = c
+		return
+	}
+
+	s.index[c.ID()] = len(s.chars)
+	s.chars = append(s.chars, c)
+}
+
+// Remove removes the character of id, reporting whether it was there. The
+// last character takes its place.
+func (s *CharacterStore) Remove(id uint64) bool {
+	i, ok := s.index[id]
+	if !ok {
+		return false
+	}
+
+	last := len(s.chars) - 1
+	s.chars[i] = s.chars[last]
+	s.index[s.chars[i].ID()] = i
+	s.chars[last] = nil
+	s.chars = s.chars[:last]
+	delete(s.index, id)
+
+	return true
+}
+
+func (s *CharacterStore) Get(id uint64) (*Character, bool) {
+	i, ok := s.index[id]
+	if !ok {
+		return nil, false
+	}
+
+	return s.chars[i], true
+}
+
+func (s *CharacterStore) Len() int {
+	return len(s.chars)
+}
+
+// All returns the characters. The slice is only valid until the next Add
+// or Remove.
+func (s *CharacterStore) All() []*Character {
+	return s.chars
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+)
+
+func TestCharacterStore(t *testing.T) {
+	s := NewCharacterStore()
+	a := NewCharacter(character.Male, jobspriteid.Novice, 0)
+	b := NewCharacter(character.Female, jobspriteid.Archer, 1)
+	c := NewCharacter(character.Male, jobspriteid.Knight, 2)
+
+	s.Add(a)
+	s.Add(b)
+	s.Add(c)
+	s.Add(a)
+	assert.Equal(t, 3, s.Len())
+
+	assert.True(t, s.Remove(a.ID()))
+	assert.False(t, s.Remove(a.ID()))
+	assert.ElementsMatch(t, []*Character{b, c}, s.All())
+
+	got, ok := s.Get(c.ID())
+	assert.True(t, ok)
+	assert.Same(t, c, got)
+
+	_, ok = s.Get(a.ID())
+	assert.False(t, ok)
+
+	assert.True(t, s.Remove(c.ID()))
+	assert.True(t, s.Remove(b.ID()))
+	assert.Empty(t, s.All())
+}
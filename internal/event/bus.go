@@ -0,0 +1,133 @@
+// Package event is the publish/subscribe bus shared by the input, network,
+// UI and gameplay systems, so they react to each other's events rather
+// than reach into each other. Events are plain structs; handlers are
+// functions of the event type they subscribe to.
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/EngoEngine/ecs"
+)
+
+type subscription struct {
+	id uint64
+	fn reflect.Value
+}
+
+type anySubscription struct {
+	id uint64
+	fn func(e interface{})
+}
+
+// Bus delivers events to the handlers of their type. Publish delivers
+// right away, on the calling goroutine; Post queues the event until the
+// next Update, so goroutines such as the network one hand events to the
+// main thread. A Bus is also a system, to run Update every frame.
+type Bus struct {
+	mu       sync.Mutex
+	nextID   uint64
+	handlers map[reflect.Type][]subscription
+	// all holds the handlers of every event, see SubscribeAll.
+	all   []anySubscription
+	queue []interface{}
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[reflect.Type][]subscription{}}
+}
+
+// Subscribe registers handler, a func(E) receiving the events of type E,
+// and returns the function removing it. It panics when handler is not
+// such a function.
+func (b *Bus) Subscribe(handler interface{}) (unsubscribe func()) {
+	fn := reflect.ValueOf(handler)
+	if fn.Kind() != reflect.Func || fn.Type().NumIn() != 1 || fn.Type().NumOut() != 0 {
+		panic(fmt.Sprintf("event: handler must be a func(E), got %T", handler))
+	}
+	typ := fn.Type().In(0)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.handlers[typ] = append(b.handlers[typ], subscription{id: id, fn: fn})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		subs := b.handlers[typ]
+		for i, s := range subs {
+			if s.id == id {
+				b.handlers[typ] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SubscribeAll registers handler to receive every event, after the
+// handlers of its type, and returns the function removing it.
+func (b *Bus) SubscribeAll(handler func(e interface{})) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.all = append(b.all, anySubscription{id: id, fn: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		for i, s := range b.all {
+			if s.id == id {
+				b.all = append(b.all[:i:i], b.all[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers e to the handlers of its type, in subscription order.
+func (b *Bus) Publish(e interface{}) {
+	b.mu.Lock()
+	subs := b.handlers[reflect.TypeOf(e)]
+	all := b.all
+	b.mu.Unlock()
+
+	args := []reflect.Value{reflect.ValueOf(e)}
+	for _, s := range subs {
+		s.fn.Call(args)
+	}
+
+	for _, s := range all {
+		s.fn(e)
+	}
+}
+
+// Post queues e, to be published by the next Update. It is safe to call
+// from any goroutine.
+func (b *Bus) Post(e interface{}) {
+	b.mu.Lock()
+	b.queue = append(b.queue, e)
+	b.mu.Unlock()
+}
+
+// Update publishes the posted events, in order.
+func (b *Bus) Update(dt float32) {
+	b.mu.Lock()
+	queue := b.queue
+	b.queue = nil
+	b.mu.Unlock()
+
+	for _, e := range queue {
+		b.Publish(e)
+	}
+}
+
+func (b *Bus) Remove(ecs.BasicEntity) {}
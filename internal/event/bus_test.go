@@ -0,0 +1,79 @@
+package event
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusPublish(t *testing.T) {
+	bus := NewBus()
+
+	var got []string
+	bus.Subscribe(func(e MouseClicked) {
+		got = append(got, "first")
+		assert.Equal(t, MouseClicked{X: 1, Y: 2}, e)
+	})
+	unsubscribe := bus.Subscribe(func(e MouseClicked) {
+		got = append(got, "second")
+	})
+	bus.Subscribe(func(e GroundClicked) {
+		got = append(got, "ground")
+	})
+
+	bus.Publish(MouseClicked{X: 1, Y: 2})
+	assert.Equal(t, []string{"first", "second"}, got)
+
+	got = nil
+	unsubscribe()
+	bus.Publish(MouseClicked{X: 1, Y: 2})
+	bus.Publish(GATOverlayToggled{})
+	assert.Equal(t, []string{"first"}, got)
+}
+
+func TestBusSubscribeAll(t *testing.T) {
+	bus := NewBus()
+
+	var got []interface{}
+	unsubscribe := bus.SubscribeAll(func(e interface{}) {
+		got = append(got, e)
+	})
+
+	bus.Publish(MouseClicked{X: 1, Y: 2})
+	bus.Publish(GATOverlayToggled{})
+	unsubscribe()
+	bus.Publish(GATOverlayToggled{})
+
+	assert.Equal(t, []interface{}{MouseClicked{X: 1, Y: 2}, GATOverlayToggled{}}, got)
+}
+
+func TestBusPost(t *testing.T) {
+	bus := NewBus()
+
+	var got []int
+	bus.Subscribe(func(e GroundClicked) {
+		got = append(got, e.X)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bus.Post(GroundClicked{X: i})
+		}(i)
+	}
+	wg.Wait()
+	assert.Empty(t, got)
+
+	bus.Update(0)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, got)
+}
+
+func TestBusSubscribeInvalid(t *testing.T) {
+	bus := NewBus()
+
+	assert.Panics(t, func() { bus.Subscribe(MouseClicked{}) })
+	assert.Panics(t, func() { bus.Subscribe(func(a, b MouseClicked) {}) })
+}
@@ -0,0 +1,126 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/project-midgard/midgarts/internal/character/statustype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+)
+
+// KeyChanged is published by the input when a key is pressed or released.
+// Key is an SDL keycode.
+type KeyChanged struct {
+	Key     int32
+	Pressed bool
+}
+
+// MouseClicked is published by the input when the window is clicked, in
+// window pixels from the top-left corner.
+type MouseClicked struct {
+	X, Y int
+}
+
+// Tapped is published by the input when a touch screen is tapped (see
+// touch.Tap), in window pixels from the top-left corner.
+type Tapped struct {
+	X, Y int
+}
+
+// Pinched is published by the input when two fingers move apart or closer
+// on a touch screen, Scale being the ratio of their distance to the one
+// before (see touch.Pinch).
+type Pinched struct {
+	Scale float32
+}
+
+// CharacterPicked is published when a click hits a character.
+type CharacterPicked struct {
+	Character *entity.Character
+}
+
+// GroundClicked is published when a click hits no character.
+type GroundClicked struct {
+	X, Y int
+}
+
+// GATOverlayToggled is published to show or hide the GAT overlay.
+type GATOverlayToggled struct{}
+
+// SnapshotRequested is published to save the state of the client to a
+// snapshot file (see internal/snapshot).
+type SnapshotRequested struct{}
+
+// WeatherCycled is published to change the weather of the map to the next
+// kind (see weather.Kind.Next).
+type WeatherCycled struct{}
+
+// StatusChanged is published when a status effect of a character starts,
+// for Duration (0 until it ends), or ends when Active is not set.
+type StatusChanged struct {
+	Character *entity.Character
+	Status    statustype.Type
+	Active    bool
+	Duration  time.Duration
+}
+
+// StatusCycled is published to show the next status effect on the
+// character of the player, for debugging.
+type StatusCycled struct{}
+
+// CharacterEntered is published when the character of the player enters
+// the game, switching to the profile of Account and Name (see
+// client.Profile).
+type CharacterEntered struct {
+	Account string
+	Name    string
+}
+
+// TimeScaleToggled is published to run the simulation at Scale, e.g. 0 to
+// pause it, or back at the normal speed when it already runs at Scale.
+type TimeScaleToggled struct {
+	Scale float32
+}
+
+// PacketReceived is posted by the network for every server packet.
+type PacketReceived struct {
+	Packet packet.Packet
+}
+
+// MarshalJSON encodes the packet as sent by the server.
+func (e PacketReceived) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Packet.Encode())
+}
+
+func (e *PacketReceived) UnmarshalJSON(data []byte) error {
+	var raw []byte
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p, err := packet.Read(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	e.Packet = p
+	return nil
+}
+
+// LevelUpKind is what a character gained.
+type LevelUpKind int
+
+const (
+	BaseLevelUp LevelUpKind = iota
+	JobLevelUp
+	JobChanged
+)
+
+// LevelUp is published when a character gains a base or a job level, or
+// changes its job.
+type LevelUp struct {
+	Character *entity.Character
+	Kind      LevelUpKind
+}
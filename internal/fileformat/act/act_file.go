@@ -28,10 +28,30 @@ type ActionFrameLayer struct {
 	Height           int32
 }
 
+// AnchorPoint is a point of a frame the frames of the other sprites of a
+// character are aligned on, e.g. the neck of a body and of a head. The
+// files of version 2.3 on have them.
+type AnchorPoint struct {
+	X, Y      int32
+	Attribute int32
+}
+
 type ActionFrame struct {
-	Layers    []*ActionFrameLayer
-	Sound     int32
-	Positions [][2]int32
+	Layers []*ActionFrameLayer
+	// Sound is the index in the Sounds of the file of the sound played
+	// when the frame starts, -1 for none.
+	Sound   int32
+	Anchors []AnchorPoint
+}
+
+// Anchor returns the first anchor point of f, the one the attachments of
+// the characters are aligned on, and whether f has any.
+func (f *ActionFrame) Anchor() (AnchorPoint, bool) {
+	if len(f.Anchors) == 0 {
+		return AnchorPoint{}, false
+	}
+
+	return f.Anchors[0], true
 }
 
 type Action struct {
@@ -49,6 +69,8 @@ type ActionFile struct {
 	ActionCount uint16
 	Actions     []*Action
 
+	// Sounds are the names of the sounds of the frames, in data/wav/. The
+	// files of version 2.1 on have them.
 	Sounds []string
 }
 
@@ -64,27 +86,33 @@ func Load(data []byte) (*ActionFile, error) {
 		return nil, err
 	}
 
-	if f.Header.Version > 2.1 {
-		// Sound
-		var soundLen int32
-		_ = binary.Read(reader, binary.LittleEndian, &soundLen)
-		f.Sounds = make([]string, soundLen)
+	if f.Header.Version >= 2.1 {
+		var soundCount int32
+		_ = binary.Read(reader, binary.LittleEndian, &soundCount)
+		if soundCount < 0 || int64(soundCount)*40 > int64(reader.Len()) {
+			return nil, fmt.Errorf("invalid sound count %d", soundCount)
+		}
+		f.Sounds = make([]string, soundCount)
 
 		for i := 0; i < len(f.Sounds); i++ {
 			var b [40]byte
 			_ = binary.Read(reader, binary.LittleEndian, &b)
 
-			f.Sounds[i] = string(b[:])
+			// The names are padded with zeros.
+			f.Sounds[i] = string(bytes.TrimRight(b[:], "\x00"))
 		}
 	}
 
 	for i := 0; i < int(f.ActionCount); i++ {
-		var d float32
-		_ = binary.Read(reader, binary.LittleEndian, &d)
-
 		act := f.Actions[i]
-		f.Actions[i].Delay = uint32(d * 25.0)
-		f.Actions[i].DurationMilliseconds = act.Delay * uint32(len(act.Frames))
+
+		// The older files keep the default delay.
+		if f.Header.Version >= 2.2 {
+			var d float32
+			_ = binary.Read(reader, binary.LittleEndian, &d)
+			act.Delay = uint32(d * 25.0)
+		}
+		act.DurationMilliseconds = act.Delay * uint32(len(act.Frames))
 	}
 
 	return f, nil
@@ -118,7 +146,7 @@ func (f *ActionFile) loadHeader(buf io.ReadSeeker) error {
 	return nil
 }
 
-func (f *ActionFile) loadActions(buf io.ReadSeeker) error {
+func (f *ActionFile) loadActions(buf *bytes.Reader) error {
 	var (
 		count = int(f.ActionCount)
 	)
@@ -126,8 +154,13 @@ func (f *ActionFile) loadActions(buf io.ReadSeeker) error {
 	_ = binary.Read(buf, binary.LittleEndian, &count)
 
 	for i := 0; i < count; i++ {
+		frames, err := f.loadActionFrames(buf)
+		if err != nil {
+			return fmt.Errorf("could not load action %d: %w", i, err)
+		}
+
 		f.Actions[i] = &Action{
-			Frames:               f.loadActionFrames(buf),
+			Frames:               frames,
 			Delay:                ActionDefaultDelay,
 			DurationMilliseconds: 0,
 		}
@@ -136,12 +169,12 @@ func (f *ActionFile) loadActions(buf io.ReadSeeker) error {
 	return nil
 }
 
-func (f *ActionFile) loadActionFrames(buf io.ReadSeeker) []*ActionFrame {
+func (f *ActionFile) loadActionFrames(buf *bytes.Reader) ([]*ActionFrame, error) {
 	var (
-		frames     []*ActionFrame
-		frameCount uint32
-		sound      int32
-		posCount   int32
+		frames      []*ActionFrame
+		frameCount  uint32
+		sound       int32
+		anchorCount int32
 	)
 
 	_ = binary.Read(buf, binary.LittleEndian, &frameCount)
@@ -150,7 +183,6 @@ func (f *ActionFile) loadActionFrames(buf io.ReadSeeker) []*ActionFrame {
 	for i := 0; i < int(frameCount); i++ {
 		_ = bytesutil.SkipBytes(buf, 32)
 
-		positions := make([][2]int32, frameCount)
 		layers := f.loadActionFrameLayers(buf)
 
 		if f.Header.Version >= 2.0 {
@@ -159,31 +191,34 @@ func (f *ActionFile) loadActionFrames(buf io.ReadSeeker) []*ActionFrame {
 			sound = -1
 		}
 
+		var anchors []AnchorPoint
 		if f.Header.Version >= 2.3 {
-			_ = binary.Read(buf, binary.LittleEndian, &posCount)
+			_ = binary.Read(buf, binary.LittleEndian, &anchorCount)
+			// An anchor is 16 bytes, its 4 reserved ones included.
+			if anchorCount < 0 || int64(anchorCount)*16 > int64(buf.Len()) {
+				return nil, fmt.Errorf("invalid anchor count %d", anchorCount)
+			}
 
-			for i := 0; i < int(posCount); i++ {
+			anchors = make([]AnchorPoint, 0, anchorCount)
+			for j := 0; j < int(anchorCount); j++ {
 				_ = bytesutil.SkipBytes(buf, 4)
 
-				var a, b int32
-				_ = binary.Read(buf, binary.LittleEndian, &a)
-				_ = binary.Read(buf, binary.LittleEndian, &b)
-
-				positions[i][0] = a
-				positions[i][1] = b
-
-				_ = bytesutil.SkipBytes(buf, 4)
+				var anchor AnchorPoint
+				if err := binary.Read(buf, binary.LittleEndian, &anchor); err != nil {
+					break
+				}
+				anchors = append(anchors, anchor)
 			}
 		}
 
 		frames[i] = &ActionFrame{
-			Layers:    layers,
-			Sound:     sound,
-			Positions: positions,
+			Layers:  layers,
+			Sound:   sound,
+			Anchors: anchors,
 		}
 	}
 
-	return frames
+	return frames, nil
 }
 
 func (f *ActionFile) loadActionFrameLayers(buf io.ReadSeeker) []*ActionFrameLayer {
@@ -0,0 +1,103 @@
+package act
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// actFile returns an ACT file of version 2.3 of an action of frames, each
+// a layer and the anchor points of anchors, the first one playing the
+// first of sounds.
+func actFile(sounds []string, anchors ...[]AnchorPoint) []byte {
+	buf := &bytes.Buffer{}
+	write := func(v ...interface{}) {
+		for _, v := range v {
+			_ = binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+
+	write([]byte(HeaderSignature), []byte{3, 2}, uint16(1), [10]byte{})
+	write(uint32(len(anchors)))
+	for i, frame := range anchors {
+		sound := int32(-1)
+		if i == 0 && len(sounds) > 0 {
+			sound = 0
+		}
+		write([32]byte{}, uint32(1))
+		// The layer: position, sprite, mirrored, color, scale, angle, type.
+		write([2]int32{4, -2}, int32(0), int32(0), [4]byte{255, 255, 255, 255}, float32(1), int32(0), int32(0))
+		write(sound, int32(len(frame)))
+		for _, a := range frame {
+			write(int32(0), a)
+		}
+	}
+	write(int32(len(sounds)))
+	for _, s := range sounds {
+		var name [40]byte
+		copy(name[:], s)
+		write(name)
+	}
+	// The delay of the action.
+	write(float32(4))
+
+	return buf.Bytes()
+}
+
+func TestLoadAnchors(t *testing.T) {
+	f, err := Load(actFile(
+		nil,
+		[]AnchorPoint{{X: -1, Y: -80}},
+		nil,
+		[]AnchorPoint{{X: 2, Y: -78, Attribute: 1}, {X: 5, Y: 5}},
+	))
+	require.NoError(t, err)
+	require.Len(t, f.Actions, 1)
+	frames := f.Actions[0].Frames
+	require.Len(t, frames, 3)
+
+	assert.Equal(t, []AnchorPoint{{X: -1, Y: -80}}, frames[0].Anchors)
+	assert.Len(t, frames[0].Layers, 1)
+	assert.Equal(t, [2]int32{4, -2}, frames[0].Layers[0].Position)
+
+	// A frame without anchor point has none, not the ones of the frame
+	// count.
+	_, ok := frames[1].Anchor()
+	assert.False(t, ok)
+
+	a, ok := frames[2].Anchor()
+	require.True(t, ok)
+	assert.Equal(t, AnchorPoint{X: 2, Y: -78, Attribute: 1}, a)
+	assert.Len(t, frames[2].Anchors, 2)
+
+	assert.Equal(t, uint32(100), f.Actions[0].Delay)
+}
+
+func TestLoadSounds(t *testing.T) {
+	f, err := Load(actFile([]string{"knight_attack.wav", "_hit_sword.wav"}, nil, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"knight_attack.wav", "_hit_sword.wav"}, f.Sounds)
+	frames := f.Actions[0].Frames
+	assert.Equal(t, int32(0), frames[0].Sound)
+	assert.Equal(t, int32(-1), frames[1].Sound)
+	// The delay follows the sounds.
+	assert.Equal(t, uint32(100), f.Actions[0].Delay)
+	assert.Equal(t, uint32(200), f.Actions[0].DurationMilliseconds)
+}
+
+func TestLoadInvalidAnchorCount(t *testing.T) {
+	for _, count := range []int32{-1, 1 << 30} {
+		data := actFile(nil, []AnchorPoint{{X: -1, Y: -80}})
+		// The anchor count follows the header, the frame count, the frame
+		// and its layer, and the sound.
+		binary.LittleEndian.PutUint32(data[92:], uint32(count))
+
+		_, err := Load(data)
+		assert.EqualError(t, err, fmt.Sprintf("could not load action 0: invalid anchor count %d", count))
+	}
+}
@@ -0,0 +1,85 @@
+package act
+
+import (
+	"sort"
+	"time"
+)
+
+// Timeline is the precomputed timing of an action, so finding the frame
+// shown at some time is a binary search.
+type Timeline struct {
+	// Delay is the duration of a frame at the normal speed.
+	Delay time.Duration
+	// Ends holds the end of each frame from the start of the action, at
+	// the normal speed.
+	Ends []time.Duration
+	// Layers holds the layers of each frame that refer to a sprite frame.
+	Layers [][]*ActionFrameLayer
+}
+
+// NewTimeline returns the timeline of action. Actions without delay use
+// ActionDefaultDelay.
+func NewTimeline(action *Action) *Timeline {
+	delay := time.Duration(action.Delay) * time.Millisecond
+	if delay <= 0 {
+		delay = ActionDefaultDelay * time.Millisecond
+	}
+
+	t := &Timeline{
+		Delay:  delay,
+		Ends:   make([]time.Duration, len(action.Frames)),
+		Layers: make([][]*ActionFrameLayer, len(action.Frames)),
+	}
+
+	for i, frame := range action.Frames {
+		t.Ends[i] = time.Duration(i+1) * delay
+
+		if frame == nil {
+			continue
+		}
+		for _, layer := range frame.Layers {
+			if layer.SpriteFrameIndex >= 0 {
+				t.Layers[i] = append(t.Layers[i], layer)
+			}
+		}
+	}
+
+	return t
+}
+
+// NewTimelines returns the timeline of every action of f.
+func NewTimelines(f *ActionFile) []*Timeline {
+	timelines := make([]*Timeline, len(f.Actions))
+	for i, action := range f.Actions {
+		timelines[i] = NewTimeline(action)
+	}
+
+	return timelines
+}
+
+// Duration returns the duration of the action at the normal speed.
+func (t *Timeline) Duration() time.Duration {
+	if len(t.Ends) == 0 {
+		return 0
+	}
+
+	return t.Ends[len(t.Ends)-1]
+}
+
+// FrameAt returns the frame shown at elapsed, at the normal speed, from
+// the start of the action, the action repeating.
+func (t *Timeline) FrameAt(elapsed time.Duration) int {
+	duration := t.Duration()
+	if duration <= 0 {
+		return 0
+	}
+
+	elapsed %= duration
+	if elapsed < 0 {
+		elapsed += duration
+	}
+
+	return sort.Search(len(t.Ends), func(i int) bool {
+		return t.Ends[i] > elapsed
+	})
+}
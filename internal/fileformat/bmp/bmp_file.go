@@ -0,0 +1,44 @@
+// Package bmp decodes the BMP textures of the archives, as found in
+// data/texture: their magenta pixels are transparent, as the original
+// client draws them.
+package bmp
+
+import (
+	"bytes"
+	"image"
+
+	"github.com/pkg/errors"
+	xbmp "golang.org/x/image/bmp"
+
+	"github.com/project-midgard/midgarts/internal/graphic"
+)
+
+// Load decodes the BMP texture data, its magenta pixels transparent black.
+func Load(data []byte) (*graphic.UniqueRGBA, error) {
+	img, err := xbmp.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode texture")
+	}
+
+	bounds := img.Bounds()
+	rgba := graphic.NewUniqueRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			if isMagenta(uint8(r>>8), uint8(g>>8), uint8(b>>8)) {
+				continue
+			}
+
+			i := rgba.PixOffset(x, y)
+			rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2], rgba.Pix[i+3] = uint8(r>>8), uint8(g>>8), uint8(b>>8), 0xff
+		}
+	}
+
+	return rgba, nil
+}
+
+// isMagenta reports whether a texture pixel is transparent, the
+// compression of the BMP files blurring the magenta a little.
+func isMagenta(r, g, b uint8) bool {
+	return r > 230 && g < 20 && b > 230
+}
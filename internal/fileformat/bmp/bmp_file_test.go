@@ -0,0 +1,29 @@
+package bmp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	xbmp "golang.org/x/image/bmp"
+)
+
+func TestLoad(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, color.RGBA{R: 255, B: 255, A: 255})
+	// Magenta blurred by the compression.
+	img.Set(1, 0, color.RGBA{R: 240, G: 10, B: 250, A: 255})
+	img.Set(2, 0, color.RGBA{R: 10, G: 20, B: 30, A: 255})
+	var data bytes.Buffer
+	require.NoError(t, xbmp.Encode(&data, img))
+
+	texture, err := Load(data.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []uint8{0, 0, 0, 0, 0, 0, 0, 0, 10, 20, 30, 255}, texture.Pix)
+
+	_, err = Load([]byte("BM"))
+	assert.Error(t, err)
+}
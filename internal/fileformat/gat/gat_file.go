@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"math"
 
 	"github.com/project-midgard/midgarts/internal/romap"
 )
 
 const HeaderSignature = "GRAT"
 
+// UnitsPerCell is the size of a cell in the units of the altitudes, a
+// GND tile being two cells of 5 units.
+const UnitsPerCell = 5
+
 const (
 	None     = romap.CellTypeNone
 	Walkable = romap.CellTypeWalkable
@@ -28,6 +33,8 @@ var TypeTable = [7]romap.CellType{
 }
 
 type Cell struct {
+	// Cells holds the altitudes of the corners: south west, south east,
+	// north west and north east.
 	Cells    [4]float32
 	CellType romap.CellType
 }
@@ -76,13 +83,50 @@ func Load(data []byte) (f *GroundAltitudeFile, err error) {
 		var t uint32
 		_ = binary.Read(reader, binary.LittleEndian, &t)
 
+		cellType := None
+		if int(t) < len(TypeTable) {
+			cellType = TypeTable[t]
+		}
+
 		f.Cells[i] = Cell{
 			Cells:    [4]float32{h1, h2, h3, h4},
-			CellType: TypeTable[t],
+			CellType: cellType,
 		}
 	}
 
-	//log.Printf("gat.GroundAltitudeFile(%v)", f)
+	return f, nil
+}
+
+// CellAt returns the cell at the given map coordinates, or nil when
+// they are out of bounds.
+func (f *GroundAltitudeFile) CellAt(x, y int) *Cell {
+	if x < 0 || y < 0 || x >= int(f.Width) || y >= int(f.Height) {
+		return nil
+	}
+
+	return &f.Cells[x+y*int(f.Width)]
+}
+
+// Height returns the average altitude of the cell corners. Altitudes
+// grow downwards, as in the original files.
+func (c *Cell) Height() float32 {
+	return (c.Cells[0] + c.Cells[1] + c.Cells[2] + c.Cells[3]) / 4
+}
+
+// HeightAt returns the altitude of the ground at the given map
+// coordinates, in cells, the cell (x, y) spanning from (x, y) to
+// (x+1, y+1). The altitude is interpolated between the corners of the
+// cell, following the slopes. ok is false out of bounds.
+func (f *GroundAltitudeFile) HeightAt(x, y float32) (height float32, ok bool) {
+	cx, cy := int(math.Floor(float64(x))), int(math.Floor(float64(y)))
+	cell := f.CellAt(cx, cy)
+	if cell == nil {
+		return 0, false
+	}
+
+	fx, fy := x-float32(cx), y-float32(cy)
+	south := cell.Cells[0] + (cell.Cells[1]-cell.Cells[0])*fx
+	north := cell.Cells[2] + (cell.Cells[3]-cell.Cells[2])*fx
 
-	return nil, nil
+	return south + (north-south)*fy, true
 }
@@ -0,0 +1,43 @@
+package gat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeightAt(t *testing.T) {
+	f := &GroundAltitudeFile{
+		Width:  2,
+		Height: 1,
+		Cells: []Cell{
+			// Flat, then sloping up to the north east (altitudes grow
+			// downwards).
+			{Cells: [4]float32{10, 10, 10, 10}},
+			{Cells: [4]float32{10, 6, 6, 2}},
+		},
+	}
+
+	tests := []struct {
+		X, Y   float32
+		Height float32
+	}{
+		{X: 0.5, Y: 0.5, Height: 10},
+		{X: 1, Y: 0, Height: 10},
+		{X: 1.5, Y: 0, Height: 8},
+		{X: 1, Y: 0.5, Height: 8},
+		{X: 1.5, Y: 0.5, Height: 6},
+		{X: 1.99, Y: 0.99, Height: 2.08},
+	}
+
+	for _, tt := range tests {
+		height, ok := f.HeightAt(tt.X, tt.Y)
+		assert.True(t, ok)
+		assert.InDelta(t, tt.Height, height, 1e-4, "(%v, %v)", tt.X, tt.Y)
+	}
+
+	_, ok := f.HeightAt(-0.5, 0)
+	assert.False(t, ok)
+	_, ok = f.HeightAt(2, 0)
+	assert.False(t, ok)
+}
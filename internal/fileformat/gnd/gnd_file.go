@@ -5,17 +5,53 @@ import (
 	"encoding/binary"
 	"github.com/project-midgard/midgarts/internal/bytesutil"
 	"io"
-	"log"
+	"math"
 
-	"golang.org/x/text/encoding/charmap"
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
 )
 
+// TilesVersion is the first version whose tiles and surfaces are read.
+const TilesVersion = 1.7
+
 type GroundFile struct {
-	Version        float32
+	Version float32
+	// Width and Height are the size of the ground, in surfaces of 2 by 2
+	// GAT cells.
 	Width, Height  uint32
 	Zoom           float32
 	Textures       []string
 	TextureIndices []int64
+
+	// Tiles and Surfaces are left empty before TilesVersion.
+	Tiles    []Tile
+	Surfaces []Surface
+}
+
+// Tile is a textured face of the ground.
+type Tile struct {
+	// U and V are the texture coordinates of the corners: south west,
+	// south east, north west and north east.
+	U, V [4]float32
+	// Texture is the index of the texture in Textures.
+	Texture int
+	Light   uint16
+	// Color is the BGRA color of the tile.
+	Color [4]uint8
+}
+
+// NoTile is the tile of the faces not drawn.
+const NoTile = -1
+
+// Surface is a cube of the ground, of 2 by 2 GAT cells.
+type Surface struct {
+	// Heights are the altitudes of the corners, in the order of the tile
+	// texture coordinates.
+	Heights [4]float32
+	// TileUp, TileFront and TileRight are the indices of the tiles of the
+	// top, north and east faces in Tiles, or NoTile.
+	TileUp, TileFront, TileRight int32
 }
 
 type LightMapData struct {
@@ -47,17 +83,25 @@ func Load(data []byte) (f *GroundFile, err error) {
 	f.Height = h
 	f.Zoom = zoom
 
-	err = f.loadTextures(reader)
-	if err != nil {
-		log.Fatal(err)
+	if err = f.loadTextures(reader); err != nil {
+		return nil, errors.Wrap(err, "could not load textures")
 	}
 
-	err = f.loadLightMaps(reader)
-	if err != nil {
-		log.Fatal(err)
+	if f.Version < TilesVersion {
+		return f, nil
 	}
 
-	//log.Printf("%#v\n", f)
+	if err = f.loadLightMaps(reader); err != nil {
+		return nil, errors.Wrap(err, "could not load light maps")
+	}
+
+	if err = f.loadTiles(reader); err != nil {
+		return nil, errors.Wrap(err, "could not load tiles")
+	}
+
+	if err = f.loadSurfaces(reader); err != nil {
+		return nil, errors.Wrap(err, "could not load surfaces")
+	}
 
 	return f, nil
 }
@@ -73,7 +117,7 @@ func (f *GroundFile) loadTextures(buf io.Reader) error {
 	for i := 0; i < int(textureCount); i++ {
 		name, err := bytesutil.ReadString(buf, int(texturePathLength))
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 
 		pos := -1
@@ -85,14 +129,7 @@ func (f *GroundFile) loadTextures(buf io.Reader) error {
 		}
 
 		if pos == -1 {
-			var decodedName string
-			if decodedName, err = charmap.Windows1252.NewDecoder().String(
-				name,
-			); err != nil {
-				panic(err)
-			}
-
-			textures = append(textures, decodedName)
+			textures = append(textures, encoding.DecodeRaw([]byte(name)))
 			pos = len(textures) - 1
 		}
 
@@ -105,7 +142,7 @@ func (f *GroundFile) loadTextures(buf io.Reader) error {
 	return nil
 }
 
-func (f *GroundFile) loadLightMaps(buf io.Reader) error {
+func (f *GroundFile) loadLightMaps(buf io.ReadSeeker) error {
 	var count uint32
 	_ = binary.Read(buf, binary.LittleEndian, &count)
 
@@ -118,7 +155,75 @@ func (f *GroundFile) loadLightMaps(buf io.Reader) error {
 
 	perCell := perCellX * perCellY * sizeCell
 
-	_ = perCell
+	// The light maps are not drawn: their brightness and color are
+	// skipped.
+	return bytesutil.SkipBytes(buf, int64(count)*int64(perCell)*4)
+}
+
+func (f *GroundFile) loadTiles(buf io.Reader) error {
+	var count uint32
+	if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+		return err
+	}
+
+	f.Tiles = make([]Tile, 0, count)
+	for i := 0; i < int(count); i++ {
+		var raw struct {
+			U, V    [4]float32
+			Texture uint16
+			Light   uint16
+			Color   [4]uint8
+		}
+		if err := binary.Read(buf, binary.LittleEndian, &raw); err != nil {
+			return errors.Wrapf(err, "tile %d", i)
+		}
+
+		texture := int(raw.Texture)
+		if texture < len(f.TextureIndices) {
+			texture = int(f.TextureIndices[texture])
+		}
+
+		f.Tiles = append(f.Tiles, Tile{U: raw.U, V: raw.V, Texture: texture, Light: raw.Light, Color: raw.Color})
+	}
 
 	return nil
 }
+
+func (f *GroundFile) loadSurfaces(buf io.Reader) error {
+	f.Surfaces = make([]Surface, int(f.Width)*int(f.Height))
+
+	return binary.Read(buf, binary.LittleEndian, f.Surfaces)
+}
+
+// SurfaceAt returns the surface at the given coordinates, in surfaces,
+// or nil when they are out of bounds or the surfaces were not read.
+func (f *GroundFile) SurfaceAt(x, y int) *Surface {
+	if x < 0 || y < 0 || x >= int(f.Width) || y >= int(f.Height) || len(f.Surfaces) == 0 {
+		return nil
+	}
+
+	return &f.Surfaces[x+y*int(f.Width)]
+}
+
+// CellsPerSurface is the number of GAT cells along a side of a surface.
+const CellsPerSurface = 2
+
+// HeightAt returns the altitude of the top of the ground at the given map
+// coordinates, in GAT cells, as GAT.HeightAt does. The altitude is
+// interpolated between the corners of the surface, following its slope;
+// the walls between the surfaces make steps, as the cliffs. ok is false
+// out of bounds or when the surfaces were not read.
+func (f *GroundFile) HeightAt(x, y float32) (height float32, ok bool) {
+	sx, sy := x/CellsPerSurface, y/CellsPerSurface
+	cx, cy := int(math.Floor(float64(sx))), int(math.Floor(float64(sy)))
+	surface := f.SurfaceAt(cx, cy)
+	if surface == nil {
+		return 0, false
+	}
+
+	fx, fy := sx-float32(cx), sy-float32(cy)
+	south := surface.Heights[0] + (surface.Heights[1]-surface.Heights[0])*fx
+	north := surface.Heights[2] + (surface.Heights[3]-surface.Heights[2])*fx
+
+	return south + (north-south)*fy, true
+}
@@ -0,0 +1,100 @@
+package gnd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFile returns a 1.7 ground of a single surface, of two textures, the
+// first one named twice, and a light map.
+func newFile() []byte {
+	var buf bytes.Buffer
+	write := func(v interface{}) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+	name := func(s string) {
+		b := make([]byte, 80)
+		copy(b, s)
+		buf.Write(b)
+	}
+
+	buf.WriteString("GRGN")
+	buf.Write([]byte{1, 7})
+	write([2]uint32{1, 1})
+	write(float32(10))
+
+	write([2]uint32{3, 80})
+	name(`grass.bmp`)
+	name(`stone.bmp`)
+	name(`grass.bmp`)
+
+	// A light map of 8x8 cells of 1 byte.
+	write([4]uint32{1, 8, 8, 1})
+	buf.Write(make([]byte, 8*8*4))
+
+	write(uint32(1))
+	write([8]float32{0, 0.25, 0, 0.25, 0, 0, 0.25, 0.25})
+	write([2]uint16{2, 0})
+	buf.Write([]byte{1, 2, 3, 4})
+
+	write([4]float32{-1, -2, -3, -4})
+	write([3]int32{0, NoTile, NoTile})
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	f, err := Load(newFile())
+	require.NoError(t, err)
+
+	assert.Equal(t, float32(1.7), f.Version)
+	assert.Equal(t, []string{"grass.bmp", "stone.bmp"}, f.Textures)
+
+	require.Len(t, f.Tiles, 1)
+	tile := f.Tiles[0]
+	assert.Equal(t, [4]float32{0, 0.25, 0, 0.25}, tile.U)
+	assert.Equal(t, [4]float32{0, 0, 0.25, 0.25}, tile.V)
+	// The third texture name is the first texture.
+	assert.Equal(t, 0, tile.Texture)
+	assert.Equal(t, [4]uint8{1, 2, 3, 4}, tile.Color)
+
+	s := f.SurfaceAt(0, 0)
+	require.NotNil(t, s)
+	assert.Equal(t, [4]float32{-1, -2, -3, -4}, s.Heights)
+	assert.Equal(t, int32(0), s.TileUp)
+	assert.Equal(t, int32(NoTile), s.TileFront)
+	assert.Nil(t, f.SurfaceAt(1, 0))
+}
+
+func TestHeightAt(t *testing.T) {
+	// Sloping up to the north east (altitudes grow downwards), over 2 by
+	// 2 cells.
+	f := &GroundFile{Width: 1, Height: 1, Surfaces: []Surface{{Heights: [4]float32{10, 6, 6, 2}}}}
+
+	for _, tt := range []struct {
+		X, Y   float32
+		Height float32
+	}{
+		{X: 0, Y: 0, Height: 10},
+		{X: 1, Y: 0, Height: 8},
+		{X: 1, Y: 1, Height: 6},
+		{X: 1.5, Y: 1.5, Height: 4},
+	} {
+		height, ok := f.HeightAt(tt.X, tt.Y)
+		assert.True(t, ok)
+		assert.InDelta(t, tt.Height, height, 1e-4, "(%v, %v)", tt.X, tt.Y)
+	}
+
+	_, ok := f.HeightAt(2, 0)
+	assert.False(t, ok)
+	_, ok = (&GroundFile{Width: 1, Height: 1}).HeightAt(0, 0)
+	assert.False(t, ok)
+}
+
+func TestLoadTruncated(t *testing.T) {
+	data := newFile()
+	_, err := Load(data[:len(data)-4])
+	assert.Error(t, err)
+}
@@ -29,10 +29,26 @@ type Entry struct {
 	Name   string
 	Header EntryHeader
 	Data   []byte
+
+	// rawName holds the file name exactly as it is encoded in the archive,
+	// so it can be written back without going through the lowercased Name.
+	rawName []byte
 }
 
 // Decode ...
 func (e *Entry) Decode(data []byte) error {
+	data, err := e.decode(data)
+	if err != nil {
+		return err
+	}
+	e.Data = data
+
+	return nil
+}
+
+// decode returns the decrypted and decompressed data, as read from the
+// archive. It decrypts data in place.
+func (e *Entry) decode(data []byte) ([]byte, error) {
 	if e.Header.Flags&entryTypeEncryptMixed != 0 {
 		des.DecodeFull(data, int(e.Header.CompressedSizeAligned), int(e.Header.CompressedSize))
 	} else if e.Header.Flags&entryTypeEncryptHeader != 0 {
@@ -40,15 +56,13 @@ func (e *Entry) Decode(data []byte) error {
 	}
 
 	if e.Header.CompressedSize == e.Header.UncompressedSize {
-		e.Data = data
-		return nil
+		return data, nil
 	}
 
-	data, err := decompress(data)
+	data, err := decompress(data, int(e.Header.UncompressedSize))
 	if err != nil {
-		return errors.Wrap(err, "could not decompress entry data")
+		return nil, errors.Wrap(err, "could not decompress entry data")
 	}
-	e.Data = data
 
-	return nil
+	return data, nil
 }
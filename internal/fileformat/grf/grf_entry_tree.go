@@ -25,6 +25,23 @@ func (t *EntryTree) Insert(value string, data []*Entry) error {
 	return t.Root.Insert(value, data)
 }
 
+// set sets the entries of value, inserting it if needed.
+func (t *EntryTree) set(value string, data []*Entry) error {
+	for n := t.Root; n != nil; {
+		switch {
+		case value == n.Value:
+			n.Data = data
+			return nil
+		case value < n.Value:
+			n = n.Left
+		default:
+			n = n.Right
+		}
+	}
+
+	return t.Insert(value, data)
+}
+
 func (t *EntryTree) Find(s string) ([]*Entry, bool) {
 	if t.Root == nil {
 		return nil, false
@@ -0,0 +1,173 @@
+package grf
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+// EntriesError is the error of ReadEntries when some of the entries could
+// not be read, by name.
+type EntriesError map[string]error
+
+func (e EntriesError) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 1 {
+		return e[names[0]].Error()
+	}
+
+	return fmt.Sprintf("could not read %d entries, first: %v", len(names), e[names[0]])
+}
+
+// ReadEntries returns the entries of names, as GetEntry, read and
+// decompressed by up to workers goroutines (runtime.NumCPU() when <= 0).
+func (f *File) ReadEntries(names []string, workers int) ([]*Entry, error) {
+	return f.ReadEntriesContext(context.Background(), names, workers)
+}
+
+// ReadEntriesContext is like ReadEntries, but stops loading once ctx is done.
+// The entries that cannot be read are nil, the others still read, and the
+// error is then an EntriesError.
+func (f *File) ReadEntriesContext(ctx context.Context, names []string, workers int) ([]*Entry, error) {
+	return readEntries(ctx, f.GetEntryContext, names, workers)
+}
+
+// readEntries reads the entries of names with get, on up to workers
+// goroutines.
+func readEntries(ctx context.Context, get func(ctx context.Context, name string) (*Entry, error), names []string, workers int) ([]*Entry, error) {
+	entries := make([]*Entry, len(names))
+
+	var (
+		mu     sync.Mutex
+		failed = EntriesError{}
+	)
+	err := parallel(ctx, len(names), workers, func(ctx context.Context, i int) error {
+		e, err := get(ctx, names[i])
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			mu.Lock()
+			failed[names[i]] = err
+			mu.Unlock()
+			return nil
+		}
+
+		entries[i] = e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(failed) != 0 {
+		return entries, failed
+	}
+
+	return entries, nil
+}
+
+// ExtractAll writes the entries of names under dir, with their paths
+// converted to UTF-8, using up to workers goroutines. Unlike ReadEntries, the data of the entries is not kept.
+func (f *File) ExtractAll(ctx context.Context, dir string, names []string, workers int) error {
+	return parallel(ctx, len(names), workers, func(ctx context.Context, i int) error {
+		e, err := f.findEntry(ctx, names[i])
+		if err != nil {
+			return err
+		}
+
+		data := e.Data
+		if len(data) == 0 && !f.isAdded(e) {
+			if data, err = f.readEntryData(e); err != nil {
+				return err
+			}
+		}
+
+		// The names of the entries are lowercased, their raw form keeps
+		// the Korean characters intact.
+		name := e.Name
+		if len(e.rawName) != 0 {
+			if utf8Name, err := encoding.DecodeCP949(e.rawName); err == nil {
+				name = strings.ReplaceAll(utf8Name, `\`, `/`)
+			}
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) {
+			return errors.Errorf("entry '%s' is outside of the extraction folder", e.Name)
+		}
+
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		return errors.Wrapf(ioutil.WriteFile(path, data, 0644), "could not extract '%s'", e.Name)
+	})
+}
+
+// parallel calls fn for 0 to n-1 on up to workers goroutines, until an
+// error or ctx is done.
+func parallel(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		next     = make(chan int)
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range next {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return ctx.Err()
+}
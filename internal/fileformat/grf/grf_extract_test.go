@@ -0,0 +1,89 @@
+package grf
+
+import (
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestGRF(t *testing.T, entries map[string]string) *File {
+	path := filepath.Join(t.TempDir(), "test.grf")
+	out, err := os.Create(path)
+	require.NoError(t, err)
+
+	w, err := NewWriter(out, zlib.DefaultCompression)
+	require.NoError(t, err)
+	for name, data := range entries {
+		require.NoError(t, w.Write(name, []byte(data)))
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, out.Close())
+
+	f, err := Load(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	return f
+}
+
+func TestReadEntries(t *testing.T) {
+	entries := map[string]string{}
+	var names []string
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("data/texture/%d.bmp", i)
+		entries[name] = fmt.Sprintf("texture %d texture %d texture %d", i, i, i)
+		names = append(names, name)
+	}
+	f := writeTestGRF(t, entries)
+
+	got, err := f.ReadEntries(names, 4)
+	require.NoError(t, err)
+	for i, e := range got {
+		assert.Equal(t, entries[names[i]], string(e.Data))
+	}
+
+	// The entries that cannot be read do not stop the others.
+	got, err = f.ReadEntries(append([]string{"data/texture/missing.bmp"}, names...), 4)
+	assert.EqualError(t, err, "could not find entry 'data/texture/missing.bmp'")
+	require.IsType(t, EntriesError{}, err)
+	assert.Len(t, err, 1)
+	require.Len(t, got, len(names)+1)
+	assert.Nil(t, got[0])
+	for i, e := range got[1:] {
+		assert.Equal(t, entries[names[i]], string(e.Data))
+	}
+
+	_, err = f.ReadEntries([]string{"data/texture/a.bmp", "data/texture/b.bmp"}, 4)
+	assert.EqualError(t, err, "could not read 2 entries, first: could not find entry 'data/texture/a.bmp'")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = f.ReadEntriesContext(ctx, names, 4)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestExtractAll(t *testing.T) {
+	// data/sprite/인간족/a.spr, in raw form.
+	f := writeTestGRF(t, map[string]string{
+		"data/sprite/ÀÎ°£Á·/a.spr": "sprite",
+		"data/prontera.gat":        "gat",
+	})
+
+	dir := t.TempDir()
+	require.NoError(t, f.ExtractAll(context.Background(), dir, []string{"data/sprite/ÀÎ°£Á·/a.spr", "data/prontera.gat"}, 0))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "data", "sprite", "인간족", "a.spr"))
+	require.NoError(t, err)
+	assert.Equal(t, "sprite", string(data))
+
+	e, err := f.findEntry(context.Background(), "data/prontera.gat")
+	require.NoError(t, err)
+	assert.Empty(t, e.Data, "the extracted data is not kept")
+}
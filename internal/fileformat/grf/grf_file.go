@@ -3,18 +3,24 @@ package grf
 import (
 	"bufio"
 	"compress/zlib"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/imf"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
 	"github.com/project-midgard/midgarts/internal/fileformat/spr"
 	"io"
 	"os"
-	"path/filepath"
+	"path"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
-	"golang.org/x/text/encoding/charmap"
+
+	"github.com/project-midgard/midgarts/internal/metrics"
+	"github.com/project-midgard/midgarts/pkg/encoding"
 )
 
 const (
@@ -36,10 +42,27 @@ type File struct {
 	entries     map[string][]*Entry
 	entriesTree *EntryTree
 
-	file *os.File
+	file    *os.File
+	dataDir string
+	// added holds the entries added or replaced by AddEntry.
+	added map[*Entry]bool
+
+	// mu guards the data of the entries, loaded from several goroutines.
+	// The archive is read with ReadAt, which needs no locking.
+	mu sync.Mutex
 }
 
 func Load(path string) (*File, error) {
+	return LoadContext(context.Background(), path)
+}
+
+// LoadContext is like Load, but stops reading the file table when ctx is
+// done, returning ctx.Err().
+func LoadContext(ctx context.Context, path string) (*File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -54,11 +77,13 @@ func Load(path string) (*File, error) {
 
 	err = grfFile.parseHeader(f, fi)
 	if err != nil {
+		_ = f.Close()
 		return nil, errors.Wrap(err, "could not read header")
 	}
 
-	err = grfFile.parseEntries(f)
+	err = grfFile.parseEntries(ctx, f)
 	if err != nil {
+		_ = f.Close()
 		return nil, errors.Wrap(err, "could not read entries")
 	}
 
@@ -77,47 +102,96 @@ func (f *File) GetEntryTree() *EntryTree {
 	return f.entriesTree
 }
 
-func (f *File) GetEntry(name string) (entry *Entry, err error) {
-	name = strings.ToLower(name)
-	var entries []*Entry
-	var exists bool
-	dir, _ := filepath.Split(name)
+func (f *File) GetEntry(name string) (*Entry, error) {
+	return f.GetEntryContext(context.Background(), name)
+}
+
+// GetEntryContext is like GetEntry, but does not read nor decompress the
+// entry data once ctx is done.
+func (f *File) GetEntryContext(ctx context.Context, name string) (entry *Entry, err error) {
+	if entry, err = f.findEntry(ctx, name); err != nil {
+		return entry, err
+	}
+
+	if err = f.loadEntryData(entry); err != nil {
+		return entry, err
+	}
+
+	return
+}
+
+// findEntry returns the entry of name, from the data folder or the
+// archive, without loading its data.
+func (f *File) findEntry(ctx context.Context, name string) (entry *Entry, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// The entry names are slash separated, whatever the system.
+	name = strings.ReplaceAll(name, `\`, `/`)
+
+	if entry, err = f.overlayEntry(name); entry != nil || err != nil {
+		return entry, err
+	}
+
+	return f.archiveEntry(strings.ToLower(name))
+}
+
+// archiveEntry returns the entry of name, lowercased and slash separated,
+// from the archive only.
+func (f *File) archiveEntry(name string) (*Entry, error) {
+	dir, _ := path.Split(name)
 	dir = strings.TrimSuffix(dir, `/`)
 
-	if entries, exists = f.entriesTree.Find(dir); !exists {
-		return entry, fmt.Errorf("could not find directory '%s'", dir)
+	entries, exists := f.entriesTree.Find(dir)
+	if !exists {
+		return nil, fmt.Errorf("could not find directory '%s'", dir)
 	}
 
 	for _, e := range entries {
 		if e.Name == name {
-			entry = e
-			break
+			return e, nil
 		}
 	}
 
-	if entry == nil {
-		return nil, fmt.Errorf("could not find entry '%s'", name)
-	}
+	return nil, fmt.Errorf("could not find entry '%s'", name)
+}
+
+// loadEntryData reads the data of entry, once.
+func (f *File) loadEntryData(entry *Entry) error {
+	f.mu.Lock()
+	loaded := len(entry.Data) != 0 || f.added[entry]
+	f.mu.Unlock()
 
-	if len(entry.Data) != 0 {
-		return entry, nil
+	if loaded {
+		return nil
 	}
 
-	_, err = f.file.Seek(int64(entry.Header.Offset)+fileHeaderLength, io.SeekStart)
+	data, err := f.readEntryData(entry)
 	if err != nil {
-		return entry, err
+		return err
 	}
 
-	data, err := readNextBytes(f.file, int(entry.Header.CompressedSizeAligned))
-	if err != nil {
-		return entry, err
+	f.mu.Lock()
+	if len(entry.Data) == 0 {
+		entry.Data = data
 	}
+	f.mu.Unlock()
 
-	if err = entry.Decode(data); err != nil {
-		return entry, err
+	return nil
+}
+
+// readEntryData reads and decodes the data of an entry of the archive,
+// without keeping it. It can be called concurrently.
+func (f *File) readEntryData(entry *Entry) ([]byte, error) {
+	data := make([]byte, entry.Header.CompressedSizeAligned)
+	if _, err := f.file.ReadAt(data, int64(entry.Header.Offset)+fileHeaderLength); err != nil {
+		return nil, errors.Wrapf(err, "could not read entry '%s'", entry.Name)
 	}
+	metrics.GRFReads.Add(1)
+	metrics.GRFReadBytes.Add(int64(len(data)))
 
-	return
+	return entry.decode(data)
 }
 
 type ActionSpriteFilePair struct {
@@ -126,7 +200,23 @@ type ActionSpriteFilePair struct {
 }
 
 func (f *File) GetSpriteFiles(name string) (ActionSpriteFilePair, error) {
-	e, err := f.GetEntry(fmt.Sprintf("%s.act", name))
+	return f.GetSpriteFilesContext(context.Background(), name)
+}
+
+// GetSpriteFilesContext is like GetSpriteFiles, but stops loading once ctx
+// is done.
+func (f *File) GetSpriteFilesContext(ctx context.Context, name string) (ActionSpriteFilePair, error) {
+	return loadSpriteFiles(ctx, f.GetEntryContext, name)
+}
+
+// loadSpriteFiles loads the ACT and SPR files of the sprite name with
+// getEntry.
+func loadSpriteFiles(
+	ctx context.Context,
+	getEntry func(ctx context.Context, name string) (*Entry, error),
+	name string,
+) (ActionSpriteFilePair, error) {
+	e, err := getEntry(ctx, fmt.Sprintf("%s.act", name))
 	if err != nil {
 		return ActionSpriteFilePair{}, err
 	}
@@ -136,7 +226,7 @@ func (f *File) GetSpriteFiles(name string) (ActionSpriteFilePair, error) {
 		return ActionSpriteFilePair{}, err
 	}
 
-	e, err = f.GetEntry(fmt.Sprintf("%s.spr", name))
+	e, err = getEntry(ctx, fmt.Sprintf("%s.spr", name))
 	if err != nil {
 		return ActionSpriteFilePair{}, err
 	}
@@ -152,7 +242,50 @@ func (f *File) GetSpriteFiles(name string) (ActionSpriteFilePair, error) {
 	}, nil
 }
 
+// GetPaletteContext loads the palette of path, e.g. a dye of data/palette.
+func (f *File) GetPaletteContext(ctx context.Context, path string) (*pal.PaletteFile, error) {
+	return loadPalette(ctx, f.GetEntryContext, path)
+}
+
+// loadPalette loads the palette of path with getEntry.
+func loadPalette(
+	ctx context.Context,
+	getEntry func(ctx context.Context, name string) (*Entry, error),
+	path string,
+) (*pal.PaletteFile, error) {
+	e, err := getEntry(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return pal.Load(e.Data)
+}
+
+// GetPrioritiesContext loads the IMF file of path, e.g. the one of a job
+// in data/imf.
+func (f *File) GetPrioritiesContext(ctx context.Context, path string) (*imf.PriorityFile, error) {
+	return loadPriorities(ctx, f.GetEntryContext, path)
+}
+
+func loadPriorities(
+	ctx context.Context,
+	getEntry func(ctx context.Context, name string) (*Entry, error),
+	path string,
+) (*imf.PriorityFile, error) {
+	e, err := getEntry(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return imf.Load(e.Data)
+}
+
 func (f *File) Close() error {
+	// The archives made with New have no file.
+	if f.file == nil {
+		return nil
+	}
+
 	return f.file.Close()
 }
 
@@ -183,7 +316,7 @@ func (f *File) parseHeader(file *os.File, fi os.FileInfo) error {
 	return nil
 }
 
-func (f *File) parseEntries(file *os.File) error {
+func (f *File) parseEntries(ctx context.Context, file *os.File) error {
 	_, _ = file.Seek(int64(f.Header.FileTableOffset), io.SeekStart)
 
 	var compressedSize, uncompressedSize uint32
@@ -196,23 +329,24 @@ func (f *File) parseEntries(file *os.File) error {
 		return errors.Wrap(err, "could instantiate zlib reader")
 	}
 	var (
-		reader          = bufio.NewReader(zlibReader)
-		uniqueDirs      = make(map[string]bool)
-		fileNameDecoder = charmap.Windows1252.NewDecoder()
+		reader     = bufio.NewReader(zlibReader)
+		uniqueDirs = make(map[string]bool)
 	)
 
 	for i := 0; i < int(f.Header.EntryCount); i++ {
+		// Checking every entry would slow down large archives.
+		if i%4096 == 0 {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+		}
+
 		fileNameBytes, err := reader.ReadBytes(0)
 		if err != nil {
 			return errors.Wrap(err, "could not parse entry file name")
 		}
 
-		var d []byte
-		if d, err = fileNameDecoder.Bytes(fileNameBytes[0 : len(fileNameBytes)-1]); err != nil {
-			return errors.Wrap(err, "could not decode entry file name")
-		}
-
-		entry := &Entry{Data: []byte{}}
+		entry := &Entry{Data: []byte{}, rawName: fileNameBytes[0 : len(fileNameBytes)-1]}
 
 		if err = binary.Read(reader, binary.LittleEndian, &entry.Header); err != nil {
 			return errors.Wrap(err, "could not read file entry header")
@@ -222,9 +356,9 @@ func (f *File) parseEntries(file *os.File) error {
 			continue
 		}
 
-		properFileName := strings.ToLower(strings.ReplaceAll(string(d), `\`, `/`))
+		properFileName := strings.ToLower(strings.ReplaceAll(encoding.DecodeRaw(entry.rawName), `\`, `/`))
 		entry.Name = properFileName
-		dir, _ := filepath.Split(properFileName)
+		dir, _ := path.Split(properFileName)
 		dir = strings.TrimSuffix(dir, `/`)
 		uniqueDirs[dir] = true
 
@@ -262,16 +396,3 @@ func (f *File) parseEntries(file *os.File) error {
 
 	return nil
 }
-
-func readNextBytes(reader io.Reader, number int) ([]byte, error) {
-	bytesRead := make([]byte, number)
-
-	n, err := reader.Read(bytesRead)
-	if err != nil {
-		return nil, errors.Wrap(err, "could not read next bytes")
-	}
-	if n != number {
-		return nil, errors.Wrapf(err, "could not read next bytes: want %d, got %d", number, n)
-	}
-	return bytesRead, nil
-}
@@ -0,0 +1,266 @@
+package grf
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/imf"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+)
+
+// MultiFile is a set of archives loaded together, as the client loads
+// data.grf, rdata.grf and the patch archives: an entry is read from the
+// first archive having it.
+type MultiFile struct {
+	// Files are the archives, by priority.
+	Files []*File
+}
+
+// LoadAll loads the archives of paths, the first ones taking precedence.
+// The DATA.INI files (see ReadDataINI) among paths are replaced by the
+// archives they list.
+func LoadAll(paths ...string) (*MultiFile, error) {
+	return LoadAllContext(context.Background(), paths...)
+}
+
+// LoadAllContext is like LoadAll, but stops reading the file tables when
+// ctx is done, returning ctx.Err().
+func LoadAllContext(ctx context.Context, paths ...string) (*MultiFile, error) {
+	paths, err := ArchivePaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("no archive to load")
+	}
+
+	m := &MultiFile{}
+	for _, path := range paths {
+		f, err := LoadContext(ctx, path)
+		if err != nil {
+			_ = m.Close()
+			return nil, errors.Wrapf(err, "could not load archive '%s'", path)
+		}
+		m.Files = append(m.Files, f)
+	}
+
+	return m, nil
+}
+
+// ArchivePaths returns paths, the DATA.INI files among them, told by their
+// .ini extension, replaced by the archives they list. Those are relative
+// to the directory of their DATA.INI.
+func ArchivePaths(paths []string) ([]string, error) {
+	var archives []string
+	for _, path := range paths {
+		if !strings.EqualFold(filepath.Ext(path), ".ini") {
+			archives = append(archives, path)
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		names, err := ReadDataINI(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid '%s'", path)
+		}
+
+		for _, name := range names {
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(filepath.Dir(path), name)
+			}
+			archives = append(archives, name)
+		}
+	}
+
+	return archives, nil
+}
+
+// ReadDataINI returns the archives listed in the [Data] section of the
+// DATA.INI of r, by priority: the client reads the entries from the
+// archive of the lowest number first, e.g.
+//
+//	[Data]
+//	0=custom.grf
+//	1=rdata.grf
+//	2=data.grf
+func ReadDataINI(r io.Reader) ([]string, error) {
+	type archive struct {
+		priority int
+		name     string
+	}
+	var archives []archive
+
+	scanner := bufio.NewScanner(r)
+	section := ""
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, ";") || strings.HasPrefix(text, "#"):
+			continue
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			section = strings.ToLower(strings.TrimSpace(text[1 : len(text)-1]))
+			continue
+		case section != "data":
+			continue
+		}
+
+		key, value := text, ""
+		if i := strings.Index(text, "="); i >= 0 {
+			key, value = strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+		}
+		priority, err := strconv.Atoi(key)
+		if err != nil || value == "" {
+			return nil, fmt.Errorf("invalid archive '%s' on line %d", text, line)
+		}
+		archives = append(archives, archive{priority, value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read DATA.INI")
+	}
+
+	sort.SliceStable(archives, func(i, j int) bool {
+		return archives[i].priority < archives[j].priority
+	})
+
+	names := make([]string, len(archives))
+	for i, a := range archives {
+		names[i] = a.name
+	}
+
+	return names, nil
+}
+
+// SetDataDir sets the data folder overriding the entries of every archive
+// (see File.SetDataDir).
+func (m *MultiFile) SetDataDir(dir string) {
+	for _, f := range m.Files {
+		f.SetDataDir(dir)
+	}
+}
+
+func (m *MultiFile) DataDir() string {
+	return m.Files[0].DataDir()
+}
+
+// OverlayPaths returns the paths an entry may have in the data folder, see
+// File.OverlayPaths.
+func (m *MultiFile) OverlayPaths(name string) []string {
+	return m.Files[0].OverlayPaths(name)
+}
+
+func (m *MultiFile) GetEntry(name string) (*Entry, error) {
+	return m.GetEntryContext(context.Background(), name)
+}
+
+// GetEntryContext returns the entry of name from the data folder, or else
+// from the first archive having it, with its data.
+func (m *MultiFile) GetEntryContext(ctx context.Context, name string) (*Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	name = strings.ReplaceAll(name, `\`, `/`)
+
+	// The data folder is the same for every archive.
+	entry, err := m.Files[0].overlayEntry(name)
+	if entry != nil || err != nil {
+		return entry, err
+	}
+
+	name = strings.ToLower(name)
+	for _, f := range m.Files {
+		if entry, err = f.archiveEntry(name); err == nil {
+			return entry, f.loadEntryData(entry)
+		}
+	}
+
+	return nil, fmt.Errorf("could not find entry '%s'", name)
+}
+
+// ReadEntriesContext returns the entries of names, as GetEntryContext, read
+// in parallel as File.ReadEntriesContext does.
+func (m *MultiFile) ReadEntriesContext(ctx context.Context, names []string, workers int) ([]*Entry, error) {
+	return readEntries(ctx, m.GetEntryContext, names, workers)
+}
+
+// GetEntries returns the entries of the directory dir, the ones of the
+// archives taking precedence hiding the others of the same name.
+func (m *MultiFile) GetEntries(dir string) []*Entry {
+	var entries []*Entry
+	seen := map[string]bool{}
+	for _, f := range m.Files {
+		for _, e := range f.GetEntries(dir) {
+			if !seen[e.Name] {
+				seen[e.Name] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	return entries
+}
+
+// GetEntryTree returns the tree of the directories of the archives, with
+// their entries as GetEntries returns them.
+func (m *MultiFile) GetEntryTree() *EntryTree {
+	dirs := map[string]bool{}
+	for _, f := range m.Files {
+		for dir := range f.entries {
+			dirs[dir] = true
+		}
+	}
+
+	// In the order of the map, not sorted, for the tree not to be a list.
+	tree := &EntryTree{}
+	for dir := range dirs {
+		_ = tree.Insert(dir, m.GetEntries(dir))
+	}
+
+	return tree
+}
+
+func (m *MultiFile) GetSpriteFiles(name string) (ActionSpriteFilePair, error) {
+	return m.GetSpriteFilesContext(context.Background(), name)
+}
+
+// GetSpriteFilesContext is like GetSpriteFiles, but stops loading once ctx
+// is done.
+func (m *MultiFile) GetSpriteFilesContext(ctx context.Context, name string) (ActionSpriteFilePair, error) {
+	return loadSpriteFiles(ctx, m.GetEntryContext, name)
+}
+
+// GetPaletteContext loads the palette of path, e.g. a dye of data/palette.
+func (m *MultiFile) GetPaletteContext(ctx context.Context, path string) (*pal.PaletteFile, error) {
+	return loadPalette(ctx, m.GetEntryContext, path)
+}
+
+// GetPrioritiesContext loads the IMF file of path, e.g. the one of a job
+// in data/imf.
+func (m *MultiFile) GetPrioritiesContext(ctx context.Context, path string) (*imf.PriorityFile, error) {
+	return loadPriorities(ctx, m.GetEntryContext, path)
+}
+
+// Close closes every archive, returning the first error.
+func (m *MultiFile) Close() error {
+	var first error
+	for _, f := range m.Files {
+		if err := f.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,100 @@
+package grf
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDataINI(t *testing.T) {
+	names, err := ReadDataINI(strings.NewReader(`; patched
+[Data]
+2=data.grf
+0=custom.grf
+1 = rdata.grf
+
+[Other]
+0=ignored.grf
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"custom.grf", "rdata.grf", "data.grf"}, names)
+
+	_, err = ReadDataINI(strings.NewReader("[Data]\nfirst=data.grf\n"))
+	assert.EqualError(t, err, "invalid archive 'first=data.grf' on line 2")
+}
+
+func TestMultiFile(t *testing.T) {
+	patch := writeTestGRF(t, map[string]string{
+		"data/sprite/poring.spr": "patched poring",
+	})
+	data := writeTestGRF(t, map[string]string{
+		"data/sprite/poring.spr": "poring",
+		"data/sprite/drops.spr":  "drops",
+		"data/prontera.gat":      "prontera",
+	})
+
+	// The archives of a DATA.INI are relative to it.
+	dir := t.TempDir()
+	require.NoError(t, os.Rename(patch.file.Name(), filepath.Join(dir, "patch.grf")))
+	ini := filepath.Join(dir, "DATA.INI")
+	require.NoError(t, ioutil.WriteFile(ini, []byte("[Data]\n0=patch.grf\n"), 0644))
+
+	m, err := LoadAll(ini, data.file.Name())
+	require.NoError(t, err)
+	defer m.Close()
+	require.Len(t, m.Files, 2)
+
+	e, err := m.GetEntry(`DATA\sprite\poring.spr`)
+	require.NoError(t, err)
+	assert.Equal(t, "patched poring", string(e.Data))
+	e, err = m.GetEntry("data/prontera.gat")
+	require.NoError(t, err)
+	assert.Equal(t, "prontera", string(e.Data))
+
+	_, err = m.GetEntry("data/sprite/missing.spr")
+	assert.EqualError(t, err, "could not find entry 'data/sprite/missing.spr'")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = m.GetEntryContext(ctx, "data/prontera.gat")
+	assert.Equal(t, context.Canceled, err)
+
+	read, err := m.ReadEntriesContext(context.Background(), []string{"data/sprite/poring.spr", "data/sprite/missing.spr", "data/sprite/drops.spr"}, 0)
+	assert.EqualError(t, err, "could not find entry 'data/sprite/missing.spr'")
+	require.Len(t, read, 3)
+	assert.Equal(t, "patched poring", string(read[0].Data))
+	assert.Nil(t, read[1])
+	assert.Equal(t, "drops", string(read[2].Data))
+
+	entries := m.GetEntries("data/sprite")
+	require.Len(t, entries, 2)
+	assert.Equal(t, "data/sprite/poring.spr", entries[0].Name)
+	assert.Equal(t, "patched poring", string(entries[0].Data))
+
+	tree := m.GetEntryTree()
+	var dirs []string
+	tree.Traverse(tree.Root, func(n *EntryTreeNode) { dirs = append(dirs, n.Value) })
+	assert.Equal(t, []string{"data", "data/sprite"}, dirs)
+	sprites, ok := tree.Find("data/sprite")
+	require.True(t, ok)
+	assert.Len(t, sprites, 2)
+
+	// The data folder overrides every archive.
+	overlay := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(overlay, "data"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(overlay, "data", "prontera.gat"), []byte("edited"), 0644))
+	m.SetDataDir(overlay)
+	e, err = m.GetEntry("data/prontera.gat")
+	require.NoError(t, err)
+	assert.Equal(t, "edited", string(e.Data))
+
+	_, err = LoadAll(filepath.Join(dir, "missing.grf"))
+	assert.Error(t, err)
+	_, err = LoadAll()
+	assert.EqualError(t, err, "no archive to load")
+}
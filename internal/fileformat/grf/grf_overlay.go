@@ -0,0 +1,80 @@
+package grf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+// SetDataDir sets a loose data folder, the one containing "data/", whose
+// files take precedence over the archive entries. Overlay files are read
+// from disk on every GetEntry, so edits are picked up right away.
+func (f *File) SetDataDir(dir string) {
+	f.dataDir = dir
+}
+
+func (f *File) DataDir() string {
+	return f.dataDir
+}
+
+// OverlayPaths returns the paths an entry may have in the data folder: the
+// entry name converted to readable UTF-8, as extracted on most systems,
+// and the raw name. Their ASCII letters are lowercased, like the entry
+// names: the other ones are bytes of Korean characters.
+func (f *File) OverlayPaths(name string) []string {
+	if f.dataDir == "" {
+		return nil
+	}
+
+	name = lowerASCII(strings.ReplaceAll(name, `\`, `/`))
+	paths := []string{}
+
+	if utf8Name, err := encoding.ToUTF8(name); err == nil && utf8Name != name {
+		paths = append(paths, filepath.Join(f.dataDir, filepath.FromSlash(utf8Name)))
+	}
+
+	return append(paths, filepath.Join(f.dataDir, filepath.FromSlash(name)))
+}
+
+// overlayEntry returns the entry read from the data folder, or nil when
+// the entry is not overridden. name must not be lowercased beyond its ASCII
+// letters, the raw Korean names would not convert to UTF-8 anymore.
+func (f *File) overlayEntry(name string) (*Entry, error) {
+	for _, path := range f.OverlayPaths(name) {
+		data, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read overlay file '%s'", path)
+		}
+
+		return &Entry{
+			Name: strings.ToLower(name),
+			Header: EntryHeader{
+				CompressedSize:        uint32(len(data)),
+				CompressedSizeAligned: uint32(len(data)),
+				UncompressedSize:      uint32(len(data)),
+				Flags:                 entryType,
+			},
+			Data: data,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// lowerASCII returns s with its ASCII letters lowercased.
+func lowerASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,55 @@
+package grf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+func TestOverlay(t *testing.T) {
+	// A Korean name, as decoded from the archive.
+	korean := "data/sprite/" + encoding.DecodeRaw([]byte{0xc0, 0xce, 0xb0, 0xa3}) + ".spr"
+	f := writeTestGRF(t, map[string]string{
+		"data/sprite/poring.spr": "poring",
+		"data/sprite/poring.act": "poring actions",
+		korean:                   "human",
+	})
+	assert.Empty(t, f.OverlayPaths("data/sprite/poring.spr"))
+
+	dir := t.TempDir()
+	f.SetDataDir(dir)
+	write := func(name, data string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+	}
+
+	write("data/sprite/poring.spr", "modded poring")
+	write("data/sprite/drops.spr", "drops")
+	utf8Name, err := encoding.ToUTF8(korean)
+	require.NoError(t, err)
+	write(utf8Name, "modded human")
+
+	for name, want := range map[string]string{
+		`DATA\sprite\poring.spr`: "modded poring",
+		"data/sprite/poring.act": "poring actions",
+		"data/sprite/drops.spr":  "drops",
+		korean:                   "modded human",
+	} {
+		e, err := f.GetEntry(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, string(e.Data), name)
+	}
+
+	// Edits are read right away.
+	write("data/sprite/poring.spr", "remodded poring")
+	e, err := f.GetEntry("data/sprite/poring.spr")
+	require.NoError(t, err)
+	assert.Equal(t, "remodded poring", string(e.Data))
+}
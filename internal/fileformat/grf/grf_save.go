@@ -0,0 +1,207 @@
+package grf
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+// New returns an archive without entries, to add entries to (see
+// AddEntry) and save.
+func New() *File {
+	return &File{entries: map[string][]*Entry{}, entriesTree: &EntryTree{}}
+}
+
+// AddEntry adds an entry of name holding data, replacing the entry of the
+// same name, if any. The entries added are kept in memory, and read back
+// by GetEntry, until the archive is saved (see Save).
+func (f *File) AddEntry(name string, data []byte) error {
+	// Written as given, looked up lowercased, as the entries read.
+	rawName, err := encoding.EncodeRaw(strings.ReplaceAll(name, `/`, `\`))
+	if err != nil {
+		return errors.Wrapf(err, "could not encode entry file name '%s'", name)
+	}
+	name = strings.ToLower(strings.ReplaceAll(name, `\`, `/`))
+	dir, _ := path.Split(name)
+	dir = strings.TrimSuffix(dir, `/`)
+
+	header := EntryHeader{
+		CompressedSize:        uint32(len(data)),
+		CompressedSizeAligned: uint32(len(data)),
+		UncompressedSize:      uint32(len(data)),
+		Flags:                 entryType,
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.added == nil {
+		f.added = map[*Entry]bool{}
+	}
+	for _, e := range f.entries[dir] {
+		if e.Name == name {
+			e.Header, e.Data = header, data
+			f.added[e] = true
+			return nil
+		}
+	}
+
+	e := &Entry{Name: name, Header: header, Data: data, rawName: rawName}
+	f.added[e] = true
+	f.entries[dir] = append(f.entries[dir], e)
+
+	return f.entriesTree.set(dir, f.entries[dir])
+}
+
+// isAdded tells whether the data of e was added by AddEntry, rather than
+// read from the archive.
+func (f *File) isAdded(e *Entry) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.added[e]
+}
+
+// Save writes the entries of f, the ones added included, to a new archive
+// at path, compressed at level (see NewWriter). The entries of the archive
+// are read one at a time, not kept in memory; the files of the data folder
+// (see SetDataDir) are not written. Saved to the path of its own archive,
+// f is replaced once written, and reads from the new archive from then on.
+func (f *File) Save(path string, level int) error {
+	names := f.entryNames()
+
+	// Next to path, to be renamed to it.
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "could not create archive")
+	}
+	defer func() {
+		// Gone once renamed.
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	w, err := NewWriter(tmp, level)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		e, err := f.findEntryInArchive(name)
+		if err != nil {
+			return err
+		}
+
+		data := e.Data
+		if len(data) == 0 && !f.isAdded(e) {
+			if data, err = f.readEntryData(e); err != nil {
+				return err
+			}
+		}
+
+		if err = w.WriteEntry(&Entry{Name: e.Name, Data: data, rawName: e.rawName}); err != nil {
+			return err
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "could not write archive")
+	}
+
+	replaced := f.file != nil && samePath(f.file.Name(), path)
+	if replaced {
+		// An open file cannot be replaced on Windows.
+		f.mu.Lock()
+		_ = f.file.Close()
+		f.mu.Unlock()
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		if replaced {
+			// f reads its archive, left as it was, again.
+			f.reopen(path)
+		}
+		return errors.Wrap(err, "could not write archive")
+	}
+	if !replaced {
+		return nil
+	}
+
+	saved, err := Load(path)
+	if err != nil {
+		return errors.Wrap(err, "could not reload archive")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Header, f.entries, f.entriesTree, f.file, f.added = saved.Header, saved.entries, saved.entriesTree, saved.file, nil
+
+	return nil
+}
+
+// reopen opens the archive of f at path again, once closed by Save.
+func (f *File) reopen(path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.file = file
+}
+
+// entryNames returns the names of the entries of the archive, sorted.
+func (f *File) entryNames() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var names []string
+	seen := map[string]bool{}
+	for _, entries := range f.entries {
+		for _, e := range entries {
+			if !seen[e.Name] {
+				seen[e.Name] = true
+				names = append(names, e.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// findEntryInArchive returns the entry of name, as GetEntry resolves it
+// without the data folder: the first of the file table.
+func (f *File) findEntryInArchive(name string) (*Entry, error) {
+	dir, _ := path.Split(name)
+	dir = strings.TrimSuffix(dir, `/`)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, e := range f.entries[dir] {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+
+	return nil, errors.Errorf("could not find entry '%s'", name)
+}
+
+// samePath tells whether a and b are the path of the same file.
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && absA == absB
+}
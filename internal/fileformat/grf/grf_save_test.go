@@ -0,0 +1,98 @@
+package grf
+
+import (
+	"compress/zlib"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// entryData returns the data of the entry of name in f.
+func entryData(t *testing.T, f *File, name string) string {
+	e, err := f.GetEntry(name)
+	require.NoError(t, err, name)
+	return string(e.Data)
+}
+
+func TestAddEntry(t *testing.T) {
+	f := writeTestGRF(t, map[string]string{
+		"data/sprite/poring.spr": "poring",
+		"data/sprite/poring.act": "poring actions",
+	})
+
+	require.NoError(t, f.AddEntry(`data\sprite\poring.spr`, []byte("golden poring")))
+	require.NoError(t, f.AddEntry("data/sprite/drops.spr", []byte("drops")))
+	require.NoError(t, f.AddEntry("data/prontera.gat", []byte("prontera")))
+	require.NoError(t, f.AddEntry("data/empty.txt", nil))
+
+	assert.Equal(t, "golden poring", entryData(t, f, "data/sprite/poring.spr"))
+	assert.Equal(t, "poring actions", entryData(t, f, "data/sprite/poring.act"))
+	assert.Equal(t, "drops", entryData(t, f, "DATA/SPRITE/DROPS.SPR"))
+	assert.Equal(t, "prontera", entryData(t, f, "data/prontera.gat"))
+	assert.Equal(t, "", entryData(t, f, "data/empty.txt"))
+	assert.Len(t, f.GetEntries("data/sprite"), 3)
+}
+
+func TestSave(t *testing.T) {
+	f := writeTestGRF(t, map[string]string{
+		"data/sprite/poring.spr": "poring",
+		"data/sprite/poring.act": "poring actions",
+	})
+	require.NoError(t, f.AddEntry("data/sprite/poring.spr", []byte("golden poring")))
+	require.NoError(t, f.AddEntry("data/prontera.gat", []byte("prontera")))
+
+	path := filepath.Join(t.TempDir(), "saved.grf")
+	require.NoError(t, f.Save(path, zlib.BestCompression))
+
+	saved, err := Load(path)
+	require.NoError(t, err)
+	defer saved.Close()
+	assert.Equal(t, "golden poring", entryData(t, saved, "data/sprite/poring.spr"))
+	assert.Equal(t, "poring actions", entryData(t, saved, "data/sprite/poring.act"))
+	assert.Equal(t, "prontera", entryData(t, saved, "data/prontera.gat"))
+	assert.Equal(t, uint32(3), saved.Header.EntryCount)
+
+	// In place, f reading the new archive.
+	require.NoError(t, saved.AddEntry("data/izlude.gat", []byte("izlude")))
+	require.NoError(t, saved.Save(path, zlib.DefaultCompression))
+	assert.Equal(t, uint32(4), saved.Header.EntryCount)
+	assert.Equal(t, "izlude", entryData(t, saved, "data/izlude.gat"))
+	assert.Equal(t, "poring actions", entryData(t, saved, "data/sprite/poring.act"))
+	assert.Empty(t, saved.added)
+}
+
+func TestSaveInPlace(t *testing.T) {
+	f := writeTestGRF(t, map[string]string{"data/sprite/poring.act": "poring actions"})
+	previous := f.file
+
+	for i, name := range []string{"data/prontera.gat", "data/izlude.gat"} {
+		require.NoError(t, f.AddEntry(name, []byte(name)))
+		require.NoError(t, f.Save(f.file.Name(), zlib.DefaultCompression))
+		assert.Equal(t, uint32(i+2), f.Header.EntryCount)
+	}
+
+	// The replaced archive is closed, f reading the new one.
+	assert.Error(t, previous.Close())
+	entries, err := f.ReadEntries([]string{"data/sprite/poring.act", "data/prontera.gat", "data/izlude.gat"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "poring actions", string(entries[0].Data))
+	assert.Equal(t, "data/prontera.gat", string(entries[1].Data))
+	assert.Equal(t, "data/izlude.gat", string(entries[2].Data))
+	assert.NoError(t, f.Close())
+}
+
+func TestNewSave(t *testing.T) {
+	f := New()
+	require.NoError(t, f.AddEntry("data/sprite/À×¿©.spr", []byte("sprite")))
+
+	path := filepath.Join(t.TempDir(), "new.grf")
+	require.NoError(t, f.Save(path, zlib.DefaultCompression))
+	require.NoError(t, f.Close())
+
+	saved, err := Load(path)
+	require.NoError(t, err)
+	defer saved.Close()
+	assert.Equal(t, "sprite", entryData(t, saved, "data/sprite/À×¿©.spr"))
+}
@@ -0,0 +1,192 @@
+package grf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+const fileHeaderVersion = 0x200
+
+// Writer writes entries into a new 0x200 GRF archive. Entry data is
+// compressed and written as it is added, the file table and the header
+// are only written by Close.
+type Writer struct {
+	w       io.WriteSeeker
+	level   int
+	offset  uint32
+	entries []*Entry
+	names   map[string]bool
+}
+
+// NewWriter returns a Writer that compresses entries with the given zlib
+// level (zlib.NoCompression up to zlib.BestCompression).
+func NewWriter(w io.WriteSeeker, level int) (*Writer, error) {
+	if level < zlib.HuffmanOnly || level > zlib.BestCompression {
+		return nil, errors.Errorf("invalid compression level %d", level)
+	}
+
+	// Reserve room for the header, it is written once the
+	// file table offset is known.
+	if _, err := w.Write(make([]byte, fileHeaderLength)); err != nil {
+		return nil, errors.Wrap(err, "could not reserve file header")
+	}
+
+	return &Writer{
+		w:     w,
+		level: level,
+		names: map[string]bool{},
+	}, nil
+}
+
+// Write adds a new entry with the given name and uncompressed data.
+func (w *Writer) Write(name string, data []byte) error {
+	return w.WriteEntry(&Entry{Name: name, Data: data})
+}
+
+// WriteEntry adds an already loaded entry (see File.GetEntry), keeping its
+// original encoded file name. Entries are always written unencrypted.
+func (w *Writer) WriteEntry(e *Entry) error {
+	rawName, err := e.encodedName()
+	if err != nil {
+		return err
+	}
+
+	key := strings.ToLower(string(rawName))
+	if w.names[key] {
+		return errors.Errorf("duplicate entry '%s'", e.Name)
+	}
+
+	data, err := w.compress(e.Data)
+	if err != nil {
+		return errors.Wrapf(err, "could not compress entry '%s'", e.Name)
+	}
+
+	if _, err = w.w.Write(data); err != nil {
+		return errors.Wrapf(err, "could not write entry '%s'", e.Name)
+	}
+
+	w.names[key] = true
+	w.entries = append(w.entries, &Entry{
+		Name: e.Name,
+		Header: EntryHeader{
+			CompressedSize:        uint32(len(data)),
+			CompressedSizeAligned: uint32(len(data)),
+			UncompressedSize:      uint32(len(e.Data)),
+			Flags:                 entryType,
+			Offset:                w.offset,
+		},
+		rawName: rawName,
+	})
+	w.offset += uint32(len(data))
+
+	return nil
+}
+
+// Close writes the file table, sorted by file name, and the archive header.
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	sort.SliceStable(w.entries, func(i, j int) bool {
+		return w.entries[i].Name < w.entries[j].Name
+	})
+
+	table := new(bytes.Buffer)
+	for _, e := range w.entries {
+		table.Write(e.rawName)
+		table.WriteByte(0)
+
+		if err := binary.Write(table, binary.LittleEndian, e.Header); err != nil {
+			return errors.Wrap(err, "could not write file entry header")
+		}
+	}
+
+	compressed := new(bytes.Buffer)
+	zlibWriter, err := zlib.NewWriterLevel(compressed, w.level)
+	if err != nil {
+		return err
+	}
+	if _, err = zlibWriter.Write(table.Bytes()); err != nil {
+		return errors.Wrap(err, "could not compress file table")
+	}
+	if err = zlibWriter.Close(); err != nil {
+		return errors.Wrap(err, "could not compress file table")
+	}
+
+	sizes := [2]uint32{uint32(compressed.Len()), uint32(table.Len())}
+	if err = binary.Write(w.w, binary.LittleEndian, sizes); err != nil {
+		return errors.Wrap(err, "could not write file table")
+	}
+	if _, err = w.w.Write(compressed.Bytes()); err != nil {
+		return errors.Wrap(err, "could not write file table")
+	}
+
+	if _, err = w.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	var header struct {
+		Signature       [15]byte
+		EncryptionKey   [15]byte
+		FileTableOffset uint32
+		Seed            uint32
+		ReservedFiles   uint32
+		Version         uint32
+	}
+	copy(header.Signature[:], fileHeaderSignature)
+	header.FileTableOffset = w.offset
+	header.ReservedFiles = uint32(len(w.entries)) + 7
+	header.Version = fileHeaderVersion
+
+	if err = binary.Write(w.w, binary.LittleEndian, header); err != nil {
+		return errors.Wrap(err, "could not write header")
+	}
+
+	_, err = w.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+// compress returns the stored form of data. Data that does not shrink is
+// stored raw, since readers treat equal sizes as uncompressed.
+func (w *Writer) compress(data []byte) ([]byte, error) {
+	if w.level == zlib.NoCompression {
+		return data, nil
+	}
+
+	buf := new(bytes.Buffer)
+	zlibWriter, err := zlib.NewWriterLevel(buf, w.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = zlibWriter.Write(data); err != nil {
+		return nil, err
+	}
+	if err = zlibWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	if buf.Len() >= len(data) {
+		return data, nil
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *Entry) encodedName() ([]byte, error) {
+	if len(e.rawName) != 0 {
+		return e.rawName, nil
+	}
+
+	name, err := encoding.EncodeRaw(strings.ReplaceAll(e.Name, `/`, `\`))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not encode entry file name '%s'", e.Name)
+	}
+
+	return name, nil
+}
@@ -2,21 +2,94 @@ package grf
 
 import (
 	"bytes"
-	"compress/zlib"
+	stdzlib "compress/zlib"
+	"io"
 	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/zlib"
+	"github.com/pkg/errors"
 )
 
-func decompress(data []byte) ([]byte, error) {
-	zlibReader, err := zlib.NewReader(bytes.NewReader(data))
+// Decompressor inflates the zlib data of the entries.
+type Decompressor interface {
+	// Decompress returns data inflated. size is the uncompressed size
+	// from the entry header, or 0 when unknown.
+	Decompress(data []byte, size int) ([]byte, error)
+}
+
+// DefaultDecompressor inflates the entries read by every File.
+var DefaultDecompressor Decompressor = NewFastDecompressor()
+
+// StdDecompressor uses compress/zlib.
+type StdDecompressor struct{}
+
+func (StdDecompressor) Decompress(data []byte, size int) ([]byte, error) {
+	r, err := stdzlib.NewReader(bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
-	defer zlibReader.Close()
+	defer r.Close()
+
+	return readAll(r, size)
+}
 
-	data, err = ioutil.ReadAll(zlibReader)
+// FastDecompressor uses github.com/klauspost/compress/zlib, faster than
+// compress/zlib, and reuses its readers (see BenchmarkDecompressors).
+type FastDecompressor struct {
+	readers sync.Pool
+}
+
+func NewFastDecompressor() *FastDecompressor {
+	return &FastDecompressor{}
+}
+
+func (d *FastDecompressor) Decompress(data []byte, size int) ([]byte, error) {
+	var (
+		r   io.ReadCloser
+		err error
+	)
+
+	if pooled, ok := d.readers.Get().(io.ReadCloser); ok {
+		r = pooled
+		err = r.(zlib.Resetter).Reset(bytes.NewReader(data), nil)
+	} else {
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return data, nil
+	out, err := readAll(r, size)
+	if err == nil {
+		d.readers.Put(r)
+	}
+
+	return out, err
+}
+
+// readAll reads r to the end, in a single allocation when size is known.
+func readAll(r io.Reader, size int) ([]byte, error) {
+	if size <= 0 {
+		return ioutil.ReadAll(r)
+	}
+
+	out := make([]byte, size)
+	if _, err := io.ReadFull(r, out); err != nil {
+		return nil, err
+	}
+
+	// The stream must end there, checking its checksum.
+	if n, err := r.Read(make([]byte, 1)); err != io.EOF {
+		if err == nil {
+			err = errors.Errorf("data larger than its size (%d bytes), read %d more", size, n)
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func decompress(data []byte, size int) ([]byte, error) {
+	return DefaultDecompressor.Decompress(data, size)
 }
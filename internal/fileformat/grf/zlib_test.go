@@ -0,0 +1,75 @@
+package grf
+
+import (
+	"bytes"
+	stdzlib "compress/zlib"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compressed returns size bytes of sprite-like data, runs of a few
+// palette indexes, and their zlib stream.
+func compressed(t testing.TB, size int) (raw, data []byte) {
+	rnd := rand.New(rand.NewSource(1))
+	raw = make([]byte, size)
+	for i := 0; i < size; {
+		run := 1 + rnd.Intn(16)
+		b := byte(rnd.Intn(8))
+		for ; run > 0 && i < size; run-- {
+			raw[i] = b
+			i++
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	w := stdzlib.NewWriter(buf)
+	_, err := w.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return raw, buf.Bytes()
+}
+
+func TestDecompressors(t *testing.T) {
+	raw, data := compressed(t, 64<<10)
+
+	for name, d := range map[string]Decompressor{"std": StdDecompressor{}, "fast": NewFastDecompressor()} {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				got, err := d.Decompress(data, len(raw))
+				require.NoError(t, err)
+				assert.Equal(t, raw, got)
+			}
+
+			got, err := d.Decompress(data, 0)
+			require.NoError(t, err)
+			assert.Equal(t, raw, got)
+
+			_, err = d.Decompress(data, len(raw)-1)
+			assert.Error(t, err)
+
+			_, err = d.Decompress(data, len(raw)+1)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func BenchmarkDecompressors(b *testing.B) {
+	raw, data := compressed(b, 256<<10)
+
+	for name, d := range map[string]Decompressor{"std": StdDecompressor{}, "fast": NewFastDecompressor()} {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(raw)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := d.Decompress(data, len(raw)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package imf reads the IMF files of the jobs, as found in data/imf: the
+// draw priority of the layers of a character, the body and the head, at
+// each frame of each action.
+package imf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// The layers of the characters.
+const (
+	LayerBody = 0
+	LayerHead = 1
+)
+
+// Frame is the priority of a layer at a frame, the layers drawn from the
+// lowest priority to the highest, and the anchor of the layer.
+type Frame struct {
+	Priority int32
+	X, Y     int32
+}
+
+type PriorityFile struct {
+	Version  float32
+	Checksum int32
+	// Layers are the frames of each action of the layers.
+	Layers [][][]Frame
+}
+
+func Load(data []byte) (f *PriorityFile, err error) {
+	f = new(PriorityFile)
+	reader := bytes.NewReader(data)
+
+	var header struct {
+		Version  float32
+		Checksum int32
+		MaxLayer int32
+	}
+	if err = binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("could not read header: %w", err)
+	}
+	f.Version, f.Checksum = header.Version, header.Checksum
+
+	// The actions and frames counts take 4 bytes each at least.
+	if header.MaxLayer < 0 || int(header.MaxLayer) >= reader.Len()/4 {
+		return nil, fmt.Errorf("invalid layer count %d", header.MaxLayer+1)
+	}
+
+	f.Layers = make([][][]Frame, header.MaxLayer+1)
+	for layer := range f.Layers {
+		var actions int32
+		if err = binary.Read(reader, binary.LittleEndian, &actions); err != nil {
+			return nil, fmt.Errorf("could not read action count of layer %d: %w", layer, err)
+		}
+		if actions < 0 || int(actions) > reader.Len()/4 {
+			return nil, fmt.Errorf("invalid action count %d of layer %d", actions, layer)
+		}
+
+		f.Layers[layer] = make([][]Frame, actions)
+		for action := range f.Layers[layer] {
+			var frames int32
+			if err = binary.Read(reader, binary.LittleEndian, &frames); err != nil {
+				return nil, fmt.Errorf("could not read frame count of action %d of layer %d: %w", action, layer, err)
+			}
+			if frames < 0 || int(frames) > reader.Len()/binary.Size(Frame{}) {
+				return nil, fmt.Errorf("invalid frame count %d of action %d of layer %d", frames, action, layer)
+			}
+
+			f.Layers[layer][action] = make([]Frame, frames)
+			if err = binary.Read(reader, binary.LittleEndian, f.Layers[layer][action]); err != nil {
+				return nil, fmt.Errorf("could not read frames of action %d of layer %d: %w", action, layer, err)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// Priority returns the priority of layer at the frame of action, and
+// whether f has it.
+func (f *PriorityFile) Priority(layer, action, frame int) (int32, bool) {
+	if layer < 0 || layer >= len(f.Layers) ||
+		action < 0 || action >= len(f.Layers[layer]) ||
+		frame < 0 || frame >= len(f.Layers[layer][action]) {
+		return 0, false
+	}
+
+	return f.Layers[layer][action][frame].Priority, true
+}
+
+// HeadBehind tells whether the head is drawn behind the body at the frame
+// of action, its priority lower than the one of the body. The head is in
+// front when f has no priority for either.
+func (f *PriorityFile) HeadBehind(action, frame int) bool {
+	body, ok := f.Priority(LayerBody, action, frame)
+	if !ok {
+		return false
+	}
+	head, ok := f.Priority(LayerHead, action, frame)
+
+	return ok && head < body
+}
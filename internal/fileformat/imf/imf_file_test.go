@@ -0,0 +1,55 @@
+package imf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFile returns an IMF file of a body and a head of an action of two
+// frames, the head behind the body at the second one.
+func newFile() []byte {
+	var buf bytes.Buffer
+	w := func(v interface{}) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+
+	w(float32(1.01))
+	w(int32(0))
+	w(int32(1))
+	for _, priorities := range [][]int32{{0, 1}, {1, 0}} {
+		w(int32(1))
+		w(int32(len(priorities)))
+		for _, p := range priorities {
+			w(Frame{Priority: p})
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	f, err := Load(newFile())
+	require.NoError(t, err)
+
+	assert.InDelta(t, 1.01, f.Version, 1e-6)
+	require.Len(t, f.Layers, 2)
+	require.Len(t, f.Layers[LayerHead], 1)
+	assert.Len(t, f.Layers[LayerHead][0], 2)
+
+	p, ok := f.Priority(LayerBody, 0, 1)
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), p)
+	_, ok = f.Priority(LayerHead, 1, 0)
+	assert.False(t, ok)
+
+	assert.False(t, f.HeadBehind(0, 0))
+	assert.True(t, f.HeadBehind(0, 1))
+	assert.False(t, f.HeadBehind(3, 0), "unknown action")
+
+	_, err = Load(newFile()[:20])
+	assert.Error(t, err)
+	_, err = Load(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+package pal
+
+import (
+	"fmt"
+)
+
+// Size is the length of a palette: 256 colors stored as R, G, B and a
+// reserved byte.
+const Size = 1024
+
+// PaletteFile is a .pal file, as found in data/palette. It shares the
+// layout of the palette embedded at the end of .spr files.
+type PaletteFile struct {
+	Palette [Size]byte
+}
+
+func Load(data []byte) (*PaletteFile, error) {
+	if len(data) < Size {
+		return nil, fmt.Errorf("invalid palette size: %d", len(data))
+	}
+
+	f := new(PaletteFile)
+	copy(f.Palette[:], data[:Size])
+
+	return f, nil
+}
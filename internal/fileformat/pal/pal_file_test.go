@@ -0,0 +1,39 @@
+package pal_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+)
+
+func TestLoad(t *testing.T) {
+	data := make([]byte, pal.Size)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	f, err := pal.Load(data)
+	require.NoError(t, err)
+	assert.Equal(t, data, f.Palette[:])
+
+	// The color of index 1 starts at its fourth byte.
+	assert.Equal(t, []byte{4, 5, 6, 7}, f.Palette[4:8])
+}
+
+func TestLoadIgnoresTrailingData(t *testing.T) {
+	data := make([]byte, pal.Size+16)
+	data[pal.Size-1] = 0xff
+	data[pal.Size] = 0xee
+
+	f, err := pal.Load(data)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0xff), f.Palette[pal.Size-1])
+}
+
+func TestLoadTruncated(t *testing.T) {
+	_, err := pal.Load(make([]byte, pal.Size-1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,325 @@
+package rsm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/bytesutil"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+const HeaderSignature = "GRSM"
+
+// ModelFile is a map prop model: a tree of nodes, each a mesh placed
+// relatively to its parent. The position keyframes of the models before
+// version 1.5 and the bounding volumes that follow the nodes are not
+// read.
+type ModelFile struct {
+	Version float32
+	// AnimLength is the length of the animation of the nodes, in
+	// milliseconds, the frames of the keyframes being milliseconds too.
+	AnimLength int32
+	ShadeType  int32
+	Alpha      uint8
+	Textures   []string
+	// MainNode is the name of the root node.
+	MainNode string
+	Nodes    []Node
+}
+
+// Node is a mesh of a model.
+type Node struct {
+	Name, Parent string
+	// Textures are the indices in the textures of the model of the
+	// textures of the node, by face texture.
+	Textures []int32
+	// OffsetMatrix and Offset place the vertices in the node, Position,
+	// the rotation and Scale place the node in its parent.
+	OffsetMatrix  mgl32.Mat3
+	Offset        mgl32.Vec3
+	Position      mgl32.Vec3
+	RotationAngle float32
+	RotationAxis  mgl32.Vec3
+	Scale         mgl32.Vec3
+	Vertices      []mgl32.Vec3
+	TexCoords     []mgl32.Vec2
+	Faces         []Face
+	// RotationKeyframes, when any, replace the rotation by the one of the
+	// frame played.
+	RotationKeyframes []RotationKeyframe
+}
+
+// Face is a triangle of a node.
+type Face struct {
+	Vertices  [3]uint16
+	TexCoords [3]uint16
+	// Texture is the index of the texture in the textures of the node.
+	Texture     uint16
+	TwoSided    bool
+	SmoothGroup int32
+}
+
+// RotationKeyframe is the rotation of a node from a frame, as a
+// quaternion.
+type RotationKeyframe struct {
+	Frame    int32
+	Rotation mgl32.Quat
+}
+
+// Animated tells whether a node of f turns during its animation.
+func (f *ModelFile) Animated() bool {
+	if f.AnimLength <= 0 {
+		return false
+	}
+	for _, n := range f.Nodes {
+		if len(n.RotationKeyframes) > 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RotationAt returns the rotation of n at the frame, in milliseconds,
+// interpolated between its keyframes, and false when n has none. Past
+// the last keyframe, the rotation is the one of the last keyframe.
+func (n *Node) RotationAt(frame float32) (mgl32.Quat, bool) {
+	keyframes := n.RotationKeyframes
+	if len(keyframes) == 0 {
+		return mgl32.QuatIdent(), false
+	}
+
+	next := 0
+	for next < len(keyframes) && float32(keyframes[next].Frame) <= frame {
+		next++
+	}
+	switch next {
+	case 0:
+		return keyframes[0].Rotation.Normalize(), true
+	case len(keyframes):
+		return keyframes[next-1].Rotation.Normalize(), true
+	}
+
+	from, to := keyframes[next-1], keyframes[next]
+	t := (frame - float32(from.Frame)) / float32(to.Frame-from.Frame)
+
+	return mgl32.QuatSlerp(from.Rotation.Normalize(), to.Rotation.Normalize(), t), true
+}
+
+func Load(data []byte) (f *ModelFile, err error) {
+	f = new(ModelFile)
+	reader := bytes.NewReader(data)
+
+	var signature [4]byte
+	_ = binary.Read(reader, binary.LittleEndian, &signature)
+
+	if string(signature[:]) != HeaderSignature {
+		return nil, fmt.Errorf("invalid file header signature: %s", signature)
+	}
+
+	var a, b byte
+	_ = binary.Read(reader, binary.LittleEndian, &a)
+	_ = binary.Read(reader, binary.LittleEndian, &b)
+	f.Version = float32(a) + float32(b)/10
+
+	if f.Version >= 2.0 {
+		return nil, fmt.Errorf("unsupported rsm version %.1f", f.Version)
+	}
+
+	_ = binary.Read(reader, binary.LittleEndian, &f.AnimLength)
+	_ = binary.Read(reader, binary.LittleEndian, &f.ShadeType)
+
+	f.Alpha = 0xFF
+	if f.Version >= 1.4 {
+		_ = binary.Read(reader, binary.LittleEndian, &f.Alpha)
+	}
+
+	if err = bytesutil.SkipBytes(reader, 16); err != nil {
+		return nil, err
+	}
+
+	if err = f.loadTextures(reader); err != nil {
+		return nil, err
+	}
+
+	if err = f.loadNodes(reader); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *ModelFile) loadTextures(buf io.Reader) error {
+	count, err := readCount(buf, "texture")
+	if err != nil {
+		return err
+	}
+
+	f.Textures = make([]string, count)
+	for i := range f.Textures {
+		name, _ := bytesutil.ReadString(buf, 40)
+		f.Textures[i] = encoding.DecodeRaw([]byte(name))
+	}
+
+	return nil
+}
+
+// maxCount bounds the counts of the files, not to allocate the memory of
+// a corrupt count.
+const maxCount = 1 << 20
+
+// readCount reads a count of what.
+func readCount(buf io.Reader, what string) (int, error) {
+	var count int32
+	if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+		return 0, fmt.Errorf("could not read %s count: %w", what, err)
+	}
+	if count < 0 || count > maxCount {
+		return 0, fmt.Errorf("invalid %s count %d", what, count)
+	}
+
+	return int(count), nil
+}
+
+func (f *ModelFile) loadNodes(buf io.Reader) error {
+	name, err := bytesutil.ReadString(buf, 40)
+	if err != nil {
+		return fmt.Errorf("could not read main node name: %w", err)
+	}
+	f.MainNode = encoding.DecodeRaw([]byte(name))
+
+	count, err := readCount(buf, "node")
+	if err != nil {
+		return err
+	}
+
+	f.Nodes = make([]Node, count)
+	for i := range f.Nodes {
+		if err = f.loadNode(buf, &f.Nodes[i]); err != nil {
+			return fmt.Errorf("could not read node %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// nodeTransform is the placement of a node, as stored.
+type nodeTransform struct {
+	OffsetMatrix  [9]float32
+	Offset        [3]float32
+	Position      [3]float32
+	RotationAngle float32
+	RotationAxis  [3]float32
+	Scale         [3]float32
+}
+
+// rawFace is a face, as stored.
+type rawFace struct {
+	Vertices  [3]uint16
+	TexCoords [3]uint16
+	Texture   uint16
+	_         uint16
+	TwoSided  int32
+}
+
+func (f *ModelFile) loadNode(buf io.Reader, n *Node) error {
+	name, _ := bytesutil.ReadString(buf, 40)
+	parent, err := bytesutil.ReadString(buf, 40)
+	if err != nil {
+		return err
+	}
+	n.Name, n.Parent = encoding.DecodeRaw([]byte(name)), encoding.DecodeRaw([]byte(parent))
+
+	count, err := readCount(buf, "texture")
+	if err != nil {
+		return err
+	}
+	n.Textures = make([]int32, count)
+	if err = binary.Read(buf, binary.LittleEndian, n.Textures); err != nil {
+		return err
+	}
+
+	var t nodeTransform
+	if err = binary.Read(buf, binary.LittleEndian, &t); err != nil {
+		return err
+	}
+	n.OffsetMatrix = mgl32.Mat3(t.OffsetMatrix)
+	n.Offset, n.Position = t.Offset, t.Position
+	n.RotationAngle, n.RotationAxis, n.Scale = t.RotationAngle, t.RotationAxis, t.Scale
+
+	if count, err = readCount(buf, "vertex"); err != nil {
+		return err
+	}
+	n.Vertices = make([]mgl32.Vec3, count)
+	if err = binary.Read(buf, binary.LittleEndian, n.Vertices); err != nil {
+		return err
+	}
+
+	if count, err = readCount(buf, "texture coordinate"); err != nil {
+		return err
+	}
+	n.TexCoords = make([]mgl32.Vec2, count)
+	for i := range n.TexCoords {
+		// Colors, unused, come first from 1.2.
+		if f.Version >= 1.2 {
+			var color uint32
+			_ = binary.Read(buf, binary.LittleEndian, &color)
+		}
+		if err = binary.Read(buf, binary.LittleEndian, &n.TexCoords[i]); err != nil {
+			return err
+		}
+	}
+
+	if count, err = readCount(buf, "face"); err != nil {
+		return err
+	}
+	n.Faces = make([]Face, count)
+	for i := range n.Faces {
+		var raw rawFace
+		if err = binary.Read(buf, binary.LittleEndian, &raw); err != nil {
+			return err
+		}
+		face := Face{Vertices: raw.Vertices, TexCoords: raw.TexCoords, Texture: raw.Texture, TwoSided: raw.TwoSided != 0}
+		if f.Version >= 1.2 {
+			if err = binary.Read(buf, binary.LittleEndian, &face.SmoothGroup); err != nil {
+				return err
+			}
+		}
+		n.Faces[i] = face
+	}
+
+	// The position keyframes of the node, from 1.5, are skipped.
+	if f.Version >= 1.5 {
+		if count, err = readCount(buf, "position keyframe"); err != nil {
+			return err
+		}
+		positions := make([][4]float32, count)
+		if err = binary.Read(buf, binary.LittleEndian, positions); err != nil {
+			return err
+		}
+	}
+
+	if count, err = readCount(buf, "rotation keyframe"); err != nil {
+		return err
+	}
+	keyframes := make([]struct {
+		Frame    int32
+		Rotation [4]float32
+	}, count)
+	if err = binary.Read(buf, binary.LittleEndian, keyframes); err != nil {
+		return err
+	}
+	n.RotationKeyframes = make([]RotationKeyframe, count)
+	for i, k := range keyframes {
+		n.RotationKeyframes[i] = RotationKeyframe{
+			Frame:    k.Frame,
+			Rotation: mgl32.Quat{W: k.Rotation[3], V: mgl32.Vec3{k.Rotation[0], k.Rotation[1], k.Rotation[2]}},
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package rsm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// name returns s padded to 40 bytes.
+func name(s string) []byte {
+	b := make([]byte, 40)
+	copy(b, s)
+	return b
+}
+
+// newFile returns an RSM 1.5 file of a textured quad node.
+func newFile() []byte {
+	return rsmFile(5)
+}
+
+// rsmFile returns an RSM file of version 1.minor of a textured quad node,
+// the fields the version does not have left out.
+func rsmFile(minor byte) []byte {
+	var buf bytes.Buffer
+	w := func(v interface{}) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+
+	buf.WriteString("GRSM")
+	buf.Write([]byte{1, minor})
+	w([2]int32{0, 0})
+	if minor >= 4 {
+		buf.WriteByte(0x80)
+	}
+	buf.Write(make([]byte, 16))
+	w(int32(1))
+	buf.Write(name("wall.bmp"))
+
+	buf.Write(name("quad"))
+	w(int32(1))
+	buf.Write(name("quad"))
+	buf.Write(name(""))
+	w(int32(1))
+	w([]int32{0})
+	w(nodeTransform{
+		OffsetMatrix: [9]float32{1, 0, 0, 0, 1, 0, 0, 0, 1},
+		Position:     [3]float32{1, 2, 3},
+		RotationAxis: [3]float32{0, 1, 0},
+		Scale:        [3]float32{1, 1, 1},
+	})
+	w(int32(4))
+	w([][3]float32{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}})
+	w(int32(2))
+	if minor >= 2 {
+		w([]struct {
+			Color uint32
+			UV    [2]float32
+		}{{0, [2]float32{0, 0}}, {0, [2]float32{1, 1}}})
+	} else {
+		w([][2]float32{{0, 0}, {1, 1}})
+	}
+	w(int32(2))
+	w(rawFace{Vertices: [3]uint16{0, 1, 2}, TexCoords: [3]uint16{0, 1, 0}, TwoSided: 1})
+	if minor >= 2 {
+		w(int32(7))
+	}
+	w(rawFace{Vertices: [3]uint16{1, 3, 2}, TexCoords: [3]uint16{1, 1, 0}})
+	if minor >= 2 {
+		w(int32(0))
+	}
+	// A position keyframe, then a rotation one.
+	if minor >= 5 {
+		w(int32(1))
+		w([4]float32{0, 1, 2, 3})
+	}
+	w(int32(1))
+	w(int32(0))
+	w([4]float32{0, 0, 0, 1})
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	f, err := Load(newFile())
+	require.NoError(t, err)
+
+	assert.Equal(t, float32(1.5), f.Version)
+	assert.Equal(t, []string{"wall.bmp"}, f.Textures)
+	assert.Equal(t, "quad", f.MainNode)
+	require.Len(t, f.Nodes, 1)
+
+	n := f.Nodes[0]
+	assert.Equal(t, "quad", n.Name)
+	assert.Equal(t, "", n.Parent)
+	assert.Equal(t, []int32{0}, n.Textures)
+	assert.Equal(t, mgl32.Ident3(), n.OffsetMatrix)
+	assert.Equal(t, mgl32.Vec3{1, 2, 3}, n.Position)
+	assert.Len(t, n.Vertices, 4)
+	assert.Equal(t, []mgl32.Vec2{{0, 0}, {1, 1}}, n.TexCoords)
+	assert.Equal(t, []Face{
+		{Vertices: [3]uint16{0, 1, 2}, TexCoords: [3]uint16{0, 1, 0}, TwoSided: true, SmoothGroup: 7},
+		{Vertices: [3]uint16{1, 3, 2}, TexCoords: [3]uint16{1, 1, 0}},
+	}, n.Faces)
+	assert.Equal(t, []RotationKeyframe{{Rotation: mgl32.QuatIdent()}}, n.RotationKeyframes)
+}
+
+func TestLoadVersions(t *testing.T) {
+	for _, tt := range []struct {
+		Name        string
+		Minor       byte
+		Alpha       uint8
+		SmoothGroup int32
+	}{
+		{"1.1 has neither alpha, colors nor smooth groups", 1, 0xFF, 0},
+		{"1.3 has no alpha", 3, 0xFF, 7},
+		{"1.4 has no position keyframes", 4, 0x80, 7},
+		{"1.5", 5, 0x80, 7},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			f, err := Load(rsmFile(tt.Minor))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.Alpha, f.Alpha)
+			require.Len(t, f.Nodes, 1)
+			n := f.Nodes[0]
+			assert.Equal(t, []mgl32.Vec2{{0, 0}, {1, 1}}, n.TexCoords)
+			require.Len(t, n.Faces, 2)
+			assert.Equal(t, tt.SmoothGroup, n.Faces[0].SmoothGroup)
+			assert.Equal(t, []RotationKeyframe{{Rotation: mgl32.QuatIdent()}}, n.RotationKeyframes)
+		})
+	}
+}
+
+func TestLoadUnsupported(t *testing.T) {
+	_, err := Load([]byte("GRSW\x01\x05"))
+	assert.Error(t, err)
+
+	_, err = Load([]byte("GRSM\x02\x02"))
+	assert.Error(t, err)
+}
+
+func TestLoadTruncated(t *testing.T) {
+	data := newFile()
+	for _, n := range []int{len(data) - 4, 30, 100} {
+		_, err := Load(data[:n])
+		assert.Error(t, err, "%d bytes", n)
+	}
+}
+
+func TestLoadInvalidCount(t *testing.T) {
+	data := newFile()
+	// The texture count follows the header.
+	binary.LittleEndian.PutUint32(data[31:], 1<<30)
+
+	_, err := Load(data)
+	assert.Error(t, err)
+}
+
+func TestRotationAt(t *testing.T) {
+	quarter := mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 1, 0})
+	n := Node{RotationKeyframes: []RotationKeyframe{
+		{Frame: 100, Rotation: mgl32.QuatIdent()},
+		{Frame: 300, Rotation: quarter},
+	}}
+
+	for frame, want := range map[float32]mgl32.Quat{
+		0:   mgl32.QuatIdent(),
+		100: mgl32.QuatIdent(),
+		200: mgl32.QuatRotate(mgl32.DegToRad(45), mgl32.Vec3{0, 1, 0}),
+		300: quarter,
+		900: quarter,
+	} {
+		got, ok := n.RotationAt(frame)
+		require.True(t, ok)
+		assert.True(t, want.ApproxEqualThreshold(got, 1e-5), "frame %g: %v", frame, got)
+	}
+
+	_, ok := (&Node{}).RotationAt(0)
+	assert.False(t, ok)
+
+	f := &ModelFile{AnimLength: 300, Nodes: []Node{{}, n}}
+	assert.True(t, f.Animated())
+	f.AnimLength = 0
+	assert.False(t, f.Animated())
+}
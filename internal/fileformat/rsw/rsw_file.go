@@ -0,0 +1,262 @@
+package rsw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/bytesutil"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+const HeaderSignature = "GRSW"
+
+type ObjectType int32
+
+const (
+	ObjectTypeModel  = ObjectType(1)
+	ObjectTypeLight  = ObjectType(2)
+	ObjectTypeSound  = ObjectType(3)
+	ObjectTypeEffect = ObjectType(4)
+)
+
+type Water struct {
+	Level      float32
+	Type       int32
+	WaveHeight float32
+	WaveSpeed  float32
+	WavePitch  float32
+	AnimSpeed  int32
+}
+
+type Light struct {
+	Longitude int32
+	Latitude  int32
+	Diffuse   mgl32.Vec3
+	Ambient   mgl32.Vec3
+	Opacity   float32
+}
+
+type Model struct {
+	Name      string
+	AnimType  int32
+	AnimSpeed float32
+	BlockType int32
+	FileName  string
+	NodeName  string
+	Position  mgl32.Vec3
+	Rotation  mgl32.Vec3
+	Scale     mgl32.Vec3
+}
+
+type LightSource struct {
+	Name     string
+	Position mgl32.Vec3
+	Color    [3]int32
+	Range    float32
+}
+
+type Sound struct {
+	Name     string
+	FileName string
+	Position mgl32.Vec3
+	Volume   float32
+	Width    int32
+	Height   int32
+	Range    float32
+	Cycle    float32
+}
+
+type Effect struct {
+	Name      string
+	Position  mgl32.Vec3
+	ID        int32
+	EmitSpeed float32
+	Params    [4]float32
+}
+
+// ResourceWorldFile is a map world file: the ground, altitude and
+// objects (models, lights, sounds and effects) of a map.
+type ResourceWorldFile struct {
+	Version float32
+
+	IniFile      string
+	GroundFile   string
+	AltitudeFile string
+	SourceFile   string
+
+	Water Water
+	Light Light
+
+	// Ground bounds (top, bottom, left, right).
+	Ground [4]int32
+
+	Models       []Model
+	LightSources []LightSource
+	Sounds       []Sound
+	Effects      []Effect
+}
+
+func Load(data []byte) (f *ResourceWorldFile, err error) {
+	f = new(ResourceWorldFile)
+	reader := bytes.NewReader(data)
+
+	var signature [4]byte
+	_ = binary.Read(reader, binary.LittleEndian, &signature)
+
+	if string(signature[:]) != HeaderSignature {
+		return nil, fmt.Errorf("invalid file header signature: %s", signature)
+	}
+
+	var a, b byte
+	_ = binary.Read(reader, binary.LittleEndian, &a)
+	_ = binary.Read(reader, binary.LittleEndian, &b)
+	f.Version = float32(a) + float32(b)/10
+
+	if f.Version >= 2.6 {
+		return nil, fmt.Errorf("unsupported rsw version %.1f", f.Version)
+	}
+
+	// Build number of the newer map editors.
+	if f.Version >= 2.5 {
+		_ = bytesutil.SkipBytes(reader, 5)
+	} else if f.Version >= 2.2 {
+		_ = bytesutil.SkipBytes(reader, 1)
+	}
+
+	f.IniFile = readName(reader, 40)
+	f.GroundFile = readName(reader, 40)
+	if f.Version >= 1.4 {
+		f.AltitudeFile = readName(reader, 40)
+	}
+	f.SourceFile = readName(reader, 40)
+
+	f.loadWater(reader)
+	f.loadLight(reader)
+
+	if f.Version >= 1.6 {
+		_ = binary.Read(reader, binary.LittleEndian, &f.Ground)
+	}
+
+	if err = f.loadObjects(reader); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *ResourceWorldFile) loadWater(buf io.Reader) {
+	if f.Version >= 1.3 {
+		_ = binary.Read(buf, binary.LittleEndian, &f.Water.Level)
+	}
+
+	if f.Version >= 1.8 {
+		_ = binary.Read(buf, binary.LittleEndian, &f.Water.Type)
+		_ = binary.Read(buf, binary.LittleEndian, &f.Water.WaveHeight)
+		_ = binary.Read(buf, binary.LittleEndian, &f.Water.WaveSpeed)
+		_ = binary.Read(buf, binary.LittleEndian, &f.Water.WavePitch)
+	}
+
+	if f.Version >= 1.9 {
+		_ = binary.Read(buf, binary.LittleEndian, &f.Water.AnimSpeed)
+	}
+}
+
+func (f *ResourceWorldFile) loadLight(buf io.Reader) {
+	f.Light.Opacity = 1
+
+	if f.Version >= 1.5 {
+		_ = binary.Read(buf, binary.LittleEndian, &f.Light.Longitude)
+		_ = binary.Read(buf, binary.LittleEndian, &f.Light.Latitude)
+		_ = binary.Read(buf, binary.LittleEndian, &f.Light.Diffuse)
+		_ = binary.Read(buf, binary.LittleEndian, &f.Light.Ambient)
+	}
+
+	if f.Version >= 1.7 {
+		_ = binary.Read(buf, binary.LittleEndian, &f.Light.Opacity)
+	}
+}
+
+func (f *ResourceWorldFile) loadObjects(buf io.Reader) error {
+	var count int32
+	if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+		return fmt.Errorf("could not read object count: %w", err)
+	}
+
+	for i := 0; i < int(count); i++ {
+		var t ObjectType
+		if err := binary.Read(buf, binary.LittleEndian, &t); err != nil {
+			return fmt.Errorf("could not read object %d: %w", i, err)
+		}
+
+		switch t {
+		case ObjectTypeModel:
+			var m Model
+			if f.Version >= 1.3 {
+				m.Name = readName(buf, 40)
+				_ = binary.Read(buf, binary.LittleEndian, &m.AnimType)
+				_ = binary.Read(buf, binary.LittleEndian, &m.AnimSpeed)
+				_ = binary.Read(buf, binary.LittleEndian, &m.BlockType)
+			}
+			m.FileName = readName(buf, 80)
+			m.NodeName = readName(buf, 80)
+			_ = binary.Read(buf, binary.LittleEndian, &m.Position)
+			_ = binary.Read(buf, binary.LittleEndian, &m.Rotation)
+			if err := binary.Read(buf, binary.LittleEndian, &m.Scale); err != nil {
+				return fmt.Errorf("could not read model %d: %w", i, err)
+			}
+			f.Models = append(f.Models, m)
+		case ObjectTypeLight:
+			var l LightSource
+			l.Name = readName(buf, 80)
+			_ = binary.Read(buf, binary.LittleEndian, &l.Position)
+			_ = binary.Read(buf, binary.LittleEndian, &l.Color)
+			if err := binary.Read(buf, binary.LittleEndian, &l.Range); err != nil {
+				return fmt.Errorf("could not read light %d: %w", i, err)
+			}
+			f.LightSources = append(f.LightSources, l)
+		case ObjectTypeSound:
+			var s Sound
+			s.Name = readName(buf, 80)
+			s.FileName = readName(buf, 80)
+			_ = binary.Read(buf, binary.LittleEndian, &s.Position)
+			_ = binary.Read(buf, binary.LittleEndian, &s.Volume)
+			_ = binary.Read(buf, binary.LittleEndian, &s.Width)
+			_ = binary.Read(buf, binary.LittleEndian, &s.Height)
+			if err := binary.Read(buf, binary.LittleEndian, &s.Range); err != nil {
+				return fmt.Errorf("could not read sound %d: %w", i, err)
+			}
+			s.Cycle = 4
+			if f.Version >= 2.0 {
+				if err := binary.Read(buf, binary.LittleEndian, &s.Cycle); err != nil {
+					return fmt.Errorf("could not read sound %d: %w", i, err)
+				}
+			}
+			f.Sounds = append(f.Sounds, s)
+		case ObjectTypeEffect:
+			var e Effect
+			e.Name = readName(buf, 80)
+			_ = binary.Read(buf, binary.LittleEndian, &e.Position)
+			_ = binary.Read(buf, binary.LittleEndian, &e.ID)
+			_ = binary.Read(buf, binary.LittleEndian, &e.EmitSpeed)
+			if err := binary.Read(buf, binary.LittleEndian, &e.Params); err != nil {
+				return fmt.Errorf("could not read effect %d: %w", i, err)
+			}
+			f.Effects = append(f.Effects, e)
+		default:
+			return fmt.Errorf("unknown object type %d at object %d", t, i)
+		}
+	}
+
+	return nil
+}
+
+// readName reads a fixed length file name, decoded the same way as the
+// GRF entry names.
+func readName(buf io.Reader, length int) string {
+	name, _ := bytesutil.ReadString(buf, length)
+	return encoding.DecodeRaw([]byte(name))
+}
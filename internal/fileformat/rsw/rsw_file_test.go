@@ -0,0 +1,174 @@
+package rsw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// name returns s padded to length bytes.
+func name(s string, length int) []byte {
+	b := make([]byte, length)
+	copy(b, s)
+	return b
+}
+
+// rswFile returns an RSW file of version major.minor of a model, a light,
+// a sound and an effect, the fields the version does not have left out.
+func rswFile(major, minor byte) []byte {
+	version := float32(major) + float32(minor)/10
+	buf := &bytes.Buffer{}
+	write := func(v ...interface{}) {
+		for _, v := range v {
+			_ = binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+
+	write([]byte(HeaderSignature), []byte{major, minor})
+	if version >= 2.5 {
+		write([5]byte{})
+	} else if version >= 2.2 {
+		write(byte(0))
+	}
+
+	write(name("prontera.ini", 40), name("prontera.gnd", 40))
+	if version >= 1.4 {
+		write(name("prontera.gat", 40))
+	}
+	write(name("prontera.src", 40))
+
+	if version >= 1.3 {
+		write(float32(-1))
+	}
+	if version >= 1.8 {
+		write(int32(2), float32(1), float32(2), float32(50))
+	}
+	if version >= 1.9 {
+		write(int32(3))
+	}
+
+	if version >= 1.5 {
+		write(int32(45), int32(60), [3]float32{1, 1, 1}, [3]float32{.3, .3, .3})
+	}
+	if version >= 1.7 {
+		write(float32(.5))
+	}
+	if version >= 1.6 {
+		write([4]int32{-500, 500, -500, 500})
+	}
+
+	write(int32(4))
+
+	write(ObjectTypeModel)
+	if version >= 1.3 {
+		write(name("fountain", 40), int32(0), float32(1), int32(0))
+	}
+	write(name("fountain.rsm", 80), name("", 80))
+	write([3]float32{1, 2, 3}, [3]float32{0, 90, 0}, [3]float32{1, 1, 1})
+
+	write(ObjectTypeLight, name("torch", 80), [3]float32{4, 5, 6}, [3]int32{255, 128, 0}, float32(20))
+
+	write(ObjectTypeSound, name("birds", 80), name("birds.wav", 80))
+	write([3]float32{7, 8, 9}, float32(.8), int32(10), int32(10), float32(100))
+	if version >= 2.0 {
+		write(float32(6))
+	}
+
+	write(ObjectTypeEffect, name("smoke", 80), [3]float32{1, 1, 1}, int32(44), float32(.5), [4]float32{1, 2, 3, 4})
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	f, err := Load(rswFile(2, 1))
+	require.NoError(t, err)
+
+	assert.Equal(t, float32(2.1), f.Version)
+	assert.Equal(t, "prontera.gnd", f.GroundFile)
+	assert.Equal(t, "prontera.gat", f.AltitudeFile)
+	assert.Equal(t, "prontera.src", f.SourceFile)
+	assert.Equal(t, Water{Level: -1, Type: 2, WaveHeight: 1, WaveSpeed: 2, WavePitch: 50, AnimSpeed: 3}, f.Water)
+	assert.Equal(t, float32(.5), f.Light.Opacity)
+	assert.Equal(t, [4]int32{-500, 500, -500, 500}, f.Ground)
+
+	require.Len(t, f.Models, 1)
+	assert.Equal(t, "fountain", f.Models[0].Name)
+	assert.Equal(t, "fountain.rsm", f.Models[0].FileName)
+	assert.Equal(t, mgl32.Vec3{1, 2, 3}, f.Models[0].Position)
+
+	require.Len(t, f.LightSources, 1)
+	assert.Equal(t, [3]int32{255, 128, 0}, f.LightSources[0].Color)
+
+	require.Len(t, f.Sounds, 1)
+	assert.Equal(t, "birds.wav", f.Sounds[0].FileName)
+	assert.Equal(t, float32(6), f.Sounds[0].Cycle)
+
+	require.Len(t, f.Effects, 1)
+	assert.Equal(t, int32(44), f.Effects[0].ID)
+	assert.Equal(t, [4]float32{1, 2, 3, 4}, f.Effects[0].Params)
+}
+
+func TestLoadVersions(t *testing.T) {
+	for _, tt := range []struct {
+		Name         string
+		Major, Minor byte
+		AltitudeFile string
+		WaterLevel   float32
+		Opacity      float32
+		ModelName    string
+		SoundCycle   float32
+	}{
+		{"1.2 has neither altitude file, water nor light", 1, 2, "", 0, 1, "", 4},
+		{"1.9 has no sound cycle", 1, 9, "prontera.gat", -1, .5, "fountain", 4},
+		{"2.2 has a build number", 2, 2, "prontera.gat", -1, .5, "fountain", 6},
+		{"2.5 has a longer build number", 2, 5, "prontera.gat", -1, .5, "fountain", 6},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			f, err := Load(rswFile(tt.Major, tt.Minor))
+			require.NoError(t, err)
+
+			assert.Equal(t, "prontera.gnd", f.GroundFile)
+			assert.Equal(t, tt.AltitudeFile, f.AltitudeFile)
+			assert.Equal(t, tt.WaterLevel, f.Water.Level)
+			assert.Equal(t, tt.Opacity, f.Light.Opacity)
+			require.Len(t, f.Models, 1)
+			assert.Equal(t, tt.ModelName, f.Models[0].Name)
+			assert.Equal(t, "fountain.rsm", f.Models[0].FileName)
+			require.Len(t, f.Sounds, 1)
+			assert.Equal(t, tt.SoundCycle, f.Sounds[0].Cycle)
+			require.Len(t, f.Effects, 1)
+		})
+	}
+}
+
+func TestLoadUnsupported(t *testing.T) {
+	_, err := Load([]byte("GRSM\x02\x01"))
+	assert.Error(t, err)
+
+	_, err = Load(rswFile(2, 6))
+	assert.Error(t, err)
+}
+
+func TestLoadTruncated(t *testing.T) {
+	data := rswFile(2, 1)
+
+	for _, n := range []int{len(data) - 4, 200} {
+		_, err := Load(data[:n])
+		assert.Error(t, err, "%d bytes", n)
+	}
+}
+
+func TestLoadUnknownObjectType(t *testing.T) {
+	data := rswFile(1, 2)
+	// The object count, then the type of the first object, the model.
+	i := bytes.Index(data, []byte{4, 0, 0, 0, 1, 0, 0, 0})
+	require.NotEqual(t, -1, i)
+	data[i+4] = 9
+
+	_, err := Load(data)
+	assert.Error(t, err)
+}
@@ -6,10 +6,11 @@ import (
 	"fmt"
 	"github.com/project-midgard/midgarts/internal/bytesutil"
 	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
 	"github.com/project-midgard/midgarts/internal/graphic"
 	"image"
-	"image/color"
 	"io"
+	"sync"
 )
 
 type FileType uint32
@@ -41,7 +42,20 @@ type SpriteFile struct {
 
 	Frames  []*SpriteFrame
 	Palette [PaletteSize]byte
-	Images  []*graphic.UniqueRGBA
+	// Images holds the frames decoded by ImageAt, with the current palette.
+	Images []*graphic.UniqueRGBA
+
+	// variants holds the paletted frames decoded with the other palettes
+	// applied, see ApplyPalette.
+	variants map[[PaletteSize]byte][]*graphic.UniqueRGBA
+	// atlases holds the atlas of the frames with each palette applied,
+	// see Atlas.
+	atlases map[[PaletteSize]byte]*graphic.Atlas
+
+	// mu guards dyed, the sprites of WithPalette, made on the loading
+	// goroutines.
+	mu   sync.Mutex
+	dyed map[[PaletteSize]byte]*SpriteFile
 }
 
 func Load(data []byte) (f *SpriteFile, err error) {
@@ -230,6 +244,116 @@ func (f *SpriteFile) readRGBAFrames(buf io.ReadSeeker) error {
 	return nil
 }
 
+// ApplyPalette replaces the palette of the sprite, e.g. with a hair or
+// clothes dye. The paletted frames decoded with the previous palette are
+// kept, and reused when it is applied again.
+func (f *SpriteFile) ApplyPalette(p *pal.PaletteFile) {
+	if p.Palette == f.Palette {
+		return
+	}
+
+	n := int(f.Header.PalettedFrameCount)
+	if f.variants == nil {
+		f.variants = map[[PaletteSize]byte][]*graphic.UniqueRGBA{}
+	}
+	f.variants[f.Palette] = append([]*graphic.UniqueRGBA(nil), f.Images[:n]...)
+
+	f.Palette = p.Palette
+
+	images := f.variants[f.Palette]
+	delete(f.variants, f.Palette)
+	for i := 0; i < n; i++ {
+		if images != nil {
+			f.Images[i] = images[i]
+		} else {
+			f.Images[i] = nil
+		}
+	}
+}
+
+// WithPalette returns the sprite with the palette of p, e.g. a hair or
+// clothes dye, leaving f as is: unlike ApplyPalette, the characters sharing
+// f can be drawn with different palettes. The sprite of a palette is made
+// once, sharing the frames of f, and its decoded images are returned by the
+// DecodedImages of f.
+func (f *SpriteFile) WithPalette(p *pal.PaletteFile) *SpriteFile {
+	if p.Palette == f.Palette {
+		return f
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if dyed, ok := f.dyed[p.Palette]; ok {
+		return dyed
+	}
+
+	dyed := &SpriteFile{
+		Header:  f.Header,
+		Frames:  f.Frames,
+		Palette: p.Palette,
+		Images:  make([]*graphic.UniqueRGBA, len(f.Frames)),
+	}
+	if f.dyed == nil {
+		f.dyed = map[[PaletteSize]byte]*SpriteFile{}
+	}
+	f.dyed[p.Palette] = dyed
+
+	return dyed
+}
+
+// Atlas returns the atlas of the frames with the current palette, packed
+// once.
+func (f *SpriteFile) Atlas() *graphic.Atlas {
+	if a, ok := f.atlases[f.Palette]; ok {
+		return a
+	}
+
+	images := make([]*graphic.UniqueRGBA, len(f.Frames))
+	for i := range f.Frames {
+		images[i] = f.ImageAt(character.SpriteIndex(i))
+	}
+
+	if f.atlases == nil {
+		f.atlases = map[[PaletteSize]byte]*graphic.Atlas{}
+	}
+	a := graphic.NewAtlas(images)
+	f.atlases[f.Palette] = a
+
+	return a
+}
+
+// DecodedImages returns the frames decoded so far, with every palette
+// applied, and their atlases.
+func (f *SpriteFile) DecodedImages() []*graphic.UniqueRGBA {
+	var images []*graphic.UniqueRGBA
+	for _, a := range f.atlases {
+		images = append(images, a.Image)
+	}
+	for _, img := range f.Images {
+		if img != nil {
+			images = append(images, img)
+		}
+	}
+
+	for _, variant := range f.variants {
+		for _, img := range variant {
+			if img != nil {
+				images = append(images, img)
+			}
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, dyed := range f.dyed {
+		images = append(images, dyed.DecodedImages()...)
+	}
+
+	return images
+}
+
+// ImageAt returns the frame at index, decoded once.
 func (f *SpriteFile) ImageAt(index character.SpriteIndex) *graphic.UniqueRGBA {
 	if f.Images[index] != nil {
 		return f.Images[index]
@@ -247,36 +371,27 @@ func (f *SpriteFile) ImageAt(index character.SpriteIndex) *graphic.UniqueRGBA {
 	}
 
 	img := graphic.NewUniqueRGBA(image.Rect(0, 0, width, height))
+	pix := img.Pix
 
 	if frame.SpriteType == FileTypeRGBA {
-		for y := 0; y < height; y++ {
-			for x := 0; x < width; x++ {
-				i := (x + y*width) * 4
-
-				img.Set(x, y, color.RGBA{
-					R: data[i+3],
-					G: data[i+2],
-					B: data[i+1],
-					A: data[i+0],
-				})
-			}
+		// Stored as ABGR.
+		for i := 0; i < width*height*4; i += 4 {
+			pix[i+0] = data[i+3]
+			pix[i+1] = data[i+2]
+			pix[i+2] = data[i+1]
+			pix[i+3] = data[i+0]
 		}
 	} else {
-		for y := 0; y < height; y++ {
-			for x := 0; x < width; x++ {
-				i := int(frame.Data[x+y*width]) * 4
-				var a byte
-
-				if i != 0 {
-					a = 255
-				}
-
-				img.Set(x, y, color.RGBA{
-					R: f.Palette[i+0],
-					G: f.Palette[i+1],
-					B: f.Palette[i+2],
-					A: a,
-				})
+		for p := 0; p < width*height; p++ {
+			i := int(data[p]) * 4
+			o := p * 4
+
+			pix[o+0] = f.Palette[i+0]
+			pix[o+1] = f.Palette[i+1]
+			pix[o+2] = f.Palette[i+2]
+			// The first color of the palette is the transparent one.
+			if i != 0 {
+				pix[o+3] = 255
 			}
 		}
 	}
@@ -1,7 +1,107 @@
 package spr_test
 
-import "testing"
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/graphic"
+)
 
 func TestNewFile(t *testing.T) {
 
 }
+
+// newSprite returns a sprite of a 2x1 paletted frame and a 1x1 RGBA one.
+func newSprite() *spr.SpriteFile {
+	f := &spr.SpriteFile{
+		Frames: []*spr.SpriteFrame{
+			{SpriteType: spr.FileTypePAL, Width: 2, Height: 1, Data: []byte{0, 1}},
+			{SpriteType: spr.FileTypeRGBA, Width: 1, Height: 1, Data: []byte{0x80, 3, 2, 1}},
+		},
+		Images: make([]*graphic.UniqueRGBA, 2),
+	}
+	f.Header.PalettedFrameCount = 1
+	copy(f.Palette[:], []byte{9, 9, 9, 0, 10, 20, 30, 0})
+
+	return f
+}
+
+func TestImageAt(t *testing.T) {
+	f := newSprite()
+
+	img := f.ImageAt(0)
+	require.NotNil(t, img)
+	assert.Equal(t, color.RGBA{R: 9, G: 9, B: 9, A: 0}, img.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{R: 10, G: 20, B: 30, A: 255}, img.RGBAAt(1, 0))
+	assert.Same(t, img, f.ImageAt(0))
+
+	assert.Equal(t, color.RGBA{R: 1, G: 2, B: 3, A: 0x80}, f.ImageAt(1).RGBAAt(0, 0))
+}
+
+func TestApplyPalette(t *testing.T) {
+	f := newSprite()
+	original := f.ImageAt(0)
+	rgba := f.ImageAt(1)
+
+	dye := &pal.PaletteFile{}
+	copy(dye.Palette[:], []byte{0, 0, 0, 0, 200, 100, 50, 0})
+	previous := &pal.PaletteFile{Palette: f.Palette}
+
+	f.ApplyPalette(dye)
+	dyed := f.ImageAt(0)
+	assert.NotSame(t, original, dyed)
+	assert.Equal(t, color.RGBA{R: 200, G: 100, B: 50, A: 255}, dyed.RGBAAt(1, 0))
+	assert.Same(t, rgba, f.ImageAt(1), "RGBA frames do not use the palette")
+
+	f.ApplyPalette(previous)
+	assert.Same(t, original, f.ImageAt(0))
+
+	f.ApplyPalette(dye)
+	assert.Same(t, dyed, f.ImageAt(0))
+
+	assert.ElementsMatch(t, []*graphic.UniqueRGBA{original, dyed, rgba}, f.DecodedImages())
+}
+
+func TestAtlas(t *testing.T) {
+	f := newSprite()
+
+	a := f.Atlas()
+	require.Len(t, a.Rects, 2)
+	assert.Same(t, a, f.Atlas())
+	assert.Equal(t, f.ImageAt(0).RGBAAt(1, 0), a.Image.RGBAAt(a.Rects[0].Min.X+1, a.Rects[0].Min.Y))
+
+	dye := &pal.PaletteFile{}
+	copy(dye.Palette[:], []byte{0, 0, 0, 0, 200, 100, 50, 0})
+	f.ApplyPalette(dye)
+
+	dyed := f.Atlas()
+	assert.NotSame(t, a, dyed)
+	assert.Equal(t, color.RGBA{R: 200, G: 100, B: 50, A: 255}, dyed.Image.RGBAAt(dyed.Rects[0].Min.X+1, dyed.Rects[0].Min.Y))
+	assert.Subset(t, f.DecodedImages(), []*graphic.UniqueRGBA{a.Image, dyed.Image})
+}
+
+func TestWithPalette(t *testing.T) {
+	f := newSprite()
+	original := f.Atlas()
+
+	dye := &pal.PaletteFile{}
+	copy(dye.Palette[:], []byte{0, 0, 0, 0, 200, 100, 50, 0})
+
+	dyed := f.WithPalette(dye)
+	assert.NotSame(t, f, dyed)
+	assert.Same(t, dyed, f.WithPalette(dye))
+	assert.Same(t, f, f.WithPalette(&pal.PaletteFile{Palette: f.Palette}))
+
+	a := dyed.Atlas()
+	assert.Equal(t, color.RGBA{R: 200, G: 100, B: 50, A: 255}, a.Image.RGBAAt(a.Rects[0].Min.X+1, a.Rects[0].Min.Y))
+	// f keeps its palette.
+	assert.Same(t, original, f.Atlas())
+	assert.Equal(t, color.RGBA{R: 10, G: 20, B: 30, A: 255}, f.ImageAt(0).RGBAAt(1, 0))
+
+	assert.Subset(t, f.DecodedImages(), []*graphic.UniqueRGBA{original.Image, a.Image, dyed.ImageAt(0)})
+}
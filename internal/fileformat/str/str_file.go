@@ -0,0 +1,267 @@
+// Package str reads the STR files of the effects, as found in
+// data/texture/effect: layers of textured quads animated by keyframes,
+// played at a number of frames per second.
+package str
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/bytesutil"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+const (
+	HeaderSignature = "STRM"
+	// Version is the only version of the STR files.
+	Version = 0x94
+)
+
+// Center is the point of the canvas of the effects the positions of the
+// keyframes are relative to, in pixels, their y growing down.
+var Center = mgl32.Vec2{320, 320}
+
+// KeyframeType tells a keyframe setting the state of a layer from one
+// adding to it on every frame.
+type KeyframeType uint32
+
+const (
+	// KeyframeBase sets the state of the layer.
+	KeyframeBase = KeyframeType(0)
+	// KeyframeMorph adds its values to the ones of the base keyframe before
+	// it on every frame.
+	KeyframeMorph = KeyframeType(1)
+)
+
+// TextureAnimation is how a morph keyframe goes through the textures of a
+// layer.
+type TextureAnimation uint32
+
+const (
+	TextureAnimationNone = TextureAnimation(iota)
+	// TextureAnimationNormal adds TextureFrame on every frame.
+	TextureAnimationNormal
+	// TextureAnimationStop adds Delay on every frame, stopping at the last
+	// texture, TextureAnimationRepeat loops back to the first one and
+	// TextureAnimationReverse goes backwards, looping.
+	TextureAnimationStop
+	TextureAnimationRepeat
+	TextureAnimationReverse
+)
+
+// Keyframe is a keyframe of a layer, as stored in the files.
+type Keyframe struct {
+	Frame int32
+	Type  KeyframeType
+	// Position is the position of the quad on the canvas, see Center.
+	Position mgl32.Vec2
+	UV       [8]float32
+	// XY are the x of the corners of the quad, then their y, relative to
+	// Position.
+	XY               [8]float32
+	TextureFrame     float32
+	TextureAnimation TextureAnimation
+	Delay            float32
+	// Angle is the rotation of the quad, in 1024ths of a turn.
+	Angle float32
+	// Color is the color of the quad, and its opacity, from 0 to 255.
+	Color [4]float32
+	// SourceBlend and DestinationBlend are the Direct3D blend factors of the
+	// quad.
+	SourceBlend        uint32
+	DestinationBlend   uint32
+	MultiTexturePreset uint32
+}
+
+// Corner returns the i-th corner of the quad of k.
+func (k *Keyframe) Corner(i int) mgl32.Vec2 {
+	return mgl32.Vec2{k.XY[i], k.XY[i+4]}
+}
+
+// Layer is a quad of the effect, drawn with its textures.
+type Layer struct {
+	Textures  []string
+	Keyframes []Keyframe
+}
+
+type EffectFile struct {
+	Version uint32
+	// FPS is the number of frames per second, and MaxKey the number of
+	// frames.
+	FPS    uint32
+	MaxKey uint32
+	Layers []Layer
+}
+
+func Load(data []byte) (f *EffectFile, err error) {
+	f = new(EffectFile)
+	reader := bytes.NewReader(data)
+
+	var signature [4]byte
+	_ = binary.Read(reader, binary.LittleEndian, &signature)
+
+	if string(signature[:]) != HeaderSignature {
+		return nil, fmt.Errorf("invalid file header signature: %s", signature)
+	}
+
+	var header struct {
+		Version, FPS, MaxKey, LayerCount uint32
+	}
+	if err = binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("could not read header: %w", err)
+	}
+	if header.Version != Version {
+		return nil, fmt.Errorf("unsupported str version 0x%x", header.Version)
+	}
+	f.Version, f.FPS, f.MaxKey = header.Version, header.FPS, header.MaxKey
+
+	if err = bytesutil.SkipBytes(reader, 16); err != nil {
+		return nil, err
+	}
+
+	// A layer takes 8 bytes at least.
+	if int64(header.LayerCount) > int64(reader.Len()/8) {
+		return nil, fmt.Errorf("invalid layer count %d", header.LayerCount)
+	}
+	f.Layers = make([]Layer, header.LayerCount)
+	for i := range f.Layers {
+		if err = f.Layers[i].load(reader); err != nil {
+			return nil, fmt.Errorf("could not read layer %d: %w", i, err)
+		}
+	}
+
+	return f, nil
+}
+
+func (l *Layer) load(reader *bytes.Reader) error {
+	var count int32
+	if err := binary.Read(reader, binary.LittleEndian, &count); err != nil {
+		return fmt.Errorf("could not read texture count: %w", err)
+	}
+	if count < 0 || int(count) > reader.Len()/128 {
+		return fmt.Errorf("invalid texture count %d", count)
+	}
+
+	l.Textures = make([]string, count)
+	for i := range l.Textures {
+		name, _ := bytesutil.ReadString(reader, 128)
+		l.Textures[i] = encoding.DecodeRaw([]byte(name))
+	}
+
+	if err := binary.Read(reader, binary.LittleEndian, &count); err != nil {
+		return fmt.Errorf("could not read keyframe count: %w", err)
+	}
+	if count < 0 || int(count) > reader.Len()/binary.Size(Keyframe{}) {
+		return fmt.Errorf("invalid keyframe count %d", count)
+	}
+
+	l.Keyframes = make([]Keyframe, count)
+	if err := binary.Read(reader, binary.LittleEndian, l.Keyframes); err != nil && err != io.EOF {
+		return fmt.Errorf("could not read keyframes: %w", err)
+	}
+
+	return nil
+}
+
+// Duration returns the time the effect plays.
+func (f *EffectFile) Duration() time.Duration {
+	if f.FPS == 0 {
+		return 0
+	}
+
+	return time.Duration(f.MaxKey) * time.Second / time.Duration(f.FPS)
+}
+
+// State is the quad of a layer at a frame.
+type State struct {
+	// Position is the position of the quad on the canvas, see Center, and
+	// Corners the ones of its corners relative to it.
+	Position mgl32.Vec2
+	Corners  [4]mgl32.Vec2
+	// Angle is the rotation of the quad, in radians.
+	Angle float32
+	// Color is the color and the opacity of the quad, from 0 to 1.
+	Color mgl32.Vec4
+	// Texture is the index of the texture of the layer drawn.
+	Texture int
+}
+
+// StateAt returns the state of l at frame, and whether it is drawn: from
+// the first base keyframe to the last keyframe of the layer. The last base
+// keyframe up to frame gives the state, the last morph keyframe after it
+// adding to it once per frame since.
+func (l *Layer) StateAt(frame int) (State, bool) {
+	from, to := -1, -1
+	last := int32(math.MinInt32)
+	for i, k := range l.Keyframes {
+		if int(k.Frame) <= frame {
+			switch k.Type {
+			case KeyframeBase:
+				from = i
+			case KeyframeMorph:
+				to = i
+			}
+		}
+		if k.Frame > last {
+			last = k.Frame
+		}
+	}
+	if from < 0 || frame > int(last) {
+		return State{}, false
+	}
+
+	base := &l.Keyframes[from]
+	var morph Keyframe
+	if to > from {
+		morph = l.Keyframes[to]
+	}
+	delta := float32(frame - int(base.Frame))
+
+	s := State{
+		Position: base.Position.Add(morph.Position.Mul(delta)),
+		Angle:    (base.Angle + morph.Angle*delta) / 1024 * 2 * math.Pi,
+		Texture:  l.texture(base, &morph, delta),
+	}
+	for i := range s.Corners {
+		s.Corners[i] = base.Corner(i).Add(morph.Corner(i).Mul(delta))
+	}
+	for i := range s.Color {
+		s.Color[i] = mgl32.Clamp((base.Color[i]+morph.Color[i]*delta)/255, 0, 1)
+	}
+
+	return s, true
+}
+
+// texture returns the index of the texture of the layer, delta frames
+// after the base keyframe.
+func (l *Layer) texture(base, morph *Keyframe, delta float32) int {
+	count := len(l.Textures)
+	if count == 0 {
+		return 0
+	}
+
+	frame := base.TextureFrame
+	switch morph.TextureAnimation {
+	case TextureAnimationNormal:
+		frame += morph.TextureFrame * delta
+	case TextureAnimationStop:
+		frame = float32(math.Min(float64(frame+morph.Delay*delta), float64(count-1)))
+	case TextureAnimationRepeat:
+		frame += morph.Delay * delta
+	case TextureAnimationReverse:
+		frame -= morph.Delay * delta
+	}
+
+	i := int(math.Floor(float64(frame))) % count
+	if i < 0 {
+		i += count
+	}
+
+	return i
+}
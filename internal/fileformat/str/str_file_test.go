@@ -0,0 +1,113 @@
+package str
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// name returns s padded to 128 bytes.
+func name(s string) []byte {
+	b := make([]byte, 128)
+	copy(b, s)
+	return b
+}
+
+// newFile returns an STR file of 30 frames at 60 FPS of a layer of two
+// textures, moving right and growing from frame 10 to 20, where it jumps.
+func newFile() []byte {
+	var buf bytes.Buffer
+	w := func(v interface{}) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+
+	buf.WriteString("STRM")
+	w([4]uint32{Version, 60, 30, 1})
+	buf.Write(make([]byte, 16))
+
+	w(int32(2))
+	buf.Write(name("spark1.bmp"))
+	buf.Write(name("spark2.bmp"))
+	w(int32(3))
+	w([]Keyframe{
+		{
+			Frame:    10,
+			Type:     KeyframeBase,
+			Position: mgl32.Vec2{320, 300},
+			XY:       [8]float32{-10, 10, -10, 10, -10, -10, 10, 10},
+			Angle:    256,
+			Color:    [4]float32{255, 128, 0, 255},
+		},
+		{
+			Frame:            10,
+			Type:             KeyframeMorph,
+			Position:         mgl32.Vec2{2, 0},
+			XY:               [8]float32{-1, 1, -1, 1, -1, -1, 1, 1},
+			TextureAnimation: TextureAnimationRepeat,
+			Delay:            0.5,
+		},
+		{Frame: 20, Type: KeyframeBase, Position: mgl32.Vec2{340, 300}, TextureFrame: 1},
+	})
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	f, err := Load(newFile())
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(60), f.FPS)
+	assert.Equal(t, 500*time.Millisecond, f.Duration())
+	require.Len(t, f.Layers, 1)
+	assert.Equal(t, []string{"spark1.bmp", "spark2.bmp"}, f.Layers[0].Textures)
+	require.Len(t, f.Layers[0].Keyframes, 3)
+	assert.Equal(t, mgl32.Vec2{-10, 10}, f.Layers[0].Keyframes[0].Corner(2))
+
+	_, err = Load([]byte("GRSM"))
+	assert.Error(t, err)
+
+	data := newFile()
+	data[4] = 0x95
+	_, err = Load(data)
+	assert.Error(t, err)
+
+	// Truncated in the keyframes.
+	_, err = Load(newFile()[:300])
+	assert.Error(t, err)
+}
+
+func TestStateAt(t *testing.T) {
+	f, err := Load(newFile())
+	require.NoError(t, err)
+	l := &f.Layers[0]
+
+	_, ok := l.StateAt(9)
+	assert.False(t, ok, "before the first keyframe")
+	_, ok = l.StateAt(21)
+	assert.False(t, ok, "after the last keyframe")
+
+	s, ok := l.StateAt(10)
+	require.True(t, ok)
+	assert.Equal(t, mgl32.Vec2{320, 300}, s.Position)
+	assert.InDelta(t, math.Pi/2, s.Angle, 1e-6)
+	assert.Equal(t, mgl32.Vec4{1, 128.0 / 255, 0, 1}, s.Color)
+	assert.Equal(t, 0, s.Texture)
+
+	// The morph keyframe counts from the base one.
+	s, ok = l.StateAt(14)
+	require.True(t, ok)
+	assert.Equal(t, mgl32.Vec2{328, 300}, s.Position)
+	assert.Equal(t, mgl32.Vec2{-14, -14}, s.Corners[0])
+	assert.Equal(t, 0, s.Texture)
+	s, _ = l.StateAt(12)
+	assert.Equal(t, 1, s.Texture)
+
+	// The next base keyframe ends the morph.
+	s, _ = l.StateAt(20)
+	assert.Equal(t, mgl32.Vec2{340, 300}, s.Position)
+	assert.Equal(t, 1, s.Texture)
+}
@@ -0,0 +1,85 @@
+// Package footstep picks the sounds of the steps of the characters from
+// the terrain under them: the water cells of the GAT files, and the
+// ground of the map elsewhere, sand on the deserts and beaches. The steps
+// are played at the frames of the walk animations where a foot lands (see
+// system.FootstepSystem).
+package footstep
+
+import (
+	"strings"
+
+	"github.com/project-midgard/midgarts/internal/romap"
+)
+
+// Terrain is what a character walks on.
+type Terrain string
+
+const (
+	Ground Terrain = "ground"
+	Water  Terrain = "water"
+	Sand   Terrain = "sand"
+)
+
+// Set is the sounds of the steps on a terrain, played in turn, in
+// data/wav/.
+type Set struct {
+	Sounds []string
+	// Volume is the gain of the steps next to the listener, from 0 to 1.
+	Volume float32
+}
+
+// Sound returns the sound of the step n of a character, the sounds of s
+// in turn.
+func (s Set) Sound(n int) (string, bool) {
+	if len(s.Sounds) == 0 {
+		return "", false
+	}
+
+	return s.Sounds[n%len(s.Sounds)], true
+}
+
+// DefaultSets are the sounds of the steps on each terrain, a sound per
+// foot.
+var DefaultSets = map[Terrain]Set{
+	Ground: {Sounds: []string{"footstep/ground_left.wav", "footstep/ground_right.wav"}, Volume: 0.4},
+	Water:  {Sounds: []string{"footstep/water_left.wav", "footstep/water_right.wav"}, Volume: 0.5},
+	Sand:   {Sounds: []string{"footstep/sand_left.wav", "footstep/sand_right.wav"}, Volume: 0.3},
+}
+
+// DefaultContactFrames are the frames of the walk animations, of 8
+// frames, where a foot lands.
+var DefaultContactFrames = []int{0, 4}
+
+// DefaultMapGrounds are the terrains of the ground of the maps, by prefix
+// of their names: the ground of the other maps is Ground.
+var DefaultMapGrounds = map[string]Terrain{
+	"morocc":    Sand,
+	"moc_fild":  Sand,
+	"moc_ruins": Sand,
+	"comodo":    Sand,
+	"cmd_fild":  Sand,
+	"beach_dun": Sand,
+}
+
+// MapGround returns the terrain of the ground of the map mapName, from the
+// longest of the prefixes of grounds it starts with, Ground when none.
+func MapGround(grounds map[string]Terrain, mapName string) Terrain {
+	terrain, longest := Ground, -1
+	for prefix, t := range grounds {
+		if strings.HasPrefix(mapName, prefix) && len(prefix) > longest {
+			terrain, longest = t, len(prefix)
+		}
+	}
+
+	return terrain
+}
+
+// CellTerrain returns the terrain of a cell of cellType on a map whose
+// ground is ground.
+func CellTerrain(cellType romap.CellType, ground Terrain) Terrain {
+	if cellType&romap.CellTypeWater != 0 {
+		return Water
+	}
+
+	return ground
+}
@@ -0,0 +1,38 @@
+package footstep
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+)
+
+func TestMapGround(t *testing.T) {
+	grounds := map[string]Terrain{"moc_": Sand, "moc_prydb": Ground}
+
+	assert.Equal(t, Sand, MapGround(grounds, "moc_fild01"))
+	// The longest prefix wins.
+	assert.Equal(t, Ground, MapGround(grounds, "moc_prydb1"))
+	assert.Equal(t, Ground, MapGround(grounds, "prontera"))
+	assert.Equal(t, Sand, MapGround(DefaultMapGrounds, "cmd_fild07"))
+}
+
+func TestCellTerrain(t *testing.T) {
+	assert.Equal(t, Water, CellTerrain(gat.TypeTable[3], Sand))
+	assert.Equal(t, Sand, CellTerrain(gat.TypeTable[0], Sand))
+	assert.Equal(t, Ground, CellTerrain(gat.TypeTable[0], Ground))
+}
+
+func TestSetSound(t *testing.T) {
+	s := DefaultSets[Water]
+	left, _ := s.Sound(0)
+	right, _ := s.Sound(1)
+	again, ok := s.Sound(2)
+	assert.True(t, ok)
+	assert.NotEqual(t, left, right)
+	assert.Equal(t, left, again)
+
+	_, ok = Set{}.Sound(0)
+	assert.False(t, ok)
+}
@@ -0,0 +1,21 @@
+// Package gl is the OpenGL API the renderer draws with: the desktop
+// OpenGL 3.2 core profile, or OpenGL ES 3.0 when built with the gles tag,
+// as for Android. Both name their functions and constants as go-gl does;
+// the few ES lacks are emulated or do nothing.
+package gl
+
+import "strings"
+
+// desktop is the version of the shaders written for the desktop profile,
+// as the ones of the renderer and of the plugins.
+const desktop = "#version 330 core"
+
+// GLSL returns the source of a shader written for the desktop profile,
+// its version set for the API built.
+func GLSL(source string) string {
+	if version == desktop || !strings.HasPrefix(source, desktop) {
+		return source
+	}
+
+	return version + source[len(desktop):]
+}
@@ -0,0 +1,136 @@
+//go:build !gles
+// +build !gles
+
+package gl
+
+import core "github.com/go-gl/gl/v3.2-core/gl"
+
+// The context of the desktop build, OpenGL 3.2 with the core profile.
+const (
+	ES           = false
+	MajorVersion = 3
+	MinorVersion = 2
+
+	version = "#version 330 core"
+)
+
+const (
+	ARRAY_BUFFER         = core.ARRAY_BUFFER
+	BLEND                = core.BLEND
+	CLAMP_TO_BORDER      = core.CLAMP_TO_BORDER
+	CLAMP_TO_EDGE        = core.CLAMP_TO_EDGE
+	COLOR_ATTACHMENT0    = core.COLOR_ATTACHMENT0
+	COLOR_BUFFER_BIT     = core.COLOR_BUFFER_BIT
+	COMPILE_STATUS       = core.COMPILE_STATUS
+	DEPTH_ATTACHMENT     = core.DEPTH_ATTACHMENT
+	DEPTH_BUFFER_BIT     = core.DEPTH_BUFFER_BIT
+	DEPTH_COMPONENT24    = core.DEPTH_COMPONENT24
+	DEPTH_TEST           = core.DEPTH_TEST
+	DRAW_FRAMEBUFFER     = core.DRAW_FRAMEBUFFER
+	ELEMENT_ARRAY_BUFFER = core.ELEMENT_ARRAY_BUFFER
+	FALSE                = core.FALSE
+	FILL                 = core.FILL
+	FLOAT                = core.FLOAT
+	FRAGMENT_SHADER      = core.FRAGMENT_SHADER
+	FRAMEBUFFER          = core.FRAMEBUFFER
+	FRAMEBUFFER_BINDING  = core.FRAMEBUFFER_BINDING
+	FRAMEBUFFER_COMPLETE = core.FRAMEBUFFER_COMPLETE
+	FRONT_AND_BACK       = core.FRONT_AND_BACK
+	INFO_LOG_LENGTH      = core.INFO_LOG_LENGTH
+	LEQUAL               = core.LEQUAL
+	LINE                 = core.LINE
+	LINEAR               = core.LINEAR
+	LINK_STATUS          = core.LINK_STATUS
+	NEAREST              = core.NEAREST
+	ONE_MINUS_SRC_ALPHA  = core.ONE_MINUS_SRC_ALPHA
+	READ_FRAMEBUFFER     = core.READ_FRAMEBUFFER
+	RENDERBUFFER         = core.RENDERBUFFER
+	REPEAT               = core.REPEAT
+	RGBA                 = core.RGBA
+	RGBA8                = core.RGBA8
+	SRC_ALPHA            = core.SRC_ALPHA
+	STATIC_DRAW          = core.STATIC_DRAW
+	TEXTURE0             = core.TEXTURE0
+	TEXTURE_2D           = core.TEXTURE_2D
+	TEXTURE_MAG_FILTER   = core.TEXTURE_MAG_FILTER
+	TEXTURE_MIN_FILTER   = core.TEXTURE_MIN_FILTER
+	TEXTURE_WRAP_S       = core.TEXTURE_WRAP_S
+	TEXTURE_WRAP_T       = core.TEXTURE_WRAP_T
+	TRIANGLES            = core.TRIANGLES
+	UNSIGNED_BYTE        = core.UNSIGNED_BYTE
+	UNSIGNED_INT         = core.UNSIGNED_INT
+	VERSION              = core.VERSION
+	VERTEX_SHADER        = core.VERTEX_SHADER
+	VIEWPORT             = core.VIEWPORT
+)
+
+var (
+	ActiveTexture           = core.ActiveTexture
+	AttachShader            = core.AttachShader
+	BindBuffer              = core.BindBuffer
+	BindFramebuffer         = core.BindFramebuffer
+	BindRenderbuffer        = core.BindRenderbuffer
+	BindTexture             = core.BindTexture
+	BindVertexArray         = core.BindVertexArray
+	BlendFunc               = core.BlendFunc
+	BlitFramebuffer         = core.BlitFramebuffer
+	BufferData              = core.BufferData
+	BufferSubData           = core.BufferSubData
+	CheckFramebufferStatus  = core.CheckFramebufferStatus
+	Clear                   = core.Clear
+	ClearColor              = core.ClearColor
+	CompileShader           = core.CompileShader
+	CreateProgram           = core.CreateProgram
+	CreateShader            = core.CreateShader
+	DeleteBuffers           = core.DeleteBuffers
+	DeleteFramebuffers      = core.DeleteFramebuffers
+	DeleteProgram           = core.DeleteProgram
+	DeleteRenderbuffers     = core.DeleteRenderbuffers
+	DeleteTextures          = core.DeleteTextures
+	DeleteVertexArrays      = core.DeleteVertexArrays
+	DepthFunc               = core.DepthFunc
+	Disable                 = core.Disable
+	DrawArrays              = core.DrawArrays
+	DrawElements            = core.DrawElements
+	Enable                  = core.Enable
+	EnableVertexAttribArray = core.EnableVertexAttribArray
+	FramebufferRenderbuffer = core.FramebufferRenderbuffer
+	FramebufferTexture2D    = core.FramebufferTexture2D
+	GenBuffers              = core.GenBuffers
+	GenFramebuffers         = core.GenFramebuffers
+	GenRenderbuffers        = core.GenRenderbuffers
+	GenTextures             = core.GenTextures
+	GenVertexArrays         = core.GenVertexArrays
+	GenerateMipmap          = core.GenerateMipmap
+	GetAttribLocation       = core.GetAttribLocation
+	GetIntegerv             = core.GetIntegerv
+	GetProgramInfoLog       = core.GetProgramInfoLog
+	GetProgramiv            = core.GetProgramiv
+	GetShaderInfoLog        = core.GetShaderInfoLog
+	GetShaderiv             = core.GetShaderiv
+	GetString               = core.GetString
+	GetUniformLocation      = core.GetUniformLocation
+	GoStr                   = core.GoStr
+	Init                    = core.Init
+	LineWidth               = core.LineWidth
+	LinkProgram             = core.LinkProgram
+	PointSize               = core.PointSize
+	PolygonMode             = core.PolygonMode
+	Ptr                     = core.Ptr
+	PtrOffset               = core.PtrOffset
+	RenderbufferStorage     = core.RenderbufferStorage
+	ShaderSource            = core.ShaderSource
+	Str                     = core.Str
+	Strs                    = core.Strs
+	TexImage2D              = core.TexImage2D
+	TexParameteri           = core.TexParameteri
+	Uniform1f               = core.Uniform1f
+	Uniform1i               = core.Uniform1i
+	Uniform2fv              = core.Uniform2fv
+	Uniform3fv              = core.Uniform3fv
+	Uniform4fv              = core.Uniform4fv
+	UniformMatrix4fv        = core.UniformMatrix4fv
+	UseProgram              = core.UseProgram
+	VertexAttribPointer     = core.VertexAttribPointer
+	Viewport                = core.Viewport
+)
@@ -0,0 +1,148 @@
+//go:build gles
+// +build gles
+
+package gl
+
+import "github.com/go-gl/gl/v3.1/gles2"
+
+// The context of the ES build, OpenGL ES 3.0, created with EGL on Android.
+const (
+	ES           = true
+	MajorVersion = 3
+	MinorVersion = 0
+
+	version = "#version 300 es\nprecision highp float;"
+)
+
+// ES has no border color: the textures clamped to their border repeat
+// their edges instead.
+const CLAMP_TO_BORDER = gles2.CLAMP_TO_EDGE
+
+// ES has no polygon modes: the wireframes, as the hitboxes, are drawn
+// filled.
+const (
+	FILL = 0x1B02
+	LINE = 0x1B01
+)
+
+// PolygonMode does nothing, see FILL.
+func PolygonMode(face, mode uint32) {}
+
+// PointSize does nothing: ES sets the size of the points in the shaders.
+func PointSize(size float32) {}
+
+const (
+	ARRAY_BUFFER         = gles2.ARRAY_BUFFER
+	BLEND                = gles2.BLEND
+	CLAMP_TO_EDGE        = gles2.CLAMP_TO_EDGE
+	COLOR_ATTACHMENT0    = gles2.COLOR_ATTACHMENT0
+	COLOR_BUFFER_BIT     = gles2.COLOR_BUFFER_BIT
+	COMPILE_STATUS       = gles2.COMPILE_STATUS
+	DEPTH_ATTACHMENT     = gles2.DEPTH_ATTACHMENT
+	DEPTH_BUFFER_BIT     = gles2.DEPTH_BUFFER_BIT
+	DEPTH_COMPONENT24    = gles2.DEPTH_COMPONENT24
+	DEPTH_TEST           = gles2.DEPTH_TEST
+	DRAW_FRAMEBUFFER     = gles2.DRAW_FRAMEBUFFER
+	ELEMENT_ARRAY_BUFFER = gles2.ELEMENT_ARRAY_BUFFER
+	FALSE                = gles2.FALSE
+	FLOAT                = gles2.FLOAT
+	FRAGMENT_SHADER      = gles2.FRAGMENT_SHADER
+	FRAMEBUFFER          = gles2.FRAMEBUFFER
+	FRAMEBUFFER_BINDING  = gles2.FRAMEBUFFER_BINDING
+	FRAMEBUFFER_COMPLETE = gles2.FRAMEBUFFER_COMPLETE
+	FRONT_AND_BACK       = gles2.FRONT_AND_BACK
+	INFO_LOG_LENGTH      = gles2.INFO_LOG_LENGTH
+	LEQUAL               = gles2.LEQUAL
+	LINEAR               = gles2.LINEAR
+	LINK_STATUS          = gles2.LINK_STATUS
+	NEAREST              = gles2.NEAREST
+	ONE_MINUS_SRC_ALPHA  = gles2.ONE_MINUS_SRC_ALPHA
+	READ_FRAMEBUFFER     = gles2.READ_FRAMEBUFFER
+	RENDERBUFFER         = gles2.RENDERBUFFER
+	REPEAT               = gles2.REPEAT
+	RGBA                 = gles2.RGBA
+	RGBA8                = gles2.RGBA8
+	SRC_ALPHA            = gles2.SRC_ALPHA
+	STATIC_DRAW          = gles2.STATIC_DRAW
+	TEXTURE0             = gles2.TEXTURE0
+	TEXTURE_2D           = gles2.TEXTURE_2D
+	TEXTURE_MAG_FILTER   = gles2.TEXTURE_MAG_FILTER
+	TEXTURE_MIN_FILTER   = gles2.TEXTURE_MIN_FILTER
+	TEXTURE_WRAP_S       = gles2.TEXTURE_WRAP_S
+	TEXTURE_WRAP_T       = gles2.TEXTURE_WRAP_T
+	TRIANGLES            = gles2.TRIANGLES
+	UNSIGNED_BYTE        = gles2.UNSIGNED_BYTE
+	UNSIGNED_INT         = gles2.UNSIGNED_INT
+	VERSION              = gles2.VERSION
+	VERTEX_SHADER        = gles2.VERTEX_SHADER
+	VIEWPORT             = gles2.VIEWPORT
+)
+
+var (
+	ActiveTexture           = gles2.ActiveTexture
+	AttachShader            = gles2.AttachShader
+	BindBuffer              = gles2.BindBuffer
+	BindFramebuffer         = gles2.BindFramebuffer
+	BindRenderbuffer        = gles2.BindRenderbuffer
+	BindTexture             = gles2.BindTexture
+	BindVertexArray         = gles2.BindVertexArray
+	BlendFunc               = gles2.BlendFunc
+	BlitFramebuffer         = gles2.BlitFramebuffer
+	BufferData              = gles2.BufferData
+	BufferSubData           = gles2.BufferSubData
+	CheckFramebufferStatus  = gles2.CheckFramebufferStatus
+	Clear                   = gles2.Clear
+	ClearColor              = gles2.ClearColor
+	CompileShader           = gles2.CompileShader
+	CreateProgram           = gles2.CreateProgram
+	CreateShader            = gles2.CreateShader
+	DeleteBuffers           = gles2.DeleteBuffers
+	DeleteFramebuffers      = gles2.DeleteFramebuffers
+	DeleteProgram           = gles2.DeleteProgram
+	DeleteRenderbuffers     = gles2.DeleteRenderbuffers
+	DeleteTextures          = gles2.DeleteTextures
+	DeleteVertexArrays      = gles2.DeleteVertexArrays
+	DepthFunc               = gles2.DepthFunc
+	Disable                 = gles2.Disable
+	DrawArrays              = gles2.DrawArrays
+	DrawElements            = gles2.DrawElements
+	Enable                  = gles2.Enable
+	EnableVertexAttribArray = gles2.EnableVertexAttribArray
+	FramebufferRenderbuffer = gles2.FramebufferRenderbuffer
+	FramebufferTexture2D    = gles2.FramebufferTexture2D
+	GenBuffers              = gles2.GenBuffers
+	GenFramebuffers         = gles2.GenFramebuffers
+	GenRenderbuffers        = gles2.GenRenderbuffers
+	GenTextures             = gles2.GenTextures
+	GenVertexArrays         = gles2.GenVertexArrays
+	GenerateMipmap          = gles2.GenerateMipmap
+	GetAttribLocation       = gles2.GetAttribLocation
+	GetIntegerv             = gles2.GetIntegerv
+	GetProgramInfoLog       = gles2.GetProgramInfoLog
+	GetProgramiv            = gles2.GetProgramiv
+	GetShaderInfoLog        = gles2.GetShaderInfoLog
+	GetShaderiv             = gles2.GetShaderiv
+	GetString               = gles2.GetString
+	GetUniformLocation      = gles2.GetUniformLocation
+	GoStr                   = gles2.GoStr
+	Init                    = gles2.Init
+	LineWidth               = gles2.LineWidth
+	LinkProgram             = gles2.LinkProgram
+	Ptr                     = gles2.Ptr
+	PtrOffset               = gles2.PtrOffset
+	RenderbufferStorage     = gles2.RenderbufferStorage
+	ShaderSource            = gles2.ShaderSource
+	Str                     = gles2.Str
+	Strs                    = gles2.Strs
+	TexImage2D              = gles2.TexImage2D
+	TexParameteri           = gles2.TexParameteri
+	Uniform1f               = gles2.Uniform1f
+	Uniform1i               = gles2.Uniform1i
+	Uniform2fv              = gles2.Uniform2fv
+	Uniform3fv              = gles2.Uniform3fv
+	Uniform4fv              = gles2.Uniform4fv
+	UniformMatrix4fv        = gles2.UniformMatrix4fv
+	UseProgram              = gles2.UseProgram
+	VertexAttribPointer     = gles2.VertexAttribPointer
+	Viewport                = gles2.Viewport
+)
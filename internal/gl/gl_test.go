@@ -0,0 +1,19 @@
+package gl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGLSL(t *testing.T) {
+	source := "#version 330 core\nvoid main() {}"
+	if ES {
+		assert.Equal(t, "#version 300 es\nprecision highp float;\nvoid main() {}", GLSL(source))
+	} else {
+		assert.Equal(t, source, GLSL(source))
+	}
+
+	// The shaders written for another version are left to the driver.
+	assert.Equal(t, "#version 410 core\n", GLSL("#version 410 core\n"))
+}
@@ -0,0 +1,191 @@
+// Package golden compares the images the tests render to the golden PNGs
+// checked in the testdata/golden folder of their package, so the
+// regressions of the rendering fail the tests.
+//
+// Run the tests with GOLDEN_UPDATE=1 to write the images rendered as the
+// golden ones, after a change of the rendering meant to change them. On a
+// mismatch, the image rendered is written next to the golden one, as
+// <name>.actual.png, to be looked at.
+//
+// The pixels of the RGBA images are taken as they are, their alpha
+// straight, as the textures are uploaded and blended.
+package golden
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Dir is the folder of the golden images, in the folder of the package
+	// tested.
+	Dir = "testdata/golden"
+	// EnvUpdate is the environment variable writing the golden images
+	// instead of comparing to them, when set to 1.
+	EnvUpdate = "GOLDEN_UPDATE"
+)
+
+// Tolerance is how far an image may be from its golden one, so the small
+// differences of the rounding of the platforms pass.
+type Tolerance struct {
+	// Channel is the largest difference of a channel of a pixel, from 0 to
+	// 255, for the pixels to be the same.
+	Channel uint8
+	// Pixels is the fraction of the pixels that may differ.
+	Pixels float64
+}
+
+// DefaultTolerance lets a few pixels be slightly off.
+var DefaultTolerance = Tolerance{Channel: 2, Pixels: 0.001}
+
+// Assert fails t unless img matches the golden image name, within
+// DefaultTolerance.
+func Assert(t testing.TB, name string, img image.Image) {
+	t.Helper()
+	AssertWithin(t, name, img, DefaultTolerance)
+}
+
+// AssertWithin fails t unless img matches the golden image name, within
+// tol.
+func AssertWithin(t testing.TB, name string, img image.Image, tol Tolerance) {
+	t.Helper()
+
+	path := filepath.Join(Dir, name+".png")
+	if os.Getenv(EnvUpdate) == "1" {
+		if err := Write(path, img); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := Read(path)
+	if err != nil {
+		t.Fatalf("%v, run the tests with %s=1 to write it", err, EnvUpdate)
+	}
+
+	if err = Compare(want, img, tol); err != nil {
+		actual := filepath.Join(Dir, name+".actual.png")
+		if werr := Write(actual, img); werr != nil {
+			t.Logf("could not write the image rendered: %v", werr)
+		}
+		t.Errorf("%s: %v, rendered to %s", name, err, actual)
+	}
+}
+
+// Compare returns an error telling how got differs from want beyond tol.
+func Compare(want, got image.Image, tol Tolerance) error {
+	want, got = straight(want), straight(got)
+	wb, gb := want.Bounds(), got.Bounds()
+	if wb.Size() != gb.Size() {
+		return errors.Errorf("size %v, expected %v", gb.Size(), wb.Size())
+	}
+
+	var differ, worst int
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			d := difference(want.At(wb.Min.X+x, wb.Min.Y+y), got.At(gb.Min.X+x, gb.Min.Y+y))
+			if d > worst {
+				worst = d
+			}
+			if d > int(tol.Channel) {
+				differ++
+			}
+		}
+	}
+
+	if total := wb.Dx() * wb.Dy(); total > 0 && float64(differ)/float64(total) > tol.Pixels {
+		return errors.Errorf("%d of %d pixels differ, by up to %d", differ, total, worst)
+	}
+
+	return nil
+}
+
+// difference returns the largest difference of the channels of c1 and
+// c2, in 8 bits.
+func difference(c1, c2 color.Color) int {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+
+	d := 0
+	for _, c := range [][2]uint32{{r1, r2}, {g1, g2}, {b1, b2}, {a1, a2}} {
+		v := int(c[0]>>8) - int(c[1]>>8)
+		if v < 0 {
+			v = -v
+		}
+		if v > d {
+			d = v
+		}
+	}
+
+	return d
+}
+
+// Read returns the image of the PNG file of path.
+func Read(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open golden image")
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode golden image '%s'", path)
+	}
+
+	return img, nil
+}
+
+// Write writes img to the PNG file of path, creating its folder.
+func Write(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "could not create golden folder")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "could not create golden image")
+	}
+	if err = png.Encode(f, straight(img)); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "could not encode golden image '%s'", path)
+	}
+
+	return f.Close()
+}
+
+// rgbaImage is an RGBA image, e.g. a *image.RGBA or a graphic.UniqueRGBA.
+type rgbaImage interface {
+	image.Image
+	RGBAAt(x, y int) color.RGBA
+}
+
+// straightRGBA is an RGBA image whose pixels are not premultiplied.
+type straightRGBA struct {
+	rgbaImage
+}
+
+func (img straightRGBA) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (img straightRGBA) At(x, y int) color.Color {
+	c := img.RGBAAt(x, y)
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// straight returns img, its pixels taken as not premultiplied if it is an
+// RGBA image.
+func straight(img image.Image) image.Image {
+	if rgba, ok := img.(rgbaImage); ok {
+		return straightRGBA{rgba}
+	}
+
+	return img
+}
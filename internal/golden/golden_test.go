@@ -0,0 +1,54 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newImage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	want := newImage(10, 10, color.RGBA{R: 100, A: 255})
+
+	got := newImage(10, 10, color.RGBA{R: 102, A: 255})
+	assert.NoError(t, Compare(want, got, DefaultTolerance))
+
+	// One pixel of a hundred is off.
+	got.SetRGBA(3, 4, color.RGBA{G: 255, A: 255})
+	assert.Error(t, Compare(want, got, DefaultTolerance))
+	assert.NoError(t, Compare(want, got, Tolerance{Channel: 2, Pixels: 0.01}))
+
+	assert.Error(t, Compare(want, newImage(10, 9, color.RGBA{R: 100, A: 255}), DefaultTolerance))
+}
+
+func TestReadWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden", "image.png")
+	img := newImage(4, 3, color.RGBA{R: 1, G: 2, B: 3, A: 255})
+
+	require.NoError(t, Write(path, img))
+	read, err := Read(path)
+	require.NoError(t, err)
+	assert.NoError(t, Compare(img, read, Tolerance{}))
+
+	// The alpha of the RGBA images is straight.
+	translucent := newImage(2, 2, color.RGBA{R: 200, G: 100, A: 160})
+	require.NoError(t, Write(path, translucent))
+	read, err = Read(path)
+	require.NoError(t, err)
+	assert.NoError(t, Compare(translucent, read, Tolerance{}))
+
+	_, err = Read(filepath.Join(t.TempDir(), "missing.png"))
+	assert.Error(t, err)
+}
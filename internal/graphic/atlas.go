@@ -0,0 +1,88 @@
+package graphic
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// atlasPadding is the transparent space around the images of an atlas, so
+// the filtering of a frame does not bleed its neighbours.
+const atlasPadding = 1
+
+// Atlas is a set of images packed in a single one, uploaded as a single
+// texture: drawing the images one after another then needs no texture
+// bind in between.
+type Atlas struct {
+	Image *UniqueRGBA
+	// Rects holds the place of each image in Image, empty for the nil
+	// ones.
+	Rects []image.Rectangle
+}
+
+// NewAtlas packs images, some of which may be nil, in rows of images of
+// decreasing height.
+func NewAtlas(images []*UniqueRGBA) *Atlas {
+	a := &Atlas{Rects: make([]image.Rectangle, len(images))}
+
+	var (
+		order    []int
+		area     int
+		maxWidth int
+	)
+	for i, img := range images {
+		if img == nil || img.Rect.Empty() {
+			continue
+		}
+
+		size := img.Rect.Size()
+		order = append(order, i)
+		area += (size.X + atlasPadding) * (size.Y + atlasPadding)
+		if size.X > maxWidth {
+			maxWidth = size.X
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return images[order[i]].Rect.Dy() > images[order[j]].Rect.Dy()
+	})
+
+	// About square, as wide as the widest image.
+	width := 1
+	for width < maxWidth+atlasPadding || width*width < area {
+		width *= 2
+	}
+
+	var x, y, rowHeight int
+	for _, i := range order {
+		size := images[i].Rect.Size()
+		if x+size.X+atlasPadding > width {
+			x, y, rowHeight = 0, y+rowHeight, 0
+		}
+
+		a.Rects[i] = image.Rect(x, y, x+size.X, y+size.Y)
+		x += size.X + atlasPadding
+		if size.Y+atlasPadding > rowHeight {
+			rowHeight = size.Y + atlasPadding
+		}
+	}
+
+	a.Image = NewUniqueRGBA(image.Rect(0, 0, width, int(math.Max(1, float64(y+rowHeight)))))
+	for _, i := range order {
+		draw.Draw(a.Image, a.Rects[i], images[i], images[i].Rect.Min, draw.Src)
+	}
+
+	return a
+}
+
+// UV returns the texture coordinates of the image i, its top left corner
+// then its bottom right one.
+func (a *Atlas) UV(i int) mgl32.Vec4 {
+	r, size := a.Rects[i], a.Image.Rect.Size()
+	w, h := float32(size.X), float32(size.Y)
+
+	return mgl32.Vec4{float32(r.Min.X) / w, float32(r.Min.Y) / h, float32(r.Max.X) / w, float32(r.Max.Y) / h}
+}
@@ -0,0 +1,71 @@
+package graphic
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFilledRGBA(width, height int, c color.RGBA) *UniqueRGBA {
+	img := NewUniqueRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestNewAtlas(t *testing.T) {
+	red, green, blue := color.RGBA{R: 255, A: 255}, color.RGBA{G: 255, A: 255}, color.RGBA{B: 255, A: 255}
+	images := []*UniqueRGBA{
+		newFilledRGBA(3, 2, red),
+		nil,
+		newFilledRGBA(2, 4, green),
+		newFilledRGBA(5, 1, blue),
+	}
+
+	a := NewAtlas(images)
+	assert.Len(t, a.Rects, 4)
+	assert.True(t, a.Rects[1].Empty())
+
+	// The tallest first, a pixel apart.
+	assert.Equal(t, image.Rect(0, 0, 2, 4), a.Rects[2])
+	assert.Equal(t, image.Rect(3, 0, 6, 2), a.Rects[0])
+
+	for i, img := range images {
+		if img == nil {
+			continue
+		}
+
+		r := a.Rects[i]
+		assert.Equal(t, img.Rect.Size(), r.Size())
+		assert.Equal(t, img.RGBAAt(0, 0), a.Image.RGBAAt(r.Min.X, r.Min.Y))
+		assert.Equal(t, img.RGBAAt(r.Dx()-1, r.Dy()-1), a.Image.RGBAAt(r.Max.X-1, r.Max.Y-1))
+
+		for j := range images {
+			if j != i {
+				assert.False(t, r.Overlaps(a.Rects[j]), "images %d and %d overlap", i, j)
+			}
+		}
+	}
+
+	// Padded.
+	assert.Equal(t, color.RGBA{}, a.Image.RGBAAt(2, 0))
+}
+
+func TestAtlasUV(t *testing.T) {
+	a := NewAtlas([]*UniqueRGBA{newFilledRGBA(2, 2, color.RGBA{A: 255})})
+	assert.Equal(t, image.Rect(0, 0, 4, 3), a.Image.Rect)
+	assert.Equal(t, mgl32.Vec4{0, 0, 0.5, 2.0 / 3}, a.UV(0))
+}
+
+func TestNewAtlasEmpty(t *testing.T) {
+	a := NewAtlas([]*UniqueRGBA{nil})
+	assert.False(t, a.Image.Rect.Empty())
+	assert.True(t, a.Rects[0].Empty())
+}
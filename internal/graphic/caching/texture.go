@@ -1,27 +1,129 @@
 package caching
 
 import (
+	"container/list"
+
 	"github.com/google/uuid"
 
 	graphic2 "github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/metrics"
 )
 
-type CachedTextureProvider map[uuid.UUID]*graphic2.Texture
+// CachedTextureProvider creates a texture once per image. Past its
+// budget, the least recently used textures are deleted; they are uploaded
+// again from their image when requested.
+type CachedTextureProvider struct {
+	// Budget is the memory the textures may use, in bytes, 0 for no limit.
+	Budget int
+	// Filters holds the filter of each texture class, see
+	// graphic.DefaultFilters. It applies to the textures created after
+	// it is changed.
+	Filters map[graphic2.TextureClass]graphic2.Filter
+
+	upload func(rgba *graphic2.UniqueRGBA, filter graphic2.Filter) (*graphic2.Texture, error)
+	delete func(tex *graphic2.Texture)
+
+	entries map[uuid.UUID]*list.Element
+	// lru holds the entries, the most recently used first.
+	lru   *list.List
+	used  int
+	frame uint64
+}
 
-func NewCachedTextureProvider() CachedTextureProvider {
-	return make(map[uuid.UUID]*graphic2.Texture)
+type entry struct {
+	id       uuid.UUID
+	tex      *graphic2.Texture
+	size     int
+	lastUsed uint64
+}
+
+// NewCachedTextureProvider returns a provider keeping the textures within
+// budget bytes, 0 for no limit.
+func NewCachedTextureProvider(budget int) *CachedTextureProvider {
+	return &CachedTextureProvider{
+		Budget:  budget,
+		Filters: map[graphic2.TextureClass]graphic2.Filter{},
+		upload:  graphic2.NewTextureFromRGBA,
+		delete:  (*graphic2.Texture).Delete,
+		entries: map[uuid.UUID]*list.Element{},
+		lru:     list.New(),
+	}
 }
 
-func (t CachedTextureProvider) NewTextureFromRGBA(rgba *graphic2.UniqueRGBA) (*graphic2.Texture, error) {
-	if txt, ok := t[rgba.ID]; ok {
-		return txt, nil
+func (t *CachedTextureProvider) NewTextureFromRGBA(rgba *graphic2.UniqueRGBA, class graphic2.TextureClass) (*graphic2.Texture, error) {
+	if el, ok := t.entries[rgba.ID]; ok {
+		metrics.TextureHits.Add(1)
+		el.Value.(*entry).lastUsed = t.frame
+		t.lru.MoveToFront(el)
+		return el.Value.(*entry).tex, nil
 	}
+	metrics.TextureMisses.Add(1)
 
-	tex, err := graphic2.NewTextureFromRGBA(rgba)
+	tex, err := t.upload(rgba, t.filter(class))
 	if err != nil {
 		return nil, err
 	}
 
-	t[rgba.ID] = tex
+	// RGBA8 textures, 4 bytes per pixel.
+	size := rgba.Rect.Dx() * rgba.Rect.Dy() * 4
+	t.entries[rgba.ID] = t.lru.PushFront(&entry{id: rgba.ID, tex: tex, size: size, lastUsed: t.frame})
+	t.used += size
+	metrics.TextureBytes.Add(int64(size))
+	t.evict()
+
 	return tex, nil
 }
+
+func (t *CachedTextureProvider) DeleteTexture(rgba *graphic2.UniqueRGBA) {
+	if el, ok := t.entries[rgba.ID]; ok {
+		t.remove(el)
+	}
+}
+
+// NextFrame starts a new frame. The textures requested during the current
+// frame are not evicted, as they may still be drawn.
+func (t *CachedTextureProvider) NextFrame() {
+	t.frame++
+	t.evict()
+}
+
+// filter returns the filter of class.
+func (t *CachedTextureProvider) filter(class graphic2.TextureClass) graphic2.Filter {
+	if f, ok := t.Filters[class]; ok {
+		return f
+	}
+
+	return graphic2.DefaultFilters[class]
+}
+
+// Used returns the memory used by the textures, in bytes.
+func (t *CachedTextureProvider) Used() int {
+	return t.used
+}
+
+// Len returns the number of textures.
+func (t *CachedTextureProvider) Len() int {
+	return len(t.entries)
+}
+
+// evict deletes the least recently used textures, not used during the
+// current frame, until the budget is met.
+func (t *CachedTextureProvider) evict() {
+	for t.Budget > 0 && t.used > t.Budget {
+		el := t.lru.Back()
+		if el == nil || el.Value.(*entry).lastUsed == t.frame {
+			return
+		}
+
+		t.remove(el)
+		metrics.TextureEvictions.Add(1)
+	}
+}
+
+func (t *CachedTextureProvider) remove(el *list.Element) {
+	e := t.lru.Remove(el).(*entry)
+	delete(t.entries, e.id)
+	t.used -= e.size
+	metrics.TextureBytes.Add(-int64(e.size))
+	t.delete(e.tex)
+}
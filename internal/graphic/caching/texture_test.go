@@ -0,0 +1,99 @@
+package caching
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	graphic2 "github.com/project-midgard/midgarts/internal/graphic"
+)
+
+// newTestProvider returns a provider counting the uploads and deletions
+// instead of creating OpenGL textures.
+func newTestProvider(budget int) (p *CachedTextureProvider, uploads, deletes *int) {
+	uploads, deletes = new(int), new(int)
+
+	p = NewCachedTextureProvider(budget)
+	p.upload = func(rgba *graphic2.UniqueRGBA, filter graphic2.Filter) (*graphic2.Texture, error) {
+		*uploads++
+		return &graphic2.Texture{}, nil
+	}
+	p.delete = func(tex *graphic2.Texture) {
+		*deletes++
+	}
+
+	return p, uploads, deletes
+}
+
+func TestCachedTextureProvider(t *testing.T) {
+	// 2x2 images of 16 bytes, a budget of two textures.
+	p, uploads, deletes := newTestProvider(32)
+	a, b, c := graphic2.NewUniqueRGBA(image.Rect(0, 0, 2, 2)), graphic2.NewUniqueRGBA(image.Rect(0, 0, 2, 2)), graphic2.NewUniqueRGBA(image.Rect(0, 0, 2, 2))
+
+	texA, err := p.NewTextureFromRGBA(a, graphic2.TextureSprite)
+	require.NoError(t, err)
+	_, err = p.NewTextureFromRGBA(b, graphic2.TextureSprite)
+	require.NoError(t, err)
+
+	got, err := p.NewTextureFromRGBA(a, graphic2.TextureSprite)
+	require.NoError(t, err)
+	assert.Same(t, texA, got)
+	assert.Equal(t, 2, *uploads)
+
+	// Over budget, but every texture is used by the current frame.
+	_, err = p.NewTextureFromRGBA(c, graphic2.TextureSprite)
+	require.NoError(t, err)
+	assert.Equal(t, 48, p.Used())
+	assert.Equal(t, 0, *deletes)
+
+	// b is the least recently used.
+	p.NextFrame()
+	assert.Equal(t, 32, p.Used())
+	assert.Equal(t, 1, *deletes)
+
+	_, err = p.NewTextureFromRGBA(a, graphic2.TextureSprite)
+	require.NoError(t, err)
+	_, err = p.NewTextureFromRGBA(b, graphic2.TextureSprite)
+	require.NoError(t, err)
+	assert.Equal(t, 4, *uploads, "b is uploaded again")
+	assert.Equal(t, 2, p.Len())
+	assert.Equal(t, 2, *deletes, "c, unused this frame, is evicted")
+
+	p.DeleteTexture(a)
+	assert.Equal(t, 16, p.Used())
+	assert.Equal(t, 3, *deletes)
+}
+
+func TestCachedTextureProviderNoBudget(t *testing.T) {
+	p, _, deletes := newTestProvider(0)
+	for i := 0; i < 10; i++ {
+		_, err := p.NewTextureFromRGBA(graphic2.NewUniqueRGBA(image.Rect(0, 0, 64, 64)), graphic2.TextureSprite)
+		require.NoError(t, err)
+		p.NextFrame()
+	}
+
+	assert.Equal(t, 10, p.Len())
+	assert.Equal(t, 0, *deletes)
+}
+
+func TestCachedTextureProviderFilters(t *testing.T) {
+	p, _, _ := newTestProvider(0)
+
+	var filters []graphic2.Filter
+	p.upload = func(rgba *graphic2.UniqueRGBA, filter graphic2.Filter) (*graphic2.Texture, error) {
+		filters = append(filters, filter)
+		return &graphic2.Texture{}, nil
+	}
+
+	newImage := func() *graphic2.UniqueRGBA { return graphic2.NewUniqueRGBA(image.Rect(0, 0, 2, 2)) }
+
+	_, _ = p.NewTextureFromRGBA(newImage(), graphic2.TextureSprite)
+	_, _ = p.NewTextureFromRGBA(newImage(), graphic2.TextureGround)
+
+	p.Filters[graphic2.TextureSprite] = graphic2.FilterLinear
+	_, _ = p.NewTextureFromRGBA(newImage(), graphic2.TextureSprite)
+
+	assert.Equal(t, []graphic2.Filter{graphic2.FilterNearest, graphic2.FilterLinear, graphic2.FilterLinear}, filters)
+}
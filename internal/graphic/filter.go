@@ -0,0 +1,56 @@
+package graphic
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TextureClass is the kind of image a texture is made of, each class
+// having its filtering (see TextureProvider).
+type TextureClass int
+
+const (
+	// TextureSprite is the class of the character and effect sprites.
+	TextureSprite TextureClass = iota
+	// TextureGround is the class of the map textures.
+	TextureGround
+)
+
+// Filter is the sampling of a texture when it is scaled.
+type Filter int
+
+const (
+	// FilterNearest keeps the pixels sharp.
+	FilterNearest Filter = iota
+	// FilterLinear blends the pixels, bilinear filtering.
+	FilterLinear
+)
+
+// DefaultFilters are the filters of the classes: crisp sprites, smooth
+// ground.
+var DefaultFilters = map[TextureClass]Filter{
+	TextureSprite: FilterNearest,
+	TextureGround: FilterLinear,
+}
+
+// ParseFilter returns the filter named s, "nearest" or "linear"
+// ("bilinear").
+func ParseFilter(s string) (Filter, error) {
+	switch strings.ToLower(s) {
+	case "nearest":
+		return FilterNearest, nil
+	case "linear", "bilinear":
+		return FilterLinear, nil
+	}
+
+	return 0, errors.Errorf("unknown texture filter '%s', want nearest or linear", s)
+}
+
+func (f Filter) String() string {
+	if f == FilterLinear {
+		return "linear"
+	}
+
+	return "nearest"
+}
@@ -1,8 +1,7 @@
 package geometry
 
 import (
-	"github.com/go-gl/gl/v3.2-core/gl"
-
+	"github.com/project-midgard/midgarts/internal/gl"
 	"github.com/project-midgard/midgarts/internal/graphic"
 	"github.com/project-midgard/midgarts/internal/opengl"
 )
@@ -23,10 +22,16 @@ type Plane struct {
 	positions []float32
 }
 
+// SetBounds sets the size of the plane, its vertices being uploaded again
+// on the next render when it changed.
 func (s *Plane) SetBounds(width, height float32) {
 	w := width / 2
 	h := height / 2
 
+	if s.Geometry != nil && s.positions[3] == w && s.positions[1] == h {
+		return
+	}
+
 	s.positions[0] = -w
 	s.positions[3] = w
 	s.positions[6] = -w
@@ -36,6 +41,12 @@ func (s *Plane) SetBounds(width, height float32) {
 	s.positions[4] = -h
 	s.positions[7] = -h
 	s.positions[10] = h
+
+	if s.Geometry != nil {
+		for _, vbo := range s.Geometry.VBOs() {
+			vbo.Update()
+		}
+	}
 }
 
 func (s *Plane) SetColors(colors []float32) {
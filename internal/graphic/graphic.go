@@ -1,8 +1,10 @@
+//go:build !js
+// +build !js
+
 package graphic
 
 import (
-	"github.com/go-gl/gl/v3.2-core/gl"
-
+	"github.com/project-midgard/midgarts/internal/gl"
 	"github.com/project-midgard/midgarts/internal/opengl"
 )
 
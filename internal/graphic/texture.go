@@ -1,3 +1,6 @@
+//go:build !js
+// +build !js
+
 package graphic
 
 import (
@@ -6,7 +9,7 @@ import (
 	"image/draw"
 	_ "image/png"
 
-	"github.com/go-gl/gl/v3.2-core/gl"
+	"github.com/project-midgard/midgarts/internal/gl"
 )
 
 type Texture struct {
@@ -28,11 +31,22 @@ func (t *Texture) Unbind(unit uint32) {
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 }
 
+// Delete frees the OpenGL texture.
+func (t *Texture) Delete() {
+	gl.DeleteTextures(1, &t.handle)
+	t.handle = 0
+}
+
 type TextureProvider interface {
-	NewTextureFromRGBA(rgba *UniqueRGBA) (tex *Texture, err error)
+	// NewTextureFromRGBA returns the texture of rgba, filtered as the
+	// textures of class.
+	NewTextureFromRGBA(rgba *UniqueRGBA, class TextureClass) (tex *Texture, err error)
+	// DeleteTexture deletes the texture created from rgba, if any.
+	DeleteTexture(rgba *UniqueRGBA)
 }
 
-func NewTextureFromRGBA(rgba *UniqueRGBA) (tex *Texture, err error) {
+// NewTextureFromRGBA uploads rgba to a new texture sampled with filter.
+func NewTextureFromRGBA(rgba *UniqueRGBA, filter Filter) (tex *Texture, err error) {
 	if rgba.Stride != rgba.Rect.Size().X*4 {
 		return nil, fmt.Errorf("unsupported stride")
 	}
@@ -45,8 +59,8 @@ func NewTextureFromRGBA(rgba *UniqueRGBA) (tex *Texture, err error) {
 		internalFormat: gl.RGBA8,
 		format:         gl.RGBA,
 		formatType:     gl.UNSIGNED_BYTE,
-		magFilter:      gl.NEAREST,
-		minFilter:      gl.NEAREST,
+		magFilter:      filter.glFilter(),
+		minFilter:      filter.glFilter(),
 		wrapS:          gl.CLAMP_TO_BORDER,
 		wrapT:          gl.CLAMP_TO_BORDER,
 	}
@@ -75,3 +89,11 @@ func NewTextureFromRGBA(rgba *UniqueRGBA) (tex *Texture, err error) {
 
 	return
 }
+
+func (f Filter) glFilter() int32 {
+	if f == FilterLinear {
+		return gl.LINEAR
+	}
+
+	return gl.NEAREST
+}
@@ -0,0 +1,190 @@
+// Package grfdiff compares two GRF archives, typically two official client
+// releases, and reports the added, removed and modified entries.
+package grfdiff
+
+import (
+	"bytes"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+type ChangeType string
+
+const (
+	Added    = ChangeType("added")
+	Removed  = ChangeType("removed")
+	Modified = ChangeType("modified")
+)
+
+type Category string
+
+const (
+	CategorySprite  = Category("sprites")
+	CategoryMap     = Category("maps")
+	CategoryTable   = Category("tables")
+	CategoryTexture = Category("textures")
+	CategoryModel   = Category("models")
+	CategorySound   = Category("sounds")
+	CategoryOther   = Category("other")
+)
+
+// Categories lists the categories in report order.
+var Categories = []Category{
+	CategoryMap,
+	CategorySprite,
+	CategoryTable,
+	CategoryTexture,
+	CategoryModel,
+	CategorySound,
+	CategoryOther,
+}
+
+type Options struct {
+	// CompareData also compares the data of the entries that have the same
+	// size, instead of only their sizes. It decompresses every entry.
+	CompareData bool
+}
+
+// Change is a single changed entry. Sizes are uncompressed sizes, zero when
+// the entry does not exist on that side.
+type Change struct {
+	Name     string     `json:"name"`
+	Display  string     `json:"display"`
+	Category Category   `json:"category"`
+	Type     ChangeType `json:"type"`
+	OldSize  uint32     `json:"old_size"`
+	NewSize  uint32     `json:"new_size"`
+}
+
+type Report struct {
+	Old     string   `json:"old"`
+	New     string   `json:"new"`
+	Changes []Change `json:"changes"`
+}
+
+// Compare returns the changes going from the oldFile archive to newFile,
+// sorted by entry name. oldName and newName label the archives in the report.
+func Compare(oldFile, newFile *grf.File, oldName, newName string, opts Options) (*Report, error) {
+	oldEntries := entriesByName(oldFile)
+	newEntries := entriesByName(newFile)
+
+	report := &Report{Old: oldName, New: newName}
+
+	for name, o := range oldEntries {
+		n, ok := newEntries[name]
+		if !ok {
+			report.add(name, Removed, o.Header.UncompressedSize, 0)
+			continue
+		}
+
+		modified, err := isModified(oldFile, newFile, o, n, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if modified {
+			report.add(name, Modified, o.Header.UncompressedSize, n.Header.UncompressedSize)
+		}
+	}
+
+	for name, n := range newEntries {
+		if _, ok := oldEntries[name]; !ok {
+			report.add(name, Added, 0, n.Header.UncompressedSize)
+		}
+	}
+
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Name < report.Changes[j].Name
+	})
+
+	return report, nil
+}
+
+// Categorize returns the category of an entry name.
+func Categorize(name string) Category {
+	ext := strings.ToLower(path.Ext(name))
+
+	switch {
+	case strings.HasPrefix(name, "data/sprite/") && (ext == ".spr" || ext == ".act"):
+		return CategorySprite
+	case ext == ".rsw" || ext == ".gnd" || ext == ".gat":
+		return CategoryMap
+	case ext == ".txt" || ext == ".xml" || ext == ".lua" || ext == ".lub":
+		return CategoryTable
+	case strings.HasPrefix(name, "data/texture/"):
+		return CategoryTexture
+	case ext == ".rsm" || ext == ".rsm2":
+		return CategoryModel
+	case ext == ".wav" || ext == ".mp3":
+		return CategorySound
+	default:
+		return CategoryOther
+	}
+}
+
+// Count returns the number of changes of the given category and type.
+func (r *Report) Count(c Category, t ChangeType) (n int) {
+	for _, change := range r.Changes {
+		if change.Category == c && change.Type == t {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (r *Report) add(name string, t ChangeType, oldSize, newSize uint32) {
+	r.Changes = append(r.Changes, Change{
+		Name:     name,
+		Display:  encoding.Display(name),
+		Category: Categorize(name),
+		Type:     t,
+		OldSize:  oldSize,
+		NewSize:  newSize,
+	})
+}
+
+func entriesByName(f *grf.File) map[string]*grf.Entry {
+	res := map[string]*grf.Entry{}
+	for _, entries := range f.GetEntryDirectories() {
+		for _, e := range entries {
+			res[e.Name] = e
+		}
+	}
+
+	return res
+}
+
+func isModified(oldFile, newFile *grf.File, o, n *grf.Entry, opts Options) (bool, error) {
+	if o.Header.UncompressedSize != n.Header.UncompressedSize {
+		return true, nil
+	}
+
+	if !opts.CompareData {
+		return false, nil
+	}
+
+	oldEntry, err := oldFile.GetEntry(o.Name)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not load old entry '%s'", o.Name)
+	}
+
+	newEntry, err := newFile.GetEntry(n.Name)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not load new entry '%s'", n.Name)
+	}
+
+	modified := !bytes.Equal(oldEntry.Data, newEntry.Data)
+
+	// GetEntry keeps the data around, drop it so comparing whole
+	// clients does not load them in memory.
+	oldEntry.Data, newEntry.Data = nil, nil
+
+	return modified, nil
+}
@@ -0,0 +1,84 @@
+package grfdiff
+
+import (
+	"compress/zlib"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+func writeGRF(t *testing.T, path string, entries map[string]string) *grf.File {
+	out, err := os.Create(path)
+	require.NoError(t, err)
+
+	w, err := grf.NewWriter(out, zlib.DefaultCompression)
+	require.NoError(t, err)
+	for name, data := range entries {
+		require.NoError(t, w.Write(name, []byte(data)))
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, out.Close())
+
+	f, err := grf.Load(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	return f
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+
+	oldFile := writeGRF(t, filepath.Join(dir, "old.grf"), map[string]string{
+		"data/prontera.rsw":        "rsw",
+		"data/sprite/npc/a.spr":    "sprite",
+		"data/idnum2itemdesc.txt":  "old desc",
+		"data/texture/removed.bmp": "bmp",
+	})
+	newFile := writeGRF(t, filepath.Join(dir, "new.grf"), map[string]string{
+		"data/prontera.rsw":       "rsw",
+		"data/sprite/npc/a.spr":   "SPRITE",
+		"data/idnum2itemdesc.txt": "new item desc",
+		"data/sprite/npc/b.act":   "act",
+	})
+
+	report, err := Compare(oldFile, newFile, "old", "new", Options{})
+	require.NoError(t, err)
+
+	var got []Change
+	for _, c := range report.Changes {
+		got = append(got, Change{Name: c.Name, Category: c.Category, Type: c.Type})
+	}
+	assert.Equal(t, []Change{
+		{Name: "data/idnum2itemdesc.txt", Category: CategoryTable, Type: Modified},
+		{Name: "data/sprite/npc/b.act", Category: CategorySprite, Type: Added},
+		{Name: "data/texture/removed.bmp", Category: CategoryTexture, Type: Removed},
+	}, got)
+
+	report, err = Compare(oldFile, newFile, "old", "new", Options{CompareData: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Count(CategorySprite, Modified))
+}
+
+func TestCompareUnreadableEntry(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := writeGRF(t, filepath.Join(dir, "old.grf"), map[string]string{"data/a.txt": "a"})
+	newFile := writeGRF(t, filepath.Join(dir, "new.grf"), map[string]string{"data/b.txt": "b"})
+
+	o, err := oldFile.GetEntry("data/a.txt")
+	require.NoError(t, err)
+	missing := &grf.Entry{Name: "data/missing.txt", Header: o.Header}
+
+	_, err = isModified(oldFile, newFile, o, missing, Options{CompareData: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not load new entry 'data/missing.txt'")
+
+	_, err = isModified(oldFile, newFile, missing, o, Options{CompareData: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not load old entry 'data/missing.txt'")
+}
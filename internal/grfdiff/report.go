@@ -0,0 +1,67 @@
+package grfdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Markdown returns the report as a Markdown changelog, grouped by category.
+func (r *Report) Markdown() []byte {
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "# Changes from %s to %s\n\n", r.Old, r.New)
+
+	if len(r.Changes) == 0 {
+		fmt.Fprintf(buf, "No changes.\n")
+		return buf.Bytes()
+	}
+
+	fmt.Fprintf(buf, "| Category | Added | Removed | Modified |\n")
+	fmt.Fprintf(buf, "|----------|-------|---------|----------|\n")
+	for _, c := range Categories {
+		added, removed, modified := r.Count(c, Added), r.Count(c, Removed), r.Count(c, Modified)
+		if added+removed+modified > 0 {
+			fmt.Fprintf(buf, "| %s | %d | %d | %d |\n", c, added, removed, modified)
+		}
+	}
+
+	for _, c := range Categories {
+		for _, t := range []ChangeType{Added, Removed, Modified} {
+			if r.Count(c, t) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(buf, "\n## %s %s\n\n", titled(string(t)), c)
+			for _, change := range r.Changes {
+				if change.Category != c || change.Type != t {
+					continue
+				}
+
+				switch t {
+				case Added:
+					fmt.Fprintf(buf, "- `%s` (%d bytes)\n", change.Display, change.NewSize)
+				case Removed:
+					fmt.Fprintf(buf, "- `%s`\n", change.Display)
+				case Modified:
+					fmt.Fprintf(buf, "- `%s` (%d -> %d bytes)\n", change.Display, change.OldSize, change.NewSize)
+				}
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// JSON returns the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+func titled(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return string(s[0]-'a'+'A') + s[1:]
+}
@@ -0,0 +1,150 @@
+// Package grfhttp serves the entries of a GRF archive over HTTP and loads
+// them back, so the clients without a file system, such as the
+// WebAssembly build in a browser, where net/http goes through fetch, load
+// their assets from a remote archive.
+//
+// An entry is served at its name, the lowercased, slash separated name of
+// the archive (see grf.File.GetEntry), each segment path escaped.
+package grfhttp
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/logging"
+)
+
+// EntryLoader loads the entries of an archive, such as grf.File.
+type EntryLoader interface {
+	GetEntryContext(ctx context.Context, name string) (*grf.Entry, error)
+}
+
+// Handler serves the entries of an archive, under its root.
+type Handler struct {
+	loader EntryLoader
+	log    zerolog.Logger
+}
+
+// NewHandler returns the handler of the entries of loader, logging to the
+// logger of ctx (see logging.For). Mount it with http.StripPrefix to serve
+// the entries under a path.
+func NewHandler(ctx context.Context, loader EntryLoader) *Handler {
+	return &Handler{loader: loader, log: logging.For(ctx, logging.GRFHTTP)}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The pages of the demos may be served from another origin.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	e, err := h.loader.GetEntryContext(r.Context(), name)
+	if err != nil {
+		h.log.Debug().Err(err).Str("entry", name).Msg("entry not served")
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", fmt.Sprint(len(e.Data)))
+	_, _ = w.Write(e.Data)
+}
+
+// Loader loads the entries served by a Handler.
+type Loader struct {
+	// BaseURL is the URL the entries are served under, e.g.
+	// "http://localhost:8080/grf/".
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewLoader returns the loader of the entries served under baseURL.
+func NewLoader(baseURL string) *Loader {
+	return &Loader{BaseURL: strings.TrimSuffix(baseURL, "/") + "/", Client: http.DefaultClient}
+}
+
+// URL returns the URL of the entry name. The server looks the name up
+// case insensitively, as the archives do.
+func (l *Loader) URL(name string) string {
+	segments := strings.Split(entryName(name), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return l.BaseURL + strings.Join(segments, "/")
+}
+
+// GetEntry returns the entry name, with its data.
+func (l *Loader) GetEntry(name string) (*grf.Entry, error) {
+	return l.GetEntryContext(context.Background(), name)
+}
+
+// GetEntryContext is like GetEntry, the request being canceled once ctx
+// is done.
+func (l *Loader) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.URL(name), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not request entry '%s'", name)
+	}
+
+	resp, err := l.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not request entry '%s'", name)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("could not find entry '%s': %s", name, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read entry '%s'", name)
+	}
+
+	return &grf.Entry{Name: strings.ToLower(entryName(name)), Data: data}, nil
+}
+
+// GetSpriteFilesContext loads the ACT and SPR files of the sprite name,
+// without extension, as grf.File.GetSpriteFilesContext.
+func (l *Loader) GetSpriteFilesContext(ctx context.Context, name string) (grf.ActionSpriteFilePair, error) {
+	e, err := l.GetEntryContext(ctx, name+".act")
+	if err != nil {
+		return grf.ActionSpriteFilePair{}, err
+	}
+
+	actFile, err := act.Load(e.Data)
+	if err != nil {
+		return grf.ActionSpriteFilePair{}, err
+	}
+
+	if e, err = l.GetEntryContext(ctx, name+".spr"); err != nil {
+		return grf.ActionSpriteFilePair{}, err
+	}
+
+	sprFile, err := spr.Load(e.Data)
+	if err != nil {
+		return grf.ActionSpriteFilePair{}, err
+	}
+
+	return grf.ActionSpriteFilePair{ACT: actFile, SPR: sprFile}, nil
+}
+
+// entryName returns name slash separated, like the entry names.
+func entryName(name string) string {
+	return strings.ReplaceAll(name, `\`, `/`)
+}
@@ -0,0 +1,87 @@
+package grfhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+// fakeLoader serves the entries of a map.
+type fakeLoader map[string][]byte
+
+func (l fakeLoader) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	data, ok := l[name]
+	if !ok {
+		return nil, errors.Errorf("could not find entry '%s'", name)
+	}
+
+	return &grf.Entry{Name: name, Data: data}, nil
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle("/grf/", http.StripPrefix("/grf", NewHandler(context.Background(), fakeLoader{
+		"data/sprite/ÀÎ°£Á·/¸öÅë/³²/Ãʺ¸ÀÚ_³².spr": []byte("SP"),
+		"data/prontera.gat":       []byte("GRAT"),
+		"data/sprite/invalid.act": []byte("XX"),
+	})))
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestLoader(t *testing.T) {
+	s := newServer(t)
+	l := NewLoader(s.URL + "/grf")
+
+	e, err := l.GetEntry(`data\prontera.gat`)
+	require.NoError(t, err)
+	assert.Equal(t, "data/prontera.gat", e.Name)
+	assert.Equal(t, []byte("GRAT"), e.Data)
+
+	// The raw Korean names, read as Latin-1, go through the URL intact.
+	e, err = l.GetEntry("data/sprite/ÀÎ°£Á·/¸öÅë/³²/Ãʺ¸ÀÚ_³².spr")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("SP"), e.Data)
+
+	_, err = l.GetEntry("data/missing.gat")
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.GetEntryContext(ctx, "data/prontera.gat")
+	assert.Error(t, err)
+}
+
+func TestLoaderSpriteFiles(t *testing.T) {
+	l := NewLoader(newServer(t).URL + "/grf/")
+
+	_, err := l.GetSpriteFilesContext(context.Background(), "data/sprite/missing")
+	assert.Error(t, err)
+	_, err = l.GetSpriteFilesContext(context.Background(), "data/sprite/invalid")
+	assert.Error(t, err)
+}
+
+func TestLoaderURL(t *testing.T) {
+	l := NewLoader("http://localhost:8080/grf/")
+	assert.Equal(t, "http://localhost:8080/grf/data/A%20b/c%3F.spr", l.URL(`data\A b/c?.spr`))
+}
+
+func TestHandlerMethod(t *testing.T) {
+	resp, err := http.Post(newServer(t).URL+"/grf/data/prontera.gat", "text/plain", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+}
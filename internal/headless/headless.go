@@ -0,0 +1,88 @@
+// Package headless is a render backend without GPU nor window: it draws
+// the poses of the characters (see package animation) into images, the
+// sprites composed as the OpenGL backend does, for the golden tests of the
+// render pipeline (see package golden) and the tools.
+//
+// As by the OpenGL backend, the layers are mirrored, scaled and turned
+// by their angle (see portrait.LayerImage), but their colors are not
+// applied.
+package headless
+
+import (
+	"image"
+	"image/draw"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/animation"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/portrait"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+)
+
+// layer is a layer of a placement, placed from the origin of the
+// character.
+type layer struct {
+	img *image.RGBA
+	min image.Point
+}
+
+// Character draws char seen from directions, elapsed after the start of
+// its animation, on an image fitting its layers. It returns the image and
+// the position of the feet of char in it.
+func Character(char *entity.Character, directions pkgcharacter.DirectionResolver, elapsed time.Duration) (*image.RGBA, image.Point, error) {
+	layers := placeLayers(animation.Pose(char, directions, elapsed))
+
+	var bounds image.Rectangle
+	for _, l := range layers {
+		bounds = bounds.Union(image.Rectangle{Min: l.min, Max: l.min.Add(l.img.Bounds().Size())})
+	}
+	if bounds.Empty() {
+		return nil, image.Point{}, errors.New("character has no visible layers")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	origin := image.Point{}.Sub(bounds.Min)
+	drawLayers(img, origin, layers)
+
+	return img, origin, nil
+}
+
+// Draw draws placements on dst, back to front, the origin of the character
+// at origin.
+func Draw(dst *image.RGBA, origin image.Point, placements []animation.Placement) {
+	drawLayers(dst, origin, placeLayers(placements))
+}
+
+func drawLayers(dst *image.RGBA, origin image.Point, layers []layer) {
+	for _, l := range layers {
+		min := l.min.Add(origin)
+		draw.Draw(dst, image.Rectangle{Min: min, Max: min.Add(l.img.Bounds().Size())}, l.img, image.Point{}, draw.Over)
+	}
+}
+
+// placeLayers returns the layers of placements, back to front, centered on
+// their positions as the render system centers the sprites.
+func placeLayers(placements []animation.Placement) []layer {
+	var layers []layer
+	for _, p := range placements {
+		for _, l := range p.Layers {
+			img := portrait.LayerImage(p.SPR, l)
+			if img == nil {
+				continue
+			}
+
+			size := img.Bounds().Size()
+			layers = append(layers, layer{
+				img: img,
+				min: image.Point{
+					X: int(float32(l.Position[0])+p.Position[0]) - size.X/2,
+					Y: int(float32(l.Position[1])+p.Position[1]) - size.Y/2,
+				},
+			})
+		}
+	}
+
+	return layers
+}
@@ -0,0 +1,226 @@
+package headless
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/animation"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/actionindex"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/golden"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+)
+
+// camera is the camera of the client, from which the character
+// directions are seen as is.
+var camera = pkgcharacter.ResolverForCameraDirection(pkgcharacter.DefaultCameraDirection)
+
+// directions are the directions of the characters, by golden name.
+var directions = []struct {
+	name string
+	dir  directiontype.Type
+}{
+	{"s", directiontype.South}, {"sw", directiontype.SouthWest}, {"w", directiontype.West}, {"nw", directiontype.NorthWest},
+	{"n", directiontype.North}, {"ne", directiontype.NorthEast}, {"e", directiontype.East}, {"se", directiontype.SouthEast},
+}
+
+// actions are the actions of the golden images, with their frame counts
+// in the test sprites.
+var actions = []struct {
+	name   string
+	index  actionindex.Type
+	frames int
+}{
+	{"idle", actionindex.Idle, 1},
+	{"walk", actionindex.Walking, 2},
+}
+
+// frameTime returns the time frame is shown at, in the middle of it.
+func frameTime(frame int) time.Duration {
+	return time.Duration(frame)*act.ActionDefaultDelay*time.Millisecond + act.ActionDefaultDelay*time.Millisecond/2
+}
+
+// newSprite returns an RGBA sprite of a frame of w by h pixels per color,
+// each a gradient of its color from left to right so the mirrored frames
+// differ.
+func newSprite(w, h int, colors ...[3]byte) *spr.SpriteFile {
+	f := &spr.SpriteFile{}
+	for _, c := range colors {
+		data := make([]byte, w*h*4)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				shade := 64 + 191*x/(w-1)
+				// Stored as ABGR.
+				i := (y*w + x) * 4
+				data[i], data[i+1], data[i+2], data[i+3] = 255, byte(int(c[2])*shade/255), byte(int(c[1])*shade/255), byte(int(c[0])*shade/255)
+			}
+		}
+		f.Frames = append(f.Frames, &spr.SpriteFrame{SpriteType: spr.FileTypeRGBA, Width: uint16(w), Height: uint16(h), Data: data})
+	}
+	f.Images = make([]*graphic.UniqueRGBA, len(f.Frames))
+
+	return f
+}
+
+// newACT returns the actions of the test characters: 8 idle ones, then 8
+// walk ones, each a frame of a layer made by layer, anchored at anchor.
+func newACT(anchor [2]int32, layer func(dir, frame int) *act.ActionFrameLayer) *act.ActionFile {
+	f := &act.ActionFile{}
+	for _, a := range actions {
+		for dir := 0; dir < 8; dir++ {
+			action := &act.Action{}
+			for frame := 0; frame < a.frames; frame++ {
+				action.Frames = append(action.Frames, &act.ActionFrame{
+					Layers:  []*act.ActionFrameLayer{layer(dir, frame)},
+					Anchors: []act.AnchorPoint{{X: anchor[0], Y: anchor[1]}},
+				})
+			}
+			f.Actions = append(f.Actions, action)
+		}
+	}
+
+	return f
+}
+
+// newCharacter returns a character of a body and a head, showing its
+// direction: the body frame alternates, the ones facing east are mirrored,
+// and the walk bobs.
+func newCharacter() *entity.Character {
+	body := grf.ActionSpriteFilePair{
+		SPR: newSprite(10, 18, [3]byte{220, 60, 40}, [3]byte{40, 160, 60}),
+		ACT: newACT([2]int32{0, -22}, func(dir, frame int) *act.ActionFrameLayer {
+			return &act.ActionFrameLayer{
+				Position:         [2]int32{int32(2 * frame), int32(-9 - frame)},
+				SpriteFrameIndex: int32(dir % 2),
+				Mirrored:         dir >= 5,
+			}
+		}),
+	}
+	head := grf.ActionSpriteFilePair{
+		SPR: newSprite(8, 8, [3]byte{60, 80, 230}),
+		ACT: newACT([2]int32{1, 2}, func(dir, frame int) *act.ActionFrameLayer {
+			return &act.ActionFrameLayer{
+				Position:         [2]int32{0, -4},
+				SpriteFrameIndex: 0,
+				Mirrored:         dir >= 5,
+			}
+		}),
+	}
+
+	char := entity.NewCharacter(character.Male, jobspriteid.Novice, 1)
+	char.SetCharacterAttachmentComponent(&component.CharacterAttachmentComponent{
+		Files: map[character.AttachmentType]grf.ActionSpriteFilePair{
+			character.AttachmentBody: body,
+			character.AttachmentHead: head,
+		},
+	})
+
+	return char
+}
+
+func TestCharacterGolden(t *testing.T) {
+	char := newCharacter()
+	for _, a := range actions {
+		for _, d := range directions {
+			for frame := 0; frame < a.frames; frame++ {
+				char.ActionIndex, char.Direction = a.index, d.dir
+
+				img, origin, err := Character(char, camera, frameTime(frame))
+				require.NoError(t, err)
+				golden.Assert(t, fmt.Sprintf("character_%s_%s_%d", a.name, d.name, frame), img)
+				// The bottom of the body, bobbing up a pixel in the walk.
+				assert.Equal(t, img.Bounds().Dy()+frame, origin.Y)
+			}
+		}
+	}
+}
+
+func TestCharacterRotatedGolden(t *testing.T) {
+	char := newCharacter()
+	char.ActionIndex, char.Direction = actionindex.Idle, directiontype.South
+
+	// The body leans, turned clockwise as by the render system.
+	files := char.Files[character.AttachmentBody]
+	files.ACT = newACT([2]int32{0, -22}, func(dir, frame int) *act.ActionFrameLayer {
+		return &act.ActionFrameLayer{Position: [2]int32{0, -9}, Angle: 30}
+	})
+	char.Files[character.AttachmentBody] = files
+
+	img, _, err := Character(char, camera, frameTime(0))
+	require.NoError(t, err)
+	golden.Assert(t, "character_rotated", img)
+}
+
+func TestDraw(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 40))
+
+	// A row of characters, the ones behind first.
+	for i, d := range []directiontype.Type{directiontype.North, directiontype.SouthWest, directiontype.East} {
+		char := newCharacter()
+		char.ActionIndex, char.Direction = actionindex.Walking, d
+		Draw(img, image.Point{X: 12 + 20*i, Y: 36 - 2*i}, animation.Pose(char, camera, frameTime(i)))
+	}
+
+	golden.Assert(t, "scene", img)
+}
+
+func TestCharacterWithoutLayers(t *testing.T) {
+	_, _, err := Character(entity.NewCharacter(character.Male, jobspriteid.Novice, 1), camera, 0)
+	assert.Error(t, err)
+}
+
+// TestGRFCharacters renders the first frame of the idle, walk and attack
+// actions of every job in every direction, from the sprites of the GRF of
+// GRF_FILE_PATH. The sprites of the official clients are not checked in,
+// nor their golden images: run it with GOLDEN_UPDATE=1 first, then after
+// the changes of the rendering.
+func TestGRFCharacters(t *testing.T) {
+	path := os.Getenv("GRF_FILE_PATH")
+	if path == "" {
+		t.Skip("GRF_FILE_PATH is not set")
+	}
+
+	f, err := grf.Load(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, job := range jobspriteid.All() {
+		cmp, err := component.NewCharacterAttachmentComponent(ctx, f, component.CharacterAttachmentComponentConfig{
+			Gender:      character.Male,
+			JobSpriteID: job,
+			HeadIndex:   1,
+		})
+		require.NoError(t, err, job.String())
+
+		char := entity.NewCharacter(character.Male, job, 1)
+		char.SetCharacterAttachmentComponent(cmp)
+		for _, a := range []struct {
+			name  string
+			index actionindex.Type
+		}{{"idle", actionindex.Idle}, {"walk", actionindex.Walking}, {"attack", actionindex.Attacking1}} {
+			for _, d := range directions {
+				char.ActionIndex, char.Direction = a.index, d.dir
+
+				img, _, err := Character(char, camera, 0)
+				require.NoError(t, err)
+				golden.Assert(t, fmt.Sprintf("grf/%s_%s_%s", strings.ToLower(job.String()), a.name, d.name), img)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package hitbox
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/graphic"
+)
+
+// newSprite returns a 4x4 paletted sprite whose only opaque pixels are
+// (1, 1) and (2, 2).
+func newSprite() *spr.SpriteFile {
+	data := make([]byte, 16)
+	data[1+1*4] = 1
+	data[2+2*4] = 1
+
+	f := &spr.SpriteFile{
+		Frames: []*spr.SpriteFrame{{SpriteType: spr.FileTypePAL, Width: 4, Height: 4, Data: data}},
+		Images: make([]*graphic.UniqueRGBA, 1),
+	}
+	f.Header.PalettedFrameCount = 1
+
+	return f
+}
+
+func TestFrame(t *testing.T) {
+	sprFile := newSprite()
+
+	tests := []struct {
+		Name     string
+		Layer    act.ActionFrameLayer
+		Expected image.Rectangle
+	}{
+		{
+			Name:     "centered layer",
+			Layer:    act.ActionFrameLayer{Scale: [2]float32{1, 1}},
+			Expected: image.Rect(-1, -1, 1, 1),
+		},
+		{
+			Name:     "moved layer",
+			Layer:    act.ActionFrameLayer{Position: [2]int32{10, -5}, Scale: [2]float32{1, 1}},
+			Expected: image.Rect(9, -6, 11, -4),
+		},
+		{
+			Name:     "scaled layer",
+			Layer:    act.ActionFrameLayer{Scale: [2]float32{2, 2}},
+			Expected: image.Rect(-2, -2, 2, 2),
+		},
+		{
+			Name:     "missing sprite frame",
+			Layer:    act.ActionFrameLayer{SpriteFrameIndex: 3},
+			Expected: image.Rectangle{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			layer := tt.Layer
+			frame := &act.ActionFrame{Layers: []*act.ActionFrameLayer{&layer}}
+
+			assert.Equal(t, tt.Expected, Frame(sprFile, frame))
+		})
+	}
+}
+
+func TestFrameMirrored(t *testing.T) {
+	sprFile := newSprite()
+	sprFile.Frames[0].Data = make([]byte, 16)
+	sprFile.Frames[0].Data[0] = 1
+
+	frame := &act.ActionFrame{Layers: []*act.ActionFrameLayer{
+		{Scale: [2]float32{1, 1}, Mirrored: true},
+	}}
+
+	assert.Equal(t, image.Rect(1, -2, 2, -1), Frame(sprFile, frame))
+}
+
+func TestFrameOddSize(t *testing.T) {
+	// A 3x3 sprite whose only opaque pixel is (0, 0).
+	data := make([]byte, 9)
+	data[0] = 1
+	sprFile := &spr.SpriteFile{
+		Frames: []*spr.SpriteFrame{{SpriteType: spr.FileTypePAL, Width: 3, Height: 3, Data: data}},
+		Images: make([]*graphic.UniqueRGBA, 1),
+	}
+	sprFile.Header.PalettedFrameCount = 1
+
+	layer := &act.ActionFrameLayer{Scale: [2]float32{1, 1}}
+	frame := &act.ActionFrame{Layers: []*act.ActionFrameLayer{layer}}
+	assert.Equal(t, image.Rect(-1, -1, 0, 0), Frame(sprFile, frame))
+
+	// Mirrored around the center of the middle pixel.
+	layer.Mirrored = true
+	assert.Equal(t, image.Rect(1, -1, 2, 0), Frame(sprFile, frame))
+}
+
+func TestCache(t *testing.T) {
+	sprFile := newSprite()
+	frame := &act.ActionFrame{Layers: []*act.ActionFrameLayer{{Scale: [2]float32{1, 1}}}}
+
+	c := NewCache()
+	assert.Equal(t, image.Rect(-1, -1, 1, 1), c.Frame(sprFile, frame))
+
+	// Cached bounds are kept until the files are replaced.
+	frame.Layers[0].Position = [2]int32{5, 5}
+	assert.Equal(t, image.Rect(-1, -1, 1, 1), c.Frame(sprFile, frame))
+	assert.Equal(t, image.Rect(4, 4, 6, 6), c.Frame(newSprite(), frame))
+}
@@ -0,0 +1,137 @@
+// Package hotreload watches asset files for changes in development mode.
+package hotreload
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+const DefaultInterval = 500 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// Watcher polls a set of files and reports the keys of the ones that were
+// created, modified or removed. Polling keeps it dependency free and works
+// the same on every platform, which is plenty for a handful of assets.
+type Watcher struct {
+	interval time.Duration
+	changes  chan string
+
+	mu    sync.Mutex
+	files map[string]map[string]fileState // key -> path -> state
+
+	done chan struct{}
+	once sync.Once
+}
+
+func NewWatcher(interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	w := &Watcher{
+		interval: interval,
+		changes:  make(chan string, 64),
+		files:    map[string]map[string]fileState{},
+		done:     make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Watch watches the given paths, reporting key when any of them changes.
+// Paths that do not exist yet are reported once created.
+func (w *Watcher) Watch(key string, paths ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	states, ok := w.files[key]
+	if !ok {
+		states = map[string]fileState{}
+		w.files[key] = states
+	}
+
+	for _, path := range paths {
+		if _, ok := states[path]; !ok {
+			states[path] = stat(path)
+		}
+	}
+}
+
+func (w *Watcher) Unwatch(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.files, key)
+}
+
+// Changes returns the channel the changed keys are sent to. A key is sent
+// once per poll at most; keys are dropped when the channel is full, the
+// next change reporting them again.
+func (w *Watcher) Changes() <-chan string {
+	return w.changes
+}
+
+func (w *Watcher) Close() {
+	w.once.Do(func() {
+		close(w.done)
+	})
+}
+
+func (w *Watcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			for _, key := range w.poll() {
+				select {
+				case w.changes <- key:
+				default:
+				}
+			}
+		}
+	}
+}
+
+func (w *Watcher) poll() (changed []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for key, states := range w.files {
+		keyChanged := false
+
+		for path, prev := range states {
+			cur := stat(path)
+			if cur != prev {
+				states[path] = cur
+				keyChanged = true
+			}
+		}
+
+		if keyChanged {
+			changed = append(changed, key)
+		}
+	}
+
+	return changed
+}
+
+func stat(path string) fileState {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+
+	return fileState{modTime: fi.ModTime(), size: fi.Size(), exists: true}
+}
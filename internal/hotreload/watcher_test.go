@@ -0,0 +1,39 @@
+package hotreload
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sprite.spr")
+
+	w := NewWatcher(10 * time.Millisecond)
+	defer w.Close()
+
+	w.Watch("data/sprite", path, filepath.Join(dir, "other.spr"))
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("created"), 0644))
+
+	select {
+	case key := <-w.Changes():
+		assert.Equal(t, "data/sprite", key)
+	case <-time.After(time.Second):
+		t.Fatal("no change reported")
+	}
+
+	w.Unwatch("data/sprite")
+	require.NoError(t, ioutil.WriteFile(path, []byte("modified"), 0644))
+
+	select {
+	case key := <-w.Changes():
+		t.Fatalf("unexpected change of %s", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
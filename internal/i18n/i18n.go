@@ -0,0 +1,209 @@
+// Package i18n translates the text shown by the client. The strings are
+// looked up by key in language packs: the ones embedded in packs/, the
+// <language>.yaml files of a folder (see Catalog.LoadDir) and the
+// msgstringtable.txt of the data.
+package i18n
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLanguage is the fallback of every language.
+const DefaultLanguage = "en"
+
+// Key identifies a string. Its translations are fmt formats, taking the
+// same arguments in every language.
+type Key string
+
+const (
+	// WindowTitle takes the client version.
+	WindowTitle Key = "window.title"
+	// LoadingProgress takes the title, then the loaded and total assets.
+	LoadingProgress Key = "loading.progress"
+	CrashTitle      Key = "crash.title"
+	// CrashMessage takes the path of the report.
+	CrashMessage Key = "crash.message"
+)
+
+// MsgString returns the key of the line n, counted from 0, of the
+// msgstringtable.txt of the data.
+func MsgString(n int) Key {
+	return Key(fmt.Sprintf("msgstringtable.%d", n))
+}
+
+// Pack holds the strings of a language.
+type Pack map[Key]string
+
+//go:embed packs/*.yaml
+var embedded embed.FS
+
+// Catalog holds the packs of the languages.
+type Catalog struct {
+	packs map[string]Pack
+}
+
+// NewCatalog returns a catalog of the embedded packs.
+func NewCatalog() (*Catalog, error) {
+	c := &Catalog{packs: map[string]Pack{}}
+
+	names, err := embedded.ReadDir("packs")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		f, err := embedded.Open(path.Join("packs", name.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.LoadPack(strings.TrimSuffix(name.Name(), ".yaml"), f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Add adds the strings of p to the pack of lang, replacing the ones
+// already there.
+func (c *Catalog) Add(lang string, p Pack) {
+	pack, ok := c.packs[lang]
+	if !ok {
+		pack = Pack{}
+		c.packs[lang] = pack
+	}
+
+	for key, s := range p {
+		pack[key] = s
+	}
+}
+
+// LoadPack adds the strings of a YAML pack, a map of keys to strings.
+func (c *Catalog) LoadPack(lang string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	p := Pack{}
+	if err = yaml.Unmarshal(data, &p); err != nil {
+		return errors.Wrapf(err, "invalid '%s' language pack", lang)
+	}
+
+	c.Add(lang, p)
+	return nil
+}
+
+// LoadDir adds the packs of the <language>.yaml files of dir, e.g.
+// de.yaml, overriding the embedded strings.
+func (c *Catalog) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+
+		err = c.LoadPack(strings.TrimSuffix(filepath.Base(p), ".yaml"), f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadMsgStringTable adds the lines of a msgstringtable.txt, each ending
+// with '#', to the pack of lang under the MsgString keys. The lines are
+// converted with decode, the data being CP949 in the Korean clients.
+func (c *Catalog) LoadMsgStringTable(lang string, r io.Reader, decode func([]byte) (string, error)) error {
+	p := Pack{}
+
+	scanner := bufio.NewScanner(r)
+	for n := 0; scanner.Scan(); n++ {
+		s, err := decode(scanner.Bytes())
+		if err != nil {
+			return errors.Wrapf(err, "invalid msgstringtable line %d", n+1)
+		}
+
+		p[MsgString(n)] = strings.TrimSuffix(strings.TrimRight(s, "\r"), "#")
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	c.Add(lang, p)
+	return nil
+}
+
+// Languages returns the languages of the catalog, sorted.
+func (c *Catalog) Languages() []string {
+	langs := make([]string, 0, len(c.packs))
+	for lang := range c.packs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	return langs
+}
+
+// Translator returns the translator of lang. The strings missing in lang
+// are looked up in its base language, e.g. "pt" for "pt-BR", then in the
+// DefaultLanguage.
+func (c *Catalog) Translator(lang string) *Translator {
+	t := &Translator{lang: lang}
+
+	for _, l := range []string{lang, strings.SplitN(lang, "-", 2)[0], DefaultLanguage} {
+		if p, ok := c.packs[l]; ok {
+			t.packs = append(t.packs, p)
+		}
+	}
+
+	return t
+}
+
+// Translator returns the strings of a language.
+type Translator struct {
+	lang  string
+	packs []Pack
+}
+
+func (t *Translator) Language() string {
+	return t.lang
+}
+
+// T returns the string of key formatted with args, or the key itself if
+// no pack has it.
+func (t *Translator) T(key Key, args ...interface{}) string {
+	for _, p := range t.packs {
+		if s, ok := p[key]; ok {
+			if len(args) == 0 {
+				return s
+			}
+			return fmt.Sprintf(s, args...)
+		}
+	}
+
+	return string(key)
+}
@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/pkg/encoding"
+)
+
+func TestTranslator(t *testing.T) {
+	c, err := NewCatalog()
+	require.NoError(t, err)
+	assert.Contains(t, c.Languages(), DefaultLanguage)
+
+	en := c.Translator("en")
+	assert.Equal(t, "Midgarts Client - 1.0", en.T(WindowTitle, "1.0"))
+	assert.Equal(t, "missing.key", en.T("missing.key"))
+
+	c.Add("pt", Pack{"greeting": "Olá"})
+	c.Add("pt-BR", Pack{CrashTitle: "Travou"})
+
+	ptBR := c.Translator("pt-BR")
+	assert.Equal(t, "Travou", ptBR.T(CrashTitle))
+	assert.Equal(t, "Olá", ptBR.T("greeting"))
+	assert.Equal(t, "Um relatório de erro foi salvo em crash.txt.", ptBR.T(CrashMessage, "crash.txt"))
+
+	assert.Equal(t, en.T(CrashTitle), c.Translator("xx").T(CrashTitle))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.yaml"), []byte("crash.title: \"Midgarts ist abgestürzt\"\n"), 0644))
+
+	c, err := NewCatalog()
+	require.NoError(t, err)
+	require.NoError(t, c.LoadDir(dir))
+	assert.Equal(t, "Midgarts ist abgestürzt", c.Translator("de").T(CrashTitle))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "it.yaml"), []byte("- not a map\n"), 0644))
+	assert.Error(t, c.LoadDir(dir))
+}
+
+func TestLoadMsgStringTable(t *testing.T) {
+	c, err := NewCatalog()
+	require.NoError(t, err)
+
+	table, err := encoding.EncodeCP949("서버 연결 실패#\r\nOK#\r\n")
+	require.NoError(t, err)
+	require.NoError(t, c.LoadMsgStringTable("ko", strings.NewReader(string(table)), encoding.DecodeCP949))
+
+	ko := c.Translator("ko")
+	assert.Equal(t, "서버 연결 실패", ko.T(MsgString(0)))
+	assert.Equal(t, "OK", ko.T(MsgString(1)))
+	assert.Equal(t, "msgstringtable.2", ko.T(MsgString(2)))
+}
@@ -0,0 +1,94 @@
+// Package lod lowers the detail of the characters as the camera zooms
+// out, so the crowds of the sieges stay renderable: past a camera
+// distance, the small attachments are not drawn, and the characters far
+// from the player are animated less often, less and less as the camera
+// goes farther.
+package lod
+
+import (
+	"github.com/project-midgard/midgarts/internal/character"
+)
+
+const (
+	// DefaultDistance is the camera distance, in world units, from which
+	// the detail is lowered (see camera.Camera.Distance).
+	DefaultDistance = 60
+	// DefaultStep is the camera distance past Distance after which the
+	// animations of the distant characters slow down again.
+	DefaultStep = 15
+	// DefaultNear is the distance, in cells, from the player within which
+	// the characters are animated every frame whatever the zoom.
+	DefaultNear = 10
+	// DefaultMaxLevel bounds the level: the distant characters are
+	// animated at least every 2^DefaultMaxLevel frames.
+	DefaultMaxLevel = 3
+)
+
+// LOD is the level of detail of the characters, from the distance of the
+// camera. At level 0, under Distance, everything is drawn every frame.
+// From level 1 the small attachments are not drawn and the characters
+// farther than Near from the player are animated every 2^level frames,
+// the level going up every Step farther.
+type LOD struct {
+	// Distance is the camera distance from which the detail is lowered, 0
+	// to never lower it.
+	Distance float32
+	Step     float32
+	Near     float32
+	MaxLevel int
+
+	level int
+	frame uint64
+}
+
+// New returns the level of detail lowered from distance, 0 to never lower
+// it.
+func New(distance float32) *LOD {
+	return &LOD{Distance: distance, Step: DefaultStep, Near: DefaultNear, MaxLevel: DefaultMaxLevel}
+}
+
+// Observe starts a new frame, seen from a camera at cameraDistance.
+func (l *LOD) Observe(cameraDistance float32) {
+	l.frame++
+
+	l.level = 0
+	if l.Distance <= 0 || cameraDistance < l.Distance {
+		return
+	}
+
+	l.level = 1
+	if l.Step > 0 {
+		l.level += int((cameraDistance - l.Distance) / l.Step)
+	}
+	if l.level > l.MaxLevel {
+		l.level = l.MaxLevel
+	}
+}
+
+// Level returns the level of detail of the frame, 0 when everything is
+// drawn every frame.
+func (l *LOD) Level() int {
+	return l.level
+}
+
+// Draws tells whether the attachment a is drawn this frame: the shadows
+// and the shields are not from level 1.
+func (l *LOD) Draws(a character.AttachmentType) bool {
+	if l.level == 0 {
+		return true
+	}
+
+	return a != character.AttachmentShadow && a != character.AttachmentShield
+}
+
+// Skip tells whether the character id, at distance cells from the player,
+// skips its animation this frame. The distant characters are animated in
+// turns, a share of them every frame.
+func (l *LOD) Skip(id uint64, distance float32) bool {
+	if l.level == 0 || distance <= l.Near {
+		return false
+	}
+
+	period := uint64(1) << uint(l.level)
+	return (l.frame+id)%period != 0
+}
@@ -0,0 +1,61 @@
+package lod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character"
+)
+
+func TestLevel(t *testing.T) {
+	l := New(DefaultDistance)
+
+	for distance, level := range map[float32]int{
+		30:                               0,
+		DefaultDistance:                  1,
+		DefaultDistance + DefaultStep:    2,
+		DefaultDistance + 10*DefaultStep: DefaultMaxLevel,
+		DefaultDistance - 1:              0,
+	} {
+		l.Observe(distance)
+		assert.Equal(t, level, l.Level(), "distance %f", distance)
+	}
+
+	l.Distance = 0
+	l.Observe(100)
+	assert.Equal(t, 0, l.Level())
+}
+
+func TestDraws(t *testing.T) {
+	l := New(DefaultDistance)
+
+	l.Observe(30)
+	for _, a := range character.Attachments() {
+		assert.True(t, l.Draws(a), a.String())
+	}
+
+	l.Observe(DefaultDistance)
+	assert.False(t, l.Draws(character.AttachmentShadow))
+	assert.False(t, l.Draws(character.AttachmentShield))
+	assert.True(t, l.Draws(character.AttachmentBody))
+	assert.True(t, l.Draws(character.AttachmentHead))
+}
+
+func TestSkip(t *testing.T) {
+	l := New(DefaultDistance)
+
+	l.Observe(30)
+	assert.False(t, l.Skip(1, 100))
+
+	// Every 4 frames at level 2, the near ones every frame.
+	var animated int
+	for i := 0; i < 8; i++ {
+		l.Observe(DefaultDistance + DefaultStep)
+		assert.False(t, l.Skip(1, DefaultNear))
+		if !l.Skip(1, DefaultNear+1) {
+			animated++
+		}
+	}
+	assert.Equal(t, 2, animated)
+}
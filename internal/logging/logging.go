@@ -0,0 +1,116 @@
+// Package logging scopes the logs of the library packages (systems,
+// loaders, servers) by subsystem. The logger and the subsystem levels are
+// carried by the context the packages are given, so programs embedding
+// them decide where the logs go and how verbose each subsystem is.
+package logging
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// SubsystemField is the field naming the subsystem of a log entry.
+const SubsystemField = "subsystem"
+
+// Subsystems of the library packages.
+const (
+	Render      = "render"
+	Action      = "action"
+	AssetReload = "assetreload"
+	GATOverlay  = "gatoverlay"
+	OpenGL      = "opengl"
+	DebugServer = "debugserver"
+	Asset       = "asset"
+	Script      = "script"
+	Scene       = "scene"
+	MapStream   = "mapstream"
+	GRFHTTP     = "grfhttp"
+	AssetServer = "assetserver"
+	Cinematic   = "cinematic"
+	Weather     = "weather"
+	MapModel    = "mapmodel"
+	Effect      = "effect"
+	Audio       = "audio"
+)
+
+// Levels are the minimum levels of some subsystems, by name. Subsystems
+// not listed log at the level of the logger.
+//
+// The global zerolog level filters every logger, so a subsystem can only
+// be more verbose than its logger when the global level lets it: programs
+// set the default level on their logger and the global level to Lowest.
+type Levels map[string]zerolog.Level
+
+// Lowest returns the most verbose of level and the subsystem levels.
+func (l Levels) Lowest(level zerolog.Level) zerolog.Level {
+	for _, subsystemLevel := range l {
+		if subsystemLevel < level {
+			level = subsystemLevel
+		}
+	}
+
+	return level
+}
+
+type loggerKey struct{}
+
+type levelsKey struct{}
+
+// WithLogger returns a context whose library packages log to l.
+func WithLogger(ctx context.Context, l zerolog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// WithLevels returns a context whose subsystems log at the given levels.
+func WithLevels(ctx context.Context, levels Levels) context.Context {
+	return context.WithValue(ctx, levelsKey{}, levels)
+}
+
+// For returns the logger of a subsystem: the context logger, or the global
+// zerolog logger when the context has none, tagged with the subsystem name
+// and at the subsystem level.
+func For(ctx context.Context, subsystem string) zerolog.Logger {
+	l, ok := ctx.Value(loggerKey{}).(zerolog.Logger)
+	if !ok {
+		l = log.Logger
+	}
+
+	l = l.With().Str(SubsystemField, subsystem).Logger()
+
+	if levels, ok := ctx.Value(levelsKey{}).(Levels); ok {
+		if level, ok := levels[subsystem]; ok {
+			l = l.Level(level)
+		}
+	}
+
+	return l
+}
+
+// ParseLevels parses comma separated subsystem levels, such as
+// "render=debug,assetreload=warn".
+func ParseLevels(s string) (Levels, error) {
+	levels := Levels{}
+	if s == "" {
+		return levels, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("invalid subsystem level '%s', expected subsystem=level", pair)
+		}
+
+		level, err := zerolog.ParseLevel(kv[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid level of subsystem '%s'", kv[0])
+		}
+
+		levels[kv[0]] = level
+	}
+
+	return levels, nil
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFor(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithLogger(context.Background(), zerolog.New(&buf))
+	ctx = WithLevels(ctx, Levels{Render: zerolog.WarnLevel})
+
+	render := For(ctx, Render)
+	render.Info().Msg("hidden")
+	assert.Empty(t, buf.String())
+
+	render.Warn().Msg("shown")
+	assert.JSONEq(t, `{"level":"warn","subsystem":"render","message":"shown"}`, buf.String())
+
+	buf.Reset()
+	action := For(ctx, Action)
+	action.Info().Msg("shown")
+	assert.JSONEq(t, `{"level":"info","subsystem":"action","message":"shown"}`, buf.String())
+}
+
+func TestForMoreVerboseSubsystem(t *testing.T) {
+	levels := Levels{Render: zerolog.DebugLevel, AssetReload: zerolog.WarnLevel}
+	assert.Equal(t, zerolog.DebugLevel, levels.Lowest(zerolog.InfoLevel))
+	assert.Equal(t, zerolog.TraceLevel, levels.Lowest(zerolog.TraceLevel))
+
+	previous := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(previous)
+	zerolog.SetGlobalLevel(levels.Lowest(zerolog.InfoLevel))
+
+	var buf bytes.Buffer
+	ctx := WithLogger(context.Background(), zerolog.New(&buf).Level(zerolog.InfoLevel))
+	ctx = WithLevels(ctx, levels)
+
+	action := For(ctx, Action)
+	action.Debug().Msg("hidden")
+	assert.Empty(t, buf.String())
+
+	render := For(ctx, Render)
+	render.Debug().Msg("shown")
+	assert.JSONEq(t, `{"level":"debug","subsystem":"render","message":"shown"}`, buf.String())
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("render=debug,assetreload=warn")
+	require.NoError(t, err)
+	assert.Equal(t, Levels{Render: zerolog.DebugLevel, AssetReload: zerolog.WarnLevel}, levels)
+
+	levels, err = ParseLevels("")
+	require.NoError(t, err)
+	assert.Empty(t, levels)
+
+	_, err = ParseLevels("render")
+	assert.Error(t, err)
+
+	_, err = ParseLevels("render=loud")
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+// Package mapground builds the mesh of the ground of a map from its GND
+// file: the tops of its surfaces and the walls between them, in world
+// space, merged in batches by texture as the models are (see mapmodel).
+// The ground is cut in chunks of ChunkSize by ChunkSize surfaces, the
+// objects of the scene, culled as the models.
+//
+// The light maps and the colors of the tiles are not applied.
+package mapground
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+	"github.com/project-midgard/midgarts/internal/mapmodel"
+)
+
+// ChunkSize is the number of surfaces along a side of a chunk.
+const ChunkSize = 8
+
+// Build returns the ground of f, a chunk per object of the scene, without
+// model. The ground is placed around center, the world position of the
+// center of the map at the altitude 0 (see system.Ground), a world unit
+// per cell. The textures of the batches are in mapmodel.TextureDir.
+func Build(f *gnd.GroundFile, center mgl32.Vec3) *mapmodel.Scene {
+	s := &mapmodel.Scene{}
+	if len(f.Surfaces) == 0 {
+		return s
+	}
+
+	b := builder{f: f, center: center, scene: s, batches: map[string]*mapmodel.Batch{}}
+	for cy := 0; cy < int(f.Height); cy += ChunkSize {
+		for cx := 0; cx < int(f.Width); cx += ChunkSize {
+			b.chunk(cx, cy)
+		}
+	}
+
+	sort.Slice(s.Batches, func(i, j int) bool { return s.Batches[i].Texture < s.Batches[j].Texture })
+
+	return s
+}
+
+type builder struct {
+	f       *gnd.GroundFile
+	center  mgl32.Vec3
+	scene   *mapmodel.Scene
+	batches map[string]*mapmodel.Batch
+
+	object   int
+	min, max mgl32.Vec3
+	points   []mgl32.Vec3
+}
+
+// chunk adds the faces of the surfaces of the chunk from (cx, cy), as an
+// object when it has any.
+func (b *builder) chunk(cx, cy int) {
+	b.object = len(b.scene.Objects)
+	b.min = mgl32.Vec3{float32(math.Inf(1)), float32(math.Inf(1)), float32(math.Inf(1))}
+	b.max = b.min.Mul(-1)
+	b.points = b.points[:0]
+
+	for y := cy; y < cy+ChunkSize && y < int(b.f.Height); y++ {
+		for x := cx; x < cx+ChunkSize && x < int(b.f.Width); x++ {
+			s := b.f.SurfaceAt(x, y)
+			corners := b.corners(x, y, s)
+			b.face(s.TileUp, corners)
+
+			// The north wall, down to the south edge of the surface north
+			// of it, and the east one, down to the west edge of the one
+			// east of it.
+			if north := b.f.SurfaceAt(x, y+1); north != nil {
+				next := b.corners(x, y+1, north)
+				b.face(s.TileFront, [4]mgl32.Vec3{corners[2], corners[3], next[0], next[1]})
+			}
+			if east := b.f.SurfaceAt(x+1, y); east != nil {
+				next := b.corners(x+1, y, east)
+				b.face(s.TileRight, [4]mgl32.Vec3{corners[1], corners[3], next[0], next[2]})
+			}
+		}
+	}
+
+	if len(b.points) == 0 {
+		return
+	}
+	b.scene.Objects = append(b.scene.Objects, mapmodel.Object{Bounds: mapmodel.BoundingSphere(b.min, b.max, b.points)})
+}
+
+// corners returns the world positions of the corners of the surface s at
+// (x, y), in the order of its heights.
+func (b *builder) corners(x, y int, s *gnd.Surface) [4]mgl32.Vec3 {
+	width, height := float32(b.f.Width*gnd.CellsPerSurface), float32(b.f.Height*gnd.CellsPerSurface)
+
+	var corners [4]mgl32.Vec3
+	for i := range corners {
+		cellX := float32((x + i%2) * gnd.CellsPerSurface)
+		cellY := float32((y + i/2) * gnd.CellsPerSurface)
+		corners[i] = mgl32.Vec3{
+			b.center.X() + width/2 - cellX,
+			cellY + b.center.Y() - height/2,
+			b.center.Z() + s.Heights[i]/gat.UnitsPerCell,
+		}
+	}
+
+	return corners
+}
+
+// face adds the quad of the tile of index tile, its corners in the order
+// of the texture coordinates of the tiles, unless it is gnd.NoTile.
+func (b *builder) face(tile int32, corners [4]mgl32.Vec3) {
+	if tile < 0 || int(tile) >= len(b.f.Tiles) {
+		return
+	}
+	t := b.f.Tiles[tile]
+	if t.Texture < 0 || t.Texture >= len(b.f.Textures) {
+		return
+	}
+
+	texture := strings.ToLower(b.f.Textures[t.Texture])
+	batch, ok := b.batches[texture]
+	if !ok {
+		batch = &mapmodel.Batch{Texture: texture}
+		b.batches[texture] = batch
+		b.scene.Batches = append(b.scene.Batches, batch)
+	}
+	if len(batch.Ranges) == 0 || batch.Ranges[len(batch.Ranges)-1].Object != b.object {
+		batch.Ranges = append(batch.Ranges, mapmodel.Range{Object: b.object, First: len(batch.Vertices)})
+	}
+
+	for _, i := range [6]int{0, 1, 2, 2, 1, 3} {
+		p := corners[i]
+		batch.Vertices = append(batch.Vertices, mapmodel.Vertex{Position: p, UV: mgl32.Vec2{t.U[i], t.V[i]}})
+		batch.Ranges[len(batch.Ranges)-1].Count++
+
+		b.points = append(b.points, p)
+		for a := 0; a < 3; a++ {
+			b.min[a] = float32(math.Min(float64(b.min[a]), float64(p[a])))
+			b.max[a] = float32(math.Max(float64(b.max[a]), float64(p[a])))
+		}
+	}
+}
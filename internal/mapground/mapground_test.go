@@ -0,0 +1,96 @@
+package mapground
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+	"github.com/project-midgard/midgarts/internal/mapmodel"
+)
+
+// newGround returns a flat ground of width by height surfaces, their tops
+// of the tile 0.
+func newGround(width, height int) *gnd.GroundFile {
+	f := &gnd.GroundFile{
+		Width:    uint32(width),
+		Height:   uint32(height),
+		Textures: []string{`Grass.BMP`, `wall.bmp`},
+		Tiles: []gnd.Tile{
+			{U: [4]float32{0, 1, 0, 1}, V: [4]float32{0, 0, 1, 1}, Texture: 0},
+			{U: [4]float32{0, 1, 0, 1}, V: [4]float32{0, 0, 1, 1}, Texture: 1},
+		},
+	}
+	for i := 0; i < width*height; i++ {
+		f.Surfaces = append(f.Surfaces, gnd.Surface{TileUp: 0, TileFront: gnd.NoTile, TileRight: gnd.NoTile})
+	}
+
+	return f
+}
+
+func positions(b *mapmodel.Batch) []mgl32.Vec3 {
+	var p []mgl32.Vec3
+	for _, v := range b.Vertices {
+		p = append(p, v.Position)
+	}
+	return p
+}
+
+func TestBuild(t *testing.T) {
+	f := newGround(2, 1)
+	// A step down to the east, walled.
+	f.Surfaces[0].TileRight = 1
+	f.Surfaces[1].Heights = [4]float32{10, 10, 10, 10}
+
+	s := Build(f, mgl32.Vec3{0, 0, 1})
+	require.Len(t, s.Objects, 1)
+	require.Len(t, s.Batches, 2)
+
+	grass, wall := s.Batches[0], s.Batches[1]
+	assert.Equal(t, "grass.bmp", grass.Texture)
+	assert.Equal(t, []mapmodel.Range{{Object: 0, First: 0, Count: 12}}, grass.Ranges)
+	// The map is 4 by 2 cells, the east along -X.
+	assert.Equal(t, []mgl32.Vec3{
+		{2, -1, 1}, {0, -1, 1}, {2, 1, 1},
+		{2, 1, 1}, {0, -1, 1}, {0, 1, 1},
+	}, positions(grass)[:6])
+	assert.Equal(t, mgl32.Vec3{-2, 1, 3}, grass.Vertices[11].Position)
+	assert.Equal(t, mgl32.Vec2{1, 1}, grass.Vertices[5].UV)
+
+	assert.Equal(t, "wall.bmp", wall.Texture)
+	assert.Equal(t, []mgl32.Vec3{
+		{0, -1, 1}, {0, 1, 1}, {0, -1, 3},
+		{0, -1, 3}, {0, 1, 1}, {0, 1, 3},
+	}, positions(wall))
+
+	bounds := s.Objects[0].Bounds
+	for _, b := range s.Batches {
+		for _, v := range b.Vertices {
+			assert.LessOrEqual(t, v.Position.Sub(bounds.Center).Len(), bounds.Radius+1e-4)
+		}
+	}
+}
+
+func TestBuildChunks(t *testing.T) {
+	f := newGround(ChunkSize+1, 2)
+	// Not drawn.
+	f.Surfaces[ChunkSize].TileUp = gnd.NoTile
+	f.Surfaces[2*ChunkSize+1].TileUp = 7
+
+	s := Build(f, mgl32.Vec3{})
+	require.Len(t, s.Objects, 1)
+	require.Len(t, s.Batches, 1)
+	assert.Len(t, s.Batches[0].Vertices, 6*(2*ChunkSize))
+
+	f.Surfaces[ChunkSize].TileUp = 0
+	s = Build(f, mgl32.Vec3{})
+	require.Len(t, s.Objects, 2)
+	assert.Equal(t, []mapmodel.Range{
+		{Object: 0, First: 0, Count: 6 * 2 * ChunkSize},
+		{Object: 1, First: 6 * 2 * ChunkSize, Count: 6},
+	}, s.Batches[0].Ranges)
+
+	assert.Empty(t, Build(&gnd.GroundFile{Width: 2, Height: 2}, mgl32.Vec3{}).Objects)
+}
@@ -0,0 +1,60 @@
+package mapmodel
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/rsm"
+)
+
+// Animation is an object of a scene whose model is animated (see
+// rsm.ModelFile.Animated): the vertices of its triangles in the batches
+// are posed again at every frame played.
+type Animation struct {
+	Object int
+	File   *rsm.ModelFile
+
+	// toWorld places the model, centered at its first frame, in the world.
+	toWorld  mgl32.Mat4
+	vertices []animatedVertex
+}
+
+// animatedVertex is a vertex of a batch, the vertex of index vertex of the
+// node of index node of the model.
+type animatedVertex struct {
+	batch  *Batch
+	index  int
+	node   int
+	vertex uint16
+}
+
+// Animate poses the animated objects of s at elapsed milliseconds, their
+// animations looping, and returns the batches whose vertices moved. The
+// bounding spheres of the objects are the ones of their first frame.
+func (s *Scene) Animate(elapsed float32, changed []*Batch) []*Batch {
+	seen := make(map[*Batch]bool, len(changed))
+	for _, b := range changed {
+		seen[b] = true
+	}
+
+	for _, a := range s.Animations {
+		frame := float32(math.Mod(float64(elapsed), float64(a.File.AnimLength)))
+		matrices := poseMatrices(a.File, frame)
+		for node, m := range matrices {
+			matrices[node] = a.toWorld.Mul4(m)
+		}
+
+		for _, v := range a.vertices {
+			p := mgl32.TransformCoordinate(a.File.Nodes[v.node].Vertices[v.vertex], matrices[v.node])
+			v.batch.Vertices[v.index].Position = p
+
+			if !seen[v.batch] {
+				seen[v.batch] = true
+				changed = append(changed, v.batch)
+			}
+		}
+	}
+
+	return changed
+}
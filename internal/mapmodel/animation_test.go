@@ -0,0 +1,46 @@
+package mapmodel
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/rsm"
+)
+
+func TestAnimate(t *testing.T) {
+	windmill := newModel("wall.bmp")
+	windmill.AnimLength = 1000
+	windmill.Nodes[0].RotationKeyframes = []rsm.RotationKeyframe{
+		{Frame: 0, Rotation: mgl32.QuatIdent()},
+		{Frame: 500, Rotation: mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 1, 0})},
+		{Frame: 1000, Rotation: mgl32.QuatIdent()},
+	}
+	files := map[string]*rsm.ModelFile{"house.rsm": windmill, "tree.rsm": newModel("wall.bmp")}
+	world := newWorld(map[string][][2]float32{"house.rsm": {{10, 10}}, "tree.rsm": {{20, 20}}})
+
+	s := Build(world, files, mgl32.Vec3{32, 32, 0})
+	require.Len(t, s.Animations, 1)
+	assert.Equal(t, 0, s.Animations[0].Object)
+
+	b := s.Batches[0]
+	first := append([]Vertex(nil), b.Vertices...)
+
+	changed := s.Animate(250, nil)
+	assert.Equal(t, []*Batch{b}, changed)
+	// Turned around the vertical.
+	v := b.Vertices
+	assert.NotEqual(t, first[1].Position, v[1].Position)
+	assert.InDelta(t, first[1].Position.Sub(first[0].Position).Len(), v[1].Position.Sub(v[0].Position).Len(), 1e-4)
+	assert.InDelta(t, first[2].Position.Z(), v[2].Position.Z(), 1e-4)
+	// Not the tree.
+	assert.Equal(t, first[3:], v[3:])
+
+	// The animation loops.
+	s.Animate(2000, changed[:0])
+	for i := range first {
+		assert.InDeltaSlice(t, first[i].Position[:], v[i].Position[:], 1e-4)
+	}
+}
@@ -0,0 +1,80 @@
+package mapmodel
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Frustum is the volume a camera sees, as 6 planes whose normals point
+// inwards: the left, right, bottom, top, near and far ones.
+type Frustum [6]mgl32.Vec4
+
+// NewFrustum returns the frustum of the product of the projection and
+// view matrices of a camera.
+func NewFrustum(viewProjection mgl32.Mat4) Frustum {
+	row := func(i int) mgl32.Vec4 { return viewProjection.Row(i) }
+
+	f := Frustum{
+		row(3).Add(row(0)),
+		row(3).Sub(row(0)),
+		row(3).Add(row(1)),
+		row(3).Sub(row(1)),
+		row(3).Add(row(2)),
+		row(3).Sub(row(2)),
+	}
+	for i, p := range f {
+		if l := p.Vec3().Len(); l > 0 {
+			f[i] = p.Mul(1 / l)
+		}
+	}
+
+	return f
+}
+
+// Sees tells whether any of s is in the frustum.
+func (f Frustum) Sees(s Sphere) bool {
+	for _, p := range f {
+		if p.Vec3().Dot(s.Center)+p.W() < -s.Radius {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Cull sets visible, grown to the objects of s, to whether each object is
+// seen in f, returning it and the number of objects seen.
+func (s *Scene) Cull(f Frustum, visible []bool) ([]bool, int) {
+	if cap(visible) < len(s.Objects) {
+		visible = make([]bool, len(s.Objects))
+	}
+	visible = visible[:len(s.Objects)]
+
+	var seen int
+	for i, o := range s.Objects {
+		visible[i] = f.Sees(o.Bounds)
+		if visible[i] {
+			seen++
+		}
+	}
+
+	return visible, seen
+}
+
+// Draws appends to draws the ranges of the visible objects of b, the
+// ranges following each other merged, so each range is a draw call.
+func (b *Batch) Draws(visible []bool, draws []Range) []Range {
+	start := len(draws)
+	for _, r := range b.Ranges {
+		if !visible[r.Object] {
+			continue
+		}
+
+		if n := len(draws); n > start && draws[n-1].First+draws[n-1].Count == r.First {
+			draws[n-1].Count += r.Count
+			continue
+		}
+		draws = append(draws, r)
+	}
+
+	return draws
+}
@@ -0,0 +1,84 @@
+package mapmodel
+
+import (
+	"context"
+	"strings"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/bmp"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/logging"
+)
+
+// TextureDir is the folder of the textures of the models.
+const TextureDir = "data/texture/"
+
+// EntryLoader reads the files of the archives, as grf.File does.
+type EntryLoader interface {
+	GetEntryContext(ctx context.Context, name string) (*grf.Entry, error)
+}
+
+// EntriesReader reads files of the archives in parallel, as grf.MultiFile
+// does: the entries that cannot be read are nil, the error then a
+// grf.EntriesError.
+type EntriesReader interface {
+	ReadEntriesContext(ctx context.Context, names []string, workers int) ([]*grf.Entry, error)
+}
+
+// LoadTextures returns the images of the textures of the batches of s, by
+// name. The textures that cannot be loaded are logged to the logger of
+// ctx and left out, their batches not drawn.
+func LoadTextures(ctx context.Context, reader EntriesReader, s *Scene) map[string]*graphic.UniqueRGBA {
+	log := logging.For(ctx, logging.MapModel)
+
+	var names []string
+	for _, b := range s.Batches {
+		names = append(names, b.Texture)
+	}
+
+	images := map[string]*graphic.UniqueRGBA{}
+	for name, e := range readEntries(ctx, reader, TextureDir, names, "texture") {
+		img, err := bmp.Load(e.Data)
+		if err != nil {
+			log.Warn().Err(err).Str("texture", name).Msg("could not decode model texture")
+			continue
+		}
+		images[name] = img
+	}
+
+	return images
+}
+
+// readEntries reads the files of names, under dir, by name. The files that
+// cannot be read are logged as files of kind, and left out.
+func readEntries(ctx context.Context, reader EntriesReader, dir string, names []string, kind string) map[string]*grf.Entry {
+	log := logging.For(ctx, logging.MapModel)
+
+	var paths []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			paths = append(paths, dir+name)
+		}
+	}
+
+	entries, err := reader.ReadEntriesContext(ctx, paths, 0)
+	failed, ok := err.(grf.EntriesError)
+	if err != nil && !ok {
+		log.Warn().Err(err).Int("count", len(paths)).Msgf("could not read the %ss", kind)
+		return nil
+	}
+
+	read := map[string]*grf.Entry{}
+	for i, e := range entries {
+		name := strings.TrimPrefix(paths[i], dir)
+		if e == nil {
+			log.Warn().Err(failed[paths[i]]).Str(kind, name).Msgf("could not read %s, leaving it out", kind)
+			continue
+		}
+		read[name] = e
+	}
+
+	return read
+}
@@ -0,0 +1,233 @@
+// Package mapmodel merges the models placed on a map into static batches
+// at load: the triangles of every model, in world space, grouped by
+// texture, so a town of hundreds of props is drawn in a few draw calls.
+// The triangles of a model are contiguous in each batch, the models close
+// to each other next to each other, and each model has a bounding sphere:
+// the models out of the view are culled (see Frustum), the ranges of the
+// models drawn that follow each other being drawn at once.
+//
+// The models are posed at their first frame, the animated ones posed
+// again by Scene.Animate.
+package mapmodel
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsm"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+	"github.com/project-midgard/midgarts/internal/mapstream"
+)
+
+// Vertex is a vertex of a batch, in world space.
+type Vertex struct {
+	Position mgl32.Vec3
+	UV       mgl32.Vec2
+}
+
+// Sphere is a bounding sphere, in world space.
+type Sphere struct {
+	Center mgl32.Vec3
+	Radius float32
+}
+
+// Object is a model placed on the map, or a chunk of the ground without
+// model (see mapground).
+type Object struct {
+	Model  rsw.Model
+	Bounds Sphere
+}
+
+// Range is the vertices of the object of index Object in a batch, from
+// First, or of the objects following it when merged (see Batch.Draws).
+type Range struct {
+	Object       int
+	First, Count int
+}
+
+// Batch is the triangles of the objects using a texture.
+type Batch struct {
+	// Texture is the name of the texture file, in TextureDir.
+	Texture  string
+	Vertices []Vertex
+	// Ranges are the vertices of each object, in the order of the
+	// objects.
+	Ranges []Range
+}
+
+// Scene is the models of a map, merged.
+type Scene struct {
+	Objects []Object
+	Batches []*Batch
+	// Animations are the objects whose model is animated.
+	Animations []*Animation
+}
+
+// Build merges the models of world, whose files are in files by name, in
+// a scene. The models are placed around center, the world position of the
+// center of the map at the altitude 0 (see system.Ground), a world unit
+// per cell. The models whose file is missing are left out.
+func Build(world *rsw.ResourceWorldFile, files map[string]*rsm.ModelFile, center mgl32.Vec3) *Scene {
+	models := make([]rsw.Model, 0, len(world.Models))
+	for _, m := range world.Models {
+		if _, ok := files[m.FileName]; ok {
+			models = append(models, m)
+		}
+	}
+
+	// Row by row of regions, so the objects next to each other on the
+	// screen are next to each other in the batches.
+	region := func(m rsw.Model) (int, int, float32) {
+		const size = mapstream.RegionSize * gat.UnitsPerCell
+		return int(math.Floor(float64(m.Position.Z() / size))), int(math.Floor(float64(m.Position.X() / size))), m.Position.X()
+	}
+	sort.SliceStable(models, func(i, j int) bool {
+		yi, xi, pi := region(models[i])
+		yj, xj, pj := region(models[j])
+		if yi != yj {
+			return yi < yj
+		}
+		if xi != xj {
+			return xi < xj
+		}
+		return pi < pj
+	})
+
+	s := &Scene{Objects: make([]Object, len(models))}
+	batches := map[string]*Batch{}
+	for i, m := range models {
+		f := files[m.FileName]
+		toWorld := instanceMatrix(m, center)
+		matrices, centering := nodeMatrices(f)
+
+		var animation *Animation
+		if f.Animated() {
+			animation = &Animation{Object: i, File: f, toWorld: toWorld.Mul4(centering)}
+			s.Animations = append(s.Animations, animation)
+		}
+
+		min := mgl32.Vec3{float32(math.Inf(1)), float32(math.Inf(1)), float32(math.Inf(1))}
+		max := min.Mul(-1)
+		var points []mgl32.Vec3
+
+		// In the order of the nodes, for the batches to be the same at every
+		// load.
+		for node := range f.Nodes {
+			n := &f.Nodes[node]
+			matrix := toWorld.Mul4(matrices[node])
+
+			for _, face := range n.Faces {
+				texture, ok := faceTexture(f, n, face)
+				if !ok || !validFace(n, face) {
+					continue
+				}
+
+				b, ok := batches[texture]
+				if !ok {
+					b = &Batch{Texture: texture}
+					batches[texture] = b
+					s.Batches = append(s.Batches, b)
+				}
+				if len(b.Ranges) == 0 || b.Ranges[len(b.Ranges)-1].Object != i {
+					b.Ranges = append(b.Ranges, Range{Object: i, First: len(b.Vertices)})
+				}
+
+				for k := 0; k < 3; k++ {
+					if animation != nil {
+						animation.vertices = append(animation.vertices, animatedVertex{
+							batch:  b,
+							index:  len(b.Vertices),
+							node:   node,
+							vertex: face.Vertices[k],
+						})
+					}
+
+					p := mgl32.TransformCoordinate(n.Vertices[face.Vertices[k]], matrix)
+					b.Vertices = append(b.Vertices, Vertex{Position: p, UV: n.TexCoords[face.TexCoords[k]]})
+					b.Ranges[len(b.Ranges)-1].Count++
+
+					points = append(points, p)
+					for a := 0; a < 3; a++ {
+						min[a] = float32(math.Min(float64(min[a]), float64(p[a])))
+						max[a] = float32(math.Max(float64(max[a]), float64(p[a])))
+					}
+				}
+			}
+		}
+
+		s.Objects[i] = Object{Model: m, Bounds: BoundingSphere(min, max, points)}
+	}
+
+	sort.Slice(s.Batches, func(i, j int) bool { return s.Batches[i].Texture < s.Batches[j].Texture })
+
+	return s
+}
+
+// BoundingSphere returns the sphere around points, centered on their box
+// from min to max, the zero sphere without point.
+func BoundingSphere(min, max mgl32.Vec3, points []mgl32.Vec3) Sphere {
+	if len(points) == 0 {
+		return Sphere{}
+	}
+
+	center := min.Add(max).Mul(0.5)
+	var radius float32
+	for _, p := range points {
+		if d := p.Sub(center).Len(); d > radius {
+			radius = d
+		}
+	}
+
+	return Sphere{Center: center, Radius: radius}
+}
+
+// faceTexture returns the name of the texture of face, a face of n.
+func faceTexture(f *rsm.ModelFile, n *rsm.Node, face rsm.Face) (string, bool) {
+	if int(face.Texture) >= len(n.Textures) {
+		return "", false
+	}
+	index := n.Textures[face.Texture]
+	if index < 0 || int(index) >= len(f.Textures) {
+		return "", false
+	}
+
+	return strings.ToLower(f.Textures[index]), true
+}
+
+// validFace tells whether the indices of face are within the vertices and
+// the texture coordinates of n.
+func validFace(n *rsm.Node, face rsm.Face) bool {
+	for k := 0; k < 3; k++ {
+		if int(face.Vertices[k]) >= len(n.Vertices) || int(face.TexCoords[k]) >= len(n.TexCoords) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// instanceMatrix returns the matrix placing the model m of a map in the
+// world, around center. The maps are in the units of the files, centered
+// on the map, the altitudes growing down and the east along +X; the world
+// has a unit per cell, the east along -X and the north along +Y.
+func instanceMatrix(m rsw.Model, center mgl32.Vec3) mgl32.Mat4 {
+	const unit = 1.0 / gat.UnitsPerCell
+	toWorld := mgl32.Mat4{
+		-unit, 0, 0, 0,
+		0, 0, unit, 0,
+		0, unit, 0, 0,
+		center.X(), center.Y(), center.Z(), 1,
+	}
+
+	rotation := m.Rotation
+	return toWorld.
+		Mul4(mgl32.Translate3D(m.Position.X(), m.Position.Y(), m.Position.Z())).
+		Mul4(mgl32.HomogRotate3DZ(mgl32.DegToRad(rotation.Z()))).
+		Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(rotation.X()))).
+		Mul4(mgl32.HomogRotate3DY(mgl32.DegToRad(rotation.Y()))).
+		Mul4(mgl32.Scale3D(m.Scale.X(), m.Scale.Y(), m.Scale.Z()))
+}
@@ -0,0 +1,197 @@
+package mapmodel
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsm"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+)
+
+// newModel returns a model of a single node: a triangle of each texture,
+// 10 units wide and high.
+func newModel(textures ...string) *rsm.ModelFile {
+	n := rsm.Node{
+		Name:         "main",
+		OffsetMatrix: mgl32.Ident3(),
+		Scale:        mgl32.Vec3{1, 1, 1},
+		Vertices:     []mgl32.Vec3{{0, 0, 0}, {10, 0, 0}, {0, -10, 0}},
+		TexCoords:    []mgl32.Vec2{{0, 0}, {1, 0}, {0, 1}},
+	}
+	for i := range textures {
+		n.Textures = append(n.Textures, int32(i))
+		n.Faces = append(n.Faces, rsm.Face{Vertices: [3]uint16{0, 1, 2}, TexCoords: [3]uint16{0, 1, 2}, Texture: uint16(i)})
+	}
+	// Out of the textures of the node.
+	n.Faces = append(n.Faces, rsm.Face{Texture: uint16(len(textures))})
+
+	return &rsm.ModelFile{Textures: textures, MainNode: "main", Nodes: []rsm.Node{n}}
+}
+
+// newWorld returns a world with models at the given cells of a map of 64
+// by 64 cells, the models of each file next to each other in the file.
+func newWorld(cells map[string][][2]float32) *rsw.ResourceWorldFile {
+	world := &rsw.ResourceWorldFile{}
+	for _, name := range []string{"house.rsm", "tree.rsm", "missing.rsm"} {
+		for _, c := range cells[name] {
+			world.Models = append(world.Models, rsw.Model{
+				FileName: name,
+				Position: mgl32.Vec3{(c[0] - 32) * 5, 0, (c[1] - 32) * 5},
+				Scale:    mgl32.Vec3{1, 1, 1},
+			})
+		}
+	}
+
+	return world
+}
+
+func TestBuild(t *testing.T) {
+	files := map[string]*rsm.ModelFile{
+		"house.rsm": newModel("Wall.bmp", "roof.bmp"),
+		"tree.rsm":  newModel("wall.bmp"),
+	}
+	world := newWorld(map[string][][2]float32{
+		"house.rsm":   {{2, 40}, {2, 2}},
+		"tree.rsm":    {{4, 2}},
+		"missing.rsm": {{3, 3}},
+	})
+
+	s := Build(world, files, mgl32.Vec3{32, 32, 0})
+
+	// By region: the models of the first row first.
+	require.Len(t, s.Objects, 3)
+	assert.Equal(t, "house.rsm", s.Objects[0].Model.FileName)
+	assert.Equal(t, "tree.rsm", s.Objects[1].Model.FileName)
+	assert.Equal(t, "house.rsm", s.Objects[2].Model.FileName)
+
+	// The texture names are not case sensitive.
+	require.Len(t, s.Batches, 2)
+	roof, wall := s.Batches[0], s.Batches[1]
+	assert.Equal(t, "roof.bmp", roof.Texture)
+	assert.Equal(t, "wall.bmp", wall.Texture)
+	assert.Equal(t, []Range{{0, 0, 3}, {1, 3, 3}, {2, 6, 3}}, wall.Ranges)
+	assert.Equal(t, []Range{{0, 0, 3}, {2, 3, 3}}, roof.Ranges)
+
+	// Centered on the model, its bottom on the ground, 2 cells wide and
+	// high. The cell (2, 2) is at (62, 2), the east along -X and the top
+	// towards the camera.
+	v := wall.Vertices[:3]
+	assert.InDeltaSlice(t, []float32{62 + 1, 2, 0}, v[0].Position[:], 1e-4)
+	assert.InDeltaSlice(t, []float32{62 - 1, 2, 0}, v[1].Position[:], 1e-4)
+	assert.InDeltaSlice(t, []float32{62 + 1, 2, -2}, v[2].Position[:], 1e-4)
+	assert.Equal(t, mgl32.Vec2{1, 0}, v[1].UV)
+
+	b := s.Objects[0].Bounds
+	assert.InDeltaSlice(t, []float32{62, 2, -1}, b.Center[:], 1e-4)
+	assert.InDelta(t, 1.4142, b.Radius, 1e-3)
+}
+
+func TestBuildRotation(t *testing.T) {
+	files := map[string]*rsm.ModelFile{"tree.rsm": newModel("wall.bmp")}
+	world := newWorld(map[string][][2]float32{"tree.rsm": {{10, 10}}})
+	world.Models[0].Rotation = mgl32.Vec3{0, 90, 0}
+	world.Models[0].Scale = mgl32.Vec3{2, 2, 2}
+
+	s := Build(world, files, mgl32.Vec3{32, 32, 0})
+
+	// Turned around the vertical, twice as large.
+	v := s.Batches[0].Vertices
+	assert.InDeltaSlice(t, []float32{54, 10 + 2, 0}, v[0].Position[:], 1e-4)
+	assert.InDeltaSlice(t, []float32{54, 10 - 2, 0}, v[1].Position[:], 1e-4)
+	assert.InDelta(t, -4, v[2].Position.Z(), 1e-4)
+}
+
+func TestBuildNodes(t *testing.T) {
+	f := newModel("wall.bmp")
+	child := f.Nodes[0]
+	child.Name, child.Parent = "child", "main"
+	child.Position = mgl32.Vec3{0, -10, 0}
+	f.Nodes = append(f.Nodes, child)
+
+	s := Build(newWorld(map[string][][2]float32{"tree.rsm": {{10, 10}}}), map[string]*rsm.ModelFile{"tree.rsm": f}, mgl32.Vec3{32, 32, 0})
+
+	// The child stands on its parent.
+	v := s.Batches[0].Vertices
+	require.Len(t, v, 6)
+	assert.InDelta(t, 0, v[0].Position.Z(), 1e-4)
+	assert.InDelta(t, -2, v[3].Position.Z(), 1e-4)
+	assert.InDelta(t, -4, v[5].Position.Z(), 1e-4)
+}
+
+func TestCull(t *testing.T) {
+	s := &Scene{
+		Objects: []Object{
+			{Bounds: Sphere{Center: mgl32.Vec3{0, 0, -10}, Radius: 1}},
+			{Bounds: Sphere{Center: mgl32.Vec3{0, 0, 10}, Radius: 1}},
+			// Behind, reaching in.
+			{Bounds: Sphere{Center: mgl32.Vec3{0, 0, 0}, Radius: 2}},
+			{Bounds: Sphere{Center: mgl32.Vec3{50, 0, -10}, Radius: 1}},
+		},
+		Batches: []*Batch{{Ranges: []Range{{0, 0, 3}, {1, 3, 6}, {2, 9, 3}, {3, 12, 3}}}},
+	}
+
+	f := NewFrustum(mgl32.Perspective(mgl32.DegToRad(90), 1, 1, 100))
+	visible, seen := s.Cull(f, nil)
+	assert.Equal(t, []bool{true, false, true, false}, visible)
+	assert.Equal(t, 2, seen)
+
+	assert.Equal(t, []Range{{0, 0, 3}, {2, 9, 3}}, s.Batches[0].Draws(visible, nil))
+
+	// The ranges following each other are merged.
+	assert.Equal(t, []Range{{0, 0, 15}}, s.Batches[0].Draws([]bool{true, true, true, true}, nil))
+}
+
+// fakeLoader serves the entries of files.
+type fakeLoader map[string][]byte
+
+func (l fakeLoader) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	data, ok := l[name]
+	if !ok {
+		return nil, errors.New("entry not found")
+	}
+
+	return &grf.Entry{Data: data}, nil
+}
+
+func (l fakeLoader) ReadEntriesContext(ctx context.Context, names []string, workers int) ([]*grf.Entry, error) {
+	entries := make([]*grf.Entry, len(names))
+	failed := grf.EntriesError{}
+	for i, name := range names {
+		var err error
+		if entries[i], err = l.GetEntryContext(ctx, name); err != nil {
+			failed[name] = err
+		}
+	}
+	if len(failed) != 0 {
+		return entries, failed
+	}
+
+	return entries, nil
+}
+
+func TestLoad(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{R: 255, B: 255, A: 255})
+	img.Set(1, 0, color.RGBA{R: 10, G: 20, B: 30, A: 255})
+	var texture bytes.Buffer
+	require.NoError(t, bmp.Encode(&texture, img))
+
+	loader := fakeLoader{
+		TextureDir + "wall.bmp": texture.Bytes(),
+		TextureDir + "roof.bmp": []byte("BM"),
+	}
+	images := LoadTextures(context.Background(), loader, &Scene{Batches: []*Batch{{Texture: "wall.bmp"}, {Texture: "roof.bmp"}, {Texture: "missing.bmp"}}})
+	require.Len(t, images, 1)
+	// The magenta is transparent.
+	assert.Equal(t, []uint8{0, 0, 0, 0, 10, 20, 30, 255}, images["wall.bmp"].Pix)
+}
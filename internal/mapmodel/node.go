@@ -0,0 +1,111 @@
+package mapmodel
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/rsm"
+)
+
+// nodeMatrices returns the matrices placing the vertices of the nodes of
+// f in the model, by node index, at the first frame, and the matrix
+// centering the model: it is centered on its origin horizontally, its
+// bottom at the origin, as the maps expect.
+func nodeMatrices(f *rsm.ModelFile) (map[int]mgl32.Mat4, mgl32.Mat4) {
+	matrices := poseMatrices(f, 0)
+	centering := centeringMatrix(f, matrices)
+	for i, m := range matrices {
+		matrices[i] = centering.Mul4(m)
+	}
+
+	return matrices, centering
+}
+
+// poseMatrices returns the matrices placing the vertices of the nodes of
+// f in the model at the frame, in milliseconds, by node index, before the
+// model is centered. A node is placed in its parent by its position,
+// rotation and scale, its vertices in the node by its offset. The nodes
+// whose parent is missing are placed as roots.
+func poseMatrices(f *rsm.ModelFile, frame float32) map[int]mgl32.Mat4 {
+	byName := make(map[string]int, len(f.Nodes))
+	children := make(map[string]int, len(f.Nodes))
+	for i, n := range f.Nodes {
+		byName[n.Name] = i
+		if n.Parent != "" && n.Parent != n.Name {
+			children[n.Parent]++
+		}
+	}
+
+	placements := make(map[int]mgl32.Mat4, len(f.Nodes))
+	var place func(i int, depth int) mgl32.Mat4
+	place = func(i int, depth int) mgl32.Mat4 {
+		if m, ok := placements[i]; ok {
+			return m
+		}
+
+		n := &f.Nodes[i]
+		parent, hasParent := byName[n.Parent]
+		// Cycles are cut.
+		hasParent = hasParent && n.Parent != n.Name && depth < len(f.Nodes)
+
+		m := mgl32.Ident4()
+		if hasParent {
+			m = place(parent, depth+1).Mul4(mgl32.Translate3D(n.Position.X(), n.Position.Y(), n.Position.Z()))
+		}
+		m = m.Mul4(nodeRotation(n, frame)).Mul4(mgl32.Scale3D(n.Scale.X(), n.Scale.Y(), n.Scale.Z()))
+
+		placements[i] = m
+		return m
+	}
+
+	matrices := make(map[int]mgl32.Mat4, len(f.Nodes))
+	for i := range f.Nodes {
+		n := &f.Nodes[i]
+
+		local := n.OffsetMatrix.Mat4()
+		// A model of a single node is not offset.
+		if n.Name != f.MainNode || children[n.Name] > 0 {
+			local = mgl32.Translate3D(n.Offset.X(), n.Offset.Y(), n.Offset.Z()).Mul4(local)
+		}
+
+		matrices[i] = place(i, 0).Mul4(local)
+	}
+
+	return matrices
+}
+
+// centeringMatrix returns the matrix centering the vertices of the nodes
+// of f placed by matrices (see nodeMatrices).
+func centeringMatrix(f *rsm.ModelFile, matrices map[int]mgl32.Mat4) mgl32.Mat4 {
+	min := mgl32.Vec3{float32(math.Inf(1)), float32(math.Inf(1)), float32(math.Inf(1))}
+	max := min.Mul(-1)
+	for i, m := range matrices {
+		for _, v := range f.Nodes[i].Vertices {
+			p := mgl32.TransformCoordinate(v, m)
+			for a := 0; a < 3; a++ {
+				min[a] = float32(math.Min(float64(min[a]), float64(p[a])))
+				max[a] = float32(math.Max(float64(max[a]), float64(p[a])))
+			}
+		}
+	}
+
+	if min.X() > max.X() {
+		return mgl32.Ident4()
+	}
+
+	// The altitudes grow down: the bottom is the largest Y.
+	return mgl32.Translate3D(-(min.X()+max.X())/2, -max.Y(), -(min.Z()+max.Z())/2)
+}
+
+// nodeRotation returns the rotation of n at the frame, in milliseconds.
+func nodeRotation(n *rsm.Node, frame float32) mgl32.Mat4 {
+	if q, ok := n.RotationAt(frame); ok {
+		return q.Mat4()
+	}
+	if n.RotationAxis.Len() == 0 {
+		return mgl32.Ident4()
+	}
+
+	return mgl32.HomogRotate3D(n.RotationAngle, n.RotationAxis.Normalize())
+}
@@ -0,0 +1,367 @@
+// Package mapstream streams the models of a map by region: rather than
+// loading every model of a large map on entry, a Streamer loads the
+// regions around the camera, prefetches the ones ahead of it and releases
+// the ones left behind, so entering a map stays short and the memory
+// bounded.
+package mapstream
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsm"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+	"github.com/project-midgard/midgarts/internal/logging"
+)
+
+const (
+	// RegionSize is the side of the regions, in cells.
+	RegionSize = 32
+	// DefaultRadius is the default Streamer.Radius: the region of the
+	// camera and the 8 around it.
+	DefaultRadius = 1
+	// ModelDir is the folder of the model files.
+	ModelDir = "data/model/"
+)
+
+// Region is a square of RegionSize cells of a map, by its coordinates in
+// regions from the south-west corner of the map.
+type Region struct {
+	X, Y int
+}
+
+// distance returns the number of regions between r and o, diagonals
+// included.
+func (r Region) distance(o Region) int {
+	dx, dy := r.X-o.X, r.Y-o.Y
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+
+	return dy
+}
+
+// Model is a model of the map with its file.
+type Model struct {
+	rsw.Model
+	File *rsm.ModelFile
+}
+
+// EntryLoader reads the files of the archives, as grf.File does.
+type EntryLoader interface {
+	GetEntryContext(ctx context.Context, name string) (*grf.Entry, error)
+}
+
+// region is a requested region.
+type region struct {
+	cancel context.CancelFunc
+	// done is closed once the models are loaded, or failed to.
+	done   chan struct{}
+	models []Model
+	err    error
+}
+
+// modelFile is a model file shared by the regions placing it.
+type modelFile struct {
+	done chan struct{}
+	file *rsm.ModelFile
+	err  error
+	// refs is the number of regions placing the file.
+	refs int
+}
+
+// Streamer loads the models of the regions of a map around the camera,
+// on goroutines. Update and the accessors are called from the main
+// thread.
+type Streamer struct {
+	// Radius is the distance, in regions, from the region of the camera
+	// up to which the regions are loaded. The regions one further ahead
+	// of the camera, in the direction it moves to, are prefetched; the
+	// regions further than that are released.
+	Radius int
+	// MaxRegions bounds the regions kept, the farthest from the camera
+	// being released first, so it should hold the (2*Radius+1)² regions
+	// of the radius. 0 keeps the regions in the radius and the prefetched
+	// ones only.
+	MaxRegions int
+
+	ctx    context.Context
+	log    zerolog.Logger
+	loader EntryLoader
+
+	// width and height are the size of the map, in cells.
+	width, height int
+	// models holds the models of the map by region.
+	models map[Region][]rsw.Model
+
+	regions map[Region]*region
+	camera  mgl32.Vec2
+	moved   bool
+
+	mu    sync.Mutex
+	files map[string]*modelFile
+}
+
+// NewStreamer returns a streamer of the models of world, a map of width
+// by height cells; ctx cancels the loading and carries its logger (see
+// logging.For).
+func NewStreamer(ctx context.Context, loader EntryLoader, world *rsw.ResourceWorldFile, width, height int) *Streamer {
+	s := &Streamer{
+		Radius: DefaultRadius,
+		ctx:    ctx,
+		log:    logging.For(ctx, logging.MapStream),
+		loader: loader,
+		width:  width,
+		height: height,
+		models: map[Region][]rsw.Model{},
+
+		regions: map[Region]*region{},
+		files:   map[string]*modelFile{},
+	}
+
+	for _, m := range world.Models {
+		r := s.RegionAt(s.modelCell(m))
+		s.models[r] = append(s.models[r], m)
+	}
+
+	return s
+}
+
+// modelCell returns the cell of a model, its position being centered on
+// the map, gat.UnitsPerCell per cell.
+func (s *Streamer) modelCell(m rsw.Model) (x, y float32) {
+	return m.Position.X()/gat.UnitsPerCell + float32(s.width)/2, m.Position.Z()/gat.UnitsPerCell + float32(s.height)/2
+}
+
+// RegionAt returns the region of the cell at x, y.
+func (s *Streamer) RegionAt(x, y float32) Region {
+	return Region{int(math.Floor(float64(x / RegionSize))), int(math.Floor(float64(y / RegionSize)))}
+}
+
+// Update loads the regions around the camera at the cell x, y, and the
+// regions ahead of it, and releases the ones left behind.
+func (s *Streamer) Update(x, y float32) {
+	camera := mgl32.Vec2{x, y}
+	heading := camera.Sub(s.camera)
+	if !s.moved {
+		heading = mgl32.Vec2{}
+	}
+	s.camera, s.moved = camera, true
+
+	center := s.RegionAt(x, y)
+	for dy := -s.Radius - 1; dy <= s.Radius+1; dy++ {
+		for dx := -s.Radius - 1; dx <= s.Radius+1; dx++ {
+			r := Region{center.X + dx, center.Y + dy}
+			if r.distance(center) <= s.Radius || ahead(mgl32.Vec2{float32(dx), float32(dy)}, heading) {
+				s.request(r)
+			}
+		}
+	}
+
+	s.release(center)
+}
+
+// ahead tells whether the region at offset from the region of the camera
+// lies ahead of the camera moving along heading, within about 35° of it.
+func ahead(offset, heading mgl32.Vec2) bool {
+	return offset.Dot(heading) > 0.8*offset.Len()*heading.Len()
+}
+
+// request starts loading r, unless it is loaded, being loaded, or has
+// no model.
+func (s *Streamer) request(r Region) {
+	if _, ok := s.regions[r]; ok || len(s.models[r]) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	reg := &region{cancel: cancel, done: make(chan struct{})}
+	s.regions[r] = reg
+
+	models := s.models[r]
+	for _, name := range uniqueFileNames(models) {
+		s.acquire(name)
+	}
+
+	go func() {
+		defer close(reg.done)
+
+		reg.models = make([]Model, len(models))
+		for i, m := range models {
+			file, err := s.file(ctx, m.FileName)
+			if err != nil {
+				reg.models, reg.err = nil, errors.Wrapf(err, "could not load region %d,%d", r.X, r.Y)
+				return
+			}
+			reg.models[i] = Model{Model: m, File: file}
+		}
+	}()
+}
+
+// release releases the regions further than the radius and the
+// prefetched ones, and the farthest ones past MaxRegions.
+func (s *Streamer) release(center Region) {
+	var kept []Region
+	for r := range s.regions {
+		if r.distance(center) > s.Radius+1 {
+			s.drop(r)
+			continue
+		}
+		kept = append(kept, r)
+	}
+
+	if s.MaxRegions <= 0 || len(kept) <= s.MaxRegions {
+		return
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		di, dj := kept[i].distance(center), kept[j].distance(center)
+		if di != dj {
+			return di < dj
+		}
+		// The same regions are dropped from an update to the next.
+		return kept[i].Y < kept[j].Y || (kept[i].Y == kept[j].Y && kept[i].X < kept[j].X)
+	})
+	for _, r := range kept[s.MaxRegions:] {
+		s.drop(r)
+	}
+}
+
+// drop cancels the loading of r and releases its model files.
+func (s *Streamer) drop(r Region) {
+	reg := s.regions[r]
+	delete(s.regions, r)
+	reg.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range uniqueFileNames(s.models[r]) {
+		if f := s.files[name]; f != nil {
+			if f.refs--; f.refs == 0 {
+				delete(s.files, name)
+			}
+		}
+	}
+}
+
+// acquire adds a reference to the model file name, loading it on a
+// goroutine when it is not loaded yet. The loading goes on when the
+// region is dropped, other regions may place the model.
+func (s *Streamer) acquire(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f := s.files[name]; f != nil {
+		f.refs++
+		return
+	}
+
+	f := &modelFile{done: make(chan struct{}), refs: 1}
+	s.files[name] = f
+
+	go func() {
+		defer close(f.done)
+
+		e, err := s.loader.GetEntryContext(s.ctx, ModelDir+name)
+		if err != nil {
+			f.err = errors.Wrapf(err, "could not read model '%s'", name)
+			return
+		}
+		if f.file, err = rsm.Load(e.Data); err != nil {
+			f.err = errors.Wrapf(err, "could not load model '%s'", name)
+		}
+	}()
+}
+
+// file waits for the model file name, acquired beforehand.
+func (s *Streamer) file(ctx context.Context, name string) (*rsm.ModelFile, error) {
+	s.mu.Lock()
+	f := s.files[name]
+	s.mu.Unlock()
+
+	if f == nil {
+		return nil, errors.Errorf("model '%s' was released", name)
+	}
+
+	select {
+	case <-f.done:
+		return f.file, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Models returns the models of r once they are loaded. The models that
+// failed to load are logged, the region showing none.
+func (s *Streamer) Models(r Region) (models []Model, ok bool) {
+	reg, ok := s.regions[r]
+	if !ok {
+		return nil, false
+	}
+
+	select {
+	case <-reg.done:
+	default:
+		return nil, false
+	}
+
+	if reg.err != nil {
+		s.log.Error().Err(reg.err).Send()
+		reg.err = nil
+	}
+
+	return reg.models, true
+}
+
+// Regions returns the regions requested, loaded or not.
+func (s *Streamer) Regions() []Region {
+	regions := make([]Region, 0, len(s.regions))
+	for r := range s.regions {
+		regions = append(regions, r)
+	}
+
+	return regions
+}
+
+// Files returns the number of model files held by the regions.
+func (s *Streamer) Files() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.files)
+}
+
+// Close cancels the loading and releases the regions.
+func (s *Streamer) Close() {
+	for r := range s.regions {
+		s.drop(r)
+	}
+}
+
+func uniqueFileNames(models []rsw.Model) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, m := range models {
+		if !seen[m.FileName] {
+			seen[m.FileName] = true
+			names = append(names, m.FileName)
+		}
+	}
+
+	return names
+}
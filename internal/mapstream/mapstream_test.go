@@ -0,0 +1,185 @@
+package mapstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+)
+
+// newModelData returns an RSM file without texture nor node.
+func newModelData() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("GRSM")
+	buf.Write([]byte{1, 4})
+	_ = binary.Write(&buf, binary.LittleEndian, [2]int32{0, 0})
+	buf.WriteByte(0xFF)
+	buf.Write(make([]byte, 16))
+	_ = binary.Write(&buf, binary.LittleEndian, int32(0))
+	// No node.
+	buf.Write(make([]byte, 40))
+	_ = binary.Write(&buf, binary.LittleEndian, int32(0))
+
+	return buf.Bytes()
+}
+
+// fakeLoader serves model files, counting the reads.
+type fakeLoader struct {
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func (l *fakeLoader) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.reads == nil {
+		l.reads = map[string]int{}
+	}
+	l.reads[name]++
+	if name == ModelDir+"missing.rsm" {
+		return nil, errors.New("entry not found")
+	}
+
+	return &grf.Entry{Data: newModelData()}, nil
+}
+
+// newWorld returns a world of 256 by 256 cells with a model at the
+// center of each region, the models of a row sharing their file.
+func newWorld() *rsw.ResourceWorldFile {
+	world := &rsw.ResourceWorldFile{}
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			// Centered on the map, 5 units per cell.
+			cellX, cellY := float32(x*RegionSize+RegionSize/2), float32(y*RegionSize+RegionSize/2)
+			world.Models = append(world.Models, rsw.Model{
+				FileName: string(rune('a'+y)) + ".rsm",
+				Position: mgl32.Vec3{(cellX - 128) * 5, 0, (cellY - 128) * 5},
+			})
+		}
+	}
+
+	return world
+}
+
+// waitModels waits for the models of r.
+func waitModels(t *testing.T, s *Streamer, r Region) []Model {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if models, ok := s.Models(r); ok {
+			return models
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("region %v not loaded", r)
+
+	return nil
+}
+
+func sortedRegions(s *Streamer) []Region {
+	regions := s.Regions()
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Y < regions[j].Y || (regions[i].Y == regions[j].Y && regions[i].X < regions[j].X)
+	})
+
+	return regions
+}
+
+func TestStreamer(t *testing.T) {
+	loader := &fakeLoader{}
+	s := NewStreamer(context.Background(), loader, newWorld(), 256, 256)
+	defer s.Close()
+
+	assert.Equal(t, Region{2, 3}, s.RegionAt(80, 100))
+
+	// The region of the camera and the ones around it.
+	s.Update(80, 100)
+	assert.Equal(t, []Region{
+		{1, 2}, {2, 2}, {3, 2},
+		{1, 3}, {2, 3}, {3, 3},
+		{1, 4}, {2, 4}, {3, 4},
+	}, sortedRegions(s))
+
+	models := waitModels(t, s, Region{2, 3})
+	require.Len(t, models, 1)
+	assert.Equal(t, "d.rsm", models[0].FileName)
+	assert.NotNil(t, models[0].File)
+
+	// A file per row of regions, read once.
+	for _, r := range s.Regions() {
+		waitModels(t, s, r)
+	}
+	assert.Equal(t, 3, s.Files())
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	assert.Equal(t, map[string]int{ModelDir + "c.rsm": 1, ModelDir + "d.rsm": 1, ModelDir + "e.rsm": 1}, loader.reads)
+}
+
+func TestStreamerPrefetch(t *testing.T) {
+	s := NewStreamer(context.Background(), &fakeLoader{}, newWorld(), 256, 256)
+	defer s.Close()
+
+	s.Update(80, 100)
+	// Moving east, the regions one further east are prefetched.
+	s.Update(81, 100)
+	assert.Equal(t, []Region{
+		{1, 2}, {2, 2}, {3, 2}, {4, 2},
+		{1, 3}, {2, 3}, {3, 3}, {4, 3},
+		{1, 4}, {2, 4}, {3, 4}, {4, 4},
+	}, sortedRegions(s))
+
+	// Moving north-east, the regions further than the prefetched ones
+	// are released. There is no region north of the map.
+	s.Update(150, 200)
+	assert.Equal(t, []Region{
+		{2, 4}, {3, 4}, {4, 4},
+		{3, 5}, {4, 5}, {5, 5},
+		{3, 6}, {4, 6}, {5, 6},
+		{3, 7}, {4, 7}, {5, 7}, {6, 7},
+	}, sortedRegions(s))
+	assert.Equal(t, 4, s.Files())
+}
+
+func TestStreamerMaxRegions(t *testing.T) {
+	s := NewStreamer(context.Background(), &fakeLoader{}, newWorld(), 256, 256)
+	defer s.Close()
+	s.Radius, s.MaxRegions = 2, 4
+
+	// The region of the camera first, then the closest ones.
+	s.Update(80, 100)
+	assert.Equal(t, []Region{{1, 2}, {2, 2}, {3, 2}, {2, 3}}, sortedRegions(s))
+}
+
+func TestStreamerError(t *testing.T) {
+	world := newWorld()
+	world.Models[0].FileName = "missing.rsm"
+	s := NewStreamer(context.Background(), &fakeLoader{}, world, 256, 256)
+	defer s.Close()
+
+	// Logged, the region showing no model.
+	s.Update(0, 0)
+	assert.Empty(t, waitModels(t, s, Region{0, 0}))
+	assert.Len(t, waitModels(t, s, Region{1, 0}), 1)
+}
+
+func TestStreamerClose(t *testing.T) {
+	s := NewStreamer(context.Background(), &fakeLoader{}, newWorld(), 256, 256)
+
+	s.Update(80, 100)
+	s.Close()
+	assert.Empty(t, s.Regions())
+	assert.Equal(t, 0, s.Files())
+}
@@ -0,0 +1,184 @@
+// Package metrics measures the client while it runs: frame and update
+// times, texture cache, GRF reads and packets. The metrics are expvar
+// variables, served as JSON on /debug/vars by the debug server (see
+// debugserver and the -debug-addr flag).
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DurationBuckets are the upper bounds, in milliseconds, of the duration
+// histograms: around the 16.7ms of a 60fps frame.
+var DurationBuckets = []float64{1, 2, 4, 8, 16, 33, 66, 133, 266}
+
+var (
+	// FrameTime is the duration of the frames.
+	FrameTime = NewHistogram("frame_ms", DurationBuckets...)
+	// SimulationTime and RenderTime are the durations of the updates of
+	// the simulation and render worlds of the scenes.
+	SimulationTime = NewHistogram("simulation_update_ms", DurationBuckets...)
+	RenderTime     = NewHistogram("render_update_ms", DurationBuckets...)
+
+	TextureHits      = expvar.NewInt("texture_cache_hits")
+	TextureMisses    = expvar.NewInt("texture_cache_misses")
+	TextureEvictions = expvar.NewInt("texture_cache_evictions")
+	// TextureBytes is the memory used by the cached textures.
+	TextureBytes = expvar.NewInt("texture_cache_bytes")
+
+	// GRFReads counts the entries read from the archives, GRFReadBytes
+	// their compressed size.
+	GRFReads     = expvar.NewInt("grf_reads")
+	GRFReadBytes = expvar.NewInt("grf_read_bytes")
+
+	// ModelsVisible is the number of map models in the view, and
+	// ModelDrawCalls the draw calls drawing them, during the last frame.
+	ModelsVisible  = expvar.NewInt("models_visible")
+	ModelDrawCalls = expvar.NewInt("model_draw_calls")
+	// GroundChunksVisible and GroundDrawCalls are the same for the chunks
+	// of the ground.
+	GroundChunksVisible = expvar.NewInt("ground_chunks_visible")
+	GroundDrawCalls     = expvar.NewInt("ground_draw_calls")
+
+	PacketsReceived = expvar.NewInt("packets_received")
+	PacketRate      = NewRate("packets_per_second")
+)
+
+// Histogram counts observations in buckets. It is an expvar.Var, shown as
+// cumulative buckets as in Prometheus.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []int64
+	count  int64
+	sum    float64
+}
+
+// NewHistogram returns a histogram of the given upper bounds, sorted, and
+// publishes it as name.
+func NewHistogram(name string, bounds ...float64) *Histogram {
+	h := newHistogram(bounds...)
+	expvar.Publish(name, h)
+
+	return h
+}
+
+func newHistogram(bounds ...float64) *Histogram {
+	return &Histogram{bounds: bounds, counts: make([]int64, len(bounds)+1)}
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+
+	h.counts[i]++
+	h.count++
+	h.sum += v
+}
+
+// ObserveDuration observes d in milliseconds.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(float64(d) / float64(time.Millisecond))
+}
+
+// Since observes the time elapsed since start, e.g.
+//
+//	defer metrics.RenderTime.Since(time.Now())
+func (h *Histogram) Since(start time.Time) {
+	h.ObserveDuration(time.Since(start))
+}
+
+type histogramJSON struct {
+	Count   int64            `json:"count"`
+	Sum     float64          `json:"sum"`
+	Buckets map[string]int64 `json:"buckets"`
+}
+
+func (h *Histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v := histogramJSON{Count: h.count, Sum: h.sum, Buckets: map[string]int64{}}
+
+	var cumulative int64
+	for i, c := range h.counts {
+		cumulative += c
+
+		bound := math.Inf(1)
+		if i < len(h.bounds) {
+			bound = h.bounds[i]
+		}
+		v.Buckets[strconv.FormatFloat(bound, 'g', -1, 64)] = cumulative
+	}
+
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// Rate counts events per second. It is an expvar.Var, shown as the count
+// of the last complete second.
+type Rate struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	second   int64
+	current  int64
+	previous int64
+}
+
+// NewRate returns a rate and publishes it as name.
+func NewRate(name string) *Rate {
+	r := newRate(time.Now)
+	expvar.Publish(name, r)
+
+	return r
+}
+
+func newRate(now func() time.Time) *Rate {
+	return &Rate{now: now}
+}
+
+func (r *Rate) Add(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.roll()
+	r.current += n
+}
+
+// PerSecond returns the count of the last complete second.
+func (r *Rate) PerSecond() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.roll()
+	return r.previous
+}
+
+func (r *Rate) String() string {
+	return strconv.FormatInt(r.PerSecond(), 10)
+}
+
+// roll moves to the current second. r.mu must be held.
+func (r *Rate) roll() {
+	second := r.now().Unix()
+	switch {
+	case second == r.second:
+		return
+	case second == r.second+1:
+		r.previous = r.current
+	default:
+		r.previous = 0
+	}
+
+	r.second, r.current = second, 0
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram(1, 10)
+	h.Observe(0.5)
+	h.Observe(1)
+	h.ObserveDuration(5 * time.Millisecond)
+	h.Observe(100)
+
+	var got histogramJSON
+	require.NoError(t, json.Unmarshal([]byte(h.String()), &got))
+	assert.Equal(t, histogramJSON{
+		Count:   4,
+		Sum:     106.5,
+		Buckets: map[string]int64{"1": 2, "10": 3, "+Inf": 4},
+	}, got)
+}
+
+func TestRate(t *testing.T) {
+	now := time.Unix(100, 0)
+	r := newRate(func() time.Time { return now })
+
+	r.Add(3)
+	r.Add(2)
+	assert.Equal(t, int64(0), r.PerSecond(), "the second is not over")
+
+	now = now.Add(time.Second)
+	r.Add(1)
+	assert.Equal(t, int64(5), r.PerSecond())
+	assert.Equal(t, "5", r.String())
+
+	now = now.Add(3 * time.Second)
+	assert.Equal(t, int64(0), r.PerSecond())
+}
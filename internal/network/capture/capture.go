@@ -0,0 +1,294 @@
+// Package capture reads the packets a server sent in a libpcap capture of
+// a session, e.g. taken with
+//
+//	tcpdump -i any -w session.pcap tcp port 5121
+//
+// The TCP segments of the server are put back in order, and decoded as
+// packets (see packet.Read) timed from the first captured frame. The
+// frames are Ethernet, Linux cooked (-i any), loopback or raw IP, over
+// IPv4 or IPv6.
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/network/packet"
+)
+
+// DefaultPort is the port of the map servers.
+const DefaultPort = 5121
+
+// The link types of the frames, see
+// https://www.tcpdump.org/linktypes.html.
+const (
+	linkNull     = 0
+	linkEthernet = 1
+	linkRaw      = 101
+	linkLinuxSLL = 113
+	linkLinuxSL2 = 276
+)
+
+// Record is a packet of the server.
+type Record struct {
+	// Time is the time of the segment completing the packet, from the
+	// first frame of the capture.
+	Time   time.Duration
+	Packet packet.Packet
+}
+
+// Read returns the packets sent from port in the capture of r, in the
+// order they were received. The length of the packets following a packet
+// unknown to package packet is unknown too: the packets decoded before it
+// are returned with the error.
+func Read(r io.Reader, port uint16) ([]Record, error) {
+	var header struct {
+		Magic        uint32
+		VersionMajor uint16
+		VersionMinor uint16
+		ThisZone     int32
+		SigFigs      uint32
+		SnapLen      uint32
+		LinkType     uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, errors.Wrap(err, "could not read pcap header")
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	nano := false
+	switch header.Magic {
+	case 0xa1b2c3d4:
+	case 0xa1b23c4d:
+		nano = true
+	case 0xd4c3b2a1:
+		order = binary.BigEndian
+	case 0x4d3cb2a1:
+		order, nano = binary.BigEndian, true
+	default:
+		return nil, fmt.Errorf("not a pcap file, magic 0x%08x", header.Magic)
+	}
+	linkType := order.Uint32(leBytes(header.LinkType))
+
+	c := &capture{port: port, streams: map[string]*stream{}}
+	var start time.Time
+	for first := true; ; first = false {
+		var frame struct{ Sec, Frac, Captured, Original uint32 }
+		if err := binary.Read(r, order, &frame); err == io.EOF {
+			break
+		} else if err != nil {
+			return c.records, errors.Wrap(err, "could not read pcap frame")
+		}
+
+		data := make([]byte, frame.Captured)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return c.records, errors.Wrap(err, "could not read pcap frame")
+		}
+
+		frac := time.Duration(frame.Frac) * time.Microsecond
+		if nano {
+			frac = time.Duration(frame.Frac)
+		}
+		t := time.Unix(int64(frame.Sec), int64(frac))
+		if first {
+			start = t
+		}
+
+		if err := c.frame(linkType, data, t.Sub(start)); err != nil {
+			return c.records, err
+		}
+	}
+
+	return c.records, nil
+}
+
+// leBytes returns v as read little endian, to read it again in another
+// order.
+func leBytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+type capture struct {
+	port    uint16
+	streams map[string]*stream
+	records []Record
+}
+
+// stream is a TCP connection from the server.
+type stream struct {
+	// next is the sequence number of the next byte, once known.
+	next    uint32
+	started bool
+	// pending are the segments received ahead of next, by sequence number.
+	pending map[uint32][]byte
+	buf     []byte
+}
+
+// frame reads the TCP segment of the server in a frame of linkType, if
+// any, received at t.
+func (c *capture) frame(linkType uint32, data []byte, t time.Duration) error {
+	ip, ok := network(linkType, data)
+	if !ok || len(ip) == 0 {
+		return nil
+	}
+
+	var src, dst, tcp []byte
+	switch ip[0] >> 4 {
+	case 4:
+		ihl := int(ip[0]&0x0f) * 4
+		if len(ip) < 20 || len(ip) < ihl || ip[9] != 6 {
+			return nil
+		}
+		if total := int(binary.BigEndian.Uint16(ip[2:])); total >= ihl && total <= len(ip) {
+			ip = ip[:total]
+		}
+		src, dst, tcp = ip[12:16], ip[16:20], ip[ihl:]
+	case 6:
+		if len(ip) < 40 || ip[6] != 6 {
+			return nil
+		}
+		if payload := int(binary.BigEndian.Uint16(ip[4:])); 40+payload <= len(ip) {
+			ip = ip[:40+payload]
+		}
+		src, dst, tcp = ip[8:24], ip[24:40], ip[40:]
+	default:
+		return nil
+	}
+
+	if len(tcp) < 20 || binary.BigEndian.Uint16(tcp) != c.port {
+		return nil
+	}
+	offset := int(tcp[12]>>4) * 4
+	if len(tcp) < offset {
+		return nil
+	}
+
+	key := fmt.Sprintf("%x:%x:%d", src, dst, binary.BigEndian.Uint16(tcp[2:]))
+	s, ok := c.streams[key]
+	if !ok {
+		s = &stream{pending: map[uint32][]byte{}}
+		c.streams[key] = s
+	}
+
+	seq, syn := binary.BigEndian.Uint32(tcp[4:]), tcp[13]&0x02 != 0
+	if syn {
+		s.next, s.started = seq+1, true
+		return nil
+	}
+
+	s.receive(seq, tcp[offset:])
+	return c.decode(s, t)
+}
+
+// network returns the IP packet of a frame of linkType.
+func network(linkType uint32, data []byte) ([]byte, bool) {
+	switch linkType {
+	case linkNull:
+		if len(data) < 4 {
+			return nil, false
+		}
+		return data[4:], true
+	case linkEthernet:
+		if len(data) < 14 {
+			return nil, false
+		}
+		etherType, data := binary.BigEndian.Uint16(data[12:]), data[14:]
+		// 802.1Q tags.
+		for etherType == 0x8100 && len(data) >= 4 {
+			etherType, data = binary.BigEndian.Uint16(data[2:]), data[4:]
+		}
+		return data, etherType == 0x0800 || etherType == 0x86dd
+	case linkRaw:
+		return data, true
+	case linkLinuxSLL:
+		if len(data) < 16 {
+			return nil, false
+		}
+		return data[16:], true
+	case linkLinuxSL2:
+		if len(data) < 20 {
+			return nil, false
+		}
+		return data[20:], true
+	}
+
+	return nil, false
+}
+
+// receive adds the payload of the segment of seq, in order.
+func (s *stream) receive(seq uint32, payload []byte) {
+	if len(payload) == 0 {
+		return
+	}
+	// The capture started in the middle of the connection.
+	if !s.started {
+		s.next, s.started = seq, true
+	}
+
+	s.pending[seq] = append([]byte(nil), payload...)
+	for {
+		progressed := false
+		seqs := make([]uint32, 0, len(s.pending))
+		for seq := range s.pending {
+			seqs = append(seqs, seq)
+		}
+		sort.Slice(seqs, func(i, j int) bool { return int32(seqs[i]-s.next) < int32(seqs[j]-s.next) })
+
+		for _, seq := range seqs {
+			data := s.pending[seq]
+			ahead := int32(seq - s.next)
+			if ahead > 0 {
+				break
+			}
+			delete(s.pending, seq)
+
+			// Retransmitted, in part or in full.
+			if overlap := int(-ahead); overlap < len(data) {
+				s.buf = append(s.buf, data[overlap:]...)
+				s.next += uint32(len(data) - overlap)
+				progressed = true
+			}
+		}
+
+		if !progressed {
+			return
+		}
+	}
+}
+
+// decode decodes the packets complete in the buffer of s.
+func (c *capture) decode(s *stream, t time.Duration) error {
+	for len(s.buf) >= 2 {
+		id := binary.LittleEndian.Uint16(s.buf)
+		length, ok := packet.Lengths[id]
+		if !ok {
+			return fmt.Errorf("unknown packet 0x%04x after %d packets", id, len(c.records))
+		}
+		if length == -1 {
+			if len(s.buf) < 4 {
+				return nil
+			}
+			length = int(binary.LittleEndian.Uint16(s.buf[2:]))
+		}
+		if len(s.buf) < length {
+			return nil
+		}
+
+		p, err := packet.Read(bytes.NewReader(s.buf[:length]))
+		if err != nil {
+			return err
+		}
+		c.records = append(c.records, Record{Time: t, Packet: p})
+		s.buf = s.buf[length:]
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/network/packet"
+)
+
+// pcap builds an Ethernet capture of microsecond timestamps.
+type pcap struct {
+	bytes.Buffer
+}
+
+func newPcap() *pcap {
+	p := &pcap{}
+	_ = binary.Write(p, binary.LittleEndian, []uint32{0xa1b2c3d4, 0x00040002, 0, 0, 65535, linkEthernet})
+	return p
+}
+
+// segment adds a frame of a TCP segment from srcPort to dstPort, at t.
+func (p *pcap) segment(t time.Duration, srcPort, dstPort uint16, seq uint32, flags byte, payload []byte) {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp, srcPort)
+	binary.BigEndian.PutUint16(tcp[2:], dstPort)
+	binary.BigEndian.PutUint32(tcp[4:], seq)
+	tcp[12] = 5 << 4
+	tcp[13] = flags
+	tcp = append(tcp, payload...)
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)))
+	ip[9] = 6
+	copy(ip[12:], []byte{10, 0, 0, 1})
+	copy(ip[16:], []byte{10, 0, 0, 2})
+	ip = append(ip, tcp...)
+
+	frame := make([]byte, 14)
+	binary.BigEndian.PutUint16(frame[12:], 0x0800)
+	frame = append(frame, ip...)
+
+	sec, usec := uint32(t/time.Second), uint32(t%time.Second/time.Microsecond)
+	_ = binary.Write(p, binary.LittleEndian, []uint32{1600000000 + sec, usec, uint32(len(frame)), uint32(len(frame))})
+	p.Write(frame)
+}
+
+func TestRead(t *testing.T) {
+	chat := (&packet.ZC_NOTIFY_CHAT{GID: 1, Message: "Hello"}).Encode()
+	vanish := (&packet.ZC_NOTIFY_VANISH{GID: 1}).Encode()
+	effect := (&packet.ZC_NOTIFY_EFFECT2{AID: 1, EffectID: 25}).Encode()
+	stream := append(append(append([]byte{}, chat...), vanish...), effect...)
+
+	p := newPcap()
+	p.segment(0, 50000, DefaultPort, 99, 0x02, nil)
+	p.segment(time.Millisecond, DefaultPort, 50000, 1000, 0x12, nil)
+	// The chat split in two, the vanish retransmitted and the effect
+	// received before it.
+	p.segment(10*time.Millisecond, DefaultPort, 50000, 1001, 0x18, stream[:5])
+	p.segment(20*time.Millisecond, 50000, DefaultPort, 100, 0x18, []byte{0x85, 0x00, 1, 2, 3})
+	p.segment(30*time.Millisecond, DefaultPort, 50000, 1006, 0x18, stream[5:len(chat)])
+	p.segment(40*time.Millisecond, DefaultPort, 50000, 1001+uint32(len(chat)+len(vanish)), 0x18, effect)
+	p.segment(50*time.Millisecond, DefaultPort, 50000, 1001+uint32(len(chat)), 0x18, vanish)
+	p.segment(60*time.Millisecond, DefaultPort, 50000, 1001+uint32(len(chat)), 0x18, vanish)
+
+	records, err := Read(p, DefaultPort)
+	require.NoError(t, err)
+	assert.Equal(t, []Record{
+		{Time: 30 * time.Millisecond, Packet: &packet.ZC_NOTIFY_CHAT{GID: 1, Message: "Hello"}},
+		{Time: 50 * time.Millisecond, Packet: &packet.ZC_NOTIFY_VANISH{GID: 1}},
+		{Time: 50 * time.Millisecond, Packet: &packet.ZC_NOTIFY_EFFECT2{AID: 1, EffectID: 25}},
+	}, records)
+}
+
+func TestReadUnknownPacket(t *testing.T) {
+	p := newPcap()
+	p.segment(0, DefaultPort, 50000, 1, 0x18, append((&packet.ZC_NOTIFY_VANISH{GID: 1}).Encode(), 0xff, 0xff, 0, 0))
+
+	records, err := Read(p, DefaultPort)
+	assert.Error(t, err)
+	assert.Len(t, records, 1)
+
+	_, err = Read(bytes.NewReader([]byte("not a capture, not at all")), DefaultPort)
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+// Package packet holds the client/server packet definitions. Most of it is
+// generated from packets.yaml, run `go generate` after editing the spec.
+package packet
+
+//go:generate go run ../../../cmd/packetgen -spec packets.yaml -o packets_gen.go -doc PACKETS.md
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Packet is implemented by every generated packet.
+type Packet interface {
+	ID() uint16
+	// Encode returns the whole packet, header included.
+	Encode() []byte
+	// Decode reads the whole packet, header included.
+	Decode(data []byte) error
+}
+
+// New returns an empty packet for the given id.
+func New(id uint16) (Packet, error) {
+	newPacket, ok := Packets[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown packet 0x%04x", id)
+	}
+
+	return newPacket(), nil
+}
+
+// Read reads and decodes the next packet of a stream.
+func Read(r io.Reader) (Packet, error) {
+	var id uint16
+	if err := binary.Read(r, binary.LittleEndian, &id); err != nil {
+		return nil, err
+	}
+
+	p, err := New(id)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []byte{byte(id), byte(id >> 8)}
+	length := Lengths[id]
+
+	if length == -1 {
+		var l uint16
+		if err = binary.Read(r, binary.LittleEndian, &l); err != nil {
+			return nil, errors.Wrapf(err, "could not read packet 0x%04x length", id)
+		}
+
+		header = append(header, byte(l), byte(l>>8))
+		length = int(l)
+	}
+
+	if length < len(header) {
+		return nil, fmt.Errorf("invalid packet 0x%04x length %d", id, length)
+	}
+
+	data := make([]byte, length)
+	copy(data, header)
+	if _, err = io.ReadFull(r, data[len(header):]); err != nil {
+		return nil, errors.Wrapf(err, "could not read packet 0x%04x", id)
+	}
+
+	if err = p.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func writeHeader(w *bytes.Buffer, id uint16, variable bool) {
+	_ = binary.Write(w, binary.LittleEndian, id)
+
+	if variable {
+		// Length placeholder, see patchLength.
+		_ = binary.Write(w, binary.LittleEndian, uint16(0))
+	}
+}
+
+func patchLength(data []byte) []byte {
+	binary.LittleEndian.PutUint16(data[2:], uint16(len(data)))
+	return data
+}
+
+func readHeader(r *bytes.Reader, id uint16, length int) error {
+	var got uint16
+	if err := binary.Read(r, binary.LittleEndian, &got); err != nil {
+		return errors.Wrap(err, "could not read packet id")
+	}
+
+	if got != id {
+		return fmt.Errorf("unexpected packet 0x%04x, want 0x%04x", got, id)
+	}
+
+	if length == -1 {
+		var l uint16
+		if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+			return errors.Wrap(err, "could not read packet length")
+		}
+
+		length = int(l)
+	}
+
+	if int64(length) != r.Size() {
+		return fmt.Errorf("packet 0x%04x has length %d, want %d", id, r.Size(), length)
+	}
+
+	return nil
+}
+
+func writeFixedString(w *bytes.Buffer, s string, n int) {
+	buf := make([]byte, n)
+	copy(buf, s)
+	w.Write(buf)
+}
+
+func readFixedString(r *bytes.Reader, n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+
+	return string(trimString(buf)), nil
+}
+
+// trimString cuts a zero terminated string.
+func trimString(b []byte) []byte {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return b[:i]
+	}
+
+	return b
+}
+
+func decodeError(field string, err error) error {
+	return errors.Wrapf(err, "could not decode field %s", field)
+}
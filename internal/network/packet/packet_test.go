@@ -0,0 +1,55 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTrip(t *testing.T) {
+	packets := []Packet{
+		&CA_LOGIN{Version: 55, Username: "midgard", Password: "secret", ClientType: 22},
+		&AC_ACCEPT_LOGIN{AID: 2000000, Sex: 1, LastLoginTime: "2021-01-01", Servers: []ServerInfo{
+			{IP: 0x0100007f, Port: 6121, Name: "Midgarts"},
+			{IP: 0x0200007f, Port: 6122, Name: "Test", UserCount: 3},
+		}},
+		&ZC_NOTIFY_VANISH{GID: 110000, Type: 1},
+		&ZC_NOTIFY_CHAT{GID: 110000, Message: "Hello"},
+		&ZC_NOTIFY_EFFECT{AID: 110000, Type: 1},
+		&ZC_NOTIFY_EFFECT2{AID: 110000, EffectID: 25},
+		&ZC_STATE_CHANGE3{AID: 110000, BodyState: 2, HealthState: 0x11},
+		&ZC_NOTIFY_STANDENTRY{ObjectType: 5, GID: 110000, Speed: 200, Job: 1002, PosDir: EncodePosDir(150, 180, 4), CLevel: 1},
+		&ZC_NOTIFY_MOVE{GID: 110000, MoveData: EncodeMoveData(150, 180, 155, 170), MoveStartTime: 1000},
+	}
+
+	for _, p := range packets {
+		data := p.Encode()
+		if length := Lengths[p.ID()]; length != -1 {
+			assert.Len(t, data, length)
+		}
+
+		got, err := Read(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, p, got)
+	}
+}
+
+func TestDecodeLengthMismatch(t *testing.T) {
+	data := (&ZC_NOTIFY_VANISH{GID: 1}).Encode()
+
+	assert.Error(t, new(ZC_NOTIFY_VANISH).Decode(data[:len(data)-1]))
+	assert.Error(t, new(CA_LOGIN).Decode(data))
+}
+
+func TestPosition(t *testing.T) {
+	x, y, dir := DecodePosDir(EncodePosDir(1023, 517, 7))
+	assert.Equal(t, []int{1023, 517, 7}, []int{x, y, int(dir)})
+
+	x0, y0, x1, y1 := DecodeMoveData(EncodeMoveData(150, 180, 1000, 3))
+	assert.Equal(t, []int{150, 180, 1000, 3}, []int{x0, y0, x1, y1})
+
+	// As packed by the servers.
+	x, y, dir = DecodePosDir([3]byte{0x25, 0x8b, 0x44})
+	assert.Equal(t, []int{150, 180, 4}, []int{x, y, int(dir)})
+}
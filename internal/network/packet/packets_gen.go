@@ -0,0 +1,680 @@
+// Code generated by packetgen; DO NOT EDIT.
+
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+)
+
+// PacketVer is the packet version the definitions were generated for.
+const PacketVer = 20120410
+
+// ServerInfo is a character server entry of the login server list.
+type ServerInfo struct {
+	IP        uint32
+	Port      uint16
+	Name      string
+	UserCount uint16
+	State     uint16
+	Property  uint16
+}
+
+func (s *ServerInfo) encode(w *bytes.Buffer) {
+	_ = binary.Write(w, binary.LittleEndian, s.IP)
+	_ = binary.Write(w, binary.LittleEndian, s.Port)
+	writeFixedString(w, s.Name, 20)
+	_ = binary.Write(w, binary.LittleEndian, s.UserCount)
+	_ = binary.Write(w, binary.LittleEndian, s.State)
+	_ = binary.Write(w, binary.LittleEndian, s.Property)
+}
+
+func (s *ServerInfo) decode(r *bytes.Reader) (err error) {
+	if err = binary.Read(r, binary.LittleEndian, &s.IP); err != nil {
+		return decodeError("IP", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &s.Port); err != nil {
+		return decodeError("Port", err)
+	}
+	if s.Name, err = readFixedString(r, 20); err != nil {
+		return decodeError("Name", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &s.UserCount); err != nil {
+		return decodeError("UserCount", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &s.State); err != nil {
+		return decodeError("State", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &s.Property); err != nil {
+		return decodeError("Property", err)
+	}
+	return nil
+}
+
+// CA_LOGIN requests a login to the login server.
+type CA_LOGIN struct {
+	Version    uint32
+	Username   string
+	Password   string
+	ClientType uint8
+}
+
+func (p *CA_LOGIN) ID() uint16 {
+	return 0x0064
+}
+
+func (p *CA_LOGIN) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.Version)
+	writeFixedString(w, p.Username, 24)
+	writeFixedString(w, p.Password, 24)
+	_ = binary.Write(w, binary.LittleEndian, p.ClientType)
+	return w.Bytes()
+}
+
+func (p *CA_LOGIN) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 55); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Version); err != nil {
+		return decodeError("Version", err)
+	}
+	if p.Username, err = readFixedString(r, 24); err != nil {
+		return decodeError("Username", err)
+	}
+	if p.Password, err = readFixedString(r, 24); err != nil {
+		return decodeError("Password", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.ClientType); err != nil {
+		return decodeError("ClientType", err)
+	}
+	return nil
+}
+
+// AC_ACCEPT_LOGIN accepts a login and lists the character servers.
+type AC_ACCEPT_LOGIN struct {
+	AuthCode      int32
+	AID           uint32
+	UserLevel     uint32
+	LastLoginIP   uint32
+	LastLoginTime string
+	Sex           uint8
+	Servers       []ServerInfo
+}
+
+func (p *AC_ACCEPT_LOGIN) ID() uint16 {
+	return 0x0069
+}
+
+func (p *AC_ACCEPT_LOGIN) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), true)
+	_ = binary.Write(w, binary.LittleEndian, p.AuthCode)
+	_ = binary.Write(w, binary.LittleEndian, p.AID)
+	_ = binary.Write(w, binary.LittleEndian, p.UserLevel)
+	_ = binary.Write(w, binary.LittleEndian, p.LastLoginIP)
+	writeFixedString(w, p.LastLoginTime, 26)
+	_ = binary.Write(w, binary.LittleEndian, p.Sex)
+	for i := range p.Servers {
+		p.Servers[i].encode(w)
+	}
+	return patchLength(w.Bytes())
+}
+
+func (p *AC_ACCEPT_LOGIN) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), -1); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.AuthCode); err != nil {
+		return decodeError("AuthCode", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.AID); err != nil {
+		return decodeError("AID", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.UserLevel); err != nil {
+		return decodeError("UserLevel", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.LastLoginIP); err != nil {
+		return decodeError("LastLoginIP", err)
+	}
+	if p.LastLoginTime, err = readFixedString(r, 26); err != nil {
+		return decodeError("LastLoginTime", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Sex); err != nil {
+		return decodeError("Sex", err)
+	}
+	p.Servers = nil
+	for r.Len() > 0 {
+		var e ServerInfo
+		if err = e.decode(r); err != nil {
+			return decodeError("Servers", err)
+		}
+		p.Servers = append(p.Servers, e)
+	}
+	return nil
+}
+
+// AC_REFUSE_LOGIN refuses a login.
+type AC_REFUSE_LOGIN struct {
+	ErrorCode uint8
+	// Set when the account is banned.
+	BlockDate string
+}
+
+func (p *AC_REFUSE_LOGIN) ID() uint16 {
+	return 0x006a
+}
+
+func (p *AC_REFUSE_LOGIN) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.ErrorCode)
+	writeFixedString(w, p.BlockDate, 20)
+	return w.Bytes()
+}
+
+func (p *AC_REFUSE_LOGIN) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 23); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.ErrorCode); err != nil {
+		return decodeError("ErrorCode", err)
+	}
+	if p.BlockDate, err = readFixedString(r, 20); err != nil {
+		return decodeError("BlockDate", err)
+	}
+	return nil
+}
+
+// ZC_ACCEPT_ENTER accepts the connection to the map server.
+type ZC_ACCEPT_ENTER struct {
+	StartTime uint32
+	// Packed x/y cell and direction.
+	PosDir [3]byte
+	XSize  uint8
+	YSize  uint8
+}
+
+func (p *ZC_ACCEPT_ENTER) ID() uint16 {
+	return 0x0073
+}
+
+func (p *ZC_ACCEPT_ENTER) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.StartTime)
+	_ = binary.Write(w, binary.LittleEndian, p.PosDir)
+	_ = binary.Write(w, binary.LittleEndian, p.XSize)
+	_ = binary.Write(w, binary.LittleEndian, p.YSize)
+	return w.Bytes()
+}
+
+func (p *ZC_ACCEPT_ENTER) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 11); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.StartTime); err != nil {
+		return decodeError("StartTime", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.PosDir); err != nil {
+		return decodeError("PosDir", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.XSize); err != nil {
+		return decodeError("XSize", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.YSize); err != nil {
+		return decodeError("YSize", err)
+	}
+	return nil
+}
+
+// ZC_NOTIFY_STANDENTRY notifies that an entity standing still came into the view range.
+type ZC_NOTIFY_STANDENTRY struct {
+	// 0: player, 1: NPC, 5: monster, 6: NPC (event), 7: pet, 8: homunculus, 9: mercenary.
+	ObjectType uint8
+	GID        uint32
+	// Milliseconds to walk a cell.
+	Speed       int16
+	BodyState   int16
+	HealthState int16
+	EffectState int16
+	// The job of the players, the sprite id of the monsters and NPCs.
+	Job         int16
+	Head        int16
+	Weapon      int16
+	Accessory   int16
+	Shield      int16
+	Accessory2  int16
+	Accessory3  int16
+	HeadPalette int16
+	BodyPalette int16
+	HeadDir     int16
+	GUID        uint32
+	GEmblemVer  int16
+	Honor       int16
+	Virtue      int16
+	IsPKModeON  uint8
+	// 0: female, 1: male.
+	Sex uint8
+	// Packed x/y cell and direction.
+	PosDir [3]byte
+	XSize  uint8
+	YSize  uint8
+	State  uint8
+	CLevel int16
+}
+
+func (p *ZC_NOTIFY_STANDENTRY) ID() uint16 {
+	return 0x0078
+}
+
+func (p *ZC_NOTIFY_STANDENTRY) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.ObjectType)
+	_ = binary.Write(w, binary.LittleEndian, p.GID)
+	_ = binary.Write(w, binary.LittleEndian, p.Speed)
+	_ = binary.Write(w, binary.LittleEndian, p.BodyState)
+	_ = binary.Write(w, binary.LittleEndian, p.HealthState)
+	_ = binary.Write(w, binary.LittleEndian, p.EffectState)
+	_ = binary.Write(w, binary.LittleEndian, p.Job)
+	_ = binary.Write(w, binary.LittleEndian, p.Head)
+	_ = binary.Write(w, binary.LittleEndian, p.Weapon)
+	_ = binary.Write(w, binary.LittleEndian, p.Accessory)
+	_ = binary.Write(w, binary.LittleEndian, p.Shield)
+	_ = binary.Write(w, binary.LittleEndian, p.Accessory2)
+	_ = binary.Write(w, binary.LittleEndian, p.Accessory3)
+	_ = binary.Write(w, binary.LittleEndian, p.HeadPalette)
+	_ = binary.Write(w, binary.LittleEndian, p.BodyPalette)
+	_ = binary.Write(w, binary.LittleEndian, p.HeadDir)
+	_ = binary.Write(w, binary.LittleEndian, p.GUID)
+	_ = binary.Write(w, binary.LittleEndian, p.GEmblemVer)
+	_ = binary.Write(w, binary.LittleEndian, p.Honor)
+	_ = binary.Write(w, binary.LittleEndian, p.Virtue)
+	_ = binary.Write(w, binary.LittleEndian, p.IsPKModeON)
+	_ = binary.Write(w, binary.LittleEndian, p.Sex)
+	_ = binary.Write(w, binary.LittleEndian, p.PosDir)
+	_ = binary.Write(w, binary.LittleEndian, p.XSize)
+	_ = binary.Write(w, binary.LittleEndian, p.YSize)
+	_ = binary.Write(w, binary.LittleEndian, p.State)
+	_ = binary.Write(w, binary.LittleEndian, p.CLevel)
+	return w.Bytes()
+}
+
+func (p *ZC_NOTIFY_STANDENTRY) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 55); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.ObjectType); err != nil {
+		return decodeError("ObjectType", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.GID); err != nil {
+		return decodeError("GID", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Speed); err != nil {
+		return decodeError("Speed", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.BodyState); err != nil {
+		return decodeError("BodyState", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.HealthState); err != nil {
+		return decodeError("HealthState", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.EffectState); err != nil {
+		return decodeError("EffectState", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Job); err != nil {
+		return decodeError("Job", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Head); err != nil {
+		return decodeError("Head", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Weapon); err != nil {
+		return decodeError("Weapon", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Accessory); err != nil {
+		return decodeError("Accessory", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Shield); err != nil {
+		return decodeError("Shield", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Accessory2); err != nil {
+		return decodeError("Accessory2", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Accessory3); err != nil {
+		return decodeError("Accessory3", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.HeadPalette); err != nil {
+		return decodeError("HeadPalette", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.BodyPalette); err != nil {
+		return decodeError("BodyPalette", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.HeadDir); err != nil {
+		return decodeError("HeadDir", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.GUID); err != nil {
+		return decodeError("GUID", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.GEmblemVer); err != nil {
+		return decodeError("GEmblemVer", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Honor); err != nil {
+		return decodeError("Honor", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Virtue); err != nil {
+		return decodeError("Virtue", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.IsPKModeON); err != nil {
+		return decodeError("IsPKModeON", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Sex); err != nil {
+		return decodeError("Sex", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.PosDir); err != nil {
+		return decodeError("PosDir", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.XSize); err != nil {
+		return decodeError("XSize", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.YSize); err != nil {
+		return decodeError("YSize", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.State); err != nil {
+		return decodeError("State", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.CLevel); err != nil {
+		return decodeError("CLevel", err)
+	}
+	return nil
+}
+
+// ZC_NOTIFY_VANISH notifies that an entity left the view range.
+type ZC_NOTIFY_VANISH struct {
+	GID uint32
+	// 0: out of sight, 1: died, 2: logged out, 3: teleported.
+	Type uint8
+}
+
+func (p *ZC_NOTIFY_VANISH) ID() uint16 {
+	return 0x0080
+}
+
+func (p *ZC_NOTIFY_VANISH) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.GID)
+	_ = binary.Write(w, binary.LittleEndian, p.Type)
+	return w.Bytes()
+}
+
+func (p *ZC_NOTIFY_VANISH) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 7); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.GID); err != nil {
+		return decodeError("GID", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Type); err != nil {
+		return decodeError("Type", err)
+	}
+	return nil
+}
+
+// ZC_NOTIFY_MOVE notifies that an entity in the view range walks.
+type ZC_NOTIFY_MOVE struct {
+	GID uint32
+	// Packed x/y cells of the start and the destination of the walk.
+	MoveData      [6]byte
+	MoveStartTime uint32
+}
+
+func (p *ZC_NOTIFY_MOVE) ID() uint16 {
+	return 0x0086
+}
+
+func (p *ZC_NOTIFY_MOVE) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.GID)
+	_ = binary.Write(w, binary.LittleEndian, p.MoveData)
+	_ = binary.Write(w, binary.LittleEndian, p.MoveStartTime)
+	return w.Bytes()
+}
+
+func (p *ZC_NOTIFY_MOVE) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 16); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.GID); err != nil {
+		return decodeError("GID", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.MoveData); err != nil {
+		return decodeError("MoveData", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.MoveStartTime); err != nil {
+		return decodeError("MoveStartTime", err)
+	}
+	return nil
+}
+
+// ZC_NOTIFY_CHAT is a chat message said by an entity.
+type ZC_NOTIFY_CHAT struct {
+	GID     uint32
+	Message string
+}
+
+func (p *ZC_NOTIFY_CHAT) ID() uint16 {
+	return 0x008d
+}
+
+func (p *ZC_NOTIFY_CHAT) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), true)
+	_ = binary.Write(w, binary.LittleEndian, p.GID)
+	w.WriteString(p.Message)
+	return patchLength(w.Bytes())
+}
+
+func (p *ZC_NOTIFY_CHAT) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), -1); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.GID); err != nil {
+		return decodeError("GID", err)
+	}
+	rest, _ := ioutil.ReadAll(r)
+	p.Message = string(trimString(rest))
+	return nil
+}
+
+// ZC_NOTIFY_EFFECT shows an effect of the client on an entity, such as the level-up aura.
+type ZC_NOTIFY_EFFECT struct {
+	AID uint32
+	// 0: base level up, 1: job level up, 2: refine failure, 3: refine success, 4: game over, 5: pharmacy success, 6: pharmacy failure, 7: base level up (Super Novice), 8: job level up (Super Novice), 9: base level up (Taekwon).
+	Type uint32
+}
+
+func (p *ZC_NOTIFY_EFFECT) ID() uint16 {
+	return 0x019b
+}
+
+func (p *ZC_NOTIFY_EFFECT) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.AID)
+	_ = binary.Write(w, binary.LittleEndian, p.Type)
+	return w.Bytes()
+}
+
+func (p *ZC_NOTIFY_EFFECT) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 10); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.AID); err != nil {
+		return decodeError("AID", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Type); err != nil {
+		return decodeError("Type", err)
+	}
+	return nil
+}
+
+// ZC_NOTIFY_EFFECT2 shows an effect on an entity.
+type ZC_NOTIFY_EFFECT2 struct {
+	AID uint32
+	// The EF_* number of the effect, see internal/effect.
+	EffectID uint32
+}
+
+func (p *ZC_NOTIFY_EFFECT2) ID() uint16 {
+	return 0x01f3
+}
+
+func (p *ZC_NOTIFY_EFFECT2) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.AID)
+	_ = binary.Write(w, binary.LittleEndian, p.EffectID)
+	return w.Bytes()
+}
+
+func (p *ZC_NOTIFY_EFFECT2) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 10); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.AID); err != nil {
+		return decodeError("AID", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.EffectID); err != nil {
+		return decodeError("EffectID", err)
+	}
+	return nil
+}
+
+// ZC_STATE_CHANGE3 changes the body and health states of an entity.
+type ZC_STATE_CHANGE3 struct {
+	AID uint32
+	// 1: stone, 2: frozen, 3: stun, 4: sleep, 6: turning to stone.
+	BodyState uint16
+	// Bits, 0x01: poison, 0x02: curse, 0x04: silence, 0x08: confusion, 0x10: blind.
+	HealthState uint16
+	EffectState int32
+	IsPKModeON  uint8
+}
+
+func (p *ZC_STATE_CHANGE3) ID() uint16 {
+	return 0x0229
+}
+
+func (p *ZC_STATE_CHANGE3) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.AID)
+	_ = binary.Write(w, binary.LittleEndian, p.BodyState)
+	_ = binary.Write(w, binary.LittleEndian, p.HealthState)
+	_ = binary.Write(w, binary.LittleEndian, p.EffectState)
+	_ = binary.Write(w, binary.LittleEndian, p.IsPKModeON)
+	return w.Bytes()
+}
+
+func (p *ZC_STATE_CHANGE3) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 15); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.AID); err != nil {
+		return decodeError("AID", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.BodyState); err != nil {
+		return decodeError("BodyState", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.HealthState); err != nil {
+		return decodeError("HealthState", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.EffectState); err != nil {
+		return decodeError("EffectState", err)
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.IsPKModeON); err != nil {
+		return decodeError("IsPKModeON", err)
+	}
+	return nil
+}
+
+// CZ_REQUEST_MOVE requests a move to the given cell.
+type CZ_REQUEST_MOVE struct {
+	// Packed x/y cell.
+	Dest [3]byte
+}
+
+func (p *CZ_REQUEST_MOVE) ID() uint16 {
+	return 0x035f
+}
+
+func (p *CZ_REQUEST_MOVE) Encode() []byte {
+	w := new(bytes.Buffer)
+	writeHeader(w, p.ID(), false)
+	_ = binary.Write(w, binary.LittleEndian, p.Dest)
+	return w.Bytes()
+}
+
+func (p *CZ_REQUEST_MOVE) Decode(data []byte) (err error) {
+	r := bytes.NewReader(data)
+	if err = readHeader(r, p.ID(), 5); err != nil {
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &p.Dest); err != nil {
+		return decodeError("Dest", err)
+	}
+	return nil
+}
+
+// Packets maps packet ids to their constructors.
+var Packets = map[uint16]func() Packet{
+	0x0064: func() Packet { return new(CA_LOGIN) },
+	0x0069: func() Packet { return new(AC_ACCEPT_LOGIN) },
+	0x006a: func() Packet { return new(AC_REFUSE_LOGIN) },
+	0x0073: func() Packet { return new(ZC_ACCEPT_ENTER) },
+	0x0078: func() Packet { return new(ZC_NOTIFY_STANDENTRY) },
+	0x0080: func() Packet { return new(ZC_NOTIFY_VANISH) },
+	0x0086: func() Packet { return new(ZC_NOTIFY_MOVE) },
+	0x008d: func() Packet { return new(ZC_NOTIFY_CHAT) },
+	0x019b: func() Packet { return new(ZC_NOTIFY_EFFECT) },
+	0x01f3: func() Packet { return new(ZC_NOTIFY_EFFECT2) },
+	0x0229: func() Packet { return new(ZC_STATE_CHANGE3) },
+	0x035f: func() Packet { return new(CZ_REQUEST_MOVE) },
+}
+
+// Lengths maps packet ids to their length, -1 for variable length packets.
+var Lengths = map[uint16]int{
+	0x0064: 55,
+	0x0069: -1,
+	0x006a: 23,
+	0x0073: 11,
+	0x0078: 55,
+	0x0080: 7,
+	0x0086: 16,
+	0x008d: -1,
+	0x019b: 10,
+	0x01f3: 10,
+	0x0229: 15,
+	0x035f: 5,
+}
+
+var (
+	_ = bytes.NewReader
+	_ = binary.LittleEndian
+	_ = ioutil.ReadAll
+)
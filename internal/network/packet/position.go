@@ -0,0 +1,46 @@
+package packet
+
+// The cells are packed in 10 bits per coordinate, the direction in 4 bits.
+
+// EncodePosDir packs the cell (x, y) and the direction dir, as PosDir.
+func EncodePosDir(x, y int, dir uint8) [3]byte {
+	return [3]byte{
+		byte(x >> 2),
+		byte(x<<6) | byte(y>>4)&0x3f,
+		byte(y<<4) | dir&0x0f,
+	}
+}
+
+// DecodePosDir returns the cell and the direction packed in b, e.g. the
+// PosDir of ZC_NOTIFY_STANDENTRY.
+func DecodePosDir(b [3]byte) (x, y int, dir uint8) {
+	x = int(b[0])<<2 | int(b[1]>>6)
+	y = int(b[1]&0x3f)<<4 | int(b[2]>>4)
+
+	return x, y, b[2] & 0x0f
+}
+
+// EncodeMoveData packs a walk from the cell (x0, y0) to the cell (x1, y1),
+// as MoveData.
+func EncodeMoveData(x0, y0, x1, y1 int) [6]byte {
+	return [6]byte{
+		byte(x0 >> 2),
+		byte(x0<<6) | byte(y0>>4)&0x3f,
+		byte(y0<<4) | byte(x1>>6)&0x0f,
+		byte(x1<<2) | byte(y1>>8)&0x03,
+		byte(y1),
+		// The sub-cell offsets of the start, its center.
+		8<<4 | 8,
+	}
+}
+
+// DecodeMoveData returns the start and the destination cells of the walk
+// packed in b, e.g. the MoveData of ZC_NOTIFY_MOVE.
+func DecodeMoveData(b [6]byte) (x0, y0, x1, y1 int) {
+	x0 = int(b[0])<<2 | int(b[1]>>6)
+	y0 = int(b[1]&0x3f)<<4 | int(b[2]>>4)
+	x1 = int(b[2]&0x0f)<<6 | int(b[3]>>2)
+	y1 = int(b[3]&0x03)<<8 | int(b[4])
+
+	return x0, y0, x1, y1
+}
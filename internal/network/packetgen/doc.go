@@ -0,0 +1,80 @@
+package packetgen
+
+import (
+	"bytes"
+	"strings"
+)
+
+// GenerateDoc returns a Markdown reference of the packets used by packetVer,
+// with the offset of every field.
+func GenerateDoc(spec *Spec, packetVer int) ([]byte, error) {
+	packets, err := spec.Select(packetVer)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{spec: spec}
+
+	g.printf("<!-- Code generated by packetgen; DO NOT EDIT. -->\n\n")
+	g.printf("# Packets (PACKETVER %d)\n\n", packetVer)
+
+	for _, p := range packets {
+		g.printf("## 0x%04x %s\n\n", p.ID, p.Name)
+		if p.Doc != "" {
+			g.printf("%s\n\n", sentence(p.Doc))
+		}
+
+		offset := 2
+		if p.Variable {
+			g.printf("Length: variable\n\n")
+			offset += 2
+		} else {
+			g.printf("Length: %d\n\n", g.packetLength(p))
+		}
+
+		g.docFields(p.Fields, offset)
+	}
+
+	if len(spec.Structs) > 0 {
+		g.printf("# Structs\n\n")
+
+		for _, st := range spec.Structs {
+			g.printf("## %s\n\n", st.Name)
+			if st.Doc != "" {
+				g.printf("%s\n\n", sentence(st.Doc))
+			}
+
+			g.printf("Length: %d\n\n", g.fieldsLength(st.Fields))
+			g.docFields(st.Fields, 0)
+		}
+	}
+
+	return append(bytes.TrimRight(g.buf.Bytes(), "\n"), '\n'), nil
+}
+
+func (g *generator) docFields(fields []FieldSpec, offset int) {
+	if len(fields) == 0 {
+		return
+	}
+
+	g.printf("| Offset | Field | Type | Description |\n")
+	g.printf("|--------|-------|------|-------------|\n")
+
+	for _, f := range fields {
+		g.printf("| %d | %s | `%s` | %s |\n", offset, f.Name, f.Type, f.Doc)
+		offset += g.fieldLength(f.Type)
+	}
+
+	g.printf("\n")
+}
+
+// sentence capitalizes a doc written to follow the type name, as in
+// Go doc comments.
+func sentence(doc string) string {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return doc
+	}
+
+	return strings.ToUpper(doc[:1]) + doc[1:]
+}
@@ -0,0 +1,228 @@
+package packetgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Generate returns the Go source with the structs, the Encode/Decode
+// methods and the id lookup tables of the packets used by packetVer.
+func Generate(spec *Spec, packetVer int) ([]byte, error) {
+	packets, err := spec.Select(packetVer)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{spec: spec}
+	g.printf("// Code generated by packetgen; DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", spec.Package)
+	g.printf("import (\n\"bytes\"\n\"encoding/binary\"\n\"io/ioutil\"\n)\n\n")
+	g.printf("// PacketVer is the packet version the definitions were generated for.\n")
+	g.printf("const PacketVer = %d\n\n", packetVer)
+
+	for _, st := range spec.Structs {
+		g.genStruct(st)
+	}
+
+	for _, p := range packets {
+		g.genPacket(p)
+	}
+
+	g.printf("// Packets maps packet ids to their constructors.\n")
+	g.printf("var Packets = map[uint16]func() Packet{\n")
+	for _, p := range packets {
+		g.printf("0x%04x: func() Packet { return new(%s) },\n", p.ID, p.Name)
+	}
+	g.printf("}\n\n")
+
+	g.printf("// Lengths maps packet ids to their length, -1 for variable length packets.\n")
+	g.printf("var Lengths = map[uint16]int{\n")
+	for _, p := range packets {
+		g.printf("0x%04x: %d,\n", p.ID, g.packetLength(p))
+	}
+	g.printf("}\n")
+
+	// Silence the unused imports when no packet needs them.
+	g.printf("\nvar (\n_ = bytes.NewReader\n_ = binary.LittleEndian\n_ = ioutil.ReadAll\n)\n")
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "generated invalid code")
+	}
+
+	return src, nil
+}
+
+type generator struct {
+	spec *Spec
+	buf  bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) genDoc(name, doc string) {
+	if doc == "" {
+		return
+	}
+
+	for i, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		if i == 0 {
+			g.printf("// %s %s\n", name, line)
+		} else {
+			g.printf("// %s\n", line)
+		}
+	}
+}
+
+func (g *generator) genFields(fields []FieldSpec) {
+	for _, f := range fields {
+		if f.Doc != "" {
+			g.printf("// %s\n", f.Doc)
+		}
+		g.printf("%s %s\n", f.Name, g.goType(f.Type))
+	}
+}
+
+func (g *generator) genStruct(st StructSpec) {
+	g.genDoc(st.Name, st.Doc)
+	g.printf("type %s struct {\n", st.Name)
+	g.genFields(st.Fields)
+	g.printf("}\n\n")
+
+	g.printf("func (s *%s) encode(w *bytes.Buffer) {\n", st.Name)
+	for _, f := range st.Fields {
+		g.genEncodeField("s", f)
+	}
+	g.printf("}\n\n")
+
+	g.printf("func (s *%s) decode(r *bytes.Reader) (err error) {\n", st.Name)
+	for _, f := range st.Fields {
+		g.genDecodeField("s", f)
+	}
+	g.printf("return nil\n}\n\n")
+}
+
+func (g *generator) genPacket(p PacketSpec) {
+	if p.Doc == "" {
+		p.Doc = fmt.Sprintf("is the 0x%04x packet.", p.ID)
+	}
+	g.genDoc(p.Name, p.Doc)
+	g.printf("type %s struct {\n", p.Name)
+	g.genFields(p.Fields)
+	g.printf("}\n\n")
+
+	g.printf("func (p *%s) ID() uint16 {\nreturn 0x%04x\n}\n\n", p.Name, p.ID)
+
+	g.printf("func (p *%s) Encode() []byte {\n", p.Name)
+	g.printf("w := new(bytes.Buffer)\n")
+	g.printf("writeHeader(w, p.ID(), %t)\n", p.Variable)
+	for _, f := range p.Fields {
+		g.genEncodeField("p", f)
+	}
+	if p.Variable {
+		g.printf("return patchLength(w.Bytes())\n}\n\n")
+	} else {
+		g.printf("return w.Bytes()\n}\n\n")
+	}
+
+	g.printf("func (p *%s) Decode(data []byte) (err error) {\n", p.Name)
+	g.printf("r := bytes.NewReader(data)\n")
+	g.printf("if err = readHeader(r, p.ID(), %d); err != nil {\nreturn err\n}\n", g.packetLength(p))
+	for _, f := range p.Fields {
+		g.genDecodeField("p", f)
+	}
+	g.printf("return nil\n}\n\n")
+}
+
+func (g *generator) genEncodeField(recv string, f FieldSpec) {
+	kind, size, _, _ := g.spec.fieldKind(f.Type)
+	name := recv + "." + f.Name
+
+	switch kind {
+	case kindInteger, kindFixedBytes:
+		g.printf("_ = binary.Write(w, binary.LittleEndian, %s)\n", name)
+	case kindFixedString:
+		g.printf("writeFixedString(w, %s, %d)\n", name, size)
+	case kindString:
+		g.printf("w.WriteString(%s)\n", name)
+	case kindStruct:
+		g.printf("%s.encode(w)\n", name)
+	case kindSlice:
+		g.printf("for i := range %s {\n%s[i].encode(w)\n}\n", name, name)
+	}
+}
+
+func (g *generator) genDecodeField(recv string, f FieldSpec) {
+	kind, size, elem, _ := g.spec.fieldKind(f.Type)
+	name := recv + "." + f.Name
+	wrap := fmt.Sprintf("return decodeError(%q, err)", f.Name)
+
+	switch kind {
+	case kindInteger, kindFixedBytes:
+		g.printf("if err = binary.Read(r, binary.LittleEndian, &%s); err != nil {\n%s\n}\n", name, wrap)
+	case kindFixedString:
+		g.printf("if %s, err = readFixedString(r, %d); err != nil {\n%s\n}\n", name, size, wrap)
+	case kindString:
+		g.printf("rest, _ := ioutil.ReadAll(r)\n%s = string(trimString(rest))\n", name)
+	case kindStruct:
+		g.printf("if err = %s.decode(r); err != nil {\n%s\n}\n", name, wrap)
+	case kindSlice:
+		g.printf("%s = nil\n", name)
+		g.printf("for r.Len() > 0 {\nvar e %s\nif err = e.decode(r); err != nil {\n%s\n}\n%s = append(%s, e)\n}\n", elem, wrap, name, name)
+	}
+}
+
+func (g *generator) goType(t string) string {
+	kind, size, elem, _ := g.spec.fieldKind(t)
+
+	switch kind {
+	case kindFixedString, kindString:
+		return "string"
+	case kindFixedBytes:
+		return fmt.Sprintf("[%d]byte", size)
+	case kindSlice:
+		return "[]" + elem
+	default:
+		return t
+	}
+}
+
+// packetLength returns the full length of a packet, including its
+// header, or -1 when it has a variable length.
+func (g *generator) packetLength(p PacketSpec) int {
+	if p.Variable {
+		return -1
+	}
+
+	return 2 + g.fieldsLength(p.Fields)
+}
+
+func (g *generator) fieldsLength(fields []FieldSpec) (n int) {
+	for _, f := range fields {
+		n += g.fieldLength(f.Type)
+	}
+
+	return n
+}
+
+// fieldLength returns the encoded length of a fixed size type.
+func (g *generator) fieldLength(t string) int {
+	kind, size, elem, _ := g.spec.fieldKind(t)
+
+	switch kind {
+	case kindInteger:
+		return integerTypes[t]
+	case kindFixedString, kindFixedBytes:
+		return size
+	case kindStruct:
+		return g.fieldsLength(g.spec.structByName(elem).Fields)
+	default:
+		return 0
+	}
+}
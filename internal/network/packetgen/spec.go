@@ -0,0 +1,226 @@
+package packetgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the declarative description of a set of packets.
+//
+//	package: packet
+//	packetver: 20120410
+//	structs:
+//	  - name: CharacterInfo
+//	    fields:
+//	      - {name: GID, type: uint32}
+//	packets:
+//	  - name: CZ_REQUEST_MOVE
+//	    id: 0x0085
+//	    until: 20080102
+//	    fields:
+//	      - {name: Dest, type: "bytes:3"}
+type Spec struct {
+	Package   string       `yaml:"package"`
+	PacketVer int          `yaml:"packetver"`
+	Structs   []StructSpec `yaml:"structs"`
+	Packets   []PacketSpec `yaml:"packets"`
+}
+
+// StructSpec is a group of fields that can be embedded in packets.
+type StructSpec struct {
+	Name   string      `yaml:"name"`
+	Doc    string      `yaml:"doc"`
+	Fields []FieldSpec `yaml:"fields"`
+}
+
+// PacketSpec describes a single packet layout. A packet is used when
+// Since <= packetver < Until, zero values meaning unbounded.
+type PacketSpec struct {
+	Name     string      `yaml:"name"`
+	ID       uint16      `yaml:"id"`
+	Doc      string      `yaml:"doc"`
+	Since    int         `yaml:"since"`
+	Until    int         `yaml:"until"`
+	Variable bool        `yaml:"variable"`
+	Fields   []FieldSpec `yaml:"fields"`
+}
+
+// FieldSpec is a packet or struct field, named after an exported Go
+// identifier. Supported types are the sized integers (uint8...int64),
+// "string:N" and "bytes:N" for fixed length buffers, "string" and
+// "[]Struct" (last field of variable packets only) and struct names.
+type FieldSpec struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	Doc  string `yaml:"doc"`
+}
+
+var (
+	identifierRegexp = regexp.MustCompile(`^[A-Z][A-Za-z0-9_]*$`)
+	integerTypes     = map[string]int{
+		"uint8": 1, "int8": 1,
+		"uint16": 2, "int16": 2,
+		"uint32": 4, "int32": 4,
+		"uint64": 8, "int64": 8,
+	}
+)
+
+// ParseSpec reads a YAML spec and validates it.
+func ParseSpec(data []byte) (*Spec, error) {
+	spec := new(Spec)
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, errors.Wrap(err, "could not parse spec")
+	}
+
+	if spec.Package == "" {
+		return nil, errors.New("spec has no package name")
+	}
+
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// Select returns the packets in use for the given packetver, sorted by id.
+func (s *Spec) Select(packetVer int) ([]PacketSpec, error) {
+	var (
+		res   []PacketSpec
+		ids   = map[uint16]string{}
+		names = map[string]bool{}
+	)
+
+	for _, p := range s.Packets {
+		if (p.Since != 0 && packetVer < p.Since) || (p.Until != 0 && packetVer >= p.Until) {
+			continue
+		}
+
+		if other, ok := ids[p.ID]; ok {
+			return nil, fmt.Errorf("packets %s and %s share id 0x%04x at packetver %d", other, p.Name, p.ID, packetVer)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("packet %s is defined twice at packetver %d", p.Name, packetVer)
+		}
+
+		ids[p.ID] = p.Name
+		names[p.Name] = true
+		res = append(res, p)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+
+	return res, nil
+}
+
+func (s *Spec) structByName(name string) *StructSpec {
+	for i := range s.Structs {
+		if s.Structs[i].Name == name {
+			return &s.Structs[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) validate() error {
+	for _, st := range s.Structs {
+		if !identifierRegexp.MatchString(st.Name) {
+			return fmt.Errorf("invalid struct name '%s'", st.Name)
+		}
+
+		for i, f := range st.Fields {
+			if err := s.validateField(f, false, i == len(st.Fields)-1); err != nil {
+				return errors.Wrapf(err, "struct %s", st.Name)
+			}
+		}
+	}
+
+	for _, p := range s.Packets {
+		if !identifierRegexp.MatchString(p.Name) {
+			return fmt.Errorf("invalid packet name '%s'", p.Name)
+		}
+
+		for i, f := range p.Fields {
+			if err := s.validateField(f, p.Variable, i == len(p.Fields)-1); err != nil {
+				return errors.Wrapf(err, "packet %s", p.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) validateField(f FieldSpec, variable, last bool) error {
+	if !identifierRegexp.MatchString(f.Name) {
+		return fmt.Errorf("invalid field name '%s'", f.Name)
+	}
+
+	kind, _, _, err := s.fieldKind(f.Type)
+	if err != nil {
+		return errors.Wrapf(err, "field %s", f.Name)
+	}
+
+	if (kind == kindString || kind == kindSlice) && (!variable || !last) {
+		return fmt.Errorf("field %s: type '%s' is only allowed as the last field of variable packets", f.Name, f.Type)
+	}
+
+	return nil
+}
+
+type fieldKind int
+
+const (
+	kindInteger = fieldKind(iota)
+	kindFixedString
+	kindFixedBytes
+	kindString
+	kindSlice
+	kindStruct
+)
+
+// fieldKind returns the kind of a field type, its size for fixed length
+// buffers and the element struct name for slices and structs.
+func (s *Spec) fieldKind(t string) (kind fieldKind, size int, elem string, err error) {
+	if _, ok := integerTypes[t]; ok {
+		return kindInteger, 0, "", nil
+	}
+
+	if t == "string" {
+		return kindString, 0, "", nil
+	}
+
+	for prefix, k := range map[string]fieldKind{"string:": kindFixedString, "bytes:": kindFixedBytes} {
+		if strings.HasPrefix(t, prefix) {
+			n, err := strconv.Atoi(strings.TrimPrefix(t, prefix))
+			if err != nil || n <= 0 {
+				return 0, 0, "", fmt.Errorf("invalid buffer length in type '%s'", t)
+			}
+
+			return k, n, "", nil
+		}
+	}
+
+	if strings.HasPrefix(t, "[]") {
+		elem = strings.TrimPrefix(t, "[]")
+		if s.structByName(elem) == nil {
+			return 0, 0, "", fmt.Errorf("unknown struct '%s'", elem)
+		}
+
+		return kindSlice, 0, elem, nil
+	}
+
+	if s.structByName(t) != nil {
+		return kindStruct, 0, t, nil
+	}
+
+	return 0, 0, "", fmt.Errorf("unknown type '%s'", t)
+}
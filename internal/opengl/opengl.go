@@ -5,18 +5,21 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/go-gl/gl/v3.2-core/gl"
+	"github.com/project-midgard/midgarts/internal/gl"
 )
 
-func NewShader(vert string, frag string) *State {
+// ClearColor is the background color of the window.
+var ClearColor = [4]float32{0.3, 0.3, 0.5, 1.0}
+
+func NewShader(vert string, frag string) (*State, error) {
 	vertexShader, err := compileShader(vert, gl.VERTEX_SHADER)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	fragmentShader, err := compileShader(frag, gl.FRAGMENT_SHADER)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	prog := gl.CreateProgram()
@@ -24,6 +27,19 @@ func NewShader(vert string, frag string) *State {
 	gl.AttachShader(prog, fragmentShader)
 	gl.LinkProgram(prog)
 
+	var status int32
+	gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(prog, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(prog, logLength, nil, gl.Str(log))
+		gl.DeleteProgram(prog)
+
+		return nil, fmt.Errorf("failed to link program: %v", log)
+	}
+
 	gl.Enable(gl.BLEND)
 	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 	gl.LineWidth(2.0)
@@ -32,17 +48,17 @@ func NewShader(vert string, frag string) *State {
 	gl.Enable(gl.DEPTH_TEST)
 	gl.DepthFunc(gl.LEQUAL)
 
-	gl.ClearColor(0.3, 0.3, 0.5, 1.0)
+	gl.ClearColor(ClearColor[0], ClearColor[1], ClearColor[2], ClearColor[3])
 
 	return &State{
 		program: &Program{id: prog}, bufferCount: 0,
-	}
+	}, nil
 }
 
 func compileShader(source string, shaderType uint32) (uint32, error) {
 	shader := gl.CreateShader(shaderType)
 
-	csources, free := gl.Strs(source + "\x00")
+	csources, free := gl.Strs(gl.GLSL(source) + "\x00")
 	defer free()
 	gl.ShaderSource(shader, 1, csources, nil)
 	gl.CompileShader(shader)
@@ -1,9 +1,7 @@
 package opengl
 
 import (
-	"log"
-
-	"github.com/go-gl/gl/v3.2-core/gl"
+	"github.com/project-midgard/midgarts/internal/gl"
 )
 
 type Program struct {
@@ -14,9 +12,11 @@ func NewProgram() *Program {
 	return &Program{}
 }
 
+// GetAttribLocation returns the location of an attribute, or -1 when the
+// program is not loaded or has no such attribute, like OpenGL does.
 func (p *Program) GetAttribLocation(name string) int32 {
 	if p.id == 0 {
-		log.Fatalf("program '%v' not loaded", p.id)
+		return -1
 	}
 
 	return gl.GetAttribLocation(p.id, gl.Str(name+"\x00"))
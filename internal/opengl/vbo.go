@@ -1,9 +1,7 @@
 package opengl
 
 import (
-	"unsafe"
-
-	"github.com/go-gl/gl/v3.2-core/gl"
+	"github.com/project-midgard/midgarts/internal/gl"
 )
 
 type VBOAttributeType int
@@ -71,14 +69,28 @@ func (vbo *VBO) AddAttribute(t VBOAttributeType) *VBO {
 	return vbo
 }
 
+// Update makes the next Load upload the buffers again, after they
+// changed.
+func (vbo *VBO) Update() {
+	vbo.shouldUpdate = true
+}
+
+// Load uploads the buffers to the vertex array bound, when they changed
+// since the last Load (see Update): the vertex array keeps them
+// otherwise.
 func (vbo *VBO) Load(gls *State) {
 	if len(vbo.attributes) == 0 {
 		return
 	}
 
+	vbo.gls = gls
+	if !vbo.shouldUpdate {
+		return
+	}
+
 	if !vbo.buffAllocated {
 		for loc := range vbo.attributes {
-			gl.GenBuffers(int32(NumVertexAttributes), &vbo.handles[loc])
+			gl.GenBuffers(1, &vbo.handles[loc])
 		}
 		vbo.buffAllocated = true
 	}
@@ -94,14 +106,9 @@ func (vbo *VBO) Load(gls *State) {
 			attrib.ElementType,
 			false,
 			0,
-			unsafe.Pointer(uintptr(attrib.ByteOffset)),
+			gl.PtrOffset(int(attrib.ByteOffset)),
 		)
 	}
 
-	vbo.gls = gls
-	if !vbo.shouldUpdate {
-		return
-	}
-
 	vbo.shouldUpdate = false
 }
@@ -0,0 +1,247 @@
+package portrait
+
+import (
+	"context"
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/actionindex"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/pal"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+)
+
+// Config describes the character to render.
+type Config struct {
+	Gender      character.GenderType
+	JobSpriteID jobspriteid.Type
+	HeadIndex   character.HeadIndex
+	Direction   directiontype.Type
+	ActionIndex actionindex.Type
+	FrameIndex  int
+
+	// Palette indices from data/palette, 0 keeps the sprite palette.
+	HairPalette int
+	BodyPalette int
+
+	// Accessory names of the headgears, drawn in order after the head.
+	Headgears []string
+}
+
+// Portrait is a still image of a character. Origin is the position of
+// the character feet inside Image.
+type Portrait struct {
+	Image  *image.RGBA
+	Origin image.Point
+}
+
+type placedLayer struct {
+	img *image.RGBA
+	min image.Point
+}
+
+// Loader loads the files of the characters, as grf.File and grf.MultiFile
+// do. The IMF files are loaded too when it loads them (see
+// component.PriorityLoader).
+type Loader interface {
+	component.SpriteLoader
+	GetEntry(name string) (*grf.Entry, error)
+}
+
+// Render draws a single frame of a character, composing the body, head and
+// headgears the same way the render system anchors them.
+func Render(ctx context.Context, f Loader, conf Config) (*Portrait, error) {
+	cmp, err := component.NewCharacterAttachmentComponent(ctx, f, component.CharacterAttachmentComponentConfig{
+		Gender:      conf.Gender,
+		JobSpriteID: conf.JobSpriteID,
+		HeadIndex:   conf.HeadIndex,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body := cmp.Files[character.AttachmentBody]
+	head := cmp.Files[character.AttachmentHead]
+
+	if conf.BodyPalette != 0 {
+		if err = applyPalette(f, body.SPR, character.BodyPaletteFilePath(conf.Gender, conf.JobSpriteID, conf.BodyPalette)); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.HairPalette != 0 {
+		if err = applyPalette(f, head.SPR, character.HairPaletteFilePath(conf.Gender, conf.HeadIndex, conf.HairPalette)); err != nil {
+			return nil, err
+		}
+	}
+
+	attachments := []grf.ActionSpriteFilePair{body, head}
+	for _, name := range conf.Headgears {
+		files, err := f.GetSpriteFilesContext(ctx, character.HeadgearFilePath(conf.Gender, name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not load headgear '%s'", name)
+		}
+
+		attachments = append(attachments, files)
+	}
+
+	var (
+		layers []placedLayer
+		anchor [2]int32
+		bounds image.Rectangle
+	)
+
+	for i, files := range attachments {
+		frame := actionFrame(files.ACT, conf)
+		if frame == nil {
+			continue
+		}
+
+		// Everything but the body is anchored to the body anchor point.
+		var position [2]int32
+		if a, ok := frame.Anchor(); ok {
+			if i == 0 {
+				anchor = [2]int32{a.X, a.Y}
+			} else {
+				position = [2]int32{anchor[0] - a.X, anchor[1] - a.Y}
+			}
+		}
+
+		for _, layer := range frame.Layers {
+			img := LayerImage(files.SPR, layer)
+			if img == nil {
+				continue
+			}
+
+			size := img.Bounds().Size()
+			min := image.Point{
+				X: int(layer.Position[0]+position[0]) - size.X/2,
+				Y: int(layer.Position[1]+position[1]) - size.Y/2,
+			}
+
+			layers = append(layers, placedLayer{img: img, min: min})
+			bounds = bounds.Union(image.Rectangle{Min: min, Max: min.Add(size)})
+		}
+	}
+
+	if bounds.Empty() {
+		return nil, errors.New("character has no visible layers")
+	}
+
+	res := &Portrait{
+		Image:  image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy())),
+		Origin: image.Point{}.Sub(bounds.Min),
+	}
+
+	for _, l := range layers {
+		min := l.min.Add(res.Origin)
+		draw.Draw(res.Image, image.Rectangle{Min: min, Max: min.Add(l.img.Bounds().Size())}, l.img, image.Point{}, draw.Over)
+	}
+
+	return res, nil
+}
+
+func applyPalette(f Loader, sprFile *spr.SpriteFile, path string) error {
+	e, err := f.GetEntry(path)
+	if err != nil {
+		return errors.Wrap(err, "could not load palette")
+	}
+
+	p, err := pal.Load(e.Data)
+	if err != nil {
+		return errors.Wrapf(err, "could not load palette '%s'", path)
+	}
+
+	sprFile.ApplyPalette(p)
+	return nil
+}
+
+func actionFrame(actFile *act.ActionFile, conf Config) *act.ActionFrame {
+	if len(actFile.Actions) == 0 {
+		return nil
+	}
+
+	action := actFile.Actions[(int(conf.ActionIndex)+int(conf.Direction)%8)%len(actFile.Actions)]
+	if len(action.Frames) == 0 {
+		return nil
+	}
+
+	// Heads only have the three "doridori" frames when idle.
+	return action.Frames[conf.FrameIndex%len(action.Frames)]
+}
+
+// LayerImage returns the sprite image of a layer, mirrored, scaled and
+// rotated as the layer requests.
+func LayerImage(sprFile *spr.SpriteFile, layer *act.ActionFrameLayer) *image.RGBA {
+	if layer.SpriteFrameIndex < 0 || int(layer.SpriteFrameIndex) >= len(sprFile.Frames) {
+		return nil
+	}
+
+	src := sprFile.ImageAt(character.SpriteIndex(layer.SpriteFrameIndex))
+	if src == nil {
+		return nil
+	}
+
+	scaleX, scaleY := layer.Scale[0], layer.Scale[1]
+	if scaleX == 0 || scaleY == 0 {
+		scaleX, scaleY = 1, 1
+	}
+
+	b := src.Bounds()
+	w, h := int(float32(b.Dx())*scaleX), int(float32(b.Dy())*scaleY)
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx := int(float32(x) / scaleX)
+			if layer.Mirrored {
+				sx = b.Dx() - 1 - sx
+			}
+
+			img.Set(x, y, src.At(b.Min.X+sx, b.Min.Y+int(float32(y)/scaleY)))
+		}
+	}
+
+	return rotate(img, layer.Angle)
+}
+
+// rotate returns img turned clockwise by degrees around its center, as
+// the render system turns the layers, on an image fitting it.
+func rotate(img *image.RGBA, degrees int32) *image.RGBA {
+	if degrees%360 == 0 {
+		return img
+	}
+
+	sin, cos := math.Sincos(float64(degrees) * math.Pi / 180)
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	// Rounded first, so the quarter turns are not a pixel larger.
+	rw := int(math.Ceil(math.Round((math.Abs(w*cos)+math.Abs(h*sin))*1e6) / 1e6))
+	rh := int(math.Ceil(math.Round((math.Abs(w*sin)+math.Abs(h*cos))*1e6) / 1e6))
+
+	dst := image.NewRGBA(image.Rect(0, 0, rw, rh))
+	for y := 0; y < rh; y++ {
+		for x := 0; x < rw; x++ {
+			// The pixel of img turned onto the center of x, y.
+			dx, dy := float64(x)+0.5-float64(rw)/2, float64(y)+0.5-float64(rh)/2
+			sx := int(math.Floor(dx*cos + dy*sin + w/2))
+			sy := int(math.Floor(-dx*sin + dy*cos + h/2))
+			if sx >= 0 && sx < b.Dx() && sy >= 0 && sy < b.Dy() {
+				dst.SetRGBA(x, y, img.RGBAAt(b.Min.X+sx, b.Min.Y+sy))
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,16 @@
+package portrait
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+)
+
+func TestActionFrameSkipsEmptyActions(t *testing.T) {
+	assert.Nil(t, actionFrame(&act.ActionFile{}, Config{}))
+
+	actFile := &act.ActionFile{Actions: []*act.Action{{}}}
+	assert.Nil(t, actionFrame(actFile, Config{}))
+}
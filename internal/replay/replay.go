@@ -0,0 +1,155 @@
+// Package replay records the input and network events of a session, frame
+// by frame, and plays them back into the event bus. Played back with the
+// recorded frame durations, a recording reproduces the session, e.g. to
+// reproduce a gameplay bug or run a smoke test.
+//
+// A recording is a JSON stream of frames, each holding the frame duration
+// and the events published during the frame. Only the registered events
+// are recorded: the ones coming from outside the client, not the ones the
+// systems publish in reaction to them.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/event"
+)
+
+var types = map[string]reflect.Type{}
+
+func init() {
+	Register(event.KeyChanged{})
+	Register(event.MouseClicked{})
+	Register(event.Tapped{})
+	Register(event.Pinched{})
+	Register(event.GATOverlayToggled{})
+	Register(event.SnapshotRequested{})
+	Register(event.PacketReceived{})
+}
+
+// Register adds the type of e to the recorded events. The events must
+// survive a JSON round trip.
+func Register(e interface{}) {
+	typ := reflect.TypeOf(e)
+	types[typ.Name()] = typ
+}
+
+type frame struct {
+	DT     float32  `json:"dt"`
+	Events []record `json:"events,omitempty"`
+}
+
+type record struct {
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// Recorder writes the registered events published on a bus.
+type Recorder struct {
+	enc    *json.Encoder
+	frame  frame
+	unsubs []func()
+	err    error
+}
+
+func NewRecorder(w io.Writer, bus *event.Bus) *Recorder {
+	r := &Recorder{enc: json.NewEncoder(w)}
+
+	for name, typ := range types {
+		name := name
+		handler := reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{typ}, nil, false),
+			func(args []reflect.Value) []reflect.Value {
+				r.record(name, args[0].Interface())
+				return nil
+			},
+		)
+		r.unsubs = append(r.unsubs, bus.Subscribe(handler.Interface()))
+	}
+
+	return r
+}
+
+func (r *Recorder) record(name string, e interface{}) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		if r.err == nil {
+			r.err = errors.Wrapf(err, "could not record %s", name)
+		}
+		return
+	}
+
+	r.frame.Events = append(r.frame.Events, record{Type: name, Event: data})
+}
+
+// EndFrame writes the events recorded since the previous call, as a frame
+// lasting dt seconds.
+func (r *Recorder) EndFrame(dt float32) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.frame.DT = dt
+	if err := r.enc.Encode(r.frame); err != nil {
+		r.err = errors.Wrap(err, "could not write frame")
+		return r.err
+	}
+	r.frame = frame{}
+
+	return nil
+}
+
+// Close stops recording. The events of an unfinished frame are dropped.
+func (r *Recorder) Close() {
+	for _, unsubscribe := range r.unsubs {
+		unsubscribe()
+	}
+	r.unsubs = nil
+}
+
+// Player publishes the events of a recording.
+type Player struct {
+	dec   *json.Decoder
+	frame int
+}
+
+func NewPlayer(r io.Reader) *Player {
+	return &Player{dec: json.NewDecoder(r)}
+}
+
+// NextFrame publishes the events of the next frame on bus and returns its
+// duration. It returns io.EOF at the end of the recording.
+func (p *Player) NextFrame(bus *event.Bus) (dt float32, err error) {
+	var f frame
+	if err = p.dec.Decode(&f); err == io.EOF {
+		return 0, io.EOF
+	} else if err != nil {
+		return 0, errors.Wrapf(err, "could not read frame %d", p.frame)
+	}
+
+	events := make([]interface{}, 0, len(f.Events))
+	for _, rec := range f.Events {
+		typ, ok := types[rec.Type]
+		if !ok {
+			return 0, fmt.Errorf("unknown event '%s' in frame %d", rec.Type, p.frame)
+		}
+
+		e := reflect.New(typ)
+		if err = json.Unmarshal(rec.Event, e.Interface()); err != nil {
+			return 0, errors.Wrapf(err, "invalid %s in frame %d", rec.Type, p.frame)
+		}
+		events = append(events, e.Elem().Interface())
+	}
+	p.frame++
+
+	for _, e := range events {
+		bus.Publish(e)
+	}
+
+	return f.DT, nil
+}
@@ -0,0 +1,64 @@
+package replay
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+)
+
+func TestRecordPlay(t *testing.T) {
+	var buf bytes.Buffer
+
+	bus := event.NewBus()
+	rec := NewRecorder(&buf, bus)
+
+	bus.Publish(event.KeyChanged{Key: 'w', Pressed: true})
+	bus.Publish(event.GroundClicked{X: 1, Y: 2})
+	require.NoError(t, rec.EndFrame(0.016))
+	require.NoError(t, rec.EndFrame(0.017))
+	bus.Publish(event.MouseClicked{X: 10, Y: 20})
+	bus.Publish(event.PacketReceived{Packet: &packet.CA_LOGIN{Username: "midgarts"}})
+	require.NoError(t, rec.EndFrame(0.018))
+	rec.Close()
+	bus.Publish(event.GATOverlayToggled{})
+
+	played := event.NewBus()
+	var got []interface{}
+	played.Subscribe(func(e event.KeyChanged) { got = append(got, e) })
+	played.Subscribe(func(e event.MouseClicked) { got = append(got, e) })
+	played.Subscribe(func(e event.GroundClicked) { got = append(got, e) })
+	played.Subscribe(func(e event.GATOverlayToggled) { got = append(got, e) })
+	played.Subscribe(func(e event.PacketReceived) { got = append(got, e.Packet) })
+
+	p := NewPlayer(&buf)
+	var dts []float32
+	for {
+		dt, err := p.NextFrame(played)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		dts = append(dts, dt)
+	}
+
+	assert.Equal(t, []float32{0.016, 0.017, 0.018}, dts)
+	assert.Equal(t, []interface{}{
+		event.KeyChanged{Key: 'w', Pressed: true},
+		event.MouseClicked{X: 10, Y: 20},
+		&packet.CA_LOGIN{Username: "midgarts"},
+	}, got)
+}
+
+func TestPlayUnknownEvent(t *testing.T) {
+	p := NewPlayer(strings.NewReader(`{"dt": 0.016, "events": [{"type": "Teleported", "event": {}}]}`))
+
+	_, err := p.NextFrame(event.NewBus())
+	assert.EqualError(t, err, "unknown event 'Teleported' in frame 0")
+}
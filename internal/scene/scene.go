@@ -0,0 +1,188 @@
+// Package scene switches between the states of the client, as the maps,
+// each setting up and tearing down its own systems. A switch fades the current scene out, shows the loading scene
+// while the assets of the next one are preloaded, then fades the next
+// scene in.
+package scene
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/asset"
+	"github.com/project-midgard/midgarts/internal/logging"
+)
+
+// DefaultFadeDuration is the duration of the fade out, and of the fade in.
+const DefaultFadeDuration = 300 * time.Millisecond
+
+type Scene interface {
+	Name() string
+	// Enter sets the scene up, once its assets are preloaded.
+	Enter(ctx context.Context) error
+	Update(dt float32)
+	// Exit tears the scene down.
+	Exit()
+}
+
+// Preloader is implemented by scenes requesting their assets before they
+// are entered.
+type Preloader interface {
+	Preload(assets *asset.Manager)
+	// Release releases the assets preloaded, when the scene is replaced
+	// before it is entered. An entered scene releases them on Exit.
+	Release()
+}
+
+// Fader is implemented by scenes drawing the transitions: fade goes from 0
+// (the scene is fully visible) to 1 (the screen is black).
+type Fader interface {
+	SetFade(fade float32)
+}
+
+type phase int
+
+const (
+	idle phase = iota
+	fadingOut
+	loading
+	fadingIn
+)
+
+// Manager runs the current scene and the transitions between scenes. It
+// must be updated every frame, on the main thread.
+type Manager struct {
+	FadeDuration time.Duration
+
+	ctx     context.Context
+	log     zerolog.Logger
+	assets  *asset.Manager
+	loading Scene
+
+	current, next Scene
+	phase         phase
+	fade          float32
+}
+
+// NewManager returns a manager showing the loading scene, which may be
+// nil, while the assets of the scenes are preloaded. ctx is given to the
+// entered scenes and carries the logger (see logging.For).
+func NewManager(ctx context.Context, assets *asset.Manager, loading Scene) *Manager {
+	return &Manager{
+		FadeDuration: DefaultFadeDuration,
+		ctx:          ctx,
+		log:          logging.For(ctx, logging.Scene),
+		assets:       assets,
+		loading:      loading,
+	}
+}
+
+// Current returns the running scene, nil before the first switch or when
+// the last scene failed to enter.
+func (m *Manager) Current() Scene {
+	return m.current
+}
+
+// Fade returns the transition progress, 0 when no transition runs.
+func (m *Manager) Fade() float32 {
+	return m.fade
+}
+
+// Switch starts the transition to next. A switch requested while the
+// current scene fades out or loads replaces its next scene, whose assets
+// are released.
+func (m *Manager) Switch(next Scene) {
+	previous := m.next
+	m.next = next
+
+	switch m.phase {
+	case idle, fadingIn:
+		m.phase = fadingOut
+	case loading:
+		if p, ok := next.(Preloader); ok && m.assets != nil {
+			p.Preload(m.assets)
+		}
+		// Released once the assets of next are requested, so the ones
+		// they share are not unloaded in between.
+		if p, ok := previous.(Preloader); ok && m.assets != nil && previous != next {
+			p.Release()
+		}
+	}
+}
+
+func (m *Manager) Update(dt float32) {
+	step := float32(1)
+	if m.FadeDuration > 0 {
+		step = dt / float32(m.FadeDuration.Seconds())
+	}
+
+	switch m.phase {
+	case fadingOut:
+		m.fade += step
+		if m.fade >= 1 || m.current == nil {
+			m.fade = 1
+			m.exitCurrent()
+			m.preloadNext()
+		}
+	case loading:
+		if m.assets == nil || m.assets.Progress().Complete() {
+			m.exitCurrent()
+			m.enterNext()
+		}
+	case fadingIn:
+		m.fade -= step
+		if m.fade <= 0 {
+			m.fade, m.phase = 0, idle
+		}
+	}
+
+	if m.current == nil {
+		return
+	}
+
+	if f, ok := m.current.(Fader); ok {
+		f.SetFade(m.fade)
+	}
+	m.current.Update(dt)
+}
+
+func (m *Manager) exitCurrent() {
+	if m.current != nil {
+		m.log.Debug().Str("scene", m.current.Name()).Msg("exiting scene")
+		m.current.Exit()
+		m.current = nil
+	}
+}
+
+func (m *Manager) preloadNext() {
+	p, ok := m.next.(Preloader)
+	if !ok || m.assets == nil {
+		m.enterNext()
+		return
+	}
+
+	p.Preload(m.assets)
+	m.phase = loading
+
+	if m.loading != nil {
+		if err := m.loading.Enter(m.ctx); err != nil {
+			m.log.Error().Err(err).Msg("could not enter the loading scene")
+			return
+		}
+		m.current = m.loading
+	}
+}
+
+func (m *Manager) enterNext() {
+	next := m.next
+	m.next, m.phase = nil, fadingIn
+
+	m.log.Debug().Str("scene", next.Name()).Msg("entering scene")
+	if err := next.Enter(m.ctx); err != nil {
+		m.log.Error().Err(err).Str("scene", next.Name()).Msg("could not enter scene")
+		return
+	}
+
+	m.current = next
+}
@@ -0,0 +1,169 @@
+package scene
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/asset"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+// recorder is a scene recording its calls in a shared log.
+type recorder struct {
+	name     string
+	log      *[]string
+	enterErr error
+	preload  []string
+	fade     float32
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Enter(ctx context.Context) error {
+	*r.log = append(*r.log, r.name+" enter")
+	return r.enterErr
+}
+
+func (r *recorder) Update(dt float32) {}
+
+func (r *recorder) Exit() {
+	*r.log = append(*r.log, r.name+" exit")
+}
+
+func (r *recorder) SetFade(fade float32) {
+	r.fade = fade
+}
+
+// preloading is a recorder preloading assets.
+type preloading struct {
+	*recorder
+}
+
+func (p preloading) Preload(assets *asset.Manager) {
+	*p.log = append(*p.log, p.name+" preload")
+	assets.Preload(p.preload...)
+}
+
+func (p preloading) Release() {
+	*p.log = append(*p.log, p.name+" release")
+}
+
+type loaderFunc func(ctx context.Context, name string) (grf.ActionSpriteFilePair, error)
+
+func (f loaderFunc) GetSpriteFilesContext(ctx context.Context, name string) (grf.ActionSpriteFilePair, error) {
+	return f(ctx, name)
+}
+
+// GetEntryContext finds no file: the fake only loads sprites.
+func (f loaderFunc) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	return nil, os.ErrNotExist
+}
+
+func (f loaderFunc) OverlayPaths(name string) []string {
+	return nil
+}
+
+func newAssets(t *testing.T) *asset.Manager {
+	assets := asset.NewManager(context.Background(), loaderFunc(func(ctx context.Context, name string) (grf.ActionSpriteFilePair, error) {
+		return grf.ActionSpriteFilePair{ACT: &act.ActionFile{}}, nil
+	}), nil, 1)
+	t.Cleanup(assets.Close)
+
+	return assets
+}
+
+func TestManager(t *testing.T) {
+	var log []string
+	assets := newAssets(t)
+
+	loadingScene := &recorder{name: "loading", log: &log}
+	login := &recorder{name: "login", log: &log}
+	game := preloading{&recorder{name: "map", log: &log, preload: []string{"data/sprite/shadow"}}}
+
+	m := NewManager(context.Background(), assets, loadingScene)
+	m.FadeDuration = time.Second
+
+	// There is nothing to fade out at first, the scene fades in.
+	m.Switch(login)
+	m.Update(0.1)
+	assert.Equal(t, login, m.Current())
+	assert.Equal(t, float32(1), m.Fade())
+
+	m.Update(0.25)
+	assert.Equal(t, float32(0.75), login.fade)
+
+	m.Update(1)
+	assert.Equal(t, float32(0), m.Fade())
+
+	m.Switch(game)
+	m.Update(0.5)
+	assert.Equal(t, float32(0.5), login.fade)
+	m.Update(0.5)
+	assert.Equal(t, loadingScene, m.Current())
+
+	// The loading scene stays until the assets are ready.
+	for i := 0; i < 100 && m.Current() == loadingScene; i++ {
+		time.Sleep(time.Millisecond)
+		assets.Update(0)
+		m.Update(0)
+	}
+	assert.Equal(t, game, m.Current())
+
+	assert.Equal(t, []string{
+		"login enter",
+		"login exit",
+		"map preload",
+		"loading enter",
+		"loading exit",
+		"map enter",
+	}, log)
+}
+
+func TestManagerSwitchWhileLoading(t *testing.T) {
+	var log []string
+	assets := newAssets(t)
+
+	loadingScene := &recorder{name: "loading", log: &log}
+	prontera := preloading{&recorder{name: "prontera", log: &log, preload: []string{"data/sprite/shadow"}}}
+	geffen := preloading{&recorder{name: "geffen", log: &log, preload: []string{"data/sprite/shadow"}}}
+
+	m := NewManager(context.Background(), assets, loadingScene)
+	m.Switch(prontera)
+	m.Update(0.1)
+	assert.Equal(t, loadingScene, m.Current())
+
+	// The map is replaced before it is entered: its assets are released.
+	m.Switch(geffen)
+	for i := 0; i < 100 && m.Current() == loadingScene; i++ {
+		time.Sleep(time.Millisecond)
+		assets.Update(0)
+		m.Update(0)
+	}
+	assert.Equal(t, geffen, m.Current())
+
+	assert.Equal(t, []string{
+		"prontera preload",
+		"loading enter",
+		"geffen preload",
+		"prontera release",
+		"loading exit",
+		"geffen enter",
+	}, log)
+}
+
+func TestManagerEnterError(t *testing.T) {
+	var log []string
+
+	m := NewManager(context.Background(), nil, nil)
+	m.Switch(&recorder{name: "broken", log: &log, enterErr: errors.New("no map")})
+	m.Update(0.1)
+
+	assert.Nil(t, m.Current())
+	assert.Equal(t, []string{"broken enter"}, log)
+}
@@ -0,0 +1,348 @@
+// Package script runs Lua scripts driving the client: demo scenes,
+// cinematics or the custom behaviors of a server. Scripts only see a
+// sandboxed Lua (no io, os, require nor file loading) and the midgarts
+// module:
+//
+//	midgarts.spawn{job = "Knight", gender = "m", head = 23, x = 0, y = 44, z = 0, shield = true, scale = 1} -- returns the entity id
+//	midgarts.walk(id, x1, y1 [, x2, y2, ...]) -- walks through the positions
+//	midgarts.effect(name, x, y, z) -- e.g. "firewall", see internal/effect
+//	midgarts.state(id, state) -- e.g. "Attacking", see statetype
+//	midgarts.say(id, text [, seconds]) -- shows text above the character, 3 seconds by default
+//	midgarts.camera(x, y, z)
+//	midgarts.fade(fade) -- darkens the frame, from 0 to 1 (black)
+//	midgarts.weather(kind [, intensity]) -- "rain", "snow", "clouds" or "none", see internal/weather
+//	midgarts.dialog(text [, title])
+//	midgarts.at(seconds, fn) -- calls fn that many seconds after the script ran
+//	midgarts.log(message)
+//
+// A script defining a global on_frame(dt) function has it called every
+// frame. The functions given to at make the timeline of a cinematic (see
+// also internal/cinematic).
+package script
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/internal/weather"
+)
+
+const (
+	// ModuleName is the global table of the API.
+	ModuleName = "midgarts"
+	// FrameFunction is the global function called every frame.
+	FrameFunction = "on_frame"
+
+	// DefaultSayDuration is the time the text said by a character shows.
+	DefaultSayDuration = 3 * time.Second
+)
+
+// Character describes a character spawned by a script.
+type Character struct {
+	Gender      character.GenderType
+	JobSpriteID jobspriteid.Type
+	HeadIndex   character.HeadIndex
+	HasShield   bool
+	Position    mgl32.Vec3
+	// Scale is the size of the sprites, 1 by default.
+	Scale float32
+}
+
+// API is the part of the client scripts drive.
+type API interface {
+	// Spawn adds a character to the world, returning its entity id.
+	Spawn(c Character) (uint64, error)
+	// Walk makes the character of id walk from its position through the
+	// world positions of points.
+	Walk(id uint64, points []mgl32.Vec2) error
+	PlayEffect(name string, position mgl32.Vec3) error
+	// SetState sets the state of the character of id, playing the
+	// animation of the state.
+	SetState(id uint64, state statetype.Type) error
+	// Say shows text above the character of id for d.
+	Say(id uint64, text string, d time.Duration) error
+	MoveCamera(position mgl32.Vec3)
+	CameraPosition() mgl32.Vec3
+	// SetFade darkens the frame, from 0 (unchanged) to 1 (black).
+	SetFade(fade float32)
+	SetWeather(w weather.Weather)
+	ShowDialog(title, text string)
+}
+
+// Engine is a Lua VM bound to an API. It is also a system, calling the
+// FrameFunction of the scripts every update; it must be used from a single
+// goroutine.
+type Engine struct {
+	state *lua.LState
+	api   API
+	log   zerolog.Logger
+
+	// elapsed is the time since the engine started, and timers the
+	// functions given to at not called yet, the earliest first.
+	elapsed time.Duration
+	timers  []timer
+}
+
+type timer struct {
+	at time.Duration
+	fn *lua.LFunction
+}
+
+// NewEngine returns an engine; ctx stops the running scripts when done and
+// carries its logger (see logging.For).
+func NewEngine(ctx context.Context, api API) *Engine {
+	e := &Engine{
+		state: lua.NewState(lua.Options{SkipOpenLibs: true}),
+		api:   api,
+		log:   logging.For(ctx, logging.Script),
+	}
+	e.state.SetContext(ctx)
+
+	e.openSafeLibs()
+	e.state.SetGlobal(ModuleName, e.state.SetFuncs(e.state.NewTable(), map[string]lua.LGFunction{
+		"spawn":   e.spawn,
+		"walk":    e.walk,
+		"effect":  e.effect,
+		"state":   e.setState,
+		"say":     e.say,
+		"camera":  e.camera,
+		"fade":    e.fade,
+		"weather": e.weather,
+		"dialog":  e.dialog,
+		"at":      e.at,
+		"log":     e.print,
+	}))
+
+	return e
+}
+
+// openSafeLibs opens the libraries that cannot reach the file system nor
+// the process.
+func (e *Engine) openSafeLibs() {
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		e.state.Push(e.state.NewFunction(lib.open))
+		e.state.Push(lua.LString(lib.name))
+		e.state.Call(1, 0)
+	}
+
+	for _, name := range []string{"dofile", "loadfile", "require", "module"} {
+		e.state.SetGlobal(name, lua.LNil)
+	}
+
+	e.state.SetGlobal("print", e.state.NewFunction(e.print))
+}
+
+// RunString runs a script; name identifies it in the errors.
+func (e *Engine) RunString(name, source string) error {
+	fn, err := e.state.Load(strings.NewReader(source), name)
+	if err != nil {
+		return errors.Wrapf(err, "could not load script '%s'", name)
+	}
+
+	e.state.Push(fn)
+	if err = e.state.PCall(0, lua.MultRet, nil); err != nil {
+		return errors.Wrapf(err, "could not run script '%s'", name)
+	}
+
+	return nil
+}
+
+// Update calls the functions given to at that are due, then the
+// FrameFunction, if any. A failing FrameFunction is removed, not to fail
+// again every frame.
+func (e *Engine) Update(dt float32) {
+	e.elapsed += time.Duration(float64(dt) * float64(time.Second))
+	for len(e.timers) > 0 && e.timers[0].at <= e.elapsed {
+		t := e.timers[0]
+		e.timers = e.timers[1:]
+		if err := e.state.CallByParam(lua.P{Fn: t.fn, Protect: true}); err != nil {
+			e.log.Error().Err(err).Msgf("function at %s failed", t.at)
+		}
+	}
+
+	fn, ok := e.state.GetGlobal(FrameFunction).(*lua.LFunction)
+	if !ok {
+		return
+	}
+
+	if err := e.state.CallByParam(lua.P{Fn: fn, Protect: true}, lua.LNumber(dt)); err != nil {
+		e.log.Error().Err(err).Msgf("%s failed, it is not called anymore", FrameFunction)
+		e.state.SetGlobal(FrameFunction, lua.LNil)
+	}
+}
+
+func (e *Engine) Remove(ecs.BasicEntity) {}
+
+func (e *Engine) Close() {
+	e.state.Close()
+}
+
+func (e *Engine) spawn(L *lua.LState) int {
+	t := L.CheckTable(1)
+
+	c := Character{
+		HeadIndex: character.HeadIndex(lua.LVAsNumber(t.RawGetString("head"))),
+		HasShield: lua.LVAsBool(t.RawGetString("shield")),
+		Position:  vec3(t.RawGetString("x"), t.RawGetString("y"), t.RawGetString("z")),
+		Scale:     1,
+	}
+	if scale := t.RawGetString("scale"); scale != lua.LNil {
+		c.Scale = float32(lua.LVAsNumber(scale))
+	}
+
+	switch gender := lua.LVAsString(t.RawGetString("gender")); gender {
+	case "", "m", "male":
+		c.Gender = character.Male
+	case "f", "female":
+		c.Gender = character.Female
+	default:
+		L.ArgError(1, fmt.Sprintf("unknown gender '%s'", gender))
+	}
+
+	job, err := jobspriteid.Parse(lua.LVAsString(t.RawGetString("job")))
+	if err != nil {
+		L.ArgError(1, err.Error())
+	}
+	c.JobSpriteID = job
+
+	id, err := e.api.Spawn(c)
+	if err != nil {
+		L.RaiseError("could not spawn character: %v", err)
+	}
+
+	L.Push(lua.LNumber(id))
+	return 1
+}
+
+func (e *Engine) walk(L *lua.LState) int {
+	id := uint64(L.CheckNumber(1))
+
+	var points []mgl32.Vec2
+	for i := 2; i <= L.GetTop(); i += 2 {
+		points = append(points, mgl32.Vec2{float32(L.CheckNumber(i)), float32(L.CheckNumber(i + 1))})
+	}
+	if len(points) == 0 {
+		L.ArgError(2, "no position to walk to")
+	}
+
+	if err := e.api.Walk(id, points); err != nil {
+		L.RaiseError("could not walk: %v", err)
+	}
+
+	return 0
+}
+
+func (e *Engine) effect(L *lua.LState) int {
+	if err := e.api.PlayEffect(L.CheckString(1), vec3(L.Get(2), L.Get(3), L.Get(4))); err != nil {
+		L.RaiseError("could not play effect: %v", err)
+	}
+
+	return 0
+}
+
+func (e *Engine) setState(L *lua.LState) int {
+	id := uint64(L.CheckNumber(1))
+	state, err := statetype.Parse(L.CheckString(2))
+	if err != nil {
+		L.ArgError(2, err.Error())
+	}
+
+	if err = e.api.SetState(id, state); err != nil {
+		L.RaiseError("could not set state: %v", err)
+	}
+
+	return 0
+}
+
+func (e *Engine) say(L *lua.LState) int {
+	d := DefaultSayDuration
+	if L.GetTop() >= 3 {
+		d = seconds(L.CheckNumber(3))
+	}
+
+	if err := e.api.Say(uint64(L.CheckNumber(1)), L.CheckString(2), d); err != nil {
+		L.RaiseError("could not say: %v", err)
+	}
+
+	return 0
+}
+
+func (e *Engine) camera(L *lua.LState) int {
+	e.api.MoveCamera(vec3(L.CheckNumber(1), L.CheckNumber(2), L.CheckNumber(3)))
+	return 0
+}
+
+func (e *Engine) fade(L *lua.LState) int {
+	e.api.SetFade(float32(L.CheckNumber(1)))
+	return 0
+}
+
+func (e *Engine) weather(L *lua.LState) int {
+	kind, err := weather.Parse(L.CheckString(1))
+	if err != nil {
+		L.ArgError(1, err.Error())
+	}
+
+	e.api.SetWeather(weather.Weather{Kind: kind, Intensity: float32(L.OptNumber(2, 1))})
+	return 0
+}
+
+func (e *Engine) at(L *lua.LState) int {
+	t := timer{at: e.elapsed + seconds(L.CheckNumber(1)), fn: L.CheckFunction(2)}
+
+	// After the timers of the same time, in the order they were given.
+	i := sort.Search(len(e.timers), func(i int) bool { return e.timers[i].at > t.at })
+	e.timers = append(e.timers, timer{})
+	copy(e.timers[i+1:], e.timers[i:])
+	e.timers[i] = t
+
+	return 0
+}
+
+func (e *Engine) dialog(L *lua.LState) int {
+	e.api.ShowDialog(L.OptString(2, ""), L.CheckString(1))
+	return 0
+}
+
+func (e *Engine) print(L *lua.LState) int {
+	args := make([]string, L.GetTop())
+	for i := range args {
+		args[i] = L.ToStringMeta(L.Get(i + 1)).String()
+	}
+
+	e.log.Info().Msg(strings.Join(args, "\t"))
+	return 0
+}
+
+func seconds(n lua.LNumber) time.Duration {
+	return time.Duration(float64(n) * float64(time.Second))
+}
+
+func vec3(x, y, z lua.LValue) mgl32.Vec3 {
+	return mgl32.Vec3{
+		float32(lua.LVAsNumber(x)),
+		float32(lua.LVAsNumber(y)),
+		float32(lua.LVAsNumber(z)),
+	}
+}
@@ -0,0 +1,210 @@
+package script
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/weather"
+)
+
+// fakeAPI records the calls of the scripts.
+type fakeAPI struct {
+	spawned []Character
+	walks   map[uint64][]mgl32.Vec2
+	effects []string
+	states  map[uint64]statetype.Type
+	said    []string
+	camera  mgl32.Vec3
+	fade    float32
+	weather weather.Weather
+	dialogs [][2]string
+}
+
+func (a *fakeAPI) Spawn(c Character) (uint64, error) {
+	a.spawned = append(a.spawned, c)
+	return uint64(len(a.spawned)), nil
+}
+
+func (a *fakeAPI) Walk(id uint64, points []mgl32.Vec2) error {
+	if id > uint64(len(a.spawned)) {
+		return errors.New("unknown character")
+	}
+	if a.walks == nil {
+		a.walks = make(map[uint64][]mgl32.Vec2)
+	}
+	a.walks[id] = points
+	return nil
+}
+
+func (a *fakeAPI) PlayEffect(name string, position mgl32.Vec3) error {
+	if name == "missing" {
+		return errors.New("unknown effect")
+	}
+	a.effects = append(a.effects, name)
+	return nil
+}
+
+func (a *fakeAPI) SetState(id uint64, state statetype.Type) error {
+	if a.states == nil {
+		a.states = make(map[uint64]statetype.Type)
+	}
+	a.states[id] = state
+	return nil
+}
+
+func (a *fakeAPI) Say(id uint64, text string, d time.Duration) error {
+	a.said = append(a.said, text+" "+d.String())
+	return nil
+}
+
+func (a *fakeAPI) MoveCamera(position mgl32.Vec3) {
+	a.camera = position
+}
+
+func (a *fakeAPI) CameraPosition() mgl32.Vec3 {
+	return a.camera
+}
+
+func (a *fakeAPI) SetFade(fade float32) {
+	a.fade = fade
+}
+
+func (a *fakeAPI) SetWeather(w weather.Weather) {
+	a.weather = w
+}
+
+func (a *fakeAPI) ShowDialog(title, text string) {
+	a.dialogs = append(a.dialogs, [2]string{title, text})
+}
+
+func TestEngine(t *testing.T) {
+	api := &fakeAPI{}
+	e := NewEngine(context.Background(), api)
+	defer e.Close()
+
+	err := e.RunString("scene", `
+		local id = midgarts.spawn{job = "knight", gender = "f", head = 23, x = 1, y = 44, z = -1, shield = true, scale = 1.5}
+		midgarts.walk(id, 2, 44, 2, 46)
+		midgarts.effect("firewall", 0, 0, 0)
+		midgarts.state(id, "attacking")
+		midgarts.say(id, "Hi")
+		midgarts.say(id, "Bye", 1.5)
+		midgarts.camera(1, 2, 3)
+		midgarts.fade(0.5)
+		midgarts.weather("snow", 0.5)
+		midgarts.dialog("Welcome to Izlude", "Guide")
+		midgarts.log("spawned", id)
+	`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Character{{
+		Gender:      character.Female,
+		JobSpriteID: jobspriteid.Knight,
+		HeadIndex:   23,
+		HasShield:   true,
+		Position:    mgl32.Vec3{1, 44, -1},
+		Scale:       1.5,
+	}}, api.spawned)
+	assert.Equal(t, map[uint64][]mgl32.Vec2{1: {{2, 44}, {2, 46}}}, api.walks)
+	assert.Equal(t, []string{"firewall"}, api.effects)
+	assert.Equal(t, map[uint64]statetype.Type{1: statetype.Attacking}, api.states)
+	assert.Equal(t, []string{"Hi 3s", "Bye 1.5s"}, api.said)
+	assert.Equal(t, mgl32.Vec3{1, 2, 3}, api.camera)
+	assert.Equal(t, float32(0.5), api.fade)
+	assert.Equal(t, weather.Weather{Kind: weather.Snow, Intensity: 0.5}, api.weather)
+	assert.Equal(t, [][2]string{{"Guide", "Welcome to Izlude"}}, api.dialogs)
+}
+
+func TestEngineErrors(t *testing.T) {
+	e := NewEngine(context.Background(), &fakeAPI{})
+	defer e.Close()
+
+	assert.Error(t, e.RunString("syntax", `midgarts.camera(`))
+	assert.Error(t, e.RunString("job", `midgarts.spawn{job = "Chef"}`))
+	assert.Error(t, e.RunString("effect", `midgarts.effect("missing")`))
+	assert.Error(t, e.RunString("walk", `midgarts.walk(1)`))
+	assert.Error(t, e.RunString("walk odd", `midgarts.walk(1, 2, 3, 4)`))
+	assert.Error(t, e.RunString("walk unknown", `midgarts.walk(7, 2, 3)`))
+	assert.Error(t, e.RunString("state", `midgarts.state(1, "Dancing")`))
+	assert.Error(t, e.RunString("weather", `midgarts.weather("hail")`))
+}
+
+func TestEngineSandbox(t *testing.T) {
+	e := NewEngine(context.Background(), &fakeAPI{})
+	defer e.Close()
+
+	for _, global := range []string{"io", "os", "require", "dofile", "loadfile", "debug"} {
+		assert.NoError(t, e.RunString(global, `assert(`+global+` == nil)`), global)
+	}
+}
+
+func TestEngineUpdate(t *testing.T) {
+	api := &fakeAPI{}
+	e := NewEngine(context.Background(), api)
+	defer e.Close()
+
+	require.NoError(t, e.RunString("cinematic", `
+		local elapsed = 0
+		function on_frame(dt)
+			elapsed = elapsed + dt
+			midgarts.camera(elapsed, 0, 0)
+			if elapsed > 1 then
+				error("done")
+			end
+		end
+	`))
+
+	e.Update(0.5)
+	e.Update(0.5)
+	assert.Equal(t, mgl32.Vec3{1, 0, 0}, api.camera)
+
+	// on_frame fails and is not called anymore.
+	e.Update(0.5)
+	e.Update(0.5)
+	assert.Equal(t, mgl32.Vec3{1.5, 0, 0}, api.camera)
+}
+
+func TestEngineAt(t *testing.T) {
+	api := &fakeAPI{}
+	e := NewEngine(context.Background(), api)
+	defer e.Close()
+
+	require.NoError(t, e.RunString("timeline", `
+		midgarts.at(1, function() midgarts.camera(1, 0, 0) end)
+		midgarts.at(0.5, function()
+			midgarts.camera(0.5, 0, 0)
+			midgarts.at(1, function() midgarts.fade(1) end)
+		end)
+		midgarts.at(1, function() midgarts.camera(2, 0, 0) end)
+		midgarts.at(0.2, function() error("failing") end)
+	`))
+
+	e.Update(0.4)
+	assert.Equal(t, mgl32.Vec3{}, api.camera)
+	e.Update(0.2)
+	assert.Equal(t, mgl32.Vec3{0.5, 0, 0}, api.camera)
+	// The functions of the same time run in order.
+	e.Update(0.5)
+	assert.Equal(t, mgl32.Vec3{2, 0, 0}, api.camera)
+	assert.Equal(t, float32(0), api.fade)
+	e.Update(0.5)
+	assert.Equal(t, float32(1), api.fade)
+}
+
+func TestEngineCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := NewEngine(ctx, &fakeAPI{})
+	defer e.Close()
+
+	cancel()
+	assert.Error(t, e.RunString("loop", `while true do end`))
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/pkg/errors"
+)
+
+// Parallel is a system updating its systems concurrently, each on a
+// goroutine, and returning once they are all updated: the systems of a
+// world updated after it see what its systems did. Its systems must only
+// touch their own state while they update, never the entities nor the
+// other systems, and hand their results to the main thread with
+// event.Bus.Post or their own synchronization.
+//
+// Parallel does not add the entities to its systems; they get what they
+// need from the services.
+type Parallel struct {
+	systems []ecs.System
+	wg      sync.WaitGroup
+	// panics holds the panic of each system of the last update, if any.
+	panics []error
+}
+
+// NewParallel returns a system updating systems concurrently.
+func NewParallel(systems ...ecs.System) *Parallel {
+	p := &Parallel{}
+	for _, s := range systems {
+		p.Add(s)
+	}
+
+	return p
+}
+
+// Add adds a system, updated from the next Update on. It must not be
+// called during an Update.
+func (p *Parallel) Add(s ecs.System) {
+	p.systems = append(p.systems, s)
+	p.panics = append(p.panics, nil)
+}
+
+// Update updates the systems concurrently and waits for them. A panic of
+// a system panics again on the calling goroutine, with the stack of the
+// system, once the others are updated, so the crash reports catch it.
+func (p *Parallel) Update(dt float32) {
+	p.wg.Add(len(p.systems))
+	for i, s := range p.systems {
+		go p.update(i, s, dt)
+	}
+	p.wg.Wait()
+
+	for i, err := range p.panics {
+		if err != nil {
+			p.panics[i] = nil
+			panic(err)
+		}
+	}
+}
+
+func (p *Parallel) update(i int, s ecs.System, dt float32) {
+	defer p.wg.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			p.panics[i] = errors.Errorf("%v, in a concurrent system:\n%s", r, debug.Stack())
+		}
+	}()
+
+	s.Update(dt)
+}
+
+// Remove removes e from the systems, one after the other.
+func (p *Parallel) Remove(e ecs.BasicEntity) {
+	for _, s := range p.systems {
+		s.Remove(e)
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+	"github.com/project-midgard/midgarts/internal/system"
+)
+
+// barrier updates once every barrier of its group started updating, so
+// the update of a group only returns when its systems run concurrently.
+type barrier struct {
+	started chan struct{}
+	group   []*barrier
+	updates int
+	removed []uint64
+}
+
+func newBarriers(n int) []*barrier {
+	group := make([]*barrier, n)
+	for i := range group {
+		group[i] = &barrier{started: make(chan struct{}, 1)}
+	}
+	for _, b := range group {
+		b.group = group
+	}
+
+	return group
+}
+
+func (b *barrier) Update(dt float32) {
+	b.started <- struct{}{}
+	for _, other := range b.group {
+		if other != b {
+			<-other.started
+			other.started <- struct{}{}
+		}
+	}
+
+	b.updates++
+}
+
+func (b *barrier) Remove(e ecs.BasicEntity) {
+	b.removed = append(b.removed, e.ID())
+}
+
+func TestParallel(t *testing.T) {
+	barriers := newBarriers(3)
+	p := NewParallel(barriers[0], barriers[1])
+	p.Add(barriers[2])
+
+	// Run with -race: the updates are seen once Update returns.
+	for i := 0; i < 10; i++ {
+		p.Update(0.02)
+		for _, b := range barriers {
+			<-b.started
+		}
+	}
+	for _, b := range barriers {
+		assert.Equal(t, 10, b.updates)
+	}
+
+	e := ecs.NewBasic()
+	p.Remove(e)
+	for _, b := range barriers {
+		assert.Equal(t, []uint64{e.ID()}, b.removed)
+	}
+}
+
+// panicking panics on update.
+type panicking struct{}
+
+func (panicking) Update(dt float32) { panic("no device") }
+
+func (panicking) Remove(ecs.BasicEntity) {}
+
+func TestParallelPanic(t *testing.T) {
+	sys := &counter{}
+	p := NewParallel(panicking{}, sys)
+
+	defer func() {
+		r := recover()
+		require.Error(t, r.(error))
+		assert.Contains(t, r.(error).Error(), "no device, in a concurrent system")
+		// The other systems did update.
+		assert.Equal(t, 1, sys.updates)
+	}()
+	p.Update(0.02)
+}
+
+func TestNewWorlds(t *testing.T) {
+	w := NewWorlds()
+	sys := &counter{}
+	w.Concurrent.Add(sys)
+
+	w.Simulation.Update(0.02)
+	w.Render.Update(0.02)
+	assert.Equal(t, 1, sys.updates)
+}
+
+// mixer records the sound effects played.
+type mixer struct {
+	effects []string
+}
+
+func (m *mixer) PlayEffect(name string, gains audio.Gains) error {
+	m.effects = append(m.effects, name)
+	return nil
+}
+
+func (m *mixer) PlayMusic(path string) error { return nil }
+
+// footsteps requests a sound on each update.
+type footsteps struct {
+	sounds *system.AudioSystem
+}
+
+func (f footsteps) Update(dt float32) {
+	f.sounds.PlayEffect("step.wav", mgl32.Vec2{100, 100})
+}
+
+func (footsteps) Remove(ecs.BasicEntity) {}
+
+func TestConcurrentAudio(t *testing.T) {
+	m := &mixer{}
+	sounds := system.NewAudioSystem(context.Background(), m, nil, func() audio.Listener {
+		return audio.NewListener(100, 100, 0)
+	})
+	w := NewWorlds()
+	w.Concurrent.Add(sounds)
+	w.Concurrent.Add(footsteps{sounds})
+
+	// Run with -race: the sounds are requested while the audio system
+	// plays the ones requested before.
+	for i := 0; i < 10; i++ {
+		w.Simulation.Update(0.02)
+	}
+	sounds.Update(0.02)
+	assert.Len(t, m.effects, 10)
+}
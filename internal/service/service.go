@@ -0,0 +1,145 @@
+// Package service wires the systems of the client: the entry point builds
+// the shared Services once, and the scenes hand them to the registered
+// installers, so adding a system means registering its installer rather
+// than editing every entry point and scene.
+//
+// The systems follow a threading model:
+//
+//   - The frames run on the main goroutine, locked to the main OS thread
+//     as SDL and OpenGL require. The input, the Render world, which alone
+//     calls OpenGL, and the Simulation world update there one after the
+//     other; their systems share the entities without locking.
+//   - The systems of Worlds.Concurrent update concurrently with each
+//     other while the Simulation world waits for them (see Parallel), as
+//     the AudioSystem decoding and playing the sounds requested. They
+//     only touch their own state, or guard what they share.
+//   - Background goroutines, such as the sprite loading, the network
+//     reads or the file watching, never touch the entities nor the
+//     systems: the main thread polls their results (asset.Manager.Update)
+//     or they post events to the Bus (event.Bus.Post), published on the
+//     main thread.
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/asset"
+	"github.com/project-midgard/midgarts/internal/camera"
+	"github.com/project-midgard/midgarts/internal/console"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/i18n"
+	"github.com/project-midgard/midgarts/internal/system"
+	"github.com/project-midgard/midgarts/internal/theme"
+	"github.com/project-midgard/midgarts/pkg/plugin"
+)
+
+// Services are the dependencies shared by the systems.
+type Services struct {
+	// Ctx is done when the client quits and carries the logger (see
+	// logging.For).
+	Ctx      context.Context
+	GRF      *grf.MultiFile
+	Assets   *asset.Manager
+	Textures graphic.TextureProvider
+	Bus      *event.Bus
+	Camera   *camera.Camera
+	Factory  *entity.Factory
+	Plugins  *plugin.Host
+	Text     *i18n.Translator
+	// Console is the developer console, the scenes adding their commands
+	// to it.
+	Console *console.Console
+	// Theme is the look of the captions, the windows and the overlays, of
+	// the accessibility options.
+	Theme theme.Theme
+	// Audio plays the sounds and the music, the scenes setting its
+	// listener.
+	Audio *system.AudioSystem
+
+	WindowWidth, WindowHeight int
+}
+
+// Worlds are the worlds of a scene: Simulation is updated at a fixed rate
+// (see timestep), Render every frame.
+type Worlds struct {
+	Simulation *ecs.World
+	Render     *ecs.World
+	// Concurrent holds the systems that may update concurrently, a system
+	// of Simulation (see NewWorlds).
+	Concurrent *Parallel
+}
+
+// NewWorlds returns empty worlds, Concurrent updating with Simulation.
+func NewWorlds() Worlds {
+	w := Worlds{Simulation: &ecs.World{}, Render: &ecs.World{}, Concurrent: NewParallel()}
+	w.Simulation.AddSystem(w.Concurrent)
+
+	return w
+}
+
+// AddEntity adds e to both worlds.
+func (w Worlds) AddEntity(e ecs.Identifier) {
+	w.Simulation.AddEntity(e)
+	w.Render.AddEntity(e)
+}
+
+// RemoveEntity removes e from both worlds.
+func (w Worlds) RemoveEntity(e ecs.BasicEntity) {
+	w.Simulation.RemoveEntity(e)
+	w.Render.RemoveEntity(e)
+}
+
+// Installer adds systems to the worlds of a scene. The returned teardown,
+// if not nil, is called when the scene exits.
+type Installer func(s *Services, w Worlds) (teardown func(), err error)
+
+var installers = map[string]Installer{}
+
+// Register adds an installer, run by Install in name order. It panics
+// when the name is already registered.
+func Register(name string, install Installer) {
+	if _, ok := installers[name]; ok {
+		panic(fmt.Sprintf("service: installer '%s' registered twice", name))
+	}
+
+	installers[name] = install
+}
+
+// Install runs the registered installers. On error, the systems installed
+// so far are torn down.
+func Install(s *Services, w Worlds) (teardown func(), err error) {
+	names := make([]string, 0, len(installers))
+	for name := range installers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var teardowns []func()
+	teardown = func() {
+		for i := len(teardowns) - 1; i >= 0; i-- {
+			teardowns[i]()
+		}
+	}
+
+	for _, name := range names {
+		td, err := installers[name](s, w)
+		if err != nil {
+			teardown()
+			return nil, errors.Wrapf(err, "could not install %s", name)
+		}
+
+		if td != nil {
+			teardowns = append(teardowns, td)
+		}
+	}
+
+	return teardown, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counter counts its updates.
+type counter struct {
+	updates int
+}
+
+func (c *counter) Update(dt float32) { c.updates++ }
+
+func (c *counter) Remove(ecs.BasicEntity) {}
+
+func TestInstall(t *testing.T) {
+	defer func() { installers = map[string]Installer{} }()
+
+	var calls []string
+	sim, render := &counter{}, &counter{}
+
+	Register("b", func(s *Services, w Worlds) (func(), error) {
+		calls = append(calls, "install b")
+		w.Render.AddSystem(render)
+		return func() { calls = append(calls, "teardown b") }, nil
+	})
+	Register("a", func(s *Services, w Worlds) (func(), error) {
+		calls = append(calls, "install a")
+		w.Simulation.AddSystem(sim)
+		return func() { calls = append(calls, "teardown a") }, nil
+	})
+	assert.Panics(t, func() { Register("a", nil) })
+
+	w := Worlds{Simulation: &ecs.World{}, Render: &ecs.World{}}
+	teardown, err := Install(&Services{}, w)
+	require.NoError(t, err)
+
+	w.Simulation.Update(0.02)
+	assert.Equal(t, 1, sim.updates)
+	assert.Equal(t, 0, render.updates)
+
+	teardown()
+	assert.Equal(t, []string{"install a", "install b", "teardown b", "teardown a"}, calls)
+}
+
+func TestInstallError(t *testing.T) {
+	defer func() { installers = map[string]Installer{} }()
+
+	var calls []string
+	Register("a", func(s *Services, w Worlds) (func(), error) {
+		return func() { calls = append(calls, "teardown a") }, nil
+	})
+	Register("b", func(s *Services, w Worlds) (func(), error) {
+		return nil, errors.New("no device")
+	})
+
+	_, err := Install(&Services{}, Worlds{})
+	assert.EqualError(t, err, "could not install b: no device")
+	assert.Equal(t, []string{"teardown a"}, calls)
+}
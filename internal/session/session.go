@@ -0,0 +1,178 @@
+// Package session replays the zone sessions of packet captures (see
+// package capture) without any renderer: the entities the server showed
+// spawn, walk, chat and vanish in a sim.World as the client saw them, the
+// packets played back at the times they were received.
+package session
+
+import (
+	"time"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/network/capture"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+	"github.com/project-midgard/midgarts/internal/sim"
+)
+
+// Object types of the entities, see packet.ZC_NOTIFY_STANDENTRY.
+const (
+	ObjectPlayer  = 0
+	ObjectNPC     = 1
+	ObjectMonster = 5
+)
+
+// Entity is an entity in sight.
+type Entity struct {
+	GID uint32
+	// ObjectType is the kind of entity, e.g. ObjectMonster, and Job its job
+	// or the sprite id of the monsters and the NPCs.
+	ObjectType uint8
+	Job        int16
+	Head       int16
+	// TopHeadgear, MidHeadgear and LowHeadgear are the view ids of the
+	// headgears of the players.
+	TopHeadgear, MidHeadgear, LowHeadgear int16
+	// Weapon is the class of the weapon of the players, see
+	// weapontype.Type.
+	Weapon int16
+	Male   bool
+	Actor  *sim.Actor
+}
+
+// World is the map of a session and its entities in sight.
+type World struct {
+	Sim *sim.World
+	// Entered is called when the player enters the map at a cell, Spawned
+	// and Vanished when an entity comes into sight and leaves it, and Said
+	// when it chats.
+	Entered  func(c sim.Cell)
+	Spawned  func(e *Entity)
+	Vanished func(e *Entity)
+	Said     func(e *Entity, message string)
+
+	entities map[uint32]*Entity
+}
+
+// NewWorld returns the world of the map of gatFile, without entities.
+func NewWorld(gatFile *gat.GroundAltitudeFile) *World {
+	return &World{Sim: sim.NewWorld(gatFile), entities: map[uint32]*Entity{}}
+}
+
+// Entity returns the entity of gid, and whether it is in sight.
+func (w *World) Entity(gid uint32) (*Entity, bool) {
+	e, ok := w.entities[gid]
+	return e, ok
+}
+
+// Apply applies the packet p the server sent. The packets of the entities
+// not in sight are dropped.
+func (w *World) Apply(p packet.Packet) {
+	switch p := p.(type) {
+	case *packet.ZC_ACCEPT_ENTER:
+		x, y, _ := packet.DecodePosDir(p.PosDir)
+		if w.Entered != nil {
+			w.Entered(sim.Cell{X: x, Y: y})
+		}
+	case *packet.ZC_NOTIFY_STANDENTRY:
+		x, y, dir := packet.DecodePosDir(p.PosDir)
+		e, ok := w.entities[p.GID]
+		if !ok {
+			e = &Entity{
+				GID:        p.GID,
+				ObjectType: p.ObjectType,
+				Job:        p.Job,
+				Head:       p.Head,
+				// The accessories are the low, top and middle headgears.
+				LowHeadgear: p.Accessory,
+				TopHeadgear: p.Accessory2,
+				MidHeadgear: p.Accessory3,
+				Weapon:      p.Weapon,
+				Male:        p.Sex == 1,
+				Actor:       w.Sim.Spawn(sim.Cell{X: x, Y: y}),
+			}
+			w.entities[p.GID] = e
+		} else {
+			w.Sim.Stop(e.Actor)
+			w.Sim.Place(e.Actor, sim.Cell{X: x, Y: y}.Center())
+		}
+		e.Actor.Direction = Direction(dir)
+		if p.Speed > 0 {
+			e.Actor.MovementSpeed = character.WalkAnimationMultiplier(time.Duration(p.Speed) * time.Millisecond)
+		}
+
+		if !ok && w.Spawned != nil {
+			w.Spawned(e)
+		}
+	case *packet.ZC_NOTIFY_MOVE:
+		e, ok := w.entities[p.GID]
+		if !ok {
+			return
+		}
+		x0, y0, x1, y1 := packet.DecodeMoveData(p.MoveData)
+		w.Sim.Place(e.Actor, sim.Cell{X: x0, Y: y0}.Center())
+		w.Sim.WalkTo(e.Actor, sim.Cell{X: x1, Y: y1})
+	case *packet.ZC_NOTIFY_VANISH:
+		e, ok := w.entities[p.GID]
+		if !ok {
+			return
+		}
+		delete(w.entities, p.GID)
+		w.Sim.Remove(e.Actor)
+
+		if w.Vanished != nil {
+			w.Vanished(e)
+		}
+	case *packet.ZC_NOTIFY_CHAT:
+		if e, ok := w.entities[p.GID]; ok && w.Said != nil {
+			w.Said(e, p.Message)
+		}
+	}
+}
+
+// Update advances the walks by dt seconds.
+func (w *World) Update(dt float32) {
+	w.Sim.Update(dt)
+}
+
+// Direction returns the direction of dir, as the server numbers them: from
+// the north, counterclockwise.
+func Direction(dir uint8) directiontype.Type {
+	return directiontype.Type((4 - int(dir&7) + 8) % 8)
+}
+
+// Player plays the packets of a capture back at the times they were
+// received.
+type Player struct {
+	records []capture.Record
+	next    int
+	elapsed time.Duration
+}
+
+// NewPlayer returns the player of records, sorted by time.
+func NewPlayer(records []capture.Record) *Player {
+	return &Player{records: records}
+}
+
+// Advance advances the playback by dt seconds, returning the packets
+// received meanwhile.
+func (p *Player) Advance(dt float32) []packet.Packet {
+	p.elapsed += time.Duration(float64(dt) * float64(time.Second))
+
+	var packets []packet.Packet
+	for ; p.next < len(p.records) && p.records[p.next].Time <= p.elapsed; p.next++ {
+		packets = append(packets, p.records[p.next].Packet)
+	}
+
+	return packets
+}
+
+// Elapsed returns the time played back.
+func (p *Player) Elapsed() time.Duration {
+	return p.elapsed
+}
+
+// Done tells whether every packet was played back.
+func (p *Player) Done() bool {
+	return p.next == len(p.records)
+}
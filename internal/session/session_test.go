@@ -0,0 +1,93 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/network/capture"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+	"github.com/project-midgard/midgarts/internal/sim"
+)
+
+func newMap(width, height int) *gat.GroundAltitudeFile {
+	f := &gat.GroundAltitudeFile{Width: uint32(width), Height: uint32(height), Cells: make([]gat.Cell, width*height)}
+	for i := range f.Cells {
+		f.Cells[i].CellType = gat.Walkable
+	}
+
+	return f
+}
+
+func TestWorld(t *testing.T) {
+	w := NewWorld(newMap(20, 20))
+	var (
+		entered       sim.Cell
+		spawned, gone []uint32
+		said          []string
+	)
+	w.Entered = func(c sim.Cell) { entered = c }
+	w.Spawned = func(e *Entity) { spawned = append(spawned, e.GID) }
+	w.Vanished = func(e *Entity) { gone = append(gone, e.GID) }
+	w.Said = func(e *Entity, message string) { said = append(said, message) }
+
+	w.Apply(&packet.ZC_ACCEPT_ENTER{PosDir: packet.EncodePosDir(2, 3, 0)})
+	assert.Equal(t, sim.Cell{X: 2, Y: 3}, entered)
+
+	w.Apply(&packet.ZC_NOTIFY_STANDENTRY{ObjectType: ObjectMonster, GID: 7, Job: 1002, Speed: 300, PosDir: packet.EncodePosDir(5, 5, 2)})
+	e, ok := w.Entity(7)
+	require.True(t, ok)
+	assert.Equal(t, []uint32{7}, spawned)
+	assert.Equal(t, int16(1002), e.Job)
+	assert.Equal(t, directiontype.West, e.Actor.Direction)
+	assert.Equal(t, 0.5, e.Actor.MovementSpeed)
+	assert.Equal(t, mgl32.Vec2{5.5, 5.5}, e.Actor.Position)
+
+	// 2 cells at 300ms per cell.
+	w.Apply(&packet.ZC_NOTIFY_MOVE{GID: 7, MoveData: packet.EncodeMoveData(5, 5, 7, 5)})
+	w.Update(0.3)
+	assert.Equal(t, statetype.Walking, e.Actor.State)
+	w.Update(0.4)
+	assert.Equal(t, sim.Cell{X: 7, Y: 5}, e.Actor.Cell())
+
+	w.Apply(&packet.ZC_NOTIFY_CHAT{GID: 7, Message: "Poring : hi"})
+	w.Apply(&packet.ZC_NOTIFY_CHAT{GID: 8, Message: "not in sight"})
+	assert.Equal(t, []string{"Poring : hi"}, said)
+
+	w.Apply(&packet.ZC_NOTIFY_VANISH{GID: 7, Type: 1})
+	assert.Equal(t, []uint32{7}, gone)
+	_, ok = w.Entity(7)
+	assert.False(t, ok)
+	assert.Empty(t, w.Sim.Actors())
+	// The accessories are the low, top and middle headgears.
+	w.Apply(&packet.ZC_NOTIFY_STANDENTRY{ObjectType: ObjectPlayer, GID: 9, Accessory: 12, Accessory2: 5, Accessory3: 17, Weapon: 2})
+	player, ok := w.Entity(9)
+	require.True(t, ok)
+	assert.Equal(t, [3]int16{5, 17, 12}, [3]int16{player.TopHeadgear, player.MidHeadgear, player.LowHeadgear})
+	assert.Equal(t, int16(2), player.Weapon)
+}
+
+func TestDirection(t *testing.T) {
+	assert.Equal(t, directiontype.North, Direction(0))
+	assert.Equal(t, directiontype.NorthWest, Direction(1))
+	assert.Equal(t, directiontype.South, Direction(4))
+	assert.Equal(t, directiontype.NorthEast, Direction(7))
+}
+
+func TestPlayer(t *testing.T) {
+	vanish := &packet.ZC_NOTIFY_VANISH{GID: 1}
+	chat := &packet.ZC_NOTIFY_CHAT{GID: 1}
+	p := NewPlayer([]capture.Record{{Time: 0, Packet: chat}, {Time: time.Second, Packet: vanish}})
+
+	assert.Equal(t, []packet.Packet{chat}, p.Advance(0.5))
+	assert.Empty(t, p.Advance(0.4))
+	assert.False(t, p.Done())
+	assert.Equal(t, []packet.Packet{vanish}, p.Advance(0.2))
+	assert.True(t, p.Done())
+}
@@ -0,0 +1,47 @@
+package sim
+
+// move updates the cell a occupies after it moved.
+func (w *World) move(a *Actor) {
+	c := Cell{X: int(a.Position.X()), Y: int(a.Position.Y())}
+	if a.Position.X() < 0 {
+		c.X--
+	}
+	if a.Position.Y() < 0 {
+		c.Y--
+	}
+	if c == a.cell {
+		return
+	}
+
+	w.leave(a, a.cell)
+	a.cell = c
+	w.occupancy[c] = append(w.occupancy[c], a)
+}
+
+// leave removes a from the actors occupying c.
+func (w *World) leave(a *Actor, c Cell) {
+	actors := w.occupancy[c]
+	for i, other := range actors {
+		if other == a {
+			actors = append(actors[:i], actors[i+1:]...)
+			break
+		}
+	}
+
+	if len(actors) == 0 {
+		delete(w.occupancy, c)
+		return
+	}
+	w.occupancy[c] = actors
+}
+
+// occupiedByOthers tells whether an actor other than a occupies c.
+func (w *World) occupiedByOthers(c Cell, a *Actor) bool {
+	for _, other := range w.occupancy[c] {
+		if other != a {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,166 @@
+package sim
+
+import (
+	"container/heap"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+)
+
+// Costs of the steps of the paths, a diagonal step being about √2
+// straight ones.
+const (
+	straightCost = 10
+	diagonalCost = 14
+)
+
+// Cell is a cell of a map, (0, 0) being its south west corner.
+type Cell struct {
+	X, Y int
+}
+
+// Center returns the position of the center of c, in cells.
+func (c Cell) Center() mgl32.Vec2 {
+	return mgl32.Vec2{float32(c.X) + 0.5, float32(c.Y) + 0.5}
+}
+
+// Distance returns the number of steps between a and b, straight or
+// diagonal, as the attack ranges count them.
+func Distance(a, b Cell) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	if dx > dy {
+		return dx
+	}
+
+	return dy
+}
+
+// Step returns the step to the neighbor cell in the direction d.
+func Step(d directiontype.Type) Cell {
+	return directionSteps[d%8]
+}
+
+// directionSteps are the steps of the directions, from the south
+// clockwise.
+var directionSteps = [8]Cell{
+	{Y: -1}, {X: -1, Y: -1}, {X: -1}, {X: -1, Y: 1},
+	{Y: 1}, {X: 1, Y: 1}, {X: 1}, {X: 1, Y: -1},
+}
+
+// neighbors are the steps from a cell, the straight ones first.
+var neighbors = [8]Cell{
+	{X: 1}, {X: -1}, {Y: 1}, {Y: -1},
+	{X: 1, Y: 1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: -1, Y: -1},
+}
+
+// FindPath returns the shortest path from one cell to another, through the
+// cells walkable tells, from the cell after from to to, and whether there
+// is one. The paths step to the 8 neighbors of the cells, the diagonal
+// steps not cutting the corners of the cells that are not walkable.
+// maxCells bounds the search, 0 for no bound.
+func FindPath(from, to Cell, walkable func(Cell) bool, maxCells int) ([]Cell, bool) {
+	if from == to {
+		return nil, true
+	}
+	if !walkable(to) {
+		return nil, false
+	}
+
+	type node struct {
+		cost   int
+		parent Cell
+		closed bool
+	}
+	nodes := map[Cell]*node{from: {}}
+	open := &pathQueue{}
+	heap.Push(open, pathItem{cell: from, priority: heuristic(from, to)})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathItem).cell
+		n := nodes[current]
+		if n.closed {
+			continue
+		}
+		n.closed = true
+
+		if current == to {
+			var path []Cell
+			for c := to; c != from; c = nodes[c].parent {
+				path = append(path, c)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+
+			return path, true
+		}
+
+		if maxCells > 0 && len(nodes) > maxCells {
+			return nil, false
+		}
+
+		for i, step := range neighbors {
+			next := Cell{X: current.X + step.X, Y: current.Y + step.Y}
+			if !walkable(next) {
+				continue
+			}
+
+			cost := n.cost + straightCost
+			if i >= 4 {
+				if !walkable(Cell{X: current.X + step.X, Y: current.Y}) || !walkable(Cell{X: current.X, Y: current.Y + step.Y}) {
+					continue
+				}
+				cost = n.cost + diagonalCost
+			}
+
+			if known, ok := nodes[next]; ok && (known.closed || known.cost <= cost) {
+				continue
+			}
+			nodes[next] = &node{cost: cost, parent: current}
+			heap.Push(open, pathItem{cell: next, priority: cost + heuristic(next, to)})
+		}
+	}
+
+	return nil, false
+}
+
+// heuristic returns the cost of the path from a to b without obstacles.
+func heuristic(a, b Cell) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	if dx < dy {
+		dx, dy = dy, dx
+	}
+
+	return dy*diagonalCost + (dx-dy)*straightCost
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
+
+type pathItem struct {
+	cell     Cell
+	priority int
+}
+
+// pathQueue is the priority queue of the cells to search, the lowest
+// priority first.
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathItem)) }
+
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return item
+}
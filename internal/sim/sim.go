@@ -0,0 +1,343 @@
+// Package sim simulates the characters of a map without any renderer:
+// their walks along the paths found through the walkable cells, the cells
+// they occupy, and their states, idle, walking or attacking on the timing
+// of their ASPD. It runs on the GAT files alone, for the server side
+// tools, the bots and the tests.
+//
+// The positions are in cells from the south west corner of the map, the
+// north being +Y and the east +X, the cell (x, y) spanning from (x, y) to
+// (x+1, y+1).
+package sim
+
+import (
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/aspd"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/character/walkpath"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/romap"
+)
+
+// DefaultMaxPathCells bounds the cells searched for a path, as the
+// servers do not search the whole map.
+const DefaultMaxPathCells = 4096
+
+// Actor is a character of a World.
+type Actor struct {
+	ID uint64
+	// Position is the position of the actor, in cells.
+	Position mgl32.Vec2
+	// Direction is the direction the actor faces.
+	Direction directiontype.Type
+	State     statetype.Type
+	// MovementSpeed is the walk speed, relative to the normal one (see
+	// character.WalkSpeed).
+	MovementSpeed float64
+	// ASPD is the attack speed (see aspd.New).
+	ASPD float64
+	// Range is the attack range, in cells: 1 for the melee attacks.
+	Range int
+
+	cell   Cell
+	walk   *walkpath.Walk
+	goal   Cell
+	target *Actor
+	// attackElapsed is the time since the start of the last attack, and
+	// hit whether its damage is applied.
+	attackElapsed time.Duration
+	hit           bool
+}
+
+// Cell returns the cell the actor occupies.
+func (a *Actor) Cell() Cell {
+	return a.cell
+}
+
+// Target returns the actor a attacks, nil if none.
+func (a *Actor) Target() *Actor {
+	return a.target
+}
+
+// Destination returns the cell a walks to, and whether it is walking.
+func (a *Actor) Destination() (Cell, bool) {
+	return a.goal, a.walk != nil
+}
+
+// World is a map and its actors.
+type World struct {
+	// Hit is called when an attacker applies the damage of an attack, at
+	// the end of its attack motion. It may remove the target.
+	Hit func(attacker, target *Actor)
+	// MaxPathCells bounds the cells searched for a path, 0 for no bound.
+	MaxPathCells int
+	// BlockOccupied makes the cells occupied by the other actors not
+	// walkable, for the actors not to walk through one another.
+	BlockOccupied bool
+
+	gat       *gat.GroundAltitudeFile
+	actors    []*Actor
+	occupancy map[Cell][]*Actor
+	nextID    uint64
+}
+
+// NewWorld returns a world of the cells of gatFile, without actors.
+func NewWorld(gatFile *gat.GroundAltitudeFile) *World {
+	return &World{
+		MaxPathCells: DefaultMaxPathCells,
+		gat:          gatFile,
+		occupancy:    map[Cell][]*Actor{},
+		nextID:       1,
+	}
+}
+
+// Walkable tells whether the actors may walk on c.
+func (w *World) Walkable(c Cell) bool {
+	cell := w.gat.CellAt(c.X, c.Y)
+	return cell != nil && cell.CellType&romap.CellTypeWalkable != 0
+}
+
+// NearestWalkable returns the walkable cell nearest to c, within radius
+// cells, and whether there is one.
+func (w *World) NearestWalkable(c Cell, radius int) (Cell, bool) {
+	for r := 0; r <= radius; r++ {
+		for y := c.Y - r; y <= c.Y+r; y++ {
+			for x := c.X - r; x <= c.X+r; x++ {
+				next := Cell{X: x, Y: y}
+				if Distance(c, next) == r && w.Walkable(next) {
+					return next, true
+				}
+			}
+		}
+	}
+
+	return Cell{}, false
+}
+
+// Spawn adds an actor standing at the center of c, facing south, at the
+// normal speeds.
+func (w *World) Spawn(c Cell) *Actor {
+	a := &Actor{
+		ID:            w.nextID,
+		Position:      c.Center(),
+		Direction:     directiontype.South,
+		State:         statetype.Idle,
+		MovementSpeed: 1,
+		ASPD:          aspd.Default,
+		Range:         1,
+		cell:          c,
+	}
+	w.nextID++
+	w.actors = append(w.actors, a)
+	w.occupancy[c] = append(w.occupancy[c], a)
+
+	return a
+}
+
+// Remove removes a from the world, the actors attacking it standing by.
+func (w *World) Remove(a *Actor) {
+	for i, other := range w.actors {
+		if other == a {
+			w.actors = append(w.actors[:i], w.actors[i+1:]...)
+			break
+		}
+	}
+	w.leave(a, a.cell)
+
+	for _, other := range w.actors {
+		if other.target == a {
+			w.Stop(other)
+		}
+	}
+}
+
+// Actor returns the actor id, and whether it is in the world.
+func (w *World) Actor(id uint64) (*Actor, bool) {
+	for _, a := range w.actors {
+		if a.ID == id {
+			return a, true
+		}
+	}
+
+	return nil, false
+}
+
+// Actors returns the actors, in the order they were spawned.
+func (w *World) Actors() []*Actor {
+	return w.actors
+}
+
+// ActorsAt returns the actors occupying c.
+func (w *World) ActorsAt(c Cell) []*Actor {
+	return w.occupancy[c]
+}
+
+// FindPath returns the path a would walk to c (see FindPath).
+func (w *World) FindPath(a *Actor, c Cell) ([]Cell, bool) {
+	return FindPath(a.cell, c, func(next Cell) bool {
+		return w.Walkable(next) && (!w.BlockOccupied || next == c || !w.occupiedByOthers(next, a))
+	}, w.MaxPathCells)
+}
+
+// WalkTo makes a walk to c, dropping its attack, returning whether there
+// is a path to c.
+func (w *World) WalkTo(a *Actor, c Cell) bool {
+	a.target = nil
+	return w.walkTo(a, c)
+}
+
+// Attack makes a attack target, walking to it first when it is out of
+// range.
+func (w *World) Attack(a, target *Actor) {
+	if a == target {
+		return
+	}
+
+	a.target = target
+	if w.inRange(a) {
+		w.startAttack(a)
+		return
+	}
+	w.walkTo(a, target.cell)
+}
+
+// Place moves a to p at once, for the actors the world does not walk,
+// e.g. a character the player moves.
+func (w *World) Place(a *Actor, p mgl32.Vec2) {
+	a.Position = p
+	w.move(a)
+}
+
+// Stop makes a stand by where it is, dropping its walk and its attack.
+func (w *World) Stop(a *Actor) {
+	a.walk, a.target = nil, nil
+	a.State = statetype.StandBy
+}
+
+// Update advances the world by dt seconds.
+func (w *World) Update(dt float32) {
+	// The actors may be removed by Hit.
+	for _, a := range append([]*Actor(nil), w.actors...) {
+		if !w.contains(a) {
+			continue
+		}
+
+		if a.walk != nil {
+			w.advance(a, dt)
+		} else if a.target != nil {
+			w.attack(a, time.Duration(float64(dt)*float64(time.Second)))
+		}
+	}
+}
+
+func (w *World) walkTo(a *Actor, c Cell) bool {
+	path, ok := w.FindPath(a, c)
+	if !ok {
+		return false
+	}
+	if len(path) == 0 {
+		a.walk = nil
+		a.State = statetype.StandBy
+		return true
+	}
+
+	points := []mgl32.Vec2{a.Position}
+	for i, cell := range path {
+		// The straight parts of the path are walked through their ends.
+		if i+1 < len(path) && i > 0 && cell.X-path[i-1].X == path[i+1].X-cell.X && cell.Y-path[i-1].Y == path[i+1].Y-cell.Y {
+			continue
+		}
+		points = append(points, cell.Center())
+	}
+
+	a.walk = &walkpath.Walk{Path: walkpath.New(points...)}
+	a.goal = c
+	a.State = statetype.Walking
+
+	return true
+}
+
+// advance moves a along its walk for dt seconds, following its target.
+func (w *World) advance(a *Actor, dt float32) {
+	position, heading, done := a.walk.Advance(character.WalkDistance(dt, character.WalkSpeed(a.MovementSpeed)))
+	a.Position = position
+	a.Direction = direction(heading)
+	w.move(a)
+
+	if a.target != nil {
+		switch {
+		case w.inRange(a):
+			w.startAttack(a)
+			return
+		case done || a.target.cell != a.goal:
+			// The target moved away.
+			if !w.walkTo(a, a.target.cell) {
+				w.Stop(a)
+			}
+			return
+		}
+	}
+
+	if done {
+		a.walk = nil
+		a.State = statetype.StandBy
+	}
+}
+
+// attack advances the attack of a by elapsed, applying its damage at the
+// end of the attack motion, and attacking again after the attack delay.
+func (w *World) attack(a *Actor, elapsed time.Duration) {
+	if !w.inRange(a) {
+		if !w.walkTo(a, a.target.cell) {
+			w.Stop(a)
+		}
+		return
+	}
+
+	timing := aspd.New(a.ASPD)
+	a.attackElapsed += elapsed
+	a.Direction = direction(a.target.Position.Sub(a.Position))
+
+	if !a.hit && timing.CanCancel(a.attackElapsed) {
+		a.hit = true
+		if w.Hit != nil {
+			w.Hit(a, a.target)
+		}
+	}
+
+	if timing.CanAttack(a.attackElapsed) {
+		a.attackElapsed -= timing.Delay
+		a.hit = false
+	}
+}
+
+func (w *World) startAttack(a *Actor) {
+	a.walk = nil
+	a.State = statetype.Attacking
+	a.attackElapsed, a.hit = 0, false
+	a.Direction = direction(a.target.Position.Sub(a.Position))
+}
+
+func (w *World) inRange(a *Actor) bool {
+	return Distance(a.cell, a.target.cell) <= a.Range
+}
+
+func (w *World) contains(a *Actor) bool {
+	for _, other := range w.actors {
+		if other == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+// direction returns the direction of an actor heading along heading, in
+// map coordinates, the world ones having the east at -X.
+func direction(heading mgl32.Vec2) directiontype.Type {
+	return walkpath.Direction(mgl32.Vec2{-heading.X(), heading.Y()})
+}
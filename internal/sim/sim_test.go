@@ -0,0 +1,213 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+)
+
+// newMap returns a map of the rows, the north one first, '#' being the
+// cells that are not walkable.
+func newMap(rows ...string) *gat.GroundAltitudeFile {
+	width, height := len(rows[0]), len(rows)
+	f := &gat.GroundAltitudeFile{Width: uint32(width), Height: uint32(height), Cells: make([]gat.Cell, width*height)}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := f.CellAt(x, y)
+			cell.CellType = gat.Walkable
+			if rows[height-1-y][x] == '#' {
+				cell.CellType = gat.None
+			}
+		}
+	}
+
+	return f
+}
+
+func TestFindPath(t *testing.T) {
+	w := NewWorld(newMap(
+		".....",
+		".###.",
+		".....",
+	))
+
+	path, ok := FindPath(Cell{0, 1}, Cell{4, 1}, w.Walkable, 0)
+	require.True(t, ok)
+	// Around the wall, north or south, without cutting its corners: the
+	// diagonal steps would.
+	assert.Len(t, path, 6)
+	assert.Equal(t, Cell{4, 1}, path[len(path)-1])
+	for i, c := range path {
+		assert.True(t, w.Walkable(c), "cell %v", c)
+		previous := Cell{0, 1}
+		if i > 0 {
+			previous = path[i-1]
+		}
+		assert.Equal(t, 1, Distance(previous, c))
+	}
+
+	_, ok = FindPath(Cell{0, 0}, Cell{2, 1}, w.Walkable, 0)
+	assert.False(t, ok, "not walkable")
+
+	path, ok = FindPath(Cell{0, 0}, Cell{0, 0}, w.Walkable, 0)
+	assert.True(t, ok)
+	assert.Empty(t, path)
+}
+
+func TestFindPathDiagonal(t *testing.T) {
+	w := NewWorld(newMap(
+		"....",
+		"....",
+		"....",
+		"....",
+	))
+
+	path, ok := FindPath(Cell{0, 0}, Cell{3, 3}, w.Walkable, 0)
+	require.True(t, ok)
+	assert.Equal(t, []Cell{{1, 1}, {2, 2}, {3, 3}}, path)
+}
+
+func TestFindPathUnreachable(t *testing.T) {
+	w := NewWorld(newMap(
+		"..#..",
+		"..#..",
+	))
+
+	_, ok := FindPath(Cell{0, 0}, Cell{4, 0}, w.Walkable, 0)
+	assert.False(t, ok)
+}
+
+func TestWalkTo(t *testing.T) {
+	w := NewWorld(newMap(
+		"......",
+		"......",
+	))
+	a := w.Spawn(Cell{0, 0})
+	assert.Equal(t, []*Actor{a}, w.ActorsAt(Cell{0, 0}))
+
+	require.True(t, w.WalkTo(a, Cell{5, 0}))
+	assert.Equal(t, statetype.Walking, a.State)
+
+	// A cell every 150ms at the normal speed: 5 cells in 0.75s.
+	w.Update(0.3)
+	assert.Equal(t, Cell{2, 0}, a.Cell())
+	assert.Equal(t, directiontype.East, a.Direction)
+	assert.Empty(t, w.ActorsAt(Cell{0, 0}))
+	assert.Equal(t, []*Actor{a}, w.ActorsAt(Cell{2, 0}))
+
+	w.Update(0.5)
+	assert.Equal(t, Cell{5, 0}, a.Cell())
+	assert.InDelta(t, 5.5, a.Position.X(), 1e-4)
+	assert.Equal(t, statetype.StandBy, a.State)
+
+	assert.False(t, w.WalkTo(a, Cell{6, 0}), "out of the map")
+}
+
+func TestBlockOccupied(t *testing.T) {
+	w := NewWorld(newMap(
+		"...",
+		"...",
+	))
+	a := w.Spawn(Cell{0, 0})
+	w.Spawn(Cell{1, 0})
+
+	path, ok := w.FindPath(a, Cell{2, 0})
+	require.True(t, ok)
+	assert.Equal(t, []Cell{{1, 0}, {2, 0}}, path)
+
+	w.BlockOccupied = true
+	path, ok = w.FindPath(a, Cell{2, 0})
+	require.True(t, ok)
+	// Around the other actor, whose cell is a corner not to cut.
+	assert.Equal(t, []Cell{{0, 1}, {1, 1}, {2, 1}, {2, 0}}, path)
+}
+
+func TestAttack(t *testing.T) {
+	w := NewWorld(newMap(
+		".....",
+	))
+	a, target := w.Spawn(Cell{0, 0}), w.Spawn(Cell{4, 0})
+
+	var hits int
+	w.Hit = func(attacker, hit *Actor) {
+		assert.Equal(t, a, attacker)
+		assert.Equal(t, target, hit)
+		hits++
+	}
+
+	// Walks to the target, then attacks it: at ASPD 150, the damage is
+	// applied after 500ms, the next attack starting after 1s.
+	w.Attack(a, target)
+	assert.Equal(t, statetype.Walking, a.State)
+	for i := 0; i < 30; i++ {
+		w.Update(0.02)
+	}
+	assert.Equal(t, Cell{3, 0}, a.Cell())
+	assert.Equal(t, statetype.Attacking, a.State)
+	assert.Equal(t, 0, hits)
+
+	for i := 0; i < 25; i++ {
+		w.Update(0.02)
+	}
+	assert.Equal(t, 1, hits)
+
+	for i := 0; i < 50; i++ {
+		w.Update(0.02)
+	}
+	assert.Equal(t, 2, hits)
+
+	// The target is removed: the attacker stands by.
+	w.Remove(target)
+	assert.Equal(t, statetype.StandBy, a.State)
+	assert.Nil(t, a.Target())
+	assert.Empty(t, w.ActorsAt(Cell{4, 0}))
+}
+
+func TestAttackFollows(t *testing.T) {
+	w := NewWorld(newMap(
+		"........",
+	))
+	a, target := w.Spawn(Cell{0, 0}), w.Spawn(Cell{1, 0})
+
+	w.Attack(a, target)
+	assert.Equal(t, statetype.Attacking, a.State)
+
+	// The target walks away: the attacker follows it.
+	require.True(t, w.WalkTo(target, Cell{7, 0}))
+	for i := 0; i < 100; i++ {
+		w.Update(0.02)
+	}
+	assert.Equal(t, Cell{7, 0}, target.Cell())
+	assert.Equal(t, Cell{6, 0}, a.Cell())
+	assert.Equal(t, statetype.Attacking, a.State)
+	assert.Equal(t, directiontype.East, a.Direction)
+}
+
+func TestNearestWalkable(t *testing.T) {
+	w := NewWorld(newMap(
+		"....",
+		"###.",
+		"###.",
+	))
+
+	c, ok := w.NearestWalkable(Cell{1, 1}, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Cell{0, 2}, c)
+
+	_, ok = w.NearestWalkable(Cell{0, 0}, 1)
+	assert.False(t, ok)
+
+	c, _ = w.NearestWalkable(Cell{3, 0}, 1)
+	assert.Equal(t, Cell{3, 0}, c)
+}
+
+func TestStep(t *testing.T) {
+	assert.Equal(t, Cell{Y: 1}, Step(directiontype.North))
+	assert.Equal(t, Cell{X: 1, Y: -1}, Step(directiontype.SouthEast))
+	assert.Equal(t, Cell{X: -1}, Step(directiontype.West))
+}
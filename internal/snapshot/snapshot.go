@@ -0,0 +1,166 @@
+// Package snapshot saves the state of the client (the characters of the
+// world and the camera) to a file and restores it, to reproduce a scene
+// from a bug report or start a test from a known state.
+package snapshot
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/camera"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+)
+
+// Version is the version of the snapshot files written by this client.
+const Version = 1
+
+type Snapshot struct {
+	Version    int         `json:"version"`
+	Map        string      `json:"map,omitempty"`
+	Camera     Camera      `json:"camera"`
+	Characters []Character `json:"characters"`
+}
+
+type Camera struct {
+	Position mgl32.Vec3 `json:"position"`
+}
+
+// Character holds the attachment configuration and the state of a
+// character. The job is saved by name (see jobspriteid.Parse).
+type Character struct {
+	Job              string               `json:"job"`
+	Gender           character.GenderType `json:"gender"`
+	Head             character.HeadIndex  `json:"head"`
+	HasShield        bool                 `json:"has_shield,omitempty"`
+	ShieldSpriteName string               `json:"shield_sprite_name,omitempty"`
+	SpritePath       string               `json:"sprite_path,omitempty"`
+	IsMounted        bool                 `json:"is_mounted,omitempty"`
+	MovementSpeed    float64              `json:"movement_speed"`
+	Scale            float32              `json:"scale,omitempty"`
+	Position         mgl32.Vec3           `json:"position"`
+	Direction        directiontype.Type   `json:"direction"`
+	State            statetype.Type       `json:"state"`
+}
+
+// Take returns the snapshot of the characters on mapName, seen by cam.
+func Take(mapName string, cam *camera.Camera, chars ...*entity.Character) *Snapshot {
+	s := &Snapshot{
+		Version:    Version,
+		Map:        mapName,
+		Camera:     Camera{Position: cam.Position()},
+		Characters: make([]Character, 0, len(chars)),
+	}
+
+	for _, char := range chars {
+		s.Characters = append(s.Characters, Character{
+			Job:              char.JobSpriteID.String(),
+			Gender:           char.Gender,
+			Head:             char.HeadIndex,
+			HasShield:        char.HasShield,
+			ShieldSpriteName: char.ShieldSpriteName,
+			SpritePath:       char.SpritePath,
+			IsMounted:        char.IsMounted,
+			MovementSpeed:    char.MovementSpeed,
+			Scale:            char.Scale,
+			Position:         char.Position(),
+			Direction:        char.Direction,
+			State:            char.State,
+		})
+	}
+
+	return s
+}
+
+// Restore moves cam and returns new characters in the saved state, to be
+// added to the world. The characters have new entity ids.
+func (s *Snapshot) Restore(cam *camera.Camera) ([]*entity.Character, error) {
+	chars := make([]*entity.Character, 0, len(s.Characters))
+	for i, c := range s.Characters {
+		job, err := jobspriteid.Parse(c.Job)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid character %d", i)
+		}
+
+		char := entity.NewCharacter(c.Gender, job, c.Head)
+		char.HasShield = c.HasShield
+		char.ShieldSpriteName = c.ShieldSpriteName
+		char.SpritePath = c.SpritePath
+		char.IsMounted = c.IsMounted
+		char.MovementSpeed = c.MovementSpeed
+		if c.Scale != 0 {
+			char.Scale = c.Scale
+		}
+		char.SetPosition(c.Position)
+		char.Direction = c.Direction
+		if c.State != "" {
+			char.SetState(c.State)
+		}
+
+		chars = append(chars, char)
+	}
+
+	if cam != nil {
+		cam.SetPosition(s.Camera.Position)
+	}
+
+	return chars, nil
+}
+
+func (s *Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(s)
+}
+
+func Read(r io.Reader) (*Snapshot, error) {
+	s := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, errors.Wrap(err, "invalid snapshot")
+	}
+
+	if s.Version > Version {
+		return nil, errors.Errorf("unsupported snapshot version %d", s.Version)
+	}
+
+	return s, nil
+}
+
+// Save writes the snapshot to the file at path.
+func (s *Snapshot) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = s.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Load reads the snapshot file at path.
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := Read(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load '%s'", path)
+	}
+
+	return s, nil
+}
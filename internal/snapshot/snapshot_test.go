@@ -0,0 +1,68 @@
+package snapshot
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/camera"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/directiontype"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+)
+
+func TestSaveLoad(t *testing.T) {
+	cam := camera.NewPerspectiveCamera(0.638, 4.0/3, 0.1, 1000)
+	cam.SetPosition(mgl32.Vec3{1, 2, 3})
+
+	knight := entity.NewCharacter(character.Male, jobspriteid.Knight, 23)
+	knight.HasShield = true
+	knight.SetPosition(mgl32.Vec3{0, 44, -1})
+	knight.Direction = directiontype.NorthEast
+	knight.SetState(statetype.Walking)
+	sage := entity.NewCharacter(character.Female, jobspriteid.Sage, 4)
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, Take("izlude", cam, knight, sage).Save(path))
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "izlude", s.Map)
+
+	restoredCam := camera.NewPerspectiveCamera(0.638, 4.0/3, 0.1, 1000)
+	chars, err := s.Restore(restoredCam)
+	require.NoError(t, err)
+	assert.Equal(t, mgl32.Vec3{1, 2, 3}, restoredCam.Position())
+
+	require.Len(t, chars, 2)
+	assert.Equal(t, jobspriteid.Knight, chars[0].JobSpriteID)
+	assert.True(t, chars[0].HasShield)
+	assert.Equal(t, mgl32.Vec3{0, 44, -1}, chars[0].Position())
+	assert.Equal(t, directiontype.NorthEast, chars[0].Direction)
+	assert.Equal(t, statetype.Walking, chars[0].State)
+	assert.Equal(t, character.Female, chars[1].Gender)
+	assert.Equal(t, jobspriteid.Sage, chars[1].JobSpriteID)
+	assert.Equal(t, character.HeadIndex(4), chars[1].HeadIndex)
+	assert.NotEqual(t, knight.ID(), chars[0].ID())
+}
+
+func TestReadInvalid(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"version": 2}`))
+	assert.EqualError(t, err, "unsupported snapshot version 2")
+
+	s, err := Read(strings.NewReader(`{"version": 1, "characters": [{"job": "Jester"}]}`))
+	require.NoError(t, err)
+	_, err = s.Restore(nil)
+	assert.EqualError(t, err, "invalid character 0: unknown job sprite 'Jester'")
+
+	var buf bytes.Buffer
+	require.NoError(t, (&Snapshot{Version: Version}).Write(&buf))
+	assert.Contains(t, buf.String(), `"version": 1`)
+}
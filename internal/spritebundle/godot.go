@@ -0,0 +1,83 @@
+package spritebundle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/project-midgard/midgarts/internal/aseprite"
+)
+
+// WriteGodot writes the animations of data as a Godot 4 SpriteFrames
+// resource (.tres), the sheet image loaded from texturePath, e.g.
+// "res://sprites/poring.png".
+//
+// Every frame is the whole canvas shared by the frames, the trimmed pixels
+// restored with the margin of its atlas texture. The "offset" metadata of
+// the resource is the offset to give to a centered AnimatedSprite2D, for
+// its position to be the origin of the sprite.
+func WriteGodot(w io.Writer, data aseprite.Data, animations []Animation, texturePath string) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "[gd_resource type=\"SpriteFrames\" load_steps=%d format=3]\n\n", 2+len(data.Frames))
+	fmt.Fprintf(bw, "[ext_resource type=\"Texture2D\" path=%s id=\"1\"]\n\n", strconv.Quote(texturePath))
+
+	for i, f := range data.Frames {
+		source := f.SpriteSourceSize
+		fmt.Fprintf(bw, "[sub_resource type=\"AtlasTexture\" id=\"%s\"]\n", atlasID(i))
+		fmt.Fprintf(bw, "atlas = ExtResource(\"1\")\n")
+		fmt.Fprintf(bw, "region = Rect2(%d, %d, %d, %d)\n", f.Frame.X, f.Frame.Y, f.Frame.W, f.Frame.H)
+		fmt.Fprintf(bw, "margin = Rect2(%d, %d, %d, %d)\n\n", source.X, source.Y, f.SourceSize.W-source.W, f.SourceSize.H-source.H)
+	}
+
+	fmt.Fprintf(bw, "[resource]\n")
+	fmt.Fprintf(bw, "animations = [")
+	for i, anim := range animations {
+		if i > 0 {
+			fmt.Fprintf(bw, ", ")
+		}
+
+		// The frames of an action share its delay: the speed is the one
+		// of a frame of duration 1.
+		speed := 0.0
+		if len(anim.Frames) > 0 && anim.Frames[0].Duration > 0 {
+			speed = 1000 / float64(anim.Frames[0].Duration)
+		}
+
+		fmt.Fprintf(bw, "{\n\"frames\": [")
+		for j, f := range anim.Frames {
+			if j > 0 {
+				fmt.Fprintf(bw, ", ")
+			}
+			fmt.Fprintf(bw, "{\n\"duration\": 1.0,\n\"texture\": SubResource(\"%s\")\n}", atlasID(f.Index))
+		}
+		fmt.Fprintf(bw, "],\n\"loop\": %t,\n\"name\": &%s,\n\"speed\": %s\n}", anim.Loop, strconv.Quote(anim.Name), godotFloat(speed))
+	}
+	fmt.Fprintf(bw, "]\n")
+
+	var canvas aseprite.Size
+	if len(data.Frames) > 0 {
+		canvas = data.Frames[0].SourceSize
+	}
+	o := origin(data)
+	fmt.Fprintf(bw, "metadata/offset = Vector2(%s, %s)\n",
+		godotFloat(float64(canvas.W)/2-float64(o.X)), godotFloat(float64(canvas.H)/2-float64(o.Y)))
+
+	return bw.Flush()
+}
+
+func atlasID(frame int) string {
+	return "AtlasTexture_" + strconv.Itoa(frame)
+}
+
+// godotFloat formats f as the Godot resources do, with a decimal point.
+func godotFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+
+	return s
+}
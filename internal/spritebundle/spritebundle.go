@@ -0,0 +1,93 @@
+// Package spritebundle exports the sprite sheets of the aseprite package
+// to the formats of other game engines: a Godot SpriteFrames resource and
+// a JSON description for a Unity importer, with an animation per action
+// and direction of the sprite.
+package spritebundle
+
+import (
+	"strconv"
+
+	"github.com/project-midgard/midgarts/internal/aseprite"
+	"github.com/project-midgard/midgarts/pkg/character"
+)
+
+// directionNames are the suffixes of the animations, by
+// character.Direction.
+var directionNames = [character.DirectionCount]string{"s", "sw", "w", "nw", "n", "ne", "e", "se"}
+
+// groupNames are the names of the groups of 8 actions, one per direction,
+// of the sprites of the players and of the monsters, found by the number
+// of actions.
+var groupNames = map[int][]string{
+	// See actionindex.
+	13 * character.DirectionCount: {
+		"idle", "walk", "sit", "pick", "standby", "attack1", "hurt",
+		"freeze1", "dead", "freeze2", "attack2", "attack3", "cast",
+	},
+	5 * character.DirectionCount: {"idle", "walk", "attack", "hurt", "dead"},
+}
+
+// Frame is a frame of an animation.
+type Frame struct {
+	// Index is the index of the frame in the sheet data.
+	Index int
+	// Duration is the duration of the frame, in milliseconds.
+	Duration int
+}
+
+// Animation is an action of the sprite.
+type Animation struct {
+	Name   string
+	Action int
+	Frames []Frame
+	Loop   bool
+}
+
+// Animations returns the animations of the frame tags of data, the
+// actions of a sprite of actionCount actions, named after their action
+// and direction, e.g. "walk_sw", when the layout of the actions is known
+// and "action_<action>" otherwise.
+func Animations(data aseprite.Data, actionCount int) []Animation {
+	var animations []Animation
+	for _, tag := range data.Meta.FrameTags {
+		a, ok := aseprite.TagAction(tag.Name)
+		if !ok {
+			continue
+		}
+
+		anim := Animation{Name: ActionName(a, actionCount), Action: a, Loop: true}
+		if groups := groupNames[actionCount]; groups != nil && groups[a/character.DirectionCount] == "dead" {
+			anim.Loop = false
+		}
+		for i := tag.From; i <= tag.To && i < len(data.Frames); i++ {
+			anim.Frames = append(anim.Frames, Frame{Index: i, Duration: data.Frames[i].Duration})
+		}
+
+		animations = append(animations, anim)
+	}
+
+	return animations
+}
+
+// ActionName returns the name of the action a of a sprite of actionCount
+// actions.
+func ActionName(a, actionCount int) string {
+	groups := groupNames[actionCount]
+	if groups == nil || a < 0 || a >= actionCount {
+		return "action_" + strconv.Itoa(a)
+	}
+
+	return groups[a/character.DirectionCount] + "_" + directionNames[a%character.DirectionCount]
+}
+
+// origin returns the origin of the sprite, the character feet, in the
+// canvas shared by the frames.
+func origin(data aseprite.Data) aseprite.Point {
+	for _, s := range data.Meta.Slices {
+		if s.Name == aseprite.OriginSlice && len(s.Keys) > 0 {
+			return s.Keys[0].Pivot
+		}
+	}
+
+	return aseprite.Point{}
+}
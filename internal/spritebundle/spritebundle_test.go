@@ -0,0 +1,84 @@
+package spritebundle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/aseprite"
+)
+
+// newData returns the data of a 6x4 canvas, its origin at (1, 3), of the
+// actions 0 and 66 of a player sprite.
+func newData() aseprite.Data {
+	canvas := aseprite.Size{W: 6, H: 4}
+	return aseprite.Data{
+		Frames: []aseprite.Frame{
+			{Frame: aseprite.Rect{X: 0, Y: 0, W: 2, H: 2}, SpriteSourceSize: aseprite.Rect{X: 0, Y: 2, W: 2, H: 2}, SourceSize: canvas, Duration: 150},
+			{Frame: aseprite.Rect{X: 2, Y: 0, W: 2, H: 2}, SpriteSourceSize: aseprite.Rect{X: 4, Y: 0, W: 2, H: 2}, SourceSize: canvas, Duration: 150},
+			{Frame: aseprite.Rect{X: 4, Y: 0, W: 1, H: 1}, SpriteSourceSize: aseprite.Rect{W: 1, H: 1}, SourceSize: canvas, Duration: 100},
+		},
+		Meta: aseprite.Meta{
+			Size: aseprite.Size{W: 5, H: 2},
+			FrameTags: []aseprite.FrameTag{
+				{Name: aseprite.ActionTag(0), From: 0, To: 1},
+				{Name: aseprite.ActionTag(66), From: 2, To: 2},
+			},
+			Slices: []aseprite.Slice{{
+				Name: aseprite.OriginSlice,
+				Keys: []aseprite.SliceKey{{Pivot: aseprite.Point{X: 1, Y: 3}}},
+			}},
+		},
+	}
+}
+
+func TestActionName(t *testing.T) {
+	assert.Equal(t, "idle_s", ActionName(0, 104))
+	assert.Equal(t, "walk_sw", ActionName(9, 104))
+	assert.Equal(t, "cast_se", ActionName(103, 104))
+	assert.Equal(t, "dead_n", ActionName(36, 40))
+	assert.Equal(t, "action_3", ActionName(3, 12))
+	assert.Equal(t, "action_104", ActionName(104, 104))
+}
+
+func TestAnimations(t *testing.T) {
+	animations := Animations(newData(), 104)
+
+	assert.Equal(t, []Animation{
+		{Name: "idle_s", Action: 0, Loop: true, Frames: []Frame{{Index: 0, Duration: 150}, {Index: 1, Duration: 150}}},
+		{Name: "dead_w", Action: 66, Loop: false, Frames: []Frame{{Index: 2, Duration: 100}}},
+	}, animations)
+}
+
+func TestWriteGodot(t *testing.T) {
+	data := newData()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteGodot(&buf, data, Animations(data, 104), "res://poring.png"))
+
+	res := buf.String()
+	assert.Contains(t, res, `[gd_resource type="SpriteFrames" load_steps=5 format=3]`)
+	assert.Contains(t, res, `[ext_resource type="Texture2D" path="res://poring.png" id="1"]`)
+	// The second frame is placed back at the top right of the canvas.
+	assert.Contains(t, res, "[sub_resource type=\"AtlasTexture\" id=\"AtlasTexture_1\"]\natlas = ExtResource(\"1\")\nregion = Rect2(2, 0, 2, 2)\nmargin = Rect2(4, 0, 4, 2)\n")
+	assert.Contains(t, res, "\"loop\": true,\n\"name\": &\"idle_s\",\n\"speed\": 6.666666666666667\n")
+	assert.Contains(t, res, "\"loop\": false,\n\"name\": &\"dead_w\",\n\"speed\": 10.0\n")
+	assert.Contains(t, res, "metadata/offset = Vector2(2.0, -1.0)\n")
+}
+
+func TestNewUnitySheet(t *testing.T) {
+	data := newData()
+	sheet := NewUnitySheet(data, Animations(data, 104), "poring.png")
+
+	require.Len(t, sheet.Sprites, 3)
+	// From the bottom of the 2 pixels high texture.
+	assert.Equal(t, UnityRect{X: 2, Y: 0, Width: 2, Height: 2}, sheet.Sprites[1].Rect)
+	assert.Equal(t, UnityRect{X: 4, Y: 1, Width: 1, Height: 1}, sheet.Sprites[2].Rect)
+	// The origin is at the center of the first frame.
+	assert.Equal(t, [2]float32{0.5, 0.5}, sheet.Sprites[0].Pivot)
+
+	require.Len(t, sheet.Clips, 2)
+	assert.Equal(t, UnityClip{Name: "dead_w", Frames: []UnityClipFrame{{Sprite: 2, Duration: 0.1}}}, sheet.Clips[1])
+}
@@ -0,0 +1,83 @@
+package spritebundle
+
+import (
+	"strconv"
+
+	"github.com/project-midgard/midgarts/internal/aseprite"
+)
+
+// UnitySheet describes a sprite sheet as a Unity importer slices it: the
+// sprites of the texture in its coordinates, from the bottom left corner,
+// with their pivot, and the animation clips playing them.
+type UnitySheet struct {
+	Texture string `json:"texture"`
+	// Width and Height are the size of the texture, in pixels.
+	Width   int           `json:"width"`
+	Height  int           `json:"height"`
+	Sprites []UnitySprite `json:"sprites"`
+	Clips   []UnityClip   `json:"clips"`
+}
+
+type UnityRect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type UnitySprite struct {
+	Name string    `json:"name"`
+	Rect UnityRect `json:"rect"`
+	// Pivot is the origin of the sprite, the character feet, relative to
+	// the sprite rectangle: from (0, 0) at its bottom left corner to
+	// (1, 1) at its top right one, as Sprite.Create takes it.
+	Pivot [2]float32 `json:"pivot"`
+}
+
+type UnityClipFrame struct {
+	// Sprite is the index of the sprite of the frame.
+	Sprite int `json:"sprite"`
+	// Duration is the duration of the frame, in seconds.
+	Duration float32 `json:"duration"`
+}
+
+type UnityClip struct {
+	Name   string           `json:"name"`
+	Loop   bool             `json:"loop"`
+	Frames []UnityClipFrame `json:"frames"`
+}
+
+// NewUnitySheet returns the Unity description of the animations of data,
+// the sheet image being texture.
+func NewUnitySheet(data aseprite.Data, animations []Animation, texture string) *UnitySheet {
+	sheet := &UnitySheet{
+		Texture: texture,
+		Width:   data.Meta.Size.W,
+		Height:  data.Meta.Size.H,
+		Sprites: make([]UnitySprite, 0, len(data.Frames)),
+		Clips:   make([]UnityClip, 0, len(animations)),
+	}
+
+	o := origin(data)
+	for i, f := range data.Frames {
+		source := f.SpriteSourceSize
+		sheet.Sprites = append(sheet.Sprites, UnitySprite{
+			Name: "frame_" + strconv.Itoa(i),
+			Rect: UnityRect{X: f.Frame.X, Y: data.Meta.Size.H - f.Frame.Y - f.Frame.H, Width: f.Frame.W, Height: f.Frame.H},
+			Pivot: [2]float32{
+				float32(o.X-source.X) / float32(source.W),
+				1 - float32(o.Y-source.Y)/float32(source.H),
+			},
+		})
+	}
+
+	for _, anim := range animations {
+		clip := UnityClip{Name: anim.Name, Loop: anim.Loop, Frames: make([]UnityClipFrame, 0, len(anim.Frames))}
+		for _, f := range anim.Frames {
+			clip.Frames = append(clip.Frames, UnityClipFrame{Sprite: f.Index, Duration: float32(f.Duration) / 1000})
+		}
+		sheet.Clips = append(sheet.Clips, clip)
+	}
+
+	return sheet
+}
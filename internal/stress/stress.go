@@ -0,0 +1,173 @@
+// Package stress measures the client under load: a Crowd of animated
+// characters of mixed jobs loads the renderer, and a Recorder reports the
+// frame rate, the frame time percentiles and the allocation rate the
+// client sustains, so the renderer changes are compared objectively.
+package stress
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+)
+
+// Crowd is a crowd of players walking squares around their spot, a third
+// of them attacking in place.
+type Crowd struct {
+	Characters []*entity.Character
+	spots      []mgl32.Vec3
+}
+
+// NewCrowd returns a crowd of n players of mixed jobs, genders and hair,
+// standing in a square around center, 2 cells apart.
+func NewCrowd(factory *entity.Factory, n int, center mgl32.Vec3) *Crowd {
+	var jobs []jobspriteid.Type
+	for _, job := range jobspriteid.All() {
+		if character.JobSpriteNameTable[job] != "" {
+			jobs = append(jobs, job)
+		}
+	}
+
+	side := int(math.Ceil(math.Sqrt(float64(n))))
+	origin := center.Sub(mgl32.Vec3{float32(side - 1), float32(side - 1), 0})
+
+	c := &Crowd{}
+	for i := 0; i < n; i++ {
+		job := jobs[i%len(jobs)]
+		gender := character.Male
+		if (i/len(jobs))%2 == 1 || job == jobspriteid.Dancer {
+			gender = character.Female
+		}
+		if job == jobspriteid.Bard {
+			gender = character.Male
+		}
+
+		char := factory.CreatePlayer(gender, job, character.HeadIndex(1+i%20))
+		spot := origin.Add(mgl32.Vec3{float32(2 * (i % side)), float32(2 * (i / side)), 0})
+		char.SetPosition(spot)
+		if i%3 == 0 {
+			char.SetState(statetype.Attacking)
+		}
+
+		c.Characters = append(c.Characters, char)
+		c.spots = append(c.spots, spot)
+	}
+
+	return c
+}
+
+// Update starts a new square walk for the walkers done with the last
+// one.
+func (c *Crowd) Update() {
+	for i, char := range c.Characters {
+		if i%3 == 0 || char.Walk != nil {
+			continue
+		}
+
+		spot := c.spots[i]
+		char.WalkTo(
+			mgl32.Vec2{spot.X() + 1, spot.Y()},
+			mgl32.Vec2{spot.X() + 1, spot.Y() + 1},
+			mgl32.Vec2{spot.X(), spot.Y() + 1},
+			mgl32.Vec2{spot.X(), spot.Y()},
+		)
+	}
+}
+
+// Report is the performance of the recorded frames.
+type Report struct {
+	Frames   int
+	Duration time.Duration
+	// FPS is the mean frame rate.
+	FPS float64
+	// P50, P95 and P99 are frame time percentiles, Max the longest frame.
+	P50, P95, P99, Max time.Duration
+	// AllocsPerFrame and AllocBytesPerSecond are the heap allocation rates.
+	AllocsPerFrame      float64
+	AllocBytesPerSecond float64
+	// GCs is the number of garbage collections.
+	GCs uint32
+}
+
+// Recorder records the frame times and the allocations of the client.
+type Recorder struct {
+	// Warmup is the time, from the first frame, during which the frames
+	// are not recorded, e.g. while the sprites load.
+	Warmup time.Duration
+
+	warm     time.Duration
+	frames   []time.Duration
+	duration time.Duration
+	start    runtime.MemStats
+
+	readMemStats func(*runtime.MemStats)
+}
+
+// NewRecorder returns a recorder skipping the frames of warmup.
+func NewRecorder(warmup time.Duration) *Recorder {
+	return &Recorder{Warmup: warmup, readMemStats: runtime.ReadMemStats}
+}
+
+// Frame records a frame of d.
+func (r *Recorder) Frame(d time.Duration) {
+	if r.warm < r.Warmup {
+		if r.warm += d; r.warm >= r.Warmup {
+			r.readMemStats(&r.start)
+		}
+		return
+	}
+	if r.Warmup == 0 && len(r.frames) == 0 {
+		r.readMemStats(&r.start)
+	}
+
+	r.frames = append(r.frames, d)
+	r.duration += d
+}
+
+// Duration returns the time of the recorded frames.
+func (r *Recorder) Duration() time.Duration {
+	return r.duration
+}
+
+// Report returns the performance of the recorded frames.
+func (r *Recorder) Report() Report {
+	report := Report{Frames: len(r.frames), Duration: r.duration}
+	if len(r.frames) == 0 || r.duration <= 0 {
+		return report
+	}
+
+	var end runtime.MemStats
+	r.readMemStats(&end)
+
+	sorted := append([]time.Duration(nil), r.frames...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	seconds := r.duration.Seconds()
+	report.FPS = float64(len(r.frames)) / seconds
+	report.P50 = percentile(sorted, 0.50)
+	report.P95 = percentile(sorted, 0.95)
+	report.P99 = percentile(sorted, 0.99)
+	report.Max = sorted[len(sorted)-1]
+	report.AllocsPerFrame = float64(end.Mallocs-r.start.Mallocs) / float64(len(r.frames))
+	report.AllocBytesPerSecond = float64(end.TotalAlloc-r.start.TotalAlloc) / seconds
+	report.GCs = end.NumGC - r.start.NumGC
+
+	return report
+}
+
+// percentile returns the nearest-rank percentile p of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	return sorted[i]
+}
@@ -0,0 +1,73 @@
+package stress
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+)
+
+func TestNewCrowd(t *testing.T) {
+	c := NewCrowd(entity.NewFactory(), 50, mgl32.Vec3{0, 40, 0})
+	require.Len(t, c.Characters, 50)
+
+	jobs := map[jobspriteid.Type]bool{}
+	for _, char := range c.Characters {
+		jobs[char.JobSpriteID] = true
+		if char.JobSpriteID == jobspriteid.Dancer {
+			assert.Equal(t, character.Female, char.Gender)
+		}
+	}
+	assert.Greater(t, len(jobs), 20)
+
+	// A square of 8 by 8 spots around the center.
+	assert.Equal(t, mgl32.Vec3{-7, 33, 0}, c.Characters[0].Position())
+	assert.Equal(t, mgl32.Vec3{-5, 33, 0}, c.Characters[1].Position())
+	assert.Equal(t, mgl32.Vec3{-7, 35, 0}, c.Characters[8].Position())
+
+	assert.Equal(t, statetype.Attacking, c.Characters[0].State)
+	c.Update()
+	assert.Nil(t, c.Characters[0].Walk)
+	assert.NotNil(t, c.Characters[1].Walk)
+}
+
+func TestRecorder(t *testing.T) {
+	var mallocs uint64
+	r := NewRecorder(100 * time.Millisecond)
+	r.readMemStats = func(m *runtime.MemStats) {
+		m.Mallocs, m.TotalAlloc = mallocs, mallocs*64
+	}
+
+	// Skipped by the warmup.
+	r.Frame(60 * time.Millisecond)
+	r.Frame(60 * time.Millisecond)
+	assert.Equal(t, time.Duration(0), r.Duration())
+
+	for i := 1; i <= 100; i++ {
+		mallocs += 10
+		r.Frame(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 5050*time.Millisecond, r.Duration())
+
+	report := r.Report()
+	assert.Equal(t, 100, report.Frames)
+	assert.InDelta(t, 100/5.05, report.FPS, 1e-9)
+	assert.Equal(t, 50*time.Millisecond, report.P50)
+	assert.Equal(t, 95*time.Millisecond, report.P95)
+	assert.Equal(t, 99*time.Millisecond, report.P99)
+	assert.Equal(t, 100*time.Millisecond, report.Max)
+	assert.Equal(t, float64(10), report.AllocsPerFrame)
+	assert.InDelta(t, 64000/5.05, report.AllocBytesPerSecond, 1e-6)
+}
+
+func TestRecorderEmpty(t *testing.T) {
+	assert.Equal(t, Report{}, NewRecorder(time.Second).Report())
+}
@@ -0,0 +1,89 @@
+package system
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/animation"
+	"github.com/project-midgard/midgarts/internal/audio"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+)
+
+// ActionSoundSystem plays the sounds of the ACT frames of the characters,
+// such as the swings of the attacks and the cries of the monsters hit, at
+// the frames of their animations they are set on (see audio.FrameSound).
+// The frames of the body and of the weapon are heard. It hooks the frames
+// of the animations (see component.AnimationEvents.OnEachFrame) as the
+// characters are added, the action system advancing them, and unhooks
+// them as they are removed.
+type ActionSoundSystem struct {
+	Ground *Ground
+	// Volume is the gain of the sounds next to the listener, from 0 to 1.
+	Volume float32
+	// Play, when set, plays a sound of char, at a position in map cells
+	// (see Ground.Cell).
+	Play func(char *entity.Character, sound audio.Emitter)
+
+	// unsubscribes remove the hooks of the characters added.
+	unsubscribes map[uint64]func()
+}
+
+// NewActionSoundSystem returns the system of the sounds of the characters
+// on ground, at full volume.
+func NewActionSoundSystem(ground *Ground) *ActionSoundSystem {
+	return &ActionSoundSystem{Ground: ground, Volume: 1, unsubscribes: map[uint64]func(){}}
+}
+
+func (s *ActionSoundSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o.(*entity.Character))
+}
+
+// Add plays the sounds of the frames of char.
+func (s *ActionSoundSystem) Add(char *entity.Character) {
+	if _, ok := s.unsubscribes[char.ID()]; ok {
+		return
+	}
+
+	s.unsubscribes[char.ID()] = char.Events.OnEachFrame(func(_ statetype.Type, frame int) {
+		s.frame(char, frame)
+	})
+}
+
+func (s *ActionSoundSystem) Remove(e ecs.BasicEntity) {
+	if unsubscribe, ok := s.unsubscribes[e.ID()]; ok {
+		unsubscribe()
+		delete(s.unsubscribes, e.ID())
+	}
+}
+
+func (s *ActionSoundSystem) Update(dt float32) {}
+
+// frame plays the sounds of the frame n of the current action of char.
+func (s *ActionSoundSystem) frame(char *entity.Character, n int) {
+	if char.CharacterAttachmentComponent == nil || s.Play == nil {
+		return
+	}
+
+	for _, elem := range [...]character.AttachmentType{character.AttachmentBody, character.AttachmentWeapon} {
+		files, ok := char.Files[elem]
+		if !ok || files.ACT == nil || len(files.ACT.Actions) == 0 {
+			continue
+		}
+
+		// The 8 directions of an action have the same frames.
+		action := files.ACT.Actions[animation.ActionIndex(char, pkgcharacter.DirectionResolver{}, len(files.ACT.Actions))]
+		if n >= len(action.Frames) {
+			continue
+		}
+		name, ok := audio.FrameSound(files.ACT, action.Frames[n])
+		if !ok {
+			continue
+		}
+
+		x, y := s.Ground.Cell(char.Position())
+		s.Play(char, audio.Emitter{Name: name, Position: mgl32.Vec2{x, y}, Volume: s.Volume})
+	}
+}
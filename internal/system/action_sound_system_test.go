@@ -0,0 +1,75 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+// soundACT returns 16 actions of a frame per index of frames, in the
+// sounds of sound, -1 for none.
+func soundACT(sound string, frames ...int32) *act.ActionFile {
+	f := &act.ActionFile{Sounds: []string{sound}}
+	for i := 0; i < 16; i++ {
+		action := &act.Action{}
+		for _, s := range frames {
+			action.Frames = append(action.Frames, &act.ActionFrame{Sound: s})
+		}
+		f.Actions = append(f.Actions, action)
+	}
+
+	return f
+}
+
+func TestActionSoundSystem(t *testing.T) {
+	s := NewActionSoundSystem(newSlopeGround())
+	s.Volume = 0.5
+	var sounds []audio.Emitter
+	s.Play = func(char *entity.Character, sound audio.Emitter) {
+		sounds = append(sounds, sound)
+	}
+
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	char.SetPosition(mgl32.Vec3{1.5, 0.5, 0})
+	char.SetCharacterAttachmentComponent(&component.CharacterAttachmentComponent{
+		Files: map[character.AttachmentType]grf.ActionSpriteFilePair{
+			character.AttachmentBody:   {ACT: soundACT("knight_attack.wav", -1, 0, -1)},
+			character.AttachmentWeapon: {ACT: soundACT("_hit_sword.wav", -1, -1, 0)},
+			character.AttachmentHead:   {ACT: soundACT("head.wav", 0, 0, 0)},
+		},
+	})
+	s.Add(char)
+	s.Add(char)
+
+	// As the action system animates the attack.
+	char.SetState(statetype.Attacking)
+	char.Events.Restart(char.State)
+	char.Events.Advance(char.State, 1, 3, false)
+	x, y := s.Ground.Cell(char.Position())
+	assert.Equal(t, []audio.Emitter{{Name: "knight_attack.wav", Position: mgl32.Vec2{x, y}, Volume: 0.5}}, sounds)
+
+	char.Events.Advance(char.State, 2, 3, false)
+	assert.Len(t, sounds, 2)
+	assert.Equal(t, "_hit_sword.wav", sounds[1].Name)
+
+	s.Remove(*char.BasicEntity)
+	char.Events.Restart(char.State)
+	char.Events.Advance(char.State, 2, 3, false)
+	assert.Len(t, sounds, 2)
+
+	// Added again, its frames are heard once.
+	s.Add(char)
+	char.Events.Restart(char.State)
+	char.Events.Advance(char.State, 2, 3, false)
+	assert.Len(t, sounds, 4)
+}
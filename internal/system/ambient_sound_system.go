@@ -0,0 +1,35 @@
+package system
+
+import (
+	"github.com/EngoEngine/ecs"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+)
+
+// AmbientSoundSystem loops the ambient sounds of the map around the
+// listener, such as the waterfalls and the birds of its RSW (see
+// audio.Ambience).
+type AmbientSoundSystem struct {
+	Ambience *audio.Ambience
+	// Listener returns the listener of the frame.
+	Listener func() audio.Listener
+	// Play, when set, is handed the sounds heard every update: to start
+	// them when Start is set, else to change their gains.
+	Play func(sound audio.Sound)
+}
+
+// NewAmbientSoundSystem returns the system looping the sounds of emitters,
+// heard by listener.
+func NewAmbientSoundSystem(emitters []audio.Emitter, listener func() audio.Listener) *AmbientSoundSystem {
+	return &AmbientSoundSystem{Ambience: audio.NewAmbience(emitters), Listener: listener}
+}
+
+func (s *AmbientSoundSystem) Update(dt float32) {
+	for _, sound := range s.Ambience.Update(s.Listener(), dt) {
+		if s.Play != nil {
+			s.Play(sound)
+		}
+	}
+}
+
+func (s *AmbientSoundSystem) Remove(ecs.BasicEntity) {}
@@ -0,0 +1,30 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+)
+
+func TestAmbientSoundSystem(t *testing.T) {
+	listener := audio.NewListener(10, 10, 0)
+	s := NewAmbientSoundSystem([]audio.Emitter{
+		{Name: "fountain.wav", Position: mgl32.Vec2{12, 10}, Volume: 1},
+		{Name: "forest.wav", Position: mgl32.Vec2{100, 100}, Volume: 1},
+	}, func() audio.Listener { return listener })
+
+	var played []audio.Sound
+	s.Play = func(sound audio.Sound) { played = append(played, sound) }
+
+	s.Update(0.1)
+	s.Update(0.1)
+	if assert.Len(t, played, 2) {
+		assert.Equal(t, "fountain.wav", played[0].Name)
+		assert.True(t, played[0].Start)
+		assert.False(t, played[1].Start)
+		assert.Greater(t, played[0].Gains.Right, played[0].Gains.Left, "on the east")
+	}
+}
@@ -0,0 +1,115 @@
+package system
+
+import (
+	"context"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/hotreload"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+// AssetReloadSystem reloads the character sprites overridden in the data
+// folder of fs (see vfs.Open) when they change on disk. The new
+// sprite images get new textures on the next render, so edits show live.
+type AssetReloadSystem struct {
+	// done is closed with the context of the system, stopping the reloads.
+	done       <-chan struct{}
+	log        zerolog.Logger
+	fs         vfs.FS
+	watcher    *hotreload.Watcher
+	characters *entity.CharacterStore
+}
+
+func NewAssetReloadSystem(ctx context.Context, fs vfs.FS, watcher *hotreload.Watcher) *AssetReloadSystem {
+	return &AssetReloadSystem{
+		done:       ctx.Done(),
+		log:        logging.For(ctx, logging.AssetReload),
+		fs:         fs,
+		watcher:    watcher,
+		characters: entity.NewCharacterStore(),
+	}
+}
+
+func (s *AssetReloadSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o.(*entity.Character))
+}
+
+func (s *AssetReloadSystem) Add(char *entity.Character) {
+	s.characters.Add(char)
+
+	// The attachments may still be loading, see CharacterRenderSystem.Add.
+	paths, err := component.CharacterAttachmentPaths(char.AttachmentConfig())
+	if err != nil {
+		s.log.Warn().Err(err).Uint64("entity", char.ID()).Msg("could not watch character")
+		return
+	}
+
+	for _, path := range paths {
+		s.watcher.Watch(path, append(s.fs.OverlayPaths(path+".act"), s.fs.OverlayPaths(path+".spr")...)...)
+	}
+}
+
+// Update applies the pending changes, until the context of the system is
+// done. It runs on the main thread, as the textures are uploaded by the
+// render system.
+func (s *AssetReloadSystem) Update(dt float32) {
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		select {
+		case path := <-s.watcher.Changes():
+			s.reload(path)
+		default:
+			return
+		}
+	}
+}
+
+func (s *AssetReloadSystem) Remove(e ecs.BasicEntity) {
+	s.characters.Remove(e.ID())
+}
+
+func (s *AssetReloadSystem) reload(path string) {
+	// The parsers panic on truncated files.
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Warn().Str("path", path).Msgf("could not reload sprite: %v", r)
+		}
+	}()
+
+	// Read on the main thread, between two frames.
+	files, err := s.fs.GetSpriteFilesContext(context.Background(), path)
+	if err != nil {
+		// Usually a file being written, the next change reloads it.
+		s.log.Warn().Err(err).Str("path", path).Msg("could not reload sprite")
+		return
+	}
+
+	timelines := act.NewTimelines(files.ACT)
+	for _, char := range s.characters.All() {
+		if char.CharacterAttachmentComponent == nil {
+			continue
+		}
+
+		for elem, p := range char.Paths {
+			if p == path {
+				char.Files[elem] = files
+				if char.Timelines != nil {
+					char.Timelines[elem] = timelines
+				}
+			}
+		}
+	}
+
+	s.log.Info().Str("path", path).Msg("sprite reloaded")
+}
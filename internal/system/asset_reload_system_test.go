@@ -0,0 +1,41 @@
+package system
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/hotreload"
+)
+
+func TestAssetReloadSystemCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "novice.spr")
+	require.NoError(t, ioutil.WriteFile(path, []byte("v1"), 0644))
+
+	watcher := hotreload.NewWatcher(time.Millisecond)
+	defer watcher.Close()
+	watcher.Watch("novice", path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewAssetReloadSystem(ctx, nil, watcher)
+	cancel()
+
+	// A later modification time, whatever the resolution of the file
+	// system.
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	require.Eventually(t, func() bool { return len(watcher.Changes()) > 0 }, time.Second, time.Millisecond)
+
+	s.Update(1.0 / 60)
+	assert.NotEmpty(t, watcher.Changes(), "the change was reloaded after the context was done")
+
+	// Without archive, the reload fails, but the change is applied.
+	NewAssetReloadSystem(context.Background(), nil, watcher).Update(1.0 / 60)
+	assert.Empty(t, watcher.Changes())
+}
@@ -0,0 +1,147 @@
+package system
+
+import (
+	"context"
+	"sync"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+	"github.com/project-midgard/midgarts/internal/logging"
+)
+
+// AudioSystem plays the sounds of a scene with a mixer: the sound effects
+// faded and panned as the listener hears them from where they are played
+// (see audio.Listener.Gains), and the music of the map. The sounds are
+// logged at the trace level.
+//
+// The sounds requested are played by Update, decoding them on the mixer:
+// it updates concurrently with the systems of service.Worlds.Concurrent,
+// which may request sounds meanwhile.
+type AudioSystem struct {
+	// Mixer plays the sounds; when nil, they are only logged.
+	Mixer audio.Mixer
+	// Listener returns the listener of the frame; when nil, as before the
+	// scene sets it, no sound effect is heard.
+	Listener func() audio.Listener
+	// Musics are the music of the maps (see PlayBGM).
+	Musics audio.MusicTable
+
+	log zerolog.Logger
+
+	// mu guards the sounds and the music requested.
+	mu      sync.Mutex
+	effects []effectRequest
+	music   string
+	// playMusic is true when music is to be played by the next Update.
+	playMusic bool
+
+	// failed are the sounds the mixer could not play, not tried again. It
+	// is used by Update only.
+	failed map[string]bool
+}
+
+// effectRequest is a sound effect requested.
+type effectRequest struct {
+	name  string
+	gains audio.Gains
+}
+
+// NewAudioSystem returns the system playing the sounds with mixer, heard
+// by listener, the music of the maps of musics, logging to the logger of
+// ctx (see logging.For).
+func NewAudioSystem(ctx context.Context, mixer audio.Mixer, musics audio.MusicTable, listener func() audio.Listener) *AudioSystem {
+	return &AudioSystem{
+		Mixer:    mixer,
+		Listener: listener,
+		Musics:   musics,
+		log:      logging.For(ctx, logging.Audio),
+		failed:   map[string]bool{},
+	}
+}
+
+// PlayEffect plays once the sound effect of name, a .wav file of data/wav/,
+// at full volume at position, in map cells (see Ground.Cell).
+func (s *AudioSystem) PlayEffect(name string, position mgl32.Vec2) {
+	s.Play(audio.Emitter{Name: name, Position: position, Volume: 1})
+}
+
+// Play plays once the sound of e, unless the listener does not hear it.
+func (s *AudioSystem) Play(e audio.Emitter) {
+	if s.Listener == nil {
+		return
+	}
+	gains := s.Listener().Gains(e)
+	if gains == (audio.Gains{}) {
+		return
+	}
+
+	s.play(e.Name, gains)
+}
+
+// PlayAmbient plays the ambient sounds as they start (see
+// AmbientSoundSystem.Play); their gains are not followed once started.
+func (s *AudioSystem) PlayAmbient(sound audio.Sound) {
+	if sound.Start {
+		s.play(sound.Name, sound.Gains)
+	}
+}
+
+func (s *AudioSystem) play(name string, gains audio.Gains) {
+	s.log.Trace().Str("sound", name).Float32("left", gains.Left).Float32("right", gains.Right).Msg("sound")
+	if s.Mixer == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.effects = append(s.effects, effectRequest{name: name, gains: gains})
+	s.mu.Unlock()
+}
+
+// PlayBGM loops the music of the map mapName, going on when it is the one
+// playing, and stops the music when the map has none.
+func (s *AudioSystem) PlayBGM(mapName string) {
+	music, _ := s.Musics.Music(mapName)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if music == s.music {
+		return
+	}
+	s.music, s.playMusic = music, true
+
+	s.log.Debug().Str("map", mapName).Str("music", music).Msg("music")
+}
+
+// Update plays the sounds and the music requested since the last Update.
+func (s *AudioSystem) Update(dt float32) {
+	s.mu.Lock()
+	effects, music, playMusic := s.effects, s.music, s.playMusic
+	s.effects, s.playMusic = nil, false
+	s.mu.Unlock()
+
+	if s.Mixer == nil {
+		return
+	}
+
+	if playMusic {
+		if err := s.Mixer.PlayMusic(music); err != nil {
+			s.log.Warn().Err(err).Str("music", music).Msg("could not play music")
+		}
+	}
+
+	for _, e := range effects {
+		if s.failed[e.name] {
+			continue
+		}
+		if err := s.Mixer.PlayEffect(e.name, e.gains); err != nil {
+			s.failed[e.name] = true
+			s.log.Debug().Err(err).Str("sound", e.name).Msg("could not play sound")
+		}
+	}
+}
+
+func (s *AudioSystem) Remove(ecs.BasicEntity) {}
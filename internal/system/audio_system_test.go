@@ -0,0 +1,73 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+)
+
+// mixer records the sounds played, failing to play the ones of missing.
+type mixer struct {
+	effects []string
+	musics  []string
+	missing map[string]bool
+}
+
+func (m *mixer) PlayEffect(name string, gains audio.Gains) error {
+	if m.missing[name] {
+		return assert.AnError
+	}
+	m.effects = append(m.effects, name)
+	return nil
+}
+
+func (m *mixer) PlayMusic(path string) error {
+	m.musics = append(m.musics, path)
+	return nil
+}
+
+func TestAudioSystem(t *testing.T) {
+	m := &mixer{missing: map[string]bool{"missing.wav": true}}
+	s := NewAudioSystem(context.Background(), m, audio.MusicTable{"prontera": "bgm/08.mp3", "prt_fild08": "bgm/08.mp3", "izlude": "bgm/26.mp3"}, func() audio.Listener {
+		return audio.NewListener(100, 100, 0)
+	})
+
+	s.PlayEffect("_hit_sword.wav", mgl32.Vec2{102, 100})
+	// Out of hearing.
+	s.PlayEffect("far.wav", mgl32.Vec2{100, 200})
+	s.PlayAmbient(audio.Sound{Emitter: audio.Emitter{Name: "birds.wav"}, Gains: audio.Gains{Left: 0.5, Right: 0.5}, Start: true})
+	s.PlayAmbient(audio.Sound{Emitter: audio.Emitter{Name: "birds.wav"}, Gains: audio.Gains{Left: 0.4, Right: 0.5}})
+	// The sounds are played by Update.
+	assert.Empty(t, m.effects)
+	s.Update(0.02)
+	assert.Equal(t, []string{"_hit_sword.wav", "birds.wav"}, m.effects)
+
+	// The sounds that failed are not tried again.
+	s.PlayEffect("missing.wav", mgl32.Vec2{100, 100})
+	s.Update(0.02)
+	delete(m.missing, "missing.wav")
+	s.PlayEffect("missing.wav", mgl32.Vec2{100, 100})
+	s.Update(0.02)
+	assert.Len(t, m.effects, 2)
+
+	// The music goes on over the maps sharing it.
+	s.PlayBGM("prontera")
+	s.Update(0.02)
+	s.PlayBGM("prt_fild08")
+	s.Update(0.02)
+	s.PlayBGM("izlude")
+	s.Update(0.02)
+	s.PlayBGM("geffen")
+	s.Update(0.02)
+	assert.Equal(t, []string{"bgm/08.mp3", "bgm/26.mp3", ""}, m.musics)
+
+	// Only the last music requested before an update is played.
+	s.PlayBGM("prontera")
+	s.PlayBGM("izlude")
+	s.Update(0.02)
+	assert.Equal(t, []string{"bgm/08.mp3", "bgm/26.mp3", "", "bgm/26.mp3"}, m.musics)
+}
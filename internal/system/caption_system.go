@@ -0,0 +1,132 @@
+package system
+
+import (
+	"image"
+	"image/draw"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/theme"
+)
+
+const (
+	// captionHeight is the height above the feet of the characters of the
+	// bottom of their captions, in sprite pixels.
+	captionHeight = 110
+	// captionPadding is the margin around the text of the captions, in
+	// pixels.
+	captionPadding = 3
+)
+
+// CaptionSystem draws texts above the characters, as their chat, for a
+// time.
+type CaptionSystem struct {
+	// Theme gives the colors and the scale of the texts said after it is
+	// set.
+	Theme theme.Theme
+
+	renderCommands  *opengl.RenderCommands
+	textureProvider graphic.TextureProvider
+	captions        []*caption
+}
+
+type caption struct {
+	char  *entity.Character
+	image *graphic.UniqueRGBA
+	left  time.Duration
+	// scale is the text scale of the theme it was drawn with.
+	scale float32
+}
+
+// NewCaptionSystem returns the system, drawing with commands.
+func NewCaptionSystem(textureProvider graphic.TextureProvider, commands *opengl.RenderCommands) *CaptionSystem {
+	return &CaptionSystem{Theme: theme.Default, renderCommands: commands, textureProvider: textureProvider}
+}
+
+// Say shows text above char for d, replacing what char said before.
+func (s *CaptionSystem) Say(char *entity.Character, text string, d time.Duration) {
+	s.remove(char.ID())
+	s.captions = append(s.captions, &caption{char: char, image: NewCaptionImage(text, s.Theme), left: d, scale: s.Theme.TextScale})
+}
+
+func (s *CaptionSystem) Update(dt float32) {
+	kept := s.captions[:0]
+	for _, c := range s.captions {
+		c.left -= time.Duration(float64(dt) * float64(time.Second))
+		if c.left <= 0 {
+			s.textureProvider.DeleteTexture(c.image)
+			continue
+		}
+		kept = append(kept, c)
+
+		texture, err := s.textureProvider.NewTextureFromRGBA(c.image, graphic.TextureSprite)
+		if err != nil {
+			continue
+		}
+
+		bounds := c.image.Bounds()
+		width := float32(bounds.Dx()) * geometry.OnePixelSize * c.scale
+		height := float32(bounds.Dy()) * geometry.OnePixelSize * c.scale
+		s.renderCommands.Sprites = append(s.renderCommands.Sprites, opengl.SpriteRenderCommand{
+			Scale:    [2]float32{1, 1},
+			Size:     mgl32.Vec2{width, height},
+			Position: c.char.Position(),
+			// Centered above the head, the offsets growing down.
+			Offset:  mgl32.Vec2{0, -captionHeight*geometry.OnePixelSize*c.char.SpriteScale() - height/2},
+			Texture: texture,
+		})
+	}
+	s.captions = kept
+}
+
+func (s *CaptionSystem) Remove(e ecs.BasicEntity) {
+	s.remove(e.ID())
+}
+
+func (s *CaptionSystem) remove(id uint64) {
+	for i, c := range s.captions {
+		if c.char.ID() == id {
+			s.textureProvider.DeleteTexture(c.image)
+			s.captions = append(s.captions[:i], s.captions[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close deletes the textures of the captions.
+func (s *CaptionSystem) Close() {
+	for _, c := range s.captions {
+		s.textureProvider.DeleteTexture(c.image)
+	}
+	s.captions = nil
+}
+
+// NewCaptionImage returns the image of a caption: text in the text color of
+// t on a box of its text background.
+func NewCaptionImage(text string, t theme.Theme) *graphic.UniqueRGBA {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+	metrics := face.Metrics()
+
+	img := graphic.NewUniqueRGBA(image.Rect(0, 0, width+2*captionPadding, metrics.Height.Ceil()+2*captionPadding))
+	draw.Draw(img, img.Bounds(), image.NewUniform(t.TextBackground), image.Point{}, draw.Src)
+
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(t.Text),
+		Face: face,
+		Dot:  fixed.P(captionPadding, captionPadding+metrics.Ascent.Ceil()),
+	}
+	d.DrawString(text)
+
+	return img
+}
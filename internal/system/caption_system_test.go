@@ -0,0 +1,53 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/golden"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/theme"
+)
+
+func TestNewCaptionImage(t *testing.T) {
+	img := NewCaptionImage("Hi", theme.Default)
+	// 7x13 glyphs, and the padding.
+	assert.Equal(t, 2*7+2*captionPadding, img.Bounds().Dx())
+	assert.Equal(t, 13+2*captionPadding, img.Bounds().Dy())
+	assert.Equal(t, theme.Default.TextBackground, img.RGBAAt(0, 0))
+
+	contrast, err := theme.New("", 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(255), NewCaptionImage("Hi", contrast).RGBAAt(0, 0).A)
+
+	golden.Assert(t, "caption", NewCaptionImage("Hello, Midgard!", theme.Default))
+	golden.Assert(t, "caption_high_contrast", NewCaptionImage("Hello, Midgard!", contrast))
+}
+
+func TestCaptionSystem(t *testing.T) {
+	commands := &opengl.RenderCommands{}
+	s := NewCaptionSystem(&staticTextures{}, commands)
+
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	s.Say(char, "Hello", time.Second)
+	s.Say(char, "Bye", time.Second)
+
+	s.Update(0.5)
+	assert.Len(t, commands.Sprites, 1, "the last text replaces the first")
+	size := commands.Sprites[0].Size
+
+	commands.Sprites = nil
+	s.Update(0.5)
+	assert.Empty(t, commands.Sprites)
+
+	s.Theme.TextScale = 2
+	s.Say(char, "Bye", time.Second)
+	s.Update(0.1)
+	assert.Equal(t, size.Mul(2), commands.Sprites[0].Size)
+}
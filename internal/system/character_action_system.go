@@ -1,17 +1,25 @@
 package system
 
 import (
-	"log"
-	"strconv"
+	"context"
 	"time"
 
 	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/rs/zerolog"
 
+	"github.com/project-midgard/midgarts/internal/animation"
+	"github.com/project-midgard/midgarts/internal/character"
 	"github.com/project-midgard/midgarts/internal/character/actionindex"
+	"github.com/project-midgard/midgarts/internal/character/actionplaymode"
+	"github.com/project-midgard/midgarts/internal/character/aspd"
 	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/character/walkpath"
 	"github.com/project-midgard/midgarts/internal/component"
 	"github.com/project-midgard/midgarts/internal/entity"
-	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/logging"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
 )
 
 type CharacterActionable interface {
@@ -20,29 +28,32 @@ type CharacterActionable interface {
 }
 
 type CharacterActionSystem struct {
-	grfFile *grf.File
+	// Clock times the starts and ends of the animations, the wall clock
+	// by default.
+	Clock animation.Clock
 
-	characters map[string]*entity.Character
+	log     zerolog.Logger
+	loading *characterLoader
+
+	characters *entity.CharacterStore
 }
 
-func NewCharacterActionSystem(grfFile *grf.File) *CharacterActionSystem {
+// NewCharacterActionSystem returns the system, reading the attachments of
+// the added characters from fs; ctx cancels their loading and carries its
+// logger (see logging.For).
+func NewCharacterActionSystem(ctx context.Context, fs vfs.FS) *CharacterActionSystem {
 	return &CharacterActionSystem{
-		grfFile,
-		map[string]*entity.Character{},
+		animation.WallClock{},
+		logging.For(ctx, logging.Action),
+		newCharacterLoader(ctx, fs),
+		entity.NewCharacterStore(),
 	}
 }
 
+// Add loads the attachments of char in the background; it is animated
+// once they are loaded.
 func (s *CharacterActionSystem) Add(char *entity.Character) {
-	cmp, e := component.NewCharacterAttachmentComponent(s.grfFile, component.CharacterAttachmentComponentConfig{
-		Gender:      char.Gender,
-		JobSpriteID: char.JobSpriteID,
-		HeadIndex:   char.HeadIndex,
-	})
-	if e != nil {
-		log.Fatal(e)
-	}
-	char.SetCharacterAttachmentComponent(cmp)
-	s.characters[strconv.Itoa(int(char.ID()))] = char
+	s.loading.Load(char)
 }
 
 func (s *CharacterActionSystem) AddByInterface(o ecs.Identifier) {
@@ -51,8 +62,21 @@ func (s *CharacterActionSystem) AddByInterface(o ecs.Identifier) {
 }
 
 func (s *CharacterActionSystem) Update(dt float32) {
-	for _, c := range s.characters {
-		now := time.Now()
+	for _, c := range s.loading.Ready() {
+		if c.err != nil {
+			// The attachments that did load are still set, see CharacterRenderSystem.Update.
+			s.log.Error().Err(c.err).Uint64("entity", c.char.ID()).Msg("could not load character attachments")
+		}
+		c.char.SetCharacterAttachmentComponent(c.cmp)
+		s.characters.Add(c.char)
+	}
+
+	for _, c := range s.characters.All() {
+		if c.Walk != nil {
+			walk(c, dt)
+		}
+
+		now := s.Clock.Now()
 		previousAnimationHasEnded := now.After(c.AnimationEndsAt)
 
 		stopPreviousAnimation := previousAnimationHasEnded
@@ -60,25 +84,66 @@ func (s *CharacterActionSystem) Update(dt float32) {
 			stopPreviousAnimation = true
 		}
 
-		c.ActionIndex = actionindex.GetActionIndex(c.State)
+		c.AnimationElapsed += time.Duration(float64(dt) * c.TimeScale * float64(time.Second))
+
+		actionIndex, err := actionindex.GetActionIndex(c.State)
+		if err != nil {
+			s.log.Error().Err(err).Uint64("entity", c.ID()).Send()
+			continue
+		}
+		c.ActionIndex = actionIndex
+
+		// Attacks start over every attack delay.
+		nextAttack := c.State == statetype.Attacking && aspd.New(c.ASPD).CanAttack(c.AnimationElapsed)
 
 		if (c.State != c.PreviousState && c.State != statetype.Idle) ||
-			(c.State == statetype.Idle && stopPreviousAnimation) {
+			(c.State == statetype.Idle && stopPreviousAnimation) ||
+			nextAttack {
 			c.AnimationStartedAt = now
-
-			// TODO: treat special case when attacking
-			var forcedDuration time.Duration
-			c.ForcedDuration = forcedDuration
+			c.AnimationElapsed = 0
+			c.Events.Restart(c.State)
+
+			c.ForcedDuration, c.PlayMode = 0, actionplaymode.Repeat
+			if c.State == statetype.Attacking {
+				// The attack animation lasts the attack motion, the damage
+				// being applied at its end, then holds until the next one.
+				c.ForcedDuration, c.PlayMode = aspd.New(c.ASPD).Motion, actionplaymode.PlayThenHold
+			}
 
 			c.FPSMultiplier = 1.0
-			if c.State == statetype.Walking {
-				c.FPSMultiplier = c.MovementSpeed
-			}
+		}
+		if c.State == statetype.Walking {
+			// Follows the walk speed as it changes, e.g. with Agi Up.
+			c.FPSMultiplier = character.WalkAnimationMultiplier(c.WalkSpeed())
 		}
 		c.AnimationEndsAt = now.Add(c.AnimationDelay)
+
+		// The 8 directions of an action have the same timing.
+		if timeline, ok := animation.CurrentTimeline(c, character.AttachmentBody, pkgcharacter.DirectionResolver{}); ok {
+			frame := animation.Progress(c, timeline, c.AnimationElapsed)
+			c.Events.Advance(c.State, frame, len(timeline.Ends), c.PlayMode == actionplaymode.Repeat)
+		}
+	}
+}
+
+// walk moves c along its walk path for a tick of dt seconds, a cell in
+// its walk speed, standing by once the path is walked.
+func walk(c *entity.Character, dt float32) {
+	distance := character.WalkDistance(dt, c.WalkSpeed()) * float32(c.TimeScale)
+	position, heading, done := c.Walk.Advance(distance)
+
+	c.SetPosition(mgl32.Vec3{position.X(), position.Y(), c.Position().Z()})
+	c.Direction = walkpath.Direction(heading)
+
+	if done {
+		c.Walk = nil
+		c.SetState(statetype.StandBy)
+		return
 	}
+	c.SetState(statetype.Walking)
 }
 
 func (s *CharacterActionSystem) Remove(e ecs.BasicEntity) {
-	delete(s.characters, strconv.Itoa(int(e.ID())))
+	s.loading.Remove(e.ID())
+	s.characters.Remove(e.ID())
 }
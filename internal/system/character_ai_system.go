@@ -0,0 +1,98 @@
+package system
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/ai"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/sim"
+)
+
+// CharacterAISystem makes characters act on their own: each controlled
+// character is an actor of a sim.World of the map, driven by its
+// ai.Controller, the character following its actor. The characters moved
+// by something else, e.g. the player, are tracked for the controllers to
+// see them.
+type CharacterAISystem struct {
+	Driver *ai.Driver
+
+	ground     *Ground
+	controlled []*aiCharacter
+	tracked    []*aiCharacter
+}
+
+// aiCharacter is a character and its actor.
+type aiCharacter struct {
+	char  *entity.Character
+	actor *sim.Actor
+}
+
+// NewCharacterAISystem returns the system of the characters on ground.
+func NewCharacterAISystem(ground *Ground) *CharacterAISystem {
+	return &CharacterAISystem{
+		Driver: ai.NewDriver(sim.NewWorld(ground.GAT)),
+		ground: ground,
+	}
+}
+
+// Control makes controller control char, returning its actor.
+func (s *CharacterAISystem) Control(char *entity.Character, controller ai.Controller) *sim.Actor {
+	c := s.add(&s.controlled, char)
+	c.actor.MovementSpeed, c.actor.ASPD = char.MovementSpeed, char.ASPD
+	s.Driver.Attach(c.actor, controller)
+
+	return c.actor
+}
+
+// Track adds char to the world as an actor that follows it, returning the
+// actor.
+func (s *CharacterAISystem) Track(char *entity.Character) *sim.Actor {
+	return s.add(&s.tracked, char).actor
+}
+
+func (s *CharacterAISystem) add(list *[]*aiCharacter, char *entity.Character) *aiCharacter {
+	x, y := s.ground.Cell(char.Position())
+	c := &aiCharacter{char: char, actor: s.Driver.World.Spawn(sim.Cell{X: int(x), Y: int(y)})}
+	c.actor.Position = mgl32.Vec2{x, y}
+	*list = append(*list, c)
+
+	return c
+}
+
+func (s *CharacterAISystem) Update(dt float32) {
+	for _, c := range s.tracked {
+		x, y := s.ground.Cell(c.char.Position())
+		s.Driver.World.Place(c.actor, mgl32.Vec2{x, y})
+	}
+
+	s.Driver.Update(dt)
+
+	for _, c := range s.controlled {
+		p := s.ground.World(c.actor.Position.X(), c.actor.Position.Y())
+		c.char.SetPosition(mgl32.Vec3{p.X(), p.Y(), c.char.Position().Z()})
+
+		c.char.Direction = c.actor.Direction
+		if c.char.State != c.actor.State {
+			c.char.SetState(c.actor.State)
+		}
+	}
+}
+
+// Remove removes the character of e from the world.
+func (s *CharacterAISystem) Remove(e ecs.BasicEntity) {
+	s.controlled = s.remove(s.controlled, e)
+	s.tracked = s.remove(s.tracked, e)
+}
+
+func (s *CharacterAISystem) remove(list []*aiCharacter, e ecs.BasicEntity) []*aiCharacter {
+	for i, c := range list {
+		if c.char.ID() == e.ID() {
+			s.Driver.Detach(c.actor)
+			s.Driver.World.Remove(c.actor)
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+
+	return list
+}
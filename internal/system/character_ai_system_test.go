@@ -0,0 +1,51 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/ai"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/sim"
+)
+
+func TestCharacterAISystem(t *testing.T) {
+	gatFile := &gat.GroundAltitudeFile{Width: 20, Height: 20, Cells: make([]gat.Cell, 400)}
+	for i := range gatFile.Cells {
+		gatFile.Cells[i].CellType = gat.Walkable
+	}
+	ground := &Ground{GAT: gatFile, Center: mgl32.Vec3{4, 38, -1}}
+
+	x, y := ground.Cell(mgl32.Vec3{1, 40, 0})
+	assert.Equal(t, mgl32.Vec2{1, 40}, ground.World(x, y))
+
+	player := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	player.SetPosition(mgl32.Vec3{0, 40, 0})
+	follower := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	follower.SetPosition(mgl32.Vec3{8, 40, 0})
+
+	s := NewCharacterAISystem(ground)
+	leader := s.Track(player)
+	actor := s.Control(follower, ai.NewFollow(leader))
+
+	s.Update(0.02)
+	assert.Equal(t, statetype.Walking, follower.State)
+
+	for i := 0; i < 100; i++ {
+		s.Update(0.02)
+	}
+	assert.Equal(t, statetype.StandBy, follower.State)
+	// Walked east, the east being -X, up to 2 cells from the player.
+	assert.Equal(t, 2, sim.Distance(actor.Cell(), leader.Cell()))
+	assert.Equal(t, ground.World(actor.Position.X(), actor.Position.Y()), mgl32.Vec2{follower.Position().X(), follower.Position().Y()})
+	assert.Less(t, follower.Position().X(), float32(3))
+
+	s.Remove(*follower.BasicEntity)
+	assert.Len(t, s.Driver.World.Actors(), 1)
+}
@@ -0,0 +1,63 @@
+package system
+
+import (
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// skip tells whether char skips its update this frame, see Budget and
+// LOD.
+func (s *CharacterRenderSystem) skip(char *entity.Character) bool {
+	if (s.Budget == nil && s.LOD == nil) || s.focus == nil || char == s.focus {
+		return false
+	}
+
+	distance := char.Position().Sub(s.focus.Position()).Vec2().Len()
+	return (s.Budget != nil && s.Budget.Skip(char.ID(), distance)) ||
+		(s.LOD != nil && s.LOD.Skip(char.ID(), distance))
+}
+
+// remember keeps the commands char was just rendered with, to draw them
+// again when it is skipped.
+func (s *CharacterRenderSystem) remember(char *entity.Character) {
+	s.drawn[char.ID()] = append(s.drawn[char.ID()][:0], s.drawing...)
+}
+
+// redraw draws char with the commands of its last update, at its current
+// position, and tells whether it did. The textures are requested again,
+// which does not upload them unless they were evicted since.
+func (s *CharacterRenderSystem) redraw(char *entity.Character) bool {
+	drawn, ok := s.drawn[char.ID()]
+	if !ok {
+		return false
+	}
+
+	n, position := len(s.RenderCommands.Sprites), s.renderPosition(char)
+	for _, d := range drawn {
+		texture, err := s.textureProvider.NewTextureFromRGBA(d.img, graphic.TextureSprite)
+		if err != nil {
+			// Rendered again from its sprites.
+			s.RenderCommands.Sprites = s.RenderCommands.Sprites[:n]
+			return false
+		}
+
+		cmd := d.cmd
+		cmd.Position, cmd.Texture, cmd.Dirty = position, texture, false
+		s.renderSpriteCommand(cmd)
+	}
+
+	return true
+}
+
+// changed tells whether the sprite of cmd, of a character being rendered,
+// changed size or flips since its last update.
+func (s *CharacterRenderSystem) changed(cmd opengl.SpriteRenderCommand) bool {
+	drawn := s.drawn[cmd.Key.Entity]
+	if cmd.Key.Index >= len(drawn) {
+		return true
+	}
+
+	last := drawn[cmd.Key.Index].cmd
+	return last.Size != cmd.Size || last.FlipHorizontally != cmd.FlipHorizontally || last.FlipVertically != cmd.FlipVertically
+}
@@ -0,0 +1,38 @@
+package system
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/entity"
+)
+
+// Tick remembers the positions of the characters before a simulation
+// tick, to render them between the positions before and after the tick
+// (see SetAlpha).
+func (s *CharacterRenderSystem) Tick() {
+	for _, char := range s.characters.All() {
+		s.previous[char.ID()] = char.Position()
+	}
+}
+
+// SetAlpha sets the fraction of a tick elapsed since the last Tick, see
+// timestep.FixedStep.
+func (s *CharacterRenderSystem) SetAlpha(alpha float32) {
+	s.alpha = alpha
+}
+
+// renderPosition returns the position char is rendered at, its feet on
+// the Ground when set. Characters are rendered at their position until
+// the first Tick.
+func (s *CharacterRenderSystem) renderPosition(char *entity.Character) mgl32.Vec3 {
+	position := char.Position()
+	if previous, ok := s.previous[char.ID()]; ok {
+		position = previous.Add(position.Sub(previous).Mul(s.alpha))
+	}
+
+	if s.Ground != nil {
+		position = s.Ground.Anchor(position)
+	}
+
+	return position
+}
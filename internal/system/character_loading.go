@@ -0,0 +1,144 @@
+package system
+
+import (
+	"context"
+	"sync"
+
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
+)
+
+// loadedCharacter is a character whose attachments finished loading.
+type loadedCharacter struct {
+	char *entity.Character
+	cmp  *component.CharacterAttachmentComponent
+	err  error
+}
+
+// readyWaiter is a SpriteLoader uploading the textures of the sprites
+// after loading their files, as asset.Manager and asset.Scope.
+type readyWaiter interface {
+	WaitSpriteReady(ctx context.Context, name string) error
+}
+
+// characterLoader loads the attachments of the characters added to a
+// system on goroutines, so the main thread does not wait for their sprites
+// to be read and decoded (see asset.Manager). The system handles the
+// characters once Ready returns them, on the main thread, with the
+// textures of their sprites uploaded when fs uploads them (see
+// asset.Scope): the system does not upload them itself, ahead of the
+// upload queue.
+//
+// The loads run with the context of the system, held by the goroutine of
+// run rather than by the loader: once it is done, the loads in progress
+// stop and the next ones are dropped.
+type characterLoader struct {
+	fs       vfs.FS
+	requests chan loadRequest
+	done     <-chan struct{}
+
+	mu      sync.Mutex
+	pending map[uint64]bool
+	loaded  []loadedCharacter
+}
+
+// loadRequest is a character to load, with its configuration read on the
+// main thread.
+type loadRequest struct {
+	char *entity.Character
+	conf component.CharacterAttachmentComponentConfig
+}
+
+func newCharacterLoader(ctx context.Context, fs vfs.FS) *characterLoader {
+	l := &characterLoader{
+		fs:       fs,
+		requests: make(chan loadRequest),
+		done:     ctx.Done(),
+		pending:  map[uint64]bool{},
+	}
+	go l.run(ctx)
+
+	return l
+}
+
+// run starts the loads requested until ctx is done.
+func (l *characterLoader) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r := <-l.requests:
+			if ctx.Err() != nil {
+				l.Remove(r.char.ID())
+				return
+			}
+			go l.load(ctx, r)
+		}
+	}
+}
+
+// Load starts loading the attachments of char.
+func (l *characterLoader) Load(char *entity.Character) {
+	r := loadRequest{char: char, conf: char.AttachmentConfig()}
+
+	l.mu.Lock()
+	l.pending[char.ID()] = true
+	l.mu.Unlock()
+
+	select {
+	case <-l.done:
+		l.Remove(char.ID())
+	case l.requests <- r:
+	}
+}
+
+func (l *characterLoader) load(ctx context.Context, r loadRequest) {
+	cmp, err := component.NewCharacterAttachmentComponent(ctx, l.fs, r.conf)
+	if w, ok := l.fs.(readyWaiter); ok && err == nil {
+		for _, path := range cmp.Paths {
+			if err = w.WaitSpriteReady(ctx, path); err != nil {
+				break
+			}
+		}
+	}
+
+	l.finish(loadedCharacter{char: r.char, cmp: cmp, err: err})
+}
+
+// finish hands a loaded character to Ready, unless it was removed since.
+func (l *characterLoader) finish(c loadedCharacter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pending[c.char.ID()] {
+		delete(l.pending, c.char.ID())
+		l.loaded = append(l.loaded, c)
+	}
+}
+
+// Ready returns the characters loaded since the last call. The attachments
+// that did load are set even when err is not nil.
+func (l *characterLoader) Ready() []loadedCharacter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loaded := l.loaded
+	l.loaded = nil
+
+	return loaded
+}
+
+// Remove drops a character, loaded or not.
+func (l *characterLoader) Remove(id uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.pending, id)
+	for i, c := range l.loaded {
+		if c.char.ID() == id {
+			l.loaded = append(l.loaded[:i], l.loaded[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package system
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+// blockingLoader blocks the loads of sprites until their context is
+// done.
+type blockingLoader struct {
+	started chan string
+	calls   int32
+}
+
+func (l *blockingLoader) GetSpriteFilesContext(ctx context.Context, name string) (grf.ActionSpriteFilePair, error) {
+	atomic.AddInt32(&l.calls, 1)
+	l.started <- name
+	<-ctx.Done()
+
+	return grf.ActionSpriteFilePair{}, ctx.Err()
+}
+
+func (l *blockingLoader) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	return nil, os.ErrNotExist
+}
+
+func (l *blockingLoader) OverlayPaths(name string) []string {
+	return nil
+}
+
+func TestCharacterLoaderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	loader := &blockingLoader{started: make(chan string, 1)}
+	l := newCharacterLoader(ctx, loader)
+
+	char := entity.NewCharacter(character.Male, jobspriteid.Novice, 1)
+	l.Load(char)
+	<-loader.started
+	cancel()
+
+	var loaded []loadedCharacter
+	require.Eventually(t, func() bool {
+		loaded = append(loaded, l.Ready()...)
+		return len(loaded) > 0
+	}, time.Second, time.Millisecond)
+	assert.Same(t, char, loaded[0].char)
+	assert.True(t, errors.Is(loaded[0].err, context.Canceled), "%v", loaded[0].err)
+
+	// The loads requested once the context is done do not start.
+	l.Load(entity.NewCharacter(character.Female, jobspriteid.Novice, 1))
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, l.Ready())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loader.calls))
+}
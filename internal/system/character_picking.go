@@ -0,0 +1,81 @@
+package system
+
+import (
+	"image"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/camera"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+)
+
+// PickCharacter returns the character whose visible sprite pixels, as last
+// rendered, contain the window point (x, y), or nil. The nearest character
+// to the camera wins when several overlap.
+func (s *CharacterRenderSystem) PickCharacter(cam *camera.Camera, windowWidth, windowHeight, x, y int) *entity.Character {
+	var (
+		picked *entity.Character
+		depth  float32
+	)
+
+	view := cam.ViewMatrix()
+	projection := cam.ProjectionMatrix()
+	point := image.Point{X: x, Y: y}
+
+	for _, char := range s.characters.All() {
+		if char.Hitbox.Empty() {
+			continue
+		}
+
+		// Sprites are billboards: their pixels are offset in view space.
+		center := view.Mul4x1(s.renderPosition(char).Vec4(1)).Vec3()
+		if center.Z() >= 0 {
+			continue
+		}
+
+		min := projectToWindow(projection, center, char.Hitbox.Min, windowWidth, windowHeight)
+		max := projectToWindow(projection, center, char.Hitbox.Max, windowWidth, windowHeight)
+
+		if !point.In(image.Rectangle{Min: min, Max: max}.Canon()) {
+			continue
+		}
+
+		if picked == nil || center.Z() > depth {
+			picked, depth = char, center.Z()
+		}
+	}
+
+	return picked
+}
+
+func projectToWindow(projection mgl32.Mat4, center mgl32.Vec3, p image.Point, windowWidth, windowHeight int) image.Point {
+	pos := center.Add(mgl32.Vec3{
+		float32(p.X) * geometry.OnePixelSize,
+		-float32(p.Y) * geometry.OnePixelSize,
+		0,
+	})
+
+	clip := projection.Mul4x1(pos.Vec4(1))
+	ndc := clip.Vec3().Mul(1 / clip.W())
+
+	return image.Point{
+		X: int((ndc.X() + 1) / 2 * float32(windowWidth)),
+		Y: int((1 - ndc.Y()) / 2 * float32(windowHeight)),
+	}
+}
+
+// SubscribePicking picks the character under every event.MouseClicked of
+// bus, publishing event.CharacterPicked, or event.GroundClicked when no
+// character is hit.
+func (s *CharacterRenderSystem) SubscribePicking(bus *event.Bus, cam *camera.Camera, windowWidth, windowHeight int) (unsubscribe func()) {
+	return bus.Subscribe(func(e event.MouseClicked) {
+		if picked := s.PickCharacter(cam, windowWidth, windowHeight, e.X, e.Y); picked != nil {
+			bus.Publish(event.CharacterPicked{Character: picked})
+			return
+		}
+
+		bus.Publish(event.GroundClicked{X: e.X, Y: e.Y})
+	})
+}
@@ -1,32 +1,38 @@
 package system
 
 import (
+	"context"
+	"fmt"
+	"image"
 	"math"
-	"strconv"
 	"time"
 
 	"github.com/EngoEngine/ecs"
 	"github.com/go-gl/mathgl/mgl32"
-	"github.com/rs/zerolog/log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 
+	"github.com/project-midgard/midgarts/internal/animation"
 	"github.com/project-midgard/midgarts/internal/character"
-	"github.com/project-midgard/midgarts/internal/character/actionindex"
-	"github.com/project-midgard/midgarts/internal/character/actionplaymode"
-	"github.com/project-midgard/midgarts/internal/character/directiontype"
 	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/crash"
 	"github.com/project-midgard/midgarts/internal/entity"
 	"github.com/project-midgard/midgarts/internal/fileformat/act"
-	"github.com/project-midgard/midgarts/internal/fileformat/grf"
 	"github.com/project-midgard/midgarts/internal/fileformat/spr"
 	"github.com/project-midgard/midgarts/internal/graphic"
 	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+	"github.com/project-midgard/midgarts/internal/hitbox"
+	"github.com/project-midgard/midgarts/internal/lod"
+	"github.com/project-midgard/midgarts/internal/logging"
 	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/timestep"
+	pkgcharacter "github.com/project-midgard/midgarts/pkg/character"
+	"github.com/project-midgard/midgarts/pkg/fileformat/vfs"
 )
 
-const (
-	SpriteScaleFactor    = float32(1.0)
-	FixedCameraDirection = 6
-)
+// FixedCameraDirection is the camera direction of the official client the
+// characters are seen from until SetCameraYaw is called.
+const FixedCameraDirection = pkgcharacter.DefaultCameraDirection
 
 type CharacterRenderable interface {
 	ecs.BasicFace
@@ -35,28 +41,115 @@ type CharacterRenderable interface {
 }
 
 type CharacterRenderSystem struct {
-	grfFile         *grf.File
-	characters      map[string]*entity.Character
+	log        zerolog.Logger
+	loading    *characterLoader
+	characters *entity.CharacterStore
+	// failed holds the characters that could not be rendered, they are
+	// not rendered anymore.
+	failed map[uint64]error
+	// previous holds the positions before the last tick, alpha the
+	// fraction of a tick since then.
+	previous map[uint64]mgl32.Vec3
+	alpha    float32
+	// directions resolves the sprite directions seen from the camera.
+	directions pkgcharacter.DirectionResolver
+	// focus is the character of the player, never skipped, and drawn
+	// holds the commands of the characters at their last update, drawn
+	// again when they are skipped (see Budget) and telling the sprites
+	// that changed (see opengl.SpriteRenderCommand.Dirty).
+	focus *entity.Character
+	drawn map[uint64][]drawnSprite
+	// drawing holds the commands of the character being rendered, and
+	// placements its frames, reused from a character to the next.
+	drawing    []drawnSprite
+	placements []animation.Placement
+	// tint is the tint of the layers of the attachment being rendered
+	// (see StatusTint).
+	tint mgl32.Vec4
+
+	// Budget, when set, skips the updates of the characters far from the
+	// player when the frames are too long: they keep their last frame and
+	// only move.
+	Budget *timestep.Budget
+	// LOD, when set, lowers the detail of the characters as the camera
+	// zooms out (see SetCameraDistance): their small attachments are not
+	// drawn and the distant ones are skipped as with Budget.
+	LOD            *lod.LOD
+	cameraDistance float32
+	// Ground, when set, puts the feet of the characters on the ground of
+	// the map, following its slopes.
+	Ground *Ground
+
 	RenderCommands  *opengl.RenderCommands
 	textureProvider graphic.TextureProvider
+	hitboxes        hitbox.Cache
 }
 
-func NewCharacterRenderSystem(grfFile *grf.File, textureProvider graphic.TextureProvider) *CharacterRenderSystem {
+// NewCharacterRenderSystem returns the system, reading the attachments of
+// the added characters from fs; ctx cancels their loading and carries its
+// logger (see logging.For).
+func NewCharacterRenderSystem(ctx context.Context, fs vfs.FS, textureProvider graphic.TextureProvider) *CharacterRenderSystem {
 	return &CharacterRenderSystem{
-		grfFile:    grfFile,
-		characters: map[string]*entity.Character{},
+		log:        logging.For(ctx, logging.Render),
+		loading:    newCharacterLoader(ctx, fs),
+		characters: entity.NewCharacterStore(),
+		failed:     map[uint64]error{},
+		previous:   map[uint64]mgl32.Vec3{},
+		drawn:      map[uint64][]drawnSprite{},
+		directions: pkgcharacter.ResolverForCameraDirection(FixedCameraDirection),
 		RenderCommands: &opengl.RenderCommands{
 			Sprites: []opengl.SpriteRenderCommand{},
 		},
 		textureProvider: textureProvider,
+		hitboxes:        hitbox.NewCache(),
 	}
 }
 
+// drawnSprite is a command of a character, with the image of its texture.
+type drawnSprite struct {
+	cmd opengl.SpriteRenderCommand
+	img *graphic.UniqueRGBA
+}
+
 func (s *CharacterRenderSystem) Update(dt float32) {
-	s.RenderCommands.Sprites = []opengl.SpriteRenderCommand{}
+	// Reused, the commands are drawn before the next update.
+	s.RenderCommands.Sprites = s.RenderCommands.Sprites[:0]
+	s.RenderCommands.HUD = s.RenderCommands.HUD[:0]
+	if s.Budget != nil {
+		s.Budget.Observe(time.Duration(dt * float32(time.Second)))
+	}
+	if s.LOD != nil {
+		s.LOD.Observe(s.cameraDistance)
+	}
+
+	for _, c := range s.loading.Ready() {
+		if c.err != nil {
+			// Render the attachments that did load, e.g. a body without
+			// its missing head, rather than dropping the character.
+			s.log.Error().Err(c.err).Uint64("entity", c.char.ID()).Msg("could not load character attachments")
+		}
 
-	for _, char := range s.characters {
-		s.renderCharacter(dt, char)
+		c.char.SetCharacterAttachmentComponent(c.cmp)
+		s.characters.Add(c.char)
+	}
+
+	// Backwards, as removing a character moves the last one in its place.
+	chars := s.characters.All()
+	for i := len(chars) - 1; i >= 0; i-- {
+		char := chars[i]
+		if s.skip(char) && s.redraw(char) {
+			continue
+		}
+
+		if err := s.renderCharacter(char); err != nil {
+			s.log.Error().Err(err).Uint64("entity", char.ID()).Msg("could not render character, hiding it")
+			s.characters.Remove(char.ID())
+			s.failed[char.ID()] = err
+			delete(s.drawn, char.ID())
+			continue
+		}
+
+		s.remember(char)
 	}
 }
 
@@ -65,119 +158,82 @@ func (s *CharacterRenderSystem) AddByInterface(o ecs.Identifier) {
 	s.Add(char)
 }
 
+// Add loads the attachments of char in the background; it is rendered
+// once they are loaded.
 func (s *CharacterRenderSystem) Add(char *entity.Character) {
-	cmp, err := component.NewCharacterAttachmentComponent(s.grfFile, component.CharacterAttachmentComponentConfig{
-		Gender:           char.Gender,
-		JobSpriteID:      char.JobSpriteID,
-		HeadIndex:        char.HeadIndex,
-		EnableShield:     char.HasShield,
-		ShieldSpriteName: char.ShieldSpriteName,
-	})
-	if err != nil {
-		log.Fatal().Err(err).Send()
-	}
-
-	char.SetCharacterAttachmentComponent(cmp)
-	s.characters[strconv.Itoa(int(char.ID()))] = char
+	s.loading.Load(char)
 }
 
 func (s *CharacterRenderSystem) Remove(e ecs.BasicEntity) {
-	delete(s.characters, strconv.Itoa(int(e.ID())))
+	s.loading.Remove(e.ID())
+	s.characters.Remove(e.ID())
+	delete(s.failed, e.ID())
+	delete(s.previous, e.ID())
+	delete(s.drawn, e.ID())
 }
 
-func (s *CharacterRenderSystem) renderCharacter(dt float32, char *entity.Character) {
-	offset := [2]float32{0, 0}
-
-	direction := int(char.Direction) + directiontype.DirectionTable[FixedCameraDirection]%8
-	behind := direction > 1 && direction < 6
-	renderShield := char.HasShield && char.ActionIndex == actionindex.StandBy
-
-	if char.ActionIndex != actionindex.Dead && char.ActionIndex != actionindex.Sitting {
-		s.renderAttachment(dt, char, character.AttachmentShadow, &offset)
-	}
-
-	if behind && renderShield {
-		s.renderAttachment(dt, char, character.AttachmentShield, &offset)
-	}
-
-	s.renderAttachment(dt, char, character.AttachmentBody, &offset)
-	s.renderAttachment(dt, char, character.AttachmentHead, &offset)
+// SetFocus sets the character of the player, always updated (see
+// Budget).
+func (s *CharacterRenderSystem) SetFocus(char *entity.Character) {
+	s.focus = char
+}
 
-	if !behind && renderShield {
-		s.renderAttachment(dt, char, character.AttachmentShield, &offset)
-	}
+// SetCameraYaw sets the orbit of the camera, in degrees (see
+// camera.Camera.SetOrbitYaw), the characters showing the sprite direction
+// they are seen from.
+func (s *CharacterRenderSystem) SetCameraYaw(yaw float32) {
+	s.directions.Yaw = float64(yaw)
 }
 
-func (s *CharacterRenderSystem) renderAttachment(
-	dt float32,
-	char *entity.Character,
-	elem character.AttachmentType,
-	offset *[2]float32,
-) {
-	var actions []*act.Action
-	if actions = char.Files[elem].ACT.Actions; len(actions) == 0 {
-		return
-	}
+// SetCameraDistance sets the distance of the camera to the ground (see
+// camera.Camera.Distance), lowering the detail of the characters past the
+// distance of LOD.
+func (s *CharacterRenderSystem) SetCameraDistance(distance float32) {
+	s.cameraDistance = distance
+}
 
-	idx := (int(char.ActionIndex) + (int(char.Direction)+directiontype.DirectionTable[FixedCameraDirection])%8) % len(actions)
-	action := actions[idx]
-	frameCount := int64(len(action.Frames))
-	timeNeededForOneFrame := int64(float64(action.Delay) * (1.0 / char.FPSMultiplier))
+func (s *CharacterRenderSystem) renderCharacter(char *entity.Character) error {
+	defer crash.AnnotateUint("entity", char.ID())
 
-	if char.ForcedDuration != 0 {
-		timeNeededForOneFrame = int64(char.ForcedDuration) / frameCount
-	}
+	char.Hitbox = image.Rectangle{}
+	s.drawing = s.drawing[:0]
 
-	timeNeededForOneFrame = int64(math.Max(float64(timeNeededForOneFrame), 100))
-	elapsedTime := time.Since(char.AnimationStartedAt).Milliseconds() - int64(dt)
-	realIndex := elapsedTime / timeNeededForOneFrame
+	s.placements = animation.AppendPose(s.placements[:0], char, s.directions, char.AnimationElapsed)
+	for _, p := range s.placements {
+		if err := s.renderPlacement(char, p); err != nil {
+			return errors.Wrapf(err, "could not render %s", p.Attachment)
+		}
 
-	var frameIndex int64
-	switch char.PlayMode {
-	case actionplaymode.Repeat:
-		frameIndex = realIndex % frameCount
-		break
+		char.AnimationDelay = time.Duration(p.Action.DurationMilliseconds) * time.Millisecond
 	}
 
-	// Ignore "doridori" animation
-	if len(action.Frames) == 3 {
-		frameIndex = 0
-	}
+	return nil
+}
 
-	var frame *act.ActionFrame
-	if frame = action.Frames[frameIndex]; len(frame.Layers) == 0 {
-		*offset = [2]float32{0, 0}
-		return
+func (s *CharacterRenderSystem) renderPlacement(char *entity.Character, p animation.Placement) error {
+	if p.Attachment != character.AttachmentShadow {
+		box := s.hitboxes.Frame(p.SPR, p.Frame).Add(image.Point{X: int(p.Position[0]), Y: int(p.Position[1])})
+		char.Hitbox = char.Hitbox.Union(scaleRect(box, char.SpriteScale()))
 	}
-
-	position := [2]float32{0, 0}
-
-	if len(frame.Positions) > 0 &&
-		elem != character.AttachmentBody &&
-		elem != character.AttachmentShield {
-
-		position[0] = offset[0] - float32(frame.Positions[0][0])
-		position[1] = offset[1] - float32(frame.Positions[0][1])
+	// Still picked by its shield when it is not drawn.
+	if s.LOD != nil && !s.LOD.Draws(p.Attachment) {
+		return nil
 	}
 
-	// Render all layers
-	for _, layer := range frame.Layers {
-		if layer.SpriteFrameIndex < 0 {
-			continue
-		}
-
-		s.renderLayer(char, layer, char.Files[elem].SPR, position)
+	// The shadows are not tinted.
+	s.tint = mgl32.Vec4{}
+	if p.Attachment != character.AttachmentShadow {
+		s.tint = StatusTint(char)
 	}
 
-	// Save offset reference
-	if len(frame.Positions) > 0 {
-		*offset = [2]float32{
-			float32(frame.Positions[0][0]),
-			float32(frame.Positions[0][1]),
+	// Render all layers
+	for _, layer := range p.Layers {
+		if err := s.renderLayer(char, layer, p.SPR, p.Position); err != nil {
+			return err
 		}
 	}
 
-	char.AnimationDelay = time.Duration(action.DurationMilliseconds) * time.Millisecond
+	return nil
 }
 
 func (s *CharacterRenderSystem) renderLayer(
@@ -185,43 +241,88 @@ func (s *CharacterRenderSystem) renderLayer(
 	layer *act.ActionFrameLayer,
 	spr *spr.SpriteFile,
 	offset [2]float32,
-) {
+) error {
 	frameIndex := character.SpriteIndex(layer.SpriteFrameIndex)
 	if frameIndex < 0 {
-		return
+		return nil
+	}
+
+	if int(frameIndex) >= len(spr.Frames) {
+		return fmt.Errorf("sprite frame %d out of range (%d frames)", frameIndex, len(spr.Frames))
 	}
 
-	texture, err := s.textureProvider.NewTextureFromRGBA(spr.ImageAt(frameIndex))
+	if spr.ImageAt(frameIndex) == nil {
+		return nil
+	}
+
+	// Drawn from the atlas of the sprite, the layers of an attachment
+	// share a texture.
+	atlas := spr.Atlas()
+	img := atlas.Image
+	texture, err := s.textureProvider.NewTextureFromRGBA(img, graphic.TextureSprite)
 	if err != nil {
-		log.Fatal().Err(err).Send()
+		return errors.Wrapf(err, "could not create the texture of sprite frame %d", frameIndex)
 	}
 
+	// The size of a sprite pixel in the world.
+	unit := geometry.OnePixelSize * char.SpriteScale()
+
 	frame := spr.Frames[frameIndex]
 	width, height := float32(frame.Width), float32(frame.Height)
-	width *= layer.Scale[0] * SpriteScaleFactor * geometry.OnePixelSize
-	height *= layer.Scale[1] * SpriteScaleFactor * geometry.OnePixelSize
+	width *= layer.Scale[0] * unit
+	height *= layer.Scale[1] * unit
 	rot := float64(layer.Angle) * (math.Pi / 180)
 
+	// The layers are drawn from their position minus half their size in
+	// whole pixels, as in the official client (and portrait.Render): the
+	// center of odd sized layers is half a pixel right and down of their
+	// position, and mirrored layers are flipped around it.
 	offset = [2]float32{
-		(float32(layer.Position[0]) + offset[0]) * geometry.OnePixelSize,
-		(float32(layer.Position[1]) + offset[1]) * geometry.OnePixelSize,
+		(float32(layer.Position[0]) + offset[0] + pixelCenter(float32(frame.Width)*layer.Scale[0])) * unit,
+		(float32(layer.Position[1]) + offset[1] + pixelCenter(float32(frame.Height)*layer.Scale[1])) * unit,
 	}
 
 	cmd := opengl.SpriteRenderCommand{
-		Scale:           layer.Scale,
-		Size:            mgl32.Vec2{width, height},
-		Position:        char.Position(),
-		Offset:          mgl32.Vec2{offset[0], offset[1]},
-		RotationRadians: float32(rot),
-		Texture:         texture,
-		FlipVertically:  layer.Mirrored,
+		Scale:            layer.Scale,
+		Size:             mgl32.Vec2{width, height},
+		Position:         s.renderPosition(char),
+		Offset:           mgl32.Vec2{offset[0], offset[1]},
+		RotationRadians:  float32(rot),
+		Texture:          texture,
+		UV:               atlas.UV(int(frameIndex)),
+		FlipHorizontally: layer.Mirrored,
+		Tint:             s.tint,
+		Key:              opengl.SpriteKey{Entity: char.ID(), Index: len(s.drawing)},
 	}
+	cmd.Dirty = s.changed(cmd)
 
 	// This is the current API to render a shaders. Commands will
 	// be collected by the lower-level rendering system (OpenGL).
 	s.renderSpriteCommand(cmd)
+	s.drawing = append(s.drawing, drawnSprite{cmd, img})
+
+	return nil
 }
 
 func (s *CharacterRenderSystem) renderSpriteCommand(cmd ...opengl.SpriteRenderCommand) {
 	s.RenderCommands.Sprites = append(s.RenderCommands.Sprites, cmd...)
 }
+
+// scaleRect returns r scaled by scale around the origin.
+func scaleRect(r image.Rectangle, scale float32) image.Rectangle {
+	if scale == 1 {
+		return r
+	}
+
+	return image.Rect(
+		int(float32(r.Min.X)*scale), int(float32(r.Min.Y)*scale),
+		int(float32(r.Max.X)*scale), int(float32(r.Max.Y)*scale),
+	)
+}
+
+// pixelCenter returns the offset from the position of a layer of size
+// pixels to its center, the layer starting at the position minus half
+// its size rounded down.
+func pixelCenter(size float32) float32 {
+	return size/2 - float32(int(size)/2)
+}
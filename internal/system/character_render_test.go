@@ -0,0 +1,127 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/component"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/act"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/spr"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/lod"
+)
+
+// staticTextures returns the same texture for every image, without
+// OpenGL.
+type staticTextures struct {
+	tex graphic.Texture
+}
+
+func (t *staticTextures) NewTextureFromRGBA(*graphic.UniqueRGBA, graphic.TextureClass) (*graphic.Texture, error) {
+	return &t.tex, nil
+}
+
+func (t *staticTextures) DeleteTexture(*graphic.UniqueRGBA) {}
+
+// newBenchmarkSprite returns the files of a sprite of 8 actions of 4
+// frames of 2 layers.
+func newBenchmarkSprite() grf.ActionSpriteFilePair {
+	sprFile := &spr.SpriteFile{Images: make([]*graphic.UniqueRGBA, 2)}
+	for i := 0; i < 2; i++ {
+		sprFile.Frames = append(sprFile.Frames, &spr.SpriteFrame{
+			SpriteType: spr.FileTypeRGBA,
+			Width:      40,
+			Height:     80,
+			Data:       make([]byte, 40*80*4),
+		})
+	}
+
+	actFile := &act.ActionFile{}
+	for i := 0; i < 8; i++ {
+		action := &act.Action{Delay: 100}
+		for j := 0; j < 4; j++ {
+			action.Frames = append(action.Frames, &act.ActionFrame{
+				Layers: []*act.ActionFrameLayer{
+					{SpriteFrameIndex: 0, Scale: [2]float32{1, 1}},
+					{SpriteFrameIndex: 1, Scale: [2]float32{1, 1}, Mirrored: true},
+				},
+				Anchors: []act.AnchorPoint{{Y: -60}},
+			})
+		}
+		actFile.Actions = append(actFile.Actions, action)
+	}
+
+	return grf.ActionSpriteFilePair{ACT: actFile, SPR: sprFile}
+}
+
+// newBenchmarkCharacter returns a walking character whose attachments are
+// loaded.
+func newBenchmarkCharacter() *entity.Character {
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	char.SetState(statetype.Walking)
+
+	cmp := &component.CharacterAttachmentComponent{
+		Files:     map[character.AttachmentType]grf.ActionSpriteFilePair{},
+		Paths:     map[character.AttachmentType]string{},
+		Timelines: map[character.AttachmentType][]*act.Timeline{},
+	}
+	for _, elem := range []character.AttachmentType{character.AttachmentShadow, character.AttachmentBody, character.AttachmentHead} {
+		files := newBenchmarkSprite()
+		cmp.Files[elem] = files
+		cmp.Timelines[elem] = act.NewTimelines(files.ACT)
+	}
+	char.SetCharacterAttachmentComponent(cmp)
+
+	return char
+}
+
+func BenchmarkCharacterRenderSystemUpdate(b *testing.B) {
+	s := NewCharacterRenderSystem(context.Background(), nil, &staticTextures{})
+	for i := 0; i < 100; i++ {
+		s.characters.Add(newBenchmarkCharacter())
+	}
+	s.Update(1.0 / 60)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Update(1.0 / 60)
+	}
+}
+
+func BenchmarkCharacterActionSystemUpdate(b *testing.B) {
+	s := NewCharacterActionSystem(context.Background(), nil)
+	for i := 0; i < 100; i++ {
+		s.characters.Add(newBenchmarkCharacter())
+	}
+	s.Update(1.0 / 50)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Update(1.0 / 50)
+	}
+}
+
+func TestCharacterRenderSystemLOD(t *testing.T) {
+	s := NewCharacterRenderSystem(context.Background(), nil, &staticTextures{})
+	s.LOD = lod.New(lod.DefaultDistance)
+	s.characters.Add(newBenchmarkCharacter())
+
+	// 2 layers of the shadow, the body and the head.
+	s.SetCameraDistance(30)
+	s.Update(1.0 / 60)
+	assert.Len(t, s.RenderCommands.Sprites, 6)
+
+	// Without the shadow.
+	s.SetCameraDistance(lod.DefaultDistance)
+	s.Update(1.0 / 60)
+	assert.Len(t, s.RenderCommands.Sprites, 4)
+}
@@ -0,0 +1,133 @@
+package system
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/project-midgard/midgarts/internal/console"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/theme"
+)
+
+const (
+	// consoleHeight is the default height of the console, in fractions of
+	// the screen.
+	consoleHeight = 0.5
+	// consolePadding is the margin around the text of the console, in
+	// pixels.
+	consolePadding = 4
+)
+
+// ConsoleSystem draws the developer console over the top of the screen
+// while it is open. Its image is only drawn again when the console, or its
+// layout, changed.
+type ConsoleSystem struct {
+	// Position is the top left corner of the console and Size its size, in
+	// fractions of the screen from its top left corner.
+	Position, Size mgl32.Vec2
+	// Theme gives the colors and the scale of the text.
+	Theme theme.Theme
+
+	console         *console.Console
+	renderCommands  *opengl.RenderCommands
+	textureProvider graphic.TextureProvider
+	// width and height are the size of the window, in pixels.
+	width, height int
+
+	image   *graphic.UniqueRGBA
+	version uint64
+}
+
+// NewConsoleSystem returns the system drawing c with commands, on a window
+// of width by height pixels.
+func NewConsoleSystem(c *console.Console, textureProvider graphic.TextureProvider, commands *opengl.RenderCommands, width, height int) *ConsoleSystem {
+	return &ConsoleSystem{
+		console:         c,
+		renderCommands:  commands,
+		textureProvider: textureProvider,
+		Size:            mgl32.Vec2{1, consoleHeight},
+		Theme:           theme.Default,
+		width:           width,
+		height:          height,
+	}
+}
+
+func (s *ConsoleSystem) Update(dt float32) {
+	if !s.console.IsOpen() {
+		return
+	}
+
+	// The image is stretched over the console, the text larger the
+	// smaller it is.
+	width := int(s.Size.X() * float32(s.width) / s.Theme.TextScale)
+	height := int(s.Size.Y() * float32(s.height) / s.Theme.TextScale)
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	if s.image == nil || s.version != s.console.Version() || s.image.Bounds().Dx() != width || s.image.Bounds().Dy() != height {
+		s.Close()
+		s.image = NewConsoleImage(s.console, width, height, s.Theme)
+		s.version = s.console.Version()
+	}
+
+	texture, err := s.textureProvider.NewTextureFromRGBA(s.image, graphic.TextureSprite)
+	if err != nil {
+		return
+	}
+
+	s.renderCommands.HUD = append(s.renderCommands.HUD, opengl.HUDRenderCommand{
+		Texture:  texture,
+		Position: s.Position,
+		Size:     s.Size,
+	})
+}
+
+func (s *ConsoleSystem) Remove(ecs.BasicEntity) {}
+
+// Close deletes the texture of the console.
+func (s *ConsoleSystem) Close() {
+	if s.image != nil {
+		s.textureProvider.DeleteTexture(s.image)
+		s.image = nil
+	}
+}
+
+// NewConsoleImage returns the image of c, of width by height pixels, in the
+// colors of t: the last lines of its output, and the line being edited at
+// the bottom.
+func NewConsoleImage(c *console.Console, width, height int, t theme.Theme) *graphic.UniqueRGBA {
+	face := basicfont.Face7x13
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+
+	img := graphic.NewUniqueRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(t.Panel), image.Point{}, draw.Src)
+
+	d := &font.Drawer{Dst: img, Face: face}
+	line := func(y int, text string, c color.RGBA) {
+		d.Src = image.NewUniform(c)
+		d.Dot = fixed.P(consolePadding, y+metrics.Ascent.Ceil())
+		d.DrawString(text)
+	}
+
+	// From the bottom up.
+	y := height - consolePadding - lineHeight
+	line(y, "> "+c.Input()+"_", t.Accent)
+
+	lines := c.Lines()
+	for i := len(lines) - 1; i >= 0 && y-lineHeight >= consolePadding; i-- {
+		y -= lineHeight
+		line(y, lines[i], t.Text)
+	}
+
+	return img
+}
@@ -0,0 +1,62 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/console"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/theme"
+)
+
+func TestConsoleSystem(t *testing.T) {
+	c := console.New()
+	commands := &opengl.RenderCommands{}
+	s := NewConsoleSystem(c, &staticTextures{}, commands, 320, 200)
+
+	s.Update(0.1)
+	assert.Empty(t, commands.HUD, "closed")
+
+	c.Toggle()
+	s.Update(0.1)
+	image := s.image
+	assert.Len(t, commands.HUD, 1)
+	assert.Equal(t, 100, image.Bounds().Dy())
+
+	// Drawn again only when the console changes.
+	s.Update(0.1)
+	assert.Same(t, image, s.image)
+	c.Type("help")
+	s.Update(0.1)
+	assert.NotSame(t, image, s.image)
+
+	// As when its window is moved by a profile.
+	s.Position, s.Size = mgl32.Vec2{0.5, 0.5}, mgl32.Vec2{0.5, 0.25}
+	s.Update(0.1)
+	assert.Equal(t, 160, s.image.Bounds().Dx())
+	assert.Equal(t, 50, s.image.Bounds().Dy())
+	assert.Equal(t, s.Position, commands.HUD[len(commands.HUD)-1].Position)
+
+	// The larger text fits fewer pixels in the same window.
+	s.Theme.TextScale = 2
+	s.Update(0.1)
+	assert.Equal(t, 80, s.image.Bounds().Dx())
+}
+
+func TestNewConsoleImage(t *testing.T) {
+	c := console.New()
+	img := NewConsoleImage(c, 100, 40, theme.Default)
+
+	assert.Equal(t, theme.Default.Panel, img.RGBAAt(0, 0))
+
+	// The prompt is drawn at the bottom.
+	var drawn bool
+	for x := 0; x < 40; x++ {
+		for y := 20; y < 40; y++ {
+			drawn = drawn || img.RGBAAt(x, y) == theme.Default.Accent
+		}
+	}
+	assert.True(t, drawn)
+}
@@ -0,0 +1,183 @@
+package system
+
+import (
+	"context"
+	"math"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/effect"
+	"github.com/project-midgard/midgarts/internal/fileformat/bmp"
+	"github.com/project-midgard/midgarts/internal/fileformat/str"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/internal/mapmodel"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// EffectRenderSystem plays the STR animations of the effects, drawing the
+// quad of each layer as a sprite facing the camera, around the position
+// the effect is played at. A quad is drawn as the rectangle around its
+// corners, tinted with its color; its opacity and its blend mode are not
+// applied.
+type EffectRenderSystem struct {
+	ctx             context.Context
+	log             zerolog.Logger
+	loader          mapmodel.EntryLoader
+	textureProvider graphic.TextureProvider
+	renderCommands  *opengl.RenderCommands
+
+	files map[string]*str.EffectFile
+	// images are the textures of the effects by path, nil when they could
+	// not be loaded.
+	images  map[string]*graphic.UniqueRGBA
+	playing []*playingEffect
+}
+
+type playingEffect struct {
+	file     *str.EffectFile
+	path     string
+	position mgl32.Vec3
+	elapsed  float32
+}
+
+// NewEffectRenderSystem returns the system, loading the STR files and
+// their textures with loader, logging to the logger of ctx (see
+// logging.For).
+func NewEffectRenderSystem(
+	ctx context.Context,
+	loader mapmodel.EntryLoader,
+	textureProvider graphic.TextureProvider,
+	commands *opengl.RenderCommands,
+) *EffectRenderSystem {
+	return &EffectRenderSystem{
+		ctx:             ctx,
+		log:             logging.For(ctx, logging.Effect),
+		loader:          loader,
+		textureProvider: textureProvider,
+		renderCommands:  commands,
+		files:           map[string]*str.EffectFile{},
+		images:          map[string]*graphic.UniqueRGBA{},
+	}
+}
+
+// Play plays the STR file of effect.STRDir once at position. The files
+// are loaded once.
+func (s *EffectRenderSystem) Play(file string, position mgl32.Vec3) error {
+	path := effect.STRDir + file
+	f, ok := s.files[path]
+	if !ok {
+		e, err := s.loader.GetEntryContext(s.ctx, path)
+		if err != nil {
+			return errors.Wrapf(err, "could not read effect '%s'", path)
+		}
+		if f, err = str.Load(e.Data); err != nil {
+			return errors.Wrapf(err, "could not load effect '%s'", path)
+		}
+		s.files[path] = f
+	}
+
+	s.playing = append(s.playing, &playingEffect{file: f, path: path, position: position})
+	return nil
+}
+
+// Playing returns the number of effects playing.
+func (s *EffectRenderSystem) Playing() int {
+	return len(s.playing)
+}
+
+func (s *EffectRenderSystem) Update(dt float32) {
+	playing := s.playing[:0]
+	for _, p := range s.playing {
+		p.elapsed += dt
+		frame := int(p.elapsed * float32(p.file.FPS))
+		if frame >= int(p.file.MaxKey) {
+			continue
+		}
+		playing = append(playing, p)
+
+		for i := range p.file.Layers {
+			s.renderLayer(p, &p.file.Layers[i], frame)
+		}
+	}
+	for i := len(playing); i < len(s.playing); i++ {
+		s.playing[i] = nil
+	}
+	s.playing = playing
+}
+
+// renderLayer adds the sprite of the quad of l at frame, if drawn.
+func (s *EffectRenderSystem) renderLayer(p *playingEffect, l *str.Layer, frame int) {
+	state, ok := l.StateAt(frame)
+	if !ok || state.Texture >= len(l.Textures) || state.Color.W() == 0 {
+		return
+	}
+	img := s.image(l.Textures[state.Texture])
+	if img == nil {
+		return
+	}
+	texture, err := s.textureProvider.NewTextureFromRGBA(img, graphic.TextureSprite)
+	if err != nil {
+		s.log.Error().Err(err).Str("effect", p.path).Msg("could not create effect texture")
+		return
+	}
+
+	min := mgl32.Vec2{float32(math.Inf(1)), float32(math.Inf(1))}
+	max := min.Mul(-1)
+	for _, c := range state.Corners {
+		for a := 0; a < 2; a++ {
+			min[a] = float32(math.Min(float64(min[a]), float64(c[a])))
+			max[a] = float32(math.Max(float64(max[a]), float64(c[a])))
+		}
+	}
+	center := state.Position.Sub(str.Center).Add(min.Add(max).Mul(0.5))
+
+	s.renderCommands.Sprites = append(s.renderCommands.Sprites, opengl.SpriteRenderCommand{
+		Scale:    [2]float32{1, 1},
+		Size:     max.Sub(min).Mul(geometry.OnePixelSize),
+		Position: p.position,
+		// The y of the canvas grows down, as the offsets.
+		Offset:          center.Mul(geometry.OnePixelSize),
+		RotationRadians: state.Angle,
+		Texture:         texture,
+		Tint:            mgl32.Vec4{state.Color.X(), state.Color.Y(), state.Color.Z(), 1},
+	})
+}
+
+// image returns the image of the texture of name, in the folder of the
+// STR files, loaded once; nil when it could not be loaded.
+func (s *EffectRenderSystem) image(name string) *graphic.UniqueRGBA {
+	path := effect.STRDir + name
+	if img, ok := s.images[path]; ok {
+		return img
+	}
+
+	var img *graphic.UniqueRGBA
+	e, err := s.loader.GetEntryContext(s.ctx, path)
+	if err == nil {
+		img, err = bmp.Load(e.Data)
+	}
+	if err != nil {
+		s.log.Warn().Err(err).Str("texture", path).Msg("could not load effect texture, not drawing it")
+	}
+	s.images[path] = img
+
+	return img
+}
+
+func (s *EffectRenderSystem) Remove(ecs.BasicEntity) {}
+
+// Close stops the effects and deletes their textures.
+func (s *EffectRenderSystem) Close() {
+	for _, img := range s.images {
+		if img != nil {
+			s.textureProvider.DeleteTexture(img)
+		}
+	}
+	s.images = map[string]*graphic.UniqueRGBA{}
+	s.playing = nil
+}
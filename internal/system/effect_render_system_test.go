@@ -0,0 +1,92 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+
+	"github.com/project-midgard/midgarts/internal/effect"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/str"
+	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// entries serves the entries of files.
+type entries map[string][]byte
+
+func (e entries) GetEntryContext(_ context.Context, name string) (*grf.Entry, error) {
+	data, ok := e[name]
+	if !ok {
+		return nil, errors.New("entry not found")
+	}
+
+	return &grf.Entry{Data: data}, nil
+}
+
+// newEffect returns an STR file of 10 frames at 10 FPS of a layer of a
+// red quad of 70 by 35 pixels, 35 pixels above the center of the canvas.
+func newEffect() []byte {
+	var buf bytes.Buffer
+	w := func(v interface{}) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+
+	buf.WriteString(str.HeaderSignature)
+	w([4]uint32{str.Version, 10, 10, 1})
+	buf.Write(make([]byte, 16))
+	w(int32(1))
+	texture := make([]byte, 128)
+	copy(texture, "spark.bmp")
+	buf.Write(texture)
+	w(int32(2))
+	w([]str.Keyframe{
+		{
+			Position: str.Center.Sub(mgl32.Vec2{0, 35}),
+			XY:       [8]float32{-35, 35, -35, 35, -17.5, -17.5, 17.5, 17.5},
+			Color:    [4]float32{255, 0, 0, 255},
+		},
+		{Frame: 9},
+	})
+
+	return buf.Bytes()
+}
+
+func TestEffectRenderSystem(t *testing.T) {
+	var texture bytes.Buffer
+	require.NoError(t, bmp.Encode(&texture, image.NewRGBA(image.Rect(0, 0, 2, 2))))
+	loader := entries{
+		effect.STRDir + "spark.str":  newEffect(),
+		effect.STRDir + "spark.bmp":  texture.Bytes(),
+		effect.STRDir + "broken.str": []byte("STRM"),
+	}
+
+	commands := &opengl.RenderCommands{}
+	s := NewEffectRenderSystem(context.Background(), loader, &staticTextures{}, commands)
+	assert.Error(t, s.Play("missing.str", mgl32.Vec3{}))
+	assert.Error(t, s.Play("broken.str", mgl32.Vec3{}))
+
+	position := mgl32.Vec3{4, 38, -1}
+	require.NoError(t, s.Play("spark.str", position))
+	assert.Equal(t, 1, s.Playing())
+
+	s.Update(0.05)
+	require.Len(t, commands.Sprites, 1)
+	sprite := commands.Sprites[0]
+	assert.Equal(t, position, sprite.Position)
+	assert.Equal(t, mgl32.Vec2{70, 35}.Mul(geometry.OnePixelSize), sprite.Size)
+	assert.InDelta(t, -1, sprite.Offset.Y(), 1e-6, "above the position")
+	assert.Equal(t, mgl32.Vec4{1, 0, 0, 1}, sprite.Tint)
+
+	// Done after its 10 frames.
+	commands.Sprites = nil
+	s.Update(1)
+	assert.Empty(t, commands.Sprites)
+	assert.Zero(t, s.Playing())
+}
@@ -0,0 +1,118 @@
+package system
+
+import (
+	"math"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/footstep"
+)
+
+// FootstepSystem plays the steps of the walking characters at the
+// ContactFrames of their walk animation, the sound of the terrain of the
+// cell they stand on (see footstep.CellTerrain). It adds the callbacks of
+// the frames (see entity.Character.OnFrame) when a walk starts, so it must
+// run before the action system, in the simulation world.
+type FootstepSystem struct {
+	Ground *Ground
+	// MapGround is the terrain of the cells of the map out of the water
+	// (see footstep.MapGround).
+	MapGround     footstep.Terrain
+	Sets          map[footstep.Terrain]footstep.Set
+	ContactFrames []int
+	// Play, when set, plays the step of char, at a position in map cells
+	// (see Ground.Cell).
+	Play func(char *entity.Character, step audio.Emitter)
+
+	walkers []*walker
+}
+
+// walker is a character, with the callbacks of its steps.
+type walker struct {
+	char *entity.Character
+	// generation counts the walks with callbacks: the callbacks of the
+	// previous walks, if not dropped yet, do nothing.
+	generation int
+	walking    bool
+	steps      int
+}
+
+// NewFootstepSystem returns the system of the steps on ground, whose
+// cells out of the water are of the terrain mapGround, with the default
+// sets and contact frames.
+func NewFootstepSystem(ground *Ground, mapGround footstep.Terrain) *FootstepSystem {
+	return &FootstepSystem{
+		Ground:        ground,
+		MapGround:     mapGround,
+		Sets:          footstep.DefaultSets,
+		ContactFrames: footstep.DefaultContactFrames,
+	}
+}
+
+func (s *FootstepSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o.(*entity.Character))
+}
+
+// Add plays the steps of char from its next walk.
+func (s *FootstepSystem) Add(char *entity.Character) {
+	s.walkers = append(s.walkers, &walker{char: char})
+}
+
+func (s *FootstepSystem) Remove(e ecs.BasicEntity) {
+	for i, w := range s.walkers {
+		if w.char.ID() == e.ID() {
+			// The callbacks left do nothing.
+			w.generation++
+			s.walkers = append(s.walkers[:i], s.walkers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *FootstepSystem) Update(dt float32) {
+	for _, w := range s.walkers {
+		// The action system starts the walks along a path.
+		walking := w.char.State == statetype.Walking || w.char.Walk != nil
+		if !walking || w.walking {
+			w.walking = walking
+			continue
+		}
+		w.walking = true
+
+		// The callbacks of the walk animation are dropped once another
+		// animation starts, they are added again for every walk.
+		w.generation++
+		generation := w.generation
+		for _, frame := range s.ContactFrames {
+			w.char.Events.OnFrame(statetype.Walking, frame, func() {
+				if w.generation == generation {
+					s.step(w)
+				}
+			})
+		}
+	}
+}
+
+// step plays a step of w.
+func (s *FootstepSystem) step(w *walker) {
+	x, y := s.Ground.Cell(w.char.Position())
+	cell := s.Ground.GAT.CellAt(int(math.Floor(float64(x))), int(math.Floor(float64(y))))
+	if cell == nil {
+		return
+	}
+
+	set := s.Sets[footstep.CellTerrain(cell.CellType, s.MapGround)]
+	sound, ok := set.Sound(w.steps)
+	if !ok {
+		return
+	}
+	w.steps++
+
+	if s.Play != nil {
+		s.Play(w.char, audio.Emitter{Name: sound, Position: mgl32.Vec2{x, y}, Volume: set.Volume})
+	}
+}
@@ -0,0 +1,70 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/audio"
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/footstep"
+)
+
+func TestFootstepSystem(t *testing.T) {
+	g := newSlopeGround()
+	// The east half is under water.
+	for i := range g.GAT.Cells {
+		g.GAT.Cells[i].CellType = gat.TypeTable[0]
+		if i%4 >= 2 {
+			g.GAT.Cells[i].CellType = gat.TypeTable[3]
+		}
+	}
+
+	s := NewFootstepSystem(g, footstep.Sand)
+	var steps []string
+	s.Play = func(char *entity.Character, step audio.Emitter) {
+		steps = append(steps, step.Name)
+	}
+
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	char.SetPosition(mgl32.Vec3{1.5, 0.5, 0})
+	s.Add(char)
+
+	// As the action system animates the walk of 8 frames, after the
+	// footstep system.
+	animate := func(from, to int) {
+		for frame := from; frame <= to; frame++ {
+			s.Update(0.1)
+			if frame == 0 {
+				char.Events.Restart(char.State)
+			}
+			char.Events.Advance(char.State, frame, 8, true)
+		}
+	}
+
+	s.Update(0.1)
+	assert.Empty(t, steps)
+
+	char.SetState(statetype.Walking)
+	animate(0, 8)
+	assert.Equal(t, []string{"footstep/sand_left.wav", "footstep/sand_right.wav", "footstep/sand_left.wav"}, steps)
+
+	// In the water, after a stop.
+	char.SetState(statetype.StandBy)
+	animate(0, 3)
+	char.SetPosition(mgl32.Vec3{-1.5, 0.5, 0})
+	char.SetState(statetype.Walking)
+	steps = nil
+	animate(0, 4)
+	assert.Equal(t, []string{"footstep/water_right.wav", "footstep/water_left.wav"}, steps)
+
+	s.Remove(*char.BasicEntity)
+	steps = nil
+	animate(5, 12)
+	assert.Empty(t, steps)
+}
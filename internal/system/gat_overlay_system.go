@@ -0,0 +1,156 @@
+package system
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/internal/romap"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/theme"
+)
+
+// GATOverlaySystem draws the cells of a GAT file colored by type (walkable,
+// water, cliff...) and shaded by height, to debug walkability.
+type GATOverlaySystem struct {
+	Enabled  bool
+	Position mgl32.Vec3
+	CellSize float32
+
+	log             zerolog.Logger
+	renderCommands  *opengl.RenderCommands
+	textureProvider graphic.TextureProvider
+	gatFile         *gat.GroundAltitudeFile
+	image           *graphic.UniqueRGBA
+}
+
+// NewGATOverlaySystem returns the system, logging to the logger of ctx (see
+// logging.For).
+func NewGATOverlaySystem(
+	ctx context.Context,
+	gatFile *gat.GroundAltitudeFile,
+	textureProvider graphic.TextureProvider,
+	commands *opengl.RenderCommands,
+) *GATOverlaySystem {
+	return &GATOverlaySystem{
+		CellSize:        1.0,
+		log:             logging.For(ctx, logging.GATOverlay),
+		renderCommands:  commands,
+		textureProvider: textureProvider,
+		gatFile:         gatFile,
+		image:           NewGATImage(gatFile, theme.Default.Palette),
+	}
+}
+
+// SetPalette draws the cells in the colors of p.
+func (s *GATOverlaySystem) SetPalette(p theme.Palette) {
+	s.Close()
+	s.image = NewGATImage(s.gatFile, p)
+}
+
+func (s *GATOverlaySystem) Toggle() {
+	s.Enabled = !s.Enabled
+}
+
+// Subscribe toggles the overlay on every event.GATOverlayToggled of bus.
+func (s *GATOverlaySystem) Subscribe(bus *event.Bus) (unsubscribe func()) {
+	return bus.Subscribe(func(event.GATOverlayToggled) {
+		s.Toggle()
+	})
+}
+
+func (s *GATOverlaySystem) Update(dt float32) {
+	if !s.Enabled || s.image == nil {
+		return
+	}
+
+	texture, err := s.textureProvider.NewTextureFromRGBA(s.image, graphic.TextureGround)
+	if err != nil {
+		s.log.Error().Err(err).Msg("could not create gat overlay texture")
+		s.Enabled = false
+		return
+	}
+
+	width := float32(s.gatFile.Width) * s.CellSize
+	height := float32(s.gatFile.Height) * s.CellSize
+
+	s.renderCommands.Sprites = append(s.renderCommands.Sprites, opengl.SpriteRenderCommand{
+		Scale:    [2]float32{1, 1},
+		Size:     mgl32.Vec2{width, height},
+		Position: s.Position,
+		Texture:  texture,
+	})
+}
+
+func (s *GATOverlaySystem) Remove(e ecs.BasicEntity) {
+}
+
+// Close deletes the texture of the overlay.
+func (s *GATOverlaySystem) Close() {
+	if s.image != nil {
+		s.textureProvider.DeleteTexture(s.image)
+		s.image = nil
+	}
+}
+
+// NewGATImage returns an image with one pixel per GAT cell, in the colors
+// of p, with the northern-most row on top. Higher cells are drawn
+// brighter.
+func NewGATImage(f *gat.GroundAltitudeFile, p theme.Palette) *graphic.UniqueRGBA {
+	if f == nil || f.Width == 0 || f.Height == 0 {
+		return nil
+	}
+
+	minHeight, maxHeight := float32(math.MaxFloat32), float32(-math.MaxFloat32)
+	for i := range f.Cells {
+		h := f.Cells[i].Height()
+		minHeight = float32(math.Min(float64(minHeight), float64(h)))
+		maxHeight = float32(math.Max(float64(maxHeight), float64(h)))
+	}
+
+	img := graphic.NewUniqueRGBA(image.Rect(0, 0, int(f.Width), int(f.Height)))
+
+	for y := 0; y < int(f.Height); y++ {
+		for x := 0; x < int(f.Width); x++ {
+			cell := f.CellAt(x, y)
+
+			// Altitudes grow downwards.
+			shade := float32(1.0)
+			if maxHeight > minHeight {
+				shade = 1.0 - 0.5*(cell.Height()-minHeight)/(maxHeight-minHeight)
+			}
+
+			c := gatCellColor(cell.CellType, p)
+			img.Set(x, int(f.Height)-1-y, color.RGBA{
+				R: uint8(float32(c.R) * shade),
+				G: uint8(float32(c.G) * shade),
+				B: uint8(float32(c.B) * shade),
+				A: c.A,
+			})
+		}
+	}
+
+	return img
+}
+
+func gatCellColor(t romap.CellType, p theme.Palette) color.RGBA {
+	switch {
+	case t&romap.CellTypeWater != 0:
+		return p.Water
+	case t&romap.CellTypeWalkable != 0:
+		return p.Walkable
+	case t&romap.CellTypeSnipable != 0:
+		return p.Snipable
+	default:
+		return p.NonWalkable
+	}
+}
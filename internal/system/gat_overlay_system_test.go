@@ -0,0 +1,52 @@
+package system
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/golden"
+	"github.com/project-midgard/midgarts/internal/romap"
+	"github.com/project-midgard/midgarts/internal/theme"
+)
+
+// newMapChunk returns a chunk of a map: a hill in the middle, a pond in a
+// corner, a wall and a cliff along it.
+func newMapChunk() *gat.GroundAltitudeFile {
+	const width, height = 24, 16
+	f := &gat.GroundAltitudeFile{Width: width, Height: height, Cells: make([]gat.Cell, width*height)}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := &f.Cells[y*width+x]
+			// Altitudes grow downwards.
+			altitude := -20 * float32(math.Exp(-float64((x-12)*(x-12)+(y-8)*(y-8))/30))
+			cell.Cells = [4]float32{altitude, altitude, altitude, altitude}
+
+			switch {
+			case (x-3)*(x-3)+(y-3)*(y-3) <= 5:
+				cell.CellType = romap.CellTypeWater | romap.CellTypeWalkable
+			case x == 18 && y > 2:
+				cell.CellType = romap.CellTypeNone
+			case x == 19 && y > 2:
+				cell.CellType = romap.CellTypeSnipable
+			default:
+				cell.CellType = romap.CellTypeWalkable
+			}
+		}
+	}
+
+	return f
+}
+
+func TestNewGATImageGolden(t *testing.T) {
+	f := newMapChunk()
+	for _, name := range theme.PaletteNames() {
+		golden.Assert(t, "gat_"+name, NewGATImage(f, theme.Palettes[name]))
+	}
+
+	contrast, err := theme.New("", 0, true)
+	require.NoError(t, err)
+	golden.Assert(t, "gat_high_contrast", NewGATImage(f, contrast.Palette))
+}
@@ -0,0 +1,65 @@
+package system
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+)
+
+// Ground places the cells of a GAT file in the world, a cell per world
+// unit, west to east along -X and south to north along +Y, as the GAT
+// overlay draws them.
+type Ground struct {
+	GAT *gat.GroundAltitudeFile
+	// GND, when set, gives the altitudes of the ground as the map draws
+	// it, the GAT ones where it has none.
+	GND *gnd.GroundFile
+	// Center is the world position of the center of the map at the
+	// altitude 0.
+	Center mgl32.Vec3
+}
+
+// Cell returns the map coordinates, in cells, of a world position.
+func (g *Ground) Cell(p mgl32.Vec3) (x, y float32) {
+	x = g.Center.X() + float32(g.GAT.Width)/2 - p.X()
+	y = p.Y() - (g.Center.Y() - float32(g.GAT.Height)/2)
+
+	return x, y
+}
+
+// World returns the world position, at the altitude 0, of the map
+// coordinates (x, y), in cells (see Cell).
+func (g *Ground) World(x, y float32) mgl32.Vec2 {
+	return mgl32.Vec2{g.Center.X() + float32(g.GAT.Width)/2 - x, y + g.Center.Y() - float32(g.GAT.Height)/2}
+}
+
+// Altitude returns the world altitude of the ground under p, interpolated
+// on the slopes, the altitudes growing along +Z away from the camera. ok
+// is false out of the map.
+func (g *Ground) Altitude(p mgl32.Vec3) (z float32, ok bool) {
+	x, y := g.Cell(p)
+
+	var height float32
+	if g.GND != nil {
+		height, ok = g.GND.HeightAt(x, y)
+	}
+	if !ok {
+		if height, ok = g.GAT.HeightAt(x, y); !ok {
+			return 0, false
+		}
+	}
+
+	return g.Center.Z() + height/gat.UnitsPerCell, true
+}
+
+// Anchor returns p on the ground, moved to the altitude of the ground
+// under it (see Altitude). p is returned as is out of the map.
+func (g *Ground) Anchor(p mgl32.Vec3) mgl32.Vec3 {
+	z, ok := g.Altitude(p)
+	if !ok {
+		return p
+	}
+
+	return mgl32.Vec3{p.X(), p.Y(), z}
+}
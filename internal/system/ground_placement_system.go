@@ -0,0 +1,80 @@
+package system
+
+import (
+	"math"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/entity"
+)
+
+// GroundPlacementSystem keeps the characters standing on the ground of
+// the map: after every tick, the characters that moved are put at the
+// altitude of the ground under them, following the slopes and stepping
+// down the cliffs. OnCellChanged, when set, is called when a character
+// enters a new cell, including the one it is added on.
+type GroundPlacementSystem struct {
+	Ground        *Ground
+	OnCellChanged func(char *entity.Character, x, y int)
+
+	placed []*placedCharacter
+}
+
+// placedCharacter is a character, with where it was last placed.
+type placedCharacter struct {
+	char     *entity.Character
+	position mgl32.Vec2
+	cellX    int
+	cellY    int
+	known    bool
+}
+
+// NewGroundPlacementSystem returns the system of the characters on
+// ground.
+func NewGroundPlacementSystem(ground *Ground) *GroundPlacementSystem {
+	return &GroundPlacementSystem{Ground: ground}
+}
+
+func (s *GroundPlacementSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o.(*entity.Character))
+}
+
+// Add places char on the ground from the next update.
+func (s *GroundPlacementSystem) Add(char *entity.Character) {
+	s.placed = append(s.placed, &placedCharacter{char: char})
+}
+
+func (s *GroundPlacementSystem) Remove(e ecs.BasicEntity) {
+	for i, p := range s.placed {
+		if p.char.ID() == e.ID() {
+			s.placed = append(s.placed[:i], s.placed[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *GroundPlacementSystem) Update(dt float32) {
+	for _, p := range s.placed {
+		position := p.char.Position()
+		if p.known && position.Vec2() == p.position {
+			continue
+		}
+		p.position = position.Vec2()
+
+		if z, ok := s.Ground.Altitude(position); ok && z != position.Z() {
+			p.char.SetPosition(mgl32.Vec3{position.X(), position.Y(), z})
+		}
+
+		fx, fy := s.Ground.Cell(position)
+		x, y := int(math.Floor(float64(fx))), int(math.Floor(float64(fy)))
+		if p.known && x == p.cellX && y == p.cellY {
+			continue
+		}
+		p.cellX, p.cellY, p.known = x, y, true
+
+		if s.OnCellChanged != nil {
+			s.OnCellChanged(p.char, x, y)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+)
+
+// newSlopeGround returns a ground of 4 by 2 cells centered on the origin:
+// a flat cell at the altitude -2, then sloping up east by a cell per cell
+// (the altitudes growing downwards), then a cliff.
+func newSlopeGround() *Ground {
+	u := float32(gat.UnitsPerCell)
+	cells := make([]gat.Cell, 8)
+	for i := range cells {
+		x := i % 4
+		switch x {
+		case 0:
+			cells[i].Cells = [4]float32{2 * u, 2 * u, 2 * u, 2 * u}
+		case 1, 2:
+			west, east := float32(3-x)*u, float32(2-x)*u
+			cells[i].Cells = [4]float32{west, east, west, east}
+		default:
+			cells[i].Cells = [4]float32{-4 * u, -4 * u, -4 * u, -4 * u}
+		}
+	}
+
+	return &Ground{GAT: &gat.GroundAltitudeFile{Width: 4, Height: 2, Cells: cells}}
+}
+
+func TestGroundAltitude(t *testing.T) {
+	g := newSlopeGround()
+
+	// The east is along -X; the map spans X from 2 to -2.
+	for _, tt := range []struct {
+		X        float32
+		Altitude float32
+	}{
+		{X: 1.5, Altitude: 2},
+		{X: 0.5, Altitude: 1.5},
+		{X: 0, Altitude: 1},
+		{X: -0.5, Altitude: 0.5},
+		{X: -1.5, Altitude: -4},
+	} {
+		z, ok := g.Altitude(mgl32.Vec3{tt.X, 0.5, 0})
+		assert.True(t, ok)
+		assert.InDelta(t, tt.Altitude, z, 1e-4, "x %v", tt.X)
+	}
+
+	_, ok := g.Altitude(mgl32.Vec3{3, 0, 0})
+	assert.False(t, ok)
+	assert.Equal(t, mgl32.Vec3{3, 0, 7}, g.Anchor(mgl32.Vec3{3, 0, 7}))
+
+	// The GND wins where it has surfaces, 2 by 2 cells each.
+	g.GND = &gnd.GroundFile{Width: 2, Height: 1, Surfaces: []gnd.Surface{{}, {Heights: [4]float32{-50, -50, -50, -50}}}}
+	z, _ := g.Altitude(mgl32.Vec3{1.5, 0.5, 0})
+	assert.Equal(t, float32(0), z)
+	z, _ = g.Altitude(mgl32.Vec3{-1.5, 0.5, 0})
+	assert.Equal(t, float32(-10), z)
+}
+
+func TestGroundPlacementSystem(t *testing.T) {
+	s := NewGroundPlacementSystem(newSlopeGround())
+
+	var cells [][2]int
+	s.OnCellChanged = func(char *entity.Character, x, y int) {
+		cells = append(cells, [2]int{x, y})
+	}
+
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	char.SetPosition(mgl32.Vec3{1.5, 0.5, 0})
+	s.Add(char)
+
+	s.Update(0.1)
+	assert.Equal(t, mgl32.Vec3{1.5, 0.5, 2}, char.Position())
+	assert.Equal(t, [][2]int{{0, 1}}, cells)
+
+	// Up the slope, in the next cell.
+	char.SetPosition(mgl32.Vec3{0.5, 0.5, 2})
+	s.Update(0.1)
+	assert.InDelta(t, 1.5, char.Position().Z(), 1e-4)
+	char.SetPosition(mgl32.Vec3{0.25, 0.5, 2})
+	s.Update(0.1)
+	assert.Equal(t, [][2]int{{0, 1}, {1, 1}}, cells)
+
+	s.Remove(*char.BasicEntity)
+	char.SetPosition(mgl32.Vec3{-1.5, 0.5, 0})
+	s.Update(0.1)
+	assert.Equal(t, float32(0), char.Position().Z())
+}
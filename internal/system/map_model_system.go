@@ -0,0 +1,202 @@
+package system
+
+import (
+	"context"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/camera"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsm"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/internal/mapmodel"
+	"github.com/project-midgard/midgarts/internal/mapstream"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// MapModelSystem draws the models of a map streamed around the camera
+// (see mapstream): the models of the regions loaded are merged in batches
+// (see mapmodel) on a goroutine, built again when the regions loaded
+// change, and drawn by the render system.
+type MapModelSystem struct {
+	// done is closed with the context of the system, stopping the
+	// streaming.
+	done            <-chan struct{}
+	streamer        *mapstream.Streamer
+	ground          *Ground
+	cam             *camera.Camera
+	render          *opengl.RenderSystem
+	textureProvider graphic.TextureProvider
+
+	builds chan []mapstream.Model
+	scenes chan builtScene
+	// building is true from a build requested to its scene received.
+	building bool
+	// loaded are the regions of the last build requested.
+	loaded  map[mapstream.Region]bool
+	batches *opengl.ModelBatches
+}
+
+// builtScene is the scene of the models of the regions loaded, with the
+// images of its textures.
+type builtScene struct {
+	scene  *mapmodel.Scene
+	images map[string]*graphic.UniqueRGBA
+}
+
+// NewMapModelSystem returns the system drawing the models of streamer,
+// placed on ground, around the camera cam, with render. The textures are
+// read with reader; ctx stops the builds and carries their logger (see
+// logging.For).
+func NewMapModelSystem(
+	ctx context.Context,
+	streamer *mapstream.Streamer,
+	reader mapmodel.EntriesReader,
+	ground *Ground,
+	cam *camera.Camera,
+	render *opengl.RenderSystem,
+	textureProvider graphic.TextureProvider,
+) *MapModelSystem {
+	s := &MapModelSystem{
+		done:            ctx.Done(),
+		streamer:        streamer,
+		ground:          ground,
+		cam:             cam,
+		render:          render,
+		textureProvider: textureProvider,
+		builds:          make(chan []mapstream.Model, 1),
+		scenes:          make(chan builtScene, 1),
+	}
+	go s.build(ctx, reader, ground.Center)
+
+	return s
+}
+
+// build merges the models of the builds requested, until ctx is done. The
+// textures are read once, the images kept from a build to the next.
+func (s *MapModelSystem) build(ctx context.Context, reader mapmodel.EntriesReader, center mgl32.Vec3) {
+	log := logging.For(ctx, logging.MapModel)
+	images := map[string]*graphic.UniqueRGBA{}
+	read := map[string]bool{}
+
+	for {
+		var models []mapstream.Model
+		select {
+		case <-ctx.Done():
+			return
+		case models = <-s.builds:
+		}
+
+		world := &rsw.ResourceWorldFile{}
+		files := map[string]*rsm.ModelFile{}
+		for _, m := range models {
+			world.Models = append(world.Models, m.Model)
+			files[m.FileName] = m.File
+		}
+		scene := mapmodel.Build(world, files, center)
+		log.Debug().Int("models", len(scene.Objects)).Int("batches", len(scene.Batches)).Msg("map models merged")
+
+		unread := &mapmodel.Scene{}
+		for _, b := range scene.Batches {
+			if !read[b.Texture] {
+				read[b.Texture] = true
+				unread.Batches = append(unread.Batches, b)
+			}
+		}
+		for name, img := range mapmodel.LoadTextures(ctx, reader, unread) {
+			images[name] = img
+		}
+
+		// The images of the scene only, the map being read by the main
+		// thread.
+		built := builtScene{scene: scene, images: map[string]*graphic.UniqueRGBA{}}
+		for _, b := range scene.Batches {
+			if img, ok := images[b.Texture]; ok {
+				built.images[b.Texture] = img
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case s.scenes <- built:
+		}
+	}
+}
+
+// Update streams the regions around the cell of the camera, draws the
+// last scene built, and requests a build when the regions loaded changed.
+// It runs on the main thread, as the batches are drawn by the render
+// system.
+func (s *MapModelSystem) Update(dt float32) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	s.streamer.Update(s.ground.Cell(s.cam.Position()))
+
+	select {
+	case built := <-s.scenes:
+		s.building = false
+		s.setBatches(opengl.NewModelBatches(built.scene, built.images, s.textureProvider))
+	default:
+	}
+
+	if s.building {
+		return
+	}
+
+	loaded := map[mapstream.Region]bool{}
+	var models []mapstream.Model
+	for _, r := range s.streamer.Regions() {
+		if m, ok := s.streamer.Models(r); ok {
+			loaded[r] = true
+			models = append(models, m...)
+		}
+	}
+	if sameRegions(loaded, s.loaded) {
+		return
+	}
+
+	s.loaded, s.building = loaded, true
+	s.builds <- models
+}
+
+func sameRegions(a, b map[mapstream.Region]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for r := range a {
+		if !b[r] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setBatches draws batches in place of the previous ones, closed.
+func (s *MapModelSystem) setBatches(batches *opengl.ModelBatches) {
+	previous := s.batches
+	s.batches = batches
+	s.render.Models = batches
+	if previous != nil {
+		previous.Close()
+	}
+}
+
+func (s *MapModelSystem) Remove(ecs.BasicEntity) {}
+
+// Close releases the regions and the batches.
+func (s *MapModelSystem) Close() {
+	s.streamer.Close()
+	if s.batches != nil {
+		s.batches.Close()
+		s.batches = nil
+		s.render.Models = nil
+	}
+}
@@ -0,0 +1,94 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/camera"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+	"github.com/project-midgard/midgarts/internal/fileformat/rsw"
+	"github.com/project-midgard/midgarts/internal/mapstream"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// modelLoader serves an RSM file without texture nor node for every name.
+type modelLoader struct{}
+
+func (modelLoader) GetEntryContext(ctx context.Context, name string) (*grf.Entry, error) {
+	var buf bytes.Buffer
+	buf.WriteString("GRSM")
+	buf.Write([]byte{1, 4})
+	_ = binary.Write(&buf, binary.LittleEndian, [2]int32{0, 0})
+	buf.WriteByte(0xFF)
+	buf.Write(make([]byte, 16))
+	_ = binary.Write(&buf, binary.LittleEndian, int32(0))
+	buf.Write(make([]byte, 40))
+	_ = binary.Write(&buf, binary.LittleEndian, int32(0))
+
+	return &grf.Entry{Data: buf.Bytes()}, nil
+}
+
+func (modelLoader) ReadEntriesContext(ctx context.Context, names []string, workers int) ([]*grf.Entry, error) {
+	return make([]*grf.Entry, len(names)), nil
+}
+
+// waitBuild updates s until the render system draws the batches of the
+// 9 regions around the camera, region among them.
+func waitBuild(t *testing.T, s *MapModelSystem, render *opengl.RenderSystem, region mapstream.Region) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.Update(0)
+		if render.Models != nil && !s.building && len(s.loaded) == 9 && s.loaded[region] {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("the models were not built")
+}
+
+func TestMapModelSystem(t *testing.T) {
+	// A model at the center of each region of a map of 256 by 256 cells.
+	world := &rsw.ResourceWorldFile{}
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			cellX, cellY := float32(x*mapstream.RegionSize+mapstream.RegionSize/2), float32(y*mapstream.RegionSize+mapstream.RegionSize/2)
+			world.Models = append(world.Models, rsw.Model{
+				FileName: "a.rsm",
+				Position: mgl32.Vec3{(cellX - 128) * gat.UnitsPerCell, 0, (cellY - 128) * gat.UnitsPerCell},
+			})
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ground := &Ground{GAT: &gat.GroundAltitudeFile{Width: 256, Height: 256}}
+	cam := camera.NewPerspectiveCamera(45, 1, 0.1, 1000)
+	// Over the cell 80, 100, of the region 2, 3.
+	cam.SetPosition(mgl32.Vec3{128 - 80, 100 - 128, -30})
+	render := &opengl.RenderSystem{}
+	streamer := mapstream.NewStreamer(ctx, modelLoader{}, world, 256, 256)
+
+	s := NewMapModelSystem(ctx, streamer, modelLoader{}, ground, cam, render, &staticTextures{})
+	defer s.Close()
+
+	waitBuild(t, s, render, mapstream.Region{X: 2, Y: 3})
+
+	// The camera moves to another region: the batches are built again.
+	previous := render.Models
+	cam.SetPosition(mgl32.Vec3{128 - 200, 200 - 128, -30})
+	waitBuild(t, s, render, mapstream.Region{X: 6, Y: 6})
+	assert.NotSame(t, previous, render.Models)
+	assert.False(t, s.loaded[mapstream.Region{X: 2, Y: 3}])
+
+	s.Close()
+	assert.Nil(t, render.Models)
+}
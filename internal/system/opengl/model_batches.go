@@ -0,0 +1,172 @@
+package opengl
+
+import (
+	"expvar"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/gl"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/mapmodel"
+	"github.com/project-midgard/midgarts/internal/metrics"
+	"github.com/project-midgard/midgarts/internal/opengl"
+)
+
+// ModelBatches draws the batches of the models of a map (see mapmodel),
+// the models out of the view culled, or of its ground (see mapground). The
+// vertices are uploaded once, on the first frame, the ones of the animated
+// models again at every frame.
+type ModelBatches struct {
+	// Visible and DrawCalls are set to the number of objects in the view
+	// and of draw calls of the last frame, metrics.ModelsVisible and
+	// metrics.ModelDrawCalls by default.
+	Visible, DrawCalls *expvar.Int
+
+	scene           *mapmodel.Scene
+	images          map[string]*graphic.UniqueRGBA
+	textureProvider graphic.TextureProvider
+
+	batches []*modelBatch
+	byBatch map[*mapmodel.Batch]*modelBatch
+	visible []bool
+	draws   []mapmodel.Range
+	changed []*mapmodel.Batch
+}
+
+type modelBatch struct {
+	*mapmodel.Batch
+	image     *graphic.UniqueRGBA
+	vao       uint32
+	buffers   [2]uint32
+	positions []float32
+	uploaded  bool
+}
+
+// NewModelBatches returns the batches of scene, textured with images by
+// texture name. The batches without image are not drawn.
+func NewModelBatches(scene *mapmodel.Scene, images map[string]*graphic.UniqueRGBA, textureProvider graphic.TextureProvider) *ModelBatches {
+	m := &ModelBatches{
+		Visible:         metrics.ModelsVisible,
+		DrawCalls:       metrics.ModelDrawCalls,
+		scene:           scene,
+		images:          images,
+		textureProvider: textureProvider,
+		byBatch:         map[*mapmodel.Batch]*modelBatch{},
+	}
+	for _, b := range scene.Batches {
+		if img, ok := images[b.Texture]; ok && len(b.Vertices) > 0 {
+			batch := &modelBatch{Batch: b, image: img}
+			m.batches = append(m.batches, batch)
+			m.byBatch[b] = batch
+		}
+	}
+
+	return m
+}
+
+// render draws the batches seen by the camera of view and projection, with
+// the model shader in use, the animated models posed at elapsed seconds.
+func (m *ModelBatches) render(view, projection mgl32.Mat4, elapsed float32) {
+	if len(m.scene.Animations) > 0 {
+		m.changed = m.scene.Animate(elapsed*1000, m.changed[:0])
+		for _, b := range m.changed {
+			if batch, ok := m.byBatch[b]; ok && batch.uploaded {
+				batch.updatePositions()
+			}
+		}
+	}
+
+	var seen int
+	m.visible, seen = m.scene.Cull(mapmodel.NewFrustum(projection.Mul4(view)), m.visible)
+	m.Visible.Set(int64(seen))
+
+	var calls int64
+	for _, b := range m.batches {
+		m.draws = b.Draws(m.visible, m.draws[:0])
+		if len(m.draws) == 0 {
+			continue
+		}
+
+		texture, err := m.textureProvider.NewTextureFromRGBA(b.image, graphic.TextureGround)
+		if err != nil {
+			continue
+		}
+		texture.Bind(0)
+		// The models repeat their textures.
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
+
+		b.upload()
+		gl.BindVertexArray(b.vao)
+		for _, r := range m.draws {
+			gl.DrawArrays(gl.TRIANGLES, int32(r.First), int32(r.Count))
+			calls++
+		}
+	}
+	gl.BindVertexArray(0)
+	m.DrawCalls.Set(calls)
+}
+
+// upload creates the vertex array of b, on its first draw.
+func (b *modelBatch) upload() {
+	if b.uploaded {
+		return
+	}
+
+	positions := b.vertexPositions()
+	uvs := make([]float32, 0, 2*len(b.Vertices))
+	for _, v := range b.Vertices {
+		uvs = append(uvs, v.UV[:]...)
+	}
+
+	gl.GenVertexArrays(1, &b.vao)
+	gl.BindVertexArray(b.vao)
+	gl.GenBuffers(2, &b.buffers[0])
+	for i, attribute := range []struct {
+		location uint32
+		size     int32
+		data     []float32
+	}{
+		{uint32(opengl.VertexPosition), 3, positions},
+		{uint32(opengl.VertexTexCoord), 2, uvs},
+	} {
+		gl.BindBuffer(gl.ARRAY_BUFFER, b.buffers[i])
+		gl.BufferData(gl.ARRAY_BUFFER, len(attribute.data)*4, gl.Ptr(attribute.data), gl.STATIC_DRAW)
+		gl.EnableVertexAttribArray(attribute.location)
+		gl.VertexAttribPointer(attribute.location, attribute.size, gl.FLOAT, false, 0, gl.PtrOffset(0))
+	}
+	gl.BindVertexArray(0)
+
+	b.uploaded = true
+}
+
+// vertexPositions returns the positions of the vertices of b, in the
+// buffer of b.
+func (b *modelBatch) vertexPositions() []float32 {
+	b.positions = b.positions[:0]
+	for _, v := range b.Vertices {
+		b.positions = append(b.positions, v.Position[:]...)
+	}
+
+	return b.positions
+}
+
+// updatePositions uploads the positions of the vertices of b again.
+func (b *modelBatch) updatePositions() {
+	positions := b.vertexPositions()
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.buffers[0])
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(positions)*4, gl.Ptr(positions))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// Close deletes the vertex arrays and the textures of the batches.
+func (m *ModelBatches) Close() {
+	for _, b := range m.batches {
+		if b.uploaded {
+			gl.DeleteBuffers(2, &b.buffers[0])
+			gl.DeleteVertexArrays(1, &b.vao)
+			b.uploaded = false
+		}
+		m.textureProvider.DeleteTexture(b.image)
+	}
+}
@@ -0,0 +1,314 @@
+package opengl
+
+import (
+	_ "embed"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/gl"
+	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+	"github.com/project-midgard/midgarts/internal/opengl"
+)
+
+//go:embed shaders/post.vert
+var postVertexShader string
+
+//go:embed shaders/post/crt.frag
+var crtFragmentShader string
+
+//go:embed shaders/post/bloom.frag
+var bloomFragmentShader string
+
+//go:embed shaders/post/grading.frag
+var gradingFragmentShader string
+
+// PostPass is a post-processing pass: a fragment shader run over the whole
+// frame once it is drawn, e.g. a bloom, a CRT filter or a color grading.
+// The passes of a render system run in their order, each over the frame
+// of the one before.
+//
+// Fragment is the GLSL 330 source of the shader. It gets the frame in
+// `uniform sampler2D frame` and the position of its pixel in it in
+// `in vec2 uv`, from 0 to 1, the size of the frame in pixels in
+// `uniform vec2 resolution` and the seconds since the first frame in
+// `uniform float time`.
+type PostPass struct {
+	Name     string
+	Fragment string
+	// Uniforms, when set, sets the other uniforms of the shader, before
+	// every run. program is in use.
+	Uniforms func(program uint32)
+}
+
+// postPass is a PostPass of a render system, with its program.
+type postPass struct {
+	*PostPass
+	shader *opengl.State
+	// failed is set when the shader could not be compiled, the pass
+	// skipped.
+	failed bool
+}
+
+// NewCRTPass returns a pass making the frame look like the one of a tube
+// screen.
+func NewCRTPass() *PostPass {
+	return &PostPass{Name: "crt", Fragment: crtFragmentShader}
+}
+
+// NewBloomPass returns a pass making the parts of the frame brighter than
+// threshold, from 0 to 1, glow by intensity.
+func NewBloomPass(threshold, intensity float32) *PostPass {
+	return &PostPass{
+		Name:     "bloom",
+		Fragment: bloomFragmentShader,
+		Uniforms: func(program uint32) {
+			gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("threshold\x00")), threshold)
+			gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("intensity\x00")), intensity)
+		},
+	}
+}
+
+// ColorGrading changes the colors of the frame.
+type ColorGrading struct {
+	// Saturation and Contrast scale the ones of the frame, 1 keeping
+	// them; a saturation of 0 is a grayscale.
+	Saturation, Contrast float32
+	// Balance multiplies the colors, the zero balance none.
+	Balance mgl32.Vec3
+}
+
+// NewColorGradingPass returns a pass grading the colors of the frame.
+func NewColorGradingPass(g ColorGrading) *PostPass {
+	if g.Balance == (mgl32.Vec3{}) {
+		g.Balance = mgl32.Vec3{1, 1, 1}
+	}
+
+	return &PostPass{
+		Name:     "grading",
+		Fragment: gradingFragmentShader,
+		Uniforms: func(program uint32) {
+			gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("saturation\x00")), g.Saturation)
+			gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("contrast\x00")), g.Contrast)
+			gl.Uniform3fv(gl.GetUniformLocation(program, gl.Str("balance\x00")), 1, &g.Balance[0])
+		},
+	}
+}
+
+// BuiltinPostPass returns the built-in pass of name, with its default
+// settings: "crt", "bloom", "grading" (warmer and more saturated) or
+// "grayscale".
+func BuiltinPostPass(name string) (*PostPass, error) {
+	switch name {
+	case "crt":
+		return NewCRTPass(), nil
+	case "bloom":
+		return NewBloomPass(0.7, 1.5), nil
+	case "grading":
+		return NewColorGradingPass(ColorGrading{Saturation: 1.2, Contrast: 1.1, Balance: mgl32.Vec3{1.05, 1, 0.95}}), nil
+	case "grayscale":
+		pass := NewColorGradingPass(ColorGrading{Saturation: 0, Contrast: 1})
+		pass.Name = "grayscale"
+		return pass, nil
+	}
+
+	return nil, errors.Errorf("unknown post-processing pass '%s'", name)
+}
+
+// AddPostPass appends pass to the passes of the system, replacing the one
+// of the same name, if any.
+func (s *RenderSystem) AddPostPass(pass *PostPass) {
+	for i, p := range s.posts {
+		if p.Name == pass.Name {
+			p.delete()
+			s.posts[i] = &postPass{PostPass: pass}
+			return
+		}
+	}
+
+	s.posts = append(s.posts, &postPass{PostPass: pass})
+}
+
+// RemovePostPass removes the pass of name, and tells whether there was
+// one. It must be called from the render thread.
+func (s *RenderSystem) RemovePostPass(name string) bool {
+	for i, p := range s.posts {
+		if p.Name == name {
+			p.delete()
+			s.posts = append(s.posts[:i], s.posts[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// PostPasses returns the passes of the system, in their order.
+func (s *RenderSystem) PostPasses() []*PostPass {
+	passes := make([]*PostPass, len(s.posts))
+	for i, p := range s.posts {
+		passes[i] = p.PostPass
+	}
+
+	return passes
+}
+
+func (p *postPass) delete() {
+	if p.shader != nil {
+		gl.DeleteProgram(p.shader.Program().ID())
+		p.shader = nil
+	}
+}
+
+// renderTarget is a framebuffer the frame is drawn to, to be sampled by
+// the next pass.
+type renderTarget struct {
+	framebuffer, texture, depth uint32
+	width, height               int32
+}
+
+func newRenderTarget(width, height int32) (*renderTarget, error) {
+	t := &renderTarget{width: width, height: height}
+
+	gl.GenTextures(1, &t.texture)
+	gl.BindTexture(gl.TEXTURE_2D, t.texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, width, height, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.GenRenderbuffers(1, &t.depth)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, t.depth)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, width, height)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	gl.GenFramebuffers(1, &t.framebuffer)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffer)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.texture, 0)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, t.depth)
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		t.delete()
+		return nil, errors.Errorf("incomplete framebuffer (status 0x%x)", status)
+	}
+
+	return t, nil
+}
+
+func (t *renderTarget) delete() {
+	gl.DeleteFramebuffers(1, &t.framebuffer)
+	gl.DeleteRenderbuffers(1, &t.depth)
+	gl.DeleteTextures(1, &t.texture)
+}
+
+// beginPostProcessing draws the frame to a render target when there are
+// passes, and returns the framebuffer the frame goes to in the end, to
+// be handed to endPostProcessing; ok is false without pass.
+func (s *RenderSystem) beginPostProcessing() (screen uint32, ok bool, err error) {
+	if len(s.posts) == 0 {
+		s.deleteTargets()
+		return 0, false, nil
+	}
+
+	var binding int32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &binding)
+	var viewport [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+
+	width, height := viewport[2], viewport[3]
+	if s.targets[0] == nil || s.targets[0].width != width || s.targets[0].height != height {
+		s.deleteTargets()
+		for i := range s.targets {
+			if s.targets[i], err = newRenderTarget(width, height); err != nil {
+				s.deleteTargets()
+				gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(binding))
+				return 0, false, err
+			}
+		}
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, s.targets[0].framebuffer)
+	return uint32(binding), true, nil
+}
+
+// endPostProcessing runs the passes over the frame drawn to the first
+// render target, the last one drawing to screen.
+func (s *RenderSystem) endPostProcessing(screen uint32) {
+	passes := s.posts[:0:0]
+	for _, p := range s.posts {
+		if p.failed {
+			continue
+		}
+		if p.shader == nil {
+			shader, err := opengl.NewShader(postVertexShader, p.Fragment)
+			if err != nil {
+				s.log.Error().Err(err).Str("pass", p.Name).Msg("could not compile post-processing pass, skipping it")
+				p.failed = true
+				continue
+			}
+			p.shader = shader
+		}
+		passes = append(passes, p)
+	}
+
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.BLEND)
+	defer gl.Enable(gl.DEPTH_TEST)
+	defer gl.Enable(gl.BLEND)
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+
+	source := s.targets[0]
+	resolution := mgl32.Vec2{float32(source.width), float32(source.height)}
+	for i, p := range passes {
+		if i == len(passes)-1 {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, screen)
+		} else {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, s.targets[(i+1)%2].framebuffer)
+		}
+
+		pid := p.shader.Program().ID()
+		gl.UseProgram(pid)
+		gl.ActiveTexture(gl.TEXTURE0)
+		gl.BindTexture(gl.TEXTURE_2D, source.texture)
+		gl.Uniform1i(gl.GetUniformLocation(pid, gl.Str("frame\x00")), 0)
+		gl.Uniform2fv(gl.GetUniformLocation(pid, gl.Str("resolution\x00")), 1, &resolution[0])
+		gl.Uniform1f(gl.GetUniformLocation(pid, gl.Str("time\x00")), s.elapsed)
+		if p.Uniforms != nil {
+			p.Uniforms(pid)
+		}
+
+		s.screenQuad().Render(p.shader)
+		source = s.targets[(i+1)%2]
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	// Every pass failed: the frame is copied as it is.
+	if len(passes) == 0 {
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, s.targets[0].framebuffer)
+		gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, screen)
+		w, h := s.targets[0].width, s.targets[0].height
+		gl.BlitFramebuffer(0, 0, w, h, 0, 0, w, h, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, screen)
+	}
+}
+
+// screenQuad returns the quad covering the screen, from -1 to 1 on both
+// axes.
+func (s *RenderSystem) screenQuad() *geometry.Plane {
+	if s.quad == nil {
+		s.quad = geometry.NewPlane(2, 2, nil)
+	}
+
+	return s.quad
+}
+
+func (s *RenderSystem) deleteTargets() {
+	for i, t := range s.targets {
+		if t != nil {
+			t.delete()
+			s.targets[i] = nil
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package opengl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostPasses(t *testing.T) {
+	s := NewOpenGLRenderSystem(context.Background(), nil, &RenderCommands{})
+
+	crt, err := BuiltinPostPass("crt")
+	require.NoError(t, err)
+	bloom, err := BuiltinPostPass("bloom")
+	require.NoError(t, err)
+	s.AddPostPass(crt)
+	s.AddPostPass(bloom)
+
+	// Replaced in place.
+	custom := &PostPass{Name: "crt", Fragment: "#version 330 core"}
+	s.AddPostPass(custom)
+	assert.Equal(t, []*PostPass{custom, bloom}, s.PostPasses())
+
+	assert.True(t, s.RemovePostPass("crt"))
+	assert.False(t, s.RemovePostPass("crt"))
+	assert.Equal(t, []*PostPass{bloom}, s.PostPasses())
+}
+
+func TestBuiltinPostPass(t *testing.T) {
+	for _, name := range []string{"crt", "bloom", "grading", "grayscale"} {
+		pass, err := BuiltinPostPass(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, name, pass.Name)
+		assert.Contains(t, pass.Fragment, "uniform sampler2D frame;", name)
+	}
+
+	_, err := BuiltinPostPass("sepia")
+	assert.EqualError(t, err, "unknown post-processing pass 'sepia'")
+}
+
+func TestSetShader(t *testing.T) {
+	s := NewOpenGLRenderSystem(context.Background(), nil, &RenderCommands{})
+
+	assert.NoError(t, s.SetShader(ModelShader, ShaderSource{Fragment: "#version 330 core"}))
+	assert.Equal(t, "#version 330 core", s.sources[ModelShader].Fragment)
+	assert.Error(t, s.SetShader(shaderCount, ShaderSource{}))
+}
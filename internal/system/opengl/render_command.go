@@ -13,5 +13,39 @@ type SpriteRenderCommand struct {
 	Offset          mgl32.Vec2
 	RotationRadians float32
 	Texture         *graphic.Texture
-	FlipVertically  bool
+	// UV is the part of Texture drawn, its top left then bottom right
+	// texture coordinates (see graphic.Atlas), the zero UV the whole
+	// texture.
+	UV mgl32.Vec4
+	// FlipHorizontally mirrors the sprite left to right, as the mirrored
+	// layers of the ACT files, and FlipVertically top to bottom. The
+	// sprite is flipped around its center, before its rotation.
+	FlipHorizontally bool
+	FlipVertically   bool
+	// Tint multiplies the colors of the sprite by its color, blended by
+	// its alpha, e.g. to show a status effect; the zero tint none.
+	Tint mgl32.Vec4
+
+	// Key identifies the sprite from a frame to the next, the zero key
+	// none. The sprite of a key keeps its vertices on the GPU: unless
+	// Dirty is set, it is drawn with the size it had the frame before,
+	// without uploading anything. Systems set Dirty when the size or the
+	// flips of a sprite changed, or it is new.
+	Key   SpriteKey
+	Dirty bool
+}
+
+// HUDRenderCommand draws a texture over the frame, after the passes, e.g.
+// the developer console.
+type HUDRenderCommand struct {
+	Texture *graphic.Texture
+	// Position is the top left corner of the texture and Size its size,
+	// in fractions of the screen from its top left corner.
+	Position, Size mgl32.Vec2
+}
+
+// SpriteKey identifies a sprite: the Index-th sprite of the Entity.
+type SpriteKey struct {
+	Entity uint64
+	Index  int
 }
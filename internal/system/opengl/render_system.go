@@ -1,18 +1,23 @@
 package opengl
 
 import (
+	"context"
 	_ "embed"
 
 	"github.com/EngoEngine/ecs"
-	"github.com/go-gl/gl/v3.2-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/rs/zerolog"
 
 	"github.com/project-midgard/midgarts/internal/camera"
+	"github.com/project-midgard/midgarts/internal/gl"
 	"github.com/project-midgard/midgarts/internal/graphic"
 	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+	"github.com/project-midgard/midgarts/internal/logging"
 	"github.com/project-midgard/midgarts/internal/opengl"
 )
 
+// The sources of the built-in shaders, see SetShader.
+//
 //go:embed shaders/box.vert
 var boxVertexShader string
 
@@ -25,49 +30,230 @@ var spriteVertexShader string
 //go:embed shaders/sprite.frag
 var spriteFragmentShader string
 
+//go:embed shaders/model.vert
+var modelVertexShader string
+
+//go:embed shaders/model.frag
+var modelFragmentShader string
+
+//go:embed shaders/overlay.vert
+var overlayVertexShader string
+
+//go:embed shaders/overlay.frag
+var overlayFragmentShader string
+
+//go:embed shaders/hud.vert
+var hudVertexShader string
+
+//go:embed shaders/hud.frag
+var hudFragmentShader string
+
 type RenderCommands struct {
 	Sprites []SpriteRenderCommand
+	// Fade darkens the frame, from 0 (unchanged) to 1 (black).
+	Fade float32
+	// Overlay tints the whole screen over the sprites, its alpha the
+	// opacity of the tint, the zero color none.
+	Overlay mgl32.Vec4
+	// HUD is drawn over the frame, in its order.
+	HUD []HUDRenderCommand
 }
 
-// RenderSystem defines an OpenGL-based rendering system.
+// RenderSystem defines an OpenGL-based rendering system. Its shaders can
+// be replaced (see SetShader), and the frame post-processed (see
+// AddPostPass).
 type RenderSystem struct {
+	log            zerolog.Logger
 	cam            *camera.Camera
 	renderCommands *RenderCommands
 
-	// Buffer of reusable sprites
+	// Ground and Models, when set, are the ground and the models of the
+	// map (see mapground and mapmodel), drawn behind the sprites.
+	Ground *ModelBatches
+	Models *ModelBatches
+
+	// Compiled on their first use, from sources when set.
+	shaders [shaderCount]*opengl.State
+	sources [shaderCount]ShaderSource
+	// quad covers the screen, for the overlay and the passes.
+	quad *geometry.Plane
+
+	posts []*postPass
+	// targets are the frames the passes draw from and to, sized as the
+	// viewport.
+	targets [2]*renderTarget
+	// elapsed is the time since the first frame, in seconds.
+	elapsed float32
+
+	// Buffer of reusable sprites, and of their boxes
 	spritesBuf []*geometry.Plane
+	boxesBuf   []*geometry.Plane
+	// keyed holds the planes of the sprites with a key, free the planes
+	// of the keys that were not drawn during the last frame.
+	keyed map[SpriteKey]*keyedPlane
+	free  []*geometry.Plane
+	frame uint64
 }
 
-func NewOpenGLRenderSystem(cam *camera.Camera, commands *RenderCommands) *RenderSystem {
+type keyedPlane struct {
+	plane     *geometry.Plane
+	lastDrawn uint64
+}
+
+// NewOpenGLRenderSystem returns the system, logging to the logger of ctx
+// (see logging.For).
+func NewOpenGLRenderSystem(ctx context.Context, cam *camera.Camera, commands *RenderCommands) *RenderSystem {
 	return &RenderSystem{
+		log:            logging.For(ctx, logging.OpenGL),
 		cam:            cam,
 		renderCommands: commands,
 		spritesBuf:     []*geometry.Plane{},
+		keyed:          map[SpriteKey]*keyedPlane{},
 	}
 }
 
 func (s *RenderSystem) Update(dt float32) {
+	s.elapsed += dt
+
+	screen, post, err := s.beginPostProcessing()
+	if err != nil {
+		s.log.Error().Err(err).Msg("could not post-process the frame")
+	}
+
+	brightness := 1 - s.renderCommands.Fade
+	gl.ClearColor(opengl.ClearColor[0]*brightness, opengl.ClearColor[1]*brightness, opengl.ClearColor[2]*brightness, opengl.ClearColor[3])
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
+	if err := s.renderModels(); err != nil {
+		s.log.Error().Err(err).Msg("could not render models")
+	}
+
 	// 2D Plane Box
-	s.renderSpriteBoxes()
+	if err := s.renderSpriteBoxes(); err != nil {
+		s.log.Error().Err(err).Msg("could not render sprite boxes")
+	}
 
 	// 2D Sprites
-	s.renderSprites()
+	if err := s.renderSprites(); err != nil {
+		s.log.Error().Err(err).Msg("could not render sprites")
+	}
+
+	if err := s.renderOverlay(); err != nil {
+		s.log.Error().Err(err).Msg("could not render overlay")
+	}
+
+	if post {
+		s.endPostProcessing(screen)
+	}
+
+	if err := s.renderHUD(); err != nil {
+		s.log.Error().Err(err).Msg("could not render HUD")
+	}
 }
 
-func (s *RenderSystem) renderSpriteBoxes() {
-	shader := opengl.NewShader(boxVertexShader, boxFragmentShader)
+// renderModels draws the ground and the models of the map, if any.
+func (s *RenderSystem) renderModels() error {
+	if s.Ground == nil && s.Models == nil {
+		return nil
+	}
+
+	shader, err := s.useShader(ModelShader)
+	if err != nil {
+		return err
+	}
 	pid := shader.Program().ID()
-	gl.UseProgram(pid)
 
-	for _, cmd := range s.renderCommands.Sprites {
-		if cmd.FlipVertically {
-			cmd.Size = mgl32.Vec2{-cmd.Size.X(), cmd.Size.Y()}
+	view := s.cam.ViewMatrix()
+	viewu := gl.GetUniformLocation(pid, gl.Str("view\x00"))
+	gl.UniformMatrix4fv(viewu, 1, false, &view[0])
+
+	projection := s.cam.ProjectionMatrix()
+	projectionu := gl.GetUniformLocation(pid, gl.Str("projection\x00"))
+	gl.UniformMatrix4fv(projectionu, 1, false, &projection[0])
+
+	brightnessu := gl.GetUniformLocation(pid, gl.Str("brightness\x00"))
+	gl.Uniform1f(brightnessu, 1-s.renderCommands.Fade)
+
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	for _, batches := range []*ModelBatches{s.Ground, s.Models} {
+		if batches != nil {
+			batches.render(view, projection, s.elapsed)
 		}
+	}
+
+	return nil
+}
+
+// renderOverlay tints the screen with the overlay color, if any.
+func (s *RenderSystem) renderOverlay() error {
+	color := s.renderCommands.Overlay
+	if color.W() <= 0 {
+		return nil
+	}
+
+	shader, err := s.useShader(OverlayShader)
+	if err != nil {
+		return err
+	}
+	pid := shader.Program().ID()
+
+	coloru := gl.GetUniformLocation(pid, gl.Str("color\x00"))
+	gl.Uniform4fv(coloru, 1, &color[0])
+
+	brightnessu := gl.GetUniformLocation(pid, gl.Str("brightness\x00"))
+	gl.Uniform1f(brightnessu, 1-s.renderCommands.Fade)
+
+	gl.Disable(gl.DEPTH_TEST)
+	defer gl.Enable(gl.DEPTH_TEST)
+
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	s.screenQuad().Render(shader)
+
+	return nil
+}
+
+// renderHUD draws the textures of the HUD over the frame.
+func (s *RenderSystem) renderHUD() error {
+	if len(s.renderCommands.HUD) == 0 {
+		return nil
+	}
+
+	shader, err := s.useShader(HUDShader)
+	if err != nil {
+		return err
+	}
+	pid := shader.Program().ID()
+	rectu := gl.GetUniformLocation(pid, gl.Str("rect\x00"))
+
+	gl.Disable(gl.DEPTH_TEST)
+	defer gl.Enable(gl.DEPTH_TEST)
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+
+	for _, cmd := range s.renderCommands.HUD {
+		rect := mgl32.Vec4{cmd.Position.X(), cmd.Position.Y(), cmd.Size.X(), cmd.Size.Y()}
+		gl.Uniform4fv(rectu, 1, &rect[0])
+		cmd.Texture.Bind(0)
+		s.screenQuad().Render(shader)
+		cmd.Texture.Unbind(0)
+	}
+
+	return nil
+}
+
+func (s *RenderSystem) renderSpriteBoxes() error {
+	shader, err := s.useShader(BoxShader)
+	if err != nil {
+		return err
+	}
+	pid := shader.Program().ID()
+
+	// Magenta, as the planes without texture.
+	s.boxesBuf = ensurePlanesLength(s.boxesBuf, len(s.renderCommands.Sprites), nil)
+
+	for i, cmd := range s.renderCommands.Sprites {
+		cmd.Size = flippedSize(cmd)
 
-		box := geometry.NewPlane(0, 0, nil)
-		box.SetColors([]float32{1.0, 0.0, 1.0, 1.0, 0.0, 1.0, 1.0, 0.0, 1.0, 1.0, 0.0, 1.0})
+		box := s.boxesBuf[i]
 		box.SetBounds(cmd.Size.X(), cmd.Size.Y())
 		box.SetPosition(mgl32.Vec3{cmd.Position.X(), cmd.Position.Y(), cmd.Position.Z()})
 
@@ -94,28 +280,51 @@ func (s *RenderSystem) renderSpriteBoxes() {
 		rotationu := gl.GetUniformLocation(pid, gl.Str("rotation\x00"))
 		gl.UniformMatrix4fv(rotationu, 1, false, &rotation[0])
 
+		brightnessu := gl.GetUniformLocation(pid, gl.Str("brightness\x00"))
+		gl.Uniform1f(brightnessu, 1-s.renderCommands.Fade)
+
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
 		box.Render(shader)
 	}
+
+	return nil
 }
 
-func (s *RenderSystem) renderSprites() {
-	shader := opengl.NewShader(spriteVertexShader, spriteFragmentShader)
+func (s *RenderSystem) renderSprites() error {
+	shader, err := s.useShader(SpriteShader)
+	if err != nil {
+		return err
+	}
 	pid := shader.Program().ID()
-	gl.UseProgram(pid)
 
 	s.EnsureSpritesBufLen(len(s.renderCommands.Sprites))
+	s.frame++
+
+	// The texture is only bound when it changes, e.g. once for all the
+	// layers of an attachment drawn from its atlas.
+	var bound *graphic.Texture
+	defer func() {
+		if bound != nil {
+			bound.Unbind(0)
+		}
+	}()
 
 	for i, cmd := range s.renderCommands.Sprites {
-		if cmd.FlipVertically {
-			cmd.Size = mgl32.Vec2{-cmd.Size.X(), cmd.Size.Y()}
-		}
+		cmd.Size = flippedSize(cmd)
 
-		sprite := s.spritesBuf[i]
-		sprite.SetBounds(cmd.Size.X(), cmd.Size.Y())
-		sprite.SetTexture(cmd.Texture)
+		sprite, known := s.spritesBuf[i], false
+		if cmd.Key != (SpriteKey{}) {
+			sprite, known = s.keyedPlane(cmd.Key)
+		}
+		if !known || cmd.Dirty {
+			sprite.SetBounds(cmd.Size.X(), cmd.Size.Y())
+		}
+		sprite.Texture = cmd.Texture
 		sprite.SetPosition(mgl32.Vec3{cmd.Position.X(), cmd.Position.Y(), cmd.Position.Z()})
-		sprite.Texture.Bind(0)
+		if cmd.Texture != bound {
+			cmd.Texture.Bind(0)
+			bound = cmd.Texture
+		}
 
 		view := s.cam.ViewMatrix()
 		viewu := gl.GetUniformLocation(pid, gl.Str("view\x00"))
@@ -135,26 +344,100 @@ func (s *RenderSystem) renderSprites() {
 		offsetu := gl.GetUniformLocation(pid, gl.Str("offset\x00"))
 		gl.Uniform2fv(offsetu, 1, &cmd.Offset[0])
 
+		uv := cmd.UV
+		if uv == (mgl32.Vec4{}) {
+			uv = mgl32.Vec4{0, 0, 1, 1}
+		}
+		uvu := gl.GetUniformLocation(pid, gl.Str("uvRect\x00"))
+		gl.Uniform4fv(uvu, 1, &uv[0])
+
 		iden := mgl32.Ident4()
 		rotation := iden.Mul4(mgl32.HomogRotate3D(cmd.RotationRadians, graphic.Backwards))
 		rotationu := gl.GetUniformLocation(pid, gl.Str("rotation\x00"))
 		gl.UniformMatrix4fv(rotationu, 1, false, &rotation[0])
 
+		brightnessu := gl.GetUniformLocation(pid, gl.Str("brightness\x00"))
+		gl.Uniform1f(brightnessu, 1-s.renderCommands.Fade)
+
+		tintu := gl.GetUniformLocation(pid, gl.Str("tint\x00"))
+		gl.Uniform4fv(tintu, 1, &cmd.Tint[0])
+
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
 		sprite.Render(shader)
-		sprite.Texture.Unbind(0)
+	}
+
+	s.freeKeyedPlanes()
+
+	return nil
+}
+
+// keyedPlane returns the plane of the sprite of key, and whether it was
+// drawn during the last frame.
+func (s *RenderSystem) keyedPlane(key SpriteKey) (plane *geometry.Plane, known bool) {
+	if k, ok := s.keyed[key]; ok {
+		k.lastDrawn = s.frame
+		return k.plane, true
+	}
+
+	if n := len(s.free); n > 0 {
+		plane, s.free = s.free[n-1], s.free[:n-1]
+	} else {
+		plane = geometry.NewPlane(0, 0, new(graphic.Texture))
+	}
+
+	s.keyed[key] = &keyedPlane{plane: plane, lastDrawn: s.frame}
+	return plane, false
+}
+
+// freeKeyedPlanes frees the planes of the keys not drawn during the
+// frame, to be reused by other keys.
+func (s *RenderSystem) freeKeyedPlanes() {
+	for key, k := range s.keyed {
+		if k.lastDrawn != s.frame {
+			delete(s.keyed, key)
+			s.free = append(s.free, k.plane)
+		}
 	}
 }
 
-func (s *RenderSystem) Remove(e ecs.BasicEntity) {
-	panic("implement me")
+func (s *RenderSystem) Remove(e ecs.BasicEntity) {}
+
+// Close deletes the programs and the render targets of the system. It
+// must be called from the render thread.
+func (s *RenderSystem) Close() {
+	for i, shader := range s.shaders {
+		if shader != nil {
+			gl.DeleteProgram(shader.Program().ID())
+			s.shaders[i] = nil
+		}
+	}
+	for _, p := range s.posts {
+		p.delete()
+	}
+	s.deleteTargets()
+}
+
+// flippedSize returns the size of the quad of cmd, negative on the axes
+// the sprite is flipped on.
+func flippedSize(cmd SpriteRenderCommand) mgl32.Vec2 {
+	size := cmd.Size
+	if cmd.FlipHorizontally {
+		size[0] = -size[0]
+	}
+	if cmd.FlipVertically {
+		size[1] = -size[1]
+	}
+
+	return size
 }
 
 func (s *RenderSystem) EnsureSpritesBufLen(minLen int) {
-	s.spritesBuf = ensureSpritesBufferLength(s.spritesBuf, minLen)
+	s.spritesBuf = ensurePlanesLength(s.spritesBuf, minLen, new(graphic.Texture))
 }
 
-func ensureSpritesBufferLength(slice []*geometry.Plane, minLen int) []*geometry.Plane {
+// ensurePlanesLength returns slice grown to minLen planes, the new ones
+// created with texture.
+func ensurePlanesLength(slice []*geometry.Plane, minLen int, texture *graphic.Texture) []*geometry.Plane {
 	oldLen := len(slice)
 
 	if cacheOverflow := minLen - oldLen; cacheOverflow <= 0 {
@@ -171,7 +454,7 @@ func ensureSpritesBufferLength(slice []*geometry.Plane, minLen int) []*geometry.
 	slice = slice[0:minLen]
 
 	for i := oldLen; i < minLen; i++ {
-		slice[i] = geometry.NewPlane(0, 0, new(graphic.Texture))
+		slice[i] = geometry.NewPlane(0, 0, texture)
 	}
 	return slice
 }
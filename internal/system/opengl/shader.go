@@ -0,0 +1,93 @@
+package opengl
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/gl"
+	"github.com/project-midgard/midgarts/internal/opengl"
+)
+
+// Shader is one of the programs the render system draws with.
+type Shader int
+
+const (
+	// SpriteShader draws the sprites (shaders/sprite.vert and sprite.frag).
+	SpriteShader Shader = iota
+	// BoxShader draws the boxes of the sprites (shaders/box.vert and
+	// box.frag).
+	BoxShader
+	// ModelShader draws the models of the map (shaders/model.vert and
+	// model.frag).
+	ModelShader
+	// OverlayShader tints the screen (shaders/overlay.vert and
+	// overlay.frag).
+	OverlayShader
+	// HUDShader draws the HUD (shaders/hud.vert and hud.frag).
+	HUDShader
+
+	shaderCount
+)
+
+var shaderNames = [shaderCount]string{"sprite", "box", "model", "overlay", "hud"}
+
+func (s Shader) String() string {
+	if s < 0 || s >= shaderCount {
+		return "unknown"
+	}
+
+	return shaderNames[s]
+}
+
+// ShaderSource is the GLSL 330 source of a program. A replacing source
+// gets the vertex attributes and the uniforms of the built-in program it
+// replaces; an empty stage keeps the built-in one.
+type ShaderSource struct {
+	Vertex, Fragment string
+}
+
+var builtinShaders = [shaderCount]ShaderSource{
+	SpriteShader:  {spriteVertexShader, spriteFragmentShader},
+	BoxShader:     {boxVertexShader, boxFragmentShader},
+	ModelShader:   {modelVertexShader, modelFragmentShader},
+	OverlayShader: {overlayVertexShader, overlayFragmentShader},
+	HUDShader:     {hudVertexShader, hudFragmentShader},
+}
+
+// SetShader replaces the source of a program of the system, compiled on
+// the next frame. It must be called from the render thread.
+func (s *RenderSystem) SetShader(shader Shader, source ShaderSource) error {
+	if shader < 0 || shader >= shaderCount {
+		return errors.Errorf("unknown shader %d", shader)
+	}
+
+	s.sources[shader] = source
+	if state := s.shaders[shader]; state != nil {
+		gl.DeleteProgram(state.Program().ID())
+		s.shaders[shader] = nil
+	}
+
+	return nil
+}
+
+// useShader returns the program of shader, in use, compiled on its first
+// use.
+func (s *RenderSystem) useShader(shader Shader) (*opengl.State, error) {
+	if s.shaders[shader] == nil {
+		source := builtinShaders[shader]
+		if custom := s.sources[shader]; custom.Vertex != "" {
+			source.Vertex = custom.Vertex
+		}
+		if custom := s.sources[shader]; custom.Fragment != "" {
+			source.Fragment = custom.Fragment
+		}
+
+		state, err := opengl.NewShader(source.Vertex, source.Fragment)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compile %s shader", shader)
+		}
+		s.shaders[shader] = state
+	}
+
+	gl.UseProgram(s.shaders[shader].Program().ID())
+	return s.shaders[shader], nil
+}
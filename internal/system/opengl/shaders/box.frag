@@ -6,9 +6,10 @@ in vec2 texCoords;
 out vec4 FragColor;
 
 uniform sampler2D tex;
+uniform float brightness;
 
 void main() {
     vec2 var_TexCoords = texCoords;
 
-    FragColor = vec4(fragColor, 1.0);
+    FragColor = vec4(fragColor * brightness, 1.0);
 }
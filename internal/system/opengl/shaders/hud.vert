@@ -0,0 +1,19 @@
+#version 330 core
+
+layout(location = 0) in vec3 VertexPosition;
+layout(location = 1) in vec3 VertexColor;
+layout(location = 2) in vec2 VertexTexCoord;
+
+out vec2 uv;
+
+// The left, top, width and height of the texture, in fractions of the
+// screen from its top left corner.
+uniform vec4 rect;
+
+void main() {
+    vec2 corner = VertexPosition.xy * 0.5 + 0.5;
+    uv = vec2(corner.x, 1.0 - corner.y);
+
+    vec2 screen = rect.xy + uv * rect.zw;
+    gl_Position = vec4(screen.x * 2.0 - 1.0, 1.0 - screen.y * 2.0, 0.0, 1.0);
+}
@@ -0,0 +1,17 @@
+#version 330 core
+
+in vec2 texCoords;
+
+out vec4 FragColor;
+
+uniform sampler2D tex;
+uniform float brightness;
+
+void main() {
+    vec4 texColor = texture(tex, texCoords);
+
+    if(texColor.a < 0.5)
+        discard;
+
+    FragColor = vec4(texColor.rgb * brightness, texColor.a);
+}
@@ -0,0 +1,15 @@
+#version 330 core
+
+layout(location = 0) in vec3 VertexPosition;
+layout(location = 2) in vec2 VertexTexCoord;
+
+uniform mat4 view;
+uniform mat4 projection;
+
+out vec2 texCoords;
+
+void main() {
+    // The vertices of the batches are in world space.
+    gl_Position = projection * view * vec4(VertexPosition, 1.0);
+    texCoords = VertexTexCoord;
+}
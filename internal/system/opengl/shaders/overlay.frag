@@ -0,0 +1,10 @@
+#version 330 core
+
+out vec4 FragColor;
+
+uniform vec4 color;
+uniform float brightness;
+
+void main() {
+    FragColor = vec4(color.rgb * brightness, color.a);
+}
@@ -0,0 +1,10 @@
+#version 330 core
+
+layout(location = 0) in vec3 VertexPosition;
+layout(location = 1) in vec3 VertexColor;
+layout(location = 2) in vec2 VertexTexCoord;
+
+// The quad covers the screen, in clip space.
+void main() {
+    gl_Position = vec4(VertexPosition.x, VertexPosition.y, 0.0, 1.0);
+}
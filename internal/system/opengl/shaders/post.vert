@@ -0,0 +1,13 @@
+#version 330 core
+
+layout(location = 0) in vec3 VertexPosition;
+layout(location = 1) in vec3 VertexColor;
+layout(location = 2) in vec2 VertexTexCoord;
+
+out vec2 uv;
+
+// The quad covers the screen, in clip space, and the frame in uv.
+void main() {
+    uv = VertexPosition.xy * 0.5 + 0.5;
+    gl_Position = vec4(VertexPosition.x, VertexPosition.y, 0.0, 1.0);
+}
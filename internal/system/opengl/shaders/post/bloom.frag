@@ -0,0 +1,33 @@
+#version 330 core
+
+in vec2 uv;
+
+out vec4 FragColor;
+
+uniform sampler2D frame;
+uniform vec2 resolution;
+// threshold is the brightness from which the pixels glow, and intensity
+// how much their glow is added.
+uniform float threshold;
+uniform float intensity;
+
+// Adds the blurred bright parts of the frame around them, in a single
+// pass over a ring of samples.
+void main() {
+    vec3 color = texture(frame, uv).rgb;
+
+    vec3 glow = vec3(0.0);
+    float weights = 0.0;
+    for (int ring = 1; ring <= 3; ring++) {
+        for (int i = 0; i < 8; i++) {
+            float angle = float(i) * 0.785398 + float(ring) * 0.3927;
+            vec2 offset = vec2(cos(angle), sin(angle)) * float(ring) * 3.0 / resolution;
+            vec3 around = texture(frame, uv + offset).rgb;
+            float weight = 1.0 / float(ring);
+            glow += max(around - vec3(threshold), vec3(0.0)) * weight;
+            weights += weight;
+        }
+    }
+
+    FragColor = vec4(color + glow / weights * intensity, 1.0);
+}
@@ -0,0 +1,26 @@
+#version 330 core
+
+in vec2 uv;
+
+out vec4 FragColor;
+
+uniform sampler2D frame;
+uniform vec2 resolution;
+
+// Curves the frame as the glass of a tube, darkens every other line and
+// the corners.
+void main() {
+    vec2 centered = uv * 2.0 - 1.0;
+    centered *= 1.0 + 0.04 * dot(centered.yx, centered.yx);
+    vec2 curved = centered * 0.5 + 0.5;
+    if (curved.x < 0.0 || curved.x > 1.0 || curved.y < 0.0 || curved.y > 1.0) {
+        FragColor = vec4(0.0, 0.0, 0.0, 1.0);
+        return;
+    }
+
+    vec3 color = texture(frame, curved).rgb;
+    color *= 0.85 + 0.15 * sin(curved.y * resolution.y * 3.14159);
+    color *= 1.0 - 0.25 * dot(centered, centered);
+
+    FragColor = vec4(color, 1.0);
+}
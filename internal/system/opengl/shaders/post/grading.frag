@@ -0,0 +1,23 @@
+#version 330 core
+
+in vec2 uv;
+
+out vec4 FragColor;
+
+uniform sampler2D frame;
+// 1 keeps the frame unchanged.
+uniform float saturation;
+uniform float contrast;
+// Multiplies the colors, e.g. to warm or cool the frame.
+uniform vec3 balance;
+
+void main() {
+    vec3 color = texture(frame, uv).rgb;
+
+    float luma = dot(color, vec3(0.299, 0.587, 0.114));
+    color = mix(vec3(luma), color, saturation);
+    color = (color - 0.5) * contrast + 0.5;
+    color *= balance;
+
+    FragColor = vec4(clamp(color, 0.0, 1.0), 1.0);
+}
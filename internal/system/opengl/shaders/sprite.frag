@@ -6,6 +6,9 @@ in vec2 texCoords;
 out vec4 FragColor;
 
 uniform sampler2D tex;
+uniform float brightness;
+// Tints the sprite towards its color, by its alpha.
+uniform vec4 tint;
 
 void main() {
     vec2 var_TexCoords = texCoords;
@@ -14,5 +17,7 @@ void main() {
     if(texColor.a < 0.1)
         discard;
 
-    FragColor = texColor * vec4(fragColor, 1.0);
+    texColor.rgb = mix(texColor.rgb, texColor.rgb * tint.rgb, tint.a);
+
+    FragColor = texColor * vec4(fragColor * brightness, 1.0);
 }
@@ -10,6 +10,8 @@ uniform mat4 projection;
 uniform mat4 rotation;
 uniform vec2 size;
 uniform vec2 offset;
+// The part of the texture drawn: top left, bottom right.
+uniform vec4 uvRect;
 
 out vec3 fragColor;
 out vec2 texCoords;
@@ -29,5 +31,5 @@ void main() {
     gl_Position = projection * modelView * pos;
 
     fragColor = VertexColor;
-    texCoords = VertexTexCoord;
+    texCoords = mix(uvRect.xy, uvRect.zw, VertexTexCoord);
 }
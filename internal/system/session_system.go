@@ -0,0 +1,111 @@
+package system
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/network/capture"
+	"github.com/project-midgard/midgarts/internal/session"
+)
+
+// SessionSystem replays a captured session on the map (see package
+// session): it applies the packets of the capture to the world of the
+// session at the times they were received, and publishes them as
+// event.PacketReceived for the other systems to show them too. A
+// character follows each entity in sight.
+type SessionSystem struct {
+	World *session.World
+	// Show returns the character of an entity coming into sight, added to
+	// the scene, nil to not show it. Hide removes it from the scene when
+	// the entity vanishes.
+	Show func(e *session.Entity) *entity.Character
+	Hide func(char *entity.Character)
+
+	bus    *event.Bus
+	ground *Ground
+	player *session.Player
+	chars  map[uint32]*entity.Character
+}
+
+// NewSessionSystem returns the system replaying records on ground,
+// publishing them on bus.
+func NewSessionSystem(bus *event.Bus, ground *Ground, records []capture.Record) *SessionSystem {
+	s := &SessionSystem{
+		World:  session.NewWorld(ground.GAT),
+		bus:    bus,
+		ground: ground,
+		player: session.NewPlayer(records),
+		chars:  map[uint32]*entity.Character{},
+	}
+
+	s.World.Spawned = func(e *session.Entity) {
+		if s.Show == nil {
+			return
+		}
+		if char := s.Show(e); char != nil {
+			s.chars[e.GID] = char
+			s.follow(char, e)
+		}
+	}
+	s.World.Vanished = func(e *session.Entity) {
+		char, ok := s.chars[e.GID]
+		if !ok {
+			return
+		}
+		delete(s.chars, e.GID)
+		if s.Hide != nil {
+			s.Hide(char)
+		}
+	}
+
+	return s
+}
+
+// Character returns the character of the entity of gid, nil when it is not
+// shown.
+func (s *SessionSystem) Character(gid uint32) *entity.Character {
+	return s.chars[gid]
+}
+
+// Done tells whether the whole session was replayed.
+func (s *SessionSystem) Done() bool {
+	return s.player.Done()
+}
+
+func (s *SessionSystem) Update(dt float32) {
+	for _, p := range s.player.Advance(dt) {
+		// The entities spawn before the other systems see their packets.
+		s.World.Apply(p)
+		s.bus.Publish(event.PacketReceived{Packet: p})
+	}
+
+	s.World.Update(dt)
+	for gid, char := range s.chars {
+		if e, ok := s.World.Entity(gid); ok {
+			s.follow(char, e)
+		}
+	}
+}
+
+// follow moves char to the actor of e.
+func (s *SessionSystem) follow(char *entity.Character, e *session.Entity) {
+	p := s.ground.World(e.Actor.Position.X(), e.Actor.Position.Y())
+	char.SetPosition(mgl32.Vec3{p.X(), p.Y(), char.Position().Z()})
+
+	char.Direction = e.Actor.Direction
+	if char.State != e.Actor.State {
+		char.SetState(e.Actor.State)
+	}
+}
+
+// Remove stops following the character of e.
+func (s *SessionSystem) Remove(e ecs.BasicEntity) {
+	for gid, char := range s.chars {
+		if char.ID() == e.ID() {
+			delete(s.chars, gid)
+			return
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statetype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/network/capture"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+	"github.com/project-midgard/midgarts/internal/session"
+)
+
+func TestSessionSystem(t *testing.T) {
+	gatFile := &gat.GroundAltitudeFile{Width: 20, Height: 20, Cells: make([]gat.Cell, 400)}
+	for i := range gatFile.Cells {
+		gatFile.Cells[i].CellType = gat.Walkable
+	}
+	ground := &Ground{GAT: gatFile, Center: mgl32.Vec3{4, 38, -1}}
+
+	bus := event.NewBus()
+	var published int
+	bus.Subscribe(func(event.PacketReceived) { published++ })
+
+	s := NewSessionSystem(bus, ground, []capture.Record{
+		{Time: 0, Packet: &packet.ZC_NOTIFY_STANDENTRY{ObjectType: session.ObjectPlayer, GID: 7, Speed: 150, PosDir: packet.EncodePosDir(5, 5, 4)}},
+		{Time: 100 * time.Millisecond, Packet: &packet.ZC_NOTIFY_MOVE{GID: 7, MoveData: packet.EncodeMoveData(5, 5, 5, 8)}},
+		{Time: time.Second, Packet: &packet.ZC_NOTIFY_VANISH{GID: 7}},
+	})
+	var shown, hidden *entity.Character
+	s.Show = func(e *session.Entity) *entity.Character {
+		shown = entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+		return shown
+	}
+	s.Hide = func(char *entity.Character) { hidden = char }
+
+	s.Update(0.05)
+	assert.Same(t, shown, s.Character(7))
+	assert.Equal(t, ground.World(5.5, 5.5), mgl32.Vec2{shown.Position().X(), shown.Position().Y()})
+
+	s.Update(0.1)
+	assert.Equal(t, statetype.Walking, shown.State)
+	assert.Greater(t, shown.Position().Y(), ground.World(5.5, 5.5).Y(), "walks north")
+
+	s.Update(1)
+	assert.Same(t, shown, hidden)
+	assert.Nil(t, s.Character(7))
+	assert.True(t, s.Done())
+	assert.Equal(t, 3, published)
+}
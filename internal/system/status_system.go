@@ -0,0 +1,325 @@
+package system
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/project-midgard/midgarts/internal/character/statustype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/theme"
+)
+
+const (
+	// statusIconSize is the size of the status icons, in pixels, and
+	// statusIconSpacing the space between them.
+	statusIconSize    = 15
+	statusIconSpacing = 2
+	// statusHeight is the height above the feet of the characters of the
+	// bottom of the row of their status icons, in sprite pixels, under
+	// their captions.
+	statusHeight = captionHeight - 14
+	// bubbleHeight is the height of the bubbles above the row of icons,
+	// in sprite pixels, and bubbleBob how far they bob up and down.
+	bubbleHeight = statusHeight + statusIconSize + 8
+	bubbleBob    = 3
+)
+
+// StatusLook is how a status effect shows on a character.
+type StatusLook struct {
+	// Icon is the color of the icon, showing Letter.
+	Icon   color.RGBA
+	Letter string
+	// Tint tints the sprites of the character (see
+	// opengl.SpriteRenderCommand.Tint), the zero tint none.
+	Tint mgl32.Vec4
+	// Freeze stops the animation and the moves of the character.
+	Freeze bool
+	// Bubble, when set, is a text bobbing above the icons, e.g. the zzz
+	// of the sleeping characters.
+	Bubble string
+}
+
+// StatusLooks are the looks of the status effects. The tint of a
+// character is the one of its first status effect with one, in the order
+// of statustype.All.
+var StatusLooks = map[statustype.Type]StatusLook{
+	statustype.Poison:  {Icon: color.RGBA{R: 120, G: 40, B: 160, A: 255}, Letter: "P", Tint: mgl32.Vec4{0.7, 1, 0.5, 0.6}},
+	statustype.Curse:   {Icon: color.RGBA{R: 90, G: 20, B: 20, A: 255}, Letter: "C", Tint: mgl32.Vec4{0.6, 0.4, 0.4, 0.5}},
+	statustype.Silence: {Icon: color.RGBA{R: 60, G: 90, B: 160, A: 255}, Letter: "S"},
+	statustype.Blind:   {Icon: color.RGBA{R: 30, G: 30, B: 30, A: 255}, Letter: "B"},
+	statustype.Stun:    {Icon: color.RGBA{R: 200, G: 160, B: 20, A: 255}, Letter: "!", Freeze: true, Bubble: "* *"},
+	statustype.Sleep:   {Icon: color.RGBA{R: 40, G: 60, B: 120, A: 255}, Letter: "Z", Freeze: true, Bubble: "zZz"},
+	statustype.Frozen:  {Icon: color.RGBA{R: 80, G: 170, B: 230, A: 255}, Letter: "F", Tint: mgl32.Vec4{0.6, 0.85, 1.4, 0.9}, Freeze: true},
+	statustype.Stone:   {Icon: color.RGBA{R: 120, G: 120, B: 120, A: 255}, Letter: "T", Tint: mgl32.Vec4{0.7, 0.7, 0.7, 1}, Freeze: true},
+}
+
+// StatusTint returns the tint of the sprites of char, from its status
+// effects (see StatusLooks).
+func StatusTint(char *entity.Character) mgl32.Vec4 {
+	if len(char.Statuses) == 0 {
+		return mgl32.Vec4{}
+	}
+
+	for _, t := range statustype.All() {
+		if look := StatusLooks[t]; look.Tint != (mgl32.Vec4{}) && char.HasStatus(t) {
+			return look.Tint
+		}
+	}
+
+	return mgl32.Vec4{}
+}
+
+// StatusSystem shows the status effects of the characters (see
+// component.StatusComponent): their icons in a row above the head, the
+// bubbles of the sleeping and stunned characters, and it stops the
+// characters frozen, asleep, stunned or turned to stone. The character
+// render system tints their sprites (see StatusTint). It counts down the
+// time left of the status effects, removing the expired ones.
+type StatusSystem struct {
+	// Theme gives the colors and the scale of the bubbles.
+	Theme theme.Theme
+
+	renderCommands  *opengl.RenderCommands
+	textureProvider graphic.TextureProvider
+
+	chars []*entity.Character
+	// frozen holds the time scales of the frozen characters, restored
+	// when they thaw.
+	frozen  map[uint64]float64
+	icons   map[statustype.Type]*graphic.UniqueRGBA
+	bubbles map[string]*graphic.UniqueRGBA
+	elapsed float32
+}
+
+// NewStatusSystem returns the system, drawing with commands.
+func NewStatusSystem(textureProvider graphic.TextureProvider, commands *opengl.RenderCommands) *StatusSystem {
+	return &StatusSystem{
+		Theme:           theme.Default,
+		renderCommands:  commands,
+		textureProvider: textureProvider,
+		frozen:          map[uint64]float64{},
+		icons:           map[statustype.Type]*graphic.UniqueRGBA{},
+		bubbles:         map[string]*graphic.UniqueRGBA{},
+	}
+}
+
+func (s *StatusSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o.(*entity.Character))
+}
+
+// Add shows the status effects of char.
+func (s *StatusSystem) Add(char *entity.Character) {
+	s.chars = append(s.chars, char)
+}
+
+func (s *StatusSystem) Remove(e ecs.BasicEntity) {
+	for i, char := range s.chars {
+		if char.ID() == e.ID() {
+			s.thaw(char)
+			s.chars = append(s.chars[:i], s.chars[i+1:]...)
+			return
+		}
+	}
+}
+
+// Subscribe applies the event.StatusChanged of bus.
+func (s *StatusSystem) Subscribe(bus *event.Bus) (unsubscribe func()) {
+	return bus.Subscribe(func(e event.StatusChanged) {
+		if e.Active {
+			e.Character.AddStatus(e.Status, e.Duration)
+		} else {
+			e.Character.RemoveStatus(e.Status)
+		}
+	})
+}
+
+func (s *StatusSystem) Update(dt float32) {
+	s.elapsed += dt
+
+	for _, char := range s.chars {
+		// Frozen in time, the time of the status effects goes on.
+		char.ExpireStatuses(time.Duration(float64(dt) * float64(time.Second)))
+
+		var (
+			freeze bool
+			bubble string
+			icons  []*graphic.UniqueRGBA
+		)
+		for _, status := range char.Statuses {
+			look := StatusLooks[status.Type]
+			freeze = freeze || look.Freeze
+			if bubble == "" {
+				bubble = look.Bubble
+			}
+			icons = append(icons, s.icon(status.Type, look))
+		}
+
+		if freeze {
+			s.freeze(char)
+		} else {
+			s.thaw(char)
+		}
+
+		s.drawIcons(char, icons)
+		if bubble != "" {
+			s.drawBubble(char, bubble)
+		}
+	}
+}
+
+// freeze stops char, keeping its time scale.
+func (s *StatusSystem) freeze(char *entity.Character) {
+	if _, ok := s.frozen[char.ID()]; ok {
+		return
+	}
+
+	s.frozen[char.ID()] = char.TimeScale
+	char.TimeScale = 0
+}
+
+// thaw gives char its time scale back, if frozen.
+func (s *StatusSystem) thaw(char *entity.Character) {
+	scale, ok := s.frozen[char.ID()]
+	if !ok {
+		return
+	}
+
+	char.TimeScale = scale
+	delete(s.frozen, char.ID())
+}
+
+// drawIcons draws icons in a row centered above the head of char.
+func (s *StatusSystem) drawIcons(char *entity.Character, icons []*graphic.UniqueRGBA) {
+	size := float32(statusIconSize * geometry.OnePixelSize)
+	spacing := float32(statusIconSpacing * geometry.OnePixelSize)
+	left := -(float32(len(icons))*(size+spacing) - spacing) / 2
+
+	for i, img := range icons {
+		texture, err := s.textureProvider.NewTextureFromRGBA(img, graphic.TextureSprite)
+		if err != nil {
+			continue
+		}
+
+		s.renderCommands.Sprites = append(s.renderCommands.Sprites, opengl.SpriteRenderCommand{
+			Scale:    [2]float32{1, 1},
+			Size:     mgl32.Vec2{size, size},
+			Position: char.Position(),
+			// The offsets grow down.
+			Offset:  mgl32.Vec2{left + float32(i)*(size+spacing) + size/2, -statusHeight*geometry.OnePixelSize*char.SpriteScale() - size/2},
+			Texture: texture,
+		})
+	}
+}
+
+// drawBubble draws text bobbing above the icons of char.
+func (s *StatusSystem) drawBubble(char *entity.Character, text string) {
+	img, ok := s.bubbles[text]
+	if !ok {
+		img = NewBubbleImage(text, s.Theme)
+		s.bubbles[text] = img
+	}
+
+	texture, err := s.textureProvider.NewTextureFromRGBA(img, graphic.TextureSprite)
+	if err != nil {
+		return
+	}
+
+	bounds := img.Bounds()
+	width := float32(bounds.Dx()) * geometry.OnePixelSize * s.Theme.TextScale
+	height := float32(bounds.Dy()) * geometry.OnePixelSize * s.Theme.TextScale
+	bob := bubbleBob * float32(math.Sin(float64(s.elapsed)*3))
+
+	s.renderCommands.Sprites = append(s.renderCommands.Sprites, opengl.SpriteRenderCommand{
+		Scale:    [2]float32{1, 1},
+		Size:     mgl32.Vec2{width, height},
+		Position: char.Position(),
+		Offset:   mgl32.Vec2{0, -(bubbleHeight*char.SpriteScale()+bob)*geometry.OnePixelSize - height/2},
+		Texture:  texture,
+	})
+}
+
+// icon returns the image of the icon of t, made once.
+func (s *StatusSystem) icon(t statustype.Type, look StatusLook) *graphic.UniqueRGBA {
+	img, ok := s.icons[t]
+	if !ok {
+		img = NewStatusIcon(look)
+		s.icons[t] = img
+	}
+
+	return img
+}
+
+// Close deletes the textures of the icons and the bubbles, and thaws the
+// frozen characters.
+func (s *StatusSystem) Close() {
+	for _, img := range s.icons {
+		s.textureProvider.DeleteTexture(img)
+	}
+	for _, img := range s.bubbles {
+		s.textureProvider.DeleteTexture(img)
+	}
+	s.icons, s.bubbles = map[statustype.Type]*graphic.UniqueRGBA{}, map[string]*graphic.UniqueRGBA{}
+
+	for _, char := range s.chars {
+		s.thaw(char)
+	}
+}
+
+// NewStatusIcon returns the icon of a status effect: its letter in white
+// on a square of its color, with a dark border.
+func NewStatusIcon(look StatusLook) *graphic.UniqueRGBA {
+	img := graphic.NewUniqueRGBA(image.Rect(0, 0, statusIconSize, statusIconSize))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{A: 255}), image.Point{}, draw.Src)
+	draw.Draw(img, img.Bounds().Inset(1), image.NewUniform(look.Icon), image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, look.Letter).Ceil()
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(theme.Default.Text),
+		Face: face,
+		Dot:  fixed.P((statusIconSize-width)/2, (statusIconSize+face.Metrics().Ascent.Ceil())/2),
+	}
+	d.DrawString(look.Letter)
+
+	return img
+}
+
+// NewBubbleImage returns the image of a bubble: text in the text color of
+// t, outlined in black, on a transparent background.
+func NewBubbleImage(text string, t theme.Theme) *graphic.UniqueRGBA {
+	face := basicfont.Face7x13
+	metrics := face.Metrics()
+	img := graphic.NewUniqueRGBA(image.Rect(0, 0, font.MeasureString(face, text).Ceil()+2, metrics.Height.Ceil()+2))
+
+	for _, o := range []struct {
+		dx, dy int
+		color  color.RGBA
+	}{
+		{0, 1, color.RGBA{A: 255}}, {2, 1, color.RGBA{A: 255}}, {1, 0, color.RGBA{A: 255}}, {1, 2, color.RGBA{A: 255}},
+		{1, 1, t.Text},
+	} {
+		d := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(o.color),
+			Face: face,
+			Dot:  fixed.P(o.dx, o.dy+metrics.Ascent.Ceil()),
+		}
+		d.DrawString(text)
+	}
+
+	return img
+}
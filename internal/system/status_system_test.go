@@ -0,0 +1,59 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/character/statustype"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+func TestStatusSystem(t *testing.T) {
+	commands := &opengl.RenderCommands{}
+	s := NewStatusSystem(&staticTextures{}, commands)
+	bus := event.NewBus()
+	defer s.Subscribe(bus)()
+
+	char := entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	char.TimeScale = 0.5
+	s.Add(char)
+
+	bus.Publish(event.StatusChanged{Character: char, Status: statustype.Poison, Active: true, Duration: time.Second})
+	bus.Publish(event.StatusChanged{Character: char, Status: statustype.Sleep, Active: true})
+	assert.Equal(t, StatusLooks[statustype.Poison].Tint, StatusTint(char))
+
+	// An icon each, and the bubble of the sleep.
+	s.Update(0.5)
+	assert.Len(t, commands.Sprites, 3)
+	assert.Equal(t, float64(0), char.TimeScale)
+	left, right := commands.Sprites[0].Offset, commands.Sprites[1].Offset
+	assert.InDelta(t, 0, left.X()+right.X(), 1e-5, "the icons are centered")
+
+	// The poison wears off, the sleeper wakes up.
+	commands.Sprites = nil
+	s.Update(0.5)
+	assert.Len(t, commands.Sprites, 2)
+	assert.Equal(t, mgl32.Vec4{}, StatusTint(char))
+
+	bus.Publish(event.StatusChanged{Character: char, Status: statustype.Sleep})
+	commands.Sprites = nil
+	s.Update(0.1)
+	assert.Empty(t, commands.Sprites)
+	assert.Equal(t, 0.5, char.TimeScale)
+}
+
+func TestNewStatusIcon(t *testing.T) {
+	look := StatusLooks[statustype.Frozen]
+	img := NewStatusIcon(look)
+
+	assert.Equal(t, statusIconSize, img.Bounds().Dx())
+	assert.Equal(t, uint8(0), img.RGBAAt(0, 0).R, "the border is dark")
+	assert.Equal(t, look.Icon, img.RGBAAt(1, 1))
+}
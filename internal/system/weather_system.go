@@ -0,0 +1,173 @@
+package system
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math/rand"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/rs/zerolog"
+
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/graphic/geometry"
+	"github.com/project-midgard/midgarts/internal/logging"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/weather"
+)
+
+// weatherScale is the size of the particles of each kind of weather, in
+// sprite pixels per pixel of their image.
+var weatherScale = map[weather.Kind]float32{
+	weather.Rain:   1,
+	weather.Snow:   1,
+	weather.Clouds: 4,
+}
+
+// WeatherSystem draws the weather of the map around the focus: the
+// particles as sprites, and the tint of the screen. The sound loop of the
+// weather is logged when it changes, not played yet.
+type WeatherSystem struct {
+	// Focus is the character the particles fall around.
+	Focus *entity.Character
+
+	log             zerolog.Logger
+	renderCommands  *opengl.RenderCommands
+	textureProvider graphic.TextureProvider
+	field           *weather.Field
+	images          map[weather.Kind]*graphic.UniqueRGBA
+}
+
+// NewWeatherSystem returns the system, starting with w, logging to the
+// logger of ctx (see logging.For).
+func NewWeatherSystem(
+	ctx context.Context,
+	w weather.Weather,
+	textureProvider graphic.TextureProvider,
+	commands *opengl.RenderCommands,
+) *WeatherSystem {
+	s := &WeatherSystem{
+		log:             logging.For(ctx, logging.Weather),
+		renderCommands:  commands,
+		textureProvider: textureProvider,
+		// The same particles every run.
+		field:  weather.NewField(weather.Weather{Kind: weather.None}, rand.New(rand.NewSource(1))),
+		images: map[weather.Kind]*graphic.UniqueRGBA{},
+	}
+	s.Set(w)
+
+	return s
+}
+
+// Weather returns the current weather.
+func (s *WeatherSystem) Weather() weather.Weather {
+	return s.field.Weather
+}
+
+// Set changes the weather.
+func (s *WeatherSystem) Set(w weather.Weather) {
+	s.field.Set(w)
+
+	l := s.log.Info().Str("weather", string(w.Kind))
+	if sound, volume, ok := s.field.Sound(); ok {
+		l = l.Str("sound", sound).Float32("volume", volume)
+	}
+	l.Msg("weather changed")
+}
+
+// Subscribe changes the weather to the next kind on every
+// event.WeatherCycled of bus.
+func (s *WeatherSystem) Subscribe(bus *event.Bus) (unsubscribe func()) {
+	return bus.Subscribe(func(event.WeatherCycled) {
+		s.Set(weather.Weather{Kind: s.field.Weather.Kind.Next(), Intensity: s.field.Weather.Intensity})
+	})
+}
+
+func (s *WeatherSystem) Update(dt float32) {
+	s.renderCommands.Overlay = s.field.Weather.Overlay()
+	if s.Focus == nil {
+		return
+	}
+
+	focus := s.Focus.Position()
+	s.field.Update(dt, mgl32.Vec2{focus.X(), focus.Y()})
+
+	kind := s.field.Weather.Kind
+	particles := s.field.Particles()
+	if len(particles) == 0 {
+		return
+	}
+
+	img, ok := s.images[kind]
+	if !ok {
+		img = NewWeatherImage(kind)
+		s.images[kind] = img
+	}
+	texture, err := s.textureProvider.NewTextureFromRGBA(img, graphic.TextureSprite)
+	if err != nil {
+		s.log.Error().Err(err).Msg("could not create weather texture")
+		return
+	}
+
+	preset, _ := s.field.Weather.Preset()
+	bounds := img.Bounds()
+	size := mgl32.Vec2{float32(bounds.Dx()), float32(bounds.Dy())}.Mul(weatherScale[kind] * geometry.OnePixelSize)
+	for _, p := range particles {
+		s.renderCommands.Sprites = append(s.renderCommands.Sprites, opengl.SpriteRenderCommand{
+			Scale:    [2]float32{1, 1},
+			Size:     size,
+			Position: mgl32.Vec3{p.Position.X(), p.Position.Y(), focus.Z()},
+			// Above the ground, the offsets growing down.
+			Offset:  mgl32.Vec2{p.Offset(preset.Sway), -p.Height},
+			Texture: texture,
+		})
+	}
+}
+
+func (s *WeatherSystem) Remove(ecs.BasicEntity) {}
+
+// Close deletes the textures of the particles.
+func (s *WeatherSystem) Close() {
+	for _, img := range s.images {
+		s.textureProvider.DeleteTexture(img)
+	}
+	s.images = map[weather.Kind]*graphic.UniqueRGBA{}
+}
+
+// NewWeatherImage returns the image of a particle of kind: a streak of
+// rain, a flake of snow or a puff of cloud.
+func NewWeatherImage(kind weather.Kind) *graphic.UniqueRGBA {
+	switch kind {
+	case weather.Rain:
+		img := graphic.NewUniqueRGBA(image.Rect(0, 0, 1, 14))
+		for y := 0; y < 14; y++ {
+			// Fading towards the top.
+			img.SetRGBA(0, y, color.RGBA{R: 190, G: 200, B: 230, A: uint8(60 + 12*y)})
+		}
+		return img
+	case weather.Snow:
+		img := graphic.NewUniqueRGBA(image.Rect(0, 0, 3, 3))
+		for _, p := range []image.Point{{1, 0}, {0, 1}, {1, 1}, {2, 1}, {1, 2}} {
+			img.SetRGBA(p.X, p.Y, color.RGBA{R: 255, G: 255, B: 255, A: 230})
+		}
+		return img
+	default:
+		// Opaque at the center, fading to the edges of the ellipse.
+		const w, h = 48, 24
+		img := graphic.NewUniqueRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				dx, dy := (float32(x)+0.5)/w*2-1, (float32(y)+0.5)/h*2-1
+				d := dx*dx + dy*dy
+				if d >= 1 {
+					continue
+				}
+				img.SetRGBA(x, y, color.RGBA{R: 230, G: 230, B: 235, A: uint8(140 * (1 - d))})
+			}
+		}
+		return img
+	}
+}
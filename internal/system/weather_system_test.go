@@ -0,0 +1,42 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+	"github.com/project-midgard/midgarts/internal/weather"
+)
+
+func TestWeatherSystem(t *testing.T) {
+	commands := &opengl.RenderCommands{}
+	s := NewWeatherSystem(context.Background(), weather.Weather{Kind: weather.Snow}, &staticTextures{}, commands)
+	s.Focus = entity.NewCharacter(character.Male, jobspriteid.Knight, 1)
+	s.Focus.SetPosition(mgl32.Vec3{4, 38, -1})
+
+	s.Update(1)
+	assert.Len(t, commands.Sprites, weather.Presets[weather.Snow].Particles)
+	assert.Equal(t, weather.Presets[weather.Snow].Overlay, commands.Overlay)
+	for _, sprite := range commands.Sprites {
+		assert.Equal(t, float32(-1), sprite.Position.Z())
+		assert.True(t, sprite.Offset.Y() <= 0, "above the ground")
+	}
+
+	bus := event.NewBus()
+	defer s.Subscribe(bus)()
+	bus.Publish(event.WeatherCycled{})
+	assert.Equal(t, weather.Clouds, s.Weather().Kind)
+	bus.Publish(event.WeatherCycled{})
+
+	commands.Sprites = nil
+	s.Update(1)
+	assert.Empty(t, commands.Sprites)
+	assert.Equal(t, mgl32.Vec4{}, commands.Overlay)
+}
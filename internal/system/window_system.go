@@ -0,0 +1,106 @@
+package system
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/project-midgard/midgarts/internal/graphic"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// Window is a panel drawn over the frame, such as the windows of the
+// plugins (see plugin.WindowProvider).
+type Window interface {
+	// Visible tells whether the window is drawn.
+	Visible() bool
+	// Bounds returns the top left corner and the size of the window, in
+	// fractions of the screen from its top left corner.
+	Bounds() (position, size mgl32.Vec2)
+	// Version changes when the window must be drawn again.
+	Version() uint64
+	// Draw draws the window on img, of the size of the window in pixels.
+	Draw(img draw.Image)
+}
+
+// WindowSystem draws windows over the frame, in their order. The image of
+// a window is only drawn again when its version, or its size, changed.
+type WindowSystem struct {
+	windows         []*drawnWindow
+	renderCommands  *opengl.RenderCommands
+	textureProvider graphic.TextureProvider
+	// width and height are the size of the screen, in pixels.
+	width, height int
+}
+
+type drawnWindow struct {
+	Window
+	image   *graphic.UniqueRGBA
+	version uint64
+}
+
+// NewWindowSystem returns the system drawing windows with commands, on a
+// screen of width by height pixels.
+func NewWindowSystem(windows []Window, textureProvider graphic.TextureProvider, commands *opengl.RenderCommands, width, height int) *WindowSystem {
+	s := &WindowSystem{
+		renderCommands:  commands,
+		textureProvider: textureProvider,
+		width:           width,
+		height:          height,
+	}
+	for _, w := range windows {
+		s.windows = append(s.windows, &drawnWindow{Window: w})
+	}
+
+	return s
+}
+
+func (s *WindowSystem) Update(dt float32) {
+	for _, w := range s.windows {
+		if !w.Visible() {
+			continue
+		}
+
+		position, size := w.Bounds()
+		width, height := int(size.X()*float32(s.width)), int(size.Y()*float32(s.height))
+		if width <= 0 || height <= 0 {
+			continue
+		}
+
+		if w.image == nil || w.version != w.Version() || w.image.Bounds().Dx() != width || w.image.Bounds().Dy() != height {
+			s.deleteImage(w)
+			w.image = graphic.NewUniqueRGBA(image.Rect(0, 0, width, height))
+			w.version = w.Version()
+			w.Draw(w.image)
+		}
+
+		texture, err := s.textureProvider.NewTextureFromRGBA(w.image, graphic.TextureSprite)
+		if err != nil {
+			continue
+		}
+
+		s.renderCommands.HUD = append(s.renderCommands.HUD, opengl.HUDRenderCommand{
+			Texture:  texture,
+			Position: position,
+			Size:     size,
+		})
+	}
+}
+
+func (s *WindowSystem) Remove(ecs.BasicEntity) {}
+
+// Close deletes the textures of the windows.
+func (s *WindowSystem) Close() {
+	for _, w := range s.windows {
+		s.deleteImage(w)
+	}
+}
+
+func (s *WindowSystem) deleteImage(w *drawnWindow) {
+	if w.image != nil {
+		s.textureProvider.DeleteTexture(w.image)
+		w.image = nil
+	}
+}
@@ -0,0 +1,59 @@
+package system
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// testWindow is a window filled with red, counting its draws.
+type testWindow struct {
+	visible bool
+	version uint64
+	draws   int
+}
+
+func (w *testWindow) Visible() bool { return w.visible }
+
+func (w *testWindow) Bounds() (mgl32.Vec2, mgl32.Vec2) {
+	return mgl32.Vec2{0.5, 0}, mgl32.Vec2{0.25, 0.5}
+}
+
+func (w *testWindow) Version() uint64 { return w.version }
+
+func (w *testWindow) Draw(img draw.Image) {
+	w.draws++
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+}
+
+func TestWindowSystem(t *testing.T) {
+	w := &testWindow{}
+	commands := &opengl.RenderCommands{}
+	s := NewWindowSystem([]Window{w}, &staticTextures{}, commands, 320, 200)
+
+	s.Update(0.1)
+	assert.Empty(t, commands.HUD, "hidden")
+
+	w.visible = true
+	s.Update(0.1)
+	assert.Len(t, commands.HUD, 1)
+	assert.Equal(t, mgl32.Vec2{0.5, 0}, commands.HUD[0].Position)
+	assert.Equal(t, image.Rect(0, 0, 80, 100), s.windows[0].image.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, s.windows[0].image.RGBAAt(0, 0))
+
+	// Drawn again only when the window changes.
+	s.Update(0.1)
+	assert.Equal(t, 1, w.draws)
+	w.version++
+	s.Update(0.1)
+	assert.Equal(t, 2, w.draws)
+
+	s.Close()
+	assert.Nil(t, s.windows[0].image)
+}
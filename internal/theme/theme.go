@@ -0,0 +1,144 @@
+// Package theme holds the colors and the text size of what the client
+// draws over the map: the captions, the windows and the overlays. The
+// accessibility options select them: a palette safe for a kind of color
+// blindness, a text scale and a high-contrast mode.
+package theme
+
+import (
+	"image/color"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultPalette is the name of the palette of the colors as the
+	// client always drew them.
+	DefaultPalette = "default"
+	// MinTextScale and MaxTextScale bound the text scale.
+	MinTextScale = float32(1)
+	MaxTextScale = float32(3)
+)
+
+// Palette is the colors telling things apart.
+type Palette struct {
+	// HPHigh and HPLow are the colors of the HP bars, full and empty.
+	HPHigh, HPLow color.RGBA
+	// DamageDealt, DamageTaken and Heal are the colors of the numbers of
+	// the damage dealt by the player, taken by it, and of the heals.
+	DamageDealt, DamageTaken, Heal color.RGBA
+	// Walkable, NonWalkable, Water and Snipable are the colors of the cells
+	// of the walkability overlay.
+	Walkable, NonWalkable, Water, Snipable color.RGBA
+}
+
+// Palettes are the palettes by name. Besides the default one, they are
+// safe for the color blindness they are named after, picked from the
+// Okabe-Ito colors: the red and green ones are told apart by their
+// lightness as much as by their hue.
+var Palettes = map[string]Palette{
+	DefaultPalette: {
+		HPHigh:      color.RGBA{R: 60, G: 200, B: 60, A: 255},
+		HPLow:       color.RGBA{R: 200, G: 50, B: 50, A: 255},
+		DamageDealt: color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		DamageTaken: color.RGBA{R: 230, G: 60, B: 60, A: 255},
+		Heal:        color.RGBA{R: 80, G: 220, B: 80, A: 255},
+		Walkable:    color.RGBA{R: 60, G: 200, B: 60, A: 160},
+		NonWalkable: color.RGBA{R: 200, G: 50, B: 50, A: 160},
+		Water:       color.RGBA{R: 60, G: 110, B: 230, A: 160},
+		Snipable:    color.RGBA{R: 230, G: 200, B: 40, A: 160},
+	},
+	// Red and green look alike, the blues and the oranges do not.
+	"deuteranopia": redGreenSafe,
+	"protanopia":   redGreenSafe,
+	// Blue and yellow look alike, the reds and the teals do not.
+	"tritanopia": {
+		HPHigh:      color.RGBA{R: 0, G: 158, B: 115, A: 255},
+		HPLow:       color.RGBA{R: 213, G: 94, B: 0, A: 255},
+		DamageDealt: color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		DamageTaken: color.RGBA{R: 213, G: 94, B: 0, A: 255},
+		Heal:        color.RGBA{R: 0, G: 158, B: 115, A: 255},
+		Walkable:    color.RGBA{R: 0, G: 158, B: 115, A: 160},
+		NonWalkable: color.RGBA{R: 213, G: 94, B: 0, A: 160},
+		Water:       color.RGBA{R: 204, G: 121, B: 167, A: 160},
+		Snipable:    color.RGBA{R: 40, G: 40, B: 40, A: 160},
+	},
+}
+
+var redGreenSafe = Palette{
+	HPHigh:      color.RGBA{R: 0, G: 114, B: 178, A: 255},
+	HPLow:       color.RGBA{R: 230, G: 159, B: 0, A: 255},
+	DamageDealt: color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	DamageTaken: color.RGBA{R: 213, G: 94, B: 0, A: 255},
+	Heal:        color.RGBA{R: 86, G: 180, B: 233, A: 255},
+	Walkable:    color.RGBA{R: 0, G: 114, B: 178, A: 160},
+	NonWalkable: color.RGBA{R: 213, G: 94, B: 0, A: 160},
+	Water:       color.RGBA{R: 86, G: 180, B: 233, A: 160},
+	Snipable:    color.RGBA{R: 240, G: 228, B: 66, A: 160},
+}
+
+// PaletteNames returns the names of the palettes, sorted.
+func PaletteNames() []string {
+	names := make([]string, 0, len(Palettes))
+	for name := range Palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Theme is the look of the captions, the windows and the overlays.
+type Theme struct {
+	Palette Palette
+	// TextScale multiplies the size of the text, from MinTextScale to
+	// MaxTextScale.
+	TextScale float32
+	// Text is the color of the text, TextBackground the one of the boxes
+	// behind the captions, Panel the background of the windows and Accent
+	// the color of the text being typed.
+	Text, TextBackground, Panel, Accent color.RGBA
+}
+
+// Default is the theme of the client without accessibility options.
+var Default = Theme{
+	Palette:        Palettes[DefaultPalette],
+	TextScale:      1,
+	Text:           color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	TextBackground: color.RGBA{A: 160},
+	Panel:          color.RGBA{R: 16, G: 16, B: 32, A: 220},
+	Accent:         color.RGBA{R: 255, G: 220, B: 120, A: 255},
+}
+
+// New returns the theme of the palette of name, "" for the default one,
+// the text scale multiplying the size of the text, 0 for 1. In the
+// high-contrast mode, the text is drawn on opaque black and the overlays
+// are opaque.
+func New(palette string, textScale float32, highContrast bool) (Theme, error) {
+	t := Default
+	if palette != "" {
+		p, ok := Palettes[palette]
+		if !ok {
+			return Theme{}, errors.Errorf("unknown palette '%s', expected one of %v", palette, PaletteNames())
+		}
+		t.Palette = p
+	}
+
+	if textScale != 0 {
+		if textScale < MinTextScale || textScale > MaxTextScale {
+			return Theme{}, errors.Errorf("invalid text scale %g, expected %g to %g", textScale, MinTextScale, MaxTextScale)
+		}
+		t.TextScale = textScale
+	}
+
+	if highContrast {
+		t.TextBackground = color.RGBA{A: 255}
+		t.Panel = color.RGBA{A: 255}
+		t.Accent = color.RGBA{R: 255, G: 255, A: 255}
+		for _, c := range []*color.RGBA{&t.Palette.Walkable, &t.Palette.NonWalkable, &t.Palette.Water, &t.Palette.Snipable} {
+			c.A = 255
+		}
+	}
+
+	return t, nil
+}
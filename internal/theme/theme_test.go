@@ -0,0 +1,40 @@
+package theme
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	th, err := New("", 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, Default, th)
+
+	th, err = New("deuteranopia", 1.5, true)
+	require.NoError(t, err)
+	assert.Equal(t, Palettes["deuteranopia"].HPHigh, th.Palette.HPHigh)
+	assert.Equal(t, float32(1.5), th.TextScale)
+	assert.Equal(t, color.RGBA{A: 255}, th.TextBackground)
+	assert.Equal(t, uint8(255), th.Palette.Walkable.A)
+	// The palettes are not changed.
+	assert.Equal(t, uint8(160), Palettes["deuteranopia"].Walkable.A)
+
+	_, err = New("sepia", 1, false)
+	assert.Error(t, err)
+	_, err = New("", 0.5, false)
+	assert.Error(t, err)
+}
+
+func TestPalettes(t *testing.T) {
+	assert.Equal(t, []string{DefaultPalette, "deuteranopia", "protanopia", "tritanopia"}, PaletteNames())
+
+	for name, p := range Palettes {
+		assert.NotEqual(t, p.HPHigh, p.HPLow, name)
+		assert.NotEqual(t, p.DamageTaken, p.Heal, name)
+		cells := map[color.RGBA]bool{p.Walkable: true, p.NonWalkable: true, p.Water: true, p.Snipable: true}
+		assert.Len(t, cells, 4, name)
+	}
+}
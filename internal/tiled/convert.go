@@ -0,0 +1,255 @@
+package tiled
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+	"github.com/project-midgard/midgarts/internal/romap"
+)
+
+const (
+	// CellSize is the size of the tiles of the maps, in pixels: a GAT
+	// cell, half a GND surface.
+	CellSize = 32
+
+	// CellTypesSource is the file name of the tileset of the cell types.
+	CellTypesSource = "celltypes.tsx"
+	// CellTypesImage is the file name of the image of the tileset of the
+	// cell types (see CellTypesTileset).
+	CellTypesImage = "celltypes.png"
+
+	// cellTypeCount are the combinations of the romap.CellType flags.
+	cellTypeCount = 16
+	// defaultTextureSize is the size of the ground textures, in pixels,
+	// used when the size of a texture is not known.
+	defaultTextureSize = 256
+)
+
+// The colors of the cell types, the ones of the GAT overlay of the client,
+// semi-transparent over the ground layer.
+var (
+	walkableColor    = color.RGBA{R: 60, G: 200, B: 60, A: 160}
+	waterColor       = color.RGBA{R: 60, G: 110, B: 230, A: 160}
+	snipableColor    = color.RGBA{R: 230, G: 200, B: 40, A: 160}
+	nonWalkableColor = color.RGBA{R: 200, G: 50, B: 50, A: 160}
+)
+
+// Texture is a ground texture of a GND file.
+type Texture struct {
+	// Source is the path of the image, relative to the TSX files.
+	Source string
+	// Width and Height are the size of the image, 0 when it is not known.
+	Width, Height int
+}
+
+// Export is a map converted to Tiled: the map, and the tilesets it
+// references, by their source.
+type Export struct {
+	Map      *Map
+	Tilesets map[string]*Tileset
+}
+
+// NewExport converts the map name, of the cells of gatFile and of the
+// ground of gndFile, its textures being textures, in the order of the
+// GND textures. gndFile may be nil, for the collision layer only.
+//
+// The ground tiles are split in 2 by 2 cells, a tileset per texture cut in
+// the parts of the texture shown by a cell. The rotated tiles are drawn
+// unrotated.
+func NewExport(name string, gatFile *gat.GroundAltitudeFile, gndFile *gnd.GroundFile, textures []Texture) *Export {
+	width, height := int(gatFile.Width), int(gatFile.Height)
+	m := &Map{
+		Version:     Version,
+		Orientation: "orthogonal",
+		RenderOrder: "right-down",
+		Width:       width,
+		Height:      height,
+		TileWidth:   CellSize,
+		TileHeight:  CellSize,
+		Properties:  []Property{{Name: "name", Value: name}},
+	}
+	e := &Export{Map: m, Tilesets: map[string]*Tileset{CellTypesSource: CellTypesTileset()}}
+
+	// The cell types first, for their GIDs to be the romap.CellType plus
+	// one.
+	m.Tilesets = append(m.Tilesets, TilesetRef{FirstGID: 1, Source: CellTypesSource})
+	nextGID := 1 + cellTypeCount
+
+	var ground *Layer
+	if gndFile != nil && len(gndFile.Surfaces) > 0 {
+		ground = &Layer{ID: 1, Name: "ground", Width: width, Height: height, GIDs: make([]int, width*height)}
+	}
+	collision := Layer{ID: 2, Name: "collision", Width: width, Height: height, GIDs: make([]int, width*height)}
+
+	// The tilesets of the textures, as the tiles use them.
+	type groundTileset struct {
+		firstGID int
+		splits   int
+	}
+	tilesets := map[int]groundTileset{}
+
+	for y := 0; y < height; y++ {
+		// The rows go down from the north.
+		row := (height - 1 - y) * width
+		for x := 0; x < width; x++ {
+			collision.GIDs[row+x] = 1 + int(gatFile.CellAt(x, y).CellType)
+
+			if ground == nil {
+				continue
+			}
+
+			surface := gndFile.SurfaceAt(x/2, y/2)
+			if surface == nil || surface.TileUp == gnd.NoTile || int(surface.TileUp) >= len(gndFile.Tiles) {
+				continue
+			}
+
+			tile := gndFile.Tiles[surface.TileUp]
+			ts, ok := tilesets[tile.Texture]
+			if !ok {
+				ts = groundTileset{firstGID: nextGID, splits: splits(tile)}
+				tex := Texture{Source: "texture_" + strconv.Itoa(tile.Texture)}
+				if tile.Texture >= 0 && tile.Texture < len(textures) {
+					tex = textures[tile.Texture]
+				}
+
+				source := "ground_" + strconv.Itoa(tile.Texture) + ".tsx"
+				e.Tilesets[source] = newTextureTileset(tex, ts.splits)
+				m.Tilesets = append(m.Tilesets, TilesetRef{FirstGID: ts.firstGID, Source: source})
+				nextGID += ts.splits * ts.splits
+				tilesets[tile.Texture] = ts
+			}
+
+			col, r := cellPart(tile, ts.splits, x%2, y%2)
+			ground.GIDs[row+x] = ts.firstGID + r*ts.splits + col
+		}
+	}
+
+	if ground != nil {
+		m.Layers = append(m.Layers, *ground)
+	}
+	m.Layers = append(m.Layers, collision)
+	m.NextLayerID = 3
+
+	return e
+}
+
+// CellTypesTileset returns the tileset of the cell types, a tile per
+// combination of the romap.CellType flags, with their flags as boolean
+// properties. Its image is CellTypesImage.
+func CellTypesTileset() *Tileset {
+	t := &Tileset{
+		Version:    Version,
+		Name:       "celltypes",
+		TileWidth:  CellSize,
+		TileHeight: CellSize,
+		TileCount:  cellTypeCount,
+		Columns:    4,
+		Image:      Image{Source: CellTypesImage, Width: 4 * CellSize, Height: 4 * CellSize},
+	}
+
+	for i := 0; i < cellTypeCount; i++ {
+		cellType := romap.CellType(i)
+		t.Tiles = append(t.Tiles, TileInfo{ID: i, Properties: []Property{
+			{Name: "walkable", Type: "bool", Value: strconv.FormatBool(cellType&romap.CellTypeWalkable != 0)},
+			{Name: "water", Type: "bool", Value: strconv.FormatBool(cellType&romap.CellTypeWater != 0)},
+			{Name: "snipable", Type: "bool", Value: strconv.FormatBool(cellType&romap.CellTypeSnipable != 0)},
+		}})
+	}
+
+	return t
+}
+
+// CellTypesTilesetImage returns the image of CellTypesTileset, a color
+// per cell type.
+func CellTypesTilesetImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4*CellSize, 4*CellSize))
+	for i := 0; i < cellTypeCount; i++ {
+		min := image.Point{X: i % 4 * CellSize, Y: i / 4 * CellSize}
+		draw.Draw(img, image.Rectangle{Min: min, Max: min.Add(image.Point{X: CellSize, Y: CellSize})},
+			image.NewUniform(cellTypeColor(romap.CellType(i))), image.Point{}, draw.Src)
+	}
+
+	return img
+}
+
+func cellTypeColor(t romap.CellType) color.RGBA {
+	switch {
+	case t&romap.CellTypeWater != 0:
+		return waterColor
+	case t&romap.CellTypeWalkable != 0:
+		return walkableColor
+	case t&romap.CellTypeSnipable != 0:
+		return snipableColor
+	default:
+		return nonWalkableColor
+	}
+}
+
+// newTextureTileset returns the tileset of a ground texture cut in splits
+// by splits tiles.
+func newTextureTileset(tex Texture, splits int) *Tileset {
+	width, height := tex.Width, tex.Height
+	if width == 0 || height == 0 {
+		width, height = defaultTextureSize, defaultTextureSize
+	}
+
+	return &Tileset{
+		Version:    Version,
+		Name:       tex.Source,
+		TileWidth:  width / splits,
+		TileHeight: height / splits,
+		TileCount:  splits * splits,
+		Columns:    splits,
+		// The ground textures are BMP, magenta transparent.
+		Image: Image{Source: tex.Source, Trans: "ff00ff", Width: width, Height: height},
+	}
+}
+
+// splits returns in how many parts a side of the texture of tile is cut
+// for a part to be shown by a cell, half a tile.
+func splits(tile gnd.Tile) int {
+	size := math.Abs(float64(tile.U[1] - tile.U[0]))
+	if size == 0 {
+		return 2
+	}
+
+	return 2 * int(math.Max(1, math.Round(1/size)))
+}
+
+// cellPart returns the column and the row of the part of the texture of
+// tile shown by the cell of the tile at (x, y), each 0 or 1 from the
+// south west, in a texture cut in splits by splits parts.
+func cellPart(tile gnd.Tile, splits, x, y int) (col, row int) {
+	minU := math.Min(math.Min(float64(tile.U[0]), float64(tile.U[1])), math.Min(float64(tile.U[2]), float64(tile.U[3])))
+	minV := math.Min(math.Min(float64(tile.V[0]), float64(tile.V[1])), math.Min(float64(tile.V[2]), float64(tile.V[3])))
+
+	// The east half is on the side of the south east corner, the north
+	// half on the side of the north west one.
+	if tile.U[1] < tile.U[0] {
+		x = 1 - x
+	}
+	if tile.V[2] < tile.V[0] {
+		y = 1 - y
+	}
+
+	col = clamp(int(math.Round(minU*float64(splits)))+x, splits)
+	row = clamp(int(math.Round(minV*float64(splits)))+y, splits)
+
+	return col, row
+}
+
+func clamp(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+
+	return i
+}
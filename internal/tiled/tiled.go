@@ -0,0 +1,136 @@
+// Package tiled converts the maps to the TMX and TSX formats of the Tiled
+// map editor (https://www.mapeditor.org): a collision layer of the cell
+// types of the GAT file and a ground layer of the tiles of the GND file,
+// for the 2D remakes and the analysis of the levels.
+package tiled
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of the TMX format written.
+const Version = "1.10"
+
+// Map is a TMX map.
+type Map struct {
+	XMLName     xml.Name     `xml:"map"`
+	Version     string       `xml:"version,attr"`
+	Orientation string       `xml:"orientation,attr"`
+	RenderOrder string       `xml:"renderorder,attr"`
+	Width       int          `xml:"width,attr"`
+	Height      int          `xml:"height,attr"`
+	TileWidth   int          `xml:"tilewidth,attr"`
+	TileHeight  int          `xml:"tileheight,attr"`
+	Infinite    int          `xml:"infinite,attr"`
+	NextLayerID int          `xml:"nextlayerid,attr"`
+	Properties  []Property   `xml:"properties>property,omitempty"`
+	Tilesets    []TilesetRef `xml:"tileset"`
+	Layers      []Layer      `xml:"layer"`
+}
+
+// TilesetRef references a TSX tileset from a map, its tiles numbered
+// from FirstGID in the layers.
+type TilesetRef struct {
+	FirstGID int    `xml:"firstgid,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// Layer is a layer of tiles, GIDs being the global tile IDs of its cells,
+// row by row from the top left corner, 0 for no tile.
+type Layer struct {
+	ID     int       `xml:"id,attr"`
+	Name   string    `xml:"name,attr"`
+	Width  int       `xml:"width,attr"`
+	Height int       `xml:"height,attr"`
+	GIDs   []int     `xml:"-"`
+	Data   LayerData `xml:"data"`
+}
+
+// LayerData is the encoding of the GIDs of a layer, set by Map.Write.
+type LayerData struct {
+	Encoding string `xml:"encoding,attr"`
+	CSV      string `xml:",innerxml"`
+}
+
+// Tileset is a TSX tileset: an image cut in a grid of tiles.
+type Tileset struct {
+	XMLName    xml.Name   `xml:"tileset"`
+	Version    string     `xml:"version,attr"`
+	Name       string     `xml:"name,attr"`
+	TileWidth  int        `xml:"tilewidth,attr"`
+	TileHeight int        `xml:"tileheight,attr"`
+	TileCount  int        `xml:"tilecount,attr"`
+	Columns    int        `xml:"columns,attr"`
+	Image      Image      `xml:"image"`
+	Tiles      []TileInfo `xml:"tile,omitempty"`
+}
+
+type Image struct {
+	Source string `xml:"source,attr"`
+	// Trans is the transparent color, e.g. "ff00ff".
+	Trans  string `xml:"trans,attr,omitempty"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+// TileInfo holds the properties of a tile of a tileset.
+type TileInfo struct {
+	ID         int        `xml:"id,attr"`
+	Properties []Property `xml:"properties>property"`
+}
+
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:"value,attr"`
+}
+
+// Write writes m as a TMX file.
+func (m *Map) Write(w io.Writer) error {
+	for i := range m.Layers {
+		m.Layers[i].Data = LayerData{Encoding: "csv", CSV: csv(m.Layers[i].GIDs, m.Layers[i].Width)}
+	}
+
+	return writeXML(w, m)
+}
+
+// Write writes t as a TSX file.
+func (t *Tileset) Write(w io.Writer) error {
+	return writeXML(w, t)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// csv returns gids in the CSV encoding of the layers, a line per row of
+// width tiles.
+func csv(gids []int, width int) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	for i, gid := range gids {
+		b.WriteString(strconv.Itoa(gid))
+		if i < len(gids)-1 {
+			b.WriteString(",")
+		}
+		if (i+1)%width == 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
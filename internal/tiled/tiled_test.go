@@ -0,0 +1,102 @@
+package tiled
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/gat"
+	"github.com/project-midgard/midgarts/internal/fileformat/gnd"
+)
+
+// newMap returns a map of 4 by 2 cells, walkable but for its north east
+// cell, its west surface textured with the second quarter of the first
+// row of a texture and its east surface not.
+func newMap() (*gat.GroundAltitudeFile, *gnd.GroundFile) {
+	gatFile := &gat.GroundAltitudeFile{Width: 4, Height: 2, Cells: make([]gat.Cell, 8)}
+	for i := range gatFile.Cells {
+		gatFile.Cells[i].CellType = gat.Walkable | gat.Snipable
+	}
+	gatFile.Cells[7].CellType = gat.None
+
+	gndFile := &gnd.GroundFile{
+		Width:    2,
+		Height:   1,
+		Textures: []string{"grass.bmp"},
+		Tiles: []gnd.Tile{{
+			U: [4]float32{0.25, 0.5, 0.25, 0.5},
+			V: [4]float32{0, 0, 0.25, 0.25},
+		}},
+		Surfaces: []gnd.Surface{
+			{TileUp: 0, TileFront: gnd.NoTile, TileRight: gnd.NoTile},
+			{TileUp: gnd.NoTile, TileFront: gnd.NoTile, TileRight: gnd.NoTile},
+		},
+	}
+
+	return gatFile, gndFile
+}
+
+func TestNewExport(t *testing.T) {
+	gatFile, gndFile := newMap()
+	e := NewExport("prontera", gatFile, gndFile, []Texture{{Source: "texture/grass.bmp", Width: 512, Height: 512}})
+
+	m := e.Map
+	assert.Equal(t, 4, m.Width)
+	assert.Equal(t, 2, m.Height)
+	assert.Equal(t, []TilesetRef{{FirstGID: 1, Source: CellTypesSource}, {FirstGID: 17, Source: "ground_0.tsx"}}, m.Tilesets)
+
+	require.Len(t, m.Layers, 2)
+	ground, collision := m.Layers[0], m.Layers[1]
+	assert.Equal(t, "ground", ground.Name)
+	// The texture is cut in 8 by 8 parts of 64 pixels, a cell showing a
+	// quarter of the tile: the parts 2 and 3 of the rows 0 and 1, the
+	// north row first.
+	assert.Equal(t, []int{
+		17 + 1*8 + 2, 17 + 1*8 + 3, 0, 0,
+		17 + 0*8 + 2, 17 + 0*8 + 3, 0, 0,
+	}, ground.GIDs)
+
+	walkable := 1 + int(gat.Walkable|gat.Snipable)
+	assert.Equal(t, []int{
+		walkable, walkable, walkable, 1 + int(gat.None),
+		walkable, walkable, walkable, walkable,
+	}, collision.GIDs)
+
+	ts := e.Tilesets["ground_0.tsx"]
+	require.NotNil(t, ts)
+	assert.Equal(t, 64, ts.TileWidth)
+	assert.Equal(t, 64, ts.TileCount)
+	assert.Equal(t, "texture/grass.bmp", ts.Image.Source)
+	assert.NotNil(t, e.Tilesets[CellTypesSource])
+}
+
+func TestNewExportCollisionOnly(t *testing.T) {
+	gatFile, _ := newMap()
+	e := NewExport("prontera", gatFile, nil, nil)
+
+	require.Len(t, e.Map.Layers, 1)
+	assert.Equal(t, "collision", e.Map.Layers[0].Name)
+	assert.Len(t, e.Tilesets, 1)
+}
+
+func TestMapWrite(t *testing.T) {
+	gatFile, gndFile := newMap()
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExport("prontera", gatFile, gndFile, nil).Map.Write(&buf))
+
+	tmx := buf.String()
+	assert.Contains(t, tmx, `<map version="1.10" orientation="orthogonal" renderorder="right-down" width="4" height="2" tilewidth="32" tileheight="32" infinite="0" nextlayerid="3">`)
+	assert.Contains(t, tmx, `<property name="name" value="prontera"></property>`)
+	assert.Contains(t, tmx, "<data encoding=\"csv\">\n11,11,11,2,\n11,11,11,11\n</data>")
+}
+
+func TestCellTypesTilesetImage(t *testing.T) {
+	img := CellTypesTilesetImage()
+	walkable := int(gat.Walkable | gat.Snipable)
+	assert.Equal(t, walkableColor, img.RGBAAt(walkable%4*CellSize, walkable/4*CellSize))
+	assert.Equal(t, waterColor, img.RGBAAt(3*CellSize, 3*CellSize))
+	assert.Equal(t, nonWalkableColor, img.RGBAAt(int(gat.None)*CellSize, 0))
+}
@@ -0,0 +1,88 @@
+package timestep
+
+import "time"
+
+const (
+	// DefaultNear is the distance, in cells, within which the entities are
+	// updated every frame whatever the load.
+	DefaultNear = 15
+	// DefaultMaxSkipLevel bounds the skip level: the distant entities are
+	// updated at least every 2^DefaultMaxSkipLevel frames.
+	DefaultMaxSkipLevel = 3
+
+	// budgetWindow is the number of frames the skip level is adjusted
+	// after, from their average time.
+	budgetWindow = 30
+)
+
+// Budget tracks the frame times against a target and sheds the work of
+// the distant entities when the frames are too long, so the entities
+// near the player stay responsive under load. Past the target, the skip
+// level goes up and the entities farther than Near are only updated every
+// 2^level frames; it goes back down once the frames are well within the
+// target.
+type Budget struct {
+	// Target is the frame time to stay within, 0 to never skip.
+	Target time.Duration
+	// Near is the distance, in cells, within which the entities are never
+	// skipped.
+	Near float32
+	// MaxLevel bounds the skip level.
+	MaxLevel int
+
+	level int
+	// frame counts the frames; total is the time taken by the frames of
+	// the current window of budgetWindow frames.
+	frame  uint64
+	total  time.Duration
+	frames int
+}
+
+// NewBudget returns a budget of target per frame, 0 to never skip.
+func NewBudget(target time.Duration) *Budget {
+	return &Budget{Target: target, Near: DefaultNear, MaxLevel: DefaultMaxSkipLevel}
+}
+
+// Observe starts a new frame, the last one having taken frameTime.
+func (b *Budget) Observe(frameTime time.Duration) {
+	b.frame++
+
+	if b.Target <= 0 {
+		b.level = 0
+		return
+	}
+
+	b.total += frameTime
+	if b.frames++; b.frames < budgetWindow {
+		return
+	}
+
+	// The average of the window, a single slow frame does not count.
+	average := b.total / budgetWindow
+	b.total, b.frames = 0, 0
+
+	switch {
+	case average > b.Target && b.level < b.MaxLevel:
+		b.level++
+	case average < b.Target*3/4 && b.level > 0:
+		b.level--
+	}
+}
+
+// Level returns the skip level, 0 when every entity is updated every
+// frame.
+func (b *Budget) Level() int {
+	return b.level
+}
+
+// Skip tells whether the entity id, at distance cells from the player,
+// skips its update this frame. The distant entities are updated in turns,
+// a share of them every frame.
+func (b *Budget) Skip(id uint64, distance float32) bool {
+	if b.level == 0 || distance <= b.Near {
+		return false
+	}
+
+	period := uint64(1) << uint(b.level)
+	return (b.frame+id)%period != 0
+}
@@ -0,0 +1,67 @@
+package timestep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func observe(b *Budget, frameTime time.Duration, frames int) {
+	for i := 0; i < frames; i++ {
+		b.Observe(frameTime)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(20 * time.Millisecond)
+
+	observe(b, 16*time.Millisecond, 100)
+	assert.Equal(t, 0, b.Level())
+	assert.False(t, b.Skip(1, 100))
+
+	// A single slow frame is ignored.
+	observe(b, 100*time.Millisecond, 1)
+	observe(b, 16*time.Millisecond, 100)
+	assert.Equal(t, 0, b.Level())
+
+	observe(b, 40*time.Millisecond, budgetWindow)
+	assert.Equal(t, 1, b.Level())
+
+	// Bounded to MaxLevel.
+	observe(b, 40*time.Millisecond, 10*budgetWindow)
+	assert.Equal(t, DefaultMaxSkipLevel, b.Level())
+
+	// Back down once well within the target, not just within it.
+	observe(b, 19*time.Millisecond, 10*budgetWindow)
+	assert.Equal(t, DefaultMaxSkipLevel, b.Level())
+	observe(b, 10*time.Millisecond, 10*budgetWindow)
+	assert.Equal(t, 0, b.Level())
+}
+
+func TestBudgetSkip(t *testing.T) {
+	b := NewBudget(20 * time.Millisecond)
+	observe(b, 40*time.Millisecond, 2*budgetWindow)
+	assert.Equal(t, 2, b.Level())
+
+	updates := map[uint64]int{}
+	for frame := 0; frame < 8; frame++ {
+		b.Observe(40 * time.Millisecond)
+		for id := uint64(0); id < 4; id++ {
+			assert.False(t, b.Skip(id, DefaultNear), "near entities are never skipped")
+			if !b.Skip(id, 100) {
+				updates[id]++
+			}
+		}
+	}
+
+	// Every 4 frames, in turns.
+	assert.Equal(t, map[uint64]int{0: 2, 1: 2, 2: 2, 3: 2}, updates)
+}
+
+func TestBudgetNoTarget(t *testing.T) {
+	b := NewBudget(0)
+	observe(b, time.Second, 10*budgetWindow)
+	assert.Equal(t, 0, b.Level())
+	assert.False(t, b.Skip(1, 100))
+}
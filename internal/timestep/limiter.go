@@ -0,0 +1,43 @@
+package timestep
+
+import "time"
+
+// Limiter caps the frame rate, sleeping until the next frame is due.
+type Limiter struct {
+	period time.Duration
+	next   time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewLimiter returns a limiter of fps frames per second, or one not
+// waiting when fps is 0 or less.
+func NewLimiter(fps int) *Limiter {
+	l := &Limiter{now: time.Now, sleep: time.Sleep}
+	if fps > 0 {
+		l.period = time.Second / time.Duration(fps)
+	}
+
+	return l
+}
+
+// Wait returns when the next frame is due. Frames are due every period
+// from the first call; a frame late by more than a period does not make
+// the next ones hurry.
+func (l *Limiter) Wait() {
+	if l.period == 0 {
+		return
+	}
+
+	now := l.now()
+	if l.next.IsZero() || now.Sub(l.next) > l.period {
+		l.next = now.Add(l.period)
+		return
+	}
+
+	if wait := l.next.Sub(now); wait > 0 {
+		l.sleep(wait)
+	}
+	l.next = l.next.Add(l.period)
+}
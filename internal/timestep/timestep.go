@@ -0,0 +1,62 @@
+// Package timestep runs the simulation at a fixed rate, whatever the frame
+// rate: every frame advances the simulation by the ticks that fit in the
+// elapsed time, and the renderer interpolates between the last two ticks
+// with the remaining fraction of a tick. Limiter caps the frame rate, and
+// Budget sheds the work of the distant entities when the frames are slow.
+package timestep
+
+import "time"
+
+// DefaultRate is the default number of simulation ticks per second.
+const DefaultRate = 50
+
+// DefaultMaxTicks bounds the ticks run in a frame, so a slow frame does
+// not make the next ones slower catching up.
+const DefaultMaxTicks = 5
+
+type FixedStep struct {
+	// Tick is the duration of a tick, in seconds.
+	Tick     float32
+	MaxTicks int
+	// Scale is the speed of the simulation: 1 by default, 0 pauses it and
+	// 0.5 runs it in slow motion.
+	Scale float32
+
+	acc float32
+}
+
+// New returns a FixedStep of rate ticks per second.
+func New(rate int) *FixedStep {
+	return &FixedStep{
+		Tick:     float32(time.Second.Seconds()) / float32(rate),
+		MaxTicks: DefaultMaxTicks,
+		Scale:    1,
+	}
+}
+
+// Advance adds a frame of dt seconds, times Scale, and returns the number
+// of ticks to run. The time beyond MaxTicks is dropped.
+func (f *FixedStep) Advance(dt float32) int {
+	f.acc += dt * f.Scale
+
+	ticks := 0
+	for f.acc >= f.Tick {
+		f.acc -= f.Tick
+		ticks++
+
+		if ticks == f.MaxTicks {
+			if f.acc >= f.Tick {
+				f.acc = 0
+			}
+			break
+		}
+	}
+
+	return ticks
+}
+
+// Alpha returns the fraction of a tick elapsed since the last tick, to
+// interpolate from the state before it (0) to the state after it (1).
+func (f *FixedStep) Alpha() float32 {
+	return f.acc / f.Tick
+}
@@ -0,0 +1,74 @@
+package timestep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedStep(t *testing.T) {
+	f := New(50)
+	assert.Equal(t, float32(0.02), f.Tick)
+
+	assert.Equal(t, 0, f.Advance(0.01))
+	assert.InDelta(t, 0.5, f.Alpha(), 1e-5)
+
+	assert.Equal(t, 1, f.Advance(0.015))
+	assert.InDelta(t, 0.25, f.Alpha(), 1e-5)
+
+	assert.Equal(t, 2, f.Advance(0.04))
+	assert.InDelta(t, 0.25, f.Alpha(), 1e-5)
+}
+
+func TestFixedStepScale(t *testing.T) {
+	f := New(50)
+
+	f.Scale = 0.5
+	assert.Equal(t, 1, f.Advance(0.05))
+	assert.InDelta(t, 0.25, f.Alpha(), 1e-5)
+
+	f.Scale = 0
+	assert.Equal(t, 0, f.Advance(1))
+	assert.InDelta(t, 0.25, f.Alpha(), 1e-5)
+}
+
+func TestFixedStepMaxTicks(t *testing.T) {
+	f := New(50)
+
+	assert.Equal(t, DefaultMaxTicks, f.Advance(1))
+	assert.Equal(t, float32(0), f.Alpha())
+	assert.Equal(t, 0, f.Advance(0.01))
+}
+
+func TestLimiter(t *testing.T) {
+	clock := time.Unix(0, 0)
+	var slept []time.Duration
+
+	l := NewLimiter(50)
+	l.now = func() time.Time { return clock }
+	l.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+	}
+
+	l.Wait()
+	clock = clock.Add(5 * time.Millisecond)
+	l.Wait()
+	clock = clock.Add(25 * time.Millisecond)
+	l.Wait()
+	clock = clock.Add(100 * time.Millisecond)
+	l.Wait()
+	clock = clock.Add(10 * time.Millisecond)
+	l.Wait()
+
+	assert.Equal(t, []time.Duration{15 * time.Millisecond, 10 * time.Millisecond}, slept)
+}
+
+func TestLimiterUncapped(t *testing.T) {
+	l := NewLimiter(0)
+	l.sleep = func(time.Duration) { t.Fatal("uncapped limiter slept") }
+
+	l.Wait()
+	l.Wait()
+}
@@ -0,0 +1,120 @@
+// Package touch recognizes the gestures of the touch screens, such as the
+// ones of the phones and tablets: a tap, a short touch of a finger, moves
+// or picks like a click, and a pinch, two fingers moving apart or closer,
+// zooms.
+package touch
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// TapDuration is the longest touch of a tap.
+	TapDuration = 300 * time.Millisecond
+	// TapDistance is the farthest, in pixels, a finger moves during a tap.
+	TapDistance = 16
+)
+
+// Tap is a short touch of a single finger, in window pixels from the
+// top-left corner.
+type Tap struct {
+	X, Y int
+}
+
+// Pinch is a move of two fingers, Scale being the ratio of the distance
+// between them to the one before the move: above 1 when they move apart.
+type Pinch struct {
+	Scale float32
+}
+
+type finger struct {
+	down           time.Duration
+	startX, startY float32
+	x, y           float32
+	// moved is set once the finger moved too far for a tap.
+	moved bool
+}
+
+// Recognizer recognizes the gestures from the fingers touching the screen,
+// in window pixels, at the times of the events.
+type Recognizer struct {
+	fingers map[int64]*finger
+	// multi is set while a gesture of several fingers goes on, until they
+	// are all released: none of them is a tap.
+	multi bool
+}
+
+// NewRecognizer returns a recognizer with no finger on the screen.
+func NewRecognizer() *Recognizer {
+	return &Recognizer{fingers: map[int64]*finger{}}
+}
+
+// Down starts the touch of the finger id at (x, y), at t.
+func (r *Recognizer) Down(id int64, x, y float32, t time.Duration) {
+	r.fingers[id] = &finger{down: t, startX: x, startY: y, x: x, y: y}
+	if len(r.fingers) > 1 {
+		r.multi = true
+	}
+}
+
+// Move moves the finger id to (x, y), returning the pinch it makes with a
+// second finger, if any.
+func (r *Recognizer) Move(id int64, x, y float32) (Pinch, bool) {
+	f, ok := r.fingers[id]
+	if !ok {
+		return Pinch{}, false
+	}
+
+	var other *finger
+	if len(r.fingers) == 2 {
+		for otherID, o := range r.fingers {
+			if otherID != id {
+				other = o
+			}
+		}
+	}
+
+	previousX, previousY := f.x, f.y
+	f.x, f.y = x, y
+	if distance(f.startX, f.startY, x, y) > TapDistance {
+		f.moved = true
+	}
+
+	if other == nil {
+		return Pinch{}, false
+	}
+
+	before := distance(previousX, previousY, other.x, other.y)
+	if before == 0 {
+		return Pinch{}, false
+	}
+
+	return Pinch{Scale: distance(x, y, other.x, other.y) / before}, true
+}
+
+// Up ends the touch of the finger id at (x, y), at t, returning the tap
+// it makes, if any.
+func (r *Recognizer) Up(id int64, x, y float32, t time.Duration) (Tap, bool) {
+	f, ok := r.fingers[id]
+	if !ok {
+		return Tap{}, false
+	}
+	delete(r.fingers, id)
+
+	multi := r.multi
+	if len(r.fingers) == 0 {
+		r.multi = false
+	}
+
+	if multi || f.moved || t-f.down > TapDuration || distance(f.startX, f.startY, x, y) > TapDistance {
+		return Tap{}, false
+	}
+
+	return Tap{X: int(x), Y: int(y)}, true
+}
+
+// distance returns the distance between (x1, y1) and (x2, y2).
+func distance(x1, y1, x2, y2 float32) float32 {
+	return float32(math.Hypot(float64(x2-x1), float64(y2-y1)))
+}
@@ -0,0 +1,61 @@
+package touch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTap(t *testing.T) {
+	r := NewRecognizer()
+
+	r.Down(1, 100, 200, 0)
+	_, ok := r.Move(1, 105, 203)
+	assert.False(t, ok)
+	tap, ok := r.Up(1, 105, 203, 100*time.Millisecond)
+	assert.True(t, ok)
+	assert.Equal(t, Tap{X: 105, Y: 203}, tap)
+
+	// Too long.
+	r.Down(1, 100, 200, time.Second)
+	_, ok = r.Up(1, 100, 200, time.Second+TapDuration+1)
+	assert.False(t, ok)
+
+	// Dragged, even if back to the start.
+	r.Down(1, 100, 200, 2*time.Second)
+	r.Move(1, 150, 200)
+	r.Move(1, 100, 200)
+	_, ok = r.Up(1, 100, 200, 2*time.Second+10*time.Millisecond)
+	assert.False(t, ok)
+
+	_, ok = r.Up(2, 0, 0, 0)
+	assert.False(t, ok)
+}
+
+func TestPinch(t *testing.T) {
+	r := NewRecognizer()
+
+	r.Down(1, 100, 100, 0)
+	r.Down(2, 200, 100, 10*time.Millisecond)
+
+	pinch, ok := r.Move(2, 300, 100)
+	assert.True(t, ok)
+	assert.InDelta(t, 2, pinch.Scale, 1e-6)
+
+	pinch, ok = r.Move(1, 200, 100)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, pinch.Scale, 1e-6)
+
+	// None of the fingers of a pinch is a tap, even the last one up.
+	_, ok = r.Up(1, 200, 100, 50*time.Millisecond)
+	assert.False(t, ok)
+	_, ok = r.Move(2, 310, 100)
+	assert.False(t, ok)
+	_, ok = r.Up(2, 300, 100, 60*time.Millisecond)
+	assert.False(t, ok)
+
+	r.Down(3, 100, 100, time.Second)
+	_, ok = r.Up(3, 100, 100, time.Second)
+	assert.True(t, ok)
+}
@@ -0,0 +1,127 @@
+package weather
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Particle is a raindrop, a snowflake or a cloud.
+type Particle struct {
+	// Position is the point of the ground plane the particle is above.
+	Position mgl32.Vec2
+	// Height is the height of the particle above Position.
+	Height float32
+	// Phase is where the particle is in its sway, in radians.
+	Phase float32
+}
+
+// Offset returns how far the particle swings to the side of its position,
+// for a sway of amplitude.
+func (p Particle) Offset(sway float32) float32 {
+	return sway * float32(math.Sin(float64(p.Phase)))
+}
+
+// Field is the particles of a weather around a center, the point the
+// camera looks at. The particles that reach the ground, or are left out
+// of the radius of the preset when the center moves, start over at a
+// random point around the center. It must be used from a single
+// goroutine.
+type Field struct {
+	Weather Weather
+
+	particles []Particle
+	rand      *rand.Rand
+}
+
+// NewField returns the field of w, its particles drawn from r.
+func NewField(w Weather, r *rand.Rand) *Field {
+	return &Field{Weather: w, rand: r}
+}
+
+// Set changes the weather of the field, keeping its particles when the
+// kind of weather stays the same.
+func (f *Field) Set(w Weather) {
+	if w.Kind != f.Weather.Kind {
+		f.particles = f.particles[:0]
+	}
+	f.Weather = w
+}
+
+// Particles returns the particles of the field.
+func (f *Field) Particles() []Particle {
+	return f.particles
+}
+
+// Update moves the particles dt seconds later, around center. A field
+// gets its particles over the first second, not to fall all at once.
+func (f *Field) Update(dt float32, center mgl32.Vec2) {
+	p, ok := f.Weather.Preset()
+	if !ok {
+		f.particles = f.particles[:0]
+		return
+	}
+
+	n := int(float32(p.Particles) * f.Weather.intensity())
+	if len(f.particles) > n {
+		f.particles = f.particles[:n]
+	}
+
+	for i := range f.particles {
+		particle := &f.particles[i]
+		particle.Position = particle.Position.Add(p.Wind.Mul(dt))
+		particle.Height -= p.Fall * dt
+		particle.Phase += 2 * dt
+
+		if particle.Height <= 0 || particle.Position.Sub(center).Len() > p.Radius {
+			*particle = f.spawn(p, center, p.Top)
+		}
+	}
+
+	spawns := n - len(f.particles)
+	if limit := int(math.Ceil(float64(float32(n) * dt))); spawns > limit {
+		spawns = limit
+	}
+	for i := 0; i < spawns; i++ {
+		// The falling ones at any height, as if they had been falling
+		// already.
+		height := p.Top
+		if p.Fall > 0 {
+			height *= f.rand.Float32()
+		}
+		f.particles = append(f.particles, f.spawn(p, center, height))
+	}
+}
+
+// spawn returns a particle at height, at a random point of the disk of
+// the radius of p around center.
+func (f *Field) spawn(p Preset, center mgl32.Vec2, height float32) Particle {
+	angle := 2 * math.Pi * f.rand.Float64()
+	distance := p.Radius * float32(math.Sqrt(f.rand.Float64()))
+
+	return Particle{
+		Position: center.Add(mgl32.Vec2{
+			distance * float32(math.Cos(angle)),
+			distance * float32(math.Sin(angle)),
+		}),
+		Height: height,
+		Phase:  2 * math.Pi * f.rand.Float32(),
+	}
+}
+
+// Sound returns the sound looped under the weather of the field and its
+// volume, from 0 to 1, if any.
+func (f *Field) Sound() (name string, volume float32, ok bool) {
+	p, ok := f.Weather.Preset()
+	if !ok || p.Sound == "" {
+		return "", 0, false
+	}
+
+	volume = f.Weather.intensity()
+	if volume > 1 {
+		volume = 1
+	}
+
+	return p.Sound, volume, true
+}
@@ -0,0 +1,186 @@
+// Package weather simulates the rain, the snow and the clouds of the maps:
+// the particles falling or drifting around the camera, the tint of the
+// screen and the sound loop of each kind of weather. The weather of a map
+// comes from a table of the maps (see LoadMaps), and can be changed at run
+// time by the scripts and the debug keys.
+//
+// The particles are on the ground plane of the world, each at a height
+// above it, so they fall as seen from any camera angle.
+package weather
+
+import (
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is a kind of weather.
+type Kind string
+
+const (
+	None   Kind = "none"
+	Rain   Kind = "rain"
+	Snow   Kind = "snow"
+	Clouds Kind = "clouds"
+)
+
+// Kinds returns the kinds of weather, None first.
+func Kinds() []Kind {
+	return []Kind{None, Rain, Snow, Clouds}
+}
+
+// Parse returns the kind of a name, ignoring case. The empty name is None.
+func Parse(name string) (Kind, error) {
+	if name == "" {
+		return None, nil
+	}
+	for _, k := range Kinds() {
+		if strings.EqualFold(string(k), name) {
+			return k, nil
+		}
+	}
+
+	return "", errors.Errorf("unknown weather '%s'", name)
+}
+
+// Next returns the kind after k, back to None after the last one.
+func (k Kind) Next() Kind {
+	kinds := Kinds()
+	for i, kind := range kinds {
+		if kind == k {
+			return kinds[(i+1)%len(kinds)]
+		}
+	}
+
+	return None
+}
+
+// Weather is the weather of a map.
+type Weather struct {
+	Kind Kind `yaml:"kind"`
+	// Intensity scales the number of particles, the tint and the volume
+	// of the sound, 1 by default.
+	Intensity float32 `yaml:"intensity"`
+}
+
+// Preset is how a kind of weather looks and sounds, at intensity 1.
+type Preset struct {
+	// Particles is the number of particles around the camera.
+	Particles int
+	// Radius is the distance from the camera, in world units, the
+	// particles are spread over, and Top the height they start from.
+	Radius, Top float32
+	// Fall is the speed the particles fall at, in world units per
+	// second, 0 for the particles staying at their height.
+	Fall float32
+	// Wind is the speed the particles drift at on the ground plane.
+	Wind mgl32.Vec2
+	// Sway is how far the particles swing to the sides while falling.
+	Sway float32
+	// Overlay is the color the screen is tinted with, its alpha the
+	// opacity of the tint.
+	Overlay mgl32.Vec4
+	// Sound is the sound file looped while the weather lasts, if any.
+	Sound string
+}
+
+// Presets are the looks of the kinds of weather.
+var Presets = map[Kind]Preset{
+	Rain: {
+		Particles: 600,
+		Radius:    18,
+		Top:       12,
+		Fall:      24,
+		Wind:      mgl32.Vec2{-1.5, 0},
+		Overlay:   mgl32.Vec4{0.1, 0.12, 0.2, 0.25},
+		Sound:     "weather/rain.wav",
+	},
+	Snow: {
+		Particles: 400,
+		Radius:    18,
+		Top:       12,
+		Fall:      2.5,
+		Wind:      mgl32.Vec2{-0.5, 0.3},
+		Sway:      0.4,
+		Overlay:   mgl32.Vec4{0.85, 0.9, 1, 0.12},
+		Sound:     "weather/wind.wav",
+	},
+	Clouds: {
+		Particles: 12,
+		Radius:    30,
+		Top:       9,
+		Wind:      mgl32.Vec2{-0.8, 0.2},
+		Overlay:   mgl32.Vec4{0.3, 0.3, 0.35, 0.15},
+	},
+}
+
+// Preset returns the preset of the kind of w, and whether it has one.
+func (w Weather) Preset() (Preset, bool) {
+	p, ok := Presets[w.Kind]
+	return p, ok
+}
+
+// intensity returns the intensity of w, 1 when not set.
+func (w Weather) intensity() float32 {
+	if w.Intensity <= 0 {
+		return 1
+	}
+
+	return w.Intensity
+}
+
+// Overlay returns the tint of the screen under w, the zero color none.
+func (w Weather) Overlay() mgl32.Vec4 {
+	p, ok := w.Preset()
+	if !ok {
+		return mgl32.Vec4{}
+	}
+
+	alpha := p.Overlay.W() * w.intensity()
+	if alpha > 1 {
+		alpha = 1
+	}
+
+	return mgl32.Vec4{p.Overlay.X(), p.Overlay.Y(), p.Overlay.Z(), alpha}
+}
+
+// DefaultMaps are the maps with a weather of their own, snowing in
+// Lutie.
+var DefaultMaps = map[string]Weather{
+	"xmas":        {Kind: Snow, Intensity: 1},
+	"xmas_fild01": {Kind: Snow, Intensity: 1},
+}
+
+// LoadMaps returns the weather of the maps of a YAML file, by map name:
+//
+//	xmas: {kind: snow}
+//	prontera: {kind: rain, intensity: 0.5}
+func LoadMaps(data []byte) (map[string]Weather, error) {
+	var maps map[string]Weather
+	if err := yaml.Unmarshal(data, &maps); err != nil {
+		return nil, errors.Wrap(err, "could not decode weather maps")
+	}
+
+	for name, w := range maps {
+		kind, err := Parse(string(w.Kind))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid weather of map '%s'", name)
+		}
+		w.Kind = kind
+		maps[name] = w
+	}
+
+	return maps, nil
+}
+
+// ForMap returns the weather of the map name in maps, None when it has
+// none.
+func ForMap(maps map[string]Weather, name string) Weather {
+	if w, ok := maps[name]; ok {
+		return w
+	}
+
+	return Weather{Kind: None}
+}
@@ -0,0 +1,86 @@
+package weather
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for name, want := range map[string]Kind{"": None, "none": None, "Rain": Rain, "SNOW": Snow, "clouds": Clouds} {
+		kind, err := Parse(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, kind, name)
+	}
+
+	_, err := Parse("hail")
+	assert.Error(t, err)
+}
+
+func TestNext(t *testing.T) {
+	assert.Equal(t, Rain, None.Next())
+	assert.Equal(t, None, Clouds.Next())
+}
+
+func TestLoadMaps(t *testing.T) {
+	maps, err := LoadMaps([]byte("xmas: {kind: snow}\nprontera: {kind: Rain, intensity: 0.5}\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, Weather{Kind: Snow}, ForMap(maps, "xmas"))
+	assert.Equal(t, Weather{Kind: Rain, Intensity: 0.5}, ForMap(maps, "prontera"))
+	assert.Equal(t, Weather{Kind: None}, ForMap(maps, "izlude"))
+
+	_, err = LoadMaps([]byte("xmas: {kind: hail}"))
+	assert.Error(t, err)
+}
+
+func TestOverlay(t *testing.T) {
+	assert.Equal(t, mgl32.Vec4{}, Weather{Kind: None}.Overlay())
+
+	rain := Presets[Rain].Overlay
+	assert.Equal(t, rain, Weather{Kind: Rain}.Overlay())
+	assert.InDelta(t, rain.W()/2, Weather{Kind: Rain, Intensity: 0.5}.Overlay().W(), 1e-6)
+}
+
+func TestField(t *testing.T) {
+	f := NewField(Weather{Kind: Rain, Intensity: 0.5}, rand.New(rand.NewSource(1)))
+	center := mgl32.Vec2{10, 40}
+	preset := Presets[Rain]
+
+	// The particles come over a second.
+	f.Update(0.5, center)
+	assert.Len(t, f.Particles(), preset.Particles/4)
+	for i := 0; i < 60; i++ {
+		f.Update(1.0/30, center)
+	}
+	require.Len(t, f.Particles(), preset.Particles/2)
+
+	for _, p := range f.Particles() {
+		assert.LessOrEqual(t, p.Position.Sub(center).Len(), preset.Radius)
+		assert.True(t, p.Height > 0 && p.Height <= preset.Top, "height %f", p.Height)
+	}
+
+	// The particles left behind start over around the camera.
+	moved := center.Add(mgl32.Vec2{100, 0})
+	f.Update(0.01, moved)
+	for _, p := range f.Particles() {
+		assert.LessOrEqual(t, p.Position.Sub(moved).Len(), preset.Radius)
+	}
+
+	f.Set(Weather{Kind: None})
+	f.Update(0.01, center)
+	assert.Empty(t, f.Particles())
+}
+
+func TestSound(t *testing.T) {
+	name, volume, ok := NewField(Weather{Kind: Rain, Intensity: 2}, rand.New(rand.NewSource(1))).Sound()
+	assert.True(t, ok)
+	assert.Equal(t, Presets[Rain].Sound, name)
+	assert.Equal(t, float32(1), volume)
+
+	_, _, ok = NewField(Weather{Kind: Clouds}, nil).Sound()
+	assert.False(t, ok)
+}
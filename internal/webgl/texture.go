@@ -0,0 +1,51 @@
+//go:build js && wasm
+// +build js,wasm
+
+package webgl
+
+import (
+	"syscall/js"
+
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/graphic"
+)
+
+// Texture is an image uploaded to the canvas.
+type Texture struct {
+	value         js.Value
+	Width, Height int
+}
+
+// NewTexture uploads rgba to a new texture sampled with filter.
+func (c *Context) NewTexture(rgba *graphic.UniqueRGBA, filter graphic.Filter) (*Texture, error) {
+	size := rgba.Rect.Size()
+	if rgba.Stride != size.X*4 {
+		return nil, errors.New("unsupported stride")
+	}
+
+	data := js.Global().Get("Uint8Array").New(len(rgba.Pix))
+	js.CopyBytesToJS(data, rgba.Pix)
+
+	tex := &Texture{value: c.gl.Call("createTexture"), Width: size.X, Height: size.Y}
+	target := c.enum("TEXTURE_2D")
+	c.gl.Call("bindTexture", target, tex.value)
+	c.gl.Call("texImage2D", target, 0, c.enum("RGBA"), size.X, size.Y, 0, c.enum("RGBA"), c.enum("UNSIGNED_BYTE"), data)
+
+	sampling := c.enum("NEAREST")
+	if filter == graphic.FilterLinear {
+		sampling = c.enum("LINEAR")
+	}
+	c.gl.Call("texParameteri", target, c.enum("TEXTURE_MAG_FILTER"), sampling)
+	c.gl.Call("texParameteri", target, c.enum("TEXTURE_MIN_FILTER"), sampling)
+	c.gl.Call("texParameteri", target, c.enum("TEXTURE_WRAP_S"), c.enum("CLAMP_TO_EDGE"))
+	c.gl.Call("texParameteri", target, c.enum("TEXTURE_WRAP_T"), c.enum("CLAMP_TO_EDGE"))
+
+	return tex, nil
+}
+
+// Delete frees the texture.
+func (c *Context) Delete(tex *Texture) {
+	c.gl.Call("deleteTexture", tex.value)
+	tex.value = js.Null()
+}
@@ -0,0 +1,209 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package webgl is the render backend of the WebAssembly build: it draws
+// the sprites in a browser canvas with WebGL 2, as textured quads in
+// pixels from the top left corner of the canvas. The sprites are drawn
+// from the atlas of their SPR file (see spr.SpriteFile.Atlas), a texture
+// per file, like the OpenGL backend does.
+package webgl
+
+import (
+	"encoding/binary"
+	"math"
+	"syscall/js"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+)
+
+// floatsPerVertex are the position, the texture coordinates and the color
+// of a vertex.
+const floatsPerVertex = 2 + 2 + 4
+
+const vertexShader = `#version 300 es
+uniform vec2 viewport;
+in vec2 position;
+in vec2 texCoord;
+in vec4 color;
+out vec2 uv;
+out vec4 tint;
+
+void main() {
+	gl_Position = vec4(position.x / viewport.x * 2.0 - 1.0, 1.0 - position.y / viewport.y * 2.0, 0.0, 1.0);
+	uv = texCoord;
+	tint = color;
+}
+`
+
+const fragmentShader = `#version 300 es
+precision mediump float;
+uniform sampler2D tex;
+in vec2 uv;
+in vec4 tint;
+out vec4 fragColor;
+
+void main() {
+	fragColor = texture(tex, uv) * tint;
+}
+`
+
+// Quad is a textured rectangle.
+type Quad struct {
+	// Center is the center of the quad and Size its size, in pixels.
+	Center, Size mgl32.Vec2
+	// RotationRadians turns the quad clockwise around its center.
+	RotationRadians float32
+	// UV are the texture coordinates of the top left corner, then of the
+	// bottom right one (see graphic.Atlas.UV).
+	UV               mgl32.Vec4
+	FlipHorizontally bool
+	// Color multiplies the texture, white to draw it as is.
+	Color mgl32.Vec4
+}
+
+// Context draws in a canvas.
+type Context struct {
+	gl       js.Value
+	canvas   js.Value
+	buffer   js.Value
+	viewport js.Value
+
+	vertices []float32
+	bytes    []byte
+}
+
+// NewContext returns the WebGL 2 context of canvas, ready to draw quads.
+func NewContext(canvas js.Value) (*Context, error) {
+	gl := canvas.Call("getContext", "webgl2")
+	if gl.IsNull() {
+		return nil, errors.New("WebGL 2 is not supported")
+	}
+
+	c := &Context{gl: gl, canvas: canvas}
+
+	program, err := c.newProgram(vertexShader, fragmentShader)
+	if err != nil {
+		return nil, err
+	}
+	gl.Call("useProgram", program)
+	c.viewport = gl.Call("getUniformLocation", program, "viewport")
+
+	c.buffer = gl.Call("createBuffer")
+	gl.Call("bindBuffer", c.enum("ARRAY_BUFFER"), c.buffer)
+
+	stride := floatsPerVertex * 4
+	for _, attr := range []struct {
+		name   string
+		size   int
+		offset int
+	}{
+		{"position", 2, 0},
+		{"texCoord", 2, 2 * 4},
+		{"color", 4, 4 * 4},
+	} {
+		location := gl.Call("getAttribLocation", program, attr.name)
+		gl.Call("enableVertexAttribArray", location)
+		gl.Call("vertexAttribPointer", location, attr.size, c.enum("FLOAT"), false, stride, attr.offset)
+	}
+
+	gl.Call("enable", c.enum("BLEND"))
+	gl.Call("blendFunc", c.enum("SRC_ALPHA"), c.enum("ONE_MINUS_SRC_ALPHA"))
+
+	return c, nil
+}
+
+// enum returns the WebGL constant name.
+func (c *Context) enum(name string) js.Value {
+	return c.gl.Get(name)
+}
+
+func (c *Context) newProgram(vert, frag string) (js.Value, error) {
+	program := c.gl.Call("createProgram")
+
+	for _, s := range []struct {
+		kind   string
+		source string
+	}{{"VERTEX_SHADER", vert}, {"FRAGMENT_SHADER", frag}} {
+		shader := c.gl.Call("createShader", c.enum(s.kind))
+		c.gl.Call("shaderSource", shader, s.source)
+		c.gl.Call("compileShader", shader)
+		if !c.gl.Call("getShaderParameter", shader, c.enum("COMPILE_STATUS")).Bool() {
+			return js.Null(), errors.Errorf("failed to compile %s: %s", s.kind, c.gl.Call("getShaderInfoLog", shader).String())
+		}
+		c.gl.Call("attachShader", program, shader)
+	}
+
+	c.gl.Call("linkProgram", program)
+	if !c.gl.Call("getProgramParameter", program, c.enum("LINK_STATUS")).Bool() {
+		return js.Null(), errors.Errorf("failed to link program: %s", c.gl.Call("getProgramInfoLog", program).String())
+	}
+
+	return program, nil
+}
+
+// Size returns the size of the canvas, in pixels.
+func (c *Context) Size() (width, height int) {
+	return c.canvas.Get("width").Int(), c.canvas.Get("height").Int()
+}
+
+// Clear starts a frame, clearing the canvas with color.
+func (c *Context) Clear(color [4]float32) {
+	width, height := c.Size()
+	c.gl.Call("viewport", 0, 0, width, height)
+	c.gl.Call("uniform2f", c.viewport, width, height)
+	c.gl.Call("clearColor", color[0], color[1], color[2], color[3])
+	c.gl.Call("clear", c.enum("COLOR_BUFFER_BIT"))
+}
+
+// Draw draws quads textured with tex, in order.
+func (c *Context) Draw(tex *Texture, quads ...Quad) {
+	if len(quads) == 0 {
+		return
+	}
+
+	c.vertices = c.vertices[:0]
+	for _, q := range quads {
+		c.vertices = appendQuad(c.vertices, q)
+	}
+
+	n := len(c.vertices) * 4
+	if cap(c.bytes) < n {
+		c.bytes = make([]byte, n)
+	}
+	c.bytes = c.bytes[:n]
+	for i, v := range c.vertices {
+		binary.LittleEndian.PutUint32(c.bytes[i*4:], math.Float32bits(v))
+	}
+
+	data := js.Global().Get("Uint8Array").New(n)
+	js.CopyBytesToJS(data, c.bytes)
+
+	c.gl.Call("bindTexture", c.enum("TEXTURE_2D"), tex.value)
+	c.gl.Call("bufferData", c.enum("ARRAY_BUFFER"), data, c.enum("STREAM_DRAW"))
+	c.gl.Call("drawArrays", c.enum("TRIANGLES"), 0, len(c.vertices)/floatsPerVertex)
+}
+
+// appendQuad appends the 2 triangles of q to vertices.
+func appendQuad(vertices []float32, q Quad) []float32 {
+	u0, v0, u1, v1 := q.UV[0], q.UV[1], q.UV[2], q.UV[3]
+	if q.FlipHorizontally {
+		u0, u1 = u1, u0
+	}
+
+	half := q.Size.Mul(0.5)
+	rotation := mgl32.Rotate2D(q.RotationRadians)
+	corner := func(x, y, u, v float32) []float32 {
+		p := q.Center.Add(rotation.Mul2x1(mgl32.Vec2{x * half.X(), y * half.Y()}))
+		return append(vertices, p.X(), p.Y(), u, v, q.Color[0], q.Color[1], q.Color[2], q.Color[3])
+	}
+
+	vertices = corner(-1, -1, u0, v0)
+	vertices = corner(1, -1, u1, v0)
+	vertices = corner(1, 1, u1, v1)
+	vertices = corner(-1, -1, u0, v0)
+	vertices = corner(1, 1, u1, v1)
+	vertices = corner(-1, 1, u0, v1)
+
+	return vertices
+}
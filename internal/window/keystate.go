@@ -263,6 +263,12 @@ func (ks *KeyState) Pressed(keyCode sdl.Keycode) bool {
 	return ks.states[keyCode]
 }
 
+// Set records whether keyCode is pressed, for key events coming from
+// elsewhere than SDL (see Update).
+func (ks *KeyState) Set(keyCode sdl.Keycode, pressed bool) {
+	ks.states[keyCode] = pressed
+}
+
 func (ks *KeyState) Update(event sdl.Event) {
 	if event == nil {
 		return
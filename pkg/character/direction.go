@@ -0,0 +1,101 @@
+// Package character resolves which of the 8 directions of its sprite a
+// character shows, from where it faces and where the camera looks from.
+//
+// The directions are those of the actions of the sprites: each action is
+// 8 consecutive actions, one per Direction, South being the front of the
+// sprite.
+//
+// It also models the progression of the characters: their levels, their
+// experience and their stats, and the attributes derived from them (see
+// Progression), for the status windows, the timing of the animations and
+// the gameplay systems.
+package character
+
+import "math"
+
+// Direction is a direction of the world, or of a sprite, clockwise when
+// seen from above with the north up.
+type Direction uint8
+
+const (
+	South Direction = iota
+	SouthWest
+	West
+	NorthWest
+	North
+	NorthEast
+	East
+	SouthEast
+)
+
+// DirectionCount is the number of directions of the sprites.
+const DirectionCount = 8
+
+// DefaultCameraDirection is the camera direction of the official client,
+// south of the character and looking north, as used by
+// ResolverForCameraDirection.
+const DefaultCameraDirection = 6
+
+// DirectionResolver resolves the sprite direction of the characters seen
+// from a camera orbiting around them.
+type DirectionResolver struct {
+	// Yaw is the rotation of the camera around the character, in degrees.
+	// At 0 the camera is south of the character, as in the official
+	// client; it orbits clockwise, seen from above, as Yaw increases (at
+	// 90 it is west of the character, looking east).
+	Yaw float64
+}
+
+// ResolverForCameraDirection returns the resolver of a camera direction of
+// the official client, from 0 to 7, DefaultCameraDirection being the
+// camera south of the character.
+func ResolverForCameraDirection(cameraDirection int) DirectionResolver {
+	return DirectionResolver{Yaw: float64(cameraDirection-DefaultCameraDirection) * 45}
+}
+
+// CameraStep returns the direction the camera is in, from the character,
+// rounded to the closest of the 8 directions.
+func (r DirectionResolver) CameraStep() Direction {
+	step := int(math.Round(r.Yaw/45)) % DirectionCount
+	if step < 0 {
+		step += DirectionCount
+	}
+
+	return Direction(step)
+}
+
+// CameraDirection returns the camera direction of the official client
+// matching Yaw.
+func (r DirectionResolver) CameraDirection() int {
+	return (DefaultCameraDirection + int(r.CameraStep())) % DirectionCount
+}
+
+// Resolve returns the sprite direction of a character facing facing.
+func (r DirectionResolver) Resolve(facing Direction) Direction {
+	return Direction((int(facing) + DirectionCount - int(r.CameraStep())) % DirectionCount)
+}
+
+// Facing returns the direction a character faces when it shows the sprite
+// direction d, the reverse of Resolve. It is also the direction of the
+// world toward d on the screen, e.g. West being left.
+func (r DirectionResolver) Facing(d Direction) Direction {
+	return Direction((int(d) + int(r.CameraStep())) % DirectionCount)
+}
+
+// Behind tells whether the camera sees the back of a character facing
+// facing, the sprite facing west to east through the north.
+func (r DirectionResolver) Behind(facing Direction) bool {
+	d := r.Resolve(facing)
+	return d > SouthWest && d < East
+}
+
+// ActionIndex returns the index, among actionCount actions, of the action
+// of a character facing facing, action being the first action index of its
+// 8 directions.
+func (r DirectionResolver) ActionIndex(action int, facing Direction, actionCount int) int {
+	if actionCount <= 0 {
+		return 0
+	}
+
+	return (action + int(r.Resolve(facing))) % actionCount
+}
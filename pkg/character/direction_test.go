@@ -0,0 +1,84 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// directionTable is the offset of the sprite directions for each camera
+// direction of the official client.
+var directionTable = [8]int{6, 5, 4, 3, 2, 1, 0, 7}
+
+func TestResolverForCameraDirection(t *testing.T) {
+	for camera := 0; camera < DirectionCount; camera++ {
+		r := ResolverForCameraDirection(camera)
+		assert.Equal(t, camera, r.CameraDirection())
+
+		for facing := South; facing <= SouthEast; facing++ {
+			want := Direction((int(facing) + directionTable[camera]) % DirectionCount)
+			assert.Equal(t, want, r.Resolve(facing), "camera %d facing %d", camera, facing)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	var r DirectionResolver
+
+	// The default camera sees the characters as they face.
+	for facing := South; facing <= SouthEast; facing++ {
+		assert.Equal(t, facing, r.Resolve(facing))
+	}
+
+	// West of the character, the camera sees its back when it faces east.
+	r.Yaw = 90
+	assert.Equal(t, North, r.Resolve(East))
+	assert.Equal(t, South, r.Resolve(West))
+
+	// North of it, the front and back are swapped.
+	r.Yaw = 180
+	assert.Equal(t, North, r.Resolve(South))
+	assert.Equal(t, East, r.Resolve(West))
+
+	// The yaw is rounded to the closest direction, and wraps.
+	r.Yaw = -20
+	assert.Equal(t, South, r.Resolve(South))
+	r.Yaw = -30
+	assert.Equal(t, SouthWest, r.Resolve(South))
+	r.Yaw = 720 + 90
+	assert.Equal(t, North, r.Resolve(East))
+}
+
+func TestFacing(t *testing.T) {
+	for _, yaw := range []float64{0, 45, 90, 180, 270, -45} {
+		r := DirectionResolver{Yaw: yaw}
+		for d := South; d <= SouthEast; d++ {
+			assert.Equal(t, d, r.Resolve(r.Facing(d)), "yaw %v direction %d", yaw, d)
+		}
+	}
+
+	// West of the character, the top of the screen is the east.
+	assert.Equal(t, East, DirectionResolver{Yaw: 90}.Facing(North))
+}
+
+func TestBehind(t *testing.T) {
+	var r DirectionResolver
+	assert.False(t, r.Behind(South))
+	assert.False(t, r.Behind(SouthWest))
+	assert.True(t, r.Behind(West))
+	assert.True(t, r.Behind(NorthEast))
+	assert.False(t, r.Behind(East))
+
+	r.Yaw = 180
+	assert.True(t, r.Behind(South))
+	assert.False(t, r.Behind(North))
+}
+
+func TestActionIndex(t *testing.T) {
+	r := DirectionResolver{Yaw: 90}
+
+	// The 8 directions of the second action.
+	assert.Equal(t, 8+int(North), r.ActionIndex(8, East, 104))
+	assert.Equal(t, 0, r.ActionIndex(8, East, 0))
+	assert.Equal(t, 4, r.ActionIndex(8, East, 8))
+}
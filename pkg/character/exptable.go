@@ -0,0 +1,65 @@
+package character
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExpTable is the experience to reach each level from the one before it:
+// the first one is the experience from level 1 to 2.
+type ExpTable []uint64
+
+// ParseExpTable parses a table of r in the exp.txt format of the servers:
+// the experience of the levels in order, separated by commas or on lines
+// of their own, the text after // being comments.
+func ParseExpTable(r io.Reader) (ExpTable, error) {
+	var table ExpTable
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "//"); i >= 0 {
+			text = text[:i]
+		}
+
+		for _, field := range strings.Split(text, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+
+			exp, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid experience on line %d", line)
+			}
+			table = append(table, exp)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read experience table")
+	}
+	if len(table) == 0 {
+		return nil, errors.New("empty experience table")
+	}
+
+	return table, nil
+}
+
+// MaxLevel returns the last level of t.
+func (t ExpTable) MaxLevel() int {
+	return len(t) + 1
+}
+
+// Next returns the experience to reach the level after level, false at the
+// last level.
+func (t ExpTable) Next(level int) (uint64, bool) {
+	if level < 1 || level >= t.MaxLevel() {
+		return 0, false
+	}
+
+	return t[level-1], true
+}
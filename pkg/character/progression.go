@@ -0,0 +1,231 @@
+package character
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Stat is a stat of the characters.
+type Stat int
+
+const (
+	Str Stat = iota
+	Agi
+	Vit
+	Int
+	Dex
+	Luk
+
+	// StatCount is the number of stats.
+	StatCount = 6
+)
+
+func (s Stat) String() string {
+	switch s {
+	case Str:
+		return "STR"
+	case Agi:
+		return "AGI"
+	case Vit:
+		return "VIT"
+	case Int:
+		return "INT"
+	case Dex:
+		return "DEX"
+	case Luk:
+		return "LUK"
+	default:
+		return ""
+	}
+}
+
+const (
+	// MaxStat is the highest value of the stats.
+	MaxStat = 99
+	// MaxASPD is the highest attack speed, the max_aspd of the servers.
+	MaxASPD = 190
+	// DefaultWalkSpeed is the time to walk a cell at the normal speed.
+	DefaultWalkSpeed = 150 * time.Millisecond
+)
+
+// Stats are the values of the stats, by Stat.
+type Stats [StatCount]int
+
+// Class is what the progression of the characters of a job depends on, as
+// in the pre-renewal job_db of the servers.
+type Class struct {
+	Name string
+	// HPFactor and HPMultiplier grow the base HP with the base level, and
+	// SPFactor the base SP.
+	HPFactor, HPMultiplier, SPFactor int
+	// AttackMotion is the duration of the attacks bare-handed, at 0 AGI and
+	// DEX, in tenths of milliseconds.
+	AttackMotion int
+}
+
+// Classes are the classes of the first and second jobs, by name.
+var Classes = map[string]Class{
+	"Novice":     {Name: "Novice", HPFactor: 0, HPMultiplier: 500, SPFactor: 100, AttackMotion: 500},
+	"Swordsman":  {Name: "Swordsman", HPFactor: 70, HPMultiplier: 500, SPFactor: 200, AttackMotion: 400},
+	"Mage":       {Name: "Mage", HPFactor: 30, HPMultiplier: 500, SPFactor: 600, AttackMotion: 500},
+	"Archer":     {Name: "Archer", HPFactor: 50, HPMultiplier: 500, SPFactor: 200, AttackMotion: 400},
+	"Acolyte":    {Name: "Acolyte", HPFactor: 40, HPMultiplier: 500, SPFactor: 500, AttackMotion: 400},
+	"Merchant":   {Name: "Merchant", HPFactor: 40, HPMultiplier: 500, SPFactor: 300, AttackMotion: 400},
+	"Thief":      {Name: "Thief", HPFactor: 50, HPMultiplier: 500, SPFactor: 200, AttackMotion: 400},
+	"Knight":     {Name: "Knight", HPFactor: 150, HPMultiplier: 500, SPFactor: 300, AttackMotion: 400},
+	"Priest":     {Name: "Priest", HPFactor: 75, HPMultiplier: 500, SPFactor: 800, AttackMotion: 400},
+	"Wizard":     {Name: "Wizard", HPFactor: 55, HPMultiplier: 500, SPFactor: 900, AttackMotion: 500},
+	"Blacksmith": {Name: "Blacksmith", HPFactor: 90, HPMultiplier: 500, SPFactor: 400, AttackMotion: 400},
+	"Hunter":     {Name: "Hunter", HPFactor: 85, HPMultiplier: 500, SPFactor: 400, AttackMotion: 400},
+	"Assassin":   {Name: "Assassin", HPFactor: 110, HPMultiplier: 500, SPFactor: 400, AttackMotion: 400},
+	"Crusader":   {Name: "Crusader", HPFactor: 110, HPMultiplier: 700, SPFactor: 400, AttackMotion: 400},
+	"Monk":       {Name: "Monk", HPFactor: 90, HPMultiplier: 650, SPFactor: 470, AttackMotion: 400},
+	"Sage":       {Name: "Sage", HPFactor: 75, HPMultiplier: 500, SPFactor: 700, AttackMotion: 400},
+	"Rogue":      {Name: "Rogue", HPFactor: 85, HPMultiplier: 650, SPFactor: 500, AttackMotion: 400},
+	"Alchemist":  {Name: "Alchemist", HPFactor: 90, HPMultiplier: 500, SPFactor: 400, AttackMotion: 400},
+}
+
+// Progression is the levels, the experience and the stats of a character.
+type Progression struct {
+	Class     Class
+	BaseLevel int
+	JobLevel  int
+	// BaseExp and JobExp are the experience gained toward the next levels.
+	BaseExp uint64
+	JobExp  uint64
+	Stats   Stats
+	// StatPoints are left to raise the stats, SkillPoints to learn skills.
+	StatPoints  int
+	SkillPoints int
+	// Speed is the walk speed, times the normal one (e.g. 1.25 with Agi Up),
+	// 0 for 1.
+	Speed float64
+}
+
+// Attributes are the attributes derived from a progression.
+type Attributes struct {
+	MaxHP int
+	MaxSP int
+	// ASPD is the attack speed bare-handed, up to MaxASPD.
+	ASPD float64
+	// WalkSpeed is the time to walk a cell.
+	WalkSpeed time.Duration
+}
+
+// NewProgression returns the progression of a new character of class, at
+// the first levels with every stat at 1.
+func NewProgression(class Class) *Progression {
+	p := &Progression{Class: class, BaseLevel: 1, JobLevel: 1}
+	for s := range p.Stats {
+		p.Stats[s] = 1
+	}
+
+	return p
+}
+
+// GainBaseExp adds exp to the base experience, leveling up as long as it
+// reaches the next level of table. Each level gives the stat points of
+// StatPointsAt. It returns the levels gained.
+func (p *Progression) GainBaseExp(exp uint64, table ExpTable) int {
+	levels := gain(&p.BaseLevel, &p.BaseExp, exp, table)
+	for level := p.BaseLevel - levels + 1; level <= p.BaseLevel; level++ {
+		p.StatPoints += StatPointsAt(level)
+	}
+
+	return levels
+}
+
+// GainJobExp adds exp to the job experience, leveling up as long as it
+// reaches the next level of table. Each level gives a skill point. It
+// returns the levels gained.
+func (p *Progression) GainJobExp(exp uint64, table ExpTable) int {
+	levels := gain(&p.JobLevel, &p.JobExp, exp, table)
+	p.SkillPoints += levels
+
+	return levels
+}
+
+// gain adds exp to the experience of level, leveling up on table. The
+// experience past the last level is dropped.
+func gain(level *int, current *uint64, exp uint64, table ExpTable) int {
+	levels := 0
+	*current += exp
+	for {
+		next, ok := table.Next(*level)
+		if !ok {
+			*current = 0
+			return levels
+		}
+		if *current < next {
+			return levels
+		}
+
+		*current -= next
+		*level++
+		levels++
+	}
+}
+
+// StatPointsAt returns the stat points given when reaching the base level.
+func StatPointsAt(level int) int {
+	return level/5 + 3
+}
+
+// StatCost returns the stat points raising a stat from value costs.
+func StatCost(value int) int {
+	return (value-1)/10 + 2
+}
+
+// Raise raises the stat s by a point, for its StatCost.
+func (p *Progression) Raise(s Stat) error {
+	if s < 0 || s >= StatCount {
+		return errors.Errorf("unknown stat %d", s)
+	}
+
+	value := p.Stats[s]
+	if value >= MaxStat {
+		return errors.Errorf("%s is already %d", s, MaxStat)
+	}
+	cost := StatCost(value)
+	if cost > p.StatPoints {
+		return errors.Errorf("raising %s costs %d stat points, %d left", s, cost, p.StatPoints)
+	}
+
+	p.StatPoints -= cost
+	p.Stats[s]++
+	return nil
+}
+
+// Attributes returns the attributes derived from the class, the base level
+// and the stats of p, as the pre-renewal servers do, without equipment.
+func (p *Progression) Attributes() Attributes {
+	class := p.Class
+
+	baseHP := 35 + float64(p.BaseLevel*class.HPMultiplier)/100
+	for level := 2; level <= p.BaseLevel; level++ {
+		baseHP += math.Round(float64(level*class.HPFactor) / 100)
+	}
+	baseSP := 10 + float64(p.BaseLevel*class.SPFactor)/100
+
+	// amotion -= amotion * (4 * AGI + DEX) / 1000.
+	motion := float64(class.AttackMotion)
+	motion -= motion * float64(4*p.Stats[Agi]+p.Stats[Dex]) / 1000
+	aspd := 200 - motion/10
+	if aspd > MaxASPD {
+		aspd = MaxASPD
+	}
+
+	walkSpeed := DefaultWalkSpeed
+	if p.Speed > 0 {
+		walkSpeed = time.Duration(float64(DefaultWalkSpeed) / p.Speed)
+	}
+
+	return Attributes{
+		MaxHP:     int(baseHP) * (100 + p.Stats[Vit]) / 100,
+		MaxSP:     int(baseSP) * (100 + p.Stats[Int]) / 100,
+		ASPD:      aspd,
+		WalkSpeed: walkSpeed,
+	}
+}
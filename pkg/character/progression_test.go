@@ -0,0 +1,87 @@
+package character
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpTable(t *testing.T) {
+	table, err := ParseExpTable(strings.NewReader("// Base experience\n9,16,25\n36 // level 5\n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, ExpTable{9, 16, 25, 36}, table)
+	assert.Equal(t, 5, table.MaxLevel())
+
+	next, ok := table.Next(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(9), next)
+	_, ok = table.Next(5)
+	assert.False(t, ok)
+
+	_, err = ParseExpTable(strings.NewReader("9,sixteen"))
+	assert.Error(t, err)
+	_, err = ParseExpTable(strings.NewReader("// nothing"))
+	assert.Error(t, err)
+}
+
+func TestProgressionGainExp(t *testing.T) {
+	table := ExpTable{10, 20, 30}
+	p := NewProgression(Classes["Novice"])
+
+	assert.Equal(t, 0, p.GainBaseExp(5, table))
+	assert.Equal(t, 2, p.GainBaseExp(30, table))
+	assert.Equal(t, 3, p.BaseLevel)
+	assert.Equal(t, uint64(5), p.BaseExp)
+	assert.Equal(t, StatPointsAt(2)+StatPointsAt(3), p.StatPoints)
+
+	// The experience past the last level is dropped.
+	assert.Equal(t, 1, p.GainBaseExp(100, table))
+	assert.Equal(t, 4, p.BaseLevel)
+	assert.Equal(t, uint64(0), p.BaseExp)
+
+	assert.Equal(t, 1, p.GainJobExp(15, table))
+	assert.Equal(t, 2, p.JobLevel)
+	assert.Equal(t, 1, p.SkillPoints)
+}
+
+func TestProgressionRaise(t *testing.T) {
+	p := NewProgression(Classes["Novice"])
+	p.StatPoints = 3
+
+	require.NoError(t, p.Raise(Agi))
+	assert.Equal(t, 2, p.Stats[Agi])
+	assert.Equal(t, 1, p.StatPoints)
+	assert.Error(t, p.Raise(Agi), "not enough stat points")
+
+	p.Stats[Str], p.StatPoints = MaxStat, 100
+	assert.Error(t, p.Raise(Str))
+	assert.Error(t, p.Raise(StatCount))
+
+	assert.Equal(t, 2, StatCost(1))
+	assert.Equal(t, 3, StatCost(11))
+	assert.Equal(t, 11, StatCost(91))
+}
+
+func TestProgressionAttributes(t *testing.T) {
+	// A new novice.
+	a := NewProgression(Classes["Novice"]).Attributes()
+	assert.Equal(t, 40, a.MaxHP)
+	assert.Equal(t, 11, a.MaxSP)
+	assert.InDelta(t, 150.25, a.ASPD, 1e-9)
+	assert.Equal(t, DefaultWalkSpeed, a.WalkSpeed)
+
+	p := NewProgression(Classes["Knight"])
+	p.BaseLevel = 99
+	p.Stats[Vit], p.Stats[Agi], p.Stats[Dex] = 80, 99, 99
+	p.Speed = 1.25
+	a = p.Attributes()
+	assert.Greater(t, a.MaxHP, 10000)
+	assert.InDelta(t, 179.8, a.ASPD, 1e-9)
+	assert.Equal(t, 120*time.Millisecond, a.WalkSpeed)
+
+	p.Class.AttackMotion = 150
+	assert.Equal(t, float64(MaxASPD), p.Attributes().ASPD)
+}
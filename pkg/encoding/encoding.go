@@ -0,0 +1,127 @@
+// Package encoding converts the strings found in the client data and the
+// protocol, which are CP949 (the Korean EUC-KR superset) byte strings.
+//
+// File names are kept in their "raw" form across the code base: the CP949
+// bytes read as Windows-1252, e.g. "ÀÎ°£Á·" for 인간족, so that they are
+// valid UTF-8 Go strings mapping 1:1 to the original bytes. ToUTF8 and
+// FromUTF8 convert between that form and readable Korean.
+package encoding
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/korean"
+)
+
+// DecodeRaw returns the raw form of a file name read from the data.
+func DecodeRaw(b []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for _, c := range b {
+		sb.WriteRune(decodeRawByte(c))
+	}
+
+	return sb.String()
+}
+
+// EncodeRaw returns the bytes of a raw file name, as stored in the data.
+func EncodeRaw(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+	for i, r := range s {
+		c, ok := encodeRawRune(r)
+		if !ok {
+			return nil, fmt.Errorf("rune %U at %d is not a raw byte", r, i)
+		}
+		b = append(b, c)
+	}
+
+	return b, nil
+}
+
+// decodeRawByte maps a byte as Windows-1252 does, but for the five bytes
+// it leaves undefined (0x81, 0x8D, 0x8F, 0x90 and 0x9D), which CP949
+// uses as trail bytes: they map to the C1 control of the same value, as
+// in the WHATWG windows-1252, so that every byte round trips.
+func decodeRawByte(c byte) rune {
+	if r := charmap.Windows1252.DecodeByte(c); r != utf8.RuneError {
+		return r
+	}
+
+	return rune(c)
+}
+
+// encodeRawRune is the reverse of decodeRawByte.
+func encodeRawRune(r rune) (byte, bool) {
+	if c, ok := charmap.Windows1252.EncodeRune(r); ok {
+		return c, true
+	}
+
+	if r >= 0x80 && r <= 0x9F && charmap.Windows1252.DecodeByte(byte(r)) == utf8.RuneError {
+		return byte(r), true
+	}
+
+	return 0, false
+}
+
+// DecodeCP949 decodes a CP949 byte string, as sent by the servers.
+func DecodeCP949(b []byte) (string, error) {
+	s, err := korean.EUCKR.NewDecoder().Bytes(b)
+	return string(s), err
+}
+
+// EncodeCP949 encodes a string to CP949.
+func EncodeCP949(s string) ([]byte, error) {
+	return korean.EUCKR.NewEncoder().Bytes([]byte(s))
+}
+
+// ToUTF8 converts a raw file name to readable UTF-8.
+func ToUTF8(raw string) (string, error) {
+	b, err := EncodeRaw(raw)
+	if err != nil {
+		return "", err
+	}
+
+	return DecodeCP949(b)
+}
+
+// FromUTF8 converts a UTF-8 file name to its raw form.
+func FromUTF8(s string) (string, error) {
+	b, err := EncodeCP949(s)
+	if err != nil {
+		return "", err
+	}
+
+	return DecodeRaw(b), nil
+}
+
+// MustFromUTF8 is like FromUTF8 but panics on error. It eases declaring
+// path constants in their readable form.
+func MustFromUTF8(s string) string {
+	raw, err := FromUTF8(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return raw
+}
+
+// Display returns a raw name in a form safe to print or log, readable
+// Korean when it converts, with control characters escaped.
+func Display(raw string) string {
+	s, err := ToUTF8(raw)
+	if err != nil || !utf8.ValidString(s) {
+		s = raw
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (unicode.IsControl(r) && r != '\t') {
+			return '?'
+		}
+
+		return r
+	}, s)
+}
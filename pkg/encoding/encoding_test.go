@@ -0,0 +1,67 @@
+package encoding
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawConversion(t *testing.T) {
+	tests := []struct {
+		raw, utf8 string
+	}{
+		{"ÀÎ°£Á·", "인간족"},
+		{"¸öÅë", "몸통"},
+		{"¸Ó¸®Åë", "머리통"},
+		{"³²", "남"},
+		{"¿©", "여"},
+		{"data/sprite/shadow", "data/sprite/shadow"},
+	}
+
+	for _, tt := range tests {
+		s, err := ToUTF8(tt.raw)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.utf8, s)
+
+		raw, err := FromUTF8(tt.utf8)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.raw, raw)
+	}
+}
+
+func TestRawRoundTrip(t *testing.T) {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+
+	raw := DecodeRaw(b)
+	assert.Equal(t, 256, utf8.RuneCountInString(raw))
+	assert.NotContains(t, raw, string(utf8.RuneError))
+
+	encoded, err := EncodeRaw(raw)
+	require.NoError(t, err)
+	assert.Equal(t, b, encoded)
+
+	// CP949 uses the bytes Windows-1252 leaves undefined, e.g. 갂 is
+	// 0x81 0x41.
+	raw, err = FromUTF8("갂")
+	require.NoError(t, err)
+	s, err := ToUTF8(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "갂", s)
+
+	_, err = EncodeRaw("인")
+	assert.Error(t, err)
+}
+
+func TestTransliterate(t *testing.T) {
+	raw := MustFromUTF8("data/sprite/인간족/몸통/남/초보자_남.spr")
+	assert.Equal(t, "data/sprite/human/body/male/novice_male.spr", Transliterate(raw))
+}
+
+func TestDisplay(t *testing.T) {
+	assert.Equal(t, "인간족?", Display("ÀÎ°£Á·\x00"))
+}
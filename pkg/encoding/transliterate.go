@@ -0,0 +1,73 @@
+package encoding
+
+import (
+	"strings"
+)
+
+// Segments maps the Korean path segments of the client data to an
+// English transliteration.
+var Segments = map[string]string{
+	"인간족":     "human",
+	"몸통":      "body",
+	"머리통":     "head",
+	"머리":      "hair",
+	"몸":       "body",
+	"남":       "male",
+	"여":       "female",
+	"몬스터":     "monster",
+	"악세사리":    "accessory",
+	"방패":      "shield",
+	"팔레트":     "palette",
+	"유저인터페이스": "ui",
+	"이팩트":     "effect",
+	"초보자":     "novice",
+	"검사":      "swordsman",
+	"마법사":     "magician",
+	"궁수":      "archer",
+	"성직자":     "acolyte",
+	"상인":      "merchant",
+	"도둑":      "thief",
+	"기사":      "knight",
+	"프리스트":    "priest",
+	"위저드":     "wizard",
+	"제철공":     "blacksmith",
+	"헌터":      "hunter",
+	"어세신":     "assassin",
+	"크루세이더":   "crusader",
+	"몽크":      "monk",
+	"세이지":     "sage",
+	"로그":      "rogue",
+	"연금술사":    "alchemist",
+	"바드":      "bard",
+	"무희":      "dancer",
+}
+
+// Transliterate converts a raw path to UTF-8 and replaces the known
+// Korean segments, and the "_" separated parts of the segments, with
+// their transliteration (data/sprite/인간족/몸통/남/초보자_남.spr becomes
+// data/sprite/human/body/male/novice_male.spr).
+func Transliterate(raw string) string {
+	s, err := ToUTF8(raw)
+	if err != nil {
+		s = raw
+	}
+
+	segments := strings.Split(s, "/")
+	for i, segment := range segments {
+		ext := ""
+		if dot := strings.LastIndexByte(segment, '.'); dot > 0 {
+			segment, ext = segment[:dot], segment[dot:]
+		}
+
+		parts := strings.Split(segment, "_")
+		for j, part := range parts {
+			if t, ok := Segments[part]; ok {
+				parts[j] = t
+			}
+		}
+
+		segments[i] = strings.Join(parts, "_") + ext
+	}
+
+	return strings.Join(segments, "/")
+}
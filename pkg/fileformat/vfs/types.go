@@ -0,0 +1,14 @@
+package vfs
+
+import "github.com/project-midgard/midgarts/internal/fileformat/grf"
+
+// The types of the filesystem, which live in an internal package: code
+// built outside of this module cannot import it, and names them here.
+type (
+	// Entry is a file read, with its data.
+	Entry = grf.Entry
+	// ActionSpriteFilePair is the .act and .spr files of a sprite.
+	ActionSpriteFilePair = grf.ActionSpriteFilePair
+	// Archives are GRF archives read as one, under a data folder.
+	Archives = grf.MultiFile
+)
@@ -0,0 +1,47 @@
+// Package vfs is the filesystem the client reads its assets from: the
+// entries of the GRF archives, shadowed by the files of a loose data
+// folder on disk, as the official client resolves them. Modders drop
+// replacement .spr and .act files in the data folder rather than repacking
+// the archives.
+//
+// The character sprites and their attachments are loaded through it (see
+// asset.Manager), and reloaded from the data folder as they are edited
+// (see system.AssetReloadSystem).
+package vfs
+
+import (
+	"context"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+// FS reads the files of the data folder, else of the archives. The names
+// are the paths of the entries, as data/sprite/npc/poring.spr; the Korean
+// ones in UTF-8 or in their raw form (see encoding.DecodeRaw).
+type FS interface {
+	// GetEntryContext returns the file name.
+	GetEntryContext(ctx context.Context, name string) (*Entry, error)
+	// GetSpriteFilesContext returns the .act and .spr files of the sprite
+	// name, without extension.
+	GetSpriteFilesContext(ctx context.Context, name string) (ActionSpriteFilePair, error)
+	// OverlayPaths returns the paths on disk the file name is read from,
+	// when present, none without data folder.
+	OverlayPaths(name string) []string
+}
+
+var (
+	_ FS = (*grf.File)(nil)
+	_ FS = (*Archives)(nil)
+)
+
+// Open returns the filesystem of the archives of paths (see grf.LoadAll)
+// under the data folder dataDir, the folder holding data/, "" for none.
+func Open(ctx context.Context, dataDir string, paths ...string) (*Archives, error) {
+	archives, err := grf.LoadAllContext(ctx, paths...)
+	if err != nil {
+		return nil, err
+	}
+	archives.SetDataDir(dataDir)
+
+	return archives, nil
+}
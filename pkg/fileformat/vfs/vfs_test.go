@@ -0,0 +1,49 @@
+package vfs
+
+import (
+	"compress/zlib"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/fileformat/grf"
+)
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.grf")
+	out, err := os.Create(path)
+	require.NoError(t, err)
+	w, err := grf.NewWriter(out, zlib.DefaultCompression)
+	require.NoError(t, err)
+	require.NoError(t, w.Write("data/prontera.gat", []byte("prontera")))
+	require.NoError(t, w.Write("data/izlude.gat", []byte("izlude")))
+	require.NoError(t, w.Close())
+	require.NoError(t, out.Close())
+
+	dataDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "data"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dataDir, "data", "prontera.gat"), []byte("edited"), 0644))
+
+	archives, err := Open(context.Background(), dataDir, path)
+	require.NoError(t, err)
+	defer archives.Close()
+	var fs FS = archives
+
+	// The data folder shadows the archives.
+	e, err := fs.GetEntryContext(context.Background(), "data/prontera.gat")
+	require.NoError(t, err)
+	assert.Equal(t, "edited", string(e.Data))
+	e, err = fs.GetEntryContext(context.Background(), "data/izlude.gat")
+	require.NoError(t, err)
+	assert.Equal(t, "izlude", string(e.Data))
+	assert.Contains(t, fs.OverlayPaths("data/prontera.gat"), filepath.Join(dataDir, "data", "prontera.gat"))
+
+	_, err = Open(context.Background(), dataDir, filepath.Join(dir, "missing.grf"))
+	assert.Error(t, err)
+}
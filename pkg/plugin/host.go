@@ -0,0 +1,127 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/pkg/errors"
+
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// Host dispatches the client events to the plugins. It is a system: added
+// to the world, it calls the FrameHandler plugins every update and the
+// SpawnHandler plugins for every character added.
+type Host struct {
+	plugins []Plugin
+}
+
+// NewHost initializes the plugins and returns their host.
+func NewHost(ctx context.Context, plugins ...Plugin) (*Host, error) {
+	for _, p := range plugins {
+		if i, ok := p.(Initializer); ok {
+			if err := i.Init(ctx); err != nil {
+				return nil, errors.Wrapf(err, "could not initialize plugin '%s'", p.Name())
+			}
+		}
+	}
+
+	return &Host{plugins: plugins}, nil
+}
+
+func (h *Host) Plugins() []Plugin {
+	return h.plugins
+}
+
+// AddSystems adds the systems of the SystemProvider plugins to w.
+func (h *Host) AddSystems(w *ecs.World) {
+	for _, p := range h.plugins {
+		if sp, ok := p.(SystemProvider); ok {
+			for _, sys := range sp.Systems() {
+				w.AddSystem(sys)
+			}
+		}
+	}
+}
+
+// AddShaders sets the shaders of the ShaderProvider plugins on r, and
+// appends the passes of the PostPassProvider plugins to the ones of r, in
+// the order of the plugins.
+func (h *Host) AddShaders(r *opengl.RenderSystem) error {
+	for _, p := range h.plugins {
+		if sp, ok := p.(ShaderProvider); ok {
+			for shader, source := range sp.Shaders() {
+				if err := r.SetShader(shader, source); err != nil {
+					return errors.Wrapf(err, "invalid shader of plugin '%s'", p.Name())
+				}
+			}
+		}
+		if pp, ok := p.(PostPassProvider); ok {
+			for _, pass := range pp.PostPasses() {
+				r.AddPostPass(pass)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Windows returns the windows of the WindowProvider plugins, in the order
+// of the plugins.
+func (h *Host) Windows() (windows []Window) {
+	for _, p := range h.plugins {
+		if wp, ok := p.(WindowProvider); ok {
+			windows = append(windows, wp.Windows()...)
+		}
+	}
+
+	return windows
+}
+
+// Subscribe hands the event.PacketReceived of bus to the PacketHandler
+// plugins, and bus to the EventSubscriber plugins.
+func (h *Host) Subscribe(bus *event.Bus) (unsubscribe func()) {
+	for _, p := range h.plugins {
+		if es, ok := p.(EventSubscriber); ok {
+			es.SubscribeEvents(bus)
+		}
+	}
+
+	return bus.Subscribe(func(e event.PacketReceived) {
+		h.Packet(e.Packet)
+	})
+}
+
+// Packet hands a server packet to the PacketHandler plugins.
+func (h *Host) Packet(pkt packet.Packet) {
+	for _, p := range h.plugins {
+		if ph, ok := p.(PacketHandler); ok {
+			ph.OnPacket(pkt)
+		}
+	}
+}
+
+func (h *Host) AddByInterface(o ecs.Identifier) {
+	h.Add(o.(*entity.Character))
+}
+
+func (h *Host) Add(char *entity.Character) {
+	for _, p := range h.plugins {
+		if sh, ok := p.(SpawnHandler); ok {
+			sh.OnEntitySpawn(char)
+		}
+	}
+}
+
+func (h *Host) Update(dt float32) {
+	for _, p := range h.plugins {
+		if fh, ok := p.(FrameHandler); ok {
+			fh.OnFrame(dt)
+		}
+	}
+}
+
+func (h *Host) Remove(e ecs.BasicEntity) {}
@@ -0,0 +1,137 @@
+// Package plugin is the extension API of the client. A plugin implements
+// the hooks it needs among the handler interfaces of this package. It is
+// either registered at build time, from the init function of a package
+// blank imported by the client, or loaded at run time from a Go plugin.
+// The package is public, and names the types of the hooks (see types.go),
+// so plugins can live in their own modules.
+package plugin
+
+import (
+	"context"
+	"fmt"
+	goplugin "plugin"
+	"sort"
+	"sync"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/pkg/errors"
+)
+
+// NewSymbol is the symbol a Go plugin exports to be loaded by Open: a
+// func() Plugin returning the plugin.
+const NewSymbol = "New"
+
+// Plugin is a client extension.
+type Plugin interface {
+	Name() string
+}
+
+// Initializer is implemented by plugins preparing themselves once loaded.
+// ctx is done when the client stops.
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// PacketHandler is implemented by plugins receiving the server packets.
+type PacketHandler interface {
+	OnPacket(p Packet)
+}
+
+// SpawnHandler is implemented by plugins notified of the characters added
+// to the world.
+type SpawnHandler interface {
+	OnEntitySpawn(char *Character)
+}
+
+// FrameHandler is implemented by plugins called every frame, on the main
+// thread.
+type FrameHandler interface {
+	OnFrame(dt float32)
+}
+
+// EventSubscriber is implemented by plugins subscribing to the events of
+// the client.
+type EventSubscriber interface {
+	SubscribeEvents(bus *EventBus)
+}
+
+// SystemProvider is implemented by plugins adding their own systems to the
+// world.
+type SystemProvider interface {
+	Systems() []ecs.System
+}
+
+// ShaderProvider is implemented by plugins replacing shaders of the render
+// system (see opengl.RenderSystem.SetShader).
+type ShaderProvider interface {
+	Shaders() map[Shader]ShaderSource
+}
+
+// PostPassProvider is implemented by plugins post-processing the frame,
+// e.g. with a bloom or a color grading (see opengl.PostPass).
+type PostPassProvider interface {
+	PostPasses() []*PostPass
+}
+
+// WindowProvider is implemented by plugins drawing their own windows over
+// the frame, e.g. a DPS meter or a minimap. The windows receive no input:
+// the plugins subscribe to the MouseClicked and KeyChanged events.
+type WindowProvider interface {
+	Windows() []Window
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Plugin{}
+)
+
+// Register makes a plugin available to the client. It panics when a
+// plugin of the same name is already registered.
+func Register(p Plugin) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[p.Name()]; ok {
+		panic(fmt.Sprintf("plugin: '%s' registered twice", p.Name()))
+	}
+
+	registry[p.Name()] = p
+}
+
+// Registered returns the registered plugins, sorted by name.
+func Registered() []Plugin {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	plugins := make([]Plugin, 0, len(registry))
+	for _, p := range registry {
+		plugins = append(plugins, p)
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name() < plugins[j].Name()
+	})
+
+	return plugins
+}
+
+// Open loads a plugin from a Go plugin file (go build -buildmode=plugin)
+// exporting NewSymbol.
+func Open(path string) (Plugin, error) {
+	gp, err := goplugin.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open plugin '%s'", path)
+	}
+
+	sym, err := gp.Lookup(NewSymbol)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid plugin '%s'", path)
+	}
+
+	newPlugin, ok := sym.(func() Plugin)
+	if !ok {
+		return nil, errors.Errorf("invalid plugin '%s': %s is a %T, not a func() plugin.Plugin", path, NewSymbol, sym)
+	}
+
+	return newPlugin(), nil
+}
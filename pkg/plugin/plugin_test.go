@@ -0,0 +1,132 @@
+package plugin
+
+import (
+	"context"
+	"image/draw"
+	"testing"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-midgard/midgarts/internal/character"
+	"github.com/project-midgard/midgarts/internal/character/jobspriteid"
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// recorder implements every hook and records the calls.
+type recorder struct {
+	name    string
+	initErr error
+	calls   []string
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Init(ctx context.Context) error {
+	r.calls = append(r.calls, "init")
+	return r.initErr
+}
+
+func (r *recorder) OnPacket(p packet.Packet) {
+	r.calls = append(r.calls, "packet")
+}
+
+func (r *recorder) OnEntitySpawn(char *entity.Character) {
+	r.calls = append(r.calls, "spawn")
+}
+
+func (r *recorder) OnFrame(dt float32) {
+	r.calls = append(r.calls, "frame")
+}
+
+// nameOnly implements no hook.
+type nameOnly string
+
+func (n nameOnly) Name() string { return string(n) }
+
+func TestHost(t *testing.T) {
+	r := &recorder{name: "recorder"}
+
+	h, err := NewHost(context.Background(), r, nameOnly("idle"))
+	require.NoError(t, err)
+
+	w := ecs.World{}
+	var spawnable *ecs.Identifier
+	w.AddSystemInterface(h, spawnable, nil)
+	w.AddEntity(entity.NewCharacter(character.Male, jobspriteid.Novice, 1))
+	w.Update(0.016)
+
+	bus := event.NewBus()
+	h.Subscribe(bus)
+	bus.Publish(event.PacketReceived{Packet: &packet.CA_LOGIN{}})
+
+	assert.Equal(t, []string{"init", "spawn", "frame", "packet"}, r.calls)
+}
+
+// shaders replaces a shader and post-processes the frame.
+type shaders struct{}
+
+func (shaders) Name() string { return "shaders" }
+
+func (shaders) Shaders() map[opengl.Shader]opengl.ShaderSource {
+	return map[opengl.Shader]opengl.ShaderSource{opengl.SpriteShader: {Fragment: "#version 330 core"}}
+}
+
+func (shaders) PostPasses() []*opengl.PostPass {
+	return []*opengl.PostPass{opengl.NewCRTPass()}
+}
+
+func TestHostAddShaders(t *testing.T) {
+	h, err := NewHost(context.Background(), shaders{}, nameOnly("idle"))
+	require.NoError(t, err)
+
+	r := opengl.NewOpenGLRenderSystem(context.Background(), nil, &opengl.RenderCommands{})
+	require.NoError(t, h.AddShaders(r))
+
+	assert.Equal(t, []*opengl.PostPass{opengl.NewCRTPass()}, r.PostPasses())
+}
+
+// meter draws a window.
+type meter struct{}
+
+func (meter) Name() string { return "meter" }
+
+func (m meter) Windows() []Window { return []Window{m} }
+
+func (meter) Visible() bool { return true }
+
+func (meter) Bounds() (mgl32.Vec2, mgl32.Vec2) { return mgl32.Vec2{}, mgl32.Vec2{0.25, 0.1} }
+
+func (meter) Version() uint64 { return 0 }
+
+func (meter) Draw(img draw.Image) {}
+
+func TestHostWindows(t *testing.T) {
+	h, err := NewHost(context.Background(), meter{}, nameOnly("idle"), meter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Window{meter{}, meter{}}, h.Windows())
+}
+
+func TestHostInitError(t *testing.T) {
+	_, err := NewHost(context.Background(), &recorder{name: "broken", initErr: errors.New("no config")})
+	assert.EqualError(t, err, "could not initialize plugin 'broken': no config")
+}
+
+func TestRegister(t *testing.T) {
+	Register(nameOnly("b"))
+	Register(nameOnly("a"))
+	defer func() {
+		delete(registry, "a")
+		delete(registry, "b")
+	}()
+
+	assert.Equal(t, []Plugin{nameOnly("a"), nameOnly("b")}, Registered())
+	assert.Panics(t, func() { Register(nameOnly("a")) })
+}
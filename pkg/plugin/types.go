@@ -0,0 +1,50 @@
+package plugin
+
+import (
+	"github.com/project-midgard/midgarts/internal/entity"
+	"github.com/project-midgard/midgarts/internal/event"
+	"github.com/project-midgard/midgarts/internal/network/packet"
+	"github.com/project-midgard/midgarts/internal/system"
+	"github.com/project-midgard/midgarts/internal/system/opengl"
+)
+
+// The types of the hooks, which live in internal packages: plugins built
+// outside of this module cannot import them, and name them here.
+type (
+	Character    = entity.Character
+	Packet       = packet.Packet
+	EventBus     = event.Bus
+	Shader       = opengl.Shader
+	ShaderSource = opengl.ShaderSource
+	PostPass     = opengl.PostPass
+	Window       = system.Window
+)
+
+// The shaders a ShaderProvider replaces.
+const (
+	SpriteShader  = opengl.SpriteShader
+	BoxShader     = opengl.BoxShader
+	ModelShader   = opengl.ModelShader
+	OverlayShader = opengl.OverlayShader
+	HUDShader     = opengl.HUDShader
+)
+
+// The events published on the EventBus, subscribed to with a func taking
+// one of them (see event.Bus.Subscribe).
+type (
+	KeyChanged        = event.KeyChanged
+	MouseClicked      = event.MouseClicked
+	Tapped            = event.Tapped
+	Pinched           = event.Pinched
+	CharacterPicked   = event.CharacterPicked
+	GroundClicked     = event.GroundClicked
+	StatusChanged     = event.StatusChanged
+	CharacterEntered  = event.CharacterEntered
+	TimeScaleToggled  = event.TimeScaleToggled
+	PacketReceived    = event.PacketReceived
+	LevelUp           = event.LevelUp
+	GATOverlayToggled = event.GATOverlayToggled
+	SnapshotRequested = event.SnapshotRequested
+	WeatherCycled     = event.WeatherCycled
+	StatusCycled      = event.StatusCycled
+)